@@ -2,21 +2,56 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"runtime/debug"
 
 	tea "github.com/charmbracelet/bubbletea"
 	zone "github.com/lrstanley/bubblezone"
 	"github.com/rebelice/lazypg/internal/app"
+	"github.com/rebelice/lazypg/internal/cli"
 	"github.com/rebelice/lazypg/internal/config"
 )
 
+// version is set at build time
+var version = "dev"
+
 func main() {
-	cfg, err := config.Load()
+	opts, err := cli.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		cli.Usage(os.Stdout)
+		return
+	}
 	if err != nil {
-		log.Printf("Warning: Could not load config: %v (using defaults)\n", err)
-		cfg = config.GetDefaults()
+		fmt.Fprintf(os.Stderr, "lazypg: %v\n\n", err)
+		cli.Usage(os.Stderr)
+		os.Exit(2)
+	}
+	if opts.ShowVersion {
+		fmt.Println("lazypg", buildVersion())
+		return
+	}
+
+	var cfg *config.Config
+	if opts.ConfigPath != "" {
+		// A config file asked for must load
+		cfg, err = config.LoadFile(opts.ConfigPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lazypg: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		cfg, err = config.Load()
+		if err != nil {
+			log.Printf("Warning: Could not load config: %v (using defaults)\n", err)
+			cfg = config.GetDefaults()
+		}
+	}
+	if opts.Theme != "" {
+		cfg.UI.Theme = opts.Theme
 	}
 
 	// Initialize bubblezone for mouse support
@@ -27,15 +62,28 @@ func main() {
 	_ = ctx // Context will be used in later tasks for discovery
 
 	app := app.New(cfg)
+	app.SetStartup(opts.StartupOptions)
 
-	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	tuiOpts := []tea.ProgramOption{tea.WithAltScreen()}
 	if cfg.UI.MouseEnabled {
-		opts = append(opts, tea.WithMouseCellMotion())
+		tuiOpts = append(tuiOpts, tea.WithMouseCellMotion())
 	}
 
-	p := tea.NewProgram(app, opts...)
+	p := tea.NewProgram(app, tuiOpts...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// buildVersion returns the release version, or the module version for
+// builds made with go install
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
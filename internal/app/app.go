@@ -2,11 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,21 +20,32 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
 	"github.com/rebelice/lazypg/internal/commands"
+	"github.com/rebelice/lazypg/internal/completion"
 	"github.com/rebelice/lazypg/internal/config"
 	"github.com/rebelice/lazypg/internal/connection_history"
 	"github.com/rebelice/lazypg/internal/db/connection"
 	"github.com/rebelice/lazypg/internal/db/discovery"
+	"github.com/rebelice/lazypg/internal/db/edit"
 	"github.com/rebelice/lazypg/internal/db/metadata"
 	"github.com/rebelice/lazypg/internal/db/query"
+	"github.com/rebelice/lazypg/internal/db/schemadiff"
+	"github.com/rebelice/lazypg/internal/erd"
+	"github.com/rebelice/lazypg/internal/explain"
+	"github.com/rebelice/lazypg/internal/export"
+	"github.com/rebelice/lazypg/internal/importer"
 	"github.com/rebelice/lazypg/internal/favorites"
 	filterBuilder "github.com/rebelice/lazypg/internal/filter"
 	"github.com/rebelice/lazypg/internal/history"
 	"github.com/rebelice/lazypg/internal/jsonb"
 	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/profiles"
+	"github.com/rebelice/lazypg/internal/sqlformat"
 	"github.com/rebelice/lazypg/internal/ui/components"
 	"github.com/rebelice/lazypg/internal/ui/help"
 	"github.com/rebelice/lazypg/internal/ui/theme"
+	"github.com/rebelice/lazypg/internal/values"
 )
 
 // App is the main application model
@@ -81,10 +96,37 @@ type App struct {
 	showJSONBViewer bool
 	jsonbViewer     *components.JSONBViewer
 
+	// Execution plan viewer
+	showExplain   bool
+	explainViewer *components.ExplainViewer
+
+	// Query history browser
+	showHistory   bool
+	historyDialog *components.HistoryDialog
+
+	// Server activity monitor
+	showActivity   bool
+	activityDialog *components.ActivityDialog
+
+	// Entity-relationship diagram of a schema
+	showERDiagram bool
+	erDiagram     *components.ERDiagramView
+
+	// Schema comparison and migration script
+	showSchemaDiffDialog bool
+	schemaDiffDialog     *components.SchemaDiffDialog
+	showSchemaDiff       bool
+	schemaDiff           *components.SchemaDiffView
+
+	// Foreign key navigation between table data tabs
+	navHistory       *models.NavigationHistory
+	showReferences   bool
+	referencesDialog *components.ReferencesDialog
+
 	// Structure view
 	showStructureView bool
 	structureView     *components.StructureView
-	currentTab        int // 0=Data, 1=Columns, 2=Constraints, 3=Indexes
+	currentTab        int // 0=Data, 1=Columns, 2=Constraints, 3=Indexes, 4=Stats
 
 	// Code editor for viewing/editing database object definitions
 	codeEditor     *components.CodeEditor
@@ -98,18 +140,74 @@ type App struct {
 	// Connection history
 	connectionHistory *connection_history.Manager
 
+	// Named connection profiles
+	showProfiles    bool
+	profilesManager *profiles.Manager
+	profilesDialog  *components.ProfilesDialog
+
 	// Password dialog for missing passwords
-	showPasswordDialog    bool
-	passwordDialog        *components.PasswordDialog
-	pendingConnectionInfo *models.ConnectionHistoryEntry
+	showPasswordDialog bool
+	passwordDialog     *components.PasswordDialog
+	pendingConnection  *models.ConnectionConfig
 
 	// Search input
 	showSearch  bool
 	searchInput *components.SearchInput
 
+	// Pending row edits review dialog
+	showPendingChanges   bool
+	pendingChangesDialog *components.PendingChangesDialog
+
+	// Insert row form
+	showInsertRow   bool
+	insertRowDialog *components.InsertRowDialog
+
+	// Confirmation for destructive operations
+	showConfirm   bool
+	confirmDialog *components.ConfirmDialog
+
+	// Prompt for the values of bind variables
+	showBindDialog bool
+	bindDialog     *components.BindDialog
+	bindValues     models.BindValues // Values last entered for editor queries
+
+	// Export dialog and the data it will export
+	showExport   bool
+	exportDialog *components.ExportDialog
+	exportSource *exportSource
+
+	// Import dialog and the state of a running import
+	showImport     bool
+	importDialog   *components.ImportDialog
+	importTarget   dataTarget
+	importCancel   context.CancelFunc
+	importProgress chan ImportProgressMsg
+
 	// Query execution state
 	executeCancelFn context.CancelFunc
+	execution       *query.Execution // Statement or script running from the editor
+	cancelNotice    string           // Outcome of the last cancellation
+	cancelNoticeAt  time.Time
 	executeSpinner  spinner.Model
+	fetchCancelFn   context.CancelFunc // Cancels fetching more streamed rows
+
+	// Explicit transaction of the SQL editor, nil in autocommit mode
+	transaction *query.Transaction
+
+	// Running multi-statement script, and whether scripts stop at an error
+	script            *scriptRun
+	scriptStopOnError bool
+
+	// SQL completion metadata by completionKey of connection and database
+	completionCaches map[string]*completionCache
+
+	// Command line options, until the connection they ask for is loaded
+	startup *StartupOptions
+
+	// Color of each open connection and the SQL editor content of the
+	// inactive ones, by connection ID
+	connColors map[string]string
+	sqlBuffers map[string]string
 
 	// Cached styles for performance (avoid recreating on every render)
 	cachedStyles *appStyles
@@ -128,6 +226,8 @@ type appStyles struct {
 	separatorStyle lipgloss.Style
 	filterStyle    lipgloss.Style
 	vimStyle       lipgloss.Style
+	txStyle        lipgloss.Style
+	txFailedStyle  lipgloss.Style
 	overlayBg      lipgloss.Color
 }
 
@@ -160,6 +260,12 @@ func (a *App) initAppStyles() {
 		vimStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#a6e3a1")). // Green for vim input
 			Bold(true),
+		txStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f9e2af")). // Yellow for an open transaction
+			Bold(true),
+		txFailedStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f38ba8")). // Red for a failed transaction
+			Bold(true),
 		overlayBg: lipgloss.Color("#555555"),
 	}
 }
@@ -176,13 +282,26 @@ type ErrorMsg struct {
 	Message string
 }
 
+// StartupOptions are what the command line asks to open at startup
+type StartupOptions struct {
+	Target   string // Connection URI, keyword/value string or profile name
+	Database string // Database to connect to instead of the target's
+	Schema   string // Schema to open, and put first on the search_path
+	Table    string // Table or view to open, as table or schema.table
+	Query    string // SQL the editor opens with
+	ReadOnly bool   // Default to read-only transactions
+}
+
+// StartupConnectMsg connects to the connection given on the command line
+type StartupConnectMsg struct{}
+
 // LoadTreeMsg requests loading the navigation tree
 type LoadTreeMsg struct{}
 
-// TreeLoadedMsg is sent when tree data is loaded
+// TreeLoadedMsg is sent when the tree of the active connection is loaded
 type TreeLoadedMsg struct {
-	Root *models.TreeNode
-	Err  error
+	Server *models.TreeNode // Node of the connection, with its databases
+	Err    error
 }
 
 // LoadTableDataMsg requests loading table data
@@ -200,6 +319,7 @@ type LoadTableDataMsg struct {
 type TableDataLoadedMsg struct {
 	Columns   []string
 	Rows      [][]string
+	Cells     [][]models.Cell
 	TotalRows int
 	Offset    int   // Offset used in the query (0 for initial load)
 	Err       error
@@ -208,9 +328,157 @@ type TableDataLoadedMsg struct {
 // QueryResultMsg is sent when a query has been executed
 type QueryResultMsg struct {
 	SQL    string
+	Values models.BindValues // Values bound to the query's variables
+	Result models.QueryResult
+	Source components.RowSource // Set when more rows can be fetched
+}
+
+// QueryCancelSentMsg is sent when the server has been asked to cancel an
+// execution. Signalled reports whether a statement was running to cancel.
+type QueryCancelSentMsg struct {
+	Exec      *query.Execution
+	Signalled bool
+	Err       error
+}
+
+// ResultRowsFetchedMsg is sent when another page of a streamed result has
+// been fetched
+type ResultRowsFetchedMsg struct {
+	TabID int
+	Rows  [][]string
+	Cells [][]models.Cell
+	Done  bool
+	Err   error
+}
+
+// ScriptSessionMsg is sent when the connection for a script is ready
+type ScriptSessionMsg struct {
+	Run     *scriptRun
+	Session *query.Session
+	Err     error
+}
+
+// ScriptStatementResultMsg is sent when a statement of a script has run
+type ScriptStatementResultMsg struct {
+	Run    *scriptRun
+	Index  int
 	Result models.QueryResult
 }
 
+// TransactionBeganMsg is sent when a transaction has been opened
+type TransactionBeganMsg struct {
+	Tx  *query.Transaction
+	Err error
+}
+
+// EndTransactionMsg requests committing or rolling back the open
+// transaction. Then, if set, is dispatched once the transaction has ended.
+type EndTransactionMsg struct {
+	Commit bool
+	Then   tea.Msg
+}
+
+// TransactionEndedMsg is sent when a transaction has been committed or
+// rolled back
+type TransactionEndedMsg struct {
+	Tx     *query.Transaction
+	Commit bool
+	Then   tea.Msg
+	Err    error
+}
+
+// TransactionTickMsg refreshes the elapsed time of an open transaction
+type TransactionTickMsg struct {
+	Tx *query.Transaction
+}
+
+// ExplainResultMsg is sent when an execution plan has been loaded
+type ExplainResultMsg struct {
+	Plan *explain.Plan
+	Exec *query.Execution
+	Err  error
+}
+
+// HistoryLoadedMsg is sent when a page of query history is loaded
+type HistoryLoadedMsg struct {
+	Entries []history.HistoryEntry
+	Total   int
+	Offset  int
+	Err     error
+}
+
+// ActivityLoadedMsg is sent when the sessions of the server are loaded
+type ActivityLoadedMsg struct {
+	Sessions []metadata.Session
+	Err      error
+}
+
+// SignalBackendMsg cancels the query of a backend, or terminates it with
+// Terminate set, once confirmed
+type SignalBackendMsg struct {
+	PID       int
+	Terminate bool
+}
+
+// BackendSignalledMsg is sent when a backend has been cancelled or terminated
+type BackendSignalledMsg struct {
+	PID       int
+	Terminate bool
+	Err       error
+}
+
+// FollowReferenceMsg opens the rows a foreign key leads to, recording the
+// move in the navigation history
+type FollowReferenceMsg struct {
+	From models.TableLocation
+	To   models.TableLocation
+}
+
+// ERDiagramLoadedMsg is sent when the tables and foreign keys of a schema
+// are loaded for its diagram
+type ERDiagramLoadedMsg struct {
+	Schema   string
+	Graph    *erd.Graph
+	Selected string // ID of the table to select
+	Err      error
+}
+
+// ERDiagramExportedMsg is sent when a diagram has been saved
+type ERDiagramExportedMsg struct {
+	Path string
+	Err  error
+}
+
+// DatabaseLoadedMsg is sent when the objects of another database of the
+// server have loaded for the tree
+type DatabaseLoadedMsg struct {
+	ConnID string
+	Node   *models.TreeNode // Detached database node holding the objects
+	Use    bool             // Switch to the database once loaded
+	Err    error
+}
+
+// SchemaDiffLoadedMsg is sent when two schemas have been compared
+type SchemaDiffLoadedMsg struct {
+	Source     components.SchemaDiffEndpoint
+	Target     components.SchemaDiffEndpoint
+	Comparison *schemadiff.Comparison
+	Err        error
+}
+
+// MigrationSavedMsg is sent when a migration script has been saved
+type MigrationSavedMsg struct {
+	Path string
+	Err  error
+}
+
+// ReferencesLoadedMsg is sent when the tables referencing a row are loaded
+type ReferencesLoadedMsg struct {
+	From    models.TableLocation
+	Entries []components.ReferenceEntry
+	Err     error
+}
+
 // ObjectDetailsLoadedMsg is sent when object details are loaded
 type ObjectDetailsLoadedMsg struct {
 	ObjectType string // "function", "sequence", "extension", "type", "index", "trigger"
@@ -223,15 +491,115 @@ type ObjectDetailsLoadedMsg struct {
 
 // TabTableDataLoadedMsg is sent when table data for a tab is loaded
 type TabTableDataLoadedMsg struct {
-	ObjectID  string   // schema.table identifier
+	TabID     int
 	Schema    string
 	Table     string
 	Columns   []string
 	Rows      [][]string
+	Cells     [][]models.Cell
 	TotalRows int
 	Err       error
 }
 
+// CommitChangesResultMsg is sent when staged row edits have been committed
+type CommitChangesResultMsg struct {
+	TabID        int
+	RowsAffected int64
+	Err          error
+}
+
+// DeleteRowsMsg is sent when confirmed row deletions should be executed
+type DeleteRowsMsg struct {
+	TabID      int
+	Statements []edit.Statement
+}
+
+// RowChangesResultMsg is sent when inserted or deleted rows have been committed
+type RowChangesResultMsg struct {
+	TabID        int
+	Action       string // "insert" or "delete"
+	RowsAffected int64
+	Err          error
+}
+
+// ExportCompleteMsg is sent when an export has finished. The rows of a
+// streamed result fetched for the export are handed back to its tab.
+type ExportCompleteMsg struct {
+	Path string
+	Rows int64
+	Err  error
+
+	TabID        int // Streamed result tab the rows were fetched for, 0 for none
+	FetchedRows  [][]string
+	FetchedCells [][]models.Cell
+	FetchDone    bool // All rows of the result were fetched
+}
+
+// ImportTargetLoadedMsg is sent when the columns of the import target table have been loaded
+type ImportTargetLoadedMsg struct {
+	ObjectID string
+	Target   dataTarget
+	Schema   string
+	Table    string
+	Columns  []models.ColumnDetail
+	Err      error
+}
+
+// ImportFileLoadedMsg is sent when an import file has been read
+type ImportFileLoadedMsg struct {
+	Path string
+	Data *importer.Data
+	Err  error
+}
+
+// ImportProgressMsg reports how many rows of a running import have been sent
+type ImportProgressMsg struct {
+	Done  int
+	Total int
+}
+
+// ImportCompleteMsg is sent when an import has finished
+type ImportCompleteMsg struct {
+	ObjectID  string
+	Inserted  int64
+	RowErrors []importer.RowError
+	Err       error
+}
+
+// statementRunner runs statements in one session: an open transaction or a
+// connection pinned for a script
+type statementRunner interface {
+	Execute(ctx context.Context, sql string, args ...any) models.QueryResult
+}
+
+// scriptRun is the state of a script whose statements run one by one
+type scriptRun struct {
+	sql         string // Whole script, which identifies its pending tab
+	statements  []query.Statement
+	results     []models.QueryResult // Results of the statements run so far
+	stopOnError bool
+	ctx         context.Context
+	runner      statementRunner
+	session     *query.Session    // Connection pinned by the script, if any
+	values      models.BindValues // Values of the script's bind variables
+	exec        *query.Execution
+}
+
+// exportSource describes the data selected for export
+type exportSource struct {
+	Target dataTarget
+
+	// Table export (streamed from the database)
+	Schema string
+	Table  string
+	Filter *models.Filter
+	Sort   *metadata.SortOptions
+
+	// Query result export (already in memory)
+	Result    *models.QueryResult
+	StreamTab int // Tab whose remaining rows are fetched before writing, 0 for none
+}
+
 // New creates a new App instance with config
 func New(cfg *config.Config) *App {
 	state := models.NewAppState()
@@ -248,6 +616,16 @@ func New(cfg *config.Config) *App {
 		state.LeftPanelWidth = cfg.UI.PanelWidthRatio
 	}
 
+	// Configure how cell values are displayed
+	if cfg != nil {
+		zone, err := values.ParseTimeZone(cfg.Data.TimeZone)
+		if err != nil {
+			log.Printf("Warning: Unknown time zone %q: %v", cfg.Data.TimeZone, err)
+			zone = time.Local
+		}
+		values.SetOptions(values.Options{TimeZone: zone, ByteaFormat: cfg.Data.ByteaFormat})
+	}
+
 	// Create empty tree root
 	emptyRoot := models.NewTreeNode("root", models.TreeNodeTypeRoot, "Databases")
 	emptyRoot.Expanded = true
@@ -285,6 +663,16 @@ func New(cfg *config.Config) *App {
 		log.Printf("Warning: Could not initialize connection history: %v", err)
 	}
 
+	// Initialize connection profiles from config.yaml and connections.yaml
+	var configuredProfiles []models.ConnectionProfile
+	if cfg != nil {
+		configuredProfiles = cfg.Connections
+	}
+	profilesManager, err := profiles.NewManager(configDir, configuredProfiles)
+	if err != nil {
+		log.Printf("Warning: Could not initialize connection profiles: %v", err)
+	}
+
 	// Initialize filter builder
 	filterBuilder := components.NewFilterBuilder(th)
 
@@ -308,9 +696,20 @@ func New(cfg *config.Config) *App {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(th.Info)
 
+	stopOnError := config.GetDefaults().Editor.StopOnError
+	autoComplete := config.GetDefaults().Editor.AutoComplete
+	if cfg != nil {
+		stopOnError = cfg.Editor.StopOnError
+		autoComplete = cfg.Editor.AutoComplete
+	}
+
 	app := &App{
 		state:             state,
 		config:            cfg,
+		scriptStopOnError: stopOnError,
+		completionCaches:  make(map[string]*completionCache),
+		connColors:        make(map[string]string),
+		sqlBuffers:        make(map[string]string),
 		theme:             th,
 		connectionManager: connection.NewManager(),
 		discoverer:        discovery.NewDiscoverer(),
@@ -328,6 +727,14 @@ func New(cfg *config.Config) *App {
 		activeFilter:      nil,
 		showJSONBViewer:   false,
 		jsonbViewer:       jsonbViewer,
+		explainViewer:     components.NewExplainViewer(th),
+		historyDialog:     components.NewHistoryDialog(th),
+		activityDialog:    components.NewActivityDialog(th),
+		erDiagram:         components.NewERDiagramView(th),
+		schemaDiffDialog:  components.NewSchemaDiffDialog(th),
+		schemaDiff:        components.NewSchemaDiffView(th),
+		navHistory:        models.NewNavigationHistory(),
+		referencesDialog:  components.NewReferencesDialog(th),
 		showStructureView: false,
 		structureView:     structureView,
 		currentTab:        0,
@@ -335,10 +742,19 @@ func New(cfg *config.Config) *App {
 		favoritesManager:  favoritesManager,
 		favoritesDialog:   favoritesDialog,
 		connectionHistory: connectionHistory,
+		profilesManager:   profilesManager,
+		profilesDialog:    components.NewProfilesDialog(th),
 		passwordDialog:    components.NewPasswordDialog(th),
 		showSearch:        false,
 		searchInput:       searchInput,
 		executeSpinner:    s,
+
+		pendingChangesDialog: components.NewPendingChangesDialog(th),
+		insertRowDialog:      components.NewInsertRowDialog(th),
+		confirmDialog:        components.NewConfirmDialog(th),
+		bindDialog:           components.NewBindDialog(th),
+		exportDialog:         components.NewExportDialog(th),
+		importDialog:         components.NewImportDialog(th),
 		leftPanel: components.Panel{
 			Title:   "Explorer",
 			Content: "Databases\n└─ (empty)",
@@ -358,9 +774,24 @@ func New(cfg *config.Config) *App {
 	// Initialize cached styles for performance
 	app.initAppStyles()
 
+	app.sqlEditor.SetCompletionSource(app.complete, autoComplete)
+	app.sqlEditor.SetFormatting(app.formatting())
+
 	return app
 }
 
+// SetStartup sets what the command line asks to open. The SQL editor opens
+// with the query at once, the rest once Init has connected.
+func (a *App) SetStartup(startup StartupOptions) {
+	a.startup = &startup
+	if startup.Query != "" {
+		a.sqlEditor.SetContent(startup.Query)
+		a.sqlEditor.Expand()
+		a.state.FocusArea = models.FocusSQLEditor
+		a.updatePanelStyles()
+	}
+}
+
 // Init implements tea.Model
 func (a *App) Init() tea.Cmd {
 	// Load connection history if available
@@ -372,10 +803,15 @@ func (a *App) Init() tea.Cmd {
 	// If no active connection, automatically show connection dialog on startup
 	if a.state.ActiveConnection == nil {
 		a.showConnectionDialog = true
-		return tea.Batch(
+		cmds := []tea.Cmd{
 			a.triggerDiscovery(),
 			a.connectionDialog.Init(), // Start cursor blinking
-		)
+		}
+		// Connect right away to the command line's target or PG* variables
+		if a.startup != nil && (a.startup.Target != "" || discovery.GetEnvironmentConfig() != nil) {
+			cmds = append(cmds, func() tea.Msg { return StartupConnectMsg{} })
+		}
+		return tea.Batch(cmds...)
 	}
 	return a.connectionDialog.Init() // Always init textinput cursors
 }
@@ -397,6 +833,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case commands.ConnectCommandMsg:
 		// Handle connect command from palette
+		if a.transaction != nil {
+			return a, a.confirmEndTransaction("Connect", msg)
+		}
 		a.showConnectionDialog = true
 		return a, a.triggerDiscovery()
 
@@ -409,6 +848,113 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case commands.SwitchConnectionCommandMsg:
+		if err := a.useConnection(msg.ID, ""); err != nil {
+			a.ShowError("Cannot Switch Connection", err.Error())
+			return a, nil
+		}
+		if server := a.serverNode(msg.ID); server != nil {
+			a.treeView.NavigateToNode(server)
+		}
+		return a, nil
+
+	case commands.DisconnectCommandMsg:
+		if a.state.ActiveConnection == nil {
+			return a, nil
+		}
+		if a.transaction != nil {
+			return a, a.confirmEndTransaction("Disconnect", msg)
+		}
+		a.disconnect(a.state.ActiveConnection.ID)
+		return a, nil
+
+	case commands.FormatSQLCommandMsg:
+		// Format the object definition being edited, otherwise the SQL editor
+		if ce := a.resultTabs.GetActiveCodeEditor(); ce != nil && !ce.ReadOnly && a.state.FocusArea == models.FocusDataPanel {
+			ce.Format()
+			return a, nil
+		}
+		a.sqlEditor.Format()
+		a.sqlEditor.Expand()
+		a.state.FocusArea = models.FocusSQLEditor
+		a.updatePanelStyles()
+		return a, nil
+
+	case commands.RunScriptCommandMsg:
+		a.sqlEditor.FormatOnSave()
+		script := a.sqlEditor.GetContent()
+		return a, func() tea.Msg {
+			return components.ExecuteScriptMsg{SQL: script}
+		}
+
+	case commands.ToggleStopOnErrorCommandMsg:
+		a.scriptStopOnError = !a.scriptStopOnError
+		if a.scriptStopOnError {
+			a.ShowError("Stop on Error", "Scripts now stop at the first failing statement.")
+		} else {
+			a.ShowError("Continue on Error", "Scripts now run every statement, even after one fails.")
+		}
+		return a, nil
+
+	case commands.BeginTransactionCommandMsg:
+		if a.transaction != nil {
+			a.ShowError("Transaction Open", "A transaction is already in progress.\n\nPress Alt+C to commit or Alt+R to roll back.")
+			return a, nil
+		}
+		return a, func() tea.Msg {
+			return components.ExecuteQueryMsg{SQL: "BEGIN"}
+		}
+
+	case commands.CommitTransactionCommandMsg:
+		return a, func() tea.Msg { return EndTransactionMsg{Commit: true} }
+
+	case commands.RollbackTransactionCommandMsg:
+		return a, func() tea.Msg { return EndTransactionMsg{} }
+
+	case TransactionBeganMsg:
+		if msg.Err != nil {
+			a.ShowError("Cannot Begin Transaction", msg.Err.Error())
+			return a, nil
+		}
+		a.transaction = msg.Tx
+		return a, a.transactionTick(msg.Tx)
+
+	case TransactionTickMsg:
+		if msg.Tx == a.transaction && msg.Tx.Open() {
+			return a, a.transactionTick(msg.Tx)
+		}
+		return a, nil
+
+	case EndTransactionMsg:
+		if a.transaction == nil {
+			if msg.Then != nil {
+				then := msg.Then
+				return a, func() tea.Msg { return then }
+			}
+			a.ShowError("No Transaction", "There is no open transaction.\n\nRun BEGIN in the SQL editor to start one.")
+			return a, nil
+		}
+		return a, a.endTransaction(a.transaction, msg.Commit, msg.Then)
+
+	case TransactionEndedMsg:
+		if msg.Tx == a.transaction {
+			a.transaction = nil
+		}
+		if msg.Then != nil {
+			// Quitting or disconnecting goes ahead even if the rollback
+			// failed: the session is gone either way
+			then := msg.Then
+			return a, func() tea.Msg { return then }
+		}
+		if msg.Err != nil {
+			title := "Rollback Failed"
+			if msg.Commit {
+				title = "Commit Failed"
+			}
+			a.ShowError(title, msg.Err.Error())
+		}
+		return a, nil
+
 	case commands.QuickQueryCommandMsg:
 		// Open SQL editor (expand if collapsed)
 		if !a.sqlEditor.IsExpanded() {
@@ -424,901 +970,1753 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case commands.HistoryCommandMsg:
-		// Query history browsing - planned feature
-		a.ShowError("Not Implemented", "Query history browsing is planned for a future release")
+		return a, a.openHistoryDialog()
+
+	case components.LoadHistoryMsg:
+		return a, a.loadHistory(msg)
+
+	case HistoryLoadedMsg:
+		a.historyDialog.SetPage(msg.Entries, msg.Total, msg.Offset, msg.Err)
 		return a, nil
 
-	case commands.FavoritesCommandMsg:
-		// Open favorites dialog
-		if a.favoritesManager != nil {
-			a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
-		}
-		a.showFavorites = true
+	case components.OpenHistoryEntryMsg:
+		a.showHistory = false
+		a.sqlEditor.SetContent(msg.Entry.Query)
+		a.sqlEditor.Expand()
+		a.state.FocusArea = models.FocusSQLEditor
+		a.updatePanelStyles()
 		return a, nil
 
-	case commands.ExportFavoritesCSVMsg:
-		// Export favorites to CSV
+	case components.RunHistoryEntryMsg:
+		a.showHistory = false
+		sql := msg.Entry.Query
+		return a, func() tea.Msg {
+			return components.ExecuteQueryMsg{SQL: sql}
+		}
+
+	case components.FavoriteHistoryEntryMsg:
 		if a.favoritesManager == nil {
-			a.ShowError("Export Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+			return a, nil
+		}
+		name := favoriteNameForQuery(msg.Entry.Query, a.favoritesManager.GetAll())
+		if _, err := a.favoritesManager.Add(name, "", msg.Entry.Query, msg.Entry.ConnectionName, msg.Entry.DatabaseName, []string{"history"}); err != nil {
+			a.ShowError("Cannot Add Favorite", fmt.Sprintf("Failed to add favorite:\n\n%v", err))
+			return a, nil
+		}
+		a.ShowError("Added to Favorites", fmt.Sprintf("Saved as '%s'.\n\nPress Ctrl+B to open favorites.", name))
+		return a, nil
+
+	case components.DeleteHistoryEntryMsg:
+		if a.historyStore == nil {
+			return a, nil
+		}
+		if err := a.historyStore.Delete(msg.ID); err != nil {
+			a.ShowError("Cannot Delete Entry", err.Error())
 			return a, nil
 		}
+		return a, a.historyDialog.Reload()
 
-		path, err := a.favoritesManager.ExportToCSV()
+	case components.PurgeHistoryMsg:
+		if a.historyStore == nil {
+			return a, nil
+		}
+		purged, err := a.historyStore.PurgeOlderThan(msg.Days)
 		if err != nil {
-			a.ShowError("Export Failed", fmt.Sprintf("Failed to export favorites to CSV:\n\n%v\n\nPlease check that you have write permissions and try again.", err))
+			a.ShowError("Purge Failed", err.Error())
 			return a, nil
 		}
+		noun := "queries"
+		if purged == 1 {
+			noun = "query"
+		}
+		a.ShowError("History Purged", fmt.Sprintf("Deleted %d %s older than %d day(s).", purged, noun, msg.Days))
+		return a, a.openHistoryDialog()
 
-		// Show success notification
-		a.ShowError("Export Complete", fmt.Sprintf("Successfully exported favorites to:\n\n%s\n\nYou can now import this file or share it with others.", path))
+	case components.CloseHistoryDialogMsg:
+		a.showHistory = false
 		return a, nil
 
-	case commands.ExportFavoritesJSONMsg:
-		// Export favorites to JSON
-		if a.favoritesManager == nil {
-			a.ShowError("Export Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+	case commands.ServerActivityCommandMsg:
+		return a, a.openActivityDialog()
+
+	case components.LoadActivityMsg:
+		// Refreshes stop with the dialog; stale timers are dropped
+		if !a.showActivity || !a.activityDialog.IsCurrent(msg) {
 			return a, nil
 		}
+		return a, a.loadActivity()
 
-		path, err := a.favoritesManager.ExportToJSON()
-		if err != nil {
-			a.ShowError("Export Failed", fmt.Sprintf("Failed to export favorites to JSON:\n\n%v\n\nPlease check that you have write permissions and try again.", err))
+	case ActivityLoadedMsg:
+		if !a.showActivity {
 			return a, nil
 		}
+		return a, a.activityDialog.SetSessions(msg.Sessions, msg.Err)
 
-		// Show success notification
-		a.ShowError("Export Complete", fmt.Sprintf("Successfully exported favorites to:\n\n%s\n\nYou can now import this file or share it with others.", path))
+	case components.SignalBackendRequestMsg:
+		session := msg.Session
+		title, message := "Cancel Query", fmt.Sprintf("Cancel the running query of backend %d?", session.PID)
+		if msg.Terminate {
+			title, message = "Terminate Session", fmt.Sprintf("Terminate backend %d? Its connection is closed and an open transaction is rolled back.", session.PID)
+		}
+		details := fmt.Sprintf("%s@%s · %s", session.User, session.Database, session.State)
+		if query := strings.Join(strings.Fields(session.Query), " "); query != "" {
+			details += "\n" + query
+		}
+		a.confirmDialog.SetConfirm(title, message, details, SignalBackendMsg{PID: session.PID, Terminate: msg.Terminate})
+		a.showConfirm = true
 		return a, nil
 
-	case components.OpenExternalEditorMsg:
-		// Open external editor
-		return a, a.openExternalEditor(msg.Content)
+	case SignalBackendMsg:
+		return a, a.signalBackend(msg.PID, msg.Terminate)
 
-	case components.ExternalEditorResultMsg:
-		if msg.Error != nil {
-			a.ShowError("Editor Error", msg.Error.Error())
+	case BackendSignalledMsg:
+		if msg.Err != nil {
+			a.activityDialog.SetNotice(msg.Err.Error())
+		} else if msg.Terminate {
+			a.activityDialog.SetNotice(fmt.Sprintf("Terminated backend %d", msg.PID))
+		} else {
+			a.activityDialog.SetNotice(fmt.Sprintf("Sent cancel to backend %d", msg.PID))
+		}
+		if !a.showActivity {
 			return a, nil
 		}
-		a.sqlEditor.SetContent(msg.Content)
+		return a, a.activityDialog.Refresh()
+
+	case components.OpenActivityQueryMsg:
+		a.showActivity = false
+		a.sqlEditor.SetContent(msg.Query)
+		a.sqlEditor.Expand()
+		a.state.FocusArea = models.FocusSQLEditor
+		a.updatePanelStyles()
 		return a, nil
 
-	case components.ExecuteQueryMsg:
-		// Handle query execution from SQL editor
-		if a.state.ActiveConnection == nil {
-			a.ShowError("No Connection", "Please connect to a database first")
+	case components.CloseActivityDialogMsg:
+		a.showActivity = false
+		return a, nil
+
+	case commands.ERDiagramCommandMsg:
+		return a, a.openERDiagram()
+
+	case ERDiagramLoadedMsg:
+		// A diagram closed or replaced while loading is dropped
+		if !a.showERDiagram || msg.Schema != a.erDiagram.Schema() {
 			return a, nil
 		}
+		a.erDiagram.SetGraph(msg.Graph, msg.Selected, msg.Err)
+		return a, nil
 
-		// Create pending tab immediately
-		a.resultTabs.StartPendingQuery(msg.SQL)
+	case components.ExportERDiagramMsg:
+		return a, exportERDiagram(msg)
 
-		// Immediately switch focus to data panel and collapse editor
-		a.sqlEditor.Collapse()
-		a.state.FocusArea = models.FocusDataPanel
-		a.updatePanelStyles()
+	case ERDiagramExportedMsg:
+		if msg.Err != nil {
+			a.erDiagram.SetNotice(fmt.Sprintf("Export failed: %v", msg.Err))
+		} else {
+			a.erDiagram.SetNotice("Saved to " + msg.Path)
+		}
+		return a, nil
 
-		// Create cancellable context for query execution
-		ctx, cancel := context.WithCancel(context.Background())
-		a.executeCancelFn = cancel
+	case components.OpenERDiagramTableMsg:
+		a.showERDiagram = false
+		return a, a.openTableLocation(models.TableLocation{Schema: msg.Schema, Table: msg.Table})
 
-		// Execute query asynchronously and start spinner
-		return a, tea.Batch(
-			a.executeSpinner.Tick,
-			func() tea.Msg {
-				conn, err := a.connectionManager.GetActive()
-				if err != nil {
-					return QueryResultMsg{
-						SQL: msg.SQL,
-						Result: models.QueryResult{
-							Error: fmt.Errorf("failed to get connection: %w", err),
-						},
-					}
-				}
-
-				result := query.Execute(ctx, conn.Pool.GetPool(), msg.SQL)
-				return QueryResultMsg{
-					SQL:    msg.SQL,
-					Result: result,
-				}
-			},
-		)
-
-	case QueryResultMsg:
-		// Clear execution state
-		a.executeCancelFn = nil
-
-		// Record query to history
-		if a.historyStore != nil {
-			connName := ""
-			dbName := ""
-			if a.state.ActiveConnection != nil {
-				connName = a.state.ActiveConnection.Config.Name
-				dbName = a.state.ActiveConnection.Config.Database
-			}
+	case components.CloseERDiagramMsg:
+		a.showERDiagram = false
+		return a, nil
 
-			entry := history.HistoryEntry{
-				ConnectionName: connName,
-				DatabaseName:   dbName,
-				Query:          msg.SQL,
-				Duration:       msg.Result.Duration,
-				RowsAffected:   msg.Result.RowsAffected,
-				Success:        msg.Result.Error == nil,
-			}
+	case commands.SchemaDiffCommandMsg:
+		a.openSchemaDiffDialog()
+		return a, nil
 
-			if msg.Result.Error != nil {
-				entry.ErrorMessage = msg.Result.Error.Error()
-			}
+	case components.CloseSchemaDiffDialogMsg:
+		a.showSchemaDiffDialog = false
+		return a, nil
 
-			// Record to history (ignore errors to not interrupt user flow)
-			_ = a.historyStore.Add(entry)
-		}
+	case components.CompareSchemasMsg:
+		return a, a.compareSchemas(msg.Source, msg.Target)
 
-		// Handle query result
-		if msg.Result.Error != nil {
-			// Check if it was cancelled (context cancelled error)
-			if msg.Result.Error.Error() == "context canceled" {
-				// Already handled by CancelPendingQuery, just return
-				return a, nil
-			}
-			// Show error and remove pending tab
-			a.resultTabs.CancelPendingQuery()
-			a.ShowError("Query Error", msg.Result.Error.Error())
+	case SchemaDiffLoadedMsg:
+		// A comparison closed or replaced while loading is dropped
+		if !a.showSchemaDiff || !a.schemaDiff.IsCurrent(msg.Source, msg.Target) {
 			return a, nil
 		}
-
-		// Complete the pending query with results
-		a.resultTabs.CompletePendingQuery(msg.SQL, msg.Result)
-
+		a.schemaDiff.SetComparison(msg.Comparison, msg.Err)
 		return a, nil
 
-	case components.ApplyFilterMsg:
-		// Apply the filter and reload table data
-		a.showFilterBuilder = false
-		a.activeFilter = &msg.Filter
+	case components.SaveMigrationMsg:
+		return a, saveMigration(msg)
 
-		// Reload table with filter
-		if a.state.TreeSelected != nil && a.state.TreeSelected.Type == models.TreeNodeTypeTable {
-			return a, a.loadTableDataWithFilter(*a.activeFilter)
+	case MigrationSavedMsg:
+		if msg.Err != nil {
+			a.schemaDiff.SetNotice(fmt.Sprintf("Save failed: %v", msg.Err))
+		} else {
+			a.schemaDiff.SetNotice("Saved to " + msg.Path)
 		}
 		return a, nil
 
-	case components.CloseFilterBuilderMsg:
-		a.showFilterBuilder = false
-		return a, nil
-
-	case components.CloseJSONBViewerMsg:
-		a.showJSONBViewer = false
+	case components.OpenMigrationMsg:
+		a.showSchemaDiff = false
+		a.sqlEditor.SetContent(msg.Script)
+		a.sqlEditor.Expand()
+		a.state.FocusArea = models.FocusSQLEditor
+		a.updatePanelStyles()
 		return a, nil
 
-	case components.CloseErrorOverlayMsg:
-		a.showError = false
+	case components.CloseSchemaDiffMsg:
+		a.showSchemaDiff = false
 		return a, nil
 
-	case components.PasswordSubmitMsg:
-		// User submitted password from password dialog
-		a.showPasswordDialog = false
-		if a.pendingConnectionInfo != nil {
-			// Create connection config with the entered password
-			config := a.pendingConnectionInfo.ToConnectionConfig()
-			config.Password = msg.Password
-
-			// Try to save the password for future use
-			if a.connectionHistory != nil {
-				if err := a.connectionHistory.SavePassword(config.Host, config.Port, config.Database, config.User, config.Password); err != nil {
-					log.Printf("Warning: Failed to save password: %v", err)
-				}
-			}
+	case FollowReferenceMsg:
+		a.showReferences = false
+		a.navHistory.Visit(msg.From, msg.To)
+		return a, a.openTableLocation(msg.To)
 
-			a.pendingConnectionInfo = nil
-			return a.performConnection(config)
+	case ReferencesLoadedMsg:
+		if msg.Err != nil {
+			a.ShowError("Cannot List References", msg.Err.Error())
+			return a, nil
+		}
+		from := msg.From.Schema + "." + msg.From.Table
+		switch len(msg.Entries) {
+		case 0:
+			a.ShowError("No References", fmt.Sprintf("No foreign keys reference this row of %s.", from))
+			return a, nil
+		case 1:
+			action := msg.Entries[0].Action
+			return a, func() tea.Msg { return action }
 		}
+		a.referencesDialog.SetEntries("Rows referencing this row of "+from, msg.Entries)
+		a.showReferences = true
 		return a, nil
 
-	case components.PasswordCancelMsg:
-		// User cancelled password dialog
-		a.showPasswordDialog = false
-		a.pendingConnectionInfo = nil
-		// Re-show connection dialog
-		a.showConnectionDialog = true
+	case components.CloseReferencesDialogMsg:
+		a.showReferences = false
 		return a, nil
 
-	case components.CloseCommandPaletteMsg:
-		a.showCommandPalette = false
+	case commands.FavoritesCommandMsg:
+		// Open favorites dialog
+		if a.favoritesManager != nil {
+			a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+		}
+		a.showFavorites = true
 		return a, nil
 
-	case components.ExecuteFavoriteMsg:
-		// Execute favorite query
-		if a.state.ActiveConnection == nil {
-			a.ShowError("No Database Connection", "Please connect to a database before executing queries.\n\nPress 'c' to open the connection dialog.")
+	case commands.ExportFavoritesCSVMsg:
+		// Export favorites to CSV
+		if a.favoritesManager == nil {
+			a.ShowError("Export Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
 			return a, nil
 		}
 
-		// Record usage
-		if a.favoritesManager != nil {
-			if err := a.favoritesManager.RecordUsage(msg.Favorite.ID); err != nil {
-				// Log error but don't block execution
-				log.Printf("Warning: Failed to record favorite usage: %v", err)
-			}
-		}
-
-		// Execute query asynchronously
-		a.showFavorites = false
-		return a, func() tea.Msg {
-			conn, err := a.connectionManager.GetActive()
-			if err != nil {
-				return QueryResultMsg{
-					SQL: msg.Favorite.Query,
-					Result: models.QueryResult{
-						Error: fmt.Errorf("connection error: %w", err),
-					},
-				}
-			}
-
-			result := query.Execute(context.Background(), conn.Pool.GetPool(), msg.Favorite.Query)
-			return QueryResultMsg{
-				SQL:    msg.Favorite.Query,
-				Result: result,
-			}
+		path, err := a.favoritesManager.ExportToCSV()
+		if err != nil {
+			a.ShowError("Export Failed", fmt.Sprintf("Failed to export favorites to CSV:\n\n%v\n\nPlease check that you have write permissions and try again.", err))
+			return a, nil
 		}
 
-	case components.CloseFavoritesDialogMsg:
-		a.showFavorites = false
+		// Show success notification
+		a.ShowError("Export Complete", fmt.Sprintf("Successfully exported favorites to:\n\n%s\n\nYou can now import this file or share it with others.", path))
 		return a, nil
 
-	case components.SearchInputMsg:
-		// Handle search request from search input
-		a.showSearch = false
-		if msg.Query == "" {
+	case commands.ExportFavoritesJSONMsg:
+		// Export favorites to JSON
+		if a.favoritesManager == nil {
+			a.ShowError("Export Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
 			return a, nil
 		}
 
-		// Get the active table view (Result Tabs or main TableView)
-		activeTable := a.getActiveTableView()
-
-		if msg.Mode == "local" {
-			// Local search - search only loaded data
-			if activeTable != nil {
-				activeTable.SearchLocal(msg.Query)
-			}
-		} else {
-			// For Result Tabs, always use local search (data is already loaded)
-			if a.resultTabs.HasTabs() {
-				if activeTable != nil {
-					activeTable.SearchLocal(msg.Query)
-				}
-				return a, nil
-			}
+		path, err := a.favoritesManager.ExportToJSON()
+		if err != nil {
+			a.ShowError("Export Failed", fmt.Sprintf("Failed to export favorites to JSON:\n\n%v\n\nPlease check that you have write permissions and try again.", err))
+			return a, nil
+		}
 
-			// Table search - query the database (only for table browser)
-			if a.state.ActiveConnection == nil {
-				a.ShowError("No Connection", "Please connect to a database first")
-				return a, nil
-			}
+		// Show success notification
+		a.ShowError("Export Complete", fmt.Sprintf("Successfully exported favorites to:\n\n%s\n\nYou can now import this file or share it with others.", path))
+		return a, nil
 
-			if a.currentTable == "" {
-				a.ShowError("No Table", "Please select a table first")
-				return a, nil
-			}
+	case commands.ExportDataCommandMsg:
+		// Open export dialog for the current results
+		return a.openExportDialog()
 
-			// Execute table search
-			return a, a.searchTable(msg.Query)
-		}
+	case components.CloseExportDialogMsg:
+		a.showExport = false
 		return a, nil
 
-	case components.CloseSearchMsg:
-		a.showSearch = false
-		a.searchInput.Reset()
-		return a, nil
+	case components.ExportDataMsg:
+		a.showExport = false
+		return a, a.exportData(msg.Format, msg.Path)
 
-	case SearchTableResultMsg:
+	case commands.ImportDataCommandMsg:
+		// Open import dialog for the selected table
+		return a.openImportDialog()
+
+	case ImportTargetLoadedMsg:
 		if msg.Err != nil {
-			a.ShowError("Search Error", msg.Err.Error())
+			a.ShowError("Import Not Available", fmt.Sprintf("Failed to load columns of %s.%s:\n\n%v", msg.Schema, msg.Table, msg.Err))
 			return a, nil
 		}
+		a.importTarget = msg.Target
+		a.importDialog.SetTable(msg.ObjectID, msg.Schema, msg.Table, msg.Columns)
+		a.showImport = true
+		return a, a.importDialog.Init()
 
-		if msg.Data == nil || len(msg.Data.Rows) == 0 {
-			a.ShowError("No Results", fmt.Sprintf("No matches found for '%s'", msg.Query))
+	case components.LoadImportFileMsg:
+		return a, loadImportFile(msg.Path)
+
+	case ImportFileLoadedMsg:
+		if msg.Err != nil {
+			a.importDialog.SetFileError(msg.Err)
 			return a, nil
 		}
+		a.importDialog.SetData(msg.Path, msg.Data)
+		return a, nil
 
-		// Replace table data with search results
-		a.tableView.SetData(msg.Data.Columns, msg.Data.Rows, int(msg.Data.TotalRows))
+	case components.StartImportMsg:
+		return a, a.startImport(msg)
 
-		// Build matches from all cells that contain the query
-		queryLower := strings.ToLower(msg.Query)
-		var matches []components.MatchPos
-		for rowIdx, row := range msg.Data.Rows {
-			for colIdx, cell := range row {
-				if strings.Contains(strings.ToLower(cell), queryLower) {
-					matches = append(matches, components.MatchPos{Row: rowIdx, Col: colIdx})
-				}
-			}
-		}
+	case ImportProgressMsg:
+		a.importDialog.SetProgress(msg.Done, msg.Total)
+		return a, waitForImportProgress(a.importProgress)
 
-		a.tableView.SetSearchResults(msg.Query, matches)
+	case ImportCompleteMsg:
+		a.importCancel = nil
+		a.importProgress = nil
+		a.importDialog.SetResult(msg.Inserted, msg.RowErrors, msg.Err)
 		return a, nil
 
-	case components.AddFavoriteMsg:
-		if a.favoritesManager != nil {
-			conn := ""
-			if a.state.ActiveConnection != nil {
-				conn = a.state.ActiveConnection.Config.Name
-			}
-			db := a.state.CurrentDatabase
-			_, err := a.favoritesManager.Add(msg.Name, msg.Description, msg.Query, conn, db, msg.Tags)
-			if err != nil {
-				a.ShowError("Cannot Add Favorite", fmt.Sprintf("Failed to add favorite:\n\n%v\n\nPlease check your input and try again.", err))
-			} else {
-				// Refresh the dialog
-				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
-			}
-		} else {
-			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+	case components.CancelImportMsg:
+		if a.importCancel != nil {
+			a.importCancel()
 		}
 		return a, nil
 
-	case components.EditFavoriteMsg:
-		if a.favoritesManager != nil {
-			err := a.favoritesManager.Update(msg.FavoriteID, msg.Name, msg.Description, msg.Query, msg.Tags)
-			if err != nil {
-				a.ShowError("Cannot Update Favorite", fmt.Sprintf("Failed to update favorite:\n\n%v\n\nPlease check your input and try again.", err))
-			} else {
-				// Refresh the dialog
-				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+	case components.CloseImportDialogMsg:
+		a.showImport = false
+		// Reload the table so it shows the imported rows
+		if msg.Imported {
+			if tab := a.findTableDataTab(msg.ObjectID); tab != nil {
+				return a, a.loadTableDataForTab(tab, tab.Structure.GetSchema(), tab.Structure.GetTable())
 			}
-		} else {
-			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
 		}
 		return a, nil
 
-	case components.DeleteFavoriteMsg:
-		if a.favoritesManager != nil {
-			err := a.favoritesManager.Delete(msg.FavoriteID)
-			if err != nil {
-				a.ShowError("Cannot Delete Favorite", fmt.Sprintf("Failed to delete favorite:\n\n%v\n\nThe favorite may have already been deleted.", err))
-			} else {
-				// Refresh the dialog
-				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
-				// Adjust selection if needed
-				if a.favoritesDialog != nil {
-					favorites := a.favoritesManager.GetAll()
-					if len(favorites) > 0 {
-						// Keep selection valid
-						a.favoritesDialog.SetFavorites(favorites)
-					}
-				}
+	case ExportCompleteMsg:
+		if msg.TabID != 0 {
+			a.resultTabs.AppendRows(msg.TabID, msg.FetchedRows, msg.FetchedCells, msg.FetchDone)
+			if !msg.FetchDone {
+				a.resultTabs.StopStreaming(msg.TabID)
 			}
-		} else {
-			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
 		}
+		if msg.Err != nil {
+			a.ShowError("Export Failed", fmt.Sprintf("Failed to export to %s:\n\n%v", msg.Path, msg.Err))
+			return a, nil
+		}
+		a.ShowError("Export Complete", fmt.Sprintf("Exported %d row(s) to:\n\n%s", msg.Rows, msg.Path))
 		return a, nil
 
-	case ErrorMsg:
-		// Handle error messages
-		a.ShowError(msg.Title, msg.Message)
-		return a, nil
+	case components.OpenExternalEditorMsg:
+		// Open external editor
+		return a, a.openExternalEditor(msg.Content)
 
-	case tea.KeyMsg:
-		// Handle error overlay dismissal first if visible
-		if a.showError {
-			key := msg.String()
-			if key == "esc" || key == "enter" {
-				a.DismissError()
-				return a, nil
-			}
-			// Allow quit keys to pass through even when error is showing
-			if key == "q" || key == "ctrl+c" {
-				return a, tea.Quit
-			}
-			// Consume all other keys when error is showing
+	case components.ExternalEditorResultMsg:
+		if msg.Error != nil {
+			a.ShowError("Editor Error", msg.Error.Error())
 			return a, nil
 		}
+		a.sqlEditor.SetContent(msg.Content)
+		return a, nil
 
-		// Handle connection dialog if visible
-		if a.showConnectionDialog {
-			return a.handleConnectionDialog(msg)
+	case components.ExecuteQueryMsg:
+		// Handle query execution from SQL editor
+		if a.state.ActiveConnection == nil {
+			a.ShowError("No Connection", "Please connect to a database first")
+			return a, nil
 		}
-
-		// Handle password dialog if visible
-		if a.showPasswordDialog {
-			var cmd tea.Cmd
-			a.passwordDialog, cmd = a.passwordDialog.Update(msg)
-			return a, cmd
+		if a.script != nil {
+			a.ShowError("Script Running", "Wait for the running script to finish, or press Esc to cancel it.")
+			return a, nil
 		}
 
-		// Handle command palette if visible
-		if a.showCommandPalette {
-			return a.handleCommandPalette(msg)
+		// Ask for the values of bind variables such as :user_id or $1
+		if msg.Values == nil {
+			if names := query.Placeholders(msg.SQL); len(names) > 0 {
+				return a, a.promptBindValues(msg.SQL, names, a.bindValues, msg)
+			}
 		}
-
-		// Handle filter builder input
-		if a.showFilterBuilder {
-			return a.handleFilterBuilder(msg)
+		sql, args, err := query.Bind(msg.SQL, msg.Values)
+		if err != nil {
+			a.ShowError("Query Error", err.Error())
+			return a, nil
 		}
 
-		// Handle JSONB viewer input
-		if a.showJSONBViewer {
-			return a.handleJSONBViewer(msg)
+		// BEGIN opens a transaction that later statements run in
+		if a.transaction == nil && query.IsTransactionStart(msg.SQL) {
+			return a, a.beginTransaction(msg.SQL)
 		}
 
-		// Handle favorites dialog if visible
-		if a.showFavorites {
-			return a.handleFavoritesDialog(msg)
+		// Create pending tab immediately
+		a.resultTabs.StartPendingQuery(msg.SQL)
+
+		// Immediately switch focus to data panel and collapse editor
+		a.sqlEditor.Collapse()
+		a.state.FocusArea = models.FocusDataPanel
+		a.updatePanelStyles()
+
+		// Create cancellable context for query execution
+		ctx, cancel := context.WithCancel(context.Background())
+		a.executeCancelFn = cancel
+		ctx = a.startExecution(ctx)
+
+		// Inside a transaction, run on its connection without a cursor
+		if tx := a.transaction; tx != nil {
+			return a, tea.Batch(
+				a.executeSpinner.Tick,
+				func() tea.Msg {
+					return QueryResultMsg{SQL: msg.SQL, Values: msg.Values, Result: tx.Execute(ctx, sql, args...)}
+				},
+			)
 		}
 
-		// Handle search input if visible
-		if a.showSearch {
-			return a.handleSearchInput(msg)
+		// Execute query asynchronously and start spinner
+		return a, tea.Batch(
+			a.executeSpinner.Tick,
+			func() tea.Msg {
+				conn, err := a.connectionManager.GetActive()
+				if err != nil {
+					return QueryResultMsg{
+						SQL: msg.SQL,
+						Result: models.QueryResult{
+							Error: fmt.Errorf("failed to get connection: %w", err),
+						},
+					}
+				}
+
+				result, cursor := query.Stream(ctx, conn.Pool.GetPool(), sql, query.DefaultFetchSize, args...)
+				resultMsg := QueryResultMsg{
+					SQL:    msg.SQL,
+					Values: msg.Values,
+					Result: result,
+				}
+				if cursor != nil {
+					resultMsg.Source = cursor
+				}
+				return resultMsg
+			},
+		)
+
+	case components.ExecuteScriptMsg:
+		if a.state.ActiveConnection == nil {
+			a.ShowError("No Connection", "Please connect to a database first")
+			return a, nil
+		}
+		if a.script != nil {
+			a.ShowError("Script Running", "Wait for the running script to finish, or press Esc to cancel it.")
+			return a, nil
 		}
 
-		// Handle TreeView search mode - route keys to TreeView
-		// This must come before global key handlers to capture typing during search
-		// and to allow Esc to clear filter in SearchFilterActive mode
-		if a.treeView != nil && a.state.FocusArea == models.FocusTreeView {
-			// In SearchInputting mode, route ALL keys to TreeView
-			if a.treeView.IsSearchInputting() {
-				var cmd tea.Cmd
-				a.treeView, cmd = a.treeView.Update(msg)
-				return a, cmd
-			}
-			// In SearchFilterActive mode, route Esc and / to TreeView
-			if a.treeView.IsSearchActive() && (msg.String() == "esc" || msg.String() == "/") {
-				var cmd tea.Cmd
-				a.treeView, cmd = a.treeView.Update(msg)
-				return a, cmd
+		statements := query.SplitScript(msg.SQL)
+		switch len(statements) {
+		case 0:
+			return a, nil
+		case 1:
+			// A single statement runs like Ctrl+S, streaming its rows
+			next := components.ExecuteQueryMsg{SQL: statements[0].SQL, Values: msg.Values}
+			return a, func() tea.Msg {
+				return next
 			}
 		}
 
-		// Handle code editor input if visible and DataPanel is focused
-		if a.showCodeEditor && a.codeEditor != nil && a.state.FocusArea == models.FocusDataPanel {
-			// Tab is handled in the unified Tab case below, skip here
-			if msg.String() != "tab" && msg.String() != "shift+tab" && msg.String() != "backtab" {
-				_, cmd := a.codeEditor.Update(msg)
-				return a, cmd
+		// The variables of all statements are asked for at once
+		if msg.Values == nil {
+			if names := query.Placeholders(msg.SQL); len(names) > 0 {
+				return a, a.promptBindValues(msg.SQL, names, a.bindValues, msg)
 			}
 		}
 
-		// If SQL editor is focused, handle input
-		if a.isSQLEditorFocused() {
-			// Handle escape to unfocus
-			if msg.String() == "esc" {
-				if a.sqlEditor.IsExpanded() {
-					a.sqlEditor.Collapse()
-				}
-				a.state.FocusArea = models.FocusDataPanel
-				a.updatePanelStyles()
-				return a, nil
-			}
+		a.resultTabs.StartPendingQuery(msg.SQL)
+		a.sqlEditor.Collapse()
+		a.state.FocusArea = models.FocusDataPanel
+		a.updatePanelStyles()
 
-			// Handle ctrl+e to toggle expand/collapse
-			if msg.String() == "ctrl+e" {
-				if a.sqlEditor.IsExpanded() {
-					a.sqlEditor.Collapse()
-					a.state.FocusArea = models.FocusDataPanel
-					a.updatePanelStyles()
-				} else {
-					a.sqlEditor.Expand()
+		ctx, cancel := context.WithCancel(context.Background())
+		a.executeCancelFn = cancel
+		ctx = a.startExecution(ctx)
+		run := &scriptRun{
+			sql:         msg.SQL,
+			statements:  statements,
+			stopOnError: a.scriptStopOnError,
+			ctx:         ctx,
+			values:      msg.Values,
+			exec:        a.execution,
+		}
+		a.script = run
+
+		// Inside a transaction the script runs in it, otherwise it pins a
+		// connection of its own so that session state carries over
+		if a.transaction != nil {
+			run.runner = a.transaction
+			return a, tea.Batch(a.executeSpinner.Tick, a.runScriptStatement(run))
+		}
+		return a, tea.Batch(
+			a.executeSpinner.Tick,
+			func() tea.Msg {
+				conn, err := a.connectionManager.GetActive()
+				if err != nil {
+					return ScriptSessionMsg{Run: run, Err: fmt.Errorf("failed to get connection: %w", err)}
 				}
-				return a, nil
-			}
+				session, err := query.NewSession(ctx, conn.Pool)
+				return ScriptSessionMsg{Run: run, Session: session, Err: err}
+			},
+		)
 
-			// Tab is handled in the unified Tab case below for focus cycling
-			if msg.String() == "tab" || msg.String() == "shift+tab" || msg.String() == "backtab" {
-				// Let Tab fall through to the switch case for focus cycling
-			} else if a.sqlEditor.IsExpanded() {
-				// Route other keys to SQL editor when expanded
-				_, cmd := a.sqlEditor.Update(msg)
-				return a, cmd
-			} else if isPrintableInput(msg.String()) {
-				// Auto-expand and route printable input when collapsed but focused
-				a.sqlEditor.Expand()
-				_, cmd := a.sqlEditor.Update(msg)
-				return a, cmd
+	case ScriptSessionMsg:
+		if msg.Run != a.script {
+			if msg.Session != nil {
+				msg.Session.Close()
 			}
+			return a, nil
 		}
-
-		switch msg.String() {
-		// Ctrl+E to toggle SQL editor expand/collapse
-		case "ctrl+e":
-			a.sqlEditor.Toggle()
-			if a.sqlEditor.IsExpanded() {
-				a.state.FocusArea = models.FocusSQLEditor
-				a.updatePanelStyles()
+		if msg.Err != nil {
+			a.script = nil
+			a.executeCancelFn = nil
+			a.execution = nil
+			a.resultTabs.CancelPendingQuery()
+			if !errors.Is(msg.Err, context.Canceled) {
+				a.ShowError("Script Error", fmt.Sprintf("Could not start the script:\n\n%v", msg.Err))
 			}
 			return a, nil
+		}
+		msg.Run.session = msg.Session
+		msg.Run.runner = msg.Session
+		return a, a.runScriptStatement(msg.Run)
 
-		// Ctrl+Shift+Up to increase editor height preset
-		case "ctrl+shift+up":
-			if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
-				a.sqlEditor.IncreaseHeight()
-			}
+	case ScriptStatementResultMsg:
+		run := a.script
+		if msg.Run != run {
 			return a, nil
+		}
+		stmt := run.statements[msg.Index]
+		run.results = append(run.results, msg.Result)
+		a.recordQuery(stmt.SQL, run.values, msg.Result)
 
-		// Ctrl+Shift+Down to decrease editor height preset
-		case "ctrl+shift+down":
-			if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
-				a.sqlEditor.DecreaseHeight()
-			}
-			return a, nil
+		// The statement may have ended the transaction, e.g. COMMIT
+		if a.transaction != nil && !a.transaction.Open() {
+			a.transaction = nil
+		}
 
-		// Tab switching with [ and ] (like lazygit)
-		case "[":
-			// Previous result tab (when not in SQL editor)
-			if a.resultTabs.HasTabs() && !a.isSQLEditorFocused() {
-				a.resultTabs.PrevTab()
-				// Sync SQL editor content with the active tab's SQL
-				if sql := a.resultTabs.GetActiveSQL(); sql != "" {
-					a.sqlEditor.SetContent(sql)
-				}
+		// Statements that return rows get a tab of their own
+		if msg.Result.Error == nil && len(msg.Result.Columns) > 0 {
+			a.resultTabs.AddResult(stmt.SQL, msg.Result)
+		}
+
+		failed := msg.Result.Error != nil
+		cancelled := failed && executionCancelled(run.exec, msg.Result.Error)
+		if cancelled && query.IsCanceled(msg.Result.Error) {
+			a.setCancelNotice(fmt.Sprintf("✓ statement %d cancelled by the server", msg.Index+1))
+		}
+		if len(run.results) < len(run.statements) && !cancelled && !(failed && run.stopOnError) {
+			return a, a.runScriptStatement(run)
+		}
+		return a, a.finishScript()
+
+	case QueryResultMsg:
+		// Clear execution state
+		exec := a.execution
+		a.executeCancelFn = nil
+		a.execution = nil
+
+		// The statement may have ended the transaction, e.g. COMMIT
+		if a.transaction != nil && !a.transaction.Open() {
+			a.transaction = nil
+		}
+
+		// Record query to history
+		a.recordQuery(msg.SQL, msg.Values, msg.Result)
+
+		// Handle query result
+		if msg.Result.Error != nil {
+			// Check if it was cancelled (context cancelled error)
+			if msg.Result.Error.Error() == "context canceled" {
+				// Already handled by CancelPendingQuery, just return
 				return a, nil
 			}
-			// Structure view tab switching (existing behavior)
-			if a.currentTab > 0 {
-				a.currentTab--
-				a.structureView.SwitchTab(a.currentTab)
-			}
-			return a, nil
-
-		case "]":
-			// Next result tab (when not in SQL editor)
-			if a.resultTabs.HasTabs() && !a.isSQLEditorFocused() {
-				a.resultTabs.NextTab()
-				// Sync SQL editor content with the active tab's SQL
-				if sql := a.resultTabs.GetActiveSQL(); sql != "" {
-					a.sqlEditor.SetContent(sql)
-				}
+			// Cancelled on the server at our request
+			if executionCancelled(exec, msg.Result.Error) {
+				a.resultTabs.CancelPendingQuery()
+				a.setCancelNotice(fmt.Sprintf("✓ cancelled by the server after %s", msg.Result.Duration.Round(100*time.Millisecond)))
 				return a, nil
 			}
-			// Structure view tab switching (existing behavior)
-			if a.currentTab < 3 {
-				a.currentTab++
-				a.structureView.SwitchTab(a.currentTab)
+			// Show error and remove pending tab
+			a.resultTabs.CancelPendingQuery()
+			if query.IsCanceled(msg.Result.Error) {
+				a.ShowError("Query Timeout", fmt.Sprintf("%v\n\nThe query ran longer than performance.query_timeout (%s).",
+					msg.Result.Error, a.queryTimeout()))
+				return a, nil
 			}
+			a.ShowError("Query Error", msg.Result.Error.Error())
 			return a, nil
+		}
 
-		case "1", "2", "3", "4":
-			// Switch structure view sub-tabs when active tab is TableData
-			if !a.isSQLEditorFocused() {
-				activeTab := a.resultTabs.GetActiveTab()
-				if activeTab != nil && activeTab.Type == components.TabTypeTableData && activeTab.Structure != nil {
-					tabIndex := int(msg.String()[0] - '1') // Convert "1"-"4" to 0-3
-					activeTab.Structure.SwitchTab(tabIndex)
-					return a, nil
-				}
-				// Legacy: also handle global structure view
-				if a.currentTable != "" {
-					tabIndex := int(msg.String()[0] - '1')
-					a.currentTab = tabIndex
-					a.structureView.SwitchTab(tabIndex)
-					return a, nil
-				}
-			}
+		// Complete the pending query with results
+		a.resultTabs.CompletePendingQuery(msg.SQL, msg.Result, msg.Source)
 
-		case "ctrl+p":
-			// Open SQL editor (expand if collapsed)
-			if !a.sqlEditor.IsExpanded() {
-				a.sqlEditor.Expand()
-			}
-			a.state.FocusArea = models.FocusSQLEditor
-			a.updatePanelStyles()
+		return a, nil
+
+	case QueryCancelSentMsg:
+		if msg.Exec != a.execution {
+			// The execution has finished meanwhile
 			return a, nil
-		case "ctrl+k":
-			// Open unified command palette
-			a.commandPalette.Reset()
-			a.commandPalette.SetCommands(a.getBuiltinCommands())
-			a.commandPalette.SetTables(a.getTableCommands())
-			a.commandPalette.SetHistory(a.getHistoryCommands())
-			a.showCommandPalette = true
+		}
+		if msg.Err != nil || !msg.Signalled {
+			// The server could not be asked; close the connection instead
+			a.abortExecution()
+			a.setCancelNotice("✗ cancel not confirmed by the server, connection closed")
 			return a, nil
-		case "ctrl+b":
-			// Open favorites dialog
-			if a.favoritesManager != nil {
-				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+		}
+		// The statement stops with an error that the result reports
+		return a, nil
+
+	case ResultRowsFetchedMsg:
+		a.fetchCancelFn = nil
+		if msg.Err != nil {
+			a.resultTabs.StopStreaming(msg.TabID)
+			if errors.Is(msg.Err, context.Canceled) {
+				return a, nil
 			}
-			a.showFavorites = true
+			a.ShowError("Fetch Error", fmt.Sprintf("Failed to fetch more rows:\n\n%v", msg.Err))
 			return a, nil
-		case "q", "ctrl+c":
-			// Don't quit if in help mode, exit help instead
-			if a.state.ViewMode == models.HelpMode {
-				a.state.ViewMode = models.NormalMode
+		}
+		a.resultTabs.AppendRows(msg.TabID, msg.Rows, msg.Cells, msg.Done)
+		return a, nil
+
+	case components.ApplyFilterMsg:
+		// Apply the filter and reload table data
+		a.showFilterBuilder = false
+		a.activeFilter = &msg.Filter
+
+		// Reload table with filter
+		if a.state.TreeSelected != nil && a.state.TreeSelected.Type == models.TreeNodeTypeTable {
+			return a, a.loadTableDataWithFilter(*a.activeFilter)
+		}
+		return a, nil
+
+	case components.CloseFilterBuilderMsg:
+		a.showFilterBuilder = false
+		return a, nil
+
+	case components.CloseJSONBViewerMsg:
+		a.showJSONBViewer = false
+		return a, nil
+
+	case commands.ExplainQueryCommandMsg:
+		// Explain the statement under the cursor, or the query of the active result tab
+		sql := a.sqlEditor.GetCurrentStatement()
+		if sql == "" {
+			sql = a.resultTabs.GetActiveSQL()
+		}
+		if sql == "" {
+			a.ShowError("Nothing to Explain", "Write a query in the SQL editor first (Ctrl+E).")
+			return a, nil
+		}
+		return a.explainQuery(components.ExplainQueryMsg{SQL: sql, Analyze: msg.Analyze})
+
+	case components.ExplainQueryMsg:
+		return a.explainQuery(msg)
+
+	case ExplainResultMsg:
+		if msg.Exec != a.execution {
+			// Aborted with Esc, already handled
+			return a, nil
+		}
+		a.executeCancelFn = nil
+		a.execution = nil
+		if msg.Err != nil {
+			if executionCancelled(msg.Exec, msg.Err) {
+				a.setCancelNotice(fmt.Sprintf("✓ cancelled by the server after %s", msg.Exec.Elapsed().Round(100*time.Millisecond)))
 				return a, nil
 			}
-			return a, tea.Quit
-		case "?":
-			// Toggle help
-			if a.state.ViewMode == models.HelpMode {
-				a.state.ViewMode = models.NormalMode
-			} else {
-				a.state.ViewMode = models.HelpMode
-			}
-		case "esc":
-			// Cancel executing query first
-			if a.resultTabs.HasPendingQuery() && a.executeCancelFn != nil {
-				a.executeCancelFn()
-				a.executeCancelFn = nil
-				a.resultTabs.CancelPendingQuery()
+			if query.IsCanceled(msg.Err) {
+				a.ShowError("Explain Timeout", fmt.Sprintf("%v\n\nThe statement ran longer than performance.query_timeout (%s).",
+					msg.Err, a.queryTimeout()))
 				return a, nil
 			}
-			// Exit help mode
-			if a.state.ViewMode == models.HelpMode {
-				a.state.ViewMode = models.NormalMode
-			}
-		case "c":
-			// Open connection dialog and trigger discovery
-			a.showConnectionDialog = true
-			return a, a.triggerDiscovery()
-		case "f":
-			// Open filter builder if on table view
-			if a.state.FocusArea == models.FocusDataPanel && a.state.TreeSelected != nil {
-				if a.state.TreeSelected.Type == models.TreeNodeTypeTable {
-					// Get column info from current table
-					columns := a.getTableColumns()
-					// Extract schema and table names
-					schemaNode := a.state.TreeSelected.Parent
-					if schemaNode != nil {
-						a.filterBuilder.SetColumns(columns)
-						a.filterBuilder.SetTable(schemaNode.Label, a.state.TreeSelected.Label)
-						a.showFilterBuilder = true
-					}
-				}
-			}
+			a.ShowError("Explain Failed", msg.Err.Error())
 			return a, nil
-		case "ctrl+f":
-			// Quick filter from current cell
-			if a.state.FocusArea == models.FocusDataPanel && a.tableView != nil {
-				selectedRow, selectedCol := a.tableView.GetSelectedCell()
-				if selectedRow >= 0 && selectedCol >= 0 && selectedCol < len(a.tableView.Columns) {
-					// Get column name and value
-					columnName := a.tableView.Columns[selectedCol]
-					cellValue := a.tableView.Rows[selectedRow][selectedCol]
+		}
+		a.explainViewer.Width = min(160, a.state.Width-4)
+		a.explainViewer.Height = a.state.Height - 2
+		a.explainViewer.SetPlan(msg.Plan)
+		a.showExplain = true
+		return a, nil
 
-					// Create quick filter
-					columns := a.getTableColumns()
-					var columnInfo models.ColumnInfo
-					for _, col := range columns {
-						if col.Name == columnName {
-							columnInfo = col
-							break
-						}
-					}
+	case components.CloseExplainViewerMsg:
+		a.showExplain = false
+		return a, nil
 
-					// Create filter with single condition
-					quickFilter := models.Filter{
-						Schema:    a.state.TreeSelected.Parent.Label,
-						TableName: a.state.TreeSelected.Label,
-						RootGroup: models.FilterGroup{
-							Conditions: []models.FilterCondition{
-								{
-									Column:   columnName,
-									Operator: models.OpEqual,
-									Value:    cellValue,
-									Type:     columnInfo.DataType,
-								},
-							},
-							Logic: "AND",
-						},
-					}
+	case components.CloseErrorOverlayMsg:
+		a.showError = false
+		return a, nil
 
-					a.activeFilter = &quickFilter
-					return a, a.loadTableDataWithFilter(quickFilter)
-				}
-			}
+	case components.ClosePendingChangesMsg:
+		a.showPendingChanges = false
+		return a, nil
+
+	case components.DiscardChangesMsg:
+		a.showPendingChanges = false
+		if tab := a.findTableDataTab(msg.ObjectID); tab != nil {
+			tab.Structure.GetTableView().DiscardPendingEdits()
+		}
+		return a, nil
+
+	case components.CommitChangesMsg:
+		a.showPendingChanges = false
+		return a, a.commitPendingChanges(msg)
+
+	case components.CloseInsertRowMsg:
+		a.showInsertRow = false
+		return a, nil
+
+	case components.InsertRowMsg:
+		a.showInsertRow = false
+		tab := a.findTableDataTab(msg.ObjectID)
+		if tab == nil {
+			a.ShowError("Insert Failed", "The table's tab was closed.")
 			return a, nil
-		case "ctrl+r":
-			// Refresh current table data (preserve sort and filter)
-			if a.currentTable != "" {
-				parts := strings.Split(a.currentTable, ".")
-				if len(parts) == 2 {
-					msg := LoadTableDataMsg{
-						Schema:     parts[0],
-						Table:      parts[1],
-						Limit:      100,
-						Offset:     0,
-						SortColumn: a.tableView.GetSortColumn(),
-						SortDir:    a.tableView.GetSortDirection(),
-						NullsFirst: a.tableView.GetNullsFirst(),
-					}
-					if a.activeFilter != nil {
-						return a, a.loadTableDataWithFilter(*a.activeFilter)
-					}
-					return a, a.loadTableData(msg)
-				}
+		}
+		return a, a.executeRowChanges(tab, "insert", []edit.Statement{msg.Statement})
+
+	case DeleteRowsMsg:
+		tab := a.resultTabs.GetTab(msg.TabID)
+		if tab == nil {
+			a.ShowError("Delete Failed", "The table's tab was closed.")
+			return a, nil
+		}
+		return a, a.executeRowChanges(tab, "delete", msg.Statements)
+
+	case components.ConfirmDialogResultMsg:
+		a.showConfirm = false
+		if msg.Confirmed && msg.Action != nil {
+			action := msg.Action
+			return a, func() tea.Msg { return action }
+		}
+		return a, nil
+
+	case RowChangesResultMsg:
+		if msg.Err != nil {
+			title := "Delete Failed"
+			if msg.Action == "insert" {
+				title = "Insert Failed"
 			}
+			a.ShowError(title, fmt.Sprintf("No changes were saved:\n\n%v", msg.Err))
 			return a, nil
-		case "ctrl+x":
-			// Clear filter and reload
-			if a.activeFilter != nil && a.state.TreeSelected != nil {
-				a.activeFilter = nil
-				schemaNode := a.state.TreeSelected.Parent
-				if schemaNode != nil {
-					return a, a.loadTableData(LoadTableDataMsg{
-						Schema:     schemaNode.Label,
-						Table:      a.state.TreeSelected.Label,
-						Limit:      100,
-						Offset:     0,
-						SortColumn: a.tableView.GetSortColumn(),
-						SortDir:    a.tableView.GetSortDirection(),
-						NullsFirst: a.tableView.GetNullsFirst(),
-					})
+		}
+		// Reload the tab so it reflects the new table contents
+		if tab := a.resultTabs.GetTab(msg.TabID); tab != nil && tab.Structure != nil {
+			return a, a.loadTableDataForTab(tab, tab.Structure.GetSchema(), tab.Structure.GetTable())
+		}
+		return a, nil
+
+	case CommitChangesResultMsg:
+		if msg.Err != nil {
+			a.ShowError("Commit Failed", fmt.Sprintf("No changes were saved:\n\n%v", msg.Err))
+			return a, nil
+		}
+		if tab := a.resultTabs.GetTab(msg.TabID); tab != nil && tab.Structure != nil {
+			tab.Structure.GetTableView().ApplyPendingEdits()
+		}
+		return a, nil
+
+	case components.PasswordSubmitMsg:
+		// User submitted password from password dialog
+		a.showPasswordDialog = false
+		if a.pendingConnection != nil {
+			// Complete the connection config with the entered password
+			config := *a.pendingConnection
+			config.Password = msg.Password
+
+			// Try to save the password for future use
+			if a.connectionHistory != nil {
+				if err := a.connectionHistory.SavePassword(config.Host, config.Port, config.Database, config.User, config.Password); err != nil {
+					log.Printf("Warning: Failed to save password: %v", err)
 				}
 			}
+
+			a.pendingConnection = nil
+			return a.performConnection(config)
+		}
+		return a, nil
+
+	case components.PasswordCancelMsg:
+		// User cancelled password dialog
+		a.showPasswordDialog = false
+		a.pendingConnection = nil
+		a.dropStartup()
+		// Re-show connection dialog
+		a.showConnectionDialog = true
+		return a, nil
+
+	case components.CloseCommandPaletteMsg:
+		a.showCommandPalette = false
+		return a, nil
+
+	case components.ExecuteFavoriteMsg:
+		// Execute favorite query
+		if a.state.ActiveConnection == nil {
+			a.ShowError("No Database Connection", "Please connect to a database before executing queries.\n\nPress 'c' to open the connection dialog.")
 			return a, nil
-		case "y":
-			// Copy functionality in structure view (copy name)
-			if a.currentTab > 0 {
-				statusMsg := a.structureView.CopyCurrentName()
-				if statusMsg != "" {
-					// Show status message (log.Println is acceptable per plan)
-					log.Println(statusMsg)
-				}
-				return a, nil
+		}
+
+		// Ask for the values of bind variables, starting from the last ones
+		a.showFavorites = false
+		if msg.Values == nil {
+			if names := query.Placeholders(msg.Favorite.Query); len(names) > 0 {
+				return a, a.promptBindValues(msg.Favorite.Query, names, msg.Favorite.Parameters, msg)
 			}
-		case "Y":
-			// Copy functionality in structure view (copy definition)
-			if a.currentTab > 0 {
-				statusMsg := a.structureView.CopyCurrentDefinition()
-				if statusMsg != "" {
-					log.Println(statusMsg)
-				}
-				return a, nil
+		}
+		if _, _, err := query.Bind(msg.Favorite.Query, msg.Values); err != nil {
+			a.ShowError("Query Error", err.Error())
+			return a, nil
+		}
+
+		// Record usage and the values entered
+		if a.favoritesManager != nil {
+			if err := a.favoritesManager.RecordUsage(msg.Favorite.ID); err != nil {
+				// Log error but don't block execution
+				log.Printf("Warning: Failed to record favorite usage: %v", err)
 			}
-		case "tab":
-			// Only handle tab in normal mode
-			if a.state.ViewMode == models.NormalMode {
-				if a.isEditingText() {
-					// In edit mode, Tab inserts indentation
-					if a.showCodeEditor && a.codeEditor != nil && !a.codeEditor.ReadOnly {
-						a.codeEditor.Update(msg)
-					} else if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
-						a.sqlEditor.Update(msg)
-					}
-				} else {
-					// Normal mode: cycle focus TreeView -> DataPanel -> SQLEditor -> TreeView
-					a.nextFocus()
+			if msg.Values != nil {
+				if err := a.favoritesManager.SetParameters(msg.Favorite.ID, msg.Values); err != nil {
+					log.Printf("Warning: Failed to save favorite parameters: %v", err)
 				}
-				return a, nil
-			}
-		case "shift+tab", "backtab":
-			// Reverse focus cycle (only when not editing)
-			if a.state.ViewMode == models.NormalMode && !a.isEditingText() {
-				a.prevFocus()
-				return a, nil
-			}
-		default:
-			// Handle tree navigation when TreeView is focused
-			if a.state.FocusArea == models.FocusTreeView && a.state.ViewMode == models.NormalMode {
-				var cmd tea.Cmd
-				a.treeView, cmd = a.treeView.Update(msg)
-				return a, cmd
 			}
+		}
 
-			// Handle table navigation when DataPanel is focused
-			if a.state.FocusArea == models.FocusDataPanel && a.state.ViewMode == models.NormalMode {
-				// Get the active table view (Result Tabs, Structure View, or main TableView)
-				activeTable := a.getActiveTableView()
+		// Run it like the editor's query, so it can be cancelled
+		next := components.ExecuteQueryMsg{SQL: msg.Favorite.Query, Values: msg.Values}
+		return a, func() tea.Msg {
+			return next
+		}
+
+	case components.BindValuesMsg:
+		a.showBindDialog = false
+		var next tea.Msg
+		switch action := msg.Action.(type) {
+		case components.ExecuteQueryMsg:
+			action.Values = msg.Values
+			a.rememberBindValues(msg.Values)
+			next = action
+		case components.ExecuteScriptMsg:
+			action.Values = msg.Values
+			a.rememberBindValues(msg.Values)
+			next = action
+		case components.ExecuteFavoriteMsg:
+			action.Values = msg.Values
+			next = action
+		case components.ExplainQueryMsg:
+			action.Values = msg.Values
+			a.rememberBindValues(msg.Values)
+			next = action
+		}
+		return a, func() tea.Msg { return next }
+
+	case components.CloseBindDialogMsg:
+		a.showBindDialog = false
+		return a, nil
 
-				// Handle preview pane scrolling (when visible)
-				if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
-					switch msg.String() {
-					case "ctrl+up":
-						activeTable.PreviewPane.ScrollUp()
-						return a, nil
-					case "ctrl+down":
-						activeTable.PreviewPane.ScrollDown()
-						return a, nil
-					}
-				}
+	case components.CloseFavoritesDialogMsg:
+		a.showFavorites = false
+		return a, nil
 
-				// Handle preview pane toggle
-				if msg.String() == "p" {
-					if activeTable != nil {
-						activeTable.TogglePreviewPane()
-					}
-					return a, nil
-				}
+	case commands.ManageConnectionsCommandMsg:
+		if a.profilesManager != nil {
+			a.profilesDialog.SetProfiles(a.profilesManager.GetAll())
+		}
+		a.showConnectionDialog = false
+		a.showProfiles = true
+		return a, nil
 
-				// Handle yank: y = copy current cell, Y = copy preview pane content
-				if msg.String() == "y" {
-					if activeTable != nil {
-						row, col := activeTable.GetSelectedCell()
-						if row >= 0 && col >= 0 && row < len(activeTable.Rows) && col < len(activeTable.Rows[row]) {
-							cellContent := activeTable.Rows[row][col]
-							if err := clipboard.WriteAll(cellContent); err == nil {
-								log.Println("Copied cell content to clipboard")
-							}
-						}
-					}
-					return a, nil
-				}
-				if msg.String() == "Y" {
-					if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
-						if err := activeTable.PreviewPane.CopyContent(); err == nil {
-							log.Println("Copied preview content to clipboard")
-						}
-					}
-					return a, nil
-				}
+	case commands.ConnectProfileCommandMsg:
+		if a.transaction != nil {
+			return a, a.confirmEndTransaction("Connect", msg)
+		}
+		return a.connectProfile(msg.Name)
 
-				// If structure view is active (not on Data tab) and no Result Tabs, route to structure view
-				if a.currentTab > 0 && !a.resultTabs.HasTabs() {
-					a.structureView.Update(msg)
-					return a, nil
-				}
+	case components.ConnectProfileMsg:
+		if a.transaction != nil {
+			return a, a.confirmEndTransaction("Connect", msg)
+		}
+		return a.connectProfile(msg.Name)
 
-				// Skip navigation handling if no active table
-				if activeTable == nil {
-					return a, nil
+	case components.SaveProfileMsg:
+		if a.profilesManager == nil {
+			a.ShowError("Profiles Not Available", "Connection profiles are not initialized.\n\nPlease restart the application.")
+			return a, nil
+		}
+		if err := a.profilesManager.Put(msg.Profile, msg.OldName); err != nil {
+			a.ShowError("Cannot Save Connection", fmt.Sprintf("Failed to save connection profile:\n\n%v", err))
+			return a, nil
+		}
+		a.profilesDialog.SetProfiles(a.profilesManager.GetAll())
+		return a, nil
+
+	case components.DeleteProfileMsg:
+		if a.profilesManager == nil {
+			a.ShowError("Profiles Not Available", "Connection profiles are not initialized.\n\nPlease restart the application.")
+			return a, nil
+		}
+		if err := a.profilesManager.Delete(msg.Name); err != nil {
+			a.ShowError("Cannot Delete Connection", fmt.Sprintf("Failed to delete connection profile:\n\n%v", err))
+			return a, nil
+		}
+		a.profilesDialog.SetProfiles(a.profilesManager.GetAll())
+		return a, nil
+
+	case components.CloseProfilesDialogMsg:
+		a.showProfiles = false
+		return a, nil
+
+	case components.SearchInputMsg:
+		// Handle search request from search input
+		a.showSearch = false
+		if msg.Query == "" {
+			return a, nil
+		}
+
+		// Get the active table view (Result Tabs or main TableView)
+		activeTable := a.getActiveTableView()
+
+		if msg.Mode == "local" {
+			// Local search - search only loaded data
+			if activeTable != nil {
+				activeTable.SearchLocal(msg.Query)
+			}
+		} else {
+			// For Result Tabs, always use local search (data is already loaded)
+			if a.resultTabs.HasTabs() {
+				if activeTable != nil {
+					activeTable.SearchLocal(msg.Query)
 				}
+				return a, nil
+			}
 
-				// Toggle relative line numbers
-				if msg.String() == "ctrl+n" {
-					activeTable.ToggleRelativeNumbers()
-					return a, nil
+			// Table search - query the database (only for table browser)
+			if a.state.ActiveConnection == nil {
+				a.ShowError("No Connection", "Please connect to a database first")
+				return a, nil
+			}
+
+			if a.currentTable == "" {
+				a.ShowError("No Table", "Please select a table first")
+				return a, nil
+			}
+
+			// Execute table search
+			return a, a.searchTable(msg.Query)
+		}
+		return a, nil
+
+	case components.CloseSearchMsg:
+		a.showSearch = false
+		a.searchInput.Reset()
+		return a, nil
+
+	case SearchTableResultMsg:
+		if msg.Err != nil {
+			a.ShowError("Search Error", msg.Err.Error())
+			return a, nil
+		}
+
+		if msg.Data == nil || len(msg.Data.Rows) == 0 {
+			a.ShowError("No Results", fmt.Sprintf("No matches found for '%s'", msg.Query))
+			return a, nil
+		}
+
+		// Replace table data with search results
+		a.tableView.SetData(msg.Data.Columns, msg.Data.Rows, int(msg.Data.TotalRows))
+		a.tableView.SetCells(msg.Data.Cells)
+
+		// Build matches from all cells that contain the query
+		queryLower := strings.ToLower(msg.Query)
+		var matches []components.MatchPos
+		for rowIdx, row := range msg.Data.Rows {
+			for colIdx, cell := range row {
+				if strings.Contains(strings.ToLower(cell), queryLower) {
+					matches = append(matches, components.MatchPos{Row: rowIdx, Col: colIdx})
 				}
+			}
+		}
 
-				// Handle Vim motion (number prefixes, g, G, etc.)
-				// This must come before individual key handling
-				if activeTable.HandleVimMotion(msg.String()) {
-					// Check if we need to load more data after vim motion (only for main table)
-					if !a.resultTabs.HasTabs() {
-						if cmd := a.checkLazyLoad(); cmd != nil {
-							return a, cmd
-						}
+		a.tableView.SetSearchResults(msg.Query, matches)
+		return a, nil
+
+	case components.AddFavoriteMsg:
+		if a.favoritesManager != nil {
+			conn := ""
+			if a.state.ActiveConnection != nil {
+				conn = a.state.ActiveConnection.Config.Name
+			}
+			db := a.state.CurrentDatabase
+			_, err := a.favoritesManager.Add(msg.Name, msg.Description, msg.Query, conn, db, msg.Tags)
+			if err != nil {
+				a.ShowError("Cannot Add Favorite", fmt.Sprintf("Failed to add favorite:\n\n%v\n\nPlease check your input and try again.", err))
+			} else {
+				// Refresh the dialog
+				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+			}
+		} else {
+			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+		}
+		return a, nil
+
+	case components.EditFavoriteMsg:
+		if a.favoritesManager != nil {
+			err := a.favoritesManager.Update(msg.FavoriteID, msg.Name, msg.Description, msg.Query, msg.Tags)
+			if err != nil {
+				a.ShowError("Cannot Update Favorite", fmt.Sprintf("Failed to update favorite:\n\n%v\n\nPlease check your input and try again.", err))
+			} else {
+				// Refresh the dialog
+				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+			}
+		} else {
+			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+		}
+		return a, nil
+
+	case components.DeleteFavoriteMsg:
+		if a.favoritesManager != nil {
+			err := a.favoritesManager.Delete(msg.FavoriteID)
+			if err != nil {
+				a.ShowError("Cannot Delete Favorite", fmt.Sprintf("Failed to delete favorite:\n\n%v\n\nThe favorite may have already been deleted.", err))
+			} else {
+				// Refresh the dialog
+				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+				// Adjust selection if needed
+				if a.favoritesDialog != nil {
+					favorites := a.favoritesManager.GetAll()
+					if len(favorites) > 0 {
+						// Keep selection valid
+						a.favoritesDialog.SetFavorites(favorites)
 					}
-					return a, nil
 				}
+			}
+		} else {
+			a.ShowError("Favorites Not Available", "Favorites manager is not initialized.\n\nPlease restart the application.")
+		}
+		return a, nil
 
-				switch msg.String() {
-				case "up":
-					activeTable.MoveSelection(-1)
-					return a, nil
-				case "down":
-					activeTable.MoveSelection(1)
-					if !a.resultTabs.HasTabs() {
-						if cmd := a.checkLazyLoad(); cmd != nil {
-							return a, cmd
-						}
-					}
-					return a, nil
-				case "left", "h":
-					activeTable.MoveSelectionHorizontal(-1)
-					return a, nil
-				case "right", "l":
-					activeTable.MoveSelectionHorizontal(1)
-					return a, nil
-				case "H":
-					// Jump scroll left (half screen)
-					activeTable.JumpScrollHorizontal(-1)
-					return a, nil
-				case "L":
-					// Jump scroll right (half screen)
-					activeTable.JumpScrollHorizontal(1)
-					return a, nil
-				case "0":
-					// Jump to first column
-					activeTable.JumpToFirstColumn()
-					return a, nil
-				case "$":
-					// Jump to last column
-					activeTable.JumpToLastColumn()
-					return a, nil
-				case "ctrl+u":
-					activeTable.PageUp()
-					return a, nil
-				case "ctrl+d":
-					activeTable.PageDown()
-					if !a.resultTabs.HasTabs() {
-						if cmd := a.checkLazyLoad(); cmd != nil {
-							return a, cmd
-						}
-					}
-					return a, nil
-				case "s":
-					// Sort by current column (only for main table browsing, not result tabs)
-					if !a.resultTabs.HasTabs() {
-						activeTable.ToggleSort()
-						// Reload data with new sort
-						if a.currentTable != "" {
+	case ErrorMsg:
+		// Handle error messages
+		a.ShowError(msg.Title, msg.Message)
+		return a, nil
+
+	case tea.KeyMsg:
+		// Handle error overlay dismissal first if visible
+		if a.showError {
+			key := msg.String()
+			if key == "esc" || key == "enter" {
+				a.DismissError()
+				return a, nil
+			}
+			// Allow quit keys to pass through even when error is showing
+			if key == "q" || key == "ctrl+c" {
+				a.DismissError()
+				return a, a.quit()
+			}
+			// Consume all other keys when error is showing
+			return a, nil
+		}
+
+		// Handle connection dialog if visible
+		if a.showConnectionDialog {
+			return a.handleConnectionDialog(msg)
+		}
+
+		// Handle password dialog if visible
+		if a.showPasswordDialog {
+			var cmd tea.Cmd
+			a.passwordDialog, cmd = a.passwordDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle command palette if visible
+		if a.showCommandPalette {
+			return a.handleCommandPalette(msg)
+		}
+
+		// Handle filter builder input
+		if a.showFilterBuilder {
+			return a.handleFilterBuilder(msg)
+		}
+
+		// Handle JSONB viewer input
+		if a.showJSONBViewer {
+			return a.handleJSONBViewer(msg)
+		}
+
+		// Handle execution plan viewer input
+		if a.showExplain {
+			var cmd tea.Cmd
+			a.explainViewer, cmd = a.explainViewer.Update(msg)
+			return a, cmd
+		}
+
+		// Handle query history browser input
+		if a.showHistory {
+			var cmd tea.Cmd
+			a.historyDialog, cmd = a.historyDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle favorites dialog if visible
+		if a.showFavorites {
+			return a.handleFavoritesDialog(msg)
+		}
+
+		// Handle connection profiles dialog if visible
+		if a.showProfiles {
+			var cmd tea.Cmd
+			a.profilesDialog, cmd = a.profilesDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle search input if visible
+		if a.showSearch {
+			return a.handleSearchInput(msg)
+		}
+
+		// Handle confirm dialog if visible
+		if a.showConfirm {
+			var cmd tea.Cmd
+			a.confirmDialog, cmd = a.confirmDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle referencing tables list input
+		if a.showReferences {
+			var cmd tea.Cmd
+			a.referencesDialog, cmd = a.referencesDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle server activity monitor input, below its confirmations
+		if a.showActivity {
+			var cmd tea.Cmd
+			a.activityDialog, cmd = a.activityDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle ER diagram input if visible
+		if a.showERDiagram {
+			var cmd tea.Cmd
+			a.erDiagram, cmd = a.erDiagram.Update(msg)
+			return a, cmd
+		}
+
+		// Handle schema diff input if visible
+		if a.showSchemaDiffDialog {
+			var cmd tea.Cmd
+			a.schemaDiffDialog, cmd = a.schemaDiffDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showSchemaDiff {
+			var cmd tea.Cmd
+			a.schemaDiff, cmd = a.schemaDiff.Update(msg)
+			return a, cmd
+		}
+
+		// Handle export dialog if visible
+		if a.showExport {
+			var cmd tea.Cmd
+			a.exportDialog, cmd = a.exportDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle import dialog if visible
+		if a.showImport {
+			var cmd tea.Cmd
+			a.importDialog, cmd = a.importDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle insert row form if visible
+		if a.showInsertRow {
+			var cmd tea.Cmd
+			a.insertRowDialog, cmd = a.insertRowDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle bind variable prompt if visible
+		if a.showBindDialog {
+			var cmd tea.Cmd
+			a.bindDialog, cmd = a.bindDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Handle pending changes dialog if visible
+		if a.showPendingChanges {
+			var cmd tea.Cmd
+			a.pendingChangesDialog, cmd = a.pendingChangesDialog.Update(msg)
+			return a, cmd
+		}
+
+		// Route all keys to the table while a cell is being edited
+		if a.state.FocusArea == models.FocusDataPanel {
+			if activeTable := a.getActiveTableView(); activeTable != nil && activeTable.IsEditingCell() {
+				activeTable.HandleCellEditKey(msg)
+				return a, nil
+			}
+		}
+
+		// Handle TreeView search mode - route keys to TreeView
+		// This must come before global key handlers to capture typing during search
+		// and to allow Esc to clear filter in SearchFilterActive mode
+		if a.treeView != nil && a.state.FocusArea == models.FocusTreeView {
+			// In SearchInputting mode, route ALL keys to TreeView
+			if a.treeView.IsSearchInputting() {
+				var cmd tea.Cmd
+				a.treeView, cmd = a.treeView.Update(msg)
+				return a, cmd
+			}
+			// In SearchFilterActive mode, route Esc and / to TreeView
+			if a.treeView.IsSearchActive() && (msg.String() == "esc" || msg.String() == "/") {
+				var cmd tea.Cmd
+				a.treeView, cmd = a.treeView.Update(msg)
+				return a, cmd
+			}
+		}
+
+		// Handle code editor input if visible and DataPanel is focused
+		if a.showCodeEditor && a.codeEditor != nil && a.state.FocusArea == models.FocusDataPanel {
+			// Tab is handled in the unified Tab case below, skip here
+			if msg.String() != "tab" && msg.String() != "shift+tab" && msg.String() != "backtab" {
+				_, cmd := a.codeEditor.Update(msg)
+				return a, cmd
+			}
+		}
+
+		// Commit and rollback work from the editor and the panels alike
+		if a.transaction != nil {
+			switch msg.String() {
+			case "alt+c":
+				return a, a.endTransaction(a.transaction, true, nil)
+			case "alt+r":
+				return a, a.endTransaction(a.transaction, false, nil)
+			}
+		}
+
+		// If SQL editor is focused, handle input
+		if a.isSQLEditorFocused() {
+			// The completion popup handles Esc and Tab itself
+			if a.sqlEditor.CompletionVisible() && (msg.String() == "esc" || msg.String() == "tab") {
+				_, cmd := a.sqlEditor.Update(msg)
+				return a, cmd
+			}
+
+			// Handle escape to cancel a running EXPLAIN or query, or unfocus
+			if msg.String() == "esc" {
+				if a.execution != nil && a.executeCancelFn != nil {
+					return a, a.cancelExecution()
+				}
+				if a.sqlEditor.IsExpanded() {
+					a.sqlEditor.Collapse()
+				}
+				a.state.FocusArea = models.FocusDataPanel
+				a.updatePanelStyles()
+				return a, nil
+			}
+
+			// Handle ctrl+e to toggle expand/collapse
+			if msg.String() == "ctrl+e" {
+				if a.sqlEditor.IsExpanded() {
+					a.sqlEditor.Collapse()
+					a.state.FocusArea = models.FocusDataPanel
+					a.updatePanelStyles()
+				} else {
+					a.sqlEditor.Expand()
+				}
+				return a, nil
+			}
+
+			// Tab is handled in the unified Tab case below for focus cycling
+			if msg.String() == "tab" || msg.String() == "shift+tab" || msg.String() == "backtab" {
+				// Let Tab fall through to the switch case for focus cycling
+			} else if a.sqlEditor.IsExpanded() {
+				// Route other keys to SQL editor when expanded
+				_, cmd := a.sqlEditor.Update(msg)
+				return a, cmd
+			} else if isPrintableInput(msg.String()) {
+				// Auto-expand and route printable input when collapsed but focused
+				a.sqlEditor.Expand()
+				_, cmd := a.sqlEditor.Update(msg)
+				return a, cmd
+			}
+		}
+
+		switch msg.String() {
+		// Ctrl+E to toggle SQL editor expand/collapse
+		case "ctrl+e":
+			a.sqlEditor.Toggle()
+			if a.sqlEditor.IsExpanded() {
+				a.state.FocusArea = models.FocusSQLEditor
+				a.updatePanelStyles()
+			}
+			return a, nil
+
+		// Ctrl+Shift+Up to increase editor height preset
+		case "ctrl+shift+up":
+			if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
+				a.sqlEditor.IncreaseHeight()
+			}
+			return a, nil
+
+		// Ctrl+Shift+Down to decrease editor height preset
+		case "ctrl+shift+down":
+			if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
+				a.sqlEditor.DecreaseHeight()
+			}
+			return a, nil
+
+		// Tab switching with [ and ] (like lazygit)
+		case "[":
+			// Previous result tab (when not in SQL editor)
+			if a.resultTabs.HasTabs() && !a.isSQLEditorFocused() {
+				a.resultTabs.PrevTab()
+				a.useTabConnection()
+				// Sync SQL editor content with the active tab's SQL
+				if sql := a.resultTabs.GetActiveSQL(); sql != "" {
+					a.sqlEditor.SetContent(sql)
+				}
+				return a, nil
+			}
+			// Structure view tab switching (existing behavior)
+			if a.currentTab > 0 {
+				a.currentTab--
+				a.structureView.SwitchTab(a.currentTab)
+			}
+			return a, nil
+
+		case "]":
+			// Next result tab (when not in SQL editor)
+			if a.resultTabs.HasTabs() && !a.isSQLEditorFocused() {
+				a.resultTabs.NextTab()
+				a.useTabConnection()
+				// Sync SQL editor content with the active tab's SQL
+				if sql := a.resultTabs.GetActiveSQL(); sql != "" {
+					a.sqlEditor.SetContent(sql)
+				}
+				return a, nil
+			}
+			// Structure view tab switching (existing behavior)
+			if a.currentTab < 4 {
+				a.currentTab++
+				a.structureView.SwitchTab(a.currentTab)
+			}
+			return a, nil
+
+		case "1", "2", "3", "4", "5":
+			// Switch structure view sub-tabs when active tab is TableData
+			if !a.isSQLEditorFocused() {
+				activeTab := a.resultTabs.GetActiveTab()
+				if activeTab != nil && activeTab.Type == components.TabTypeTableData && activeTab.Structure != nil {
+					tabIndex := int(msg.String()[0] - '1') // Convert "1"-"5" to 0-4
+					activeTab.Structure.SwitchTab(tabIndex)
+					return a, nil
+				}
+				// Legacy: also handle global structure view
+				if a.currentTable != "" {
+					tabIndex := int(msg.String()[0] - '1')
+					a.currentTab = tabIndex
+					a.structureView.SwitchTab(tabIndex)
+					return a, nil
+				}
+			}
+
+		case "ctrl+p":
+			// Open SQL editor (expand if collapsed)
+			if !a.sqlEditor.IsExpanded() {
+				a.sqlEditor.Expand()
+			}
+			a.state.FocusArea = models.FocusSQLEditor
+			a.updatePanelStyles()
+			return a, nil
+		case "ctrl+k":
+			// Open unified command palette
+			a.commandPalette.Reset()
+			a.commandPalette.SetCommands(a.getBuiltinCommands())
+			a.commandPalette.SetTables(a.getTableCommands())
+			a.commandPalette.SetHistory(a.getHistoryCommands())
+			a.showCommandPalette = true
+			return a, nil
+		case "ctrl+b":
+			// Open favorites dialog
+			if a.favoritesManager != nil {
+				a.favoritesDialog.SetFavorites(a.favoritesManager.GetAll())
+			}
+			a.showFavorites = true
+			return a, nil
+		case "ctrl+y":
+			// Open query history browser
+			return a, a.openHistoryDialog()
+		case "q", "ctrl+c":
+			// Don't quit if in help mode, exit help instead
+			if a.state.ViewMode == models.HelpMode {
+				a.state.ViewMode = models.NormalMode
+				return a, nil
+			}
+			return a, a.quit()
+		case "?":
+			// Toggle help
+			if a.state.ViewMode == models.HelpMode {
+				a.state.ViewMode = models.NormalMode
+			} else {
+				a.state.ViewMode = models.HelpMode
+			}
+		case "esc":
+			// Cancel executing query or EXPLAIN first
+			if (a.resultTabs.HasPendingQuery() || a.execution != nil) && a.executeCancelFn != nil {
+				return a, a.cancelExecution()
+			}
+			// Then stop fetching more rows of a streamed result
+			if a.fetchCancelFn != nil {
+				a.fetchCancelFn()
+				a.fetchCancelFn = nil
+				return a, nil
+			}
+			// Exit help mode
+			if a.state.ViewMode == models.HelpMode {
+				a.state.ViewMode = models.NormalMode
+			}
+		case "c":
+			// Open connection dialog and trigger discovery
+			if a.transaction != nil {
+				return a, a.confirmEndTransaction("Connect", commands.ConnectCommandMsg{})
+			}
+			a.showConnectionDialog = true
+			return a, a.triggerDiscovery()
+		case "f":
+			// Open filter builder if on table view
+			if a.state.FocusArea == models.FocusDataPanel && a.state.TreeSelected != nil {
+				if a.state.TreeSelected.Type == models.TreeNodeTypeTable {
+					// Get column info from current table
+					columns := a.getTableColumns()
+					// Extract schema and table names
+					schemaNode := a.state.TreeSelected.Parent
+					if schemaNode != nil {
+						a.filterBuilder.SetColumns(columns)
+						a.filterBuilder.SetTable(schemaNode.Label, a.state.TreeSelected.Label)
+						a.showFilterBuilder = true
+					}
+				}
+			}
+			return a, nil
+		case "ctrl+f":
+			// Quick filter from current cell
+			if a.state.FocusArea == models.FocusDataPanel && a.tableView != nil {
+				selectedRow, selectedCol := a.tableView.GetSelectedCell()
+				if selectedRow >= 0 && selectedCol >= 0 && selectedCol < len(a.tableView.Columns) {
+					// Get column name and value
+					columnName := a.tableView.Columns[selectedCol]
+					cellValue := a.tableView.Rows[selectedRow][selectedCol]
+
+					// Create quick filter
+					columns := a.getTableColumns()
+					var columnInfo models.ColumnInfo
+					for _, col := range columns {
+						if col.Name == columnName {
+							columnInfo = col
+							break
+						}
+					}
+
+					// Create filter with single condition; NULL cells match IS NULL
+					condition := models.FilterCondition{
+						Column:   columnName,
+						Operator: models.OpEqual,
+						Value:    cellValue,
+						Type:     columnInfo.CastType(),
+					}
+					if a.tableView.IsNullCell(selectedRow, selectedCol) {
+						condition.Operator = models.OpIsNull
+						condition.Value = nil
+					}
+					quickFilter := models.Filter{
+						Schema:    a.state.TreeSelected.Parent.Label,
+						TableName: a.state.TreeSelected.Label,
+						RootGroup: models.FilterGroup{
+							Conditions: []models.FilterCondition{condition},
+							Logic: "AND",
+						},
+					}
+
+					a.activeFilter = &quickFilter
+					return a, a.loadTableDataWithFilter(quickFilter)
+				}
+			}
+			return a, nil
+		case "ctrl+r":
+			// Refresh current table data (preserve sort and filter)
+			if a.currentTable != "" {
+				parts := strings.Split(a.currentTable, ".")
+				if len(parts) == 2 {
+					msg := LoadTableDataMsg{
+						Schema:     parts[0],
+						Table:      parts[1],
+						Limit:      100,
+						Offset:     0,
+						SortColumn: a.tableView.GetSortColumn(),
+						SortDir:    a.tableView.GetSortDirection(),
+						NullsFirst: a.tableView.GetNullsFirst(),
+					}
+					if a.activeFilter != nil {
+						return a, a.loadTableDataWithFilter(*a.activeFilter)
+					}
+					return a, a.loadTableData(msg)
+				}
+			}
+			return a, nil
+		case "ctrl+x":
+			// Clear filter and reload
+			if a.activeFilter != nil && a.state.TreeSelected != nil {
+				a.activeFilter = nil
+				schemaNode := a.state.TreeSelected.Parent
+				if schemaNode != nil {
+					return a, a.loadTableData(LoadTableDataMsg{
+						Schema:     schemaNode.Label,
+						Table:      a.state.TreeSelected.Label,
+						Limit:      100,
+						Offset:     0,
+						SortColumn: a.tableView.GetSortColumn(),
+						SortDir:    a.tableView.GetSortDirection(),
+						NullsFirst: a.tableView.GetNullsFirst(),
+					})
+				}
+			}
+			return a, nil
+		case "y":
+			// Copy functionality in structure view (copy name)
+			if a.currentTab > 0 {
+				statusMsg := a.structureView.CopyCurrentName()
+				if statusMsg != "" {
+					// Show status message (log.Println is acceptable per plan)
+					log.Println(statusMsg)
+				}
+				return a, nil
+			}
+		case "Y":
+			// Copy functionality in structure view (copy definition)
+			if a.currentTab > 0 {
+				statusMsg := a.structureView.CopyCurrentDefinition()
+				if statusMsg != "" {
+					log.Println(statusMsg)
+				}
+				return a, nil
+			}
+		case "tab":
+			// Only handle tab in normal mode
+			if a.state.ViewMode == models.NormalMode {
+				if a.isEditingText() {
+					// In edit mode, Tab inserts indentation
+					if a.showCodeEditor && a.codeEditor != nil && !a.codeEditor.ReadOnly {
+						a.codeEditor.Update(msg)
+					} else if a.isSQLEditorFocused() && a.sqlEditor.IsExpanded() {
+						a.sqlEditor.Update(msg)
+					}
+				} else {
+					// Normal mode: cycle focus TreeView -> DataPanel -> SQLEditor -> TreeView
+					a.nextFocus()
+				}
+				return a, nil
+			}
+		case "shift+tab", "backtab":
+			// Reverse focus cycle (only when not editing)
+			if a.state.ViewMode == models.NormalMode && !a.isEditingText() {
+				a.prevFocus()
+				return a, nil
+			}
+		default:
+			// Handle tree navigation when TreeView is focused
+			if a.state.FocusArea == models.FocusTreeView && a.state.ViewMode == models.NormalMode {
+				if msg.String() == "I" {
+					// Import a file into the selected table
+					return a.openImportDialog()
+				}
+				if msg.String() == "D" {
+					// Diagram the schema of the selected node
+					return a, a.openERDiagram()
+				}
+				var cmd tea.Cmd
+				a.treeView, cmd = a.treeView.Update(msg)
+				return a, cmd
+			}
+
+			// Handle table navigation when DataPanel is focused
+			if a.state.FocusArea == models.FocusDataPanel && a.state.ViewMode == models.NormalMode {
+				// Get the active table view (Result Tabs, Structure View, or main TableView)
+				activeTable := a.getActiveTableView()
+
+				// Handle preview pane scrolling (when visible)
+				if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
+					switch msg.String() {
+					case "ctrl+up":
+						activeTable.PreviewPane.ScrollUp()
+						return a, nil
+					case "ctrl+down":
+						activeTable.PreviewPane.ScrollDown()
+						return a, nil
+					}
+				}
+
+				// Handle preview pane toggle
+				if msg.String() == "p" {
+					if activeTable != nil {
+						activeTable.TogglePreviewPane()
+					}
+					return a, nil
+				}
+
+				// Handle yank: y = copy current cell, Y = copy preview pane content
+				if msg.String() == "y" {
+					if activeTable != nil {
+						row, col := activeTable.GetSelectedCell()
+						if row >= 0 && col >= 0 && row < len(activeTable.Rows) && col < len(activeTable.Rows[row]) {
+							cellContent := activeTable.Rows[row][col]
+							if err := clipboard.WriteAll(cellContent); err == nil {
+								log.Println("Copied cell content to clipboard")
+							}
+						}
+					}
+					return a, nil
+				}
+				if msg.String() == "Y" {
+					if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
+						if err := activeTable.PreviewPane.CopyContent(); err == nil {
+							log.Println("Copied preview content to clipboard")
+						}
+					}
+					return a, nil
+				}
+
+				// If structure view is active (not on Data tab) and no Result Tabs, route to structure view
+				if a.currentTab > 0 && !a.resultTabs.HasTabs() {
+					a.structureView.Update(msg)
+					return a, nil
+				}
+
+				// Skip navigation handling if no active table
+				if activeTable == nil {
+					return a, nil
+				}
+
+				// Toggle relative line numbers
+				if msg.String() == "ctrl+n" {
+					activeTable.ToggleRelativeNumbers()
+					return a, nil
+				}
+
+				// Handle Vim motion (number prefixes, g, G, etc.)
+				// This must come before individual key handling
+				if activeTable.HandleVimMotion(msg.String()) {
+					// Check if we need to load more data after vim motion
+					if cmd := a.checkLazyLoad(); cmd != nil {
+						return a, cmd
+					}
+					return a, nil
+				}
+
+				switch msg.String() {
+				case "up":
+					activeTable.MoveSelection(-1)
+					return a, nil
+				case "down":
+					activeTable.MoveSelection(1)
+					if cmd := a.checkLazyLoad(); cmd != nil {
+						return a, cmd
+					}
+					return a, nil
+				case "left", "h":
+					activeTable.MoveSelectionHorizontal(-1)
+					return a, nil
+				case "right", "l":
+					activeTable.MoveSelectionHorizontal(1)
+					return a, nil
+				case "H":
+					// Jump scroll left (half screen)
+					activeTable.JumpScrollHorizontal(-1)
+					return a, nil
+				case "L":
+					// Jump scroll right (half screen)
+					activeTable.JumpScrollHorizontal(1)
+					return a, nil
+				case "0":
+					// Jump to first column
+					activeTable.JumpToFirstColumn()
+					return a, nil
+				case "$":
+					// Jump to last column
+					activeTable.JumpToLastColumn()
+					return a, nil
+				case "ctrl+u":
+					activeTable.PageUp()
+					return a, nil
+				case "ctrl+d":
+					activeTable.PageDown()
+					if cmd := a.checkLazyLoad(); cmd != nil {
+						return a, cmd
+					}
+					return a, nil
+				case "s":
+					// Sort by current column (only for main table browsing, not result tabs)
+					if !a.resultTabs.HasTabs() {
+						activeTable.ToggleSort()
+						// Reload data with new sort
+						if a.currentTable != "" {
+							parts := strings.Split(a.currentTable, ".")
+							if len(parts) == 2 {
+								return a, func() tea.Msg {
+									return LoadTableDataMsg{
+										Schema:     parts[0],
+										Table:      parts[1],
+										Offset:     0,
+										Limit:      100,
+										SortColumn: activeTable.GetSortColumn(),
+										SortDir:    activeTable.GetSortDirection(),
+										NullsFirst: activeTable.GetNullsFirst(),
+									}
+								}
+							}
+						}
+					}
+					return a, nil
+				case "S":
+					// Toggle NULLS FIRST/LAST (only for main table browsing)
+					if !a.resultTabs.HasTabs() && activeTable.SortColumn >= 0 {
+						activeTable.ToggleNullsFirst()
+						// Reload data
+						if a.currentTable != "" {
+							parts := strings.Split(a.currentTable, ".")
+							if len(parts) == 2 {
+								return a, func() tea.Msg {
+									return LoadTableDataMsg{
+										Schema:     parts[0],
+										Table:      parts[1],
+										Offset:     0,
+										Limit:      100,
+										SortColumn: activeTable.GetSortColumn(),
+										SortDir:    activeTable.GetSortDirection(),
+										NullsFirst: activeTable.GetNullsFirst(),
+									}
+								}
+							}
+						}
+					}
+					return a, nil
+				case "r":
+					// Reverse sort direction (only for main table browsing)
+					if !a.resultTabs.HasTabs() && activeTable.ReverseSortDirection() {
+						// Reload data with reversed sort
+						if a.currentTable != "" {
 							parts := strings.Split(a.currentTable, ".")
 							if len(parts) == 2 {
 								return a, func() tea.Msg {
@@ -1335,3335 +2733,5680 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							}
 						}
 					}
-					return a, nil
-				case "S":
-					// Toggle NULLS FIRST/LAST (only for main table browsing)
-					if !a.resultTabs.HasTabs() && activeTable.SortColumn >= 0 {
-						activeTable.ToggleNullsFirst()
-						// Reload data
-						if a.currentTable != "" {
-							parts := strings.Split(a.currentTable, ".")
-							if len(parts) == 2 {
-								return a, func() tea.Msg {
-									return LoadTableDataMsg{
-										Schema:     parts[0],
-										Table:      parts[1],
-										Offset:     0,
-										Limit:      100,
-										SortColumn: activeTable.GetSortColumn(),
-										SortDir:    activeTable.GetSortDirection(),
-										NullsFirst: activeTable.GetNullsFirst(),
-									}
-								}
-							}
-						}
+					return a, nil
+				case "J":
+					// Open JSONB viewer if cell contains JSONB (uppercase J to avoid conflict with vim down)
+					selectedRow, selectedCol := activeTable.GetSelectedCell()
+					if selectedRow >= 0 && selectedCol >= 0 && selectedRow < len(activeTable.Rows) && selectedCol < len(activeTable.Columns) {
+						cellValue := activeTable.Rows[selectedRow][selectedCol]
+						if jsonb.IsJSONB(cellValue) {
+							if err := a.jsonbViewer.SetValue(cellValue); err == nil {
+								a.showJSONBViewer = true
+							}
+						}
+					}
+					return a, nil
+				case "/":
+					// Open search input
+					a.searchInput.Reset()
+					a.searchInput.Width = a.rightPanel.Width - 4
+					a.showSearch = true
+					return a, nil
+				case "n":
+					// Next search match
+					if activeTable.SearchActive {
+						activeTable.NextMatch()
+					}
+					return a, nil
+				case "N":
+					// Previous search match
+					if activeTable.SearchActive {
+						activeTable.PrevMatch()
+					}
+					return a, nil
+				case "e":
+					// Edit the selected cell (table data tabs only)
+					return a.beginCellEdit()
+				case "u":
+					// Revert staged edits in the selected row
+					if activeTable.HasPendingEdits() {
+						activeTable.RevertRowEdits(activeTable.SelectedRow)
+					}
+					return a, nil
+				case "w":
+					// Review and commit staged edits
+					return a.showPendingChangesDialog()
+				case "a":
+					// Add a new row
+					return a.openInsertRowDialog()
+				case "D":
+					// Delete marked rows (or the selected row)
+					return a.deleteSelectedRows()
+				case "E":
+					// Export results or table data to a file
+					return a.openExportDialog()
+				case "I":
+					// Import a file into the current table
+					return a.openImportDialog()
+				case " ":
+					// Mark/unmark the selected row and move down
+					activeTable.ToggleRowMark(activeTable.SelectedRow)
+					activeTable.MoveSelection(1)
+					return a, nil
+				case "enter":
+					// Follow the foreign key of the selected cell; consumed
+					// either way so it does not propagate to the tree view
+					return a.followForeignKey()
+				case "R":
+					// List rows of other tables referencing the selected row
+					return a.showReferencingRows()
+				case "alt+left", "backspace":
+					// Back to the rows before the last foreign key followed
+					if tab, _ := a.activeTableDataTab(); tab != nil {
+						return a.navigateHistory(tab, false)
+					}
+				case "alt+right":
+					if tab, _ := a.activeTableDataTab(); tab != nil {
+						return a.navigateHistory(tab, true)
+					}
+				}
+			}
+		}
+	case DiscoveryCompleteMsg:
+		// Update connection dialog with discovered instances
+		a.connectionDialog.SetDiscoveredInstances(msg.Instances)
+		return a, nil
+
+	case CompletionCatalogLoadedMsg:
+		cache := a.completionCaches[msg.Key]
+		if cache == nil {
+			return a, nil
+		}
+		cache.loading = false
+		if msg.Err != nil {
+			log.Printf("Warning: Failed to load completion metadata: %v", msg.Err)
+			if cache.catalog == nil {
+				cache.catalog = &completion.Catalog{}
+			}
+			cache.loadedAt = time.Now()
+			return a, nil
+		}
+		cache.catalog = msg.Catalog
+		cache.loadedAt = time.Now()
+		cache.columns = make(map[string]bool)
+		return a, a.sqlEditor.RefreshCompletion()
+
+	case CompletionColumnsLoadedMsg:
+		cache := a.completionCaches[msg.Key]
+		if cache == nil || cache.catalog == nil {
+			return a, nil
+		}
+		if msg.Err != nil {
+			log.Printf("Warning: Failed to load columns of %s: %v", msg.Relation.Key(), msg.Err)
+		}
+		// Failed loads are stored empty so they are not retried until the
+		// catalog expires
+		cache.catalog.SetColumns(msg.Relation, msg.Columns)
+		return a, a.sqlEditor.RefreshCompletion()
+
+	case StartupConnectMsg:
+		return a.connectStartup()
+
+	case LoadTreeMsg:
+		return a, a.loadTree
+
+	case TreeLoadedMsg:
+		if msg.Err != nil {
+			a.ShowError("Database Error", fmt.Sprintf("Failed to load database structure:\n\n%v", msg.Err))
+			return a, nil
+		}
+		// Replace the connection's node, or add it after the other open
+		// connections
+		id := models.GetConnectionFromNode(msg.Server)
+		root := a.treeView.Root
+		if existing := a.serverNode(id); existing != nil {
+			msg.Server.Parent = root
+			root.Children[slices.Index(root.Children, existing)] = msg.Server
+		} else {
+			root.AddChild(msg.Server)
+		}
+
+		// Auto-expand to schema level: Server -> Database -> Schemas, for the
+		// loaded database
+		msg.Server.Expanded = true
+		for _, dbNode := range msg.Server.Children {
+			dbNode.Expanded = dbNode.Loaded
+			// Expand each schema node
+			for _, schemaNode := range dbNode.Children {
+				schemaNode.Expanded = true
+			}
+		}
+		a.markActiveNodes()
+
+		// Open what the command line asked for in the first connection
+		if startup := a.startup; startup != nil {
+			a.startup = nil
+			return a, a.openStartupObject(msg.Server, startup)
+		}
+		return a, nil
+
+	case components.TreeNodeExpandedMsg:
+		// Other databases of the server load when first expanded
+		if msg.Expanded && msg.Node.Type == models.TreeNodeTypeDatabase && !msg.Node.Loaded {
+			return a, a.loadDatabase(msg.Node, false)
+		}
+		return a, nil
+
+	case DatabaseLoadedMsg:
+		var node *models.TreeNode
+		if server := a.serverNode(msg.ConnID); server != nil {
+			node = server.FindByID(msg.Node.ID)
+		}
+		if node == nil {
+			return a, nil
+		}
+		if msg.Err != nil {
+			node.Expanded = false
+			a.ShowError("Database Error", fmt.Sprintf("Failed to open database %s:\n\n%v", node.Label, msg.Err))
+			return a, nil
+		}
+		models.RefreshTreeChildren(node, msg.Node.Children)
+		node.Expanded = true
+		for _, schemaNode := range node.Children {
+			schemaNode.Expanded = schemaNode.Type == models.TreeNodeTypeSchema
+		}
+		if msg.Use {
+			if err := a.useConnection(msg.ConnID, node.Label); err != nil {
+				a.ShowError("Cannot Switch Database", err.Error())
+			}
+		}
+		return a, nil
+
+	case components.TreeNodeSelectedMsg:
+		// Handle selection based on node type
+		if msg.Node == nil {
+			return a, nil
+		}
+
+		// Objects are opened in the connection and database they belong to
+		if msg.Node.Type == models.TreeNodeTypeDatabase && !msg.Node.Loaded {
+			return a, a.loadDatabase(msg.Node, true)
+		}
+		if err := a.useTreeNode(msg.Node); err != nil {
+			a.ShowError("Cannot Switch Database", err.Error())
+			return a, nil
+		}
+
+		switch msg.Node.Type {
+		case models.TreeNodeTypeTable, models.TreeNodeTypeView, models.TreeNodeTypeMaterializedView:
+			// Get schema name by traversing up the tree
+			var schemaName string
+			current := msg.Node.Parent
+			for current != nil {
+				if current.Type == models.TreeNodeTypeSchema {
+					schemaName = strings.Split(current.Label, " ")[0]
+					break
+				}
+				current = current.Parent
+			}
+
+			if schemaName == "" {
+				return a, nil
+			}
+
+			// Store selected node
+			a.state.TreeSelected = msg.Node
+
+			// Create object ID for tab deduplication
+			objectID := schemaName + "." + msg.Node.Label
+
+			// Check if tab for this table already exists
+			existingFound := false
+			for i, tab := range a.resultTabs.GetAllTabs() {
+				if tab.ObjectID == objectID && tab.Type == components.TabTypeTableData && a.resultTabs.SameTarget(tab) {
+					a.resultTabs.SetActiveTab(i)
+					existingFound = true
+					a.state.FocusArea = models.FocusDataPanel
+					a.updatePanelStyles()
+					break
+				}
+			}
+
+			if !existingFound {
+				// Create new StructureView for this table
+				tableView := components.NewTableView(a.theme)
+				structureView := components.NewStructureView(a.theme, tableView)
+
+				// Add as a new tab
+				a.resultTabs.AddTableData(objectID, msg.Node.Label, structureView)
+
+				// Load table data asynchronously
+				return a, a.loadTableDataForTab(a.resultTabs.GetActiveTab(), schemaName, msg.Node.Label)
+			}
+			return a, nil
+
+		case models.TreeNodeTypeFunction, models.TreeNodeTypeProcedure:
+			// Display function/procedure source code
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadFunctionSource(msg.Node)
+
+		case models.TreeNodeTypeTriggerFunction:
+			// Display trigger function source code
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadTriggerFunctionSource(msg.Node)
+
+		case models.TreeNodeTypeSequence:
+			// Display sequence properties
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadSequenceDetails(msg.Node)
+
+		case models.TreeNodeTypeIndex:
+			// Display index DDL definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadIndexDetails(msg.Node)
+
+		case models.TreeNodeTypeTrigger:
+			// Display trigger DDL definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadTriggerDetails(msg.Node)
+
+		case models.TreeNodeTypeExtension:
+			// Display extension info
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadExtensionDetails(msg.Node)
+
+		case models.TreeNodeTypeCompositeType:
+			// Display composite type definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadCompositeTypeDetails(msg.Node)
+
+		case models.TreeNodeTypeEnumType:
+			// Display enum type definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadEnumTypeDetails(msg.Node)
+
+		case models.TreeNodeTypeDomainType:
+			// Display domain type definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadDomainTypeDetails(msg.Node)
+
+		case models.TreeNodeTypeRangeType:
+			// Display range type definition
+			a.state.TreeSelected = msg.Node
+			a.currentTable = "" // Clear current table
+			return a, a.loadRangeTypeDetails(msg.Node)
+
+		default:
+			return a, nil
+		}
+
+	case LoadTableDataMsg:
+		return a, a.loadTableData(msg)
+
+	case ObjectDetailsLoadedMsg:
+		if msg.Err != nil {
+			a.ShowError("Error", fmt.Sprintf("Failed to load %s details:\n\n%v", msg.ObjectType, msg.Err))
+			return a, nil
+		}
+
+		// Check if tab for this object already exists
+		for i, tab := range a.resultTabs.GetAllTabs() {
+			if tab.ObjectID == msg.ObjectID && tab.Type == components.TabTypeCodeEditor && a.resultTabs.SameTarget(tab) {
+				a.resultTabs.SetActiveTab(i)
+				a.state.FocusArea = models.FocusDataPanel
+				a.updatePanelStyles()
+				return a, nil
+			}
+		}
+
+		// Create code editor to display the object details
+		codeEditor := components.NewCodeEditor(a.theme)
+		codeEditor.SetContent(msg.Content, msg.ObjectType, msg.Title)
+		codeEditor.ObjectName = msg.ObjectName
+		codeEditor.SetFormatting(a.formatting())
+
+		// Add as a new tab
+		a.resultTabs.AddCodeEditor(msg.ObjectID, msg.Title, codeEditor)
+		a.state.FocusArea = models.FocusDataPanel
+		a.updatePanelStyles()
+		return a, nil
+
+	case components.CodeEditorCloseMsg:
+		// Close the active tab if it's a code editor tab
+		activeTab := a.resultTabs.GetActiveTab()
+		if activeTab != nil && activeTab.Type == components.TabTypeCodeEditor {
+			a.resultTabs.CloseActiveTab()
+			a.useTabConnection()
+		}
+		// Legacy: also clear the global code editor state
+		a.showCodeEditor = false
+		a.codeEditor = nil
+		// If no more tabs, return to tree
+		if !a.resultTabs.HasTabs() {
+			a.state.FocusArea = models.FocusTreeView
+		}
+		a.updatePanelStyles()
+		return a, nil
+
+	case components.SaveObjectMsg:
+		// Execute the save SQL where the object's tab was opened
+		target := a.activeTarget()
+		if tab := a.resultTabs.GetActiveTab(); tab != nil && tab.Type == components.TabTypeCodeEditor {
+			target = tabTarget(tab)
+		}
+		return a, a.saveObjectDefinition(target, msg)
+
+	case components.ObjectSavedMsg:
+		if msg.Error != nil {
+			a.ShowError("Save Error", fmt.Sprintf("Failed to save object:\n\n%v", msg.Error))
+			return a, nil
+		}
+		// Success - update the code editor's original content and exit edit mode
+		activeTab := a.resultTabs.GetActiveTab()
+		if activeTab != nil && activeTab.Type == components.TabTypeCodeEditor && activeTab.CodeEditor != nil {
+			activeTab.CodeEditor.Original = activeTab.CodeEditor.GetContent()
+			activeTab.CodeEditor.Modified = false
+			activeTab.CodeEditor.ExitEditMode(false) // Keep changes
+		}
+		// Legacy: also update global code editor
+		if a.codeEditor != nil {
+			a.codeEditor.Original = a.codeEditor.GetContent()
+			a.codeEditor.Modified = false
+			a.codeEditor.ExitEditMode(false) // Keep changes
+		}
+		return a, nil
+
+	case TableDataLoadedMsg:
+		if msg.Err != nil {
+			a.ShowError("Database Error", fmt.Sprintf("Failed to load table data:\n\n%v", msg.Err))
+			return a, nil
+		}
+
+		// Check if this is initial load or pagination
+		// Initial load if:
+		// 1. No existing rows (first load ever)
+		// 2. Offset is 0 (fresh load request, even for same table)
+		// 3. Columns changed (different table selected)
+		isInitialLoad := len(a.tableView.Rows) == 0 ||
+			msg.Offset == 0 ||
+			(len(msg.Columns) > 0 && len(a.tableView.Columns) > 0 && msg.Columns[0] != a.tableView.Columns[0])
+
+		if isInitialLoad {
+			// Initial load - replace all data
+			a.tableView.SetData(msg.Columns, msg.Rows, msg.TotalRows)
+			a.tableView.SetCells(msg.Cells)
+			a.tableView.SelectedRow = 0
+			a.tableView.TopRow = 0
+			a.state.FocusArea = models.FocusDataPanel
+			a.updatePanelStyles()
+		} else {
+			// Append paginated data (same table, loading more rows)
+			a.tableView.AppendRows(msg.Rows, msg.Cells, false)
+			a.tableView.TotalRows = msg.TotalRows
+		}
+		return a, nil
+
+	case TabTableDataLoadedMsg:
+		if msg.Err != nil {
+			a.ShowError("Database Error", fmt.Sprintf("Failed to load table data:\n\n%v", msg.Err))
+			return a, nil
+		}
+
+		// Update the data of the tab, if it is still open
+		if tab := a.resultTabs.GetTab(msg.TabID); tab != nil && tab.Structure != nil {
+			// Set table data in the structure view
+			tab.Structure.GetTableView().SetData(msg.Columns, msg.Rows, msg.TotalRows)
+			tab.Structure.GetTableView().SetCells(msg.Cells)
+			// Also load structure metadata (columns, constraints, indexes)
+			ctx := context.Background()
+			if pool, err := a.targetPool(ctx, tabTarget(tab)); err == nil {
+				_ = tab.Structure.SetTable(ctx, pool, msg.Schema, msg.Table)
+			}
+		}
+		a.state.FocusArea = models.FocusDataPanel
+		a.updatePanelStyles()
+		return a, nil
+
+	case tea.WindowSizeMsg:
+		a.state.Width = msg.Width
+		a.state.Height = msg.Height
+		a.updatePanelDimensions()
+
+	default:
+		// Forward other messages (like textinput's internal pasteMsg) to active input components
+		// This enables clipboard paste functionality (Ctrl+V) in text inputs
+		// Only components that use charmbracelet/bubbles/textinput need these messages
+		var cmd tea.Cmd
+		if a.showConnectionDialog {
+			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showInsertRow {
+			a.insertRowDialog, cmd = a.insertRowDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showBindDialog {
+			a.bindDialog, cmd = a.bindDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showExport {
+			a.exportDialog, cmd = a.exportDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showImport {
+			a.importDialog, cmd = a.importDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showHistory && a.historyDialog.IsInputActive() {
+			a.historyDialog, cmd = a.historyDialog.Update(msg)
+			return a, cmd
+		}
+		if a.showSearch {
+			a.searchInput, cmd = a.searchInput.Update(msg)
+			return a, cmd
+		}
+	}
+	return a, nil
+}
+
+// checkLazyLoad checks if we need to load more data and returns a command if so
+func (a *App) checkLazyLoad() tea.Cmd {
+	if a.resultTabs.HasTabs() {
+		return a.fetchMoreResults()
+	}
+
+	// Check if we need to load more data (lazy loading)
+	if a.tableView.SelectedRow >= len(a.tableView.Rows)-10 &&
+		len(a.tableView.Rows) < a.tableView.TotalRows &&
+		a.currentTable != "" {
+		// Parse schema and table from currentTable
+		parts := strings.Split(a.currentTable, ".")
+		if len(parts) == 2 {
+			return func() tea.Msg {
+				return LoadTableDataMsg{
+					Schema:     parts[0],
+					Table:      parts[1],
+					Offset:     len(a.tableView.Rows),
+					Limit:      100,
+					SortColumn: a.tableView.GetSortColumn(),
+					SortDir:    a.tableView.GetSortDirection(),
+					NullsFirst: a.tableView.GetNullsFirst(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fetchMoreResults fetches the next page of a streamed query result when the
+// selection nears the end of the rows loaded so far
+func (a *App) fetchMoreResults() tea.Cmd {
+	tab := a.resultTabs.GetActiveTab()
+	if tab == nil || tab.Source == nil || tab.Fetching || tab.TableView == nil {
+		return nil
+	}
+	if tab.TableView.SelectedRow < len(tab.TableView.Rows)-10 {
+		return nil
+	}
+
+	tab.Fetching = true
+	ctx, cancel := context.WithCancel(context.Background())
+	a.fetchCancelFn = cancel
+	source, tabID := tab.Source, tab.ID
+	return func() tea.Msg {
+		rows, cells, err := source.Fetch(ctx, query.DefaultFetchSize)
+		return ResultRowsFetchedMsg{TabID: tabID, Rows: rows, Cells: cells, Done: source.Done(), Err: err}
+	}
+}
+
+// getActiveTableView returns the appropriate TableView based on current context:
+// - If Result Tabs has tabs, use the active result tab's TableView
+// - If on structure tabs (columns, constraints, indexes), use structure view's TableView
+// - Otherwise use the main tableView (for Data tab browsing)
+func (a *App) getActiveTableView() *components.TableView {
+	if a.resultTabs.HasTabs() {
+		return a.resultTabs.GetActiveTableView()
+	}
+	if a.currentTab > 0 {
+		return a.structureView.GetActiveTableView()
+	}
+	return a.tableView
+}
+
+// View implements tea.Model
+func (a *App) View() string {
+	// If error overlay is showing, render it centered on top of everything
+	if a.showError {
+		return lipgloss.Place(
+			a.state.Width, a.state.Height,
+			lipgloss.Center, lipgloss.Center,
+			a.errorOverlay.View(),
+		)
+	}
+
+	// If connection dialog is showing, render it with zone.Scan for mouse support
+	if a.showConnectionDialog {
+		return zone.Scan(a.renderConnectionDialog())
+	}
+
+	// If password dialog is showing, render it
+	if a.showPasswordDialog {
+		return zone.Scan(a.renderPasswordDialog())
+	}
+
+	// If in help mode, show help overlay
+	if a.state.ViewMode == models.HelpMode {
+		return help.Render(a.state.Width, a.state.Height, lipgloss.NewStyle())
+	}
+
+	// Wrap normal view with zone.Scan for mouse support
+	return zone.Scan(a.renderNormalView())
+}
+
+// renderNormalView renders the normal application view
+func (a *App) renderNormalView() string {
+	// Use cached styles for performance
+	styles := a.cachedStyles
+
+	// Top bar with modern Catppuccin styling
+	connStatus := ""
+	if a.state.ActiveConnection != nil {
+		// Build connection string with elegant formatting
+		conn := a.state.ActiveConnection
+		connStr := fmt.Sprintf("%s@%s:%d/%s",
+			conn.Config.User,
+			conn.Config.Host,
+			conn.Config.Port,
+			conn.Database)
+
+		connStatus = "  " + styles.connGreen.Render("") + " "
+
+		// Label the connection in its color when it is named or several
+		// are open
+		open := len(a.connColors)
+		if open > 1 || conn.Config.Name != "" {
+			name := conn.Config.Name
+			if name == "" {
+				name = conn.Config.Host
+			}
+			connStatus += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color(a.connectionColor(conn.ID))).
+				Bold(true).
+				Padding(0, 1).
+				Render(name) + " "
+		}
+		connStatus += styles.connText.Render(connStr)
+		if conn.Config.ReadOnly {
+			connStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("#fab387")).Render(" read-only")
+		}
+		if open > 1 {
+			connStatus += styles.dimStyle.Render(fmt.Sprintf(" +%d", open-1))
+		}
+	} else {
+		connStatus = "  " + styles.connGray.Render("") + " " + styles.connGray.Render("Not connected")
+	}
+
+	topBarLeft := styles.appName.Render("  LazyPG ") + connStatus + a.transactionStatus() + a.executionStatus()
+	topBarRight := styles.topBarHelp.Render("? ") + styles.topBarHelpText.Render("help")
+	topBarContent := a.formatStatusBar(topBarLeft, topBarRight)
+
+	// Create modern top bar with subtle border (width needs to be dynamic)
+	topBar := lipgloss.NewStyle().
+		Width(a.state.Width).
+		Background(lipgloss.Color("#313244")).
+		Foreground(lipgloss.Color("#cdd6f4")).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#45475a")).
+		Padding(0, 1).
+		Render(topBarContent)
+
+	// Context-sensitive bottom bar with cached styles
+	var bottomBarLeft string
+	// Focus area label style
+	focusLabelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#1e1e2e")). // Dark text
+		Background(lipgloss.Color("#89b4fa")). // Blue background
+		Padding(0, 1).
+		Bold(true)
+
+	if a.isSQLEditorFocused() {
+		// SQL editor mode
+		focusLabel := focusLabelStyle.Render("SQL")
+		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Ctrl+S") + styles.dimStyle.Render(" execute") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("F5") + styles.dimStyle.Render(" run all") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Ctrl+X") + styles.dimStyle.Render(" explain") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Ctrl+O") + styles.dimStyle.Render(" editor") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Esc") + styles.dimStyle.Render(" close")
+	} else if a.state.FocusArea == models.FocusTreeView {
+		// Tree navigation keys with icons
+		focusLabel := focusLabelStyle.Render("Tree")
+		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("↑↓") + styles.dimStyle.Render(" navigate") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("→←") + styles.dimStyle.Render(" expand") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Enter") + styles.dimStyle.Render(" select") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("/") + styles.dimStyle.Render(" search")
+	} else {
+		// Data panel - include SQL editor shortcut
+		focusLabel := focusLabelStyle.Render("Data")
+		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("↑↓") + styles.dimStyle.Render(" navigate") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Ctrl+D/U") + styles.dimStyle.Render(" page") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("Ctrl+E") + styles.dimStyle.Render(" sql") +
+			styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("p") + styles.dimStyle.Render(" preview")
+	}
+
+	// Add filter indicator if active
+	if a.activeFilter != nil && len(a.activeFilter.RootGroup.Conditions) > 0 {
+		filterCount := len(a.activeFilter.RootGroup.Conditions)
+		filterSuffix := ""
+		if filterCount > 1 {
+			filterSuffix = "s"
+		}
+		filterIndicator := styles.separatorStyle.Render(" │ ") +
+			styles.filterStyle.Render("") + styles.dimStyle.Render(fmt.Sprintf(" %d filter%s", filterCount, filterSuffix))
+		bottomBarLeft = bottomBarLeft + filterIndicator
+	}
+
+	// Add Vim motion status if pending
+	if a.state.FocusArea == models.FocusDataPanel && a.currentTab == 0 {
+		vimStatus := a.tableView.GetVimMotionStatus()
+		if vimStatus != "" {
+			bottomBarLeft = bottomBarLeft + styles.separatorStyle.Render(" │ ") + styles.vimStyle.Render(vimStatus)
+		}
+	}
+
+	// Common keys on the right with icons
+	bottomBarRight := styles.keyStyle.Render("Tab") + styles.dimStyle.Render(" switch") +
+		styles.separatorStyle.Render(" │ ") +
+		styles.keyStyle.Render("[]") + styles.dimStyle.Render(" tabs")
+
+	// Show 1-5 hint when active tab is TableData
+	activeTab := a.resultTabs.GetActiveTab()
+	if activeTab != nil && activeTab.Type == components.TabTypeTableData {
+		bottomBarRight += styles.separatorStyle.Render(" │ ") +
+			styles.keyStyle.Render("1-5") + styles.dimStyle.Render(" structure")
+	}
+
+	bottomBarRight += styles.separatorStyle.Render(" │ ") +
+		styles.keyStyle.Render("c") + styles.dimStyle.Render(" connect") +
+		styles.separatorStyle.Render(" │ ") +
+		styles.keyStyle.Render("q") + styles.dimStyle.Render(" quit")
+
+	bottomBarContent := a.formatStatusBar(bottomBarLeft, bottomBarRight)
+
+	// Create modern bottom bar (width needs to be dynamic)
+	bottomBar := lipgloss.NewStyle().
+		Width(a.state.Width).
+		Background(lipgloss.Color("#313244")).
+		Foreground(lipgloss.Color("#cdd6f4")).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#45475a")).
+		Padding(0, 1).
+		Render(bottomBarContent)
+
+	// Update tree view dimensions and render
+	// Calculate available content height: panel height - borders (2) - title line (1) - padding (0)
+	treeContentHeight := a.leftPanel.Height - 3 // -2 for top/bottom borders, -1 for title
+	if treeContentHeight < 1 {
+		treeContentHeight = 1
+	}
+	a.treeView.Width = a.leftPanel.Width - 2 // -2 for horizontal padding inside panel
+	a.treeView.Height = treeContentHeight
+	a.leftPanel.Content = a.treeView.View()
+	a.leftPanel.Title = "Explorer"
+
+	// Update right panel content
+	// Calculate available content height: panel height - borders (2) - padding (0)
+	rightContentHeight := a.rightPanel.Height - 2 // -2 for top/bottom borders (no title)
+	if rightContentHeight < 1 {
+		rightContentHeight = 1
+	}
+	rightContentWidth := a.rightPanel.Width - 2 // -2 for horizontal padding inside panel
+
+	a.rightPanel.Content = a.renderRightPanel(rightContentWidth, rightContentHeight)
+
+	// Panels side by side
+	panels := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		a.leftPanel.View(),
+		a.rightPanel.View(),
+	)
+
+	// Combine all
+	mainView := lipgloss.JoinVertical(
+		lipgloss.Left,
+		topBar,
+		panels,
+		bottomBar,
+	)
+
+	// Render filter builder if visible
+	if a.showFilterBuilder {
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.filterBuilder.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render JSONB viewer if visible
+	if a.showJSONBViewer {
+		jsonbView := a.jsonbViewer.View()
+
+		// Check if preview panel should be shown below
+		if a.jsonbViewer.PreviewVisible() {
+			// Preview panel below JSONB viewer
+			// Use same width as JSONB viewer, height is 1/4 of screen
+			previewHeight := a.state.Height / 4
+			if previewHeight < 6 {
+				previewHeight = 6
+			}
+			if previewHeight > 12 {
+				previewHeight = 12
+			}
+
+			previewPanel := a.jsonbViewer.RenderPreviewPanel(a.jsonbViewer.Width, previewHeight)
+
+			// Join panels vertically
+			combined := lipgloss.JoinVertical(
+				lipgloss.Left,
+				jsonbView,
+				previewPanel,
+			)
+
+			mainView = lipgloss.Place(
+				a.state.Width,
+				a.state.Height,
+				lipgloss.Center,
+				lipgloss.Center,
+				combined,
+				lipgloss.WithWhitespaceChars(" "),
+				lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+			)
+		} else {
+			mainView = lipgloss.Place(
+				a.state.Width,
+				a.state.Height,
+				lipgloss.Center,
+				lipgloss.Center,
+				jsonbView,
+				lipgloss.WithWhitespaceChars(" "),
+				lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+			)
+		}
+	}
+
+	// Render execution plan viewer if visible
+	if a.showExplain {
+		a.explainViewer.Width = min(160, a.state.Width-4)
+		a.explainViewer.Height = a.state.Height - 2
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.explainViewer.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render query history browser if visible
+	if a.showHistory {
+		a.historyDialog.Width = min(140, a.state.Width-4)
+		a.historyDialog.Height = a.state.Height - 2
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.historyDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render referencing tables list if visible
+	if a.showReferences {
+		a.referencesDialog.Width = min(90, a.state.Width-4)
+		a.referencesDialog.Height = min(24, a.state.Height-4)
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.referencesDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render server activity monitor if visible
+	if a.showActivity {
+		a.activityDialog.Width = min(160, a.state.Width-4)
+		a.activityDialog.Height = a.state.Height - 2
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.activityDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render ER diagram if visible
+	if a.showERDiagram {
+		a.erDiagram.Width = a.state.Width - 2
+		a.erDiagram.Height = a.state.Height - 2
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.erDiagram.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render schema diff if visible
+	if a.showSchemaDiff {
+		a.schemaDiff.Width = a.state.Width - 2
+		a.schemaDiff.Height = a.state.Height - 2
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.schemaDiff.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+	if a.showSchemaDiffDialog {
+		a.schemaDiffDialog.Width = min(76, a.state.Width-4)
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.schemaDiffDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render favorites dialog if visible
+	if a.showFavorites {
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.favoritesDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render connection profiles dialog if visible
+	if a.showProfiles {
+		a.profilesDialog.Width = min(90, a.state.Width-4)
+		a.profilesDialog.Height = min(32, a.state.Height-4)
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.profilesDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render export dialog if visible
+	if a.showExport {
+		a.exportDialog.Width = min(80, a.state.Width-4)
+		a.exportDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.exportDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render import dialog if visible
+	if a.showImport {
+		a.importDialog.Width = min(110, a.state.Width-4)
+		a.importDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.importDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render insert row form if visible
+	if a.showInsertRow {
+		a.insertRowDialog.Width = min(100, a.state.Width-4)
+		a.insertRowDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.insertRowDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render bind variable prompt if visible
+	if a.showBindDialog {
+		a.bindDialog.Width = min(100, a.state.Width-4)
+		a.bindDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.bindDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render confirm dialog if visible
+	if a.showConfirm {
+		a.confirmDialog.Width = min(100, a.state.Width-4)
+		a.confirmDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.confirmDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render pending changes dialog if visible
+	if a.showPendingChanges {
+		a.pendingChangesDialog.Width = min(100, a.state.Width-4)
+		a.pendingChangesDialog.Height = a.state.Height - 4
+		mainView = lipgloss.Place(
+			a.state.Width,
+			a.state.Height,
+			lipgloss.Center,
+			lipgloss.Center,
+			a.pendingChangesDialog.View(),
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
+		)
+	}
+
+	// Render command palette if visible (as overlay on top of mainView)
+	if a.showCommandPalette {
+		a.commandPalette.Width = 80
+		if a.commandPalette.Width > a.state.Width-4 {
+			a.commandPalette.Width = a.state.Width - 4
+		}
+		a.commandPalette.Height = 20
+
+		mainView = a.overlayCommandPalette(mainView)
+	}
+
+	// Render search input if visible (as overlay on top of mainView)
+	if a.showSearch {
+		a.searchInput.Width = 60
+		if a.searchInput.Width > a.state.Width-4 {
+			a.searchInput.Width = a.state.Width - 4
+		}
+		mainView = a.overlaySearchInput(mainView)
+	}
+
+	return mainView
+}
+
+// renderRightPanel renders the right panel content based on current state
+func (a *App) renderRightPanel(width, height int) string {
+	// Calculate SQL editor height
+	editorHeight := a.sqlEditor.GetCollapsedHeight()
+	if a.sqlEditor.IsExpanded() {
+		editorHeight = int(float64(height) * a.sqlEditor.GetHeightRatio())
+		if editorHeight < 5 {
+			editorHeight = 5
+		}
+	}
+
+	// Calculate data panel height (reserve space for tab bar if tabs exist)
+	tabBarHeight := 0
+	if a.resultTabs.HasTabs() {
+		tabBarHeight = 1
+	}
+	dataPanelHeight := height - editorHeight - tabBarHeight
+	if dataPanelHeight < 5 {
+		dataPanelHeight = 5
+	}
+
+	// Render tab bar
+	tabBar := ""
+	if a.resultTabs.HasTabs() {
+		tabBar = a.resultTabs.RenderTabBar(width)
+	}
+
+	// Render data panel
+	dataPanel := a.renderDataPanel(width, dataPanelHeight)
+
+	// Render SQL editor
+	a.sqlEditor.Width = width
+	a.sqlEditor.Height = editorHeight
+	sqlEditorView := a.sqlEditor.View()
+
+	// Combine vertically: tab bar + data + editor
+	if tabBar != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, tabBar, dataPanel, sqlEditorView)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, dataPanel, sqlEditorView)
+}
+
+// renderDataPanel renders the data panel (table view or structure view)
+func (a *App) renderDataPanel(width, height int) string {
+	// If we have result tabs, show the active tab's content
+	if a.resultTabs.HasTabs() {
+		activeTab := a.resultTabs.GetActiveTab()
+		if activeTab != nil {
+			// If active tab is pending, show spinner
+			if activeTab.IsPending {
+				elapsed := a.resultTabs.GetPendingElapsed()
+				elapsedStr := fmt.Sprintf("%.1fs", elapsed.Seconds())
+
+				status, hint := "Executing query...", "Press Esc to cancel"
+				if a.execution != nil && a.execution.CancelRequested() {
+					status, hint = "Cancelling query...", "Waiting for the server · Esc again to close the connection"
+				}
+
+				spinnerView := a.executeSpinner.View()
+				statusText := lipgloss.NewStyle().
+					Foreground(a.theme.Foreground).
+					Render(fmt.Sprintf("%s (%s)", status, elapsedStr))
+
+				cancelHint := lipgloss.NewStyle().
+					Foreground(a.theme.Border).
+					Render(hint)
+
+				content := lipgloss.JoinVertical(lipgloss.Center,
+					"",
+					spinnerView+" "+statusText,
+					"",
+					cancelHint,
+				)
+
+				return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+			}
+
+			// If active tab was cancelled, show cancelled message
+			if activeTab.IsCancelled {
+				cancelledText := lipgloss.NewStyle().
+					Foreground(a.theme.Warning).
+					Bold(true).
+					Render("Query Cancelled")
+
+				hintText := lipgloss.NewStyle().
+					Foreground(a.theme.Border).
+					Render("Press Ctrl+E to edit and re-execute")
+
+				content := lipgloss.JoinVertical(lipgloss.Center,
+					"",
+					cancelledText,
+					"",
+					hintText,
+				)
+
+				return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+			}
+
+			// Render based on tab type
+			switch activeTab.Type {
+			case components.TabTypeQueryResult:
+				// Show query result table view
+				activeTable := a.resultTabs.GetActiveTableView()
+				if activeTable != nil {
+					activeTable.Width = width
+					activeTable.Height = height - 1
+					// Add empty line placeholder to align with TableData mode
+					return "\n" + activeTable.View()
+				}
+
+			case components.TabTypeTableData:
+				// Show table data with structure view
+				if activeTab.Structure != nil {
+					// Calculate preview pane height (only if visible)
+					structureView := activeTab.Structure
+					activeTable := structureView.GetActiveTableView()
+					previewHeight := 0
+					if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
+						maxPreviewHeight := height / 3
+						if maxPreviewHeight < 5 {
+							maxPreviewHeight = 5
+						}
+						activeTable.SetPreviewPaneDimensions(width, maxPreviewHeight)
+						previewHeight = activeTable.GetPreviewPaneHeight()
+					}
+
+					// Calculate main content height
+					mainHeight := height - previewHeight
+					if mainHeight < 5 {
+						mainHeight = 5
+					}
+
+					// Update structure view dimensions
+					structureView.Width = width
+					structureView.Height = mainHeight
+
+					// Render main content
+					mainContent := structureView.View()
+
+					// If preview pane is visible, append it
+					if activeTable != nil && previewHeight > 0 {
+						previewContent := activeTable.PreviewPane.View()
+						return lipgloss.JoinVertical(lipgloss.Left, mainContent, previewContent)
+					}
+
+					return mainContent
+				}
+
+			case components.TabTypeCodeEditor:
+				// Show code editor
+				if activeTab.CodeEditor != nil {
+					activeTab.CodeEditor.Width = width
+					activeTab.CodeEditor.Height = height - 1
+					// Add empty line placeholder to align with TableData mode
+					return "\n" + activeTab.CodeEditor.View()
+				}
+			}
+		}
+	}
+
+	// Legacy: If table is selected in tree (without tabs), show structure view
+	if a.currentTable != "" {
+		// Calculate preview pane height (only if visible)
+		// Get active table view for preview pane handling
+		activeTable := a.structureView.GetActiveTableView()
+		previewHeight := 0
+		if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
+			// Set preview pane dimensions (max 1/3 of available height)
+			maxPreviewHeight := height / 3
+			if maxPreviewHeight < 5 {
+				maxPreviewHeight = 5
+			}
+			activeTable.SetPreviewPaneDimensions(width, maxPreviewHeight)
+			previewHeight = activeTable.GetPreviewPaneHeight()
+		}
+
+		// Calculate main content height (subtract preview pane height)
+		mainHeight := height - previewHeight
+		if mainHeight < 5 {
+			mainHeight = 5
+		}
+
+		// Update structure view dimensions
+		a.structureView.Width = width
+		a.structureView.Height = mainHeight
+
+		// Load table structure if needed (when table changes)
+		conn, err := a.connectionManager.GetActive()
+		if err == nil && conn != nil && conn.Pool != nil {
+			parts := strings.Split(a.currentTable, ".")
+			if len(parts) == 2 {
+				// Only load if we haven't loaded this table yet
+				if !a.structureView.HasTableLoaded(parts[0], parts[1]) {
+					ctx := context.Background()
+					err := a.structureView.SetTable(ctx, conn.Pool, parts[0], parts[1])
+					if err != nil {
+						log.Printf("Failed to load structure: %v", err)
 					}
-					return a, nil
-				case "r":
-					// Reverse sort direction (only for main table browsing)
-					if !a.resultTabs.HasTabs() && activeTable.ReverseSortDirection() {
-						// Reload data with reversed sort
-						if a.currentTable != "" {
-							parts := strings.Split(a.currentTable, ".")
-							if len(parts) == 2 {
-								return a, func() tea.Msg {
-									return LoadTableDataMsg{
-										Schema:     parts[0],
-										Table:      parts[1],
-										Offset:     0,
-										Limit:      100,
-										SortColumn: activeTable.GetSortColumn(),
-										SortDir:    activeTable.GetSortDirection(),
-										NullsFirst: activeTable.GetNullsFirst(),
-									}
-								}
-							}
-						}
+				}
+			}
+		}
+
+		// Render main content
+		mainContent := a.structureView.View()
+
+		// If preview pane is visible, append it
+		if activeTable != nil && previewHeight > 0 {
+			previewContent := activeTable.PreviewPane.View()
+			return lipgloss.JoinVertical(lipgloss.Left, mainContent, previewContent)
+		}
+
+		return mainContent
+	}
+
+	// Legacy: If we have code editor to display (function source, sequence info, etc.)
+	if a.showCodeEditor && a.codeEditor != nil {
+		a.codeEditor.Width = width
+		a.codeEditor.Height = height
+		return a.codeEditor.View()
+	}
+
+	// No data - show placeholder
+	placeholderStyle := lipgloss.NewStyle().
+		Foreground(a.theme.Comment).
+		Width(width).
+		Height(height).
+		Align(lipgloss.Center, lipgloss.Center)
+
+	return placeholderStyle.Render("No data to display\n\nPress Ctrl+E to open SQL editor")
+}
+
+// updatePanelDimensions calculates panel sizes based on window size
+func (a *App) updatePanelDimensions() {
+	if a.state.Width <= 0 || a.state.Height <= 0 {
+		return
+	}
+
+	// Reserve space for top bar (3 lines) and bottom bar (3 lines)
+	// Total: 6 lines, leaving Height - 6 for panels
+	// Note: Panel.Height includes borders, so the actual content area is Height - 6
+	contentHeight := a.state.Height - 6
+	if contentHeight < 5 {
+		contentHeight = 5
+	}
+
+	// Calculate panel widths
+	// Each panel has a border (2 chars wide: left + right borders)
+	// Total border width: 4 chars (2 per panel)
+	leftWidth := (a.state.Width * a.state.LeftPanelWidth) / 100
+	if leftWidth < 20 {
+		leftWidth = 20
+	}
+
+	// Right panel gets remaining width after accounting for left panel and both borders
+	// Subtract 4 to account for borders on both panels (2 chars each)
+	rightWidth := a.state.Width - leftWidth - 4
+	if rightWidth < 20 {
+		rightWidth = 20
+		// If right panel is too small, reduce left panel width
+		leftWidth = a.state.Width - rightWidth - 4
+	}
+
+	a.leftPanel.Width = leftWidth
+	a.leftPanel.Height = contentHeight
+	a.rightPanel.Width = rightWidth
+	a.rightPanel.Height = contentHeight
+}
+
+// updatePanelStyles updates panel styling based on focus with Catppuccin colors
+func (a *App) updatePanelStyles() {
+	// Update legacy FocusedPanel for compatibility
+	if a.state.FocusArea == models.FocusTreeView {
+		a.state.FocusedPanel = models.LeftPanel
+	} else {
+		a.state.FocusedPanel = models.RightPanel
+	}
+
+	// Left panel style
+	if a.state.FocusArea == models.FocusTreeView {
+		a.leftPanel.Style = lipgloss.NewStyle().
+			BorderForeground(lipgloss.Color("#89b4fa")). // Blue - focused
+			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+	} else {
+		a.leftPanel.Style = lipgloss.NewStyle().
+			BorderForeground(lipgloss.Color("#45475a")). // Surface1 - unfocused
+			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+	}
+
+	// Right panel style (focused when DataPanel or SQLEditor)
+	if a.state.FocusArea == models.FocusDataPanel || a.state.FocusArea == models.FocusSQLEditor {
+		a.rightPanel.Style = lipgloss.NewStyle().
+			BorderForeground(lipgloss.Color("#89b4fa")). // Blue - focused
+			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+	} else {
+		a.rightPanel.Style = lipgloss.NewStyle().
+			BorderForeground(lipgloss.Color("#45475a")). // Surface1 - unfocused
+			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+	}
+
+	// Update SQL Editor focused state
+	a.sqlEditor.Focused = (a.state.FocusArea == models.FocusSQLEditor)
+
+	// Update Code Editor focused state
+	if a.codeEditor != nil {
+		a.codeEditor.Focused = (a.state.FocusArea == models.FocusDataPanel)
+	}
+
+	// Update TableView focused state
+	// DataPanel is focused and CodeEditor is not shown
+	isTableFocused := a.state.FocusArea == models.FocusDataPanel && !a.showCodeEditor
+	a.tableView.Focused = isTableFocused
+}
+
+// nextFocus moves focus to the next region in cycle: TreeView -> DataPanel -> SQLEditor -> TreeView
+func (a *App) nextFocus() {
+	switch a.state.FocusArea {
+	case models.FocusTreeView:
+		a.state.FocusArea = models.FocusDataPanel
+	case models.FocusDataPanel:
+		a.state.FocusArea = models.FocusSQLEditor
+	case models.FocusSQLEditor:
+		a.state.FocusArea = models.FocusTreeView
+	}
+	a.updatePanelStyles()
+}
+
+// prevFocus moves focus to the previous region in cycle
+func (a *App) prevFocus() {
+	switch a.state.FocusArea {
+	case models.FocusTreeView:
+		a.state.FocusArea = models.FocusSQLEditor
+	case models.FocusDataPanel:
+		a.state.FocusArea = models.FocusTreeView
+	case models.FocusSQLEditor:
+		a.state.FocusArea = models.FocusDataPanel
+	}
+	a.updatePanelStyles()
+}
+
+// isEditingText returns true if user is actively editing text (Tab should insert indent)
+func (a *App) isEditingText() bool {
+	// CodeEditor in edit mode
+	if a.state.FocusArea == models.FocusDataPanel && a.showCodeEditor && a.codeEditor != nil && !a.codeEditor.ReadOnly {
+		return true
+	}
+	// SQLEditor expanded and focused
+	if a.state.FocusArea == models.FocusSQLEditor && a.sqlEditor.IsExpanded() {
+		return true
+	}
+	return false
+}
+
+// isSQLEditorFocused returns true if SQL editor has focus (compatibility helper)
+func (a *App) isSQLEditorFocused() bool {
+	return a.state.FocusArea == models.FocusSQLEditor
+}
+
+// isPrintableInput checks if a key message is a printable character input
+// that should trigger auto-expand in SQL editor (not a control/shortcut key)
+func isPrintableInput(key string) bool {
+	// Blacklist: keys that should always be shortcuts, not input
+	blacklist := map[string]bool{
+		"tab": true, "shift+tab": true, "backtab": true,
+		"esc": true, "escape": true,
+		"[": true, "]": true,
+		"up": true, "down": true, "left": true, "right": true,
+		"home": true, "end": true, "pgup": true, "pgdown": true,
+		"f1": true, "f2": true, "f3": true, "f4": true, "f5": true,
+		"f6": true, "f7": true, "f8": true, "f9": true, "f10": true,
+		"f11": true, "f12": true,
+	}
+
+	if blacklist[key] {
+		return false
+	}
+
+	// Any key with Ctrl or Alt modifier is a shortcut
+	if strings.HasPrefix(key, "ctrl+") || strings.HasPrefix(key, "alt+") {
+		return false
+	}
+
+	// Single printable characters, space, enter, backspace are input
+	if len(key) == 1 {
+		return true
+	}
+
+	// Special input keys
+	inputKeys := map[string]bool{
+		"enter": true, "space": true, "backspace": true, "delete": true,
+	}
+	return inputKeys[key]
+}
+
+// handleMouseEvent processes mouse events for scrolling and clicking using bubblezone
+func (a *App) handleMouseEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Handle connection dialog mouse events
+	if a.showConnectionDialog {
+		return a.handleConnectionDialogMouse(msg)
+	}
+
+	// Route mouse events to overlays first
+	if a.showError {
+		handled, cmd := a.errorOverlay.HandleMouseClick(msg)
+		if handled {
+			if cmd != nil {
+				return a, cmd
+			}
+		}
+		// Block other mouse events when error overlay is showing
+		return a, nil
+	}
+
+	if a.showCommandPalette {
+		// Handle scroll wheel
+		if a.commandPalette.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		// Handle click
+		handled, cmd := a.commandPalette.HandleMouseClick(msg)
+		if handled {
+			if cmd != nil {
+				return a, cmd
+			}
+			return a, nil
+		}
+		// Block other mouse events when command palette is showing
+		return a, nil
+	}
+
+	if a.showFilterBuilder {
+		// Handle scroll wheel
+		if a.filterBuilder.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		// Handle click
+		handled, cmd := a.filterBuilder.HandleMouseClick(msg)
+		if handled {
+			if cmd != nil {
+				return a, cmd
+			}
+			return a, nil
+		}
+		// Block other mouse events when filter builder is showing
+		return a, nil
+	}
+
+	if a.showJSONBViewer {
+		// Handle scroll wheel
+		if a.jsonbViewer.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		// Handle click
+		handled, cmd := a.jsonbViewer.HandleMouseClick(msg)
+		if handled {
+			if cmd != nil {
+				return a, cmd
+			}
+			return a, nil
+		}
+		// Block other mouse events when JSONB viewer is showing
+		return a, nil
+	}
+
+	if a.showExplain {
+		if a.explainViewer.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		handled, cmd := a.explainViewer.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when plan viewer is showing
+		return a, nil
+	}
+
+	if a.showHistory {
+		if a.historyDialog.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		handled, cmd := a.historyDialog.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when history browser is showing
+		return a, nil
+	}
+
+	if a.showFavorites {
+		// Handle scroll wheel
+		if a.favoritesDialog.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		// Handle click
+		handled, cmd := a.favoritesDialog.HandleMouseClick(msg)
+		if handled {
+			if cmd != nil {
+				return a, cmd
+			}
+			return a, nil
+		}
+		// Block other mouse events when favorites dialog is showing
+		return a, nil
+	}
+
+	if a.showProfiles {
+		if a.profilesDialog.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		if handled, cmd := a.profilesDialog.HandleMouseClick(msg); handled {
+			return a, cmd
+		}
+		// Block other mouse events when profiles dialog is showing
+		return a, nil
+	}
+
+	if a.showConfirm {
+		handled, cmd := a.confirmDialog.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when confirm dialog is showing
+		return a, nil
+	}
+
+	if a.showReferences {
+		handled, cmd := a.referencesDialog.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when references list is showing
+		return a, nil
+	}
+
+	if a.showActivity {
+		if a.activityDialog.HandleMouseWheel(msg) {
+			return a, nil
+		}
+		handled, cmd := a.activityDialog.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when activity monitor is showing
+		return a, nil
+	}
+
+	if a.showERDiagram {
+		a.erDiagram.HandleMouseWheel(msg)
+		// Block other mouse events when the diagram is showing
+		return a, nil
+	}
+
+	if a.showSchemaDiffDialog {
+		// Block mouse events when a form is showing
+		return a, nil
+	}
+
+	if a.showSchemaDiff {
+		a.schemaDiff.HandleMouseWheel(msg)
+		// Block other mouse events when the schema diff is showing
+		return a, nil
+	}
+
+	if a.showInsertRow || a.showBindDialog || a.showExport || a.showImport {
+		// Block mouse events when a form is showing
+		return a, nil
+	}
+
+	if a.showPendingChanges {
+		handled, cmd := a.pendingChangesDialog.HandleMouseClick(msg)
+		if handled {
+			return a, cmd
+		}
+		// Block other mouse events when pending changes dialog is showing
+		return a, nil
+	}
+
+	// Handle structure view tabs (when a table is selected, structure view is shown)
+	if a.currentTable != "" {
+		handled, tabIndex := a.structureView.HandleMouseClick(msg)
+		if handled {
+			// Sync a.currentTab with the clicked tab
+			if tabIndex >= 0 {
+				a.currentTab = tabIndex
+			}
+			return a, nil
+		}
+		// Fall through to normal table handling for the structure view content
+	}
+
+	// Search input doesn't need mouse handling currently
+	if a.showSearch {
+		// Block other mouse events when search is showing
+		return a, nil
+	}
+
+	// Handle scroll events (these don't need zone detection)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		// Check which zone we're scrolling in
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				a.treeView.ScrollUp(3)
+				return a, nil
+			}
+		}
+		// Check table cells or rows for scroll (scroll viewport, not selection)
+		tableScrolled := false
+		for row := 0; row < 100 && !tableScrolled; row++ {
+			for col := 0; col < 50; col++ {
+				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
+				if zone.Get(zoneID).InBounds(msg) {
+					if activeTable := a.getActiveTableView(); activeTable != nil {
+						activeTable.ScrollViewport(-3) // Scroll up
 					}
-					return a, nil
-				case "J":
-					// Open JSONB viewer if cell contains JSONB (uppercase J to avoid conflict with vim down)
-					selectedRow, selectedCol := activeTable.GetSelectedCell()
-					if selectedRow >= 0 && selectedCol >= 0 && selectedRow < len(activeTable.Rows) && selectedCol < len(activeTable.Columns) {
-						cellValue := activeTable.Rows[selectedRow][selectedCol]
-						if jsonb.IsJSONB(cellValue) {
-							if err := a.jsonbViewer.SetValue(cellValue); err == nil {
-								a.showJSONBViewer = true
+					tableScrolled = true
+					break
+				}
+			}
+		}
+		if tableScrolled {
+			return a, nil
+		}
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				if activeTable := a.getActiveTableView(); activeTable != nil {
+					activeTable.ScrollViewport(-3) // Scroll up
+				}
+				return a, nil
+			}
+		}
+		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
+			// Could add scroll in editor if needed
+			return a, nil
+		}
+		return a, nil
+
+	case tea.MouseButtonWheelDown:
+		// Check which zone we're scrolling in
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				a.treeView.ScrollDown(3)
+				return a, nil
+			}
+		}
+		// Check table cells or rows for scroll (scroll viewport, not selection)
+		tableScrolledDown := false
+		for row := 0; row < 100 && !tableScrolledDown; row++ {
+			for col := 0; col < 50; col++ {
+				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
+				if zone.Get(zoneID).InBounds(msg) {
+					if activeTable := a.getActiveTableView(); activeTable != nil {
+						needsLazyLoad := activeTable.ScrollViewport(3) // Scroll down
+						// Check for lazy loading
+						if needsLazyLoad {
+							if cmd := a.checkLazyLoad(); cmd != nil {
+								return a, cmd
 							}
 						}
 					}
-					return a, nil
-				case "/":
-					// Open search input
-					a.searchInput.Reset()
-					a.searchInput.Width = a.rightPanel.Width - 4
-					a.showSearch = true
-					return a, nil
-				case "n":
-					// Next search match
-					if activeTable.SearchActive {
-						activeTable.NextMatch()
-					}
-					return a, nil
-				case "N":
-					// Previous search match
-					if activeTable.SearchActive {
-						activeTable.PrevMatch()
+					tableScrolledDown = true
+					break
+				}
+			}
+		}
+		if tableScrolledDown {
+			return a, nil
+		}
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				if activeTable := a.getActiveTableView(); activeTable != nil {
+					needsLazyLoad := activeTable.ScrollViewport(3) // Scroll down
+					// Check for lazy loading
+					if needsLazyLoad {
+						if cmd := a.checkLazyLoad(); cmd != nil {
+							return a, cmd
+						}
 					}
-					return a, nil
-				case "enter", " ":
-					// Consume enter/space in table view (no action needed for now)
-					// This prevents the key from propagating to tree view
-					return a, nil
 				}
+				return a, nil
 			}
 		}
-	case DiscoveryCompleteMsg:
-		// Update connection dialog with discovered instances
-		a.connectionDialog.SetDiscoveredInstances(msg.Instances)
-		return a, nil
-
-	case LoadTreeMsg:
-		return a, a.loadTree
-
-	case TreeLoadedMsg:
-		if msg.Err != nil {
-			a.ShowError("Database Error", fmt.Sprintf("Failed to load database structure:\n\n%v", msg.Err))
+		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
+			// Could add scroll in editor if needed
 			return a, nil
 		}
-		// Update tree view with loaded data
-		a.treeView.Root = msg.Root
-
-		// Auto-expand to schema level: Root -> Database -> Schemas
-		if msg.Root != nil {
-			msg.Root.Expanded = true
-			for _, dbNode := range msg.Root.Children {
-				dbNode.Expanded = true
-				// Expand each schema node
-				for _, schemaNode := range dbNode.Children {
-					schemaNode.Expanded = true
-				}
-			}
-		}
 		return a, nil
 
-	case components.TreeNodeSelectedMsg:
-		// Handle selection based on node type
-		if msg.Node == nil {
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
 			return a, nil
 		}
 
-		switch msg.Node.Type {
-		case models.TreeNodeTypeTable, models.TreeNodeTypeView, models.TreeNodeTypeMaterializedView:
-			// Get schema name by traversing up the tree
-			var schemaName string
-			current := msg.Node.Parent
-			for current != nil {
-				if current.Type == models.TreeNodeTypeSchema {
-					schemaName = strings.Split(current.Label, " ")[0]
-					break
+		// Check result tabs first
+		for i := 0; i < components.MaxResultTabs; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneResultTabPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				a.resultTabs.SetActiveTab(i)
+				a.useTabConnection()
+				// Sync SQL editor content with new active tab
+				if activeSQL := a.resultTabs.GetActiveSQL(); activeSQL != "" {
+					a.sqlEditor.SetContent(activeSQL)
 				}
-				current = current.Parent
-			}
-
-			if schemaName == "" {
 				return a, nil
 			}
+		}
 
-			// Store selected node
-			a.state.TreeSelected = msg.Node
-
-			// Create object ID for tab deduplication
-			objectID := schemaName + "." + msg.Node.Label
+		// Check tree view rows
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
+				a.state.FocusArea = models.FocusTreeView
+				a.updatePanelStyles()
+				_, cmd := a.treeView.HandleClick(i)
+				return a, cmd
+			}
+		}
 
-			// Check if tab for this table already exists
-			existingFound := false
-			for i, tab := range a.resultTabs.GetAllTabs() {
-				if tab.ObjectID == objectID && tab.Type == components.TabTypeTableData {
-					a.resultTabs.SetActiveTab(i)
-					existingFound = true
+		// Check table view cells first (more specific than row)
+		for row := 0; row < 100; row++ {
+			for col := 0; col < 50; col++ {
+				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
+				if zone.Get(zoneID).InBounds(msg) {
 					a.state.FocusArea = models.FocusDataPanel
 					a.updatePanelStyles()
-					break
-				}
-			}
-
-			if !existingFound {
-				// Create new StructureView for this table
-				tableView := components.NewTableView(a.theme)
-				structureView := components.NewStructureView(a.theme, tableView)
-
-				// Add as a new tab
-				a.resultTabs.AddTableData(objectID, msg.Node.Label, structureView)
+					if activeTable := a.getActiveTableView(); activeTable != nil {
+						// Convert visible col index to actual col index
+						actualCol := activeTable.LeftColOffset + col
+						actualRow := activeTable.TopRow + row
 
-				// Load table data asynchronously
-				return a, a.loadTableDataForTab(schemaName, msg.Node.Label, objectID)
+						// Check if clicking on already selected cell
+						if activeTable.SelectedRow == actualRow && activeTable.SelectedCol == actualCol {
+							// Double-click behavior: open JSONB viewer or preview pane
+							if actualRow >= 0 && actualRow < len(activeTable.Rows) && actualCol >= 0 && actualCol < len(activeTable.Columns) {
+								cellValue := activeTable.Rows[actualRow][actualCol]
+								// Check if it's JSON/JSONB data
+								if jsonb.IsJSONB(cellValue) {
+									if err := a.jsonbViewer.SetValue(cellValue); err == nil {
+										a.showJSONBViewer = true
+									}
+								} else {
+									// For non-JSONB, toggle preview pane
+									activeTable.TogglePreviewPane()
+								}
+							}
+						} else {
+							// First click: just select the cell
+							activeTable.SetSelectedRow(actualRow)
+							activeTable.SelectedCol = actualCol
+						}
+					}
+					return a, nil
+				}
 			}
-			return a, nil
-
-		case models.TreeNodeTypeFunction, models.TreeNodeTypeProcedure:
-			// Display function/procedure source code
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadFunctionSource(msg.Node)
-
-		case models.TreeNodeTypeTriggerFunction:
-			// Display trigger function source code
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadTriggerFunctionSource(msg.Node)
-
-		case models.TreeNodeTypeSequence:
-			// Display sequence properties
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadSequenceDetails(msg.Node)
-
-		case models.TreeNodeTypeIndex:
-			// Display index DDL definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadIndexDetails(msg.Node)
-
-		case models.TreeNodeTypeTrigger:
-			// Display trigger DDL definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadTriggerDetails(msg.Node)
-
-		case models.TreeNodeTypeExtension:
-			// Display extension info
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadExtensionDetails(msg.Node)
-
-		case models.TreeNodeTypeCompositeType:
-			// Display composite type definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadCompositeTypeDetails(msg.Node)
-
-		case models.TreeNodeTypeEnumType:
-			// Display enum type definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadEnumTypeDetails(msg.Node)
-
-		case models.TreeNodeTypeDomainType:
-			// Display domain type definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadDomainTypeDetails(msg.Node)
-
-		case models.TreeNodeTypeRangeType:
-			// Display range type definition
-			a.state.TreeSelected = msg.Node
-			a.currentTable = "" // Clear current table
-			return a, a.loadRangeTypeDetails(msg.Node)
-
-		default:
-			return a, nil
-		}
-
-	case LoadTableDataMsg:
-		return a, a.loadTableData(msg)
-
-	case ObjectDetailsLoadedMsg:
-		if msg.Err != nil {
-			a.ShowError("Error", fmt.Sprintf("Failed to load %s details:\n\n%v", msg.ObjectType, msg.Err))
-			return a, nil
 		}
 
-		// Check if tab for this object already exists
-		for i, tab := range a.resultTabs.GetAllTabs() {
-			if tab.ObjectID == msg.ObjectID && tab.Type == components.TabTypeCodeEditor {
-				a.resultTabs.SetActiveTab(i)
+		// Check table view rows (fallback for areas without cell zones)
+		for i := 0; i < 100; i++ {
+			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
+			if zone.Get(zoneID).InBounds(msg) {
 				a.state.FocusArea = models.FocusDataPanel
 				a.updatePanelStyles()
+				if activeTable := a.getActiveTableView(); activeTable != nil {
+					activeTable.SetSelectedRow(activeTable.TopRow + i)
+				}
 				return a, nil
 			}
 		}
 
-		// Create code editor to display the object details
-		codeEditor := components.NewCodeEditor(a.theme)
-		codeEditor.SetContent(msg.Content, msg.ObjectType, msg.Title)
-		codeEditor.ObjectName = msg.ObjectName
-
-		// Add as a new tab
-		a.resultTabs.AddCodeEditor(msg.ObjectID, msg.Title, codeEditor)
-		a.state.FocusArea = models.FocusDataPanel
-		a.updatePanelStyles()
-		return a, nil
+		// Check SQL editor
+		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
+			a.state.FocusArea = models.FocusSQLEditor
+			a.updatePanelStyles()
 
-	case components.CodeEditorCloseMsg:
-		// Close the active tab if it's a code editor tab
-		activeTab := a.resultTabs.GetActiveTab()
-		if activeTab != nil && activeTab.Type == components.TabTypeCodeEditor {
-			a.resultTabs.CloseActiveTab()
-		}
-		// Legacy: also clear the global code editor state
-		a.showCodeEditor = false
-		a.codeEditor = nil
-		// If no more tabs, return to tree
-		if !a.resultTabs.HasTabs() {
-			a.state.FocusArea = models.FocusTreeView
+			// Expand editor if collapsed
+			if !a.sqlEditor.IsExpanded() {
+				a.sqlEditor.Expand()
+			}
+			return a, nil
 		}
-		a.updatePanelStyles()
+
 		return a, nil
+	}
 
-	case components.SaveObjectMsg:
-		// Execute the save SQL
-		return a, a.saveObjectDefinition(msg)
+	return a, nil
+}
 
-	case components.ObjectSavedMsg:
-		if msg.Error != nil {
-			a.ShowError("Save Error", fmt.Sprintf("Failed to save object:\n\n%v", msg.Error))
-			return a, nil
-		}
-		// Success - update the code editor's original content and exit edit mode
-		activeTab := a.resultTabs.GetActiveTab()
-		if activeTab != nil && activeTab.Type == components.TabTypeCodeEditor && activeTab.CodeEditor != nil {
-			activeTab.CodeEditor.Original = activeTab.CodeEditor.GetContent()
-			activeTab.CodeEditor.Modified = false
-			activeTab.CodeEditor.ExitEditMode(false) // Keep changes
-		}
-		// Legacy: also update global code editor
-		if a.codeEditor != nil {
-			a.codeEditor.Original = a.codeEditor.GetContent()
-			a.codeEditor.Modified = false
-			a.codeEditor.ExitEditMode(false) // Keep changes
-		}
+// handleConnectionDialogMouse handles mouse events for connection dialog using bubblezone
+func (a *App) handleConnectionDialogMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Handle scroll
+	if msg.Button == tea.MouseButtonWheelUp {
+		a.connectionDialog.MoveSelection(-1)
 		return a, nil
+	}
+	if msg.Button == tea.MouseButtonWheelDown {
+		a.connectionDialog.MoveSelection(1)
+		return a, nil
+	}
 
-	case TableDataLoadedMsg:
-		if msg.Err != nil {
-			a.ShowError("Database Error", fmt.Sprintf("Failed to load table data:\n\n%v", msg.Err))
-			return a, nil
-		}
-
-		// Check if this is initial load or pagination
-		// Initial load if:
-		// 1. No existing rows (first load ever)
-		// 2. Offset is 0 (fresh load request, even for same table)
-		// 3. Columns changed (different table selected)
-		isInitialLoad := len(a.tableView.Rows) == 0 ||
-			msg.Offset == 0 ||
-			(len(msg.Columns) > 0 && len(a.tableView.Columns) > 0 && msg.Columns[0] != a.tableView.Columns[0])
+	// Only handle left click press
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return a, nil
+	}
 
-		if isInitialLoad {
-			// Initial load - replace all data
-			a.tableView.SetData(msg.Columns, msg.Rows, msg.TotalRows)
-			a.tableView.SelectedRow = 0
-			a.tableView.TopRow = 0
-			a.state.FocusArea = models.FocusDataPanel
-			a.updatePanelStyles()
-		} else {
-			// Append paginated data (same table, loading more rows)
-			a.tableView.Rows = append(a.tableView.Rows, msg.Rows...)
-			a.tableView.TotalRows = msg.TotalRows
-		}
+	// Check if click is on search box
+	if zone.Get(components.ZoneSearchBox).InBounds(msg) {
+		a.connectionDialog.EnterSearchMode()
 		return a, nil
+	}
 
-	case TabTableDataLoadedMsg:
-		if msg.Err != nil {
-			a.ShowError("Database Error", fmt.Sprintf("Failed to load table data:\n\n%v", msg.Err))
-			return a, nil
-		}
+	// Check history items (up to 5)
+	filteredHistory := a.connectionDialog.GetFilteredHistory()
+	for i := 0; i < 5 && i < len(filteredHistory); i++ {
+		zoneID := fmt.Sprintf("%s%d", components.ZoneHistoryPrefix, i)
+		if zone.Get(zoneID).InBounds(msg) {
+			wasAlreadySelected := a.connectionDialog.InHistorySection && a.connectionDialog.SelectedIndex == i
 
-		// Find the tab with this objectID and update its data
-		for _, tab := range a.resultTabs.GetAllTabs() {
-			if tab.ObjectID == msg.ObjectID && tab.Type == components.TabTypeTableData {
-				if tab.Structure != nil {
-					// Set table data in the structure view
-					tab.Structure.GetTableView().SetData(msg.Columns, msg.Rows, msg.TotalRows)
-					// Also load structure metadata (columns, constraints, indexes)
-					conn, err := a.connectionManager.GetActive()
-					if err == nil && conn != nil && conn.Pool != nil {
-						ctx := context.Background()
-						_ = tab.Structure.SetTable(ctx, conn.Pool, msg.Schema, msg.Table)
-					}
-				}
-				break
+			a.connectionDialog.InHistorySection = true
+			a.connectionDialog.SelectedIndex = i
+
+			// If clicking already selected item, trigger connect (lazygit-style)
+			if wasAlreadySelected {
+				return a.connectToHistoryEntry(filteredHistory[i])
 			}
+			return a, nil
 		}
-		a.state.FocusArea = models.FocusDataPanel
-		a.updatePanelStyles()
-		return a, nil
+	}
 
-	case tea.WindowSizeMsg:
-		a.state.Width = msg.Width
-		a.state.Height = msg.Height
-		a.updatePanelDimensions()
+	// Check discovered items (up to 3)
+	filteredDiscovered := a.connectionDialog.GetFilteredDiscovered()
+	for i := 0; i < 3 && i < len(filteredDiscovered); i++ {
+		zoneID := fmt.Sprintf("%s%d", components.ZoneDiscoveredPrefix, i)
+		if zone.Get(zoneID).InBounds(msg) {
+			wasAlreadySelected := !a.connectionDialog.InHistorySection && a.connectionDialog.SelectedIndex == i
 
-	default:
-		// Forward other messages (like textinput's internal pasteMsg) to active input components
-		// This enables clipboard paste functionality (Ctrl+V) in text inputs
-		// Only components that use charmbracelet/bubbles/textinput need these messages
-		var cmd tea.Cmd
-		if a.showConnectionDialog {
-			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
-			return a, cmd
-		}
-		if a.showSearch {
-			a.searchInput, cmd = a.searchInput.Update(msg)
-			return a, cmd
+			a.connectionDialog.InHistorySection = false
+			a.connectionDialog.SelectedIndex = i
+
+			// If clicking already selected item, trigger connect (lazygit-style)
+			if wasAlreadySelected {
+				return a.connectToDiscoveredInstance(filteredDiscovered[i])
+			}
+			return a, nil
 		}
 	}
+
 	return a, nil
 }
 
-// checkLazyLoad checks if we need to load more data and returns a command if so
-func (a *App) checkLazyLoad() tea.Cmd {
-	// Check if we need to load more data (lazy loading)
-	if a.tableView.SelectedRow >= len(a.tableView.Rows)-10 &&
-		len(a.tableView.Rows) < a.tableView.TotalRows &&
-		a.currentTable != "" {
-		// Parse schema and table from currentTable
-		parts := strings.Split(a.currentTable, ".")
-		if len(parts) == 2 {
-			return func() tea.Msg {
-				return LoadTableDataMsg{
-					Schema:     parts[0],
-					Table:      parts[1],
-					Offset:     len(a.tableView.Rows),
-					Limit:      100,
-					SortColumn: a.tableView.GetSortColumn(),
-					SortDir:    a.tableView.GetSortDirection(),
-					NullsFirst: a.tableView.GetNullsFirst(),
-				}
-			}
+// connectToHistoryEntry connects using a history entry
+func (a *App) connectToHistoryEntry(entry models.ConnectionHistoryEntry) (tea.Model, tea.Cmd) {
+	// A profile connection gets its settings and password from the profile
+	if entry.Profile != "" {
+		return a.connectProfile(entry.Profile)
+	}
+
+	var config models.ConnectionConfig
+
+	// Convert history entry to connection config WITH password from keyring
+	if a.connectionHistory != nil {
+		result := a.connectionHistory.GetConnectionConfigWithPassword(&entry)
+		config = result.Config
+
+		// If password is missing, show password dialog
+		if result.PasswordMissing {
+			return a, a.promptPassword(config)
 		}
+	} else {
+		config = entry.ToConnectionConfig()
 	}
-	return nil
+
+	return a.performConnection(config)
 }
 
-// getActiveTableView returns the appropriate TableView based on current context:
-// - If Result Tabs has tabs, use the active result tab's TableView
-// - If on structure tabs (columns, constraints, indexes), use structure view's TableView
-// - Otherwise use the main tableView (for Data tab browsing)
-func (a *App) getActiveTableView() *components.TableView {
-	if a.resultTabs.HasTabs() {
-		return a.resultTabs.GetActiveTableView()
-	}
-	if a.currentTab > 0 {
-		return a.structureView.GetActiveTableView()
-	}
-	return a.tableView
+// promptPassword asks for the password of a connection, then connects
+func (a *App) promptPassword(config models.ConnectionConfig) tea.Cmd {
+	a.pendingConnection = &config
+	a.passwordDialog.SetConnectionInfo(config.Host, config.Port, config.Database, config.User)
+	a.showPasswordDialog = true
+	a.showConnectionDialog = false
+	a.showProfiles = false
+	return a.passwordDialog.Init()
 }
 
-// View implements tea.Model
-func (a *App) View() string {
-	// If error overlay is showing, render it centered on top of everything
-	if a.showError {
-		return lipgloss.Place(
-			a.state.Width, a.state.Height,
-			lipgloss.Center, lipgloss.Center,
-			a.errorOverlay.View(),
-		)
+// connectProfile connects to a named profile. Its password comes from the
+// profile's password command, then from the keyring, and is asked for
+// when neither has one.
+func (a *App) connectProfile(name string) (tea.Model, tea.Cmd) {
+	if a.profilesManager == nil {
+		a.ShowError("Profiles Not Available", "Connection profiles are not initialized.\n\nPlease restart the application.")
+		return a, nil
 	}
-
-	// If connection dialog is showing, render it with zone.Scan for mouse support
-	if a.showConnectionDialog {
-		return zone.Scan(a.renderConnectionDialog())
+	profile, err := a.profilesManager.Get(name)
+	if err != nil {
+		a.ShowError("Connection Profile Not Found", fmt.Sprintf("Could not find the connection profile '%s'.\n\nAdd it from Manage Connections, config.yaml or connections.yaml.", name))
+		return a, nil
 	}
+	return a.connectWithProfile(profile, profile.ToConnectionConfig())
+}
 
-	// If password dialog is showing, render it
-	if a.showPasswordDialog {
-		return zone.Scan(a.renderPasswordDialog())
-	}
+// connectWithProfile connects with the config of a profile, possibly
+// changed, getting the password the way the profile says
+func (a *App) connectWithProfile(profile *models.ConnectionProfile, config models.ConnectionConfig) (tea.Model, tea.Cmd) {
+	a.showProfiles = false
 
-	// If in help mode, show help overlay
-	if a.state.ViewMode == models.HelpMode {
-		return help.Render(a.state.Width, a.state.Height, lipgloss.NewStyle())
+	if profile.PasswordCommand != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		password, err := profiles.RunPasswordCommand(ctx, profile.PasswordCommand)
+		if err != nil {
+			a.ShowError("Password Command Failed", fmt.Sprintf("Could not get the password of %s\n\nError: %v", profile.Name, err))
+			return a, nil
+		}
+		config.Password = password
+		config.NoSavePassword = true
+		return a.performConnection(config)
 	}
 
-	// Wrap normal view with zone.Scan for mouse support
-	return zone.Scan(a.renderNormalView())
+	if a.connectionHistory != nil {
+		password, err := a.connectionHistory.GetPassword(config.Host, config.Port, config.Database, config.User)
+		if err != nil || password == "" {
+			return a, a.promptPassword(config)
+		}
+		config.Password = password
+	}
+	return a.performConnection(config)
 }
 
-// renderNormalView renders the normal application view
-func (a *App) renderNormalView() string {
-	// Use cached styles for performance
-	styles := a.cachedStyles
-
-	// Top bar with modern Catppuccin styling
-	connStatus := ""
-	if a.state.ActiveConnection != nil {
-		// Build connection string with elegant formatting
-		conn := a.state.ActiveConnection
-		connStr := fmt.Sprintf("%s@%s:%d/%s",
-			conn.Config.User,
-			conn.Config.Host,
-			conn.Config.Port,
-			conn.Config.Database)
-
-		connStatus = "  " + styles.connGreen.Render("") + " " + styles.connText.Render(connStr)
-	} else {
-		connStatus = "  " + styles.connGray.Render("") + " " + styles.connGray.Render("Not connected")
+// connectStartup connects to the target given on the command line: a URI
+// or connection string, a profile name, or else the PG* environment
+// variables
+func (a *App) connectStartup() (tea.Model, tea.Cmd) {
+	startup := a.startup
+	if startup == nil {
+		return a, nil
 	}
 
-	topBarLeft := styles.appName.Render("  LazyPG ") + connStatus
-	topBarRight := styles.topBarHelp.Render("? ") + styles.topBarHelpText.Render("help")
-	topBarContent := a.formatStatusBar(topBarLeft, topBarRight)
-
-	// Create modern top bar with subtle border (width needs to be dynamic)
-	topBar := lipgloss.NewStyle().
-		Width(a.state.Width).
-		Background(lipgloss.Color("#313244")).
-		Foreground(lipgloss.Color("#cdd6f4")).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#45475a")).
-		Padding(0, 1).
-		Render(topBarContent)
+	switch {
+	case startup.Target == "":
+		config := discovery.GetEnvironmentConfig()
+		if config == nil {
+			return a, nil
+		}
+		return a.performConnection(*config)
 
-	// Context-sensitive bottom bar with cached styles
-	var bottomBarLeft string
-	// Focus area label style
-	focusLabelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#1e1e2e")). // Dark text
-		Background(lipgloss.Color("#89b4fa")). // Blue background
-		Padding(0, 1).
-		Bold(true)
+	case connection.IsConnectionString(startup.Target):
+		config, err := connection.ParseConnectionString(startup.Target)
+		if err != nil {
+			a.dropStartup()
+			a.ShowError("Invalid Connection String", fmt.Sprintf("Could not parse the connection given on the command line\n\nError: %v", err))
+			return a, nil
+		}
+		return a.performConnection(config)
 
-	if a.isSQLEditorFocused() {
-		// SQL editor mode
-		focusLabel := focusLabelStyle.Render("SQL")
-		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Ctrl+S") + styles.dimStyle.Render(" execute") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Ctrl+O") + styles.dimStyle.Render(" editor") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Esc") + styles.dimStyle.Render(" close")
-	} else if a.state.FocusArea == models.FocusTreeView {
-		// Tree navigation keys with icons
-		focusLabel := focusLabelStyle.Render("Tree")
-		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("↑↓") + styles.dimStyle.Render(" navigate") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("→←") + styles.dimStyle.Render(" expand") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Enter") + styles.dimStyle.Render(" select") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("/") + styles.dimStyle.Render(" search")
-	} else {
-		// Data panel - include SQL editor shortcut
-		focusLabel := focusLabelStyle.Render("Data")
-		bottomBarLeft = focusLabel + styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("↑↓") + styles.dimStyle.Render(" navigate") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Ctrl+D/U") + styles.dimStyle.Render(" page") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("Ctrl+E") + styles.dimStyle.Render(" sql") +
-			styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("p") + styles.dimStyle.Render(" preview")
+	default:
+		var profile *models.ConnectionProfile
+		if a.profilesManager != nil {
+			profile, _ = a.profilesManager.Get(startup.Target)
+		}
+		if profile == nil {
+			a.dropStartup()
+			a.ShowError("Connection Profile Not Found", fmt.Sprintf("'%s' is neither a connection URI nor the name of a connection profile.\n\nAdd it from Manage Connections, config.yaml or connections.yaml.", startup.Target))
+			return a, nil
+		}
+		config := profile.ToConnectionConfig()
+		startup.apply(&config)
+		return a.connectWithProfile(profile, config)
 	}
+}
 
-	// Add filter indicator if active
-	if a.activeFilter != nil && len(a.activeFilter.RootGroup.Conditions) > 0 {
-		filterCount := len(a.activeFilter.RootGroup.Conditions)
-		filterSuffix := ""
-		if filterCount > 1 {
-			filterSuffix = "s"
+// apply changes the config of the first connection the way the command
+// line asks, whether it is the command line's target or one picked from
+// the connection dialog
+func (s *StartupOptions) apply(config *models.ConnectionConfig) {
+	if s.Database != "" {
+		config.Database = s.Database
+	}
+	if s.Schema != "" {
+		config.DefaultSchema = s.Schema
+	}
+	if s.ReadOnly {
+		config.ReadOnly = true
+	}
+}
+
+// dropStartup forgets what the command line asked for once the connection
+// it was meant for is not made. Read-only stays on for the next try.
+func (a *App) dropStartup() {
+	if startup := a.startup; startup != nil {
+		a.startup = nil
+		if startup.ReadOnly {
+			a.startup = &StartupOptions{ReadOnly: true}
 		}
-		filterIndicator := styles.separatorStyle.Render(" │ ") +
-			styles.filterStyle.Render("") + styles.dimStyle.Render(fmt.Sprintf(" %d filter%s", filterCount, filterSuffix))
-		bottomBarLeft = bottomBarLeft + filterIndicator
 	}
+}
 
-	// Add Vim motion status if pending
-	if a.state.FocusArea == models.FocusDataPanel && a.currentTab == 0 {
-		vimStatus := a.tableView.GetVimMotionStatus()
-		if vimStatus != "" {
-			bottomBarLeft = bottomBarLeft + styles.separatorStyle.Render(" │ ") + styles.vimStyle.Render(vimStatus)
+// openStartupObject shows the schema or opens the table given on the
+// command line in the tree of the connection it connected to
+func (a *App) openStartupObject(server *models.TreeNode, startup *StartupOptions) tea.Cmd {
+	conn := a.state.ActiveConnection
+	if conn == nil || (startup.Schema == "" && startup.Table == "") {
+		return nil
+	}
+
+	schema, table := startup.Schema, startup.Table
+	if before, after, ok := strings.Cut(table, "."); ok {
+		schema, table = before, after
+	}
+	if schema == "" {
+		schema = "public"
+	}
+
+	if table == "" {
+		if node := server.FindByID(fmt.Sprintf("schema:%s.%s", conn.Database, schema)); node != nil {
+			node.Expanded = true
+			a.treeView.NavigateToNode(node)
+			return nil
 		}
+		a.ShowError("Schema Not Found", fmt.Sprintf("Schema %s was not found in %s.", schema, conn.Database))
+		return nil
 	}
 
-	// Common keys on the right with icons
-	bottomBarRight := styles.keyStyle.Render("Tab") + styles.dimStyle.Render(" switch") +
-		styles.separatorStyle.Render(" │ ") +
-		styles.keyStyle.Render("[]") + styles.dimStyle.Render(" tabs")
+	for _, prefix := range []string{"table", "view", "matview"} {
+		if node := server.FindByID(fmt.Sprintf("%s:%s.%s.%s", prefix, conn.Database, schema, table)); node != nil {
+			a.treeView.NavigateToNode(node)
+			return func() tea.Msg { return components.TreeNodeSelectedMsg{Node: node} }
+		}
+	}
+	a.ShowError("Table Not Found", fmt.Sprintf("Table %s.%s was not found in %s.", schema, table, conn.Database))
+	return nil
+}
 
-	// Show 1-4 hint when active tab is TableData
-	activeTab := a.resultTabs.GetActiveTab()
-	if activeTab != nil && activeTab.Type == components.TabTypeTableData {
-		bottomBarRight += styles.separatorStyle.Render(" │ ") +
-			styles.keyStyle.Render("1-4") + styles.dimStyle.Render(" structure")
+// connectToDiscoveredInstance connects using a discovered instance
+func (a *App) connectToDiscoveredInstance(instance models.DiscoveredInstance) (tea.Model, tea.Cmd) {
+	// Create connection config from discovered instance
+	config := models.ConnectionConfig{
+		Host:     instance.Host,
+		Port:     instance.Port,
+		Database: "postgres",        // Default database
+		User:     os.Getenv("USER"), // Current user
+		Password: "",                // No password for now
+		SSLMode:  "prefer",
 	}
 
-	bottomBarRight += styles.separatorStyle.Render(" │ ") +
-		styles.keyStyle.Render("c") + styles.dimStyle.Render(" connect") +
-		styles.separatorStyle.Render(" │ ") +
-		styles.keyStyle.Render("q") + styles.dimStyle.Render(" quit")
+	return a.performConnection(config)
+}
 
-	bottomBarContent := a.formatStatusBar(bottomBarLeft, bottomBarRight)
+// performConnection executes the actual connection
+func (a *App) performConnection(config models.ConnectionConfig) (tea.Model, tea.Cmd) {
+	if a.startup != nil {
+		a.startup.apply(&config)
+	}
 
-	// Create modern bottom bar (width needs to be dynamic)
-	bottomBar := lipgloss.NewStyle().
-		Width(a.state.Width).
-		Background(lipgloss.Color("#313244")).
-		Foreground(lipgloss.Color("#cdd6f4")).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#45475a")).
-		Padding(0, 1).
-		Render(bottomBarContent)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	// Update tree view dimensions and render
-	// Calculate available content height: panel height - borders (2) - title line (1) - padding (0)
-	treeContentHeight := a.leftPanel.Height - 3 // -2 for top/bottom borders, -1 for title
-	if treeContentHeight < 1 {
-		treeContentHeight = 1
+	connID, err := a.connectionManager.Connect(ctx, config)
+	if err != nil {
+		a.dropStartup()
+		// The failed attempt replaced a connection open with the same settings
+		if a.serverNode(connID) != nil {
+			a.disconnect(connID)
+		}
+		a.ShowError("Connection Failed", fmt.Sprintf("Could not connect to %s:%d\n\nError: %v",
+			config.Host, config.Port, err))
+		return a, nil
 	}
-	a.treeView.Width = a.leftPanel.Width - 2 // -2 for horizontal padding inside panel
-	a.treeView.Height = treeContentHeight
-	a.leftPanel.Content = a.treeView.View()
-	a.leftPanel.Title = "Explorer"
 
-	// Update right panel content
-	// Calculate available content height: panel height - borders (2) - padding (0)
-	rightContentHeight := a.rightPanel.Height - 2 // -2 for top/bottom borders (no title)
-	if rightContentHeight < 1 {
-		rightContentHeight = 1
+	// The new connection becomes the active one, next to those already
+	// open. Connecting again replaces a connection, closing its tabs.
+	if a.serverNode(connID) != nil {
+		a.resultTabs.CloseConnectionTabs(connID)
+		a.forgetConnection(connID)
+	}
+	conn, err := a.connectionManager.Get(connID)
+	if err == nil {
+		a.activateConnection(conn)
 	}
-	rightContentWidth := a.rightPanel.Width - 2 // -2 for horizontal padding inside panel
 
-	a.rightPanel.Content = a.renderRightPanel(rightContentWidth, rightContentHeight)
+	// Save to connection history (ignore errors)
+	if a.connectionHistory != nil {
+		result, err := a.connectionHistory.Add(config)
+		if err != nil {
+			log.Printf("Warning: Failed to save connection to history: %v", err)
+		} else {
+			if result != nil && result.PasswordSaveError != nil {
+				log.Printf("Warning: Failed to save password: %v", result.PasswordSaveError)
+			}
+			// Reload history in dialog
+			history := a.connectionHistory.GetRecent(10)
+			a.connectionDialog.SetHistoryEntries(history)
+		}
+	}
 
-	// Panels side by side
-	panels := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		a.leftPanel.View(),
-		a.rightPanel.View(),
-	)
+	// Trigger tree loading
+	a.showConnectionDialog = false
+	return a, func() tea.Msg {
+		return LoadTreeMsg{}
+	}
+}
 
-	// Combine all
-	mainView := lipgloss.JoinVertical(
-		lipgloss.Left,
-		topBar,
-		panels,
-		bottomBar,
-	)
+// handleTabClick handles clicking on result tabs
+// handleTabClick is no longer needed - using bubblezone for tab clicks
 
-	// Render filter builder if visible
-	if a.showFilterBuilder {
-		mainView = lipgloss.Place(
-			a.state.Width,
-			a.state.Height,
-			lipgloss.Center,
-			lipgloss.Center,
-			a.filterBuilder.View(),
-			lipgloss.WithWhitespaceChars(" "),
-			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
-		)
+// formatStatusBar formats a status bar with left and right aligned content
+func (a *App) formatStatusBar(left, right string) string {
+	// Calculate available width accounting for:
+	// - Borders: 2 chars (left + right)
+	// - Padding: 2 chars (left + right, from Padding(0, 1))
+	// Total: 4 chars
+	availableWidth := a.state.Width - 4
+	if availableWidth < 0 {
+		availableWidth = 0
 	}
 
-	// Render JSONB viewer if visible
-	if a.showJSONBViewer {
-		jsonbView := a.jsonbViewer.View()
+	// Use lipgloss.Width to get actual display width (ignoring ANSI codes)
+	leftLen := lipgloss.Width(left)
+	rightLen := lipgloss.Width(right)
 
-		// Check if preview panel should be shown below
-		if a.jsonbViewer.PreviewVisible() {
-			// Preview panel below JSONB viewer
-			// Use same width as JSONB viewer, height is 1/4 of screen
-			previewHeight := a.state.Height / 4
-			if previewHeight < 6 {
-				previewHeight = 6
-			}
-			if previewHeight > 12 {
-				previewHeight = 12
-			}
+	// If content is too wide, truncate
+	if leftLen+rightLen > availableWidth {
+		if availableWidth > rightLen {
+			// Simple truncation - in a real app you'd want smarter truncation
+			return left + right
+		}
+		return left
+	}
 
-			previewPanel := a.jsonbViewer.RenderPreviewPanel(a.jsonbViewer.Width, previewHeight)
+	// Calculate spacing between left and right content
+	spacing := availableWidth - leftLen - rightLen
+	if spacing < 0 {
+		spacing = 0
+	}
 
-			// Join panels vertically
-			combined := lipgloss.JoinVertical(
-				lipgloss.Left,
-				jsonbView,
-				previewPanel,
-			)
+	return left + lipgloss.NewStyle().Width(spacing).Render("") + right
+}
 
-			mainView = lipgloss.Place(
-				a.state.Width,
-				a.state.Height,
-				lipgloss.Center,
-				lipgloss.Center,
-				combined,
-				lipgloss.WithWhitespaceChars(" "),
-				lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
-			)
-		} else {
-			mainView = lipgloss.Place(
-				a.state.Width,
-				a.state.Height,
-				lipgloss.Center,
-				lipgloss.Center,
-				jsonbView,
-				lipgloss.WithWhitespaceChars(" "),
-				lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
-			)
+// handleConnectionDialog handles key events when connection dialog is visible
+func (a *App) handleConnectionDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle search mode
+	if a.connectionDialog.SearchMode {
+		switch msg.String() {
+		case "esc":
+			// Exit search mode and clear search
+			a.connectionDialog.ExitSearchMode(true)
+			return a, nil
+		case "enter":
+			// Exit search mode but keep search results
+			a.connectionDialog.ExitSearchMode(false)
+			return a, nil
+		default:
+			// Pass keys to search input
+			var cmd tea.Cmd
+			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
+			return a, cmd
 		}
 	}
 
-	// Render favorites dialog if visible
-	if a.showFavorites {
-		mainView = lipgloss.Place(
-			a.state.Width,
-			a.state.Height,
-			lipgloss.Center,
-			lipgloss.Center,
-			a.favoritesDialog.View(),
-			lipgloss.WithWhitespaceChars(" "),
-			lipgloss.WithWhitespaceForeground(lipgloss.Color("#555555")),
-		)
-	}
+	switch msg.String() {
+	case "esc":
+		a.showConnectionDialog = false
+		return a, nil
+
+	case "/", "ctrl+f":
+		// Enter search mode (only in discovery mode)
+		if !a.connectionDialog.ManualMode {
+			a.connectionDialog.EnterSearchMode()
+		}
+		return a, nil
+
+	case "up", "k":
+		if !a.connectionDialog.ManualMode {
+			a.connectionDialog.MoveSelection(-1)
+		}
+		return a, nil
+
+	case "down", "j":
+		if !a.connectionDialog.ManualMode {
+			a.connectionDialog.MoveSelection(1)
+		}
+		return a, nil
 
-	// Render command palette if visible (as overlay on top of mainView)
-	if a.showCommandPalette {
-		a.commandPalette.Width = 80
-		if a.commandPalette.Width > a.state.Width-4 {
-			a.commandPalette.Width = a.state.Width - 4
+	case "tab":
+		if a.connectionDialog.ManualMode {
+			a.connectionDialog.NextInput()
+		} else {
+			// In discovery mode, switch between history and discovered sections
+			a.connectionDialog.SwitchSection()
 		}
-		a.commandPalette.Height = 20
+		return a, nil
 
-		mainView = a.overlayCommandPalette(mainView)
-	}
+	case "shift+tab":
+		if a.connectionDialog.ManualMode {
+			a.connectionDialog.PrevInput()
+		}
+		return a, nil
 
-	// Render search input if visible (as overlay on top of mainView)
-	if a.showSearch {
-		a.searchInput.Width = 60
-		if a.searchInput.Width > a.state.Width-4 {
-			a.searchInput.Width = a.state.Width - 4
+	case "p":
+		// Open the connection profiles, except while typing in manual mode
+		if !a.connectionDialog.ManualMode {
+			return a.Update(commands.ManageConnectionsCommandMsg{})
 		}
-		mainView = a.overlaySearchInput(mainView)
-	}
+		var cmd tea.Cmd
+		a.connectionDialog, cmd = a.connectionDialog.Update(msg)
+		return a, cmd
 
-	return mainView
-}
+	case "m":
+		// Only handle 'm' key in discovery mode, not in manual mode (to allow typing 'm')
+		if !a.connectionDialog.ManualMode {
+			a.connectionDialog.ToggleMode()
+			return a, nil
+		}
+		// In manual mode, pass 'm' to textinput
+		var cmd tea.Cmd
+		a.connectionDialog, cmd = a.connectionDialog.Update(msg)
+		return a, cmd
 
-// renderRightPanel renders the right panel content based on current state
-func (a *App) renderRightPanel(width, height int) string {
-	// Calculate SQL editor height
-	editorHeight := a.sqlEditor.GetCollapsedHeight()
-	if a.sqlEditor.IsExpanded() {
-		editorHeight = int(float64(height) * a.sqlEditor.GetHeightRatio())
-		if editorHeight < 5 {
-			editorHeight = 5
+	case "ctrl+d":
+		// Use Ctrl+D to switch back to discovery mode to avoid conflict with typing 'd'
+		if a.connectionDialog.ManualMode {
+			a.connectionDialog.ToggleMode()
 		}
-	}
+		return a, nil
 
-	// Calculate data panel height (reserve space for tab bar if tabs exist)
-	tabBarHeight := 0
-	if a.resultTabs.HasTabs() {
-		tabBarHeight = 1
-	}
-	dataPanelHeight := height - editorHeight - tabBarHeight
-	if dataPanelHeight < 5 {
-		dataPanelHeight = 5
-	}
+	case "enter":
+		if a.connectionDialog.ManualMode {
+			config, err := a.connectionDialog.GetManualConfig()
+			if err != nil {
+				// Invalid input - show error and don't close dialog
+				a.ShowError("Invalid Configuration", fmt.Sprintf("Could not parse connection configuration\n\nError: %v", err))
+				return a, nil
+			}
 
-	// Render tab bar
-	tabBar := ""
-	if a.resultTabs.HasTabs() {
-		tabBar = a.resultTabs.RenderTabBar(width)
-	}
+			return a.performConnection(config)
+		} else {
+			var config models.ConnectionConfig
 
-	// Render data panel
-	dataPanel := a.renderDataPanel(width, dataPanelHeight)
+			// Check if browsing history or discovered instances
+			if a.connectionDialog.InHistorySection {
+				// Get selected history entry
+				historyEntry := a.connectionDialog.GetSelectedHistory()
+				if historyEntry == nil {
+					// No history entry selected
+					return a, nil
+				}
+				return a.connectToHistoryEntry(*historyEntry)
+			} else {
+				// Get selected discovered instance
+				instance := a.connectionDialog.GetSelectedInstance()
+				if instance == nil {
+					// No instance selected
+					return a, nil
+				}
 
-	// Render SQL editor
-	a.sqlEditor.Width = width
-	a.sqlEditor.Height = editorHeight
-	sqlEditorView := a.sqlEditor.View()
+				// Create connection config from discovered instance
+				// Note: We'll need to prompt for database/user/password in future
+				// For now, use common defaults
+				config = models.ConnectionConfig{
+					Host:     instance.Host,
+					Port:     instance.Port,
+					Database: "postgres",          // Default database
+					User:     os.Getenv("USER"),   // Current user
+					Password: "",                  // No password for now
+					SSLMode:  "prefer",
+				}
+			}
 
-	// Combine vertically: tab bar + data + editor
-	if tabBar != "" {
-		return lipgloss.JoinVertical(lipgloss.Left, tabBar, dataPanel, sqlEditorView)
+			return a.performConnection(config)
+		}
+
+	default:
+		// In manual mode, delegate to textinput for cursor and text handling
+		if a.connectionDialog.ManualMode {
+			var cmd tea.Cmd
+			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
+			return a, cmd
+		}
+		return a, nil
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, dataPanel, sqlEditorView)
 }
 
-// renderDataPanel renders the data panel (table view or structure view)
-func (a *App) renderDataPanel(width, height int) string {
-	// If we have result tabs, show the active tab's content
-	if a.resultTabs.HasTabs() {
-		activeTab := a.resultTabs.GetActiveTab()
-		if activeTab != nil {
-			// If active tab is pending, show spinner
-			if activeTab.IsPending {
-				elapsed := a.resultTabs.GetPendingElapsed()
-				elapsedStr := fmt.Sprintf("%.1fs", elapsed.Seconds())
-
-				spinnerView := a.executeSpinner.View()
-				statusText := lipgloss.NewStyle().
-					Foreground(a.theme.Foreground).
-					Render(fmt.Sprintf("Executing query... (%s)", elapsedStr))
-
-				cancelHint := lipgloss.NewStyle().
-					Foreground(a.theme.Border).
-					Render("Press Esc to cancel")
+// getBuiltinCommands returns built-in commands with icons
+func (a *App) getBuiltinCommands() []models.Command {
+	cmds := a.commandRegistry.GetAll()
+	// Ensure commands have icons
+	for i := range cmds {
+		if cmds[i].Icon == "" {
+			cmds[i].Icon = "▸"
+		}
+	}
 
-				content := lipgloss.JoinVertical(lipgloss.Center,
-					"",
-					spinnerView+" "+statusText,
-					"",
-					cancelHint,
-				)
+	// Switch to any other open connection
+	for _, conn := range a.connectionManager.GetAll() {
+		if !conn.Connected || (a.state.ActiveConnection != nil && conn.ID == a.state.ActiveConnection.ID) {
+			continue
+		}
+		description := fmt.Sprintf("%s@%s:%d/%s", conn.Config.User, conn.Config.Host, conn.Config.Port, conn.Database)
+		cmds = append(cmds, commands.SwitchConnectionCommand(conn.ID, description))
+	}
 
-				return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
-			}
+	// Connect to any named profile
+	if a.profilesManager != nil {
+		for _, p := range a.profilesManager.GetAll() {
+			config := p.ToConnectionConfig()
+			description := fmt.Sprintf("%s@%s:%d/%s", config.User, config.Host, config.Port, config.Database)
+			cmds = append(cmds, commands.ConnectProfileCommand(p.Name, description, p.Tags))
+		}
+	}
+	return cmds
+}
 
-			// If active tab was cancelled, show cancelled message
-			if activeTab.IsCancelled {
-				cancelledText := lipgloss.NewStyle().
-					Foreground(a.theme.Warning).
-					Bold(true).
-					Render("Query Cancelled")
+// getTableCommands returns the tables and views of the database in use as
+// commands
+func (a *App) getTableCommands() []models.Command {
+	var cmds []models.Command
 
-				hintText := lipgloss.NewStyle().
-					Foreground(a.theme.Border).
-					Render("Press Ctrl+E to edit and re-execute")
+	if a.state.ActiveConnection == nil {
+		return cmds
+	}
+	dbNode := a.findTreeNode(fmt.Sprintf("db:%s", a.state.ActiveConnection.Database))
+	if dbNode == nil {
+		return cmds
+	}
 
-				content := lipgloss.JoinVertical(lipgloss.Center,
-					"",
-					cancelledText,
-					"",
-					hintText,
-				)
+	// Traverse tree to find all tables and views
+	var traverse func(node *models.TreeNode)
+	traverse = func(node *models.TreeNode) {
+		if node == nil {
+			return
+		}
 
-				return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+		if node.Type == models.TreeNodeTypeTable || node.Type == models.TreeNodeTypeView {
+			// Get schema name from parent chain
+			var schemaName string
+			parent := node.Parent
+			for parent != nil {
+				if parent.Type == models.TreeNodeTypeSchema {
+					schemaName = strings.Split(parent.Label, " ")[0]
+					break
+				}
+				parent = parent.Parent
 			}
-
-			// Render based on tab type
-			switch activeTab.Type {
-			case components.TabTypeQueryResult:
-				// Show query result table view
-				activeTable := a.resultTabs.GetActiveTableView()
-				if activeTable != nil {
-					activeTable.Width = width
-					activeTable.Height = height - 1
-					// Add empty line placeholder to align with TableData mode
-					return "\n" + activeTable.View()
+			if schemaName != "" {
+				icon := "▦"
+				prefix := "table:"
+				if node.Type == models.TreeNodeTypeView {
+					icon = "◎"
+					prefix = "view:"
 				}
+				cmds = append(cmds, models.Command{
+					ID:          fmt.Sprintf("%s%s.%s", prefix, schemaName, node.Label),
+					Label:       fmt.Sprintf("%s.%s", schemaName, node.Label),
+					Description: "",
+					Icon:        icon,
+					Tags:        []string{schemaName, node.Label},
+				})
+			}
+		}
 
-			case components.TabTypeTableData:
-				// Show table data with structure view
-				if activeTab.Structure != nil {
-					// Calculate preview pane height (only if visible)
-					structureView := activeTab.Structure
-					activeTable := structureView.GetActiveTableView()
-					previewHeight := 0
-					if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
-						maxPreviewHeight := height / 3
-						if maxPreviewHeight < 5 {
-							maxPreviewHeight = 5
-						}
-						activeTable.SetPreviewPaneDimensions(width, maxPreviewHeight)
-						previewHeight = activeTable.GetPreviewPaneHeight()
-					}
+		for _, child := range node.Children {
+			traverse(child)
+		}
+	}
 
-					// Calculate main content height
-					mainHeight := height - previewHeight
-					if mainHeight < 5 {
-						mainHeight = 5
-					}
+	traverse(dbNode)
+	return cmds
+}
 
-					// Update structure view dimensions
-					structureView.Width = width
-					structureView.Height = mainHeight
+// getHistoryCommands returns query history as commands
+func (a *App) getHistoryCommands() []models.Command {
+	var cmds []models.Command
 
-					// Render main content
-					mainContent := structureView.View()
+	if a.historyStore == nil {
+		return cmds
+	}
 
-					// If preview pane is visible, append it
-					if activeTable != nil && previewHeight > 0 {
-						previewContent := activeTable.PreviewPane.View()
-						return lipgloss.JoinVertical(lipgloss.Left, mainContent, previewContent)
-					}
+	entries, err := a.historyStore.GetRecent(20)
+	if err != nil {
+		return cmds
+	}
 
-					return mainContent
-				}
+	for _, entry := range entries {
+		// Truncate long queries for display
+		displayQuery := entry.Query
+		if len(displayQuery) > 60 {
+			displayQuery = displayQuery[:57] + "..."
+		}
 
-			case components.TabTypeCodeEditor:
-				// Show code editor
-				if activeTab.CodeEditor != nil {
-					activeTab.CodeEditor.Width = width
-					activeTab.CodeEditor.Height = height - 1
-					// Add empty line placeholder to align with TableData mode
-					return "\n" + activeTab.CodeEditor.View()
+		cmds = append(cmds, models.Command{
+			ID:          fmt.Sprintf("history:%d", entry.ID),
+			Type:        models.CommandTypeHistory,
+			Label:       displayQuery,
+			Description: fmt.Sprintf("From %s • %s", entry.DatabaseName, entry.ExecutedAt.Format("Jan 2 15:04")),
+			Icon:        "📜",
+			Tags:        []string{"history", entry.DatabaseName},
+			Action: func(sql string) tea.Cmd {
+				return func() tea.Msg {
+					return components.ExecuteQueryMsg{SQL: sql}
 				}
-			}
-		}
+			}(entry.Query),
+		})
 	}
 
-	// Legacy: If table is selected in tree (without tabs), show structure view
-	if a.currentTable != "" {
-		// Calculate preview pane height (only if visible)
-		// Get active table view for preview pane handling
-		activeTable := a.structureView.GetActiveTableView()
-		previewHeight := 0
-		if activeTable != nil && activeTable.PreviewPane != nil && activeTable.PreviewPane.Visible {
-			// Set preview pane dimensions (max 1/3 of available height)
-			maxPreviewHeight := height / 3
-			if maxPreviewHeight < 5 {
-				maxPreviewHeight = 5
-			}
-			activeTable.SetPreviewPaneDimensions(width, maxPreviewHeight)
-			previewHeight = activeTable.GetPreviewPaneHeight()
-		}
+	return cmds
+}
 
-		// Calculate main content height (subtract preview pane height)
-		mainHeight := height - previewHeight
-		if mainHeight < 5 {
-			mainHeight = 5
-		}
+// handleCommandPalette handles key events when command palette is visible
+func (a *App) handleCommandPalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle close message
+	var cmd tea.Cmd
+	a.commandPalette, cmd = a.commandPalette.Update(msg)
 
-		// Update structure view dimensions
-		a.structureView.Width = width
-		a.structureView.Height = mainHeight
+	// Check if we got a close message
+	if msg.String() == "esc" || msg.String() == "ctrl+c" {
+		a.showCommandPalette = false
+		return a, nil
+	}
 
-		// Load table structure if needed (when table changes)
-		conn, err := a.connectionManager.GetActive()
-		if err == nil && conn != nil && conn.Pool != nil {
-			parts := strings.Split(a.currentTable, ".")
+	// Execute the command if Enter was pressed
+	if msg.String() == "enter" {
+		a.showCommandPalette = false
+
+		selected := a.commandPalette.GetSelectedCommand()
+		if selected == nil {
+			return a, nil
+		}
+
+		// Handle table/view selection (ID starts with "table:" or "view:")
+		if strings.HasPrefix(selected.ID, "table:") || strings.HasPrefix(selected.ID, "view:") {
+			// Parse schema.table from ID (format: "table:schema.name" or "view:schema.name")
+			var prefix string
+			if strings.HasPrefix(selected.ID, "table:") {
+				prefix = "table:"
+			} else {
+				prefix = "view:"
+			}
+			parts := strings.SplitN(strings.TrimPrefix(selected.ID, prefix), ".", 2)
 			if len(parts) == 2 {
-				// Only load if we haven't loaded this table yet
-				if !a.structureView.HasTableLoaded(parts[0], parts[1]) {
-					ctx := context.Background()
-					err := a.structureView.SetTable(ctx, conn.Pool, parts[0], parts[1])
-					if err != nil {
-						log.Printf("Failed to load structure: %v", err)
+				schema := parts[0]
+				table := parts[1]
+				a.currentTable = schema + "." + table
+
+				// Sync tree view position - find the node and expand ancestors
+				if a.state.ActiveConnection != nil {
+					dbName := a.state.ActiveConnection.Database
+					nodeID := fmt.Sprintf("%s%s.%s.%s", prefix, dbName, schema, table)
+					if node := a.findTreeNode(nodeID); node != nil {
+						a.treeView.NavigateToNode(node)
+					}
+				}
+
+				return a, func() tea.Msg {
+					return LoadTableDataMsg{
+						Schema: schema,
+						Table:  table,
+						Offset: 0,
+						Limit:  100,
 					}
 				}
 			}
 		}
 
-		// Render main content
-		mainContent := a.structureView.View()
-
-		// If preview pane is visible, append it
-		if activeTable != nil && previewHeight > 0 {
-			previewContent := activeTable.PreviewPane.View()
-			return lipgloss.JoinVertical(lipgloss.Left, mainContent, previewContent)
+		// Handle regular command with action
+		if selected.Action != nil {
+			return a, selected.Action
 		}
-
-		return mainContent
-	}
-
-	// Legacy: If we have code editor to display (function source, sequence info, etc.)
-	if a.showCodeEditor && a.codeEditor != nil {
-		a.codeEditor.Width = width
-		a.codeEditor.Height = height
-		return a.codeEditor.View()
 	}
 
-	// No data - show placeholder
-	placeholderStyle := lipgloss.NewStyle().
-		Foreground(a.theme.Comment).
-		Width(width).
-		Height(height).
-		Align(lipgloss.Center, lipgloss.Center)
-
-	return placeholderStyle.Render("No data to display\n\nPress Ctrl+E to open SQL editor")
+	return a, cmd
 }
 
-// updatePanelDimensions calculates panel sizes based on window size
-func (a *App) updatePanelDimensions() {
-	if a.state.Width <= 0 || a.state.Height <= 0 {
-		return
-	}
-
-	// Reserve space for top bar (3 lines) and bottom bar (3 lines)
-	// Total: 6 lines, leaving Height - 6 for panels
-	// Note: Panel.Height includes borders, so the actual content area is Height - 6
-	contentHeight := a.state.Height - 6
-	if contentHeight < 5 {
-		contentHeight = 5
-	}
-
-	// Calculate panel widths
-	// Each panel has a border (2 chars wide: left + right borders)
-	// Total border width: 4 chars (2 per panel)
-	leftWidth := (a.state.Width * a.state.LeftPanelWidth) / 100
-	if leftWidth < 20 {
-		leftWidth = 20
-	}
+// handleFilterBuilder handles key events when filter builder is visible
+func (a *App) handleFilterBuilder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.filterBuilder, cmd = a.filterBuilder.Update(msg)
+	return a, cmd
+}
 
-	// Right panel gets remaining width after accounting for left panel and both borders
-	// Subtract 4 to account for borders on both panels (2 chars each)
-	rightWidth := a.state.Width - leftWidth - 4
-	if rightWidth < 20 {
-		rightWidth = 20
-		// If right panel is too small, reduce left panel width
-		leftWidth = a.state.Width - rightWidth - 4
-	}
+// handleJSONBViewer handles key events when JSONB viewer is visible
+func (a *App) handleJSONBViewer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.jsonbViewer, cmd = a.jsonbViewer.Update(msg)
+	return a, cmd
+}
 
-	a.leftPanel.Width = leftWidth
-	a.leftPanel.Height = contentHeight
-	a.rightPanel.Width = rightWidth
-	a.rightPanel.Height = contentHeight
+// handleFavoritesDialog handles key events when favorites dialog is visible
+func (a *App) handleFavoritesDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.favoritesDialog, cmd = a.favoritesDialog.Update(msg)
+	return a, cmd
 }
 
-// updatePanelStyles updates panel styling based on focus with Catppuccin colors
-func (a *App) updatePanelStyles() {
-	// Update legacy FocusedPanel for compatibility
-	if a.state.FocusArea == models.FocusTreeView {
-		a.state.FocusedPanel = models.LeftPanel
-	} else {
-		a.state.FocusedPanel = models.RightPanel
-	}
+// handleSearchInput handles key events when search input is visible
+func (a *App) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.searchInput, cmd = a.searchInput.Update(msg)
+	return a, cmd
+}
 
-	// Left panel style
-	if a.state.FocusArea == models.FocusTreeView {
-		a.leftPanel.Style = lipgloss.NewStyle().
-			BorderForeground(lipgloss.Color("#89b4fa")). // Blue - focused
-			Foreground(lipgloss.Color("#cdd6f4"))        // Text
-	} else {
-		a.leftPanel.Style = lipgloss.NewStyle().
-			BorderForeground(lipgloss.Color("#45475a")). // Surface1 - unfocused
-			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+// getTableColumns returns column info for the current table
+func (a *App) getTableColumns() []models.ColumnInfo {
+	if a.state.TreeSelected == nil || a.state.TreeSelected.Type != models.TreeNodeTypeTable {
+		return nil
 	}
 
-	// Right panel style (focused when DataPanel or SQLEditor)
-	if a.state.FocusArea == models.FocusDataPanel || a.state.FocusArea == models.FocusSQLEditor {
-		a.rightPanel.Style = lipgloss.NewStyle().
-			BorderForeground(lipgloss.Color("#89b4fa")). // Blue - focused
-			Foreground(lipgloss.Color("#cdd6f4"))        // Text
-	} else {
-		a.rightPanel.Style = lipgloss.NewStyle().
-			BorderForeground(lipgloss.Color("#45475a")). // Surface1 - unfocused
-			Foreground(lipgloss.Color("#cdd6f4"))        // Text
+	conn, err := a.connectionManager.GetActive()
+	if err != nil {
+		return nil
 	}
 
-	// Update SQL Editor focused state
-	a.sqlEditor.Focused = (a.state.FocusArea == models.FocusSQLEditor)
+	// Get schema from parent node
+	schemaNode := a.state.TreeSelected.Parent
+	if schemaNode == nil {
+		return nil
+	}
 
-	// Update Code Editor focused state
-	if a.codeEditor != nil {
-		a.codeEditor.Focused = (a.state.FocusArea == models.FocusDataPanel)
+	columns, err := metadata.GetTableColumns(
+		context.Background(),
+		conn.Pool,
+		schemaNode.Label,
+		a.state.TreeSelected.Label,
+	)
+	if err != nil {
+		return nil
 	}
 
-	// Update TableView focused state
-	// DataPanel is focused and CodeEditor is not shown
-	isTableFocused := a.state.FocusArea == models.FocusDataPanel && !a.showCodeEditor
-	a.tableView.Focused = isTableFocused
+	return columns
 }
 
-// nextFocus moves focus to the next region in cycle: TreeView -> DataPanel -> SQLEditor -> TreeView
-func (a *App) nextFocus() {
-	switch a.state.FocusArea {
-	case models.FocusTreeView:
-		a.state.FocusArea = models.FocusDataPanel
-	case models.FocusDataPanel:
-		a.state.FocusArea = models.FocusSQLEditor
-	case models.FocusSQLEditor:
-		a.state.FocusArea = models.FocusTreeView
-	}
-	a.updatePanelStyles()
-}
+// renderConnectionDialog renders the connection dialog centered on screen
+func (a *App) renderConnectionDialog() string {
+	// Center the dialog
+	dialogWidth := 60
+	dialogHeight := 20
 
-// prevFocus moves focus to the previous region in cycle
-func (a *App) prevFocus() {
-	switch a.state.FocusArea {
-	case models.FocusTreeView:
-		a.state.FocusArea = models.FocusSQLEditor
-	case models.FocusDataPanel:
-		a.state.FocusArea = models.FocusTreeView
-	case models.FocusSQLEditor:
-		a.state.FocusArea = models.FocusDataPanel
-	}
-	a.updatePanelStyles()
-}
+	a.connectionDialog.Width = dialogWidth
+	a.connectionDialog.Height = dialogHeight
 
-// isEditingText returns true if user is actively editing text (Tab should insert indent)
-func (a *App) isEditingText() bool {
-	// CodeEditor in edit mode
-	if a.state.FocusArea == models.FocusDataPanel && a.showCodeEditor && a.codeEditor != nil && !a.codeEditor.ReadOnly {
-		return true
+	dialog := a.connectionDialog.View()
+
+	// Center it
+	verticalPadding := (a.state.Height - dialogHeight) / 2
+	horizontalPadding := (a.state.Width - dialogWidth) / 2
+
+	if verticalPadding < 0 {
+		verticalPadding = 0
 	}
-	// SQLEditor expanded and focused
-	if a.state.FocusArea == models.FocusSQLEditor && a.sqlEditor.IsExpanded() {
-		return true
+	if horizontalPadding < 0 {
+		horizontalPadding = 0
 	}
-	return false
-}
 
-// isSQLEditorFocused returns true if SQL editor has focus (compatibility helper)
-func (a *App) isSQLEditorFocused() bool {
-	return a.state.FocusArea == models.FocusSQLEditor
+	style := lipgloss.NewStyle().
+		Padding(verticalPadding, 0, 0, horizontalPadding)
+
+	return style.Render(dialog)
 }
 
-// isPrintableInput checks if a key message is a printable character input
-// that should trigger auto-expand in SQL editor (not a control/shortcut key)
-func isPrintableInput(key string) bool {
-	// Blacklist: keys that should always be shortcuts, not input
-	blacklist := map[string]bool{
-		"tab": true, "shift+tab": true, "backtab": true,
-		"esc": true, "escape": true,
-		"[": true, "]": true,
-		"up": true, "down": true, "left": true, "right": true,
-		"home": true, "end": true, "pgup": true, "pgdown": true,
-		"f1": true, "f2": true, "f3": true, "f4": true, "f5": true,
-		"f6": true, "f7": true, "f8": true, "f9": true, "f10": true,
-		"f11": true, "f12": true,
-	}
+func (a *App) renderPasswordDialog() string {
+	// Center the dialog
+	dialogWidth := 50
+	dialogHeight := 12
 
-	if blacklist[key] {
-		return false
-	}
+	a.passwordDialog.Width = dialogWidth
+	a.passwordDialog.Height = dialogHeight
 
-	// Any key with Ctrl or Alt modifier is a shortcut
-	if strings.HasPrefix(key, "ctrl+") || strings.HasPrefix(key, "alt+") {
-		return false
-	}
+	dialog := a.passwordDialog.View()
 
-	// Single printable characters, space, enter, backspace are input
-	if len(key) == 1 {
-		return true
-	}
+	// Center it
+	verticalPadding := (a.state.Height - dialogHeight) / 2
+	horizontalPadding := (a.state.Width - dialogWidth) / 2
 
-	// Special input keys
-	inputKeys := map[string]bool{
-		"enter": true, "space": true, "backspace": true, "delete": true,
+	if verticalPadding < 0 {
+		verticalPadding = 0
 	}
-	return inputKeys[key]
+	if horizontalPadding < 0 {
+		horizontalPadding = 0
+	}
+
+	style := lipgloss.NewStyle().
+		Padding(verticalPadding, 0, 0, horizontalPadding)
+
+	return style.Render(dialog)
 }
 
-// handleMouseEvent processes mouse events for scrolling and clicking using bubblezone
-func (a *App) handleMouseEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Handle connection dialog mouse events
-	if a.showConnectionDialog {
-		return a.handleConnectionDialogMouse(msg)
+// triggerDiscovery runs discovery in the background and returns a command
+func (a *App) triggerDiscovery() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		instances := a.discoverer.DiscoverAll(ctx)
+		return DiscoveryCompleteMsg{Instances: instances}
 	}
+}
 
-	// Route mouse events to overlays first
-	if a.showError {
-		handled, cmd := a.errorOverlay.HandleMouseClick(msg)
-		if handled {
-			if cmd != nil {
-				return a, cmd
-			}
-		}
-		// Block other mouse events when error overlay is showing
-		return a, nil
-	}
+// loadTree loads the database structure of the active connection and builds
+// its node of the navigation tree
+func (a *App) loadTree() tea.Msg {
+	ctx := context.Background()
 
-	if a.showCommandPalette {
-		// Handle scroll wheel
-		if a.commandPalette.HandleMouseWheel(msg) {
-			return a, nil
-		}
-		// Handle click
-		handled, cmd := a.commandPalette.HandleMouseClick(msg)
-		if handled {
-			if cmd != nil {
-				return a, cmd
-			}
-			return a, nil
-		}
-		// Block other mouse events when command palette is showing
-		return a, nil
+	conn, err := a.connectionManager.GetActive()
+	if err != nil {
+		return TreeLoadedMsg{Err: fmt.Errorf("no active connection: %w", err)}
 	}
 
-	if a.showFilterBuilder {
-		// Handle scroll wheel
-		if a.filterBuilder.HandleMouseWheel(msg) {
-			return a, nil
+	// List every database of the server. The one in use is loaded now, the
+	// others when they are expanded.
+	currentDB := conn.Database
+	names := []string{currentDB}
+	if databases, err := metadata.ListDatabases(ctx, conn.Pool); err != nil {
+		log.Printf("Warning: Failed to list databases: %v", err)
+	} else {
+		names = names[:0]
+		for _, db := range databases {
+			names = append(names, db.Name)
 		}
-		// Handle click
-		handled, cmd := a.filterBuilder.HandleMouseClick(msg)
-		if handled {
-			if cmd != nil {
-				return a, cmd
-			}
-			return a, nil
+		if !slices.Contains(names, currentDB) {
+			names = append(names, currentDB)
+			sort.Strings(names)
 		}
-		// Block other mouse events when filter builder is showing
-		return a, nil
 	}
+	server := models.BuildServerNode(conn.ID, names, currentDB)
 
-	if a.showJSONBViewer {
-		// Handle scroll wheel
-		if a.jsonbViewer.HandleMouseWheel(msg) {
-			return a, nil
+	if dbNode := server.FindByID(fmt.Sprintf("db:%s", currentDB)); dbNode != nil {
+		if err := loadDatabaseObjects(ctx, conn.Pool, dbNode); err != nil {
+			return TreeLoadedMsg{Err: err}
 		}
-		// Handle click
-		handled, cmd := a.jsonbViewer.HandleMouseClick(msg)
-		if handled {
-			if cmd != nil {
-				return a, cmd
-			}
-			return a, nil
-		}
-		// Block other mouse events when JSONB viewer is showing
-		return a, nil
 	}
 
-	if a.showFavorites {
-		// Handle scroll wheel
-		if a.favoritesDialog.HandleMouseWheel(msg) {
-			return a, nil
-		}
-		// Handle click
-		handled, cmd := a.favoritesDialog.HandleMouseClick(msg)
-		if handled {
-			if cmd != nil {
-				return a, cmd
-			}
-			return a, nil
-		}
-		// Block other mouse events when favorites dialog is showing
-		return a, nil
-	}
+	return TreeLoadedMsg{Server: server}
+}
 
-	// Handle structure view tabs (when a table is selected, structure view is shown)
-	if a.currentTable != "" {
-		handled, tabIndex := a.structureView.HandleMouseClick(msg)
-		if handled {
-			// Sync a.currentTab with the clicked tab
-			if tabIndex >= 0 {
-				a.currentTab = tabIndex
-			}
-			return a, nil
-		}
-		// Fall through to normal table handling for the structure view content
-	}
+// loadDatabaseObjects adds the extensions and schemas of a database, with
+// the objects of each schema, to its tree node
+func loadDatabaseObjects(ctx context.Context, pool *connection.Pool, dbNode *models.TreeNode) error {
+	dbName := dbNode.Label
 
-	// Search input doesn't need mouse handling currently
-	if a.showSearch {
-		// Block other mouse events when search is showing
-		return a, nil
+	schemas, err := metadata.ListSchemas(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("failed to load schemas: %w", err)
 	}
 
-	// Handle scroll events (these don't need zone detection)
-	switch msg.Button {
-	case tea.MouseButtonWheelUp:
-		// Check which zone we're scrolling in
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				a.treeView.ScrollUp(3)
-				return a, nil
-			}
-		}
-		// Check table cells or rows for scroll (scroll viewport, not selection)
-		tableScrolled := false
-		for row := 0; row < 100 && !tableScrolled; row++ {
-			for col := 0; col < 50; col++ {
-				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
-				if zone.Get(zoneID).InBounds(msg) {
-					if activeTable := a.getActiveTableView(); activeTable != nil {
-						activeTable.ScrollViewport(-3) // Scroll up
+	// Load extensions at database level (before schemas)
+	extensions, _ := metadata.ListExtensions(ctx, pool)
+	if len(extensions) > 0 {
+		extGroup := models.NewTreeNode(
+			fmt.Sprintf("extensions:%s", dbName),
+			models.TreeNodeTypeExtensionGroup,
+			fmt.Sprintf("Extensions (%d)", len(extensions)),
+		)
+		extGroup.Selectable = false
+
+		for _, ext := range extensions {
+			extNode := models.NewTreeNode(
+				fmt.Sprintf("extension:%s.%s", dbName, ext.Name),
+				models.TreeNodeTypeExtension,
+				fmt.Sprintf("%s v%s", ext.Name, ext.Version),
+			)
+			extNode.Selectable = true
+			extNode.Metadata = ext
+			extNode.Loaded = true
+			extGroup.AddChild(extNode)
+		}
+		extGroup.Loaded = true
+		dbNode.AddChild(extGroup)
+	}
+
+	// Add schema nodes as children
+	for _, schema := range schemas {
+		// Load all objects for this schema
+		tables, _ := metadata.ListTables(ctx, pool, schema.Name)
+		views, _ := metadata.ListViews(ctx, pool, schema.Name)
+		matViews, _ := metadata.ListMaterializedViews(ctx, pool, schema.Name)
+		functions, _ := metadata.ListFunctions(ctx, pool, schema.Name)
+		procedures, _ := metadata.ListProcedures(ctx, pool, schema.Name)
+		triggerFuncs, _ := metadata.ListTriggerFunctions(ctx, pool, schema.Name)
+		sequences, _ := metadata.ListSequences(ctx, pool, schema.Name)
+		compositeTypes, _ := metadata.ListCompositeTypes(ctx, pool, schema.Name)
+		enumTypes, _ := metadata.ListEnumTypes(ctx, pool, schema.Name)
+		domainTypes, _ := metadata.ListDomainTypes(ctx, pool, schema.Name)
+		rangeTypes, _ := metadata.ListRangeTypes(ctx, pool, schema.Name)
+
+		// Count total objects
+		totalObjects := len(tables) + len(views) + len(matViews) + len(functions) +
+			len(procedures) + len(triggerFuncs) + len(sequences) +
+			len(compositeTypes) + len(enumTypes) + len(domainTypes) + len(rangeTypes)
+
+		// Skip empty schemas
+		if totalObjects == 0 {
+			continue
+		}
+
+		// Create schema label
+		schemaLabel := schema.Name
+
+		schemaNode := models.NewTreeNode(
+			fmt.Sprintf("schema:%s.%s", dbName, schema.Name),
+			models.TreeNodeTypeSchema,
+			schemaLabel,
+		)
+		schemaNode.Selectable = true
+
+		// Add Tables group
+		if len(tables) > 0 {
+			tablesGroup := models.NewTreeNode(
+				fmt.Sprintf("tables:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeTableGroup,
+				fmt.Sprintf("Tables (%d)", len(tables)),
+			)
+			tablesGroup.Selectable = false
+
+			for _, table := range tables {
+				tableNode := models.NewTreeNode(
+					fmt.Sprintf("table:%s.%s.%s", dbName, schema.Name, table.Name),
+					models.TreeNodeTypeTable,
+					table.Name,
+				)
+				tableNode.Selectable = true
+
+				// Load indexes and triggers for this table
+				indexes, _ := metadata.ListTableIndexes(ctx, pool, schema.Name, table.Name)
+				triggers, _ := metadata.ListTableTriggers(ctx, pool, schema.Name, table.Name)
+
+				// Add Indexes group under table
+				if len(indexes) > 0 {
+					indexGroup := models.NewTreeNode(
+						fmt.Sprintf("indexes:%s.%s.%s", dbName, schema.Name, table.Name),
+						models.TreeNodeTypeIndexGroup,
+						fmt.Sprintf("Indexes (%d)", len(indexes)),
+					)
+					indexGroup.Selectable = false
+					for _, idx := range indexes {
+						idxNode := models.NewTreeNode(
+							fmt.Sprintf("index:%s.%s.%s.%s", dbName, schema.Name, table.Name, idx.Name),
+							models.TreeNodeTypeIndex,
+							idx.Name,
+						)
+						idxNode.Selectable = true
+						idxNode.Metadata = idx
+						idxNode.Loaded = true
+						indexGroup.AddChild(idxNode)
 					}
-					tableScrolled = true
-					break
+					indexGroup.Loaded = true
+					tableNode.AddChild(indexGroup)
 				}
-			}
-		}
-		if tableScrolled {
-			return a, nil
-		}
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				if activeTable := a.getActiveTableView(); activeTable != nil {
-					activeTable.ScrollViewport(-3) // Scroll up
+
+				// Add Triggers group under table
+				if len(triggers) > 0 {
+					triggerGroup := models.NewTreeNode(
+						fmt.Sprintf("triggers:%s.%s.%s", dbName, schema.Name, table.Name),
+						models.TreeNodeTypeTriggerGroup,
+						fmt.Sprintf("Triggers (%d)", len(triggers)),
+					)
+					triggerGroup.Selectable = false
+					for _, trg := range triggers {
+						trgNode := models.NewTreeNode(
+							fmt.Sprintf("trigger:%s.%s.%s.%s", dbName, schema.Name, table.Name, trg.Name),
+							models.TreeNodeTypeTrigger,
+							trg.Name,
+						)
+						trgNode.Selectable = true
+						trgNode.Metadata = trg
+						trgNode.Loaded = true
+						triggerGroup.AddChild(trgNode)
+					}
+					triggerGroup.Loaded = true
+					tableNode.AddChild(triggerGroup)
 				}
-				return a, nil
+
+				tableNode.Loaded = len(indexes) == 0 && len(triggers) == 0
+				tablesGroup.AddChild(tableNode)
 			}
+			tablesGroup.Loaded = true
+			schemaNode.AddChild(tablesGroup)
 		}
-		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
-			// Could add scroll in editor if needed
-			return a, nil
-		}
-		return a, nil
 
-	case tea.MouseButtonWheelDown:
-		// Check which zone we're scrolling in
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				a.treeView.ScrollDown(3)
-				return a, nil
+		// Add Views group
+		if len(views) > 0 {
+			viewsGroup := models.NewTreeNode(
+				fmt.Sprintf("views:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeViewGroup,
+				fmt.Sprintf("Views (%d)", len(views)),
+			)
+			viewsGroup.Selectable = false
+
+			for _, view := range views {
+				viewNode := models.NewTreeNode(
+					fmt.Sprintf("view:%s.%s.%s", dbName, schema.Name, view.Name),
+					models.TreeNodeTypeView,
+					view.Name,
+				)
+				viewNode.Selectable = true
+				viewNode.Loaded = true
+				viewsGroup.AddChild(viewNode)
 			}
+			viewsGroup.Loaded = true
+			schemaNode.AddChild(viewsGroup)
 		}
-		// Check table cells or rows for scroll (scroll viewport, not selection)
-		tableScrolledDown := false
-		for row := 0; row < 100 && !tableScrolledDown; row++ {
-			for col := 0; col < 50; col++ {
-				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
-				if zone.Get(zoneID).InBounds(msg) {
-					if activeTable := a.getActiveTableView(); activeTable != nil {
-						needsLazyLoad := activeTable.ScrollViewport(3) // Scroll down
-						// Check for lazy loading (only for main table browsing)
-						if needsLazyLoad && !a.resultTabs.HasTabs() {
-							if cmd := a.checkLazyLoad(); cmd != nil {
-								return a, cmd
-							}
-						}
-					}
-					tableScrolledDown = true
-					break
-				}
+
+		// Add Materialized Views group
+		if len(matViews) > 0 {
+			matViewsGroup := models.NewTreeNode(
+				fmt.Sprintf("matviews:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeMaterializedViewGroup,
+				fmt.Sprintf("Materialized Views (%d)", len(matViews)),
+			)
+			matViewsGroup.Selectable = false
+
+			for _, mv := range matViews {
+				mvNode := models.NewTreeNode(
+					fmt.Sprintf("matview:%s.%s.%s", dbName, schema.Name, mv.Name),
+					models.TreeNodeTypeMaterializedView,
+					mv.Name,
+				)
+				mvNode.Selectable = true
+				mvNode.Loaded = true
+				matViewsGroup.AddChild(mvNode)
 			}
+			matViewsGroup.Loaded = true
+			schemaNode.AddChild(matViewsGroup)
 		}
-		if tableScrolledDown {
-			return a, nil
-		}
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				if activeTable := a.getActiveTableView(); activeTable != nil {
-					needsLazyLoad := activeTable.ScrollViewport(3) // Scroll down
-					// Check for lazy loading (only for main table browsing)
-					if needsLazyLoad && !a.resultTabs.HasTabs() {
-						if cmd := a.checkLazyLoad(); cmd != nil {
-							return a, cmd
-						}
-					}
+
+		// Add Functions group
+		if len(functions) > 0 {
+			funcsGroup := models.NewTreeNode(
+				fmt.Sprintf("functions:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeFunctionGroup,
+				fmt.Sprintf("Functions (%d)", len(functions)),
+			)
+			funcsGroup.Selectable = false
+
+			for _, fn := range functions {
+				label := fn.Name
+				if fn.Arguments != "" {
+					label = fmt.Sprintf("%s(%s)", fn.Name, fn.Arguments)
 				}
-				return a, nil
+				fnNode := models.NewTreeNode(
+					fmt.Sprintf("function:%s.%s.%s", dbName, schema.Name, fn.Name),
+					models.TreeNodeTypeFunction,
+					label,
+				)
+				fnNode.Selectable = true
+				fnNode.Metadata = fn
+				fnNode.Loaded = true
+				funcsGroup.AddChild(fnNode)
 			}
+			funcsGroup.Loaded = true
+			schemaNode.AddChild(funcsGroup)
 		}
-		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
-			// Could add scroll in editor if needed
-			return a, nil
-		}
-		return a, nil
 
-	case tea.MouseButtonLeft:
-		if msg.Action != tea.MouseActionPress {
-			return a, nil
-		}
+		// Add Procedures group
+		if len(procedures) > 0 {
+			procsGroup := models.NewTreeNode(
+				fmt.Sprintf("procedures:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeProcedureGroup,
+				fmt.Sprintf("Procedures (%d)", len(procedures)),
+			)
+			procsGroup.Selectable = false
 
-		// Check result tabs first
-		for i := 0; i < components.MaxResultTabs; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneResultTabPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				a.resultTabs.SetActiveTab(i)
-				// Sync SQL editor content with new active tab
-				if activeSQL := a.resultTabs.GetActiveSQL(); activeSQL != "" {
-					a.sqlEditor.SetContent(activeSQL)
+			for _, proc := range procedures {
+				label := proc.Name
+				if proc.Arguments != "" {
+					label = fmt.Sprintf("%s(%s)", proc.Name, proc.Arguments)
 				}
-				return a, nil
+				procNode := models.NewTreeNode(
+					fmt.Sprintf("procedure:%s.%s.%s", dbName, schema.Name, proc.Name),
+					models.TreeNodeTypeProcedure,
+					label,
+				)
+				procNode.Selectable = true
+				procNode.Metadata = proc
+				procNode.Loaded = true
+				procsGroup.AddChild(procNode)
 			}
+			procsGroup.Loaded = true
+			schemaNode.AddChild(procsGroup)
 		}
 
-		// Check tree view rows
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTreeRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				a.state.FocusArea = models.FocusTreeView
-				a.updatePanelStyles()
-				_, cmd := a.treeView.HandleClick(i)
-				return a, cmd
+		// Add Trigger Functions group
+		if len(triggerFuncs) > 0 {
+			trigFuncsGroup := models.NewTreeNode(
+				fmt.Sprintf("triggerfuncs:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeTriggerFunctionGroup,
+				fmt.Sprintf("Trigger Functions (%d)", len(triggerFuncs)),
+			)
+			trigFuncsGroup.Selectable = false
+
+			for _, tf := range triggerFuncs {
+				tfNode := models.NewTreeNode(
+					fmt.Sprintf("triggerfunc:%s.%s.%s", dbName, schema.Name, tf.Name),
+					models.TreeNodeTypeTriggerFunction,
+					tf.Name,
+				)
+				tfNode.Selectable = true
+				tfNode.Metadata = tf
+				tfNode.Loaded = true
+				trigFuncsGroup.AddChild(tfNode)
 			}
+			trigFuncsGroup.Loaded = true
+			schemaNode.AddChild(trigFuncsGroup)
 		}
 
-		// Check table view cells first (more specific than row)
-		for row := 0; row < 100; row++ {
-			for col := 0; col < 50; col++ {
-				zoneID := fmt.Sprintf("%s%d-%d", components.ZoneTableCellPrefix, row, col)
-				if zone.Get(zoneID).InBounds(msg) {
-					a.state.FocusArea = models.FocusDataPanel
-					a.updatePanelStyles()
-					if activeTable := a.getActiveTableView(); activeTable != nil {
-						// Convert visible col index to actual col index
-						actualCol := activeTable.LeftColOffset + col
-						actualRow := activeTable.TopRow + row
+		// Add Sequences group
+		if len(sequences) > 0 {
+			seqsGroup := models.NewTreeNode(
+				fmt.Sprintf("sequences:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeSequenceGroup,
+				fmt.Sprintf("Sequences (%d)", len(sequences)),
+			)
+			seqsGroup.Selectable = false
 
-						// Check if clicking on already selected cell
-						if activeTable.SelectedRow == actualRow && activeTable.SelectedCol == actualCol {
-							// Double-click behavior: open JSONB viewer or preview pane
-							if actualRow >= 0 && actualRow < len(activeTable.Rows) && actualCol >= 0 && actualCol < len(activeTable.Columns) {
-								cellValue := activeTable.Rows[actualRow][actualCol]
-								// Check if it's JSON/JSONB data
-								if jsonb.IsJSONB(cellValue) {
-									if err := a.jsonbViewer.SetValue(cellValue); err == nil {
-										a.showJSONBViewer = true
-									}
-								} else {
-									// For non-JSONB, toggle preview pane
-									activeTable.TogglePreviewPane()
-								}
-							}
-						} else {
-							// First click: just select the cell
-							activeTable.SetSelectedRow(actualRow)
-							activeTable.SelectedCol = actualCol
-						}
-					}
-					return a, nil
-				}
+			for _, seq := range sequences {
+				seqNode := models.NewTreeNode(
+					fmt.Sprintf("sequence:%s.%s.%s", dbName, schema.Name, seq.Name),
+					models.TreeNodeTypeSequence,
+					seq.Name,
+				)
+				seqNode.Selectable = true
+				seqNode.Metadata = seq
+				seqNode.Loaded = true
+				seqsGroup.AddChild(seqNode)
 			}
+			seqsGroup.Loaded = true
+			schemaNode.AddChild(seqsGroup)
 		}
 
-		// Check table view rows (fallback for areas without cell zones)
-		for i := 0; i < 100; i++ {
-			zoneID := fmt.Sprintf("%s%d", components.ZoneTableRowPrefix, i)
-			if zone.Get(zoneID).InBounds(msg) {
-				a.state.FocusArea = models.FocusDataPanel
-				a.updatePanelStyles()
-				if activeTable := a.getActiveTableView(); activeTable != nil {
-					activeTable.SetSelectedRow(activeTable.TopRow + i)
+		// Add Types group (with subgroups)
+		hasTypes := len(compositeTypes) > 0 || len(enumTypes) > 0 || len(domainTypes) > 0 || len(rangeTypes) > 0
+		if hasTypes {
+			typesGroup := models.NewTreeNode(
+				fmt.Sprintf("types:%s.%s", dbName, schema.Name),
+				models.TreeNodeTypeTypeGroup,
+				fmt.Sprintf("Types (%d)", len(compositeTypes)+len(enumTypes)+len(domainTypes)+len(rangeTypes)),
+			)
+			typesGroup.Selectable = false
+
+			// Composite Types
+			if len(compositeTypes) > 0 {
+				compGroup := models.NewTreeNode(
+					fmt.Sprintf("compositetypes:%s.%s", dbName, schema.Name),
+					models.TreeNodeTypeCompositeTypeGroup,
+					fmt.Sprintf("Composite (%d)", len(compositeTypes)),
+				)
+				compGroup.Selectable = false
+				for _, ct := range compositeTypes {
+					ctNode := models.NewTreeNode(
+						fmt.Sprintf("compositetype:%s.%s.%s", dbName, schema.Name, ct.Name),
+						models.TreeNodeTypeCompositeType,
+						ct.Name,
+					)
+					ctNode.Selectable = true
+					ctNode.Loaded = true
+					compGroup.AddChild(ctNode)
 				}
-				return a, nil
+				compGroup.Loaded = true
+				typesGroup.AddChild(compGroup)
 			}
-		}
 
-		// Check SQL editor
-		if zone.Get(components.ZoneSQLEditor).InBounds(msg) {
-			a.state.FocusArea = models.FocusSQLEditor
-			a.updatePanelStyles()
+			// Enum Types
+			if len(enumTypes) > 0 {
+				enumGroup := models.NewTreeNode(
+					fmt.Sprintf("enumtypes:%s.%s", dbName, schema.Name),
+					models.TreeNodeTypeEnumTypeGroup,
+					fmt.Sprintf("Enum (%d)", len(enumTypes)),
+				)
+				enumGroup.Selectable = false
+				for _, et := range enumTypes {
+					etNode := models.NewTreeNode(
+						fmt.Sprintf("enumtype:%s.%s.%s", dbName, schema.Name, et.Name),
+						models.TreeNodeTypeEnumType,
+						et.Name,
+					)
+					etNode.Selectable = true
+					etNode.Metadata = et
+					etNode.Loaded = true
+					enumGroup.AddChild(etNode)
+				}
+				enumGroup.Loaded = true
+				typesGroup.AddChild(enumGroup)
+			}
 
-			// Expand editor if collapsed
-			if !a.sqlEditor.IsExpanded() {
-				a.sqlEditor.Expand()
+			// Domain Types
+			if len(domainTypes) > 0 {
+				domGroup := models.NewTreeNode(
+					fmt.Sprintf("domaintypes:%s.%s", dbName, schema.Name),
+					models.TreeNodeTypeDomainTypeGroup,
+					fmt.Sprintf("Domain (%d)", len(domainTypes)),
+				)
+				domGroup.Selectable = false
+				for _, dt := range domainTypes {
+					dtNode := models.NewTreeNode(
+						fmt.Sprintf("domaintype:%s.%s.%s", dbName, schema.Name, dt.Name),
+						models.TreeNodeTypeDomainType,
+						fmt.Sprintf("%s → %s", dt.Name, dt.BaseType),
+					)
+					dtNode.Selectable = true
+					dtNode.Metadata = dt
+					dtNode.Loaded = true
+					domGroup.AddChild(dtNode)
+				}
+				domGroup.Loaded = true
+				typesGroup.AddChild(domGroup)
 			}
-			return a, nil
+
+			// Range Types
+			if len(rangeTypes) > 0 {
+				rangeGroup := models.NewTreeNode(
+					fmt.Sprintf("rangetypes:%s.%s", dbName, schema.Name),
+					models.TreeNodeTypeRangeTypeGroup,
+					fmt.Sprintf("Range (%d)", len(rangeTypes)),
+				)
+				rangeGroup.Selectable = false
+				for _, rt := range rangeTypes {
+					rtNode := models.NewTreeNode(
+						fmt.Sprintf("rangetype:%s.%s.%s", dbName, schema.Name, rt.Name),
+						models.TreeNodeTypeRangeType,
+						fmt.Sprintf("%s [%s]", rt.Name, rt.Subtype),
+					)
+					rtNode.Selectable = true
+					rtNode.Metadata = rt
+					rtNode.Loaded = true
+					rangeGroup.AddChild(rtNode)
+				}
+				rangeGroup.Loaded = true
+				typesGroup.AddChild(rangeGroup)
+			}
+
+			typesGroup.Loaded = true
+			schemaNode.AddChild(typesGroup)
 		}
 
-		return a, nil
+		schemaNode.Loaded = true
+		dbNode.AddChild(schemaNode)
 	}
+	dbNode.Loaded = true
+	return nil
+}
 
-	return a, nil
+// loadDatabase opens another database of a connection's server and loads
+// its objects into a detached node, so that the tree is only changed on the
+// UI side
+func (a *App) loadDatabase(node *models.TreeNode, use bool) tea.Cmd {
+	connID, id, database := models.GetConnectionFromNode(node), node.ID, node.Label
+	return func() tea.Msg {
+		ctx := context.Background()
+		loaded := models.NewTreeNode(id, models.TreeNodeTypeDatabase, database)
+		pool, err := a.connectionManager.OpenDatabase(ctx, connID, database)
+		if err == nil {
+			err = loadDatabaseObjects(ctx, pool, loaded)
+		}
+		return DatabaseLoadedMsg{ConnID: connID, Node: loaded, Use: use, Err: err}
+	}
 }
 
-// handleConnectionDialogMouse handles mouse events for connection dialog using bubblezone
-func (a *App) handleConnectionDialogMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Handle scroll
-	if msg.Button == tea.MouseButtonWheelUp {
-		a.connectionDialog.MoveSelection(-1)
-		return a, nil
+// connectionColors tell the open connections apart in the tree and the top
+// bar (Catppuccin green, blue, yellow, mauve, peach, teal, pink and red)
+var connectionColors = []string{"#a6e3a1", "#89b4fa", "#f9e2af", "#cba6f7", "#fab387", "#94e2d5", "#f5c2e7", "#f38ba8"}
+
+// connectionColorNames are the color names a connection profile can use
+var connectionColorNames = map[string]string{
+	"green":  "#a6e3a1",
+	"blue":   "#89b4fa",
+	"yellow": "#f9e2af",
+	"mauve":  "#cba6f7",
+	"purple": "#cba6f7",
+	"peach":  "#fab387",
+	"orange": "#fab387",
+	"teal":   "#94e2d5",
+	"pink":   "#f5c2e7",
+	"red":    "#f38ba8",
+}
+
+// pickConnectionColor returns the color a profile asks for, or else the
+// first one no other open connection uses
+func (a *App) pickConnectionColor(color string) string {
+	if color != "" {
+		if hex, ok := connectionColorNames[strings.ToLower(color)]; ok {
+			return hex
+		}
+		return color
 	}
-	if msg.Button == tea.MouseButtonWheelDown {
-		a.connectionDialog.MoveSelection(1)
-		return a, nil
+	used := make(map[string]bool, len(a.connColors))
+	for _, c := range a.connColors {
+		used[c] = true
+	}
+	for _, c := range connectionColors {
+		if !used[c] {
+			return c
+		}
 	}
+	return connectionColors[len(a.connColors)%len(connectionColors)]
+}
 
-	// Only handle left click press
-	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
-		return a, nil
+// connectionColor returns the color of an open connection
+func (a *App) connectionColor(id string) string {
+	if color, ok := a.connColors[id]; ok {
+		return color
 	}
+	return connectionColors[0]
+}
 
-	// Check if click is on search box
-	if zone.Get(components.ZoneSearchBox).InBounds(msg) {
-		a.connectionDialog.EnterSearchMode()
-		return a, nil
+// stateConnection returns the state of an open connection
+func stateConnection(conn *connection.Connection) *models.Connection {
+	return &models.Connection{
+		ID:          conn.ID,
+		Config:      conn.Config,
+		Database:    conn.Database,
+		Connected:   conn.Connected,
+		ConnectedAt: conn.ConnectedAt,
+		LastPing:    conn.LastPing,
+		Error:       conn.Error,
 	}
+}
 
-	// Check history items (up to 5)
-	filteredHistory := a.connectionDialog.GetFilteredHistory()
-	for i := 0; i < 5 && i < len(filteredHistory); i++ {
-		zoneID := fmt.Sprintf("%s%d", components.ZoneHistoryPrefix, i)
-		if zone.Get(zoneID).InBounds(msg) {
-			wasAlreadySelected := a.connectionDialog.InHistorySection && a.connectionDialog.SelectedIndex == i
+// activateConnection makes an open connection the one queries, new tabs
+// and the explorer's actions run against. Each connection keeps its own
+// SQL editor content.
+func (a *App) activateConnection(conn *connection.Connection) {
+	if _, ok := a.connColors[conn.ID]; !ok {
+		a.connColors[conn.ID] = a.pickConnectionColor(conn.Config.Color)
+	}
 
-			a.connectionDialog.InHistorySection = true
-			a.connectionDialog.SelectedIndex = i
+	if active := a.state.ActiveConnection; active != nil && active.ID != conn.ID {
+		a.sqlBuffers[active.ID] = a.sqlEditor.GetContent()
+		a.sqlEditor.SetContent(a.sqlBuffers[conn.ID])
+		delete(a.sqlBuffers, conn.ID)
+	}
 
-			// If clicking already selected item, trigger connect (lazygit-style)
-			if wasAlreadySelected {
-				return a.connectToHistoryEntry(filteredHistory[i])
-			}
-			return a, nil
+	a.state.ActiveConnection = stateConnection(conn)
+	a.resultTabs.Connection = conn.ID
+	a.resultTabs.Database = conn.Database
+	a.sqlEditor.CloseCompletion()
+	a.markActiveNodes()
+}
+
+// useConnection switches to an open connection and one of its databases,
+// the one it uses when database is empty. Switching is refused while a
+// transaction is open, as it stays in its own connection.
+func (a *App) useConnection(id, database string) error {
+	if active := a.state.ActiveConnection; id != "" && (active == nil || active.ID != id) {
+		if a.transaction != nil {
+			return fmt.Errorf("commit or roll back the transaction in %s before switching to %s", active.ID, id)
 		}
+		if err := a.connectionManager.SetActive(id); err != nil {
+			return err
+		}
+		conn, err := a.connectionManager.Get(id)
+		if err != nil {
+			return err
+		}
+		a.activateConnection(conn)
 	}
+	return a.useDatabase(database)
+}
 
-	// Check discovered items (up to 3)
-	filteredDiscovered := a.connectionDialog.GetFilteredDiscovered()
-	for i := 0; i < 3 && i < len(filteredDiscovered); i++ {
-		zoneID := fmt.Sprintf("%s%d", components.ZoneDiscoveredPrefix, i)
-		if zone.Get(zoneID).InBounds(msg) {
-			wasAlreadySelected := !a.connectionDialog.InHistorySection && a.connectionDialog.SelectedIndex == i
+// useTreeNode switches to the connection and database a tree node belongs to
+func (a *App) useTreeNode(node *models.TreeNode) error {
+	return a.useConnection(models.GetConnectionFromNode(node), models.GetDatabaseFromNode(node))
+}
 
-			a.connectionDialog.InHistorySection = false
-			a.connectionDialog.SelectedIndex = i
+// serverNode returns the tree node of an open connection, or nil
+func (a *App) serverNode(id string) *models.TreeNode {
+	if a.treeView.Root == nil {
+		return nil
+	}
+	for _, node := range a.treeView.Root.Children {
+		if node.Type == models.TreeNodeTypeServer && models.GetConnectionFromNode(node) == id {
+			return node
+		}
+	}
+	return nil
+}
 
-			// If clicking already selected item, trigger connect (lazygit-style)
-			if wasAlreadySelected {
-				return a.connectToDiscoveredInstance(filteredDiscovered[i])
+// findTreeNode finds a node by ID in the tree of the active connection, as
+// IDs repeat across connections
+func (a *App) findTreeNode(id string) *models.TreeNode {
+	if a.state.ActiveConnection == nil {
+		return nil
+	}
+	if server := a.serverNode(a.state.ActiveConnection.ID); server != nil {
+		return server.FindByID(id)
+	}
+	return nil
+}
+
+// markActiveNodes marks the active connection and the database it uses in
+// the tree, and colors each connection
+func (a *App) markActiveNodes() {
+	if a.treeView.Root == nil {
+		return
+	}
+	active := a.state.ActiveConnection
+	for _, server := range a.treeView.Root.Children {
+		id := models.GetConnectionFromNode(server)
+		isActive := active != nil && id == active.ID
+		server.Metadata = map[string]interface{}{"active": isActive, "color": a.connectionColor(id)}
+		if !isActive {
+			continue
+		}
+		for _, node := range server.Children {
+			if node.Type == models.TreeNodeTypeDatabase {
+				node.Metadata = map[string]interface{}{"active": node.Label == active.Database}
 			}
-			return a, nil
 		}
 	}
-
-	return a, nil
 }
 
-// connectToHistoryEntry connects using a history entry
-func (a *App) connectToHistoryEntry(entry models.ConnectionHistoryEntry) (tea.Model, tea.Cmd) {
-	var config models.ConnectionConfig
-
-	// Convert history entry to connection config WITH password from keyring
-	if a.connectionHistory != nil {
-		result := a.connectionHistory.GetConnectionConfigWithPassword(&entry)
-		config = result.Config
-
-		// If password is missing, show password dialog
-		if result.PasswordMissing {
-			entryCopy := entry
-			a.pendingConnectionInfo = &entryCopy
-			a.passwordDialog.SetConnectionInfo(entry.Host, entry.Port, entry.Database, entry.User)
-			a.showPasswordDialog = true
-			a.showConnectionDialog = false
-			return a, a.passwordDialog.Init()
-		}
-	} else {
-		config = entry.ToConnectionConfig()
+// useDatabase makes an opened database of the active connection's server
+// the one queries, tabs and the explorer's actions run in. Switching is
+// refused while a transaction is open, as it stays in its own database.
+func (a *App) useDatabase(database string) error {
+	conn := a.state.ActiveConnection
+	if conn == nil || database == "" || database == conn.Database {
+		return nil
+	}
+	if a.transaction != nil {
+		return fmt.Errorf("commit or roll back the transaction in %s before using %s", conn.Database, database)
+	}
+	if err := a.connectionManager.UseDatabase(conn.ID, database); err != nil {
+		return err
 	}
 
-	return a.performConnection(config)
+	conn.Database = database
+	a.resultTabs.Database = database
+	a.sqlEditor.CloseCompletion()
+	a.markActiveNodes()
+	return nil
 }
 
-// connectToDiscoveredInstance connects using a discovered instance
-func (a *App) connectToDiscoveredInstance(instance models.DiscoveredInstance) (tea.Model, tea.Cmd) {
-	// Create connection config from discovered instance
-	config := models.ConnectionConfig{
-		Host:     instance.Host,
-		Port:     instance.Port,
-		Database: "postgres",        // Default database
-		User:     os.Getenv("USER"), // Current user
-		Password: "",                // No password for now
-		SSLMode:  "prefer",
+// useTabConnection switches to the connection and database the active tab
+// was opened in, so that reloading or editing it reaches them
+func (a *App) useTabConnection() {
+	tab := a.resultTabs.GetActiveTab()
+	if tab == nil || tab.Connection == "" {
+		return
 	}
+	if err := a.useConnection(tab.Connection, tab.Database); err != nil {
+		log.Printf("Warning: Failed to switch to %s of %s: %v", tab.Database, tab.Connection, err)
+	}
+}
 
-	return a.performConnection(config)
+// dataTarget is a connection and one of its server's databases
+type dataTarget struct {
+	Connection string // Connection ID, empty for the active connection
+	Database   string // Empty for the database the connection uses
 }
 
-// performConnection executes the actual connection
-func (a *App) performConnection(config models.ConnectionConfig) (tea.Model, tea.Cmd) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// tabTarget returns where the statements of a tab run: the connection and
+// database it was opened in, even after the explorer or the connection
+// switcher moved on to another one
+func tabTarget(tab *components.ResultTab) dataTarget {
+	return dataTarget{Connection: tab.Connection, Database: tab.Database}
+}
 
-	connID, err := a.connectionManager.Connect(ctx, config)
-	if err != nil {
-		a.ShowError("Connection Failed", fmt.Sprintf("Could not connect to %s:%d\n\nError: %v",
-			config.Host, config.Port, err))
-		return a, nil
+// activeTarget returns the connection and database in use
+func (a *App) activeTarget() dataTarget {
+	if conn := a.state.ActiveConnection; conn != nil {
+		return dataTarget{Connection: conn.ID, Database: conn.Database}
 	}
+	return dataTarget{}
+}
 
-	// Update active connection in state
-	conn, err := a.connectionManager.GetActive()
-	if err == nil && conn != nil {
-		a.state.ActiveConnection = &models.Connection{
-			ID:          connID,
-			Config:      config,
-			Connected:   conn.Connected,
-			ConnectedAt: conn.ConnectedAt,
-			LastPing:    conn.LastPing,
-			Error:       conn.Error,
+// targetPool returns the pool of a target's database, opening it if needed
+func (a *App) targetPool(ctx context.Context, target dataTarget) (*connection.Pool, error) {
+	if target.Connection == "" {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return nil, fmt.Errorf("no active connection: %w", err)
 		}
+		return conn.Pool, nil
 	}
+	return a.connectionManager.OpenDatabase(ctx, target.Connection, target.Database)
+}
 
-	// Save to connection history (ignore errors)
-	if a.connectionHistory != nil {
-		result, err := a.connectionHistory.Add(config)
+// loadTableData loads table data with pagination
+func (a *App) loadTableData(msg LoadTableDataMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			log.Printf("Warning: Failed to save connection to history: %v", err)
-		} else {
-			if result != nil && result.PasswordSaveError != nil {
-				log.Printf("Warning: Failed to save password: %v", result.PasswordSaveError)
+			return TableDataLoadedMsg{Err: fmt.Errorf("no active connection: %w", err)}
+		}
+
+		var sort *metadata.SortOptions
+		if msg.SortColumn != "" {
+			sort = &metadata.SortOptions{
+				Column:     msg.SortColumn,
+				Direction:  msg.SortDir,
+				NullsFirst: msg.NullsFirst,
 			}
-			// Reload history in dialog
-			history := a.connectionHistory.GetRecent(10)
-			a.connectionDialog.SetHistoryEntries(history)
 		}
-	}
 
-	// Trigger tree loading
-	a.showConnectionDialog = false
-	return a, func() tea.Msg {
-		return LoadTreeMsg{}
+		data, err := metadata.QueryTableData(ctx, conn.Pool, msg.Schema, msg.Table, msg.Offset, msg.Limit, sort)
+		if err != nil {
+			return TableDataLoadedMsg{Err: err}
+		}
+
+		return TableDataLoadedMsg{
+			Columns:   data.Columns,
+			Rows:      data.Rows,
+			Cells:     data.Cells,
+			TotalRows: int(data.TotalRows),
+			Offset:    msg.Offset,
+		}
 	}
 }
 
-// handleTabClick handles clicking on result tabs
-// handleTabClick is no longer needed - using bubblezone for tab clicks
-
-// formatStatusBar formats a status bar with left and right aligned content
-func (a *App) formatStatusBar(left, right string) string {
-	// Calculate available width accounting for:
-	// - Borders: 2 chars (left + right)
-	// - Padding: 2 chars (left + right, from Padding(0, 1))
-	// Total: 4 chars
-	availableWidth := a.state.Width - 4
-	if availableWidth < 0 {
-		availableWidth = 0
+// loadTableDataForTab loads table data for a specific tab from the database
+// it was opened in, limited to the rows matching the tab's filter
+func (a *App) loadTableDataForTab(tab *components.ResultTab, schema, table string) tea.Cmd {
+	tabID, target := tab.ID, tabTarget(tab)
+	var filter *models.Filter
+	if tab.Structure != nil {
+		filter = tab.Structure.Filter()
 	}
+	return func() tea.Msg {
+		ctx := context.Background()
 
-	// Use lipgloss.Width to get actual display width (ignoring ANSI codes)
-	leftLen := lipgloss.Width(left)
-	rightLen := lipgloss.Width(right)
+		pool, err := a.targetPool(ctx, target)
+		if err != nil {
+			return TabTableDataLoadedMsg{TabID: tabID, Err: err}
+		}
 
-	// If content is too wide, truncate
-	if leftLen+rightLen > availableWidth {
-		if availableWidth > rightLen {
-			// Simple truncation - in a real app you'd want smarter truncation
-			return left + right
+		var data *metadata.TableData
+		if filter != nil {
+			var where string
+			var args []interface{}
+			where, args, err = filterBuilder.NewBuilder().BuildWhere(*filter)
+			if err == nil {
+				data, err = metadata.QueryFilteredTableData(ctx, pool, schema, table, where, args, 100)
+			}
+		} else {
+			data, err = metadata.QueryTableData(ctx, pool, schema, table, 0, 100, nil)
+		}
+		if err != nil {
+			return TabTableDataLoadedMsg{TabID: tabID, Err: err}
 		}
-		return left
-	}
 
-	// Calculate spacing between left and right content
-	spacing := availableWidth - leftLen - rightLen
-	if spacing < 0 {
-		spacing = 0
+		return TabTableDataLoadedMsg{
+			TabID:     tabID,
+			Schema:    schema,
+			Table:     table,
+			Columns:   data.Columns,
+			Rows:      data.Rows,
+			Cells:     data.Cells,
+			TotalRows: int(data.TotalRows),
+		}
 	}
-
-	return left + lipgloss.NewStyle().Width(spacing).Render("") + right
 }
 
-// handleConnectionDialog handles key events when connection dialog is visible
-func (a *App) handleConnectionDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle search mode
-	if a.connectionDialog.SearchMode {
-		switch msg.String() {
-		case "esc":
-			// Exit search mode and clear search
-			a.connectionDialog.ExitSearchMode(true)
-			return a, nil
-		case "enter":
-			// Exit search mode but keep search results
-			a.connectionDialog.ExitSearchMode(false)
-			return a, nil
-		default:
-			// Pass keys to search input
-			var cmd tea.Cmd
-			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
-			return a, cmd
+// loadTableDataWithFilter loads table data with an applied filter
+func (a *App) loadTableDataWithFilter(filter models.Filter) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ErrorMsg{Title: "Connection Error", Message: err.Error()}
 		}
-	}
-
-	switch msg.String() {
-	case "esc":
-		a.showConnectionDialog = false
-		return a, nil
 
-	case "/", "ctrl+f":
-		// Enter search mode (only in discovery mode)
-		if !a.connectionDialog.ManualMode {
-			a.connectionDialog.EnterSearchMode()
+		node := a.state.TreeSelected
+		if node == nil || node.Type != models.TreeNodeTypeTable {
+			return ErrorMsg{Title: "Error", Message: "No table selected"}
 		}
-		return a, nil
 
-	case "up", "k":
-		if !a.connectionDialog.ManualMode {
-			a.connectionDialog.MoveSelection(-1)
+		// Get schema from parent node
+		schemaNode := node.Parent
+		if schemaNode == nil {
+			return ErrorMsg{Title: "Error", Message: "Cannot determine schema"}
 		}
-		return a, nil
 
-	case "down", "j":
-		if !a.connectionDialog.ManualMode {
-			a.connectionDialog.MoveSelection(1)
+		// Build filtered query
+		builder := filterBuilder.NewBuilder()
+		whereClause, args, err := builder.BuildWhere(filter)
+		if err != nil {
+			return ErrorMsg{Title: "Filter Error", Message: err.Error()}
 		}
-		return a, nil
 
-	case "tab":
-		if a.connectionDialog.ManualMode {
-			a.connectionDialog.NextInput()
-		} else {
-			// In discovery mode, switch between history and discovered sections
-			a.connectionDialog.SwitchSection()
-		}
-		return a, nil
+		// Construct query
+		query := fmt.Sprintf(
+			`SELECT * FROM "%s"."%s" %s LIMIT 100`,
+			schemaNode.Label,
+			node.Label,
+			whereClause,
+		)
 
-	case "shift+tab":
-		if a.connectionDialog.ManualMode {
-			a.connectionDialog.PrevInput()
+		// Execute query
+		data, err := metadata.QueryRows(context.Background(), conn.Pool, query, args...)
+		if err != nil {
+			return ErrorMsg{Title: "Query Error", Message: err.Error()}
 		}
-		return a, nil
 
-	case "m":
-		// Only handle 'm' key in discovery mode, not in manual mode (to allow typing 'm')
-		if !a.connectionDialog.ManualMode {
-			a.connectionDialog.ToggleMode()
-			return a, nil
+		return TableDataLoadedMsg{
+			Columns:   data.Columns,
+			Rows:      data.Rows,
+			Cells:     data.Cells,
+			TotalRows: len(data.Rows),
+			Offset:    0,
 		}
-		// In manual mode, pass 'm' to textinput
-		var cmd tea.Cmd
-		a.connectionDialog, cmd = a.connectionDialog.Update(msg)
-		return a, cmd
+	}
+}
 
-	case "ctrl+d":
-		// Use Ctrl+D to switch back to discovery mode to avoid conflict with typing 'd'
-		if a.connectionDialog.ManualMode {
-			a.connectionDialog.ToggleMode()
-		}
-		return a, nil
+// ShowError displays an error overlay with the given title and message
+func (a *App) ShowError(title, message string) {
+	a.errorOverlay.SetError(title, message)
+	a.showError = true
+}
 
-	case "enter":
-		if a.connectionDialog.ManualMode {
-			config, err := a.connectionDialog.GetManualConfig()
-			if err != nil {
-				// Invalid input - show error and don't close dialog
-				a.ShowError("Invalid Configuration", fmt.Sprintf("Could not parse connection configuration\n\nError: %v", err))
-				return a, nil
-			}
+// DismissError hides the error overlay
+func (a *App) DismissError() {
+	a.showError = false
+}
 
-			// Connect using manual configuration
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
+// runScriptStatement runs the next statement of a script
+func (a *App) runScriptStatement(run *scriptRun) tea.Cmd {
+	index := len(run.results)
+	sql, args, err := query.Bind(run.statements[index].SQL, run.values)
+	if err != nil {
+		return func() tea.Msg {
+			return ScriptStatementResultMsg{Run: run, Index: index, Result: models.QueryResult{Error: err}}
+		}
+	}
+	return func() tea.Msg {
+		return ScriptStatementResultMsg{Run: run, Index: index, Result: run.runner.Execute(run.ctx, sql, args...)}
+	}
+}
 
-			connID, err := a.connectionManager.Connect(ctx, config)
-			if err != nil {
-				// Show error overlay
-				a.ShowError("Connection Failed", fmt.Sprintf("Could not connect to %s:%d\n\nError: %v",
-					config.Host, config.Port, err))
-				return a, nil
-			}
+// finishScript releases the script's connection and shows its summary
+func (a *App) finishScript() tea.Cmd {
+	run := a.script
+	a.script = nil
+	a.executeCancelFn = nil
+	a.execution = nil
 
-			// Update active connection in state
-			conn, err := a.connectionManager.GetActive()
-			if err == nil && conn != nil {
-				a.state.ActiveConnection = &models.Connection{
-					ID:          connID,
-					Config:      config,
-					Connected:   conn.Connected,
-					ConnectedAt: conn.ConnectedAt,
-					LastPing:    conn.LastPing,
-					Error:       conn.Error,
-				}
+	var cmd tea.Cmd
+	if run.session != nil {
+		// A transaction the script opened and left open carries on in
+		// transaction mode
+		if tx := run.session.Close(); tx != nil {
+			if a.state.ActiveConnection != nil {
+				a.transaction = tx
+				cmd = a.transactionTick(tx)
+			} else {
+				go func() {
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer cancel()
+					_ = tx.Rollback(ctx)
+				}()
 			}
+		}
+	}
 
-			// Save to connection history (ignore errors)
-			if a.connectionHistory != nil {
-				result, err := a.connectionHistory.Add(config)
-				if err != nil {
-					log.Printf("Warning: Failed to save connection to history: %v", err)
-				} else {
-					if result != nil && result.PasswordSaveError != nil {
-						log.Printf("Warning: Failed to save password: %v", result.PasswordSaveError)
-					}
-					// Reload history in dialog
-					history := a.connectionHistory.GetRecent(10)
-					a.connectionDialog.SetHistoryEntries(history)
-				}
-			}
+	// The summary is shown first when something failed or no statement
+	// returned rows; otherwise the last result stays in front
+	summary, title, failed := scriptSummary(run)
+	focus := true
+	for _, result := range run.results {
+		if len(result.Columns) > 0 {
+			focus = false
+			break
+		}
+	}
+	a.resultTabs.FinishScript(run.sql, title, summary, focus || failed)
+	return cmd
+}
 
-			// Trigger tree loading
-			a.showConnectionDialog = false
-			return a, func() tea.Msg {
-				return LoadTreeMsg{}
+// scriptSummary builds the summary tab of a script: one row per statement
+// with its command tag or error. failed reports whether a statement failed.
+func scriptSummary(run *scriptRun) (summary models.QueryResult, title string, failed bool) {
+	summary.Columns = []string{"#", "Status", "Statement", "Result", "Time"}
+	errorCount := 0
+	cancelled := false
+	for i, stmt := range run.statements {
+		text := runewidth.Truncate(strings.Join(strings.Fields(stmt.SQL), " "), 60, "…")
+		row := []string{strconv.Itoa(i + 1), "skipped", text, "", ""}
+		if i < len(run.results) {
+			result := run.results[i]
+			summary.Duration += result.Duration
+			row[4] = result.Duration.Round(time.Millisecond).String()
+			switch {
+			case result.Error != nil && executionCancelled(run.exec, result.Error):
+				row[1] = "cancelled"
+				cancelled = true
+			case result.Error != nil:
+				row[1] = "error"
+				row[3] = result.Error.Error()
+				errorCount++
+			default:
+				row[1] = "ok"
+				row[3] = result.Command
 			}
-		} else {
-			var config models.ConnectionConfig
+		}
+		summary.Rows = append(summary.Rows, row)
+	}
+	summary.RowsAffected = int64(len(summary.Rows))
 
-			// Check if browsing history or discovered instances
-			if a.connectionDialog.InHistorySection {
-				// Get selected history entry
-				historyEntry := a.connectionDialog.GetSelectedHistory()
-				if historyEntry == nil {
-					// No history entry selected
-					return a, nil
-				}
+	switch {
+	case cancelled:
+		title = "Script (cancelled)"
+	case errorCount > 0:
+		title = fmt.Sprintf("Script (%d failed)", errorCount)
+	default:
+		title = fmt.Sprintf("Script (%d)", len(run.statements))
+	}
+	return summary, title, errorCount > 0
+}
 
-				// Convert history entry to connection config WITH password from keyring
-				if a.connectionHistory != nil {
-					result := a.connectionHistory.GetConnectionConfigWithPassword(historyEntry)
-					config = result.Config
-
-					// If password is missing, show password dialog
-					if result.PasswordMissing {
-						entryCopy := *historyEntry
-						a.pendingConnectionInfo = &entryCopy
-						a.passwordDialog.SetConnectionInfo(historyEntry.Host, historyEntry.Port, historyEntry.Database, historyEntry.User)
-						a.showPasswordDialog = true
-						a.showConnectionDialog = false
-						return a, a.passwordDialog.Init()
-					}
-				} else {
-					config = historyEntry.ToConnectionConfig()
-				}
-			} else {
-				// Get selected discovered instance
-				instance := a.connectionDialog.GetSelectedInstance()
-				if instance == nil {
-					// No instance selected
-					return a, nil
-				}
+// queryTimeout returns the statement_timeout for queries run from the editor
+func (a *App) queryTimeout() time.Duration {
+	timeout := config.GetDefaults().Performance.QueryTimeout
+	if a.config != nil {
+		timeout = a.config.Performance.QueryTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
 
-				// Create connection config from discovered instance
-				// Note: We'll need to prompt for database/user/password in future
-				// For now, use common defaults
-				config = models.ConnectionConfig{
-					Host:     instance.Host,
-					Port:     instance.Port,
-					Database: "postgres",          // Default database
-					User:     os.Getenv("USER"),   // Current user
-					Password: "",                  // No password for now
-					SSLMode:  "prefer",
-				}
-			}
+// startExecution tracks a new execution from the editor and returns a
+// context that carries it
+func (a *App) startExecution(ctx context.Context) context.Context {
+	a.execution = query.NewExecution(a.queryTimeout())
+	a.cancelNotice = ""
+	return query.WithExecution(ctx, a.execution)
+}
 
-			// Connect using the configuration
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
+// cancelExecution asks the server to cancel the running statement with
+// pg_cancel_backend. Before the statement reaches the server, or when Esc is
+// pressed again, the context is cancelled instead, which closes the
+// connection.
+func (a *App) cancelExecution() tea.Cmd {
+	exec := a.execution
+	if exec == nil || exec.PID() == 0 || exec.CancelRequested() {
+		a.abortExecution()
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		signalled, err := exec.Cancel(ctx)
+		return QueryCancelSentMsg{Exec: exec, Signalled: signalled, Err: err}
+	}
+}
 
-			connID, err := a.connectionManager.Connect(ctx, config)
-			if err != nil {
-				// Show error overlay
-				a.ShowError("Connection Failed", fmt.Sprintf("Could not connect to %s:%d\n\nError: %v",
-					config.Host, config.Port, err))
-				return a, nil
-			}
+// abortExecution cancels the running execution's context and drops its
+// pending tab
+func (a *App) abortExecution() {
+	if a.executeCancelFn != nil {
+		a.executeCancelFn()
+		a.executeCancelFn = nil
+	}
+	a.execution = nil
+	a.resultTabs.CancelPendingQuery()
+}
 
-			// Update active connection in state
-			conn, err := a.connectionManager.GetActive()
-			if err == nil && conn != nil {
-				a.state.ActiveConnection = &models.Connection{
-					ID:          connID,
-					Config:      config,
-					Connected:   conn.Connected,
-					ConnectedAt: conn.ConnectedAt,
-					LastPing:    conn.LastPing,
-					Error:       conn.Error,
-				}
-			}
+// setCancelNotice shows the outcome of a cancellation in the top bar for a
+// while
+func (a *App) setCancelNotice(notice string) {
+	a.cancelNotice = notice
+	a.cancelNoticeAt = time.Now()
+}
 
-			// Save to connection history (ignore errors)
-			if a.connectionHistory != nil {
-				result, err := a.connectionHistory.Add(config)
-				if err != nil {
-					log.Printf("Warning: Failed to save connection to history: %v", err)
-				} else {
-					if result != nil && result.PasswordSaveError != nil {
-						log.Printf("Warning: Failed to save password: %v", result.PasswordSaveError)
-					}
-					// Reload history in dialog
-					history := a.connectionHistory.GetRecent(10)
-					a.connectionDialog.SetHistoryEntries(history)
-				}
-			}
+// executionCancelled reports whether err ended a statement because it was
+// cancelled at the user's request, rather than by statement_timeout
+func executionCancelled(exec *query.Execution, err error) bool {
+	return errors.Is(err, context.Canceled) ||
+		exec != nil && exec.CancelRequested() && query.IsCanceled(err)
+}
 
-			// Trigger tree loading
-			a.showConnectionDialog = false
-			return a, func() tea.Msg {
-				return LoadTreeMsg{}
-			}
+// executionStatus renders the top bar indicator of the running execution,
+// or the outcome of the last cancellation
+func (a *App) executionStatus() string {
+	styles := a.cachedStyles
+	if exec := a.execution; exec != nil {
+		status := fmt.Sprintf("⏱ running %s", exec.Elapsed().Truncate(100*time.Millisecond))
+		if pid := exec.PID(); pid != 0 {
+			status += fmt.Sprintf(" (pid %d)", pid)
 		}
+		if exec.CancelRequested() {
+			return "  " + styles.txStyle.Render(status) + styles.dimStyle.Render("  cancelling… Esc again to close the connection")
+		}
+		return "  " + styles.dimStyle.Render(status)
+	}
+	if a.cancelNotice != "" && time.Since(a.cancelNoticeAt) < 10*time.Second {
+		return "  " + styles.txStyle.Render(a.cancelNotice)
+	}
+	return ""
+}
 
-	default:
-		// In manual mode, delegate to textinput for cursor and text handling
-		if a.connectionDialog.ManualMode {
-			var cmd tea.Cmd
-			a.connectionDialog, cmd = a.connectionDialog.Update(msg)
-			return a, cmd
+// beginTransaction opens a transaction with beginSQL on a pinned connection
+func (a *App) beginTransaction(beginSQL string) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return TransactionBeganMsg{Err: fmt.Errorf("failed to get connection: %w", err)}
 		}
-		return a, nil
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ctx = query.WithExecution(ctx, query.NewExecution(a.queryTimeout()))
+		tx, err := query.Begin(ctx, conn.Pool, beginSQL)
+		return TransactionBeganMsg{Tx: tx, Err: err}
 	}
 }
 
-// getBuiltinCommands returns built-in commands with icons
-func (a *App) getBuiltinCommands() []models.Command {
-	cmds := a.commandRegistry.GetAll()
-	// Ensure commands have icons
-	for i := range cmds {
-		if cmds[i].Icon == "" {
-			cmds[i].Icon = "▸"
+// endTransaction commits or rolls back tx, then dispatches then if set
+func (a *App) endTransaction(tx *query.Transaction, commit bool, then tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		var err error
+		if commit {
+			err = tx.Commit(ctx)
+		} else {
+			err = tx.Rollback(ctx)
 		}
+		return TransactionEndedMsg{Tx: tx, Commit: commit, Then: then, Err: err}
 	}
-	return cmds
 }
 
-// getTableCommands returns tables and views as commands
-func (a *App) getTableCommands() []models.Command {
-	var cmds []models.Command
+// confirmEndTransaction asks before an action that rolls back the open
+// transaction, such as quitting. The action is dispatched after the rollback.
+func (a *App) confirmEndTransaction(title string, action tea.Msg) tea.Cmd {
+	tx := a.transaction
+	details := fmt.Sprintf("%d statement(s) run, open for %s.", tx.Statements(), tx.Elapsed().Truncate(time.Second))
+	if tx.Failed() {
+		details = "The transaction has failed; nothing in it can be committed.\n" + details
+	}
+	a.confirmDialog.SetConfirm(
+		title,
+		"A transaction is still open. Roll it back and continue?",
+		details,
+		EndTransactionMsg{Then: action},
+	)
+	a.showConfirm = true
+	return nil
+}
 
-	if a.treeView.Root == nil {
-		return cmds
+// transactionTick schedules the next refresh of the transaction status
+func (a *App) transactionTick(tx *query.Transaction) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return TransactionTickMsg{Tx: tx}
+	})
+}
+
+// transactionStatus renders the top bar indicator of the open transaction
+func (a *App) transactionStatus() string {
+	tx := a.transaction
+	if tx == nil || !tx.Open() {
+		return ""
 	}
+	styles := a.cachedStyles
+	if tx.Failed() {
+		return "  " + styles.txFailedStyle.Render("● transaction failed, rollback required") +
+			styles.dimStyle.Render("  Alt+R rollback")
+	}
+	statements := "statements"
+	if tx.Statements() == 1 {
+		statements = "statement"
+	}
+	status := fmt.Sprintf("● in transaction (%d %s, %s)", tx.Statements(), statements, tx.Elapsed().Truncate(time.Second))
+	return "  " + styles.txStyle.Render(status) + styles.dimStyle.Render("  Alt+C commit · Alt+R rollback")
+}
 
-	// Traverse tree to find all tables and views
-	var traverse func(node *models.TreeNode)
-	traverse = func(node *models.TreeNode) {
-		if node == nil {
-			return
-		}
+// quit exits, asking first when a transaction is open
+func (a *App) quit() tea.Cmd {
+	if a.transaction != nil {
+		return a.confirmEndTransaction("Quit", tea.QuitMsg{})
+	}
+	return tea.Quit
+}
 
-		if node.Type == models.TreeNodeTypeTable || node.Type == models.TreeNodeTypeView {
-			// Get schema name from parent chain
-			var schemaName string
-			parent := node.Parent
-			for parent != nil {
-				if parent.Type == models.TreeNodeTypeSchema {
-					schemaName = strings.Split(parent.Label, " ")[0]
-					break
-				}
-				parent = parent.Parent
+// disconnect closes an open connection with its tabs. Closing the active
+// one switches to another open connection, or clears the views that depend
+// on one.
+func (a *App) disconnect(id string) {
+	active := a.state.ActiveConnection != nil && a.state.ActiveConnection.ID == id
+	if active {
+		if a.executeCancelFn != nil {
+			a.abortExecution()
+		}
+		if a.fetchCancelFn != nil {
+			a.fetchCancelFn()
+			a.fetchCancelFn = nil
+		}
+		a.sqlEditor.CloseCompletion()
+		a.state.TreeSelected = nil
+		a.currentTable = ""
+		a.activeFilter = nil
+		a.showActivity = false
+		a.showERDiagram = false
+		a.showSchemaDiffDialog = false
+		a.showSchemaDiff = false
+	}
+
+	a.resultTabs.CloseConnectionTabs(id)
+	a.forgetConnection(id)
+	if server := a.serverNode(id); server != nil {
+		root := a.treeView.Root
+		root.Children = slices.DeleteFunc(root.Children, func(node *models.TreeNode) bool {
+			return node == server
+		})
+		a.treeView.CursorIndex = 0
+	}
+
+	if active {
+		// Continue with the connection of the active tab, or the first one left
+		next, database := "", ""
+		if tab := a.resultTabs.GetActiveTab(); tab != nil {
+			next, database = tab.Connection, tab.Database
+		} else if len(a.treeView.Root.Children) > 0 {
+			next = models.GetConnectionFromNode(a.treeView.Root.Children[0])
+		}
+		if next != "" {
+			if err := a.useConnection(next, database); err != nil {
+				log.Printf("Warning: Failed to switch to %s: %v", next, err)
 			}
-			if schemaName != "" {
-				icon := "▦"
-				prefix := "table:"
-				if node.Type == models.TreeNodeTypeView {
-					icon = "◎"
-					prefix = "view:"
-				}
-				cmds = append(cmds, models.Command{
-					ID:          fmt.Sprintf("%s%s.%s", prefix, schemaName, node.Label),
-					Label:       fmt.Sprintf("%s.%s", schemaName, node.Label),
-					Description: "",
-					Icon:        icon,
-					Tags:        []string{schemaName, node.Label},
-				})
+		}
+		if a.state.ActiveConnection.ID == id {
+			a.state.ActiveConnection = nil
+			a.resultTabs.Connection = ""
+			a.resultTabs.Database = ""
+		}
+	}
+	delete(a.sqlBuffers, id)
+
+	// Closing the pool waits for connections still in use, e.g. by the
+	// sources closed above
+	go func() {
+		if err := a.connectionManager.Disconnect(id); err != nil {
+			log.Printf("Warning: Failed to disconnect: %v", err)
+		}
+	}()
+}
+
+// forgetConnection drops the completion metadata and color of a connection
+// that is closed or replaced
+func (a *App) forgetConnection(id string) {
+	for key := range a.completionCaches {
+		if strings.HasPrefix(key, id+"/") {
+			delete(a.completionCaches, key)
+		}
+	}
+	delete(a.connColors, id)
+}
+
+// overlayCommandPalette renders the command palette as an overlay on top of background
+func (a *App) overlayCommandPalette(background string) string {
+	paletteView := a.commandPalette.View()
+	paletteLines := strings.Split(paletteView, "\n")
+	bgLines := strings.Split(background, "\n")
+
+	// Calculate center position
+	paletteHeight := len(paletteLines)
+	paletteWidth := lipgloss.Width(paletteLines[0]) // Use first line width
+
+	startY := (a.state.Height - paletteHeight) / 2
+	startX := (a.state.Width - paletteWidth) / 2
+
+	if startY < 0 {
+		startY = 0
+	}
+	if startX < 0 {
+		startX = 0
+	}
+
+	// Overlay palette on background
+	result := make([]string, len(bgLines))
+	for i, bgLine := range bgLines {
+		if i >= startY && i < startY+paletteHeight {
+			paletteLineIdx := i - startY
+			if paletteLineIdx < len(paletteLines) {
+				// Overlay this palette line onto background
+				result[i] = a.overlayLine(bgLine, paletteLines[paletteLineIdx], startX)
+			} else {
+				result[i] = bgLine
 			}
+		} else {
+			result[i] = bgLine
 		}
+	}
 
-		for _, child := range node.Children {
-			traverse(child)
+	return strings.Join(result, "\n")
+}
+
+// overlaySearchInput renders the search input as an overlay on top of background
+func (a *App) overlaySearchInput(background string) string {
+	searchView := a.searchInput.View()
+	searchLines := strings.Split(searchView, "\n")
+	bgLines := strings.Split(background, "\n")
+
+	// Calculate center position
+	searchHeight := len(searchLines)
+	searchWidth := lipgloss.Width(searchLines[0]) // Use first line width
+
+	startY := (a.state.Height - searchHeight) / 2
+	startX := (a.state.Width - searchWidth) / 2
+
+	if startY < 0 {
+		startY = 0
+	}
+	if startX < 0 {
+		startX = 0
+	}
+
+	// Overlay search box on background
+	result := make([]string, len(bgLines))
+	for i, bgLine := range bgLines {
+		if i >= startY && i < startY+searchHeight {
+			searchLineIdx := i - startY
+			if searchLineIdx < len(searchLines) {
+				// Overlay this search line onto background
+				result[i] = a.overlayLine(bgLine, searchLines[searchLineIdx], startX)
+			} else {
+				result[i] = bgLine
+			}
+		} else {
+			result[i] = bgLine
 		}
 	}
 
-	traverse(a.treeView.Root)
-	return cmds
+	return strings.Join(result, "\n")
 }
 
-// getHistoryCommands returns query history as commands
-func (a *App) getHistoryCommands() []models.Command {
-	var cmds []models.Command
+// overlayLine overlays foreground onto background at given x position
+// Handles ANSI escape sequences correctly
+func (a *App) overlayLine(background, foreground string, startX int) string {
+	fgWidth := ansi.StringWidth(foreground)
+
+	// Truncate background to startX (visual width)
+	leftPart := ansi.Truncate(background, startX, "")
 
-	if a.historyStore == nil {
-		return cmds
-	}
+	// Get visible width of left part
+	leftWidth := ansi.StringWidth(leftPart)
 
-	entries, err := a.historyStore.GetRecent(20)
-	if err != nil {
-		return cmds
+	// Pad if needed
+	if leftWidth < startX {
+		leftPart += strings.Repeat(" ", startX-leftWidth)
 	}
 
-	for _, entry := range entries {
-		// Truncate long queries for display
-		displayQuery := entry.Query
-		if len(displayQuery) > 60 {
-			displayQuery = displayQuery[:57] + "..."
-		}
+	// Cut the right part of background after the overlay
+	rightStart := startX + fgWidth
+	bgWidth := ansi.StringWidth(background)
 
-		cmds = append(cmds, models.Command{
-			ID:          fmt.Sprintf("history:%d", entry.ID),
-			Type:        models.CommandTypeHistory,
-			Label:       displayQuery,
-			Description: fmt.Sprintf("From %s • %s", entry.DatabaseName, entry.ExecutedAt.Format("Jan 2 15:04")),
-			Icon:        "📜",
-			Tags:        []string{"history", entry.DatabaseName},
-			Action: func(sql string) tea.Cmd {
-				return func() tea.Msg {
-					return components.ExecuteQueryMsg{SQL: sql}
-				}
-			}(entry.Query),
-		})
+	var rightPart string
+	if rightStart < bgWidth {
+		// Cut from background starting at rightStart position
+		rightPart = ansi.Cut(background, rightStart, bgWidth)
 	}
 
-	return cmds
+	return leftPart + foreground + rightPart
 }
 
-// handleCommandPalette handles key events when command palette is visible
-func (a *App) handleCommandPalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle close message
-	var cmd tea.Cmd
-	a.commandPalette, cmd = a.commandPalette.Update(msg)
-
-	// Check if we got a close message
-	if msg.String() == "esc" || msg.String() == "ctrl+c" {
-		a.showCommandPalette = false
-		return a, nil
-	}
-
-	// Execute the command if Enter was pressed
-	if msg.String() == "enter" {
-		a.showCommandPalette = false
+// SearchTableResultMsg is sent when table search completes
+type SearchTableResultMsg struct {
+	Query   string
+	Data    *metadata.TableData
+	Err     error
+}
 
-		selected := a.commandPalette.GetSelectedCommand()
-		if selected == nil {
-			return a, nil
+// searchTable executes a table-wide search
+func (a *App) searchTable(query string) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return SearchTableResultMsg{Query: query, Err: fmt.Errorf("no active connection: %w", err)}
 		}
 
-		// Handle table/view selection (ID starts with "table:" or "view:")
-		if strings.HasPrefix(selected.ID, "table:") || strings.HasPrefix(selected.ID, "view:") {
-			// Parse schema.table from ID (format: "table:schema.name" or "view:schema.name")
-			var prefix string
-			if strings.HasPrefix(selected.ID, "table:") {
-				prefix = "table:"
-			} else {
-				prefix = "view:"
-			}
-			parts := strings.SplitN(strings.TrimPrefix(selected.ID, prefix), ".", 2)
-			if len(parts) == 2 {
-				schema := parts[0]
-				table := parts[1]
-				a.currentTable = schema + "." + table
+		parts := strings.Split(a.currentTable, ".")
+		if len(parts) != 2 {
+			return SearchTableResultMsg{Query: query, Err: fmt.Errorf("invalid table: %s", a.currentTable)}
+		}
 
-				// Sync tree view position - find the node and expand ancestors
-				if a.state.ActiveConnection != nil {
-					dbName := a.state.ActiveConnection.Config.Database
-					nodeID := fmt.Sprintf("%s%s.%s.%s", prefix, dbName, schema, table)
-					a.treeView.ExpandAndNavigateToNode(nodeID)
-				}
+		schema, table := parts[0], parts[1]
 
-				return a, func() tea.Msg {
-					return LoadTableDataMsg{
-						Schema: schema,
-						Table:  table,
-						Offset: 0,
-						Limit:  100,
-					}
-				}
-			}
+		data, err := metadata.SearchTableData(
+			context.Background(),
+			conn.Pool,
+			schema,
+			table,
+			a.tableView.Columns,
+			query,
+			500, // Max results
+		)
+		if err != nil {
+			return SearchTableResultMsg{Query: query, Err: err}
 		}
 
-		// Handle regular command with action
-		if selected.Action != nil {
-			return a, selected.Action
-		}
+		return SearchTableResultMsg{Query: query, Data: data}
 	}
-
-	return a, cmd
 }
 
-// handleFilterBuilder handles key events when filter builder is visible
-func (a *App) handleFilterBuilder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	a.filterBuilder, cmd = a.filterBuilder.Update(msg)
-	return a, cmd
-}
+// openExternalEditor opens the content in an external editor
+func (a *App) openExternalEditor(content string) tea.Cmd {
+	return func() tea.Msg {
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			editor = "vim"
+		}
 
-// handleJSONBViewer handles key events when JSONB viewer is visible
-func (a *App) handleJSONBViewer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	a.jsonbViewer, cmd = a.jsonbViewer.Update(msg)
-	return a, cmd
-}
+		// Create temp file
+		tmpFile, err := os.CreateTemp("", "lazypg-*.sql")
+		if err != nil {
+			return components.ExternalEditorResultMsg{Error: err}
+		}
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
 
-// handleFavoritesDialog handles key events when favorites dialog is visible
-func (a *App) handleFavoritesDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	a.favoritesDialog, cmd = a.favoritesDialog.Update(msg)
-	return a, cmd
-}
+		// Write content
+		if _, err := tmpFile.WriteString(content); err != nil {
+			return components.ExternalEditorResultMsg{Error: err}
+		}
+		_ = tmpFile.Close()
 
-// handleSearchInput handles key events when search input is visible
-func (a *App) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	a.searchInput, cmd = a.searchInput.Update(msg)
-	return a, cmd
-}
+		// Open editor
+		cmd := exec.Command(editor, tmpFile.Name())
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
 
-// getTableColumns returns column info for the current table
-func (a *App) getTableColumns() []models.ColumnInfo {
-	if a.state.TreeSelected == nil || a.state.TreeSelected.Type != models.TreeNodeTypeTable {
-		return nil
-	}
+		if err := cmd.Run(); err != nil {
+			return components.ExternalEditorResultMsg{Error: err}
+		}
 
-	conn, err := a.connectionManager.GetActive()
-	if err != nil {
-		return nil
-	}
+		// Read result
+		result, err := os.ReadFile(tmpFile.Name())
+		if err != nil {
+			return components.ExternalEditorResultMsg{Error: err}
+		}
 
-	// Get schema from parent node
-	schemaNode := a.state.TreeSelected.Parent
-	if schemaNode == nil {
-		return nil
+		return components.ExternalEditorResultMsg{Content: string(result)}
 	}
+}
 
-	columns, err := metadata.GetTableColumns(
-		context.Background(),
-		conn.Pool,
-		schemaNode.Label,
-		a.state.TreeSelected.Label,
-	)
-	if err != nil {
-		return nil
+// getSchemaFromNode traverses up the tree to find the schema name
+func (a *App) getSchemaFromNode(node *models.TreeNode) string {
+	current := node.Parent
+	for current != nil {
+		if current.Type == models.TreeNodeTypeSchema {
+			// Schema label might have count info, split by space
+			return strings.Split(current.Label, " ")[0]
+		}
+		current = current.Parent
 	}
-
-	return columns
+	return ""
 }
 
-// renderConnectionDialog renders the connection dialog centered on screen
-func (a *App) renderConnectionDialog() string {
-	// Center the dialog
-	dialogWidth := 60
-	dialogHeight := 20
-
-	a.connectionDialog.Width = dialogWidth
-	a.connectionDialog.Height = dialogHeight
-
-	dialog := a.connectionDialog.View()
-
-	// Center it
-	verticalPadding := (a.state.Height - dialogHeight) / 2
-	horizontalPadding := (a.state.Width - dialogWidth) / 2
-
-	if verticalPadding < 0 {
-		verticalPadding = 0
-	}
-	if horizontalPadding < 0 {
-		horizontalPadding = 0
+// getTableFromNode traverses up the tree to find the table name (for indexes/triggers)
+func (a *App) getTableFromNode(node *models.TreeNode) string {
+	current := node.Parent
+	for current != nil {
+		if current.Type == models.TreeNodeTypeTable {
+			return current.Label
+		}
+		// Skip group nodes (IndexGroup, TriggerGroup)
+		current = current.Parent
 	}
-
-	style := lipgloss.NewStyle().
-		Padding(verticalPadding, 0, 0, horizontalPadding)
-
-	return style.Render(dialog)
+	return ""
 }
 
-func (a *App) renderPasswordDialog() string {
-	// Center the dialog
-	dialogWidth := 50
-	dialogHeight := 12
+// loadFunctionSource loads the source code of a function or procedure
+func (a *App) loadFunctionSource(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: err}
+		}
+
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: fmt.Errorf("could not determine schema")}
+		}
 
-	a.passwordDialog.Width = dialogWidth
-	a.passwordDialog.Height = dialogHeight
+		// Parse function name and arguments from node label
+		// Label format: "function_name(args)" or just "function_name"
+		name := node.Label
+		args := ""
+		if idx := strings.Index(name, "("); idx != -1 {
+			args = name[idx+1 : len(name)-1] // Remove parentheses
+			name = name[:idx]
+		}
 
-	dialog := a.passwordDialog.View()
+		ctx := context.Background()
+		source, err := metadata.GetFunctionSource(ctx, conn.Pool, schema, name, args)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: err}
+		}
 
-	// Center it
-	verticalPadding := (a.state.Height - dialogHeight) / 2
-	horizontalPadding := (a.state.Width - dialogWidth) / 2
+		title := fmt.Sprintf("%s.%s(%s)", schema, source.Name, source.Arguments)
+		content := source.Definition
+		objectID := fmt.Sprintf("func:%s.%s(%s)", schema, source.Name, source.Arguments)
 
-	if verticalPadding < 0 {
-		verticalPadding = 0
-	}
-	if horizontalPadding < 0 {
-		horizontalPadding = 0
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "function",
+			ObjectName: fmt.Sprintf("%s.%s(%s)", schema, source.Name, source.Arguments),
+			ObjectID:   objectID,
+			Title:      title,
+			Content:    content,
+		}
 	}
-
-	style := lipgloss.NewStyle().
-		Padding(verticalPadding, 0, 0, horizontalPadding)
-
-	return style.Render(dialog)
 }
 
-// triggerDiscovery runs discovery in the background and returns a command
-func (a *App) triggerDiscovery() tea.Cmd {
+// loadTriggerFunctionSource loads the source code of a trigger function
+func (a *App) loadTriggerFunctionSource(node *models.TreeNode) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		instances := a.discoverer.DiscoverAll(ctx)
-		return DiscoveryCompleteMsg{Instances: instances}
-	}
-}
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: err}
+		}
 
-// loadTree loads the database structure and builds the navigation tree
-func (a *App) loadTree() tea.Msg {
-	ctx := context.Background()
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: fmt.Errorf("could not determine schema")}
+		}
 
-	conn, err := a.connectionManager.GetActive()
-	if err != nil {
-		return TreeLoadedMsg{Err: fmt.Errorf("no active connection: %w", err)}
-	}
+		ctx := context.Background()
+		source, err := metadata.GetTriggerFunctionSource(ctx, conn.Pool, schema, node.Label)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: err}
+		}
 
-	// Get current database name
-	currentDB := conn.Config.Database
+		title := fmt.Sprintf("%s.%s() → trigger", schema, source.Name)
+		content := source.Definition
+		objectID := fmt.Sprintf("trigfunc:%s.%s", schema, source.Name)
 
-	// Build simple tree with just current database for now
-	root := models.BuildDatabaseTree([]string{currentDB}, currentDB)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "trigger_function",
+			ObjectName: fmt.Sprintf("%s.%s", schema, source.Name),
+			ObjectID:   objectID,
+			Title:      title,
+			Content:    content,
+		}
+	}
+}
 
-	// Load schemas for the current database
-	schemas, err := metadata.ListSchemas(ctx, conn.Pool)
-	if err != nil {
-		return TreeLoadedMsg{Err: fmt.Errorf("failed to load schemas: %w", err)}
-	}
-
-	// Find the database node
-	dbNode := root.FindByID(fmt.Sprintf("db:%s", currentDB))
-	if dbNode != nil {
-		// Load extensions at database level (before schemas)
-		extensions, _ := metadata.ListExtensions(ctx, conn.Pool)
-		if len(extensions) > 0 {
-			extGroup := models.NewTreeNode(
-				fmt.Sprintf("extensions:%s", currentDB),
-				models.TreeNodeTypeExtensionGroup,
-				fmt.Sprintf("Extensions (%d)", len(extensions)),
-			)
-			extGroup.Selectable = false
+// loadSequenceDetails loads sequence properties
+func (a *App) loadSequenceDetails(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: err}
+		}
 
-			for _, ext := range extensions {
-				extNode := models.NewTreeNode(
-					fmt.Sprintf("extension:%s.%s", currentDB, ext.Name),
-					models.TreeNodeTypeExtension,
-					fmt.Sprintf("%s v%s", ext.Name, ext.Version),
-				)
-				extNode.Selectable = true
-				extNode.Metadata = ext
-				extNode.Loaded = true
-				extGroup.AddChild(extNode)
-			}
-			extGroup.Loaded = true
-			dbNode.AddChild(extGroup)
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: fmt.Errorf("could not determine schema")}
 		}
 
-		// Add schema nodes as children
-		for _, schema := range schemas {
-			// Load all objects for this schema
-			tables, _ := metadata.ListTables(ctx, conn.Pool, schema.Name)
-			views, _ := metadata.ListViews(ctx, conn.Pool, schema.Name)
-			matViews, _ := metadata.ListMaterializedViews(ctx, conn.Pool, schema.Name)
-			functions, _ := metadata.ListFunctions(ctx, conn.Pool, schema.Name)
-			procedures, _ := metadata.ListProcedures(ctx, conn.Pool, schema.Name)
-			triggerFuncs, _ := metadata.ListTriggerFunctions(ctx, conn.Pool, schema.Name)
-			sequences, _ := metadata.ListSequences(ctx, conn.Pool, schema.Name)
-			compositeTypes, _ := metadata.ListCompositeTypes(ctx, conn.Pool, schema.Name)
-			enumTypes, _ := metadata.ListEnumTypes(ctx, conn.Pool, schema.Name)
-			domainTypes, _ := metadata.ListDomainTypes(ctx, conn.Pool, schema.Name)
-			rangeTypes, _ := metadata.ListRangeTypes(ctx, conn.Pool, schema.Name)
-
-			// Count total objects
-			totalObjects := len(tables) + len(views) + len(matViews) + len(functions) +
-				len(procedures) + len(triggerFuncs) + len(sequences) +
-				len(compositeTypes) + len(enumTypes) + len(domainTypes) + len(rangeTypes)
-
-			// Skip empty schemas
-			if totalObjects == 0 {
-				continue
-			}
+		ctx := context.Background()
+		details, err := metadata.GetSequenceDetails(ctx, conn.Pool, schema, node.Label)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: err}
+		}
+
+		// Format as CREATE SEQUENCE statement
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("-- Current Value: %d\n", details.CurrentValue))
+		if details.Owner != "" {
+			b.WriteString(fmt.Sprintf("-- Owner: %s\n", details.Owner))
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("CREATE SEQUENCE %s.%s\n", schema, details.Name))
+		b.WriteString(fmt.Sprintf("    INCREMENT BY %d\n", details.Increment))
+		b.WriteString(fmt.Sprintf("    MINVALUE %d\n", details.MinValue))
+		b.WriteString(fmt.Sprintf("    MAXVALUE %d\n", details.MaxValue))
+		b.WriteString(fmt.Sprintf("    START WITH %d\n", details.StartValue))
+		if details.Cycle {
+			b.WriteString("    CYCLE")
+		} else {
+			b.WriteString("    NO CYCLE")
+		}
+		b.WriteString(";")
 
-			// Create schema label
-			schemaLabel := schema.Name
+		objectID := fmt.Sprintf("seq:%s.%s", schema, details.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "sequence",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s", schema, details.Name),
+			Content:    b.String(),
+		}
+	}
+}
 
-			schemaNode := models.NewTreeNode(
-				fmt.Sprintf("schema:%s.%s", currentDB, schema.Name),
-				models.TreeNodeTypeSchema,
-				schemaLabel,
-			)
-			schemaNode.Selectable = true
-
-			// Add Tables group
-			if len(tables) > 0 {
-				tablesGroup := models.NewTreeNode(
-					fmt.Sprintf("tables:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeTableGroup,
-					fmt.Sprintf("Tables (%d)", len(tables)),
-				)
-				tablesGroup.Selectable = false
+// loadIndexDetails loads index DDL definition
+func (a *App) loadIndexDetails(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: err}
+		}
 
-				for _, table := range tables {
-					tableNode := models.NewTreeNode(
-						fmt.Sprintf("table:%s.%s.%s", currentDB, schema.Name, table.Name),
-						models.TreeNodeTypeTable,
-						table.Name,
-					)
-					tableNode.Selectable = true
-
-					// Load indexes and triggers for this table
-					indexes, _ := metadata.ListTableIndexes(ctx, conn.Pool, schema.Name, table.Name)
-					triggers, _ := metadata.ListTableTriggers(ctx, conn.Pool, schema.Name, table.Name)
-
-					// Add Indexes group under table
-					if len(indexes) > 0 {
-						indexGroup := models.NewTreeNode(
-							fmt.Sprintf("indexes:%s.%s.%s", currentDB, schema.Name, table.Name),
-							models.TreeNodeTypeIndexGroup,
-							fmt.Sprintf("Indexes (%d)", len(indexes)),
-						)
-						indexGroup.Selectable = false
-						for _, idx := range indexes {
-							idxNode := models.NewTreeNode(
-								fmt.Sprintf("index:%s.%s.%s.%s", currentDB, schema.Name, table.Name, idx.Name),
-								models.TreeNodeTypeIndex,
-								idx.Name,
-							)
-							idxNode.Selectable = true
-							idxNode.Metadata = idx
-							idxNode.Loaded = true
-							indexGroup.AddChild(idxNode)
-						}
-						indexGroup.Loaded = true
-						tableNode.AddChild(indexGroup)
-					}
+		schema := a.getSchemaFromNode(node)
+		table := a.getTableFromNode(node)
+		if schema == "" || table == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: fmt.Errorf("could not determine schema/table")}
+		}
 
-					// Add Triggers group under table
-					if len(triggers) > 0 {
-						triggerGroup := models.NewTreeNode(
-							fmt.Sprintf("triggers:%s.%s.%s", currentDB, schema.Name, table.Name),
-							models.TreeNodeTypeTriggerGroup,
-							fmt.Sprintf("Triggers (%d)", len(triggers)),
-						)
-						triggerGroup.Selectable = false
-						for _, trg := range triggers {
-							trgNode := models.NewTreeNode(
-								fmt.Sprintf("trigger:%s.%s.%s.%s", currentDB, schema.Name, table.Name, trg.Name),
-								models.TreeNodeTypeTrigger,
-								trg.Name,
-							)
-							trgNode.Selectable = true
-							trgNode.Metadata = trg
-							trgNode.Loaded = true
-							triggerGroup.AddChild(trgNode)
-						}
-						triggerGroup.Loaded = true
-						tableNode.AddChild(triggerGroup)
-					}
+		ctx := context.Background()
+		indexes, err := metadata.ListTableIndexes(ctx, conn.Pool, schema, table)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: err}
+		}
 
-					tableNode.Loaded = len(indexes) == 0 && len(triggers) == 0
-					tablesGroup.AddChild(tableNode)
-				}
-				tablesGroup.Loaded = true
-				schemaNode.AddChild(tablesGroup)
+		// Find the specific index
+		var content string
+		for _, idx := range indexes {
+			if idx.Name == node.Label {
+				content = idx.Definition
+				break
 			}
+		}
 
-			// Add Views group
-			if len(views) > 0 {
-				viewsGroup := models.NewTreeNode(
-					fmt.Sprintf("views:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeViewGroup,
-					fmt.Sprintf("Views (%d)", len(views)),
-				)
-				viewsGroup.Selectable = false
+		if content == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: fmt.Errorf("index %s not found", node.Label)}
+		}
 
-				for _, view := range views {
-					viewNode := models.NewTreeNode(
-						fmt.Sprintf("view:%s.%s.%s", currentDB, schema.Name, view.Name),
-						models.TreeNodeTypeView,
-						view.Name,
-					)
-					viewNode.Selectable = true
-					viewNode.Loaded = true
-					viewsGroup.AddChild(viewNode)
-				}
-				viewsGroup.Loaded = true
-				schemaNode.AddChild(viewsGroup)
-			}
+		objectID := fmt.Sprintf("idx:%s.%s.%s", schema, table, node.Label)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "index",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (on %s)", schema, node.Label, table),
+			Content:    content,
+		}
+	}
+}
 
-			// Add Materialized Views group
-			if len(matViews) > 0 {
-				matViewsGroup := models.NewTreeNode(
-					fmt.Sprintf("matviews:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeMaterializedViewGroup,
-					fmt.Sprintf("Materialized Views (%d)", len(matViews)),
-				)
-				matViewsGroup.Selectable = false
+// loadTriggerDetails loads trigger DDL definition
+func (a *App) loadTriggerDetails(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: err}
+		}
 
-				for _, mv := range matViews {
-					mvNode := models.NewTreeNode(
-						fmt.Sprintf("matview:%s.%s.%s", currentDB, schema.Name, mv.Name),
-						models.TreeNodeTypeMaterializedView,
-						mv.Name,
-					)
-					mvNode.Selectable = true
-					mvNode.Loaded = true
-					matViewsGroup.AddChild(mvNode)
-				}
-				matViewsGroup.Loaded = true
-				schemaNode.AddChild(matViewsGroup)
-			}
+		schema := a.getSchemaFromNode(node)
+		table := a.getTableFromNode(node)
+		if schema == "" || table == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: fmt.Errorf("could not determine schema/table")}
+		}
 
-			// Add Functions group
-			if len(functions) > 0 {
-				funcsGroup := models.NewTreeNode(
-					fmt.Sprintf("functions:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeFunctionGroup,
-					fmt.Sprintf("Functions (%d)", len(functions)),
-				)
-				funcsGroup.Selectable = false
+		ctx := context.Background()
+		triggers, err := metadata.ListTableTriggers(ctx, conn.Pool, schema, table)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: err}
+		}
 
-				for _, fn := range functions {
-					label := fn.Name
-					if fn.Arguments != "" {
-						label = fmt.Sprintf("%s(%s)", fn.Name, fn.Arguments)
-					}
-					fnNode := models.NewTreeNode(
-						fmt.Sprintf("function:%s.%s.%s", currentDB, schema.Name, fn.Name),
-						models.TreeNodeTypeFunction,
-						label,
-					)
-					fnNode.Selectable = true
-					fnNode.Metadata = fn
-					fnNode.Loaded = true
-					funcsGroup.AddChild(fnNode)
-				}
-				funcsGroup.Loaded = true
-				schemaNode.AddChild(funcsGroup)
+		// Find the specific trigger
+		var content string
+		for _, trg := range triggers {
+			if trg.Name == node.Label {
+				content = trg.Definition
+				break
 			}
+		}
 
-			// Add Procedures group
-			if len(procedures) > 0 {
-				procsGroup := models.NewTreeNode(
-					fmt.Sprintf("procedures:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeProcedureGroup,
-					fmt.Sprintf("Procedures (%d)", len(procedures)),
-				)
-				procsGroup.Selectable = false
+		if content == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: fmt.Errorf("trigger %s not found", node.Label)}
+		}
 
-				for _, proc := range procedures {
-					label := proc.Name
-					if proc.Arguments != "" {
-						label = fmt.Sprintf("%s(%s)", proc.Name, proc.Arguments)
-					}
-					procNode := models.NewTreeNode(
-						fmt.Sprintf("procedure:%s.%s.%s", currentDB, schema.Name, proc.Name),
-						models.TreeNodeTypeProcedure,
-						label,
-					)
-					procNode.Selectable = true
-					procNode.Metadata = proc
-					procNode.Loaded = true
-					procsGroup.AddChild(procNode)
-				}
-				procsGroup.Loaded = true
-				schemaNode.AddChild(procsGroup)
-			}
+		objectID := fmt.Sprintf("trig:%s.%s.%s", schema, table, node.Label)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "trigger",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (on %s)", schema, node.Label, table),
+			Content:    content,
+		}
+	}
+}
 
-			// Add Trigger Functions group
-			if len(triggerFuncs) > 0 {
-				trigFuncsGroup := models.NewTreeNode(
-					fmt.Sprintf("triggerfuncs:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeTriggerFunctionGroup,
-					fmt.Sprintf("Trigger Functions (%d)", len(triggerFuncs)),
-				)
-				trigFuncsGroup.Selectable = false
+// loadExtensionDetails loads extension information
+func (a *App) loadExtensionDetails(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "extension", Err: err}
+		}
 
-				for _, tf := range triggerFuncs {
-					tfNode := models.NewTreeNode(
-						fmt.Sprintf("triggerfunc:%s.%s.%s", currentDB, schema.Name, tf.Name),
-						models.TreeNodeTypeTriggerFunction,
-						tf.Name,
-					)
-					tfNode.Selectable = true
-					tfNode.Metadata = tf
-					tfNode.Loaded = true
-					trigFuncsGroup.AddChild(tfNode)
-				}
-				trigFuncsGroup.Loaded = true
-				schemaNode.AddChild(trigFuncsGroup)
-			}
+		// Parse extension name from label (format: "name vX.Y")
+		name := node.Label
+		if idx := strings.Index(name, " v"); idx != -1 {
+			name = name[:idx]
+		}
 
-			// Add Sequences group
-			if len(sequences) > 0 {
-				seqsGroup := models.NewTreeNode(
-					fmt.Sprintf("sequences:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeSequenceGroup,
-					fmt.Sprintf("Sequences (%d)", len(sequences)),
-				)
-				seqsGroup.Selectable = false
+		ctx := context.Background()
+		details, err := metadata.GetExtensionDetails(ctx, conn.Pool, name)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "extension", Err: err}
+		}
 
-				for _, seq := range sequences {
-					seqNode := models.NewTreeNode(
-						fmt.Sprintf("sequence:%s.%s.%s", currentDB, schema.Name, seq.Name),
-						models.TreeNodeTypeSequence,
-						seq.Name,
-					)
-					seqNode.Selectable = true
-					seqNode.Metadata = seq
-					seqNode.Loaded = true
-					seqsGroup.AddChild(seqNode)
-				}
-				seqsGroup.Loaded = true
-				schemaNode.AddChild(seqsGroup)
-			}
+		// Format as CREATE EXTENSION statement
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("-- Extension: %s\n", details.Name))
+		b.WriteString(fmt.Sprintf("-- Version: %s\n", details.Version))
+		if details.Description != "" {
+			b.WriteString(fmt.Sprintf("-- %s\n", details.Description))
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s\n", details.Name))
+		b.WriteString(fmt.Sprintf("    SCHEMA %s\n", details.Schema))
+		b.WriteString(fmt.Sprintf("    VERSION '%s';", details.Version))
 
-			// Add Types group (with subgroups)
-			hasTypes := len(compositeTypes) > 0 || len(enumTypes) > 0 || len(domainTypes) > 0 || len(rangeTypes) > 0
-			if hasTypes {
-				typesGroup := models.NewTreeNode(
-					fmt.Sprintf("types:%s.%s", currentDB, schema.Name),
-					models.TreeNodeTypeTypeGroup,
-					fmt.Sprintf("Types (%d)", len(compositeTypes)+len(enumTypes)+len(domainTypes)+len(rangeTypes)),
-				)
-				typesGroup.Selectable = false
-
-				// Composite Types
-				if len(compositeTypes) > 0 {
-					compGroup := models.NewTreeNode(
-						fmt.Sprintf("compositetypes:%s.%s", currentDB, schema.Name),
-						models.TreeNodeTypeCompositeTypeGroup,
-						fmt.Sprintf("Composite (%d)", len(compositeTypes)),
-					)
-					compGroup.Selectable = false
-					for _, ct := range compositeTypes {
-						ctNode := models.NewTreeNode(
-							fmt.Sprintf("compositetype:%s.%s.%s", currentDB, schema.Name, ct.Name),
-							models.TreeNodeTypeCompositeType,
-							ct.Name,
-						)
-						ctNode.Selectable = true
-						ctNode.Loaded = true
-						compGroup.AddChild(ctNode)
-					}
-					compGroup.Loaded = true
-					typesGroup.AddChild(compGroup)
-				}
+		objectID := fmt.Sprintf("ext:%s", details.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "extension",
+			ObjectID:   objectID,
+			Title:      details.Name,
+			Content:    b.String(),
+		}
+	}
+}
 
-				// Enum Types
-				if len(enumTypes) > 0 {
-					enumGroup := models.NewTreeNode(
-						fmt.Sprintf("enumtypes:%s.%s", currentDB, schema.Name),
-						models.TreeNodeTypeEnumTypeGroup,
-						fmt.Sprintf("Enum (%d)", len(enumTypes)),
-					)
-					enumGroup.Selectable = false
-					for _, et := range enumTypes {
-						etNode := models.NewTreeNode(
-							fmt.Sprintf("enumtype:%s.%s.%s", currentDB, schema.Name, et.Name),
-							models.TreeNodeTypeEnumType,
-							et.Name,
-						)
-						etNode.Selectable = true
-						etNode.Metadata = et
-						etNode.Loaded = true
-						enumGroup.AddChild(etNode)
-					}
-					enumGroup.Loaded = true
-					typesGroup.AddChild(enumGroup)
-				}
+// loadCompositeTypeDetails loads composite type definition
+func (a *App) loadCompositeTypeDetails(node *models.TreeNode) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+		}
 
-				// Domain Types
-				if len(domainTypes) > 0 {
-					domGroup := models.NewTreeNode(
-						fmt.Sprintf("domaintypes:%s.%s", currentDB, schema.Name),
-						models.TreeNodeTypeDomainTypeGroup,
-						fmt.Sprintf("Domain (%d)", len(domainTypes)),
-					)
-					domGroup.Selectable = false
-					for _, dt := range domainTypes {
-						dtNode := models.NewTreeNode(
-							fmt.Sprintf("domaintype:%s.%s.%s", currentDB, schema.Name, dt.Name),
-							models.TreeNodeTypeDomainType,
-							fmt.Sprintf("%s → %s", dt.Name, dt.BaseType),
-						)
-						dtNode.Selectable = true
-						dtNode.Metadata = dt
-						dtNode.Loaded = true
-						domGroup.AddChild(dtNode)
-					}
-					domGroup.Loaded = true
-					typesGroup.AddChild(domGroup)
-				}
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
+		}
 
-				// Range Types
-				if len(rangeTypes) > 0 {
-					rangeGroup := models.NewTreeNode(
-						fmt.Sprintf("rangetypes:%s.%s", currentDB, schema.Name),
-						models.TreeNodeTypeRangeTypeGroup,
-						fmt.Sprintf("Range (%d)", len(rangeTypes)),
-					)
-					rangeGroup.Selectable = false
-					for _, rt := range rangeTypes {
-						rtNode := models.NewTreeNode(
-							fmt.Sprintf("rangetype:%s.%s.%s", currentDB, schema.Name, rt.Name),
-							models.TreeNodeTypeRangeType,
-							fmt.Sprintf("%s [%s]", rt.Name, rt.Subtype),
-						)
-						rtNode.Selectable = true
-						rtNode.Metadata = rt
-						rtNode.Loaded = true
-						rangeGroup.AddChild(rtNode)
-					}
-					rangeGroup.Loaded = true
-					typesGroup.AddChild(rangeGroup)
-				}
+		ctx := context.Background()
+		details, err := metadata.GetCompositeTypeDetails(ctx, conn.Pool, schema, node.Label)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+		}
 
-				typesGroup.Loaded = true
-				schemaNode.AddChild(typesGroup)
+		// Format as CREATE TYPE statement
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("CREATE TYPE %s.%s AS (\n", schema, details.Name))
+		for i, attr := range details.Attributes {
+			comma := ","
+			if i == len(details.Attributes)-1 {
+				comma = ""
 			}
+			b.WriteString(fmt.Sprintf("    %s %s%s\n", attr.Name, attr.Type, comma))
+		}
+		b.WriteString(");")
 
-			schemaNode.Loaded = true
-			dbNode.AddChild(schemaNode)
+		objectID := fmt.Sprintf("type:composite:%s.%s", schema, details.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "type",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (composite)", schema, details.Name),
+			Content:    b.String(),
 		}
-		dbNode.Loaded = true
 	}
-
-	return TreeLoadedMsg{Root: root}
 }
 
-
-// loadTableData loads table data with pagination
-func (a *App) loadTableData(msg LoadTableDataMsg) tea.Cmd {
+// loadEnumTypeDetails loads enum type definition
+func (a *App) loadEnumTypeDetails(node *models.TreeNode) tea.Cmd {
 	return func() tea.Msg {
-		ctx := context.Background()
-
 		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return TableDataLoadedMsg{Err: fmt.Errorf("no active connection: %w", err)}
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
 		}
 
-		var sort *metadata.SortOptions
-		if msg.SortColumn != "" {
-			sort = &metadata.SortOptions{
-				Column:     msg.SortColumn,
-				Direction:  msg.SortDir,
-				NullsFirst: msg.NullsFirst,
-			}
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
 		}
 
-		data, err := metadata.QueryTableData(ctx, conn.Pool, msg.Schema, msg.Table, msg.Offset, msg.Limit, sort)
+		ctx := context.Background()
+		enums, err := metadata.ListEnumTypes(ctx, conn.Pool, schema)
 		if err != nil {
-			return TableDataLoadedMsg{Err: err}
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
 		}
 
-		return TableDataLoadedMsg{
-			Columns:   data.Columns,
-			Rows:      data.Rows,
-			TotalRows: int(data.TotalRows),
-			Offset:    msg.Offset,
+		// Find the specific enum
+		var enumType *metadata.EnumType
+		for _, e := range enums {
+			if e.Name == node.Label {
+				enumType = &e
+				break
+			}
+		}
+
+		if enumType == nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("enum type %s not found", node.Label)}
+		}
+
+		// Format as CREATE TYPE statement
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("CREATE TYPE %s.%s AS ENUM (\n", schema, enumType.Name))
+		for i, label := range enumType.Labels {
+			comma := ","
+			if i == len(enumType.Labels)-1 {
+				comma = ""
+			}
+			b.WriteString(fmt.Sprintf("    '%s'%s\n", label, comma))
+		}
+		b.WriteString(");")
+
+		objectID := fmt.Sprintf("type:enum:%s.%s", schema, enumType.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "type",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (enum)", schema, enumType.Name),
+			Content:    b.String(),
 		}
 	}
 }
 
-// loadTableDataForTab loads table data for a specific tab
-func (a *App) loadTableDataForTab(schema, table, objectID string) tea.Cmd {
+// loadDomainTypeDetails loads domain type definition
+func (a *App) loadDomainTypeDetails(node *models.TreeNode) tea.Cmd {
 	return func() tea.Msg {
-		ctx := context.Background()
-
 		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return TabTableDataLoadedMsg{ObjectID: objectID, Err: fmt.Errorf("no active connection: %w", err)}
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+		}
+
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
 		}
 
-		data, err := metadata.QueryTableData(ctx, conn.Pool, schema, table, 0, 100, nil)
+		ctx := context.Background()
+		details, err := metadata.GetDomainTypeDetails(ctx, conn.Pool, schema, node.Label)
 		if err != nil {
-			return TabTableDataLoadedMsg{ObjectID: objectID, Err: err}
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
 		}
 
-		return TabTableDataLoadedMsg{
-			ObjectID:  objectID,
-			Schema:    schema,
-			Table:     table,
-			Columns:   data.Columns,
-			Rows:      data.Rows,
-			TotalRows: int(data.TotalRows),
+		// Format as CREATE DOMAIN statement
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("CREATE DOMAIN %s.%s AS %s", schema, details.Name, details.BaseType))
+		if details.NotNull {
+			b.WriteString(" NOT NULL")
+		}
+		if details.Default != "" {
+			b.WriteString(fmt.Sprintf(" DEFAULT %s", details.Default))
+		}
+		for _, constraint := range details.Constraints {
+			b.WriteString(fmt.Sprintf("\n    %s", constraint))
+		}
+		b.WriteString(";")
+
+		objectID := fmt.Sprintf("type:domain:%s.%s", schema, details.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "type",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (domain)", schema, details.Name),
+			Content:    b.String(),
 		}
 	}
 }
 
-// loadTableDataWithFilter loads table data with an applied filter
-func (a *App) loadTableDataWithFilter(filter models.Filter) tea.Cmd {
+// loadRangeTypeDetails loads range type definition
+func (a *App) loadRangeTypeDetails(node *models.TreeNode) tea.Cmd {
 	return func() tea.Msg {
 		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return ErrorMsg{Title: "Connection Error", Message: err.Error()}
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
 		}
 
-		node := a.state.TreeSelected
-		if node == nil || node.Type != models.TreeNodeTypeTable {
-			return ErrorMsg{Title: "Error", Message: "No table selected"}
+		schema := a.getSchemaFromNode(node)
+		if schema == "" {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
 		}
 
-		// Get schema from parent node
-		schemaNode := node.Parent
-		if schemaNode == nil {
-			return ErrorMsg{Title: "Error", Message: "Cannot determine schema"}
+		ctx := context.Background()
+		ranges, err := metadata.ListRangeTypes(ctx, conn.Pool, schema)
+		if err != nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
 		}
 
-		// Build filtered query
-		builder := filterBuilder.NewBuilder()
-		whereClause, args, err := builder.BuildWhere(filter)
-		if err != nil {
-			return ErrorMsg{Title: "Filter Error", Message: err.Error()}
+		// Find the specific range type
+		var rangeType *metadata.RangeType
+		for _, r := range ranges {
+			if r.Name == node.Label {
+				rangeType = &r
+				break
+			}
 		}
 
-		// Construct query
-		query := fmt.Sprintf(
-			`SELECT * FROM "%s"."%s" %s LIMIT 100`,
-			schemaNode.Label,
-			node.Label,
-			whereClause,
-		)
+		if rangeType == nil {
+			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("range type %s not found", node.Label)}
+		}
 
-		// Execute query
-		result, err := conn.Pool.QueryWithColumns(context.Background(), query, args...)
-		if err != nil {
-			return ErrorMsg{Title: "Query Error", Message: err.Error()}
+		// Format as CREATE TYPE statement
+		content := fmt.Sprintf("CREATE TYPE %s.%s AS RANGE (\n    SUBTYPE = %s\n);",
+			schema, rangeType.Name, rangeType.Subtype)
+
+		objectID := fmt.Sprintf("type:range:%s.%s", schema, rangeType.Name)
+		return ObjectDetailsLoadedMsg{
+			ObjectType: "type",
+			ObjectID:   objectID,
+			Title:      fmt.Sprintf("%s.%s (range)", schema, rangeType.Name),
+			Content:    content,
 		}
+	}
+}
 
-		// Convert to string rows for display
-		var rows [][]string
-		for _, row := range result.Rows {
-			var strRow []string
-			for _, col := range result.Columns {
-				val := row[col]
-				if val == nil {
-					strRow = append(strRow, "NULL")
-				} else {
-					strRow = append(strRow, fmt.Sprintf("%v", val))
-				}
-			}
-			rows = append(rows, strRow)
+// saveObjectDefinition executes the SQL to save an object definition
+func (a *App) saveObjectDefinition(target dataTarget, msg components.SaveObjectMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		pool, err := a.targetPool(ctx, target)
+		if err != nil {
+			return components.ObjectSavedMsg{Success: false, Error: err}
 		}
 
-		return TableDataLoadedMsg{
-			Columns:   result.Columns,
-			Rows:      rows,
-			TotalRows: len(rows),
-			Offset:    0,
+		// The content should be the full CREATE OR REPLACE statement for functions/procedures
+		// For other object types, we may need to generate appropriate SQL
+		sql := msg.Content
+
+		_, err = pool.Execute(ctx, sql)
+		if err != nil {
+			return components.ObjectSavedMsg{Success: false, Error: err}
 		}
+
+		return components.ObjectSavedMsg{Success: true}
 	}
 }
 
-// ShowError displays an error overlay with the given title and message
-func (a *App) ShowError(title, message string) {
-	a.errorOverlay.SetError(title, message)
-	a.showError = true
+// findTableDataTab returns the table data tab for the given objectID. The
+// active tab comes first, as tabs of other databases can show a table of the
+// same name.
+func (a *App) findTableDataTab(objectID string) *components.ResultTab {
+	matches := func(tab *components.ResultTab) bool {
+		return tab != nil && tab.ObjectID == objectID && tab.Type == components.TabTypeTableData && tab.Structure != nil
+	}
+	if tab := a.resultTabs.GetActiveTab(); matches(tab) {
+		return tab
+	}
+	for _, tab := range a.resultTabs.GetAllTabs() {
+		if matches(tab) {
+			return tab
+		}
+	}
+	return nil
 }
 
-// DismissError hides the error overlay
-func (a *App) DismissError() {
-	a.showError = false
+// activeTableDataTab returns the active tab if it shows table data on the Data tab.
+// Otherwise it returns nil and the reason the data cannot be modified.
+func (a *App) activeTableDataTab() (*components.ResultTab, string) {
+	tab := a.resultTabs.GetActiveTab()
+	if tab == nil || tab.Type != components.TabTypeTableData || tab.Structure == nil {
+		return nil, "Only table data opened from the explorer can be edited."
+	}
+	if tab.Structure.GetActiveTab() != 0 {
+		return nil, "Switch to the Data tab to edit rows."
+	}
+	if tab.Structure.GetTable() == "" {
+		return nil, "Table structure is still loading."
+	}
+	return tab, ""
 }
 
-// overlayCommandPalette renders the command palette as an overlay on top of background
-func (a *App) overlayCommandPalette(background string) string {
-	paletteView := a.commandPalette.View()
-	paletteLines := strings.Split(paletteView, "\n")
-	bgLines := strings.Split(background, "\n")
-
-	// Calculate center position
-	paletteHeight := len(paletteLines)
-	paletteWidth := lipgloss.Width(paletteLines[0]) // Use first line width
-
-	startY := (a.state.Height - paletteHeight) / 2
-	startX := (a.state.Width - paletteWidth) / 2
-
-	if startY < 0 {
-		startY = 0
+// editableTableTab returns the active table data tab if its rows can be
+// identified by a primary key or unique constraint.
+func (a *App) editableTableTab() (*components.ResultTab, string) {
+	tab, reason := a.activeTableDataTab()
+	if tab == nil {
+		return nil, reason
 	}
-	if startX < 0 {
-		startX = 0
+	if len(tab.Structure.RowKeyColumns()) == 0 {
+		return nil, fmt.Sprintf("%s.%s has no primary key or unique constraint.\n\nRows cannot be identified safely, so editing is disabled.",
+			tab.Structure.GetSchema(), tab.Structure.GetTable())
 	}
+	return tab, ""
+}
 
-	// Overlay palette on background
-	result := make([]string, len(bgLines))
-	for i, bgLine := range bgLines {
-		if i >= startY && i < startY+paletteHeight {
-			paletteLineIdx := i - startY
-			if paletteLineIdx < len(paletteLines) {
-				// Overlay this palette line onto background
-				result[i] = a.overlayLine(bgLine, paletteLines[paletteLineIdx], startX)
-			} else {
-				result[i] = bgLine
-			}
-		} else {
-			result[i] = bgLine
-		}
+// beginCellEdit starts editing the selected cell of the active table tab
+func (a *App) beginCellEdit() (tea.Model, tea.Cmd) {
+	tab, reason := a.editableTableTab()
+	if tab == nil {
+		a.ShowError("Cannot Edit", reason)
+		return a, nil
 	}
-
-	return strings.Join(result, "\n")
+	tab.Structure.GetTableView().BeginCellEdit()
+	return a, nil
 }
 
-// overlaySearchInput renders the search input as an overlay on top of background
-func (a *App) overlaySearchInput(background string) string {
-	searchView := a.searchInput.View()
-	searchLines := strings.Split(searchView, "\n")
-	bgLines := strings.Split(background, "\n")
-
-	// Calculate center position
-	searchHeight := len(searchLines)
-	searchWidth := lipgloss.Width(searchLines[0]) // Use first line width
+// showPendingChangesDialog opens the review dialog for staged edits
+func (a *App) showPendingChangesDialog() (tea.Model, tea.Cmd) {
+	tab, reason := a.editableTableTab()
+	if tab == nil {
+		a.ShowError("Cannot Edit", reason)
+		return a, nil
+	}
 
-	startY := (a.state.Height - searchHeight) / 2
-	startX := (a.state.Width - searchWidth) / 2
+	tableView := tab.Structure.GetTableView()
+	if !tableView.HasPendingEdits() {
+		return a, nil
+	}
 
-	if startY < 0 {
-		startY = 0
+	updates, err := tableView.BuildRowUpdates(tab.Structure.RowKeyColumns())
+	if err != nil {
+		a.ShowError("Cannot Commit", err.Error())
+		return a, nil
 	}
-	if startX < 0 {
-		startX = 0
+
+	if err := a.pendingChangesDialog.SetChanges(tab.ObjectID, tab.Structure.GetSchema(), tab.Structure.GetTable(), updates); err != nil {
+		a.ShowError("Cannot Commit", err.Error())
+		return a, nil
 	}
+	a.showPendingChanges = true
+	return a, nil
+}
 
-	// Overlay search box on background
-	result := make([]string, len(bgLines))
-	for i, bgLine := range bgLines {
-		if i >= startY && i < startY+searchHeight {
-			searchLineIdx := i - startY
-			if searchLineIdx < len(searchLines) {
-				// Overlay this search line onto background
-				result[i] = a.overlayLine(bgLine, searchLines[searchLineIdx], startX)
-			} else {
-				result[i] = bgLine
-			}
-		} else {
-			result[i] = bgLine
+// commitPendingChanges executes staged row updates in a single transaction,
+// in the database the table's tab was opened in
+func (a *App) commitPendingChanges(msg components.CommitChangesMsg) tea.Cmd {
+	tab := a.findTableDataTab(msg.ObjectID)
+	if tab == nil {
+		return func() tea.Msg {
+			return CommitChangesResultMsg{Err: fmt.Errorf("the table's tab was closed")}
 		}
 	}
+	tabID, target := tab.ID, tabTarget(tab)
+	return func() tea.Msg {
+		ctx := context.Background()
+		pool, err := a.targetPool(ctx, target)
+		if err != nil {
+			return CommitChangesResultMsg{TabID: tabID, Err: err}
+		}
 
-	return strings.Join(result, "\n")
+		affected, err := edit.ApplyInTransaction(ctx, pool, msg.Statements)
+		return CommitChangesResultMsg{TabID: tabID, RowsAffected: affected, Err: err}
+	}
 }
 
-// overlayLine overlays foreground onto background at given x position
-// Handles ANSI escape sequences correctly
-func (a *App) overlayLine(background, foreground string, startX int) string {
-	fgWidth := ansi.StringWidth(foreground)
+// openInsertRowDialog opens the form for adding a row to the active table tab
+func (a *App) openInsertRowDialog() (tea.Model, tea.Cmd) {
+	tab, reason := a.activeTableDataTab()
+	if tab == nil {
+		a.ShowError("Cannot Insert", reason)
+		return a, nil
+	}
+	if tab.Structure.GetTableView().HasPendingEdits() {
+		a.ShowError("Cannot Insert", "Commit or discard pending changes first (press w).")
+		return a, nil
+	}
 
-	// Truncate background to startX (visual width)
-	leftPart := ansi.Truncate(background, startX, "")
+	a.insertRowDialog.SetTable(tab.ObjectID, tab.Structure.GetSchema(), tab.Structure.GetTable(), tab.Structure.GetColumns())
+	a.showInsertRow = true
+	return a, a.insertRowDialog.Init()
+}
 
-	// Get visible width of left part
-	leftWidth := ansi.StringWidth(leftPart)
+// deleteSelectedRows deletes the marked rows, or the selected row if none are marked
+func (a *App) deleteSelectedRows() (tea.Model, tea.Cmd) {
+	tab, reason := a.editableTableTab()
+	if tab == nil {
+		a.ShowError("Cannot Delete", reason)
+		return a, nil
+	}
 
-	// Pad if needed
-	if leftWidth < startX {
-		leftPart += strings.Repeat(" ", startX-leftWidth)
+	tableView := tab.Structure.GetTableView()
+	if tableView.HasPendingEdits() {
+		a.ShowError("Cannot Delete", "Commit or discard pending changes first (press w).")
+		return a, nil
 	}
 
-	// Cut the right part of background after the overlay
-	rightStart := startX + fgWidth
-	bgWidth := ansi.StringWidth(background)
+	rows := tableView.GetMarkedRows()
+	if len(rows) == 0 {
+		if tableView.SelectedRow < 0 || tableView.SelectedRow >= len(tableView.Rows) {
+			return a, nil
+		}
+		rows = []int{tableView.SelectedRow}
+	}
 
-	var rightPart string
-	if rightStart < bgWidth {
-		// Cut from background starting at rightStart position
-		rightPart = ansi.Cut(background, rightStart, bgWidth)
+	keys, err := tableView.BuildRowKeys(tab.Structure.RowKeyColumns(), rows)
+	if err != nil {
+		a.ShowError("Cannot Delete", err.Error())
+		return a, nil
 	}
 
-	return leftPart + foreground + rightPart
-}
+	schema, table := tab.Structure.GetSchema(), tab.Structure.GetTable()
+	statements := make([]edit.Statement, 0, len(keys))
+	previews := make([]string, 0, len(keys))
+	for _, key := range keys {
+		stmt, err := edit.BuildDelete(schema, table, key)
+		if err != nil {
+			a.ShowError("Cannot Delete", err.Error())
+			return a, nil
+		}
+		statements = append(statements, stmt)
+		previews = append(previews, stmt.Preview()+";")
+	}
 
-// SearchTableResultMsg is sent when table search completes
-type SearchTableResultMsg struct {
-	Query   string
-	Data    *metadata.TableData
-	Err     error
+	action := DeleteRowsMsg{TabID: tab.ID, Statements: statements}
+	if a.config == nil || a.config.General.ConfirmDestructiveOps {
+		a.confirmDialog.SetConfirm(
+			"Delete Rows",
+			fmt.Sprintf("Delete %d row(s) from %s.%s? All statements run in a single transaction.", len(statements), schema, table),
+			strings.Join(previews, "\n"),
+			action,
+		)
+		a.showConfirm = true
+		return a, nil
+	}
+	return a, func() tea.Msg { return action }
 }
 
-// searchTable executes a table-wide search
-func (a *App) searchTable(query string) tea.Cmd {
+// executeRowChanges runs insert/delete statements in a single transaction,
+// in the database the table's tab was opened in
+func (a *App) executeRowChanges(tab *components.ResultTab, action string, statements []edit.Statement) tea.Cmd {
+	tabID, target := tab.ID, tabTarget(tab)
 	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
+		ctx := context.Background()
+		pool, err := a.targetPool(ctx, target)
 		if err != nil {
-			return SearchTableResultMsg{Query: query, Err: fmt.Errorf("no active connection: %w", err)}
-		}
-
-		parts := strings.Split(a.currentTable, ".")
-		if len(parts) != 2 {
-			return SearchTableResultMsg{Query: query, Err: fmt.Errorf("invalid table: %s", a.currentTable)}
+			return RowChangesResultMsg{TabID: tabID, Action: action, Err: err}
 		}
 
-		schema, table := parts[0], parts[1]
+		affected, err := edit.ApplyInTransaction(ctx, pool, statements)
+		return RowChangesResultMsg{TabID: tabID, Action: action, RowsAffected: affected, Err: err}
+	}
+}
 
-		data, err := metadata.SearchTableData(
-			context.Background(),
-			conn.Pool,
-			schema,
-			table,
-			a.tableView.Columns,
-			query,
-			500, // Max results
-		)
-		if err != nil {
-			return SearchTableResultMsg{Query: query, Err: err}
+// openExportDialog determines what is currently shown and opens the export dialog
+func (a *App) openExportDialog() (tea.Model, tea.Cmd) {
+	source := &exportSource{Target: a.activeTarget()}
+	var description, baseName string
+
+	tab := a.resultTabs.GetActiveTab()
+	if tab != nil {
+		source.Target = tabTarget(tab)
+	}
+	switch {
+	case tab != nil && tab.Type == components.TabTypeTableData && tab.Structure != nil && tab.Structure.GetTable() != "":
+		source.Schema = tab.Structure.GetSchema()
+		source.Table = tab.Structure.GetTable()
+		a.setExportTableOptions(source, tab.Structure.GetTableView())
+	case tab != nil && tab.Type == components.TabTypeQueryResult && !tab.IsPending && tab.Result.Error == nil:
+		if tab.MoreRows && tab.Source == nil {
+			a.ShowError("Cannot Export", fmt.Sprintf("Only the first %d row(s) of this result are still available. Run the query again to export all of its rows.", len(tab.Result.Rows)))
+			return a, nil
+		}
+		result := tab.Result
+		source.Result = &result
+		if tab.MoreRows {
+			source.StreamTab = tab.ID
+		}
+	case tab == nil && a.currentTable != "":
+		parts := strings.SplitN(a.currentTable, ".", 2)
+		if len(parts) == 2 {
+			source.Schema, source.Table = parts[0], parts[1]
+			a.setExportTableOptions(source, a.tableView)
 		}
+	}
 
-		return SearchTableResultMsg{Query: query, Data: data}
+	switch {
+	case source.Result != nil:
+		description = fmt.Sprintf("Query result · %d row(s)", len(source.Result.Rows))
+		if source.StreamTab != 0 {
+			// The rest of the rows is fetched when exporting
+			description = fmt.Sprintf("Query result · all rows (%d loaded so far)", len(source.Result.Rows))
+		}
+		baseName = "query-result"
+	case source.Table != "":
+		description = fmt.Sprintf("%s.%s · all rows", source.Schema, source.Table)
+		if source.Filter != nil {
+			description += " · filtered"
+		}
+		if source.Sort != nil {
+			description += fmt.Sprintf(" · sorted by %s %s", source.Sort.Column, source.Sort.Direction)
+		}
+		baseName = source.Table
+	default:
+		a.ShowError("Nothing to Export", "Open a table or run a query first.")
+		return a, nil
 	}
+
+	a.exportSource = source
+	a.exportDialog.SetSource(description, fmt.Sprintf("%s-%s", baseName, time.Now().Format("20060102-150405")))
+	a.showExport = true
+	return a, a.exportDialog.Init()
 }
 
-// openExternalEditor opens the content in an external editor
-func (a *App) openExternalEditor(content string) tea.Cmd {
-	return func() tea.Msg {
-		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			editor = "vim"
+// setExportTableOptions copies the active sort and filter of a table view into the export source
+func (a *App) setExportTableOptions(source *exportSource, tableView *components.TableView) {
+	if tableView != nil && tableView.GetSortColumn() != "" {
+		source.Sort = &metadata.SortOptions{
+			Column:     tableView.GetSortColumn(),
+			Direction:  tableView.GetSortDirection(),
+			NullsFirst: tableView.GetNullsFirst(),
 		}
+	}
+	if a.activeFilter != nil && a.activeFilter.Schema == source.Schema && a.activeFilter.TableName == source.Table {
+		filter := *a.activeFilter
+		source.Filter = &filter
+	}
+}
 
-		// Create temp file
-		tmpFile, err := os.CreateTemp("", "lazypg-*.sql")
-		if err != nil {
-			return components.ExternalEditorResultMsg{Error: err}
+// exportData writes the selected export source to path in the given format
+func (a *App) exportData(format export.Format, path string) tea.Cmd {
+	source := a.exportSource
+	var rest components.RowSource
+	if source != nil && source.StreamTab != 0 {
+		tab := a.resultTabs.GetTab(source.StreamTab)
+		switch {
+		case tab == nil:
+			a.ShowError("Cannot Export", "The result's tab was closed.")
+			return nil
+		case tab.Fetching:
+			a.ShowError("Cannot Export", "Rows of the result are being fetched. Export again once they are loaded.")
+			return nil
+		case tab.MoreRows && tab.Source == nil:
+			a.ShowError("Cannot Export", fmt.Sprintf("Only the first %d row(s) of this result are still available. Run the query again to export all of its rows.", len(tab.Result.Rows)))
+			return nil
+		}
+		// The export takes over the row source and hands the rows it
+		// fetches back to the tab
+		result := tab.Result
+		source = &exportSource{Target: source.Target, Result: &result}
+		if tab.Source != nil {
+			source.StreamTab, rest = tab.ID, tab.Source
+			tab.Source = nil
+			tab.Fetching = true
+		}
+	}
+	return func() tea.Msg {
+		if source == nil {
+			return ExportCompleteMsg{Path: path, Err: fmt.Errorf("nothing to export")}
 		}
-		defer func() { _ = os.Remove(tmpFile.Name()) }()
 
-		// Write content
-		if _, err := tmpFile.WriteString(content); err != nil {
-			return components.ExternalEditorResultMsg{Error: err}
+		path = expandHomePath(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
 		}
-		_ = tmpFile.Close()
 
-		// Open editor
-		cmd := exec.Command(editor, tmpFile.Name())
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		opts := export.Options{Schema: source.Schema, Table: source.Table}
 
-		if err := cmd.Run(); err != nil {
-			return components.ExternalEditorResultMsg{Error: err}
+		if source.Result != nil {
+			result := *source.Result
+			msg := ExportCompleteMsg{Path: path, TabID: source.StreamTab, FetchDone: true}
+			if rest != nil {
+				var err error
+				msg.FetchedRows, msg.FetchedCells, err = fetchAll(context.Background(), rest)
+				if err != nil {
+					_ = rest.Close()
+					msg.FetchDone = false
+					msg.Err = fmt.Errorf("failed to fetch the rest of the result: %w", err)
+					return msg
+				}
+				result.Rows = append(slices.Clip(result.Rows), msg.FetchedRows...)
+				result.Cells = append(slices.Clip(result.Cells), msg.FetchedCells...)
+			}
+			count, err := export.ExportQueryResult(result, format, path, opts)
+			msg.Rows, msg.Err = int64(count), err
+			return msg
 		}
 
-		// Read result
-		result, err := os.ReadFile(tmpFile.Name())
+		pool, err := a.targetPool(context.Background(), source.Target)
 		if err != nil {
-			return components.ExternalEditorResultMsg{Error: err}
-		}
-
-		return components.ExternalEditorResultMsg{Content: string(result)}
-	}
-}
-
-// getSchemaFromNode traverses up the tree to find the schema name
-func (a *App) getSchemaFromNode(node *models.TreeNode) string {
-	current := node.Parent
-	for current != nil {
-		if current.Type == models.TreeNodeTypeSchema {
-			// Schema label might have count info, split by space
-			return strings.Split(current.Label, " ")[0]
+			return ExportCompleteMsg{Path: path, Err: err}
 		}
-		current = current.Parent
-	}
-	return ""
-}
 
-// getTableFromNode traverses up the tree to find the table name (for indexes/triggers)
-func (a *App) getTableFromNode(node *models.TreeNode) string {
-	current := node.Parent
-	for current != nil {
-		if current.Type == models.TreeNodeTypeTable {
-			return current.Label
+		var whereClause string
+		var args []interface{}
+		if source.Filter != nil {
+			whereClause, args, err = filterBuilder.NewBuilder().BuildWhere(*source.Filter)
+			if err != nil {
+				return ExportCompleteMsg{Path: path, Err: err}
+			}
 		}
-		// Skip group nodes (IndexGroup, TriggerGroup)
-		current = current.Parent
-	}
-	return ""
-}
 
-// loadFunctionSource loads the source code of a function or procedure
-func (a *App) loadFunctionSource(node *models.TreeNode) tea.Cmd {
-	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
+		file, err := os.Create(path)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: err}
+			return ExportCompleteMsg{Path: path, Err: fmt.Errorf("failed to create export file: %w", err)}
 		}
+		defer func() { _ = file.Close() }()
 
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: fmt.Errorf("could not determine schema")}
+		writer, err := export.NewRowWriter(file, format, opts)
+		if err != nil {
+			return ExportCompleteMsg{Path: path, Err: err}
 		}
 
-		// Parse function name and arguments from node label
-		// Label format: "function_name(args)" or just "function_name"
-		name := node.Label
-		args := ""
-		if idx := strings.Index(name, "("); idx != -1 {
-			args = name[idx+1 : len(name)-1] // Remove parentheses
-			name = name[:idx]
+		count, err := metadata.StreamTableData(context.Background(), pool, source.Schema, source.Table, whereClause, args, source.Sort, writer)
+		if err != nil {
+			return ExportCompleteMsg{Path: path, Rows: count, Err: err}
+		}
+		if err := writer.Close(); err != nil {
+			return ExportCompleteMsg{Path: path, Rows: count, Err: err}
 		}
+		return ExportCompleteMsg{Path: path, Rows: count, Err: file.Close()}
+	}
+}
 
-		ctx := context.Background()
-		source, err := metadata.GetFunctionSource(ctx, conn.Pool, schema, name, args)
+// fetchAll reads the remaining rows of a streamed result
+func fetchAll(ctx context.Context, source components.RowSource) ([][]string, [][]models.Cell, error) {
+	var rows [][]string
+	var cells [][]models.Cell
+	for !source.Done() {
+		page, pageCells, err := source.Fetch(ctx, query.DefaultFetchSize)
+		rows = append(rows, page...)
+		cells = append(cells, pageCells...)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "function", Err: err}
+			return rows, cells, err
 		}
+	}
+	return rows, cells, nil
+}
 
-		title := fmt.Sprintf("%s.%s(%s)", schema, source.Name, source.Arguments)
-		content := source.Definition
-		objectID := fmt.Sprintf("func:%s.%s(%s)", schema, source.Name, source.Arguments)
+// explainQuery runs EXPLAIN (or EXPLAIN ANALYZE) for a statement in the
+// background, with the editor's timeout and cancellation
+func (a *App) explainQuery(msg components.ExplainQueryMsg) (tea.Model, tea.Cmd) {
+	if a.state.ActiveConnection == nil {
+		a.ShowError("No Connection", "Please connect to a database first")
+		return a, nil
+	}
+	if a.execution != nil {
+		a.ShowError("Query Running", "Wait for the running query to finish, or press Esc to cancel it.")
+		return a, nil
+	}
 
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "function",
-			ObjectName: fmt.Sprintf("%s.%s(%s)", schema, source.Name, source.Arguments),
-			ObjectID:   objectID,
-			Title:      title,
-			Content:    content,
+	// Ask for the values of bind variables such as :user_id or $1
+	if msg.Values == nil {
+		if names := query.Placeholders(msg.SQL); len(names) > 0 {
+			return a, a.promptBindValues(msg.SQL, names, a.bindValues, msg)
 		}
 	}
-}
+	sql, args, err := query.Bind(msg.SQL, msg.Values)
+	if err != nil {
+		a.ShowError("Query Error", err.Error())
+		return a, nil
+	}
 
-// loadTriggerFunctionSource loads the source code of a trigger function
-func (a *App) loadTriggerFunctionSource(node *models.TreeNode) tea.Cmd {
-	return func() tea.Msg {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.executeCancelFn = cancel
+	ctx = a.startExecution(ctx)
+	exec := a.execution
+	return a, func() tea.Msg {
 		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: err}
+			return ExplainResultMsg{Exec: exec, Err: fmt.Errorf("failed to get connection: %w", err)}
 		}
+		plan, err := query.Explain(ctx, conn.Pool.GetPool(), sql, msg.Analyze, args...)
+		return ExplainResultMsg{Plan: plan, Exec: exec, Err: err}
+	}
+}
 
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: fmt.Errorf("could not determine schema")}
-		}
+// openHistoryDialog shows the query history browser and loads its first page
+func (a *App) openHistoryDialog() tea.Cmd {
+	if a.historyStore == nil {
+		a.ShowError("History Not Available", "The query history database could not be opened.")
+		return nil
+	}
+	connections, _ := a.historyStore.Connections()
+	databases, _ := a.historyStore.Databases()
 
-		ctx := context.Background()
-		source, err := metadata.GetTriggerFunctionSource(ctx, conn.Pool, schema, node.Label)
+	a.historyDialog.Width = min(140, a.state.Width-4)
+	a.historyDialog.Height = a.state.Height - 2
+	a.showHistory = true
+	return a.historyDialog.Reset(connections, databases)
+}
+
+// loadHistory queries a page of history entries in the background
+func (a *App) loadHistory(msg components.LoadHistoryMsg) tea.Cmd {
+	store := a.historyStore
+	if store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		total, err := store.Count(msg.Filter)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger_function", Err: err}
+			return HistoryLoadedMsg{Err: err}
 		}
+		entries, err := store.Query(msg.Filter, msg.Offset, msg.Limit)
+		return HistoryLoadedMsg{Entries: entries, Total: total, Offset: msg.Offset, Err: err}
+	}
+}
 
-		title := fmt.Sprintf("%s.%s() → trigger", schema, source.Name)
-		content := source.Definition
-		objectID := fmt.Sprintf("trigfunc:%s.%s", schema, source.Name)
+// openActivityDialog shows the server activity monitor and starts
+// refreshing it
+func (a *App) openActivityDialog() tea.Cmd {
+	if a.state.ActiveConnection == nil {
+		a.ShowError("No Connection", "Connect to a database to monitor its server.")
+		return nil
+	}
+	a.activityDialog.Width = min(160, a.state.Width-4)
+	a.activityDialog.Height = a.state.Height - 2
+	a.showActivity = true
+	return a.activityDialog.Reset()
+}
 
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "trigger_function",
-			ObjectName: fmt.Sprintf("%s.%s", schema, source.Name),
-			ObjectID:   objectID,
-			Title:      title,
-			Content:    content,
+// loadActivity queries the sessions of the server in the background
+func (a *App) loadActivity() tea.Cmd {
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
+		if err != nil {
+			return ActivityLoadedMsg{Err: fmt.Errorf("failed to get connection: %w", err)}
 		}
+		sessions, err := metadata.ListSessions(context.Background(), conn.Pool)
+		return ActivityLoadedMsg{Sessions: sessions, Err: err}
 	}
 }
 
-// loadSequenceDetails loads sequence properties
-func (a *App) loadSequenceDetails(node *models.TreeNode) tea.Cmd {
+// signalBackend cancels the query of a backend, or terminates it, in the
+// background
+func (a *App) signalBackend(pid int, terminate bool) tea.Cmd {
 	return func() tea.Msg {
 		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: err}
+			return BackendSignalledMsg{PID: pid, Terminate: terminate, Err: fmt.Errorf("failed to get connection: %w", err)}
 		}
+		err = metadata.SignalBackend(context.Background(), conn.Pool, pid, terminate)
+		return BackendSignalledMsg{PID: pid, Terminate: terminate, Err: err}
+	}
+}
 
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: fmt.Errorf("could not determine schema")}
+// openERDiagram shows the diagram of the schema selected in the tree, or of
+// the active table's schema with that table selected, and loads it
+func (a *App) openERDiagram() tea.Cmd {
+	if a.state.ActiveConnection == nil {
+		a.ShowError("No Connection", "Connect to a database to diagram its schemas.")
+		return nil
+	}
+
+	var schema, table string
+	if a.state.FocusArea == models.FocusTreeView && a.treeView != nil {
+		if node := a.treeView.GetCurrentNode(); node != nil {
+			if node.Type == models.TreeNodeTypeSchema {
+				schema = strings.Split(node.Label, " ")[0]
+			} else {
+				schema = a.getSchemaFromNode(node)
+			}
+			if node.Type == models.TreeNodeTypeTable {
+				table = node.Label
+			}
+			if schema != "" {
+				if err := a.useTreeNode(node); err != nil {
+					a.ShowError("Cannot Switch Database", err.Error())
+					return nil
+				}
+			}
 		}
+	}
+	if schema == "" {
+		if tab, _ := a.activeTableDataTab(); tab != nil {
+			schema, table = tab.Structure.GetSchema(), tab.Structure.GetTable()
+		}
+	}
+	if schema == "" {
+		a.ShowError("No Schema", "Select a schema or table in the tree, or open a table first.")
+		return nil
+	}
 
-		ctx := context.Background()
-		details, err := metadata.GetSequenceDetails(ctx, conn.Pool, schema, node.Label)
+	a.erDiagram.Width = a.state.Width - 2
+	a.erDiagram.Height = a.state.Height - 2
+	a.erDiagram.SetLoading(schema)
+	a.showERDiagram = true
+
+	selected := ""
+	if table != "" {
+		selected = schema + "." + table
+	}
+	return func() tea.Msg {
+		conn, err := a.connectionManager.GetActive()
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "sequence", Err: err}
+			return ERDiagramLoadedMsg{Schema: schema, Err: fmt.Errorf("failed to get connection: %w", err)}
 		}
+		graph, err := loadSchemaGraph(context.Background(), conn.Pool, schema)
+		return ERDiagramLoadedMsg{Schema: schema, Graph: graph, Selected: selected, Err: err}
+	}
+}
 
-		// Format as CREATE SEQUENCE statement
-		var b strings.Builder
-		b.WriteString(fmt.Sprintf("-- Current Value: %d\n", details.CurrentValue))
-		if details.Owner != "" {
-			b.WriteString(fmt.Sprintf("-- Owner: %s\n", details.Owner))
-		}
-		b.WriteString("\n")
-		b.WriteString(fmt.Sprintf("CREATE SEQUENCE %s.%s\n", schema, details.Name))
-		b.WriteString(fmt.Sprintf("    INCREMENT BY %d\n", details.Increment))
-		b.WriteString(fmt.Sprintf("    MINVALUE %d\n", details.MinValue))
-		b.WriteString(fmt.Sprintf("    MAXVALUE %d\n", details.MaxValue))
-		b.WriteString(fmt.Sprintf("    START WITH %d\n", details.StartValue))
-		if details.Cycle {
-			b.WriteString("    CYCLE")
-		} else {
-			b.WriteString("    NO CYCLE")
+// loadSchemaGraph reads the columns and foreign keys of every table of a
+// schema
+func loadSchemaGraph(ctx context.Context, pool *connection.Pool, schema string) (*erd.Graph, error) {
+	tables, err := metadata.ListTables(ctx, pool, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	metas := make([]erd.TableMetadata, 0, len(tables))
+	for _, table := range tables {
+		columns, err := metadata.GetColumnDetails(ctx, pool, schema, table.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load columns of %s: %w", table.Name, err)
 		}
-		b.WriteString(";")
-
-		objectID := fmt.Sprintf("seq:%s.%s", schema, details.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "sequence",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s", schema, details.Name),
-			Content:    b.String(),
+		constraints, err := metadata.GetConstraints(ctx, pool, schema, table.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load constraints of %s: %w", table.Name, err)
 		}
+		metas = append(metas, erd.TableMetadata{Name: table.Name, Columns: columns, Constraints: constraints})
 	}
+	return erd.NewGraph(schema, metas), nil
 }
 
-// loadIndexDetails loads index DDL definition
-func (a *App) loadIndexDetails(node *models.TreeNode) tea.Cmd {
+// exportERDiagram saves a diagram to a timestamped file in the working
+// directory
+func exportERDiagram(msg components.ExportERDiagramMsg) tea.Cmd {
 	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
+		name := fmt.Sprintf("%s-erd-%s.%s", msg.Schema, time.Now().Format("20060102-150405"), msg.Extension)
+		path, err := filepath.Abs(name)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: err}
+			return ERDiagramExportedMsg{Err: err}
 		}
-
-		schema := a.getSchemaFromNode(node)
-		table := a.getTableFromNode(node)
-		if schema == "" || table == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: fmt.Errorf("could not determine schema/table")}
+		if err := os.WriteFile(path, []byte(msg.Content), 0644); err != nil {
+			return ERDiagramExportedMsg{Path: path, Err: err}
 		}
+		return ERDiagramExportedMsg{Path: path}
+	}
+}
 
-		ctx := context.Background()
-		indexes, err := metadata.ListTableIndexes(ctx, conn.Pool, schema, table)
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: err}
+// openSchemaDiffDialog asks for the two schemas to compare, starting from
+// the schema selected in the tree on the active connection
+func (a *App) openSchemaDiffDialog() {
+	if a.state.ActiveConnection == nil {
+		a.ShowError("No Connection", "Connect to a database to compare its schemas.")
+		return
+	}
+
+	var ids []string
+	for _, conn := range a.connectionManager.GetAll() {
+		if conn.Connected {
+			ids = append(ids, conn.ID)
 		}
+	}
+	sort.Strings(ids)
 
-		// Find the specific index
-		var content string
-		for _, idx := range indexes {
-			if idx.Name == node.Label {
-				content = idx.Definition
-				break
+	schema := "public"
+	if a.treeView != nil {
+		if node := a.treeView.GetCurrentNode(); node != nil {
+			if node.Type == models.TreeNodeTypeSchema {
+				schema = strings.Split(node.Label, " ")[0]
+			} else if s := a.getSchemaFromNode(node); s != "" {
+				schema = s
 			}
 		}
+	}
 
-		if content == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "index", Err: fmt.Errorf("index %s not found", node.Label)}
-		}
+	a.schemaDiffDialog.Open(ids, a.state.ActiveConnection.ID, schema)
+	a.showSchemaDiffDialog = true
+}
 
-		objectID := fmt.Sprintf("idx:%s.%s.%s", schema, table, node.Label)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "index",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (on %s)", schema, node.Label, table),
-			Content:    content,
+// compareSchemas loads both schemas in the background and compares them
+func (a *App) compareSchemas(source, target components.SchemaDiffEndpoint) tea.Cmd {
+	a.showSchemaDiffDialog = false
+	a.schemaDiff.Width = a.state.Width - 2
+	a.schemaDiff.Height = a.state.Height - 2
+	a.schemaDiff.SetLoading(source, target)
+	a.showSchemaDiff = true
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		schemas := make([]*schemadiff.Schema, 2)
+		endpoints := []components.SchemaDiffEndpoint{source, target}
+		for i, endpoint := range endpoints {
+			conn, err := a.connectionManager.Get(endpoint.ConnectionID)
+			if err != nil {
+				return SchemaDiffLoadedMsg{Source: source, Target: target, Err: err}
+			}
+			if conn.Pool == nil {
+				return SchemaDiffLoadedMsg{Source: source, Target: target, Err: fmt.Errorf("connection %s is not open", conn.ID)}
+			}
+			schemas[i], err = schemadiff.Load(ctx, conn.Pool, endpoint.Schema)
+			if err != nil {
+				return SchemaDiffLoadedMsg{Source: source, Target: target, Err: fmt.Errorf("failed to load %s: %w", endpoint.Label(endpoints[1-i]), err)}
+			}
 		}
+		return SchemaDiffLoadedMsg{Source: source, Target: target, Comparison: schemadiff.Compare(schemas[0], schemas[1])}
 	}
 }
 
-// loadTriggerDetails loads trigger DDL definition
-func (a *App) loadTriggerDetails(node *models.TreeNode) tea.Cmd {
+// saveMigration saves a migration script to a timestamped file in the
+// working directory
+func saveMigration(msg components.SaveMigrationMsg) tea.Cmd {
 	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
+		name := fmt.Sprintf("%s-migration-%s.sql", msg.Schema, time.Now().Format("20060102-150405"))
+		path, err := filepath.Abs(name)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: err}
+			return MigrationSavedMsg{Err: err}
 		}
-
-		schema := a.getSchemaFromNode(node)
-		table := a.getTableFromNode(node)
-		if schema == "" || table == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: fmt.Errorf("could not determine schema/table")}
+		if err := os.WriteFile(path, []byte(msg.Script), 0644); err != nil {
+			return MigrationSavedMsg{Path: path, Err: err}
 		}
+		return MigrationSavedMsg{Path: path}
+	}
+}
+
+// followForeignKey opens the row referenced by the foreign key of the
+// selected cell in a new tab
+func (a *App) followForeignKey() (tea.Model, tea.Cmd) {
+	tab, _ := a.activeTableDataTab()
+	if tab == nil {
+		return a, nil
+	}
+	tableView := tab.Structure.GetTableView()
+	row, col := tableView.GetSelectedCell()
+	if row < 0 || row >= len(tableView.Rows) || col < 0 || col >= len(tableView.Columns) {
+		return a, nil
+	}
+	fk := tab.Structure.ForeignKeyFor(tableView.Columns[col])
+	if fk == nil {
+		return a, nil
+	}
+
+	schema, table, _ := strings.Cut(fk.ForeignTable, ".")
+	filter, err := keyFilter(schema, table, fk.ForeignCols, fk.Columns, selectedRowValues(tab), columnTypes(tab))
+	if err != nil {
+		a.ShowError("Cannot Follow Foreign Key", err.Error())
+		return a, nil
+	}
+	// The referenced row opens in the tab's database
+	a.useTabConnection()
+	from := tab.Structure.Location()
+	to := models.TableLocation{Schema: schema, Table: table, Filter: filter}
+	return a, func() tea.Msg {
+		return FollowReferenceMsg{From: from, To: to}
+	}
+}
+
+// referenceCountLimit is how many referencing rows are counted per table
+const referenceCountLimit = 1000
+
+// showReferencingRows lists the tables with rows referencing the selected
+// row, with how many there are
+func (a *App) showReferencingRows() (tea.Model, tea.Cmd) {
+	tab, reason := a.activeTableDataTab()
+	if tab == nil {
+		a.ShowError("Cannot List References", reason)
+		return a, nil
+	}
+	tableView := tab.Structure.GetTableView()
+	if tableView.SelectedRow < 0 || tableView.SelectedRow >= len(tableView.Rows) {
+		return a, nil
+	}
 
+	// Referencing rows open beside the tab, in its database
+	a.useTabConnection()
+	from := tab.Structure.Location()
+	values := selectedRowValues(tab)
+	types := columnTypes(tab)
+	target := tabTarget(tab)
+	return a, func() tea.Msg {
 		ctx := context.Background()
-		triggers, err := metadata.ListTableTriggers(ctx, conn.Pool, schema, table)
+		pool, err := a.targetPool(ctx, target)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: err}
+			return ReferencesLoadedMsg{From: from, Err: err}
+		}
+		references, err := metadata.GetReferences(ctx, pool, from.Schema, from.Table)
+		if err != nil {
+			return ReferencesLoadedMsg{From: from, Err: err}
 		}
 
-		// Find the specific trigger
-		var content string
-		for _, trg := range triggers {
-			if trg.Name == node.Label {
-				content = trg.Definition
-				break
+		var entries []components.ReferenceEntry
+		for _, ref := range references {
+			// A NULL key is referenced by no rows
+			filter, err := keyFilter(ref.Schema, ref.Table, ref.Columns, ref.RefColumns, values, types)
+			if err != nil {
+				continue
+			}
+			where, args, err := filterBuilder.NewBuilder().BuildWhere(*filter)
+			if err != nil {
+				return ReferencesLoadedMsg{From: from, Err: err}
+			}
+			count, err := metadata.CountRowsUpTo(ctx, pool, ref.Schema, ref.Table, where, args, referenceCountLimit+1)
+			if err != nil {
+				return ReferencesLoadedMsg{From: from, Err: err}
+			}
+
+			detail := fmt.Sprintf("%d rows", count)
+			switch {
+			case count > referenceCountLimit:
+				detail = fmt.Sprintf("%d+ rows", referenceCountLimit)
+			case count == 1:
+				detail = "1 row"
 			}
+			entries = append(entries, components.ReferenceEntry{
+				Label:  fmt.Sprintf("%s.%s (%s)", ref.Schema, ref.Table, strings.Join(ref.Columns, ", ")),
+				Detail: detail,
+				Action: FollowReferenceMsg{From: from, To: models.TableLocation{Schema: ref.Schema, Table: ref.Table, Filter: filter}},
+			})
 		}
+		return ReferencesLoadedMsg{From: from, Entries: entries}
+	}
+}
 
-		if content == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "trigger", Err: fmt.Errorf("trigger %s not found", node.Label)}
+// navigateHistory goes back, or forward, from a table data tab to the rows
+// shown before or after following a foreign key
+func (a *App) navigateHistory(tab *components.ResultTab, forward bool) (tea.Model, tea.Cmd) {
+	var loc models.TableLocation
+	var ok bool
+	if forward {
+		loc, ok = a.navHistory.Forward(tab.Structure.Location())
+	} else {
+		loc, ok = a.navHistory.Back(tab.Structure.Location())
+	}
+	if !ok {
+		return a, nil
+	}
+	a.useTabConnection()
+	return a, a.openTableLocation(loc)
+}
+
+// openTableLocation shows a table location in its data tab, restoring the
+// selection if the tab is still open, or loads it into a new tab
+func (a *App) openTableLocation(loc models.TableLocation) tea.Cmd {
+	objectID := loc.ObjectID()
+	a.state.FocusArea = models.FocusDataPanel
+	a.updatePanelStyles()
+
+	for i, tab := range a.resultTabs.GetAllTabs() {
+		if tab.ObjectID == objectID && tab.Type == components.TabTypeTableData && tab.Structure != nil && a.resultTabs.SameTarget(tab) {
+			a.resultTabs.SetActiveTab(i)
+			tab.Structure.SwitchTab(0)
+			tableView := tab.Structure.GetTableView()
+			tableView.SetSelectedRow(loc.Row)
+			tableView.MoveSelectionHorizontal(loc.Col - tableView.SelectedCol)
+			return nil
+		}
+	}
+
+	structureView := components.NewStructureView(a.theme, components.NewTableView(a.theme))
+	structureView.SetFilter(loc.Filter)
+	a.resultTabs.AddTableData(objectID, loc.Title(), structureView)
+	return a.loadTableDataForTab(a.resultTabs.GetActiveTab(), loc.Schema, loc.Table)
+}
+
+// selectedRowValues returns the values of the selected row of a table data
+// tab by column name; NULL is nil
+func selectedRowValues(tab *components.ResultTab) map[string]*string {
+	tableView := tab.Structure.GetTableView()
+	row := tableView.SelectedRow
+	values := make(map[string]*string, len(tableView.Columns))
+	if row < 0 || row >= len(tableView.Rows) {
+		return values
+	}
+	for i, column := range tableView.Columns {
+		if i >= len(tableView.Rows[row]) || tableView.IsNullCell(row, i) {
+			values[column] = nil
+			continue
 		}
+		value := tableView.Rows[row][i]
+		values[column] = &value
+	}
+	return values
+}
+
+// columnTypes returns the types of the columns of a table data tab by name
+func columnTypes(tab *components.ResultTab) map[string]string {
+	types := make(map[string]string)
+	for _, col := range tab.Structure.GetColumns() {
+		types[col.Name] = col.DataType
+	}
+	return types
+}
+
+// keyFilter returns a filter on schema.table for the rows whose columns
+// equal the values of keyColumns in a row, whose types are the types of the
+// key columns. It fails when a key value is NULL, which matches no rows.
+func keyFilter(schema, table string, columns, keyColumns []string, values map[string]*string, types map[string]string) (*models.Filter, error) {
+	if len(columns) != len(keyColumns) || len(columns) == 0 {
+		return nil, fmt.Errorf("foreign key columns of %s.%s do not match", schema, table)
+	}
+	filter := &models.Filter{
+		Schema:    schema,
+		TableName: table,
+		RootGroup: models.FilterGroup{Logic: "AND"},
+	}
+	for i, column := range columns {
+		value, ok := values[keyColumns[i]]
+		if !ok {
+			return nil, fmt.Errorf("column %s is not loaded", keyColumns[i])
+		}
+		if value == nil {
+			return nil, fmt.Errorf("%s is NULL, so it references no row", keyColumns[i])
+		}
+		filter.RootGroup.Conditions = append(filter.RootGroup.Conditions, models.FilterCondition{
+			Column:   column,
+			Operator: models.OpEqual,
+			Value:    *value,
+			Type:     types[keyColumns[i]],
+		})
+	}
+	return filter, nil
+}
+
+// recordQuery adds an executed statement and the values bound to its
+// variables to the query history
+func (a *App) recordQuery(sql string, values models.BindValues, result models.QueryResult) {
+	if a.historyStore == nil {
+		return
+	}
+	connName := ""
+	dbName := ""
+	if a.state.ActiveConnection != nil {
+		connName = a.state.ActiveConnection.Config.Name
+		dbName = a.state.ActiveConnection.Database
+	}
+
+	entry := history.HistoryEntry{
+		ConnectionName: connName,
+		DatabaseName:   dbName,
+		Query:          sql,
+		Duration:       result.Duration,
+		RowsAffected:   result.RowsAffected,
+		Success:        result.Error == nil,
+		Parameters:     query.DescribeBinds(sql, values),
+	}
 
-		objectID := fmt.Sprintf("trig:%s.%s.%s", schema, table, node.Label)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "trigger",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (on %s)", schema, node.Label, table),
-			Content:    content,
-		}
+	if result.Error != nil {
+		entry.ErrorMessage = result.Error.Error()
 	}
+
+	a.recordHistory(entry)
 }
 
-// loadExtensionDetails loads extension information
-func (a *App) loadExtensionDetails(node *models.TreeNode) tea.Cmd {
-	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "extension", Err: err}
-		}
+// promptBindValues opens the prompt for the bind variables of a statement,
+// pre-filled with earlier values. The action runs once they are entered.
+func (a *App) promptBindValues(sql string, names []string, values models.BindValues, action tea.Msg) tea.Cmd {
+	a.bindDialog.SetStatement(sql, names, values, action)
+	a.showBindDialog = true
+	return a.bindDialog.Init()
+}
 
-		// Parse extension name from label (format: "name vX.Y")
-		name := node.Label
-		if idx := strings.Index(name, " v"); idx != -1 {
-			name = name[:idx]
-		}
+// rememberBindValues keeps the values entered for editor queries to pre-fill
+// the next prompt
+func (a *App) rememberBindValues(values models.BindValues) {
+	if a.bindValues == nil {
+		a.bindValues = make(models.BindValues)
+	}
+	for name, value := range values {
+		a.bindValues[name] = value
+	}
+}
 
-		ctx := context.Background()
-		details, err := metadata.GetExtensionDetails(ctx, conn.Pool, name)
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "extension", Err: err}
-		}
+// recordHistory stores an executed query according to the history settings
+func (a *App) recordHistory(entry history.HistoryEntry) {
+	if a.historyStore == nil {
+		return
+	}
+	settings := config.GetDefaults().History
+	if a.config != nil {
+		settings = a.config.History
+	}
+	if !settings.Enabled || (!entry.Success && !settings.SaveFailedQueries) {
+		return
+	}
 
-		// Format as CREATE EXTENSION statement
-		var b strings.Builder
-		b.WriteString(fmt.Sprintf("-- Extension: %s\n", details.Name))
-		b.WriteString(fmt.Sprintf("-- Version: %s\n", details.Version))
-		if details.Description != "" {
-			b.WriteString(fmt.Sprintf("-- %s\n", details.Description))
-		}
-		b.WriteString("\n")
-		b.WriteString(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s\n", details.Name))
-		b.WriteString(fmt.Sprintf("    SCHEMA %s\n", details.Schema))
-		b.WriteString(fmt.Sprintf("    VERSION '%s';", details.Version))
+	// Record to history (ignore errors to not interrupt user flow)
+	if err := a.historyStore.Add(entry); err != nil {
+		return
+	}
+	_, _ = a.historyStore.Trim(settings.MaxEntries)
+}
 
-		objectID := fmt.Sprintf("ext:%s", details.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "extension",
-			ObjectID:   objectID,
-			Title:      details.Name,
-			Content:    b.String(),
-		}
+// favoriteNameForQuery derives a unique favorite name from a query's text
+func favoriteNameForQuery(sql string, existing []models.Favorite) string {
+	base := strings.Join(strings.Fields(sql), " ")
+	base = runewidth.Truncate(base, 60, "…")
+
+	taken := make(map[string]bool, len(existing))
+	for _, fav := range existing {
+		taken[strings.ToLower(fav.Name)] = true
+	}
+	name := base
+	for i := 2; taken[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s (%d)", base, i)
 	}
+	return name
 }
 
-// loadCompositeTypeDetails loads composite type definition
-func (a *App) loadCompositeTypeDetails(node *models.TreeNode) tea.Cmd {
-	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+// openImportDialog resolves the target table and loads its columns for the import dialog.
+// The table is the selected tree node when the tree is focused, otherwise the active table tab.
+func (a *App) openImportDialog() (tea.Model, tea.Cmd) {
+	var schema, table string
+	var target dataTarget
+	if a.state.FocusArea == models.FocusTreeView && a.treeView != nil {
+		if node := a.treeView.GetCurrentNode(); node != nil && node.Type == models.TreeNodeTypeTable {
+			schema, table = a.getSchemaFromNode(node), node.Label
+			if err := a.useTreeNode(node); err != nil {
+				a.ShowError("Cannot Switch Database", err.Error())
+				return a, nil
+			}
+			target = a.activeTarget()
 		}
-
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
+	}
+	if table == "" {
+		if tab := a.resultTabs.GetActiveTab(); tab != nil && tab.Type == components.TabTypeTableData && tab.Structure != nil {
+			schema, table = tab.Structure.GetSchema(), tab.Structure.GetTable()
+			target = tabTarget(tab)
 		}
+	}
+	if schema == "" || table == "" {
+		a.ShowError("Cannot Import", "Select a table in the tree or open a table first.")
+		return a, nil
+	}
+	if a.importCancel != nil {
+		a.ShowError("Cannot Import", "Another import is still running.")
+		return a, nil
+	}
 
+	objectID := schema + "." + table
+	return a, func() tea.Msg {
 		ctx := context.Background()
-		details, err := metadata.GetCompositeTypeDetails(ctx, conn.Pool, schema, node.Label)
+		pool, err := a.targetPool(ctx, target)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
-		}
-
-		// Format as CREATE TYPE statement
-		var b strings.Builder
-		b.WriteString(fmt.Sprintf("CREATE TYPE %s.%s AS (\n", schema, details.Name))
-		for i, attr := range details.Attributes {
-			comma := ","
-			if i == len(details.Attributes)-1 {
-				comma = ""
-			}
-			b.WriteString(fmt.Sprintf("    %s %s%s\n", attr.Name, attr.Type, comma))
+			return ImportTargetLoadedMsg{ObjectID: objectID, Schema: schema, Table: table, Err: err}
 		}
-		b.WriteString(");")
-
-		objectID := fmt.Sprintf("type:composite:%s.%s", schema, details.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "type",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (composite)", schema, details.Name),
-			Content:    b.String(),
+		columns, err := metadata.GetColumnDetails(ctx, pool, schema, table)
+		if err == nil && len(columns) == 0 {
+			err = fmt.Errorf("table has no columns")
 		}
+		return ImportTargetLoadedMsg{ObjectID: objectID, Target: target, Schema: schema, Table: table, Columns: columns, Err: err}
 	}
 }
 
-// loadEnumTypeDetails loads enum type definition
-func (a *App) loadEnumTypeDetails(node *models.TreeNode) tea.Cmd {
+// loadImportFile reads and parses an import file
+func loadImportFile(path string) tea.Cmd {
 	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+		path = expandHomePath(path)
+		data, err := importer.ReadFile(path)
+		if err == nil && len(data.Rows) == 0 {
+			err = fmt.Errorf("%s contains no rows", filepath.Base(path))
 		}
+		return ImportFileLoadedMsg{Path: path, Data: data, Err: err}
+	}
+}
 
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
-		}
+// startImport type-checks the file rows and copies the valid ones into the table,
+// reporting progress through a channel
+func (a *App) startImport(msg components.StartImportMsg) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan ImportProgressMsg, 1)
+	a.importCancel = cancel
+	a.importProgress = progress
 
-		ctx := context.Background()
-		enums, err := metadata.ListEnumTypes(ctx, conn.Pool, schema)
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
-		}
+	target := a.importTarget
+	run := func() tea.Msg {
+		defer cancel()
+		defer close(progress)
 
-		// Find the specific enum
-		var enumType *metadata.EnumType
-		for _, e := range enums {
-			if e.Name == node.Label {
-				enumType = &e
-				break
-			}
-		}
+		valid, rowErrors := importer.Validate(msg.Data, msg.Mappings)
 
-		if enumType == nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("enum type %s not found", node.Label)}
+		pool, err := a.targetPool(ctx, target)
+		if err != nil {
+			return ImportCompleteMsg{ObjectID: msg.ObjectID, RowErrors: rowErrors, Err: err}
 		}
 
-		// Format as CREATE TYPE statement
-		var b strings.Builder
-		b.WriteString(fmt.Sprintf("CREATE TYPE %s.%s AS ENUM (\n", schema, enumType.Name))
-		for i, label := range enumType.Labels {
-			comma := ","
-			if i == len(enumType.Labels)-1 {
-				comma = ""
+		total := len(valid)
+		inserted, rejected, err := importer.Load(ctx, pool, msg.Schema, msg.Table, msg.Data, msg.Mappings, valid, func(done int) {
+			// Drop updates while the UI is still busy with the previous one
+			select {
+			case progress <- ImportProgressMsg{Done: done, Total: total}:
+			default:
 			}
-			b.WriteString(fmt.Sprintf("    '%s'%s\n", label, comma))
-		}
-		b.WriteString(");")
+		})
 
-		objectID := fmt.Sprintf("type:enum:%s.%s", schema, enumType.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "type",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (enum)", schema, enumType.Name),
-			Content:    b.String(),
-		}
+		rowErrors = append(rowErrors, rejected...)
+		sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+		return ImportCompleteMsg{ObjectID: msg.ObjectID, Inserted: inserted, RowErrors: rowErrors, Err: err}
 	}
+
+	return tea.Batch(run, waitForImportProgress(progress))
 }
 
-// loadDomainTypeDetails loads domain type definition
-func (a *App) loadDomainTypeDetails(node *models.TreeNode) tea.Cmd {
+// waitForImportProgress waits for the next progress update of a running import
+func waitForImportProgress(progress chan ImportProgressMsg) tea.Cmd {
+	if progress == nil {
+		return nil
+	}
 	return func() tea.Msg {
-		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+		msg, ok := <-progress
+		if !ok {
+			return nil
 		}
+		return msg
+	}
+}
 
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
+// expandHomePath expands a leading ~ to the user's home directory
+func expandHomePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
 		}
+	}
+	return path
+}
 
-		ctx := context.Background()
-		details, err := metadata.GetDomainTypeDetails(ctx, conn.Pool, schema, node.Label)
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
-		}
+// formatting returns the SQL formatting options from the editor config, and
+// whether SQL is formatted before it runs or is saved
+func (a *App) formatting() (sqlformat.Options, bool) {
+	editor := config.GetDefaults().Editor
+	if a.config != nil {
+		editor = a.config.Editor
+	}
+	return sqlformat.Options{TabSize: editor.TabSize, UseSpaces: editor.UseSpaces}, editor.FormatOnSave
+}
 
-		// Format as CREATE DOMAIN statement
-		var b strings.Builder
-		b.WriteString(fmt.Sprintf("CREATE DOMAIN %s.%s AS %s", schema, details.Name, details.BaseType))
-		if details.NotNull {
-			b.WriteString(" NOT NULL")
-		}
-		if details.Default != "" {
-			b.WriteString(fmt.Sprintf(" DEFAULT %s", details.Default))
-		}
-		for _, constraint := range details.Constraints {
-			b.WriteString(fmt.Sprintf("\n    %s", constraint))
-		}
-		b.WriteString(";")
+// completionCache holds the completion catalog of a connection
+type completionCache struct {
+	catalog  *completion.Catalog
+	loadedAt time.Time
+	loading  bool
+	columns  map[string]bool // Relations whose columns were requested
+}
 
-		objectID := fmt.Sprintf("type:domain:%s.%s", schema, details.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "type",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (domain)", schema, details.Name),
-			Content:    b.String(),
+// CompletionCatalogLoadedMsg is sent when the completion catalog of a
+// connection has loaded
+type CompletionCatalogLoadedMsg struct {
+	Key     string // completionKey of the connection and database
+	Catalog *completion.Catalog
+	Err     error
+}
+
+// CompletionColumnsLoadedMsg is sent when the columns of a relation have
+// loaded for completion
+type CompletionColumnsLoadedMsg struct {
+	Key      string // completionKey of the connection and database
+	Relation completion.Relation
+	Columns  []completion.Column
+	Err      error
+}
+
+// complete returns the SQL editor completions at offset. It loads the
+// catalog of the connection when missing or older than the metadata cache
+// TTL, and the columns of relations the statement uses.
+func (a *App) complete(sql string, offset int) (completion.Result, tea.Cmd) {
+	conn := a.state.ActiveConnection
+	if conn == nil {
+		return completion.Complete(nil, sql, offset), nil
+	}
+	key := completionKey(conn.ID, conn.Database)
+	cache := a.completionCaches[key]
+	if cache == nil {
+		cache = &completionCache{columns: make(map[string]bool)}
+		a.completionCaches[key] = cache
+	}
+
+	var cmds []tea.Cmd
+	ttl := time.Duration(config.GetDefaults().Performance.MetadataCacheTTL) * time.Second
+	if a.config != nil {
+		ttl = time.Duration(a.config.Performance.MetadataCacheTTL) * time.Second
+	}
+	if !cache.loading && (cache.catalog == nil || time.Since(cache.loadedAt) > ttl) {
+		cache.loading = true
+		cmds = append(cmds, a.loadCompletionCatalog(key))
+	}
+
+	res := completion.Complete(cache.catalog, sql, offset)
+	for _, rel := range res.Missing {
+		if !cache.columns[rel.Key()] {
+			cache.columns[rel.Key()] = true
+			cmds = append(cmds, a.loadCompletionColumns(key, rel))
 		}
 	}
+	return res, tea.Batch(cmds...)
 }
 
-// loadRangeTypeDetails loads range type definition
-func (a *App) loadRangeTypeDetails(node *models.TreeNode) tea.Cmd {
+// completionKey keys the completion cache of a database of a connection
+func completionKey(connID, database string) string {
+	return connID + "/" + database
+}
+
+// loadCompletionCatalog loads the schemas, relations, functions and search
+// path of the database a completion cache key names
+func (a *App) loadCompletionCatalog(key string) tea.Cmd {
 	return func() tea.Msg {
 		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
-		}
-
-		schema := a.getSchemaFromNode(node)
-		if schema == "" {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("could not determine schema")}
+		if err != nil || completionKey(conn.ID, conn.Database) != key {
+			return CompletionCatalogLoadedMsg{Key: key, Err: fmt.Errorf("connection is no longer active")}
 		}
-
 		ctx := context.Background()
-		ranges, err := metadata.ListRangeTypes(ctx, conn.Pool, schema)
+
+		schemas, err := metadata.ListSchemas(ctx, conn.Pool)
 		if err != nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: err}
+			return CompletionCatalogLoadedMsg{Key: key, Err: err}
 		}
+		catalog := &completion.Catalog{}
+		catalog.SearchPath, _ = metadata.ListSearchPath(ctx, conn.Pool)
+		for _, schema := range schemas {
+			catalog.Schemas = append(catalog.Schemas, schema.Name)
 
-		// Find the specific range type
-		var rangeType *metadata.RangeType
-		for _, r := range ranges {
-			if r.Name == node.Label {
-				rangeType = &r
-				break
+			tables, _ := metadata.ListTables(ctx, conn.Pool, schema.Name)
+			for _, t := range tables {
+				catalog.Relations = append(catalog.Relations, completion.Relation{Schema: t.Schema, Name: t.Name})
+			}
+			views, _ := metadata.ListViews(ctx, conn.Pool, schema.Name)
+			for _, v := range views {
+				catalog.Relations = append(catalog.Relations, completion.Relation{Schema: v.Schema, Name: v.Name, View: true})
+			}
+			matViews, _ := metadata.ListMaterializedViews(ctx, conn.Pool, schema.Name)
+			for _, v := range matViews {
+				catalog.Relations = append(catalog.Relations, completion.Relation{Schema: v.Schema, Name: v.Name, View: true})
+			}
+			functions, _ := metadata.ListFunctions(ctx, conn.Pool, schema.Name)
+			for _, f := range functions {
+				catalog.Functions = append(catalog.Functions, completion.Function{Schema: f.Schema, Name: f.Name, Arguments: f.Arguments})
 			}
 		}
-
-		if rangeType == nil {
-			return ObjectDetailsLoadedMsg{ObjectType: "type", Err: fmt.Errorf("range type %s not found", node.Label)}
-		}
-
-		// Format as CREATE TYPE statement
-		content := fmt.Sprintf("CREATE TYPE %s.%s AS RANGE (\n    SUBTYPE = %s\n);",
-			schema, rangeType.Name, rangeType.Subtype)
-
-		objectID := fmt.Sprintf("type:range:%s.%s", schema, rangeType.Name)
-		return ObjectDetailsLoadedMsg{
-			ObjectType: "type",
-			ObjectID:   objectID,
-			Title:      fmt.Sprintf("%s.%s (range)", schema, rangeType.Name),
-			Content:    content,
-		}
+		return CompletionCatalogLoadedMsg{Key: key, Catalog: catalog}
 	}
 }
 
-// saveObjectDefinition executes the SQL to save an object definition
-func (a *App) saveObjectDefinition(msg components.SaveObjectMsg) tea.Cmd {
+// loadCompletionColumns loads the columns of a relation for completion
+func (a *App) loadCompletionColumns(key string, rel completion.Relation) tea.Cmd {
 	return func() tea.Msg {
 		conn, err := a.connectionManager.GetActive()
-		if err != nil {
-			return components.ObjectSavedMsg{Success: false, Error: err}
+		if err != nil || completionKey(conn.ID, conn.Database) != key {
+			return CompletionColumnsLoadedMsg{Key: key, Relation: rel, Err: fmt.Errorf("connection is no longer active")}
 		}
-
-		ctx := context.Background()
-
-		// The content should be the full CREATE OR REPLACE statement for functions/procedures
-		// For other object types, we may need to generate appropriate SQL
-		sql := msg.Content
-
-		_, err = conn.Pool.Execute(ctx, sql)
+		columns, err := metadata.GetTableColumns(context.Background(), conn.Pool, rel.Schema, rel.Name)
 		if err != nil {
-			return components.ObjectSavedMsg{Success: false, Error: err}
+			return CompletionColumnsLoadedMsg{Key: key, Relation: rel, Err: err}
 		}
-
-		return components.ObjectSavedMsg{Success: true}
+		result := make([]completion.Column, 0, len(columns))
+		for _, col := range columns {
+			result = append(result, completion.Column{Name: col.Name, Type: col.DataType})
+		}
+		return CompletionColumnsLoadedMsg{Key: key, Relation: rel, Columns: result}
 	}
 }
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/config"
+	"github.com/rebelice/lazypg/internal/db/connection"
+	"github.com/rebelice/lazypg/internal/export"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/profiles"
+	"github.com/rebelice/lazypg/internal/ui/components"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestCommitRunsInTabDatabase(t *testing.T) {
+	th := theme.DefaultTheme()
+	a := &App{resultTabs: components.NewResultTabs(th), connectionManager: connection.NewManager()}
+
+	// The tab is loaded from one server and database
+	a.resultTabs.Connection, a.resultTabs.Database = "server-a", "shop"
+	a.resultTabs.AddTableData("public.orders", "orders", components.NewStructureView(th, components.NewTableView(th)))
+	tab := a.resultTabs.GetActiveTab()
+
+	// The tree then moves on to another server without touching the tab
+	a.resultTabs.Connection, a.resultTabs.Database = "server-b", "crm"
+
+	msg, ok := a.commitPendingChanges(components.CommitChangesMsg{ObjectID: "public.orders"})().(CommitChangesResultMsg)
+	if !ok {
+		t.Fatal("expected a CommitChangesResultMsg")
+	}
+	if msg.TabID != tab.ID {
+		t.Errorf("expected the result for tab %d, got %d", tab.ID, msg.TabID)
+	}
+	// No server is open in the test, so the error names the one tried
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "server-a") {
+		t.Errorf("expected the commit to use the tab's connection, got %v", msg.Err)
+	}
+	if target := tabTarget(tab); target != (dataTarget{Connection: "server-a", Database: "shop"}) {
+		t.Errorf("unexpected tab target %+v", target)
+	}
+
+	// Reloading the tab reads from its database too
+	loaded, ok := a.loadTableDataForTab(tab, "public", "orders")().(TabTableDataLoadedMsg)
+	if !ok || loaded.TabID != tab.ID || loaded.Err == nil || !strings.Contains(loaded.Err.Error(), "server-a") {
+		t.Errorf("expected the reload to use the tab's connection, got %+v", loaded)
+	}
+
+	// The same table on the other server gets a tab of its own
+	a.resultTabs.AddTableData("public.orders", "orders", components.NewStructureView(th, components.NewTableView(th)))
+	if a.resultTabs.TabCount() != 2 || a.resultTabs.GetActiveTab() == tab {
+		t.Errorf("expected a second tab for the other server, got %d tab(s)", a.resultTabs.TabCount())
+	}
+	if found := a.findTableDataTab("public.orders"); found != a.resultTabs.GetActiveTab() {
+		t.Error("expected the active tab to be found first")
+	}
+}
+
+func TestHistoryEntryReconnectsThroughProfile(t *testing.T) {
+	th := theme.DefaultTheme()
+	profilesManager, err := profiles.NewManager(t.TempDir(), []models.ConnectionProfile{
+		{Name: "prod", Host: "db.internal", Database: "app", User: "admin", ReadOnly: true, PasswordCommand: "exit 1"},
+	})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	a := &App{errorOverlay: components.NewErrorOverlay(th), profilesManager: profilesManager}
+
+	// The entry only names the profile, whose password command runs again
+	a.connectToHistoryEntry(models.ConnectionHistoryEntry{Name: "prod", Host: "db.internal", Port: 5432, Database: "app", User: "admin", Profile: "prod"})
+	if !a.showError || a.errorOverlay.Title != "Password Command Failed" {
+		t.Errorf("expected the profile to be used, got %q", a.errorOverlay.Title)
+	}
+
+	// A profile since removed is not connected to without its settings
+	a.connectToHistoryEntry(models.ConnectionHistoryEntry{Name: "old", Host: "db.internal", Port: 5432, Database: "app", User: "admin", Profile: "old"})
+	if a.errorOverlay.Title != "Connection Profile Not Found" {
+		t.Errorf("expected the missing profile to be reported, got %q", a.errorOverlay.Title)
+	}
+}
+
+func TestStartupAppliesToDialogConnection(t *testing.T) {
+	th := theme.DefaultTheme()
+	a := &App{
+		connectionManager: connection.NewManager(),
+		treeView:          components.NewTreeView(nil, th),
+		errorOverlay:      components.NewErrorOverlay(th),
+		startup:           &StartupOptions{Database: "shop", Schema: "billing", Table: "orders", ReadOnly: true},
+	}
+
+	// Without a target, the connection picked in the dialog gets the flags.
+	// Nothing listens on port 1, so the connection fails.
+	a.performConnection(models.ConnectionConfig{Host: "127.0.0.1", Port: 1, Database: "postgres", User: "app", SSLMode: "disable"})
+	conns := a.connectionManager.GetAll()
+	if len(conns) != 1 {
+		t.Fatalf("expected one connection attempt, got %d", len(conns))
+	}
+	if config := conns[0].Config; !config.ReadOnly || config.Database != "shop" || config.DefaultSchema != "billing" {
+		t.Errorf("expected the startup options in the config, got %+v", config)
+	}
+
+	// The table was meant for that connection, read-only stays for the next
+	if a.startup == nil || *a.startup != (StartupOptions{ReadOnly: true}) {
+		t.Errorf("expected only read-only to be kept, got %+v", a.startup)
+	}
+}
+
+func TestFavoriteRunsLikeEditorQuery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := New(config.GetDefaults())
+	a.state.ActiveConnection = &models.Connection{ID: "local", Connected: true}
+	favorite, err := a.favoritesManager.Add("slow", "", "SELECT pg_sleep(60)", "local", "postgres", nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	_, cmd := a.Update(components.ExecuteFavoriteMsg{Favorite: *favorite})
+	if cmd == nil {
+		t.Fatal("expected the favorite to be run")
+	}
+	next, ok := cmd().(components.ExecuteQueryMsg)
+	if !ok || next.SQL != "SELECT pg_sleep(60)" {
+		t.Fatalf("expected the favorite to run as the editor's query, got %#v", next)
+	}
+
+	// The editor's path tracks the execution, so Esc can cancel it
+	a.Update(next)
+	if a.execution == nil || a.executeCancelFn == nil || !a.resultTabs.HasPendingQuery() {
+		t.Error("expected the favorite's execution to be tracked")
+	}
+}
+
+func TestExplainBindsAndTracksExecution(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := New(config.GetDefaults())
+	a.state.ActiveConnection = &models.Connection{ID: "local", Connected: true}
+
+	// Bind variables are asked for before the statement is explained
+	explainMsg := components.ExplainQueryMsg{SQL: "SELECT * FROM users WHERE id = :id", Analyze: true}
+	a.Update(explainMsg)
+	if !a.showBindDialog || a.execution != nil {
+		t.Fatal("expected the bind variables to be asked for first")
+	}
+	id := "1"
+	_, cmd := a.Update(components.BindValuesMsg{Values: models.BindValues{":id": &id}, Action: explainMsg})
+	next, ok := cmd().(components.ExplainQueryMsg)
+	if !ok || next.Values[":id"] == nil || *next.Values[":id"] != "1" {
+		t.Fatalf("expected the explain to get the values, got %#v", next)
+	}
+
+	// It then runs with the configured timeout and can be cancelled
+	_, cmd = a.Update(next)
+	exec := a.execution
+	if exec == nil || a.executeCancelFn == nil || exec.Timeout != a.queryTimeout() {
+		t.Fatal("expected the explain's execution to be tracked")
+	}
+	result, ok := cmd().(ExplainResultMsg)
+	if !ok || result.Exec != exec || result.Err == nil {
+		t.Fatalf("expected the result of the tracked execution, got %+v", result)
+	}
+	a.Update(result)
+	if a.execution != nil || a.executeCancelFn != nil || a.errorOverlay.Title != "Explain Failed" {
+		t.Errorf("expected the execution to end with the error, got %q", a.errorOverlay.Title)
+	}
+}
+
+// pagedSource is a row source that returns one row per fetch
+type pagedSource struct {
+	rows [][]string
+}
+
+func (p *pagedSource) Fetch(ctx context.Context, n int) ([][]string, [][]models.Cell, error) {
+	if len(p.rows) == 0 {
+		return nil, nil, nil
+	}
+	row := p.rows[0]
+	p.rows = p.rows[1:]
+	return [][]string{row}, [][]models.Cell{{{Value: row[0]}}}, nil
+}
+
+func (p *pagedSource) Done() bool   { return len(p.rows) == 0 }
+func (p *pagedSource) Close() error { return nil }
+
+func TestExportStreamedResult(t *testing.T) {
+	a := &App{resultTabs: components.NewResultTabs(theme.DefaultTheme()), connectionManager: connection.NewManager()}
+	sql := "SELECT n FROM numbers"
+	a.resultTabs.StartPendingQuery(sql)
+	a.resultTabs.CompletePendingQuery(sql, models.QueryResult{
+		Columns: []string{"n"},
+		Rows:    [][]string{{"1"}},
+		Cells:   [][]models.Cell{{{Value: "1"}}},
+	}, &pagedSource{rows: [][]string{{"2"}, {"3"}}})
+	tab := a.resultTabs.GetActiveTab()
+
+	result := tab.Result
+	a.exportSource = &exportSource{Result: &result, StreamTab: tab.ID}
+	path := filepath.Join(t.TempDir(), "numbers.csv")
+	msg, ok := a.exportData(export.FormatCSV, path)().(ExportCompleteMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("export failed: %+v", msg)
+	}
+
+	// Every row is written, not only the loaded ones
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "n\n1\n2\n3" || msg.Rows != 3 {
+		t.Errorf("expected all 3 rows, got %d:\n%s", msg.Rows, got)
+	}
+
+	// The fetched rows go back to the tab, which is then complete
+	if msg.TabID != tab.ID || !msg.FetchDone || len(msg.FetchedRows) != 2 {
+		t.Fatalf("unexpected fetched rows: %+v", msg)
+	}
+	a.resultTabs.AppendRows(msg.TabID, msg.FetchedRows, msg.FetchedCells, msg.FetchDone)
+	if len(tab.Result.Rows) != 3 || tab.MoreRows || tab.Fetching {
+		t.Errorf("expected the tab to hold all rows, got %d (more: %v)", len(tab.Result.Rows), tab.MoreRows)
+	}
+}
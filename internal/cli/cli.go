@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/rebelice/lazypg/internal/app"
+)
+
+// Options are the command line options of lazypg
+type Options struct {
+	app.StartupOptions
+
+	ConfigPath  string // Config file to use instead of the default ones
+	Theme       string // Theme overriding the config's
+	ShowVersion bool
+}
+
+// Parse parses the command line arguments, without the program name. It
+// returns flag.ErrHelp when help was asked for.
+func Parse(args []string) (*Options, error) {
+	opts := &Options{}
+	fs := flag.NewFlagSet("lazypg", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	fs.StringVar(&opts.Database, "database", "", "")
+	fs.StringVar(&opts.Database, "d", "", "")
+	fs.StringVar(&opts.Schema, "schema", "", "")
+	fs.StringVar(&opts.Table, "table", "", "")
+	fs.StringVar(&opts.Query, "command", "", "")
+	fs.StringVar(&opts.Query, "c", "", "")
+	fs.BoolVar(&opts.ReadOnly, "read-only", false, "")
+	fs.StringVar(&opts.ConfigPath, "config", "", "")
+	fs.StringVar(&opts.Theme, "theme", "", "")
+	fs.BoolVar(&opts.ShowVersion, "version", false, "")
+
+	// Flags may come before or after the target
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) > 1 {
+		return nil, fmt.Errorf("expected at most one connection, got %d: %v", len(positional), positional)
+	}
+	if len(positional) == 1 {
+		opts.Target = positional[0]
+	}
+	return opts, nil
+}
+
+// Usage writes the command line help
+func Usage(w io.Writer) {
+	fmt.Fprint(w, `lazypg - a terminal UI for PostgreSQL
+
+Usage:
+  lazypg [flags] [connection]
+
+The connection is a postgres:// URI, a libpq keyword/value string or the
+name of a connection profile. Without one, lazypg connects with the PGHOST,
+PGPORT, PGDATABASE, PGUSER, PGPASSWORD and PGSSLMODE environment variables
+when they are set, and shows the connection dialog otherwise.
+
+Flags:
+  -d, --database NAME   Database to connect to instead of the connection's
+      --schema NAME     Schema to open, also put first on the search_path
+      --table NAME      Table or view to open, as table or schema.table
+  -c, --command SQL     Open the SQL editor with this query
+      --read-only       Default to read-only transactions
+      --config PATH     Config file to use
+      --theme NAME      Theme to use: default or catppuccin
+      --version         Print the version and exit
+  -h, --help            Show this help
+
+Examples:
+  lazypg postgres://app@localhost:5432/shop
+  lazypg "host=db.example.com user=app sslmode=require" --table orders
+  lazypg prod --read-only -c "SELECT now()"
+`)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	opts, err := Parse([]string{"-d", "shop", "prod", "--table", "sales.orders", "--read-only", "-c", "SELECT 1", "--theme=default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Target != "prod" || opts.Database != "shop" || opts.Table != "sales.orders" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	if !opts.ReadOnly || opts.Query != "SELECT 1" || opts.Theme != "default" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	opts, err = Parse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Target != "" || opts.ShowVersion {
+		t.Errorf("expected no options, got %+v", opts)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse([]string{"--help"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+	if _, err := Parse([]string{"prod", "dev"}); err == nil {
+		t.Errorf("expected two connections to be rejected")
+	}
+	if _, err := Parse([]string{"--tables", "x"}); err == nil {
+		t.Errorf("expected an unknown flag to be rejected")
+	}
+}
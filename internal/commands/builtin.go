@@ -16,6 +16,65 @@ type FavoritesCommandMsg struct{}
 type SettingsCommandMsg struct{}
 type ExportFavoritesCSVMsg struct{}
 type ExportFavoritesJSONMsg struct{}
+type ExportDataCommandMsg struct{}
+type ImportDataCommandMsg struct{}
+type BeginTransactionCommandMsg struct{}
+type CommitTransactionCommandMsg struct{}
+type RollbackTransactionCommandMsg struct{}
+type RunScriptCommandMsg struct{}
+type ToggleStopOnErrorCommandMsg struct{}
+type FormatSQLCommandMsg struct{}
+type ServerActivityCommandMsg struct{}
+type ERDiagramCommandMsg struct{}
+type SchemaDiffCommandMsg struct{}
+type ManageConnectionsCommandMsg struct{}
+
+// ExplainQueryCommandMsg requests the execution plan of the current statement
+type ExplainQueryCommandMsg struct {
+	Analyze bool
+}
+
+// SwitchConnectionCommandMsg switches to another open connection
+type SwitchConnectionCommandMsg struct {
+	ID string
+}
+
+// SwitchConnectionCommand returns the command that switches to an open
+// connection, described by its server and database
+func SwitchConnectionCommand(id, description string) models.Command {
+	return models.Command{
+		ID:          "switch-connection:" + id,
+		Type:        models.CommandTypeAction,
+		Label:       "Switch to " + id,
+		Description: description,
+		Icon:        "⇆",
+		Tags:        []string{"connection", "switch", "server", id},
+		Action: func() tea.Msg {
+			return SwitchConnectionCommandMsg{ID: id}
+		},
+	}
+}
+
+// ConnectProfileCommandMsg connects to a named connection profile
+type ConnectProfileCommandMsg struct {
+	Name string
+}
+
+// ConnectProfileCommand returns the command that connects to a profile,
+// described by its server and database
+func ConnectProfileCommand(name, description string, tags []string) models.Command {
+	return models.Command{
+		ID:          "connect-profile:" + name,
+		Type:        models.CommandTypeAction,
+		Label:       "Connect to " + name,
+		Description: description,
+		Icon:        "🔌",
+		Tags:        append([]string{"connection", "connect", "profile", name}, tags...),
+		Action: func() tea.Msg {
+			return ConnectProfileCommandMsg{Name: name}
+		},
+	}
+}
 
 // GetBuiltinCommands returns the list of built-in commands
 func GetBuiltinCommands() []models.Command {
@@ -31,6 +90,17 @@ func GetBuiltinCommands() []models.Command {
 				return ConnectCommandMsg{}
 			},
 		},
+		{
+			ID:          "manage-connections",
+			Type:        models.CommandTypeAction,
+			Label:       "Manage Connections",
+			Description: "Add, edit and connect to named connection profiles",
+			Icon:        "📇",
+			Tags:        []string{"connection", "profile", "manage", "saved"},
+			Action: func() tea.Msg {
+				return ManageConnectionsCommandMsg{}
+			},
+		},
 		{
 			ID:          "disconnect",
 			Type:        models.CommandTypeAction,
@@ -86,6 +156,39 @@ func GetBuiltinCommands() []models.Command {
 				return HistoryCommandMsg{}
 			},
 		},
+		{
+			ID:          "server-activity",
+			Type:        models.CommandTypeAction,
+			Label:       "Server Activity",
+			Description: "Monitor sessions, locks and blocking queries",
+			Icon:        "📡",
+			Tags:        []string{"activity", "sessions", "locks", "blocking", "monitor", "kill"},
+			Action: func() tea.Msg {
+				return ServerActivityCommandMsg{}
+			},
+		},
+		{
+			ID:          "er-diagram",
+			Type:        models.CommandTypeAction,
+			Label:       "ER Diagram",
+			Description: "Diagram the tables and foreign keys of a schema",
+			Icon:        "🗺",
+			Tags:        []string{"erd", "diagram", "relationships", "foreign", "keys", "schema", "mermaid", "graphviz", "dot"},
+			Action: func() tea.Msg {
+				return ERDiagramCommandMsg{}
+			},
+		},
+		{
+			ID:          "schema-diff",
+			Type:        models.CommandTypeAction,
+			Label:       "Schema Diff",
+			Description: "Compare two schemas and generate a migration script",
+			Icon:        "⇄",
+			Tags:        []string{"diff", "compare", "schema", "migration", "sync", "ddl"},
+			Action: func() tea.Msg {
+				return SchemaDiffCommandMsg{}
+			},
+		},
 		{
 			ID:          "favorites",
 			Type:        models.CommandTypeAction,
@@ -120,6 +223,116 @@ func GetBuiltinCommands() []models.Command {
 				return SettingsCommandMsg{}
 			},
 		},
+		{
+			ID:          "explain-query",
+			Type:        models.CommandTypeAction,
+			Label:       "Explain Query",
+			Description: "Show the estimated execution plan of the current statement",
+			Icon:        "🧭",
+			Tags:        []string{"explain", "plan", "query", "performance"},
+			Action: func() tea.Msg {
+				return ExplainQueryCommandMsg{}
+			},
+		},
+		{
+			ID:          "explain-analyze-query",
+			Type:        models.CommandTypeAction,
+			Label:       "Explain Analyze Query",
+			Description: "Run the current statement and show the actual execution plan (changes are rolled back)",
+			Icon:        "⏱",
+			Tags:        []string{"explain", "analyze", "plan", "query", "performance", "buffers"},
+			Action: func() tea.Msg {
+				return ExplainQueryCommandMsg{Analyze: true}
+			},
+		},
+		{
+			ID:          "run-script",
+			Type:        models.CommandTypeAction,
+			Label:       "Run Script",
+			Description: "Run all statements in the SQL editor one after another (F5)",
+			Icon:        "▶",
+			Tags:        []string{"script", "run", "all", "execute", "statements", "sql"},
+			Action: func() tea.Msg {
+				return RunScriptCommandMsg{}
+			},
+		},
+		{
+			ID:          "format-sql",
+			Type:        models.CommandTypeAction,
+			Label:       "Format SQL",
+			Description: "Pretty-print the SQL editor or the object definition being edited",
+			Icon:        "✨",
+			Tags:        []string{"format", "pretty", "indent", "beautify", "sql"},
+			Action: func() tea.Msg {
+				return FormatSQLCommandMsg{}
+			},
+		},
+		{
+			ID:          "toggle-stop-on-error",
+			Type:        models.CommandTypeAction,
+			Label:       "Toggle Stop on Error",
+			Description: "Switch scripts between stopping at the first error and continuing",
+			Icon:        "⏯",
+			Tags:        []string{"script", "error", "continue", "stop", "sql"},
+			Action: func() tea.Msg {
+				return ToggleStopOnErrorCommandMsg{}
+			},
+		},
+		{
+			ID:          "begin-transaction",
+			Type:        models.CommandTypeAction,
+			Label:       "Begin Transaction",
+			Description: "Run the following SQL editor statements in one transaction",
+			Icon:        "🔒",
+			Tags:        []string{"transaction", "begin", "start", "sql"},
+			Action: func() tea.Msg {
+				return BeginTransactionCommandMsg{}
+			},
+		},
+		{
+			ID:          "commit-transaction",
+			Type:        models.CommandTypeAction,
+			Label:       "Commit Transaction",
+			Description: "Commit the open transaction (Alt+C)",
+			Icon:        "✅",
+			Tags:        []string{"transaction", "commit", "sql"},
+			Action: func() tea.Msg {
+				return CommitTransactionCommandMsg{}
+			},
+		},
+		{
+			ID:          "rollback-transaction",
+			Type:        models.CommandTypeAction,
+			Label:       "Rollback Transaction",
+			Description: "Roll back the open transaction (Alt+R)",
+			Icon:        "↩",
+			Tags:        []string{"transaction", "rollback", "abort", "sql"},
+			Action: func() tea.Msg {
+				return RollbackTransactionCommandMsg{}
+			},
+		},
+		{
+			ID:          "export-data",
+			Type:        models.CommandTypeAction,
+			Label:       "Export Results",
+			Description: "Export query results or table data to CSV, JSON, NDJSON, Markdown or SQL",
+			Icon:        "💾",
+			Tags:        []string{"export", "csv", "json", "ndjson", "markdown", "sql", "insert", "results", "table"},
+			Action: func() tea.Msg {
+				return ExportDataCommandMsg{}
+			},
+		},
+		{
+			ID:          "import-data",
+			Type:        models.CommandTypeAction,
+			Label:       "Import into Table",
+			Description: "Load a CSV, JSON or NDJSON file into the selected table",
+			Icon:        "📥",
+			Tags:        []string{"import", "load", "copy", "csv", "json", "ndjson", "table"},
+			Action: func() tea.Msg {
+				return ImportDataCommandMsg{}
+			},
+		},
 		{
 			ID:          "export-favorites-csv",
 			Type:        models.CommandTypeAction,
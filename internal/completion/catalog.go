@@ -0,0 +1,101 @@
+package completion
+
+// Relation is a table or view that can be completed
+type Relation struct {
+	Schema string
+	Name   string
+	View   bool
+}
+
+// Key identifies a relation in the catalog
+func (r Relation) Key() string {
+	return r.Schema + "." + r.Name
+}
+
+// Column is a column of a relation
+type Column struct {
+	Name string
+	Type string
+}
+
+// Function is a function that can be completed, with its argument list
+type Function struct {
+	Schema    string
+	Name      string
+	Arguments string // e.g. "a integer, b text"
+}
+
+// Catalog is the database metadata completion draws from. Columns are
+// loaded per relation when a statement first refers to it.
+type Catalog struct {
+	Schemas    []string
+	Relations  []Relation
+	Functions  []Function
+	SearchPath []string // Schemas whose objects are used unqualified
+
+	columns map[string][]Column
+}
+
+// SetColumns stores the columns of a relation
+func (c *Catalog) SetColumns(rel Relation, columns []Column) {
+	if c.columns == nil {
+		c.columns = make(map[string][]Column)
+	}
+	c.columns[rel.Key()] = columns
+}
+
+// Columns returns the columns of a relation, and false if they have not
+// been loaded
+func (c *Catalog) Columns(rel Relation) ([]Column, bool) {
+	columns, ok := c.columns[rel.Key()]
+	return columns, ok
+}
+
+// searchPath returns the schemas searched for unqualified names
+func (c *Catalog) searchPath() []string {
+	if len(c.SearchPath) == 0 {
+		return []string{"public"}
+	}
+	return c.SearchPath
+}
+
+// inSearchPath reports whether a schema is searched for unqualified names
+func (c *Catalog) inSearchPath(schema string) bool {
+	for _, s := range c.searchPath() {
+		if s == schema {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSchema reports whether a schema exists
+func (c *Catalog) hasSchema(name string) bool {
+	for _, s := range c.Schemas {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findRelation resolves a possibly unqualified relation name the way
+// PostgreSQL does, looking through the search path
+func (c *Catalog) findRelation(schema, name string) (Relation, bool) {
+	if schema != "" {
+		for _, rel := range c.Relations {
+			if rel.Schema == schema && rel.Name == name {
+				return rel, true
+			}
+		}
+		return Relation{}, false
+	}
+	for _, s := range c.searchPath() {
+		for _, rel := range c.Relations {
+			if rel.Schema == s && rel.Name == name {
+				return rel, true
+			}
+		}
+	}
+	return Relation{}, false
+}
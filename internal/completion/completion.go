@@ -0,0 +1,491 @@
+// Package completion suggests keywords, schemas, tables, columns and
+// functions at the cursor of a SQL statement.
+package completion
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of object an item completes
+type Kind int
+
+const (
+	KindKeyword Kind = iota
+	KindSchema
+	KindTable
+	KindView
+	KindColumn
+	KindFunction
+)
+
+// String returns the kind as shown in the completion popup
+func (k Kind) String() string {
+	switch k {
+	case KindSchema:
+		return "schema"
+	case KindTable:
+		return "table"
+	case KindView:
+		return "view"
+	case KindColumn:
+		return "column"
+	case KindFunction:
+		return "function"
+	}
+	return "keyword"
+}
+
+// Item is a single completion suggestion
+type Item struct {
+	Label  string // Name shown in the popup
+	Kind   Kind
+	Detail string // Column type, function arguments or schema
+	Insert string // Text that replaces the prefix
+}
+
+// Result holds the suggestions for a cursor position
+type Result struct {
+	Prefix string // Text before the cursor that the items complete
+	Start  int    // Byte offset where the prefix starts
+	Items  []Item
+
+	// Missing lists relations the statement uses whose columns are not in
+	// the catalog yet. Completing again once they are loaded adds them.
+	Missing []Relation
+}
+
+// maxItems caps the number of suggestions
+const maxItems = 100
+
+// context is what is expected at the cursor
+type context int
+
+const (
+	contextKeyword  context = iota // After a complete expression or at the start
+	contextRelation                // After FROM, JOIN, UPDATE, INTO ...
+	contextColumn                  // In an expression
+)
+
+// tableRef is a table or view the statement reads or writes
+type tableRef struct {
+	schema string
+	name   string
+	alias  string
+}
+
+// Complete returns the suggestions for the cursor at byte offset in sql
+func Complete(cat *Catalog, sql string, offset int) Result {
+	if cat == nil {
+		cat = &Catalog{}
+	}
+	if offset > len(sql) {
+		offset = len(sql)
+	}
+	result := Result{Start: offset}
+
+	// Find the token the cursor is in and the statement around it
+	all := tokenize(sql)
+	var tokens []token
+	prefixAt := -1
+	for _, t := range all {
+		inside := t.start < offset && (offset < t.end || offset == t.end && !t.closed)
+		switch t.kind {
+		case tokComment, tokString:
+			if inside {
+				return result
+			}
+			continue
+		case tokNumber:
+			if inside || t.end == offset {
+				return result
+			}
+		case tokWord:
+			if t.start < offset && offset <= t.end {
+				prefixAt = len(tokens)
+			}
+		case tokQuoted:
+			if inside {
+				prefixAt = len(tokens)
+			}
+		}
+		tokens = append(tokens, t)
+	}
+	start, end := 0, len(tokens)
+	for i, t := range tokens {
+		if !t.is(";") {
+			continue
+		}
+		if t.end <= offset {
+			start = i + 1
+		} else if i < end {
+			end = i
+			break
+		}
+	}
+	tokens = tokens[start:end]
+	prefixAt -= start
+
+	var before, rest []token
+	if prefixAt >= 0 && prefixAt < len(tokens) {
+		prefix := tokens[prefixAt]
+		result.Start = prefix.start
+		result.Prefix = sql[prefix.start:offset]
+		before = tokens[:prefixAt]
+		rest = append(append(rest, before...), tokens[prefixAt+1:]...)
+	} else {
+		for i, t := range tokens {
+			if t.start >= offset {
+				before = tokens[:i]
+				break
+			}
+			before = tokens[:i+1]
+		}
+		rest = tokens
+	}
+
+	// A name followed by a dot qualifies the prefix
+	var qualifier []string
+	for len(before) >= 2 && before[len(before)-1].is(".") && before[len(before)-2].isName() &&
+		len(qualifier) < 2 {
+		qualifier = append([]string{before[len(before)-2].name()}, qualifier...)
+		before = before[:len(before)-2]
+	}
+
+	refs := parseRefs(rest)
+	ctx := expectedContext(before)
+
+	var items []Item
+	switch {
+	case len(qualifier) == 2:
+		if rel, ok := cat.findRelation(qualifier[0], qualifier[1]); ok {
+			items = append(items, columnItems(cat, rel, &result)...)
+		}
+	case len(qualifier) == 1:
+		q := qualifier[0]
+		if rel, ok := resolveRef(cat, refs, q); ok {
+			items = append(items, columnItems(cat, rel, &result)...)
+		}
+		if cat.hasSchema(q) {
+			items = append(items, relationItems(cat, q)...)
+			if ctx == contextColumn {
+				items = append(items, functionItems(cat, q)...)
+			}
+		}
+	case ctx == contextRelation:
+		items = append(items, relationItems(cat, "")...)
+		items = append(items, schemaItems(cat)...)
+	case ctx == contextColumn:
+		for _, ref := range refs {
+			if rel, ok := cat.findRelation(ref.schema, ref.name); ok {
+				items = append(items, columnItems(cat, rel, &result)...)
+			}
+		}
+		items = append(items, functionItems(cat, "")...)
+		items = append(items, aliasItems(refs)...)
+		items = append(items, keywordItems(result.Prefix)...)
+	default:
+		items = append(items, keywordItems(result.Prefix)...)
+	}
+
+	result.Items = filter(items, result.Prefix)
+	return result
+}
+
+// expectedContext decides what fits after the tokens before the cursor
+func expectedContext(before []token) context {
+	if len(before) == 0 {
+		return contextKeyword
+	}
+	last := before[len(before)-1]
+	switch {
+	case last.kind == tokWord && relationWords[last.upper()]:
+		return contextRelation
+	case last.kind == tokWord && columnWords[last.upper()]:
+		return contextColumn
+	case last.is(","):
+		return listContext(before[:len(before)-1])
+	case last.is("*") && (len(before) == 1 || before[len(before)-2].kind == tokWord || before[len(before)-2].is(",")):
+		// SELECT * or t.* rather than a multiplication
+		return contextKeyword
+	case last.kind == tokPunct && !last.is(")"):
+		return contextColumn
+	}
+	return contextKeyword
+}
+
+// listContext decides what a comma separated list holds by looking back for
+// the keyword that started it
+func listContext(before []token) context {
+	depth := 0
+	for i := len(before) - 1; i >= 0; i-- {
+		t := before[i]
+		switch {
+		case t.is(")"):
+			depth++
+		case t.is("("):
+			if depth == 0 {
+				return contextColumn
+			}
+			depth--
+		case depth == 0 && t.kind == tokWord && relationWords[t.upper()]:
+			return contextRelation
+		case depth == 0 && t.kind == tokWord && (columnWords[t.upper()] || clauseWords[t.upper()]):
+			return contextColumn
+		}
+	}
+	return contextColumn
+}
+
+// parseRefs collects the tables a statement refers to, with their aliases
+func parseRefs(tokens []token) []tableRef {
+	var refs []tableRef
+	for i := 0; i < len(tokens); i++ {
+		word := tokens[i].upper()
+		if word != "FROM" && word != "JOIN" && word != "UPDATE" && word != "INTO" {
+			continue
+		}
+		j := i + 1
+		for {
+			for j < len(tokens) && (tokens[j].is("ONLY") || tokens[j].is("LATERAL")) {
+				j++
+			}
+			if j >= len(tokens) {
+				break
+			}
+			var ref tableRef
+			if tokens[j].is("(") {
+				// Subquery or function call: skip it, the alias has no known columns
+				j = skipParens(tokens, j)
+			} else if tokens[j].isName() && !clauseWords[tokens[j].upper()] {
+				ref.name = tokens[j].name()
+				j++
+				if j+1 < len(tokens) && tokens[j].is(".") && tokens[j+1].isName() {
+					ref.schema, ref.name = ref.name, tokens[j+1].name()
+					j += 2
+				}
+			} else {
+				break
+			}
+			if j < len(tokens) && tokens[j].is("AS") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isName() && !clauseWords[tokens[j].upper()] {
+				ref.alias = tokens[j].name()
+				j++
+			}
+			if ref.name != "" {
+				refs = append(refs, ref)
+			}
+			if word != "FROM" || j >= len(tokens) || !tokens[j].is(",") {
+				break
+			}
+			j++
+		}
+		i = j - 1
+	}
+	return refs
+}
+
+// skipParens returns the index just past the parenthesis opened at i
+func skipParens(tokens []token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].is("(") {
+			depth++
+		} else if tokens[i].is(")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// resolveRef finds the relation a qualifier stands for: an alias, a table
+// the statement uses, or any table on the search path
+func resolveRef(cat *Catalog, refs []tableRef, qualifier string) (Relation, bool) {
+	for _, ref := range refs {
+		if ref.alias == qualifier {
+			return cat.findRelation(ref.schema, ref.name)
+		}
+	}
+	for _, ref := range refs {
+		if ref.alias == "" && ref.name == qualifier {
+			return cat.findRelation(ref.schema, ref.name)
+		}
+	}
+	return cat.findRelation("", qualifier)
+}
+
+// columnItems returns the columns of a relation, noting it as missing when
+// they have not been loaded
+func columnItems(cat *Catalog, rel Relation, result *Result) []Item {
+	columns, ok := cat.Columns(rel)
+	if !ok {
+		for _, missing := range result.Missing {
+			if missing == rel {
+				return nil
+			}
+		}
+		result.Missing = append(result.Missing, rel)
+		return nil
+	}
+	items := make([]Item, 0, len(columns))
+	for _, col := range columns {
+		items = append(items, Item{Label: col.Name, Kind: KindColumn, Detail: col.Type, Insert: quoteIdent(col.Name)})
+	}
+	return items
+}
+
+// relationItems returns the tables and views of a schema, or of the search
+// path when schema is empty
+func relationItems(cat *Catalog, schema string) []Item {
+	var items []Item
+	for _, rel := range cat.Relations {
+		if schema == "" && !cat.inSearchPath(rel.Schema) || schema != "" && rel.Schema != schema {
+			continue
+		}
+		kind := KindTable
+		if rel.View {
+			kind = KindView
+		}
+		items = append(items, Item{Label: rel.Name, Kind: kind, Detail: rel.Schema, Insert: quoteIdent(rel.Name)})
+	}
+	return items
+}
+
+// schemaItems returns all schemas
+func schemaItems(cat *Catalog) []Item {
+	items := make([]Item, 0, len(cat.Schemas))
+	for _, s := range cat.Schemas {
+		items = append(items, Item{Label: s, Kind: KindSchema, Insert: quoteIdent(s)})
+	}
+	return items
+}
+
+// functionItems returns the functions of a schema, or of the search path and
+// the common built-in functions when schema is empty
+func functionItems(cat *Catalog, schema string) []Item {
+	var items []Item
+	add := func(fn Function) {
+		items = append(items, Item{
+			Label:  fn.Name,
+			Kind:   KindFunction,
+			Detail: "(" + fn.Arguments + ")",
+			Insert: quoteIdent(fn.Name) + "(",
+		})
+	}
+	for _, fn := range cat.Functions {
+		if schema == "" && cat.inSearchPath(fn.Schema) || schema != "" && fn.Schema == schema {
+			add(fn)
+		}
+	}
+	if schema == "" || schema == "pg_catalog" {
+		for _, fn := range builtinFunctions {
+			add(fn)
+		}
+	}
+	return items
+}
+
+// aliasItems returns the aliases and tables of the statement, for
+// qualifying columns
+func aliasItems(refs []tableRef) []Item {
+	var items []Item
+	for _, ref := range refs {
+		name := ref.alias
+		if name == "" {
+			name = ref.name
+		}
+		items = append(items, Item{Label: name, Kind: KindTable, Detail: ref.name, Insert: quoteIdent(name)})
+	}
+	return items
+}
+
+// keywordItems returns the keywords, in lower case when the prefix is
+func keywordItems(prefix string) []Item {
+	lower := prefix != "" && prefix == strings.ToLower(prefix)
+	items := make([]Item, 0, len(keywords))
+	for _, kw := range keywords {
+		text := kw
+		if lower {
+			text = strings.ToLower(kw)
+		}
+		items = append(items, Item{Label: text, Kind: KindKeyword, Insert: text})
+	}
+	return items
+}
+
+// filter keeps the items matching the prefix, grouped in the order they
+// were collected and sorted by name within each kind
+func filter(items []Item, prefix string) []Item {
+	match := strings.ToLower(strings.TrimPrefix(prefix, `"`))
+	var kept []Item
+	seen := make(map[Item]bool)
+	for _, item := range items {
+		if seen[item] || !strings.HasPrefix(strings.ToLower(item.Label), match) {
+			continue
+		}
+		seen[item] = true
+		kept = append(kept, item)
+	}
+
+	order := make(map[Kind]int)
+	for _, item := range kept {
+		if _, ok := order[item.Kind]; !ok {
+			order[item.Kind] = len(order)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Kind != kept[j].Kind {
+			return order[kept[i].Kind] < order[kept[j].Kind]
+		}
+		return strings.ToLower(kept[i].Label) < strings.ToLower(kept[j].Label)
+	})
+
+	// Nothing to offer when the only match is what was typed
+	if len(kept) == 1 && kept[0].Insert == prefix {
+		return nil
+	}
+	if len(kept) > maxItems {
+		kept = kept[:maxItems]
+	}
+	return kept
+}
+
+// quoteIdent quotes an identifier when it would not survive unquoted
+func quoteIdent(name string) string {
+	plain := name != "" && !reservedWords[strings.ToUpper(name)]
+	for i := 0; i < len(name) && plain; i++ {
+		c := name[i]
+		plain = c == '_' || c >= 'a' && c <= 'z' || i > 0 && (c >= '0' && c <= '9' || c == '$')
+	}
+	if plain {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// reservedWords are the keywords PostgreSQL does not accept as plain
+// identifiers
+var reservedWords = map[string]bool{
+	"ALL": true, "ANALYSE": true, "ANALYZE": true, "AND": true, "ANY": true,
+	"ARRAY": true, "AS": true, "ASC": true, "ASYMMETRIC": true, "BOTH": true,
+	"CASE": true, "CAST": true, "CHECK": true, "COLLATE": true, "COLUMN": true,
+	"CONSTRAINT": true, "CREATE": true, "CURRENT_CATALOG": true, "CURRENT_DATE": true,
+	"CURRENT_ROLE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+	"CURRENT_USER": true, "DEFAULT": true, "DEFERRABLE": true, "DESC": true,
+	"DISTINCT": true, "DO": true, "ELSE": true, "END": true, "EXCEPT": true,
+	"FALSE": true, "FETCH": true, "FOR": true, "FOREIGN": true, "FROM": true,
+	"GRANT": true, "GROUP": true, "HAVING": true, "IN": true, "INITIALLY": true,
+	"INTERSECT": true, "INTO": true, "LATERAL": true, "LEADING": true, "LIMIT": true,
+	"LOCALTIME": true, "LOCALTIMESTAMP": true, "NOT": true, "NULL": true,
+	"OFFSET": true, "ON": true, "ONLY": true, "OR": true, "ORDER": true,
+	"PLACING": true, "PRIMARY": true, "REFERENCES": true, "RETURNING": true,
+	"SELECT": true, "SESSION_USER": true, "SOME": true, "SYMMETRIC": true,
+	"TABLE": true, "THEN": true, "TO": true, "TRAILING": true, "TRUE": true,
+	"UNION": true, "UNIQUE": true, "USER": true, "USING": true, "VARIADIC": true,
+	"WHEN": true, "WHERE": true, "WINDOW": true, "WITH": true,
+}
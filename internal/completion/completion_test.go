@@ -0,0 +1,117 @@
+package completion
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	cat := &Catalog{
+		Schemas: []string{"public", "sales"},
+		Relations: []Relation{
+			{Schema: "public", Name: "users"},
+			{Schema: "public", Name: "user_stats", View: true},
+			{Schema: "sales", Name: "orders"},
+			{Schema: "sales", Name: "Order Items"},
+		},
+		Functions: []Function{
+			{Schema: "public", Name: "user_score", Arguments: "id integer"},
+			{Schema: "sales", Name: "order_total", Arguments: "id bigint"},
+		},
+	}
+	cat.SetColumns(Relation{Schema: "public", Name: "users"}, []Column{
+		{Name: "id", Type: "integer"},
+		{Name: "email", Type: "text"},
+		{Name: "user", Type: "text"},
+	})
+	cat.SetColumns(Relation{Schema: "sales", Name: "orders"}, []Column{
+		{Name: "id", Type: "bigint"},
+		{Name: "user_id", Type: "integer"},
+	})
+	return cat
+}
+
+// complete runs Complete with the cursor at the | in sql
+func complete(cat *Catalog, sql string) Result {
+	offset := strings.Index(sql, "|")
+	return Complete(cat, sql[:offset]+sql[offset+1:], offset)
+}
+
+// labels returns "kind:label" for each item
+func labels(items []Item) []string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Kind.String()+":"+item.Label)
+	}
+	return out
+}
+
+func TestComplete(t *testing.T) {
+	cat := testCatalog()
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"relations", "SELECT * FROM us|", []string{"table:users", "view:user_stats"}},
+		{"schema relations", "SELECT * FROM sales.o|", []string{"table:Order Items", "table:orders"}},
+		{"alias columns", "SELECT u.| FROM users u", []string{"column:email", "column:id", "column:user"}},
+		{"join alias", "SELECT * FROM users u JOIN sales.orders AS o ON o.u| = u.id", []string{"column:user_id"}},
+		{"table columns", "SELECT users.e| FROM users", []string{"column:email"}},
+		{"where", "SELECT * FROM sales.orders WHERE us|", []string{"column:user_id", "function:user_score", "keyword:using"}},
+		{"keyword lower", "sel|", []string{"keyword:select"}},
+		{"keyword upper", "SEL|", []string{"keyword:SELECT"}},
+		{"after table", "SELECT * FROM users WH|", []string{"keyword:WHEN", "keyword:WHERE"}},
+		{"from list", "SELECT * FROM users, sal|", []string{"schema:sales"}},
+		{"select list", "SELECT id, em| FROM users", []string{"column:email"}},
+		{"update set", "UPDATE users SET em|", []string{"column:email"}},
+		{"insert columns", "INSERT INTO users (id, em|", []string{"column:email"}},
+		{"schema functions", "SELECT sales.order| FROM users", []string{"table:Order Items", "table:orders", "function:order_total"}},
+		{"other statement", "SELECT 1 FROM sales.orders; SELECT em| FROM users", []string{"column:email"}},
+		{"string", "SELECT 'us|' FROM users", nil},
+		{"comment", "SELECT 1 -- us|", nil},
+		{"exact match", "SELECT * FROM users|", nil},
+	}
+	for _, tt := range tests {
+		if got := labels(complete(cat, tt.sql).Items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: items = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteInsert(t *testing.T) {
+	cat := testCatalog()
+
+	res := complete(cat, "SELECT * FROM sales.Ord|")
+	if res.Prefix != "Ord" || res.Start != len("SELECT * FROM sales.") {
+		t.Fatalf("prefix = %q at %d", res.Prefix, res.Start)
+	}
+	var inserts []string
+	for _, item := range res.Items {
+		inserts = append(inserts, item.Insert)
+	}
+	if want := []string{`"Order Items"`, "orders"}; !reflect.DeepEqual(inserts, want) {
+		t.Errorf("inserts = %v, want %v", inserts, want)
+	}
+
+	res = complete(cat, "SELECT u.us| FROM users u")
+	if len(res.Items) != 1 || res.Items[0].Insert != `"user"` || res.Items[0].Detail != "text" {
+		t.Errorf("items = %+v, want reserved column quoted", res.Items)
+	}
+}
+
+func TestCompleteMissingColumns(t *testing.T) {
+	cat := testCatalog()
+	res := complete(cat, "SELECT | FROM user_stats s JOIN users u ON true")
+	want := []Relation{{Schema: "public", Name: "user_stats", View: true}}
+	if !reflect.DeepEqual(res.Missing, want) {
+		t.Fatalf("missing = %v, want %v", res.Missing, want)
+	}
+
+	cat.SetColumns(want[0], []Column{{Name: "score", Type: "numeric"}})
+	res = complete(cat, "SELECT sco| FROM user_stats s JOIN users u ON true")
+	if len(res.Missing) != 0 || len(res.Items) != 1 || res.Items[0].Label != "score" {
+		t.Errorf("after loading: items = %v, missing = %v", labels(res.Items), res.Missing)
+	}
+}
@@ -0,0 +1,76 @@
+package completion
+
+// keywords are offered where a keyword can follow
+var keywords = []string{
+	"ALL", "ALTER", "AND", "ANY", "ARRAY", "AS", "ASC", "BEGIN", "BETWEEN", "BY",
+	"CASCADE", "CASE", "CAST", "COLUMN", "COMMIT", "CONSTRAINT", "CREATE", "CROSS",
+	"DEFAULT", "DELETE", "DESC", "DISTINCT", "DO", "DROP", "ELSE", "END", "EXCEPT",
+	"EXISTS", "EXPLAIN", "FALSE", "FETCH", "FILTER", "FIRST", "FOR", "FOREIGN", "FROM",
+	"FULL", "GRANT", "GROUP", "HAVING", "ILIKE", "IN", "INDEX", "INNER", "INSERT",
+	"INTERSECT", "INTO", "IS", "JOIN", "KEY", "LAST", "LATERAL", "LEFT", "LIKE",
+	"LIMIT", "NATURAL", "NOT", "NOTHING", "NULL", "NULLS", "OFFSET", "ON", "ONLY",
+	"OR", "ORDER", "OUTER", "OVER", "PARTITION", "PRIMARY", "REFERENCES", "RETURNING",
+	"RIGHT", "ROLLBACK", "SCHEMA", "SELECT", "SET", "SHOW", "TABLE", "THEN", "TO",
+	"TRUE", "TRUNCATE", "UNION", "UNIQUE", "UPDATE", "USING", "VALUES", "VIEW",
+	"WHEN", "WHERE", "WINDOW", "WITH",
+}
+
+// relationWords are keywords followed by a table or view name
+var relationWords = map[string]bool{
+	"FROM": true, "JOIN": true, "UPDATE": true, "INTO": true, "TABLE": true,
+	"TRUNCATE": true, "ONLY": true,
+}
+
+// columnWords are keywords followed by an expression, where columns and
+// functions fit
+var columnWords = map[string]bool{
+	"SELECT": true, "WHERE": true, "AND": true, "OR": true, "ON": true, "BY": true,
+	"SET": true, "HAVING": true, "RETURNING": true, "DISTINCT": true, "NOT": true,
+	"WHEN": true, "THEN": true, "ELSE": true, "CASE": true, "LIKE": true,
+	"ILIKE": true, "BETWEEN": true, "IS": true, "USING": true,
+}
+
+// clauseWords end a table reference, so they are never taken as an alias
+var clauseWords = map[string]bool{
+	"WHERE": true, "JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true,
+	"OUTER": true, "FULL": true, "CROSS": true, "NATURAL": true, "ON": true,
+	"USING": true, "GROUP": true, "ORDER": true, "LIMIT": true, "OFFSET": true,
+	"HAVING": true, "SET": true, "UNION": true, "EXCEPT": true, "INTERSECT": true,
+	"WINDOW": true, "RETURNING": true, "VALUES": true, "SELECT": true, "FOR": true,
+	"FETCH": true, "DEFAULT": true, "LATERAL": true, "TABLESAMPLE": true,
+	"AS": true, "FROM": true,
+}
+
+// builtinFunctions are common PostgreSQL functions offered in expressions
+var builtinFunctions = []Function{
+	{Schema: "pg_catalog", Name: "abs", Arguments: "x"},
+	{Schema: "pg_catalog", Name: "array_agg", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "avg", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "coalesce", Arguments: "value, ..."},
+	{Schema: "pg_catalog", Name: "concat", Arguments: "value, ..."},
+	{Schema: "pg_catalog", Name: "count", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "date_trunc", Arguments: "field text, source timestamp"},
+	{Schema: "pg_catalog", Name: "extract", Arguments: "field FROM source"},
+	{Schema: "pg_catalog", Name: "generate_series", Arguments: "start, stop [, step]"},
+	{Schema: "pg_catalog", Name: "greatest", Arguments: "value, ..."},
+	{Schema: "pg_catalog", Name: "json_agg", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "jsonb_build_object", Arguments: "key, value, ..."},
+	{Schema: "pg_catalog", Name: "lag", Arguments: "value [, offset [, default]]"},
+	{Schema: "pg_catalog", Name: "lead", Arguments: "value [, offset [, default]]"},
+	{Schema: "pg_catalog", Name: "least", Arguments: "value, ..."},
+	{Schema: "pg_catalog", Name: "length", Arguments: "text"},
+	{Schema: "pg_catalog", Name: "lower", Arguments: "text"},
+	{Schema: "pg_catalog", Name: "max", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "min", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "now", Arguments: ""},
+	{Schema: "pg_catalog", Name: "nullif", Arguments: "value1, value2"},
+	{Schema: "pg_catalog", Name: "rank", Arguments: ""},
+	{Schema: "pg_catalog", Name: "round", Arguments: "numeric [, scale integer]"},
+	{Schema: "pg_catalog", Name: "row_number", Arguments: ""},
+	{Schema: "pg_catalog", Name: "string_agg", Arguments: "value text, delimiter text"},
+	{Schema: "pg_catalog", Name: "substring", Arguments: "text [FROM start] [FOR count]"},
+	{Schema: "pg_catalog", Name: "sum", Arguments: "expression"},
+	{Schema: "pg_catalog", Name: "to_char", Arguments: "value, format text"},
+	{Schema: "pg_catalog", Name: "trim", Arguments: "text"},
+	{Schema: "pg_catalog", Name: "upper", Arguments: "text"},
+}
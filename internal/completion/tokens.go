@@ -0,0 +1,166 @@
+package completion
+
+import "strings"
+
+// tokenKind is the lexical class of a token
+type tokenKind int
+
+const (
+	tokWord    tokenKind = iota // Unquoted identifier or keyword
+	tokQuoted                   // "Quoted identifier"
+	tokString                   // String literal or dollar-quoted body
+	tokComment                  // -- or /* */ comment
+	tokNumber                   // Numeric literal
+	tokPunct                    // Operator or punctuation character
+)
+
+// token is a lexical token of a SQL text
+type token struct {
+	kind   tokenKind
+	text   string // Identifier without quotes, or the punctuation character
+	start  int    // Byte offset of the first character
+	end    int    // Byte offset just past the token
+	closed bool   // Literals, quoted identifiers and comments: the end was found
+}
+
+// tokenize splits SQL into tokens. Line comments are never closed, so a
+// cursor at the end of one is still inside it.
+func tokenize(sql string) []token {
+	var tokens []token
+	i := 0
+	for i < len(sql) {
+		ch := sql[i]
+		start := i
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v':
+			i++
+			continue
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokComment, start: start, end: i})
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				tokens = append(tokens, token{kind: tokComment, start: start, end: len(sql)})
+				i = len(sql)
+			} else {
+				i += end + 4
+				tokens = append(tokens, token{kind: tokComment, start: start, end: i, closed: true})
+			}
+		case ch == '\'':
+			end, closed := skipQuoted(sql, i, '\'')
+			i = end
+			tokens = append(tokens, token{kind: tokString, start: start, end: i, closed: closed})
+		case ch == '"':
+			end, closed := skipQuoted(sql, i, '"')
+			i = end
+			text := sql[start+1 : i]
+			if closed {
+				text = text[:len(text)-1]
+			}
+			text = strings.ReplaceAll(text, `""`, `"`)
+			tokens = append(tokens, token{kind: tokQuoted, text: text, start: start, end: i, closed: closed})
+		case ch == '$' && dollarTag(sql, i) != "":
+			tag := dollarTag(sql, i)
+			end := strings.Index(sql[i+len(tag):], tag)
+			closed := end >= 0
+			if closed {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(sql)
+			}
+			tokens = append(tokens, token{kind: tokString, start: start, end: i, closed: closed})
+		case isWordStart(ch):
+			for i < len(sql) && isWordByte(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokWord, text: sql[start:i], start: start, end: i})
+		case ch >= '0' && ch <= '9':
+			for i < len(sql) && (sql[i] >= '0' && sql[i] <= '9' || sql[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: sql[start:i], start: start, end: i})
+		default:
+			i++
+			tokens = append(tokens, token{kind: tokPunct, text: string(ch), start: start, end: i})
+		}
+	}
+	return tokens
+}
+
+// skipQuoted returns the offset just past the literal or quoted identifier
+// starting at i, and whether its closing quote was found
+func skipQuoted(s string, i int, quote byte) (int, bool) {
+	for i++; i < len(s); i++ {
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string at i, or ""
+func dollarTag(s string, i int) string {
+	if i > 0 && isWordByte(s[i-1]) {
+		return ""
+	}
+	j := i + 1
+	if j < len(s) && isWordStart(s[j]) {
+		for j < len(s) && s[j] != '$' && isWordByte(s[j]) {
+			j++
+		}
+	}
+	if j < len(s) && s[j] == '$' {
+		return s[i : j+1]
+	}
+	return ""
+}
+
+// isWordStart reports whether c can start an unquoted identifier
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// isWordByte reports whether c can be part of an unquoted identifier
+func isWordByte(c byte) bool {
+	return isWordStart(c) || c >= '0' && c <= '9' || c == '$'
+}
+
+// isName reports whether a token names something: a word or quoted identifier
+func (t token) isName() bool {
+	return t.kind == tokWord || t.kind == tokQuoted
+}
+
+// is reports whether the token is the given keyword or punctuation
+func (t token) is(text string) bool {
+	switch t.kind {
+	case tokWord:
+		return strings.EqualFold(t.text, text)
+	case tokPunct:
+		return t.text == text
+	}
+	return false
+}
+
+// name returns the identifier a token stands for: unquoted names fold to
+// lower case like PostgreSQL does
+func (t token) name() string {
+	if t.kind == tokWord {
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+// upper returns a word in upper case, or "" for other tokens
+func (t token) upper() string {
+	if t.kind != tokWord {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
@@ -5,17 +5,19 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/rebelice/lazypg/internal/models"
 	"github.com/spf13/viper"
 )
 
 // Config holds all application configuration
 type Config struct {
-	General     GeneralConfig     `mapstructure:"general"`
-	UI          UIConfig          `mapstructure:"ui"`
-	Editor      EditorConfig      `mapstructure:"editor"`
-	Data        DataConfig        `mapstructure:"data"`
-	History     HistoryConfig     `mapstructure:"history"`
-	Performance PerformanceConfig `mapstructure:"performance"`
+	General     GeneralConfig              `mapstructure:"general"`
+	UI          UIConfig                   `mapstructure:"ui"`
+	Editor      EditorConfig               `mapstructure:"editor"`
+	Data        DataConfig                 `mapstructure:"data"`
+	History     HistoryConfig              `mapstructure:"history"`
+	Performance PerformanceConfig          `mapstructure:"performance"`
+	Connections []models.ConnectionProfile `mapstructure:"connections"` // Named connection profiles
 }
 
 type GeneralConfig struct {
@@ -37,13 +39,16 @@ type EditorConfig struct {
 	UseSpaces    bool `mapstructure:"use_spaces"`
 	AutoComplete bool `mapstructure:"auto_complete"`
 	FormatOnSave bool `mapstructure:"format_on_save"`
+	StopOnError  bool `mapstructure:"stop_on_error"` // Stop a script at its first failing statement
 }
 
 type DataConfig struct {
-	VirtualScrollBuffer  int  `mapstructure:"virtual_scroll_buffer"`
-	MaxCellDisplayLength int  `mapstructure:"max_cell_display_length"`
-	JSONBAutoFormat      bool `mapstructure:"jsonb_auto_format"`
-	LargeTableThreshold  int  `mapstructure:"large_table_threshold"`
+	VirtualScrollBuffer  int    `mapstructure:"virtual_scroll_buffer"`
+	MaxCellDisplayLength int    `mapstructure:"max_cell_display_length"`
+	JSONBAutoFormat      bool   `mapstructure:"jsonb_auto_format"`
+	LargeTableThreshold  int    `mapstructure:"large_table_threshold"`
+	TimeZone             string `mapstructure:"time_zone"`    // Zone for timestamptz values, "local" by default
+	ByteaFormat          string `mapstructure:"bytea_format"` // "hex" or "escape"
 }
 
 type HistoryConfig struct {
@@ -79,12 +84,15 @@ func GetDefaults() *Config {
 			UseSpaces:    true,
 			AutoComplete: true,
 			FormatOnSave: false,
+			StopOnError:  true,
 		},
 		Data: DataConfig{
 			VirtualScrollBuffer:  100,
 			MaxCellDisplayLength: 100,
 			JSONBAutoFormat:      true,
 			LargeTableThreshold:  1000000,
+			TimeZone:             "local",
+			ByteaFormat:          "hex",
 		},
 		History: HistoryConfig{
 			Enabled:           true,
@@ -102,23 +110,41 @@ func GetDefaults() *Config {
 
 // Load loads configuration from files
 func Load() (*Config, error) {
-	v := viper.New()
+	return load("")
+}
+
+// LoadFile loads configuration from the given file, which must exist
+func LoadFile(path string) (*Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	return load(path)
+}
 
-	// Set config name and type
-	v.SetConfigName("config")
+// load loads configuration from path, or from the first config.yaml found
+// when path is empty
+func load(path string) (*Config, error) {
+	v := viper.New()
 	v.SetConfigType("yaml")
 
-	// Add config paths in priority order
-	// 1. User config directory
-	if configDir, err := os.UserConfigDir(); err == nil {
-		v.AddConfigPath(filepath.Join(configDir, "lazypg"))
-	}
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		// Set config name
+		v.SetConfigName("config")
 
-	// 2. Current directory
-	v.AddConfigPath(".")
+		// Add config paths in priority order
+		// 1. User config directory
+		if configDir, err := os.UserConfigDir(); err == nil {
+			v.AddConfigPath(filepath.Join(configDir, "lazypg"))
+		}
 
-	// 3. Default config directory
-	v.AddConfigPath("./config")
+		// 2. Current directory
+		v.AddConfigPath(".")
+
+		// 3. Default config directory
+		v.AddConfigPath("./config")
+	}
 
 	// Set defaults from default.yaml
 	v.SetDefault("general.auto_connect_last", false)
@@ -133,10 +159,13 @@ func Load() (*Config, error) {
 	v.SetDefault("editor.use_spaces", true)
 	v.SetDefault("editor.auto_complete", true)
 	v.SetDefault("editor.format_on_save", false)
+	v.SetDefault("editor.stop_on_error", true)
 	v.SetDefault("data.virtual_scroll_buffer", 100)
 	v.SetDefault("data.max_cell_display_length", 100)
 	v.SetDefault("data.jsonb_auto_format", true)
 	v.SetDefault("data.large_table_threshold", 1000000)
+	v.SetDefault("data.time_zone", "local")
+	v.SetDefault("data.bytea_format", "hex")
 	v.SetDefault("history.enabled", true)
 	v.SetDefault("history.max_entries", 1000)
 	v.SetDefault("history.persist", true)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"sort"
@@ -113,8 +114,8 @@ type AddResult struct {
 func (m *Manager) Add(config models.ConnectionConfig) (*AddResult, error) {
 	result := &AddResult{}
 
-	// Save password to secure keyring (if provided)
-	if config.Password != "" && m.passwordStore != nil {
+	// Save password to secure keyring (if provided and allowed)
+	if config.Password != "" && !config.NoSavePassword && m.passwordStore != nil {
 		if err := m.passwordStore.Save(config.Host, config.Port, config.Database, config.User, config.Password); err != nil {
 			// Store the error but don't fail - caller can decide how to handle
 			result.PasswordSaveError = err
@@ -131,6 +132,8 @@ func (m *Manager) Add(config models.ConnectionConfig) (*AddResult, error) {
 			m.history[i].LastUsed = time.Now()
 			m.history[i].UsageCount++
 			m.history[i].SSLMode = config.SSLMode
+			m.history[i].Params = historyParams(config.Params)
+			m.history[i].Profile = config.Profile
 			// Update name if config has one
 			if config.Name != "" {
 				m.history[i].Name = config.Name
@@ -153,6 +156,8 @@ func (m *Manager) Add(config models.ConnectionConfig) (*AddResult, error) {
 		Database:   config.Database,
 		User:       config.User,
 		SSLMode:    config.SSLMode,
+		Params:     historyParams(config.Params),
+		Profile:    config.Profile,
 		LastUsed:   time.Now(),
 		UsageCount: 1,
 		CreatedAt:  time.Now(),
@@ -163,6 +168,17 @@ func (m *Manager) Add(config models.ConnectionConfig) (*AddResult, error) {
 	return result, m.Save()
 }
 
+// historyParams returns the extra keywords of a connection worth saving,
+// leaving out secrets like the password of the SSL key
+func historyParams(params map[string]string) map[string]string {
+	saved := maps.Clone(params)
+	delete(saved, "sslpassword")
+	if len(saved) == 0 {
+		return nil
+	}
+	return saved
+}
+
 // GetAll returns all connection history entries
 func (m *Manager) GetAll() []models.ConnectionHistoryEntry {
 	return m.history
@@ -262,3 +278,11 @@ func (m *Manager) SavePassword(host string, port int, database, user, password s
 	}
 	return m.passwordStore.Save(host, port, database, user, password)
 }
+
+// GetPassword returns the password saved for a connection
+func (m *Manager) GetPassword(host string, port int, database, user string) (string, error) {
+	if m.passwordStore == nil {
+		return "", fmt.Errorf("password store not initialized")
+	}
+	return m.passwordStore.Get(host, port, database, user)
+}
@@ -0,0 +1,72 @@
+package connection_history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	return &Manager{
+		path:          filepath.Join(dir, "connection_history.yaml"),
+		configDir:     dir,
+		passwordStore: &PasswordStore{ring: keyring.NewArrayKeyring(nil)},
+	}
+}
+
+func TestAddSavesPassword(t *testing.T) {
+	m := newTestManager(t)
+	config := models.ConnectionConfig{Host: "localhost", Port: 5432, Database: "shop", User: "app", Password: "secret"}
+	if _, err := m.Add(config); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	password, err := m.GetPassword("localhost", 5432, "shop", "app")
+	if err != nil || password != "secret" {
+		t.Errorf("GetPassword = %q (%v), expected %q", password, err, "secret")
+	}
+}
+
+func TestAddSkipsNoSavePassword(t *testing.T) {
+	m := newTestManager(t)
+	config := models.ConnectionConfig{Host: "db.internal", Port: 5432, Database: "app", User: "admin", Password: "from-command", NoSavePassword: true}
+	if _, err := m.Add(config); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := m.passwordStore.Get("db.internal", 5432, "app", "admin"); !errors.Is(err, ErrPasswordNotFound) {
+		t.Errorf("expected the password not to be saved, got %v", err)
+	}
+	if len(m.GetAll()) != 1 {
+		t.Errorf("expected the connection in history, got %d entries", len(m.GetAll()))
+	}
+}
+
+func TestAddKeepsProfile(t *testing.T) {
+	m := newTestManager(t)
+	profile := models.ConnectionProfile{Name: "prod", Host: "db.internal", Database: "app", User: "admin", ReadOnly: true}
+	if _, err := m.Add(profile.ToConnectionConfig()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	entries := m.GetAll()
+	if len(entries) != 1 || entries[0].Profile != "prod" {
+		t.Fatalf("expected one entry of profile prod, got %+v", entries)
+	}
+	if config := entries[0].ToConnectionConfig(); config.Profile != "prod" {
+		t.Errorf("ToConnectionConfig().Profile = %q, expected %q", config.Profile, "prod")
+	}
+
+	// Connecting by hand with the same settings is no longer the profile
+	if _, err := m.Add(models.ConnectionConfig{Host: "db.internal", Port: 5432, Database: "app", User: "admin"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if entries := m.GetAll(); len(entries) != 1 || entries[0].Profile != "" {
+		t.Errorf("expected the entry to drop the profile, got %+v", entries)
+	}
+}
@@ -0,0 +1,259 @@
+package connection
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// Keywords kept in the fields of ConnectionConfig rather than in Params
+var coreKeywords = map[string]bool{
+	"host":     true,
+	"port":     true,
+	"dbname":   true,
+	"database": true,
+	"user":     true,
+	"password": true,
+	"sslmode":  true,
+}
+
+// IsConnectionString reports whether s is a postgres:// URI or a libpq
+// keyword/value string rather than a plain host name
+func IsConnectionString(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "postgres://") || strings.HasPrefix(s, "postgresql://") || strings.Contains(s, "=")
+}
+
+// ParseConnectionString parses a postgres:// URI or a libpq keyword/value
+// string. Keywords without a field of their own, e.g. sslrootcert or
+// application_name, end up in Params.
+func ParseConnectionString(s string) (models.ConnectionConfig, error) {
+	config := models.ConnectionConfig{Port: 5432}
+	if err := ApplyConnectionOptions(&config, s); err != nil {
+		return models.ConnectionConfig{}, err
+	}
+	return config, nil
+}
+
+// ApplyConnectionOptions sets the keywords of a URI or keyword/value string
+// on config, overriding those it already has
+func ApplyConnectionOptions(config *models.ConnectionConfig, s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	var settings map[string]string
+	var err error
+	if strings.HasPrefix(s, "postgres://") || strings.HasPrefix(s, "postgresql://") {
+		settings, err = parseURISettings(s)
+	} else {
+		settings, err = parseKeywordValueSettings(s)
+	}
+	if err != nil {
+		return err
+	}
+
+	for key, value := range settings {
+		switch key {
+		case "host":
+			config.Host = value
+		case "port":
+			// Multiple hosts can each have their own port
+			ports := strings.Split(value, ",")
+			port, err := strconv.Atoi(ports[0])
+			if err != nil {
+				return fmt.Errorf("invalid port %q", value)
+			}
+			config.Port = port
+			if len(ports) > 1 {
+				setParam(config, "port", value)
+			} else {
+				delete(config.Params, "port")
+			}
+		case "dbname", "database":
+			config.Database = value
+		case "user":
+			config.User = value
+		case "password":
+			config.Password = value
+		case "sslmode":
+			config.SSLMode = value
+		default:
+			setParam(config, key, value)
+		}
+	}
+	return nil
+}
+
+// setParam sets an extra keyword of a connection
+func setParam(config *models.ConnectionConfig, key, value string) {
+	if config.Params == nil {
+		config.Params = make(map[string]string)
+	}
+	config.Params[key] = value
+}
+
+// parseURISettings returns the keywords of a postgres:// URI
+func parseURISettings(s string) (map[string]string, error) {
+	// url.Parse takes a single host, so the host list is split off first
+	scheme, rest, _ := strings.Cut(s, "://")
+	authority := rest
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		authority = rest[:i]
+	}
+	userInfo, hostList := "", authority
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userInfo, hostList = authority[:i+1], authority[i+1:]
+	}
+	u, err := url.Parse(scheme + "://" + userInfo + "host" + rest[len(authority):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection URI: %w", err)
+	}
+
+	settings := make(map[string]string)
+	if u.User != nil {
+		settings["user"] = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			settings["password"] = password
+		}
+	}
+
+	// Hosts are separated by commas, each with an optional port
+	var hosts, ports []string
+	for _, hostPort := range strings.Split(hostList, ",") {
+		if hostPort == "" {
+			continue
+		}
+		host, port := hostPort, ""
+		if i := strings.LastIndex(hostPort, ":"); i >= 0 && !strings.HasSuffix(hostPort, "]") {
+			host, port = hostPort[:i], hostPort[i+1:]
+		}
+		host, err := url.PathUnescape(strings.Trim(host, "[]"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host in connection URI: %w", err)
+		}
+		hosts = append(hosts, host)
+		ports = append(ports, port)
+	}
+	if len(hosts) > 0 {
+		settings["host"] = strings.Join(hosts, ",")
+	}
+	if strings.Join(ports, "") != "" {
+		for i, port := range ports {
+			if port == "" {
+				ports[i] = "5432"
+			}
+		}
+		settings["port"] = strings.Join(ports, ",")
+	}
+
+	if database := strings.TrimPrefix(u.Path, "/"); database != "" {
+		settings["dbname"] = database
+	}
+
+	for key, values := range u.Query() {
+		settings[key] = values[len(values)-1]
+	}
+	return settings, nil
+}
+
+// parseKeywordValueSettings returns the keywords of a libpq keyword/value
+// string. Values may be single-quoted; backslash escapes quotes and itself.
+func parseKeywordValueSettings(s string) (map[string]string, error) {
+	settings := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f\v")
+		if s == "" {
+			return settings, nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("missing \"=\" after %q in connection string", s)
+		}
+		key := strings.TrimSpace(s[:eq])
+		if key == "" || strings.ContainsAny(key, " \t\n\r\f\v") {
+			return nil, fmt.Errorf("invalid keyword %q in connection string", key)
+		}
+		s = strings.TrimLeft(s[eq+1:], " \t\n\r\f\v")
+
+		var value strings.Builder
+		quoted := strings.HasPrefix(s, "'")
+		if quoted {
+			s = s[1:]
+		}
+		i := 0
+		for ; i < len(s); i++ {
+			c := s[i]
+			if quoted && c == '\'' {
+				break
+			}
+			if !quoted && strings.IndexByte(" \t\n\r\f\v", c) >= 0 {
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				c = s[i]
+			}
+			value.WriteByte(c)
+		}
+		if quoted {
+			if i == len(s) {
+				return nil, fmt.Errorf("unterminated quoted value for %q in connection string", key)
+			}
+			i++
+		}
+		s = s[i:]
+		settings[key] = value.String()
+	}
+}
+
+// quoteConnValue quotes a value for a keyword/value connection string
+func quoteConnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\f\v'\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// buildConnectionString builds the keyword/value connection string of a
+// connection, quoting values as needed
+func buildConnectionString(config models.ConnectionConfig) string {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "prefer"
+	}
+	port := strconv.Itoa(config.Port)
+	if ports := config.Params["port"]; ports != "" {
+		port = ports
+	}
+
+	pairs := []string{
+		"host=" + quoteConnValue(config.Host),
+		"port=" + quoteConnValue(port),
+		"user=" + quoteConnValue(config.User),
+		"dbname=" + quoteConnValue(config.Database),
+		"sslmode=" + quoteConnValue(sslMode),
+	}
+	if config.Password != "" {
+		pairs = append(pairs, "password="+quoteConnValue(config.Password))
+	}
+
+	keys := make([]string, 0, len(config.Params))
+	for key := range config.Params {
+		if !coreKeywords[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+quoteConnValue(config.Params[key]))
+	}
+
+	return strings.Join(pairs, " ")
+}
@@ -3,6 +3,7 @@ package connection
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,11 +21,21 @@ type Manager struct {
 type Connection struct {
 	ID          string
 	Config      models.ConnectionConfig
-	Pool        *Pool
+	Pool        *Pool  // Pool of the database in use
+	Database    string // Database in use, the configured one until another is chosen
 	Connected   bool
 	ConnectedAt time.Time
 	LastPing    time.Time
 	Error       error
+
+	pools map[string]*Pool // Pools of the server's databases opened so far, by name
+}
+
+// close closes the pools of every database of the connection
+func (c *Connection) close() {
+	for _, pool := range c.pools {
+		pool.Close()
+	}
 }
 
 // NewManager creates a new connection manager
@@ -43,9 +54,7 @@ func (m *Manager) Connect(ctx context.Context, config models.ConnectionConfig) (
 
 	// Close existing connection if present
 	if existing, ok := m.connections[id]; ok {
-		if existing.Pool != nil {
-			existing.Pool.Close()
-		}
+		existing.close()
 	}
 
 	pool, err := NewPool(ctx, config)
@@ -64,9 +73,11 @@ func (m *Manager) Connect(ctx context.Context, config models.ConnectionConfig) (
 		ID:          id,
 		Config:      config,
 		Pool:        pool,
+		Database:    config.Database,
 		Connected:   true,
 		ConnectedAt: time.Now(),
 		LastPing:    time.Now(),
+		pools:       map[string]*Pool{config.Database: pool},
 	}
 
 	m.connections[id] = conn
@@ -85,10 +96,7 @@ func (m *Manager) Disconnect(id string) error {
 		return fmt.Errorf("connection %s not found", id)
 	}
 
-	if conn.Pool != nil {
-		conn.Pool.Close()
-	}
-
+	conn.close()
 	delete(m.connections, id)
 
 	if m.active == id {
@@ -112,7 +120,84 @@ func (m *Manager) GetActive() (*Connection, error) {
 		return nil, fmt.Errorf("active connection not found")
 	}
 
-	return conn, nil
+	return conn.snapshot(), nil
+}
+
+// Get returns a connection by ID
+func (m *Manager) Get(id string) (*Connection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	conn, ok := m.connections[id]
+	if !ok {
+		return nil, fmt.Errorf("connection %s not found", id)
+	}
+	return conn.snapshot(), nil
+}
+
+// OpenDatabase returns the pool of a database of a connection's server,
+// the database in use when database is empty, opening it with the
+// connection's settings on first use
+func (m *Manager) OpenDatabase(ctx context.Context, id, database string) (*Pool, error) {
+	m.mu.RLock()
+	conn, ok := m.connections[id]
+	var pool *Pool
+	if ok {
+		if database == "" {
+			database = conn.Database
+		}
+		pool = conn.pools[database]
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("connection %s not found", id)
+	}
+	if conn.pools == nil {
+		return nil, fmt.Errorf("connection %s is not open", id)
+	}
+	if pool != nil {
+		return pool, nil
+	}
+
+	// Connecting can take a while, so it is done without holding the lock
+	config := conn.Config
+	config.Database = database
+	pool, err := NewPool(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.connections[id]; !ok || current != conn {
+		pool.Close()
+		return nil, fmt.Errorf("connection %s was closed", id)
+	}
+	if existing, ok := conn.pools[database]; ok {
+		// Opened meanwhile by another caller
+		pool.Close()
+		return existing, nil
+	}
+	conn.pools[database] = pool
+	return pool, nil
+}
+
+// UseDatabase makes an opened database the one a connection's pool uses
+func (m *Manager) UseDatabase(id, database string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conn, ok := m.connections[id]
+	if !ok {
+		return fmt.Errorf("connection %s not found", id)
+	}
+	pool, ok := conn.pools[database]
+	if !ok {
+		return fmt.Errorf("database %s is not open", database)
+	}
+	conn.Pool = pool
+	conn.Database = database
+	return nil
 }
 
 // SetActive sets the active connection
@@ -128,34 +213,49 @@ func (m *Manager) SetActive(id string) error {
 	return nil
 }
 
-// GetAll returns all connections
+// GetAll returns all connections, ordered by ID
 func (m *Manager) GetAll() []*Connection {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	conns := make([]*Connection, 0, len(m.connections))
 	for _, conn := range m.connections {
-		conns = append(conns, conn)
+		conns = append(conns, conn.snapshot())
 	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].ID < conns[j].ID
+	})
 	return conns
 }
 
+// snapshot returns a copy of the connection that stays consistent when
+// another database is used meanwhile. The caller must hold the lock.
+func (c *Connection) snapshot() *Connection {
+	snapshot := *c
+	snapshot.pools = nil
+	return &snapshot
+}
+
 // Ping tests the active connection
 func (m *Manager) Ping(ctx context.Context) error {
 	m.mu.RLock()
 	activeID := m.active
 	conn, ok := m.connections[activeID]
+	var pool *Pool
+	if ok {
+		pool = conn.Pool
+	}
 	m.mu.RUnlock()
 
 	if !ok || activeID == "" {
 		return fmt.Errorf("no active connection")
 	}
 
-	if conn.Pool == nil {
+	if pool == nil {
 		return fmt.Errorf("connection pool not initialized")
 	}
 
-	err := conn.Pool.Ping(ctx)
+	err := pool.Ping(ctx)
 
 	m.mu.Lock()
 	// Verify connection still exists and is still active
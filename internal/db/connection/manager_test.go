@@ -0,0 +1,33 @@
+package connection
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOpenDatabaseAfterUseDatabase(t *testing.T) {
+	shop, crm := &Pool{}, &Pool{}
+	m := NewManager()
+	m.connections["a"] = &Connection{ID: "a", Pool: shop, Database: "shop", Connected: true, pools: map[string]*Pool{"shop": shop, "crm": crm}}
+	m.active = "a"
+
+	// The tree browses another database of the server
+	if err := m.UseDatabase("a", "crm"); err != nil {
+		t.Fatal(err)
+	}
+	if conn, _ := m.GetActive(); conn.Pool != crm || conn.Database != "crm" {
+		t.Errorf("expected the browsed database to be in use, got %s", conn.Database)
+	}
+
+	// A tab opened in the first database still reaches it
+	ctx := context.Background()
+	if pool, err := m.OpenDatabase(ctx, "a", "shop"); err != nil || pool != shop {
+		t.Errorf("expected the tab's database, got %v (%v)", pool, err)
+	}
+	if pool, err := m.OpenDatabase(ctx, "a", ""); err != nil || pool != crm {
+		t.Errorf("expected the database in use without a name, got %v (%v)", pool, err)
+	}
+	if _, err := m.OpenDatabase(ctx, "b", "shop"); err == nil {
+		t.Error("expected an unknown connection to fail")
+	}
+}
@@ -3,8 +3,10 @@ package connection
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rebelice/lazypg/internal/models"
 )
@@ -31,6 +33,23 @@ func NewPool(ctx context.Context, config models.ConnectionConfig) (*Pool, error)
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 	poolConfig.HealthCheckPeriod = time.Minute
 
+	// Session settings from the connection profile
+	if config.ReadOnly {
+		poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+	if config.DefaultSchema != "" {
+		poolConfig.ConnConfig.RuntimeParams["search_path"] = pgx.Identifier{config.DefaultSchema}.Sanitize() + ", public"
+	}
+	if startupSQL := strings.TrimSpace(config.StartupSQL); startupSQL != "" {
+		poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			// Without arguments Exec uses the simple protocol, which allows several statements
+			if _, err := conn.Exec(ctx, startupSQL); err != nil {
+				return fmt.Errorf("startup SQL failed: %w", err)
+			}
+			return nil
+		}
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -60,6 +79,12 @@ func (p *Pool) Ping(ctx context.Context) error {
 	return p.pool.Ping(ctx)
 }
 
+// Acquire takes a connection out of the pool for exclusive use, e.g. to keep
+// a transaction on one session. The caller must release it.
+func (p *Pool) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	return p.pool.Acquire(ctx)
+}
+
 // GetPool returns the underlying pgxpool.Pool
 func (p *Pool) GetPool() *pgxpool.Pool {
 	return p.pool
@@ -154,26 +179,3 @@ func (p *Pool) Execute(ctx context.Context, sql string, args ...interface{}) (in
 	}
 	return result.RowsAffected(), nil
 }
-
-// buildConnectionString creates a PostgreSQL connection string
-func buildConnectionString(config models.ConnectionConfig) string {
-	sslMode := config.SSLMode
-	if sslMode == "" {
-		sslMode = "prefer"
-	}
-
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s database=%s sslmode=%s",
-		config.Host,
-		config.Port,
-		config.User,
-		config.Database,
-		sslMode,
-	)
-
-	if config.Password != "" {
-		connStr += fmt.Sprintf(" password=%s", config.Password)
-	}
-
-	return connStr
-}
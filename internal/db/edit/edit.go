@@ -0,0 +1,260 @@
+package edit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/db/connection"
+)
+
+// KeyValue is one column of the key that identifies a row
+type KeyValue struct {
+	Column string
+	Value  string
+}
+
+// ColumnChange is a staged change to a single column
+type ColumnChange struct {
+	Column   string
+	OldValue string
+	NewValue string
+	IsNull   bool // true sets the column to NULL (NewValue is ignored)
+}
+
+// RowUpdate holds all staged changes for one row
+type RowUpdate struct {
+	Key     []KeyValue
+	Changes []ColumnChange
+}
+
+// ColumnValue is a value for one column of a new row
+type ColumnValue struct {
+	Column string
+	Value  string
+	IsNull bool // true inserts NULL (Value is ignored)
+}
+
+// Statement is a parameterized SQL statement ready for execution
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// QuoteIdent quotes a PostgreSQL identifier, escaping embedded quotes
+func QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QualifiedName returns the quoted "schema"."table" name
+func QualifiedName(schema, table string) string {
+	return QuoteIdent(schema) + "." + QuoteIdent(table)
+}
+
+// QuoteLiteral quotes a value as a SQL string literal
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// BuildUpdate generates a key-scoped UPDATE statement for a staged row
+func BuildUpdate(schema, table string, update RowUpdate) (Statement, error) {
+	if len(update.Key) == 0 {
+		return Statement{}, fmt.Errorf("cannot update %s.%s: no key columns to identify the row", schema, table)
+	}
+	if len(update.Changes) == 0 {
+		return Statement{}, fmt.Errorf("no changes to apply")
+	}
+
+	var args []interface{}
+	setClauses := make([]string, 0, len(update.Changes))
+	for _, change := range update.Changes {
+		if change.IsNull {
+			setClauses = append(setClauses, fmt.Sprintf("%s = NULL", QuoteIdent(change.Column)))
+			continue
+		}
+		// Values are sent as text so PostgreSQL parses them using the column type
+		args = append(args, change.NewValue)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", QuoteIdent(change.Column), len(args)))
+	}
+
+	where, args := buildKeyCondition(update.Key, args)
+
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		QualifiedName(schema, table),
+		strings.Join(setClauses, ", "),
+		where,
+	)
+	return Statement{SQL: sql, Args: args}, nil
+}
+
+// BuildInsert generates an INSERT statement for a new row.
+// Columns that are not listed get their default value.
+func BuildInsert(schema, table string, values []ColumnValue) Statement {
+	if len(values) == 0 {
+		return Statement{SQL: fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", QualifiedName(schema, table))}
+	}
+
+	var args []interface{}
+	columns := make([]string, 0, len(values))
+	placeholders := make([]string, 0, len(values))
+	for _, v := range values {
+		columns = append(columns, QuoteIdent(v.Column))
+		if v.IsNull {
+			placeholders = append(placeholders, "NULL")
+			continue
+		}
+		args = append(args, v.Value)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		QualifiedName(schema, table),
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+	)
+	return Statement{SQL: sql, Args: args}
+}
+
+// LiteralDefault extracts a plain value from a column default expression.
+// It handles numbers, booleans and quoted strings with an optional cast
+// (e.g. 'active'::text). Expressions such as nextval() or now() are not literals.
+func LiteralDefault(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return "", false
+	}
+
+	// Quoted string, optionally followed by a type cast
+	if strings.HasPrefix(expr, "'") {
+		var b strings.Builder
+		for i := 1; i < len(expr); i++ {
+			if expr[i] != '\'' {
+				b.WriteByte(expr[i])
+				continue
+			}
+			if i+1 < len(expr) && expr[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			// Closing quote: the rest may only be a cast
+			rest := expr[i+1:]
+			if rest == "" || (strings.HasPrefix(rest, "::") && isTypeName(rest[2:])) {
+				return b.String(), true
+			}
+			return "", false
+		}
+		return "", false
+	}
+
+	if expr == "true" || expr == "false" {
+		return expr, true
+	}
+
+	// Numbers, possibly wrapped in parentheses when negative
+	num := strings.TrimSuffix(strings.TrimPrefix(expr, "("), ")")
+	if idx := strings.Index(num, "::"); idx >= 0 {
+		num = num[:idx]
+	}
+	if num != "" && strings.Trim(num, "-+.0123456789eE") == "" && strings.ContainsAny(num, "0123456789") {
+		return num, true
+	}
+	return "", false
+}
+
+// isTypeName reports whether s looks like a type name used in a cast
+func isTypeName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == ' ', r == '.', r == '"', r == '[', r == ']', r == '(', r == ')', r == ',':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// BuildDelete generates a key-scoped DELETE statement for one row
+func BuildDelete(schema, table string, key []KeyValue) (Statement, error) {
+	if len(key) == 0 {
+		return Statement{}, fmt.Errorf("cannot delete from %s.%s: no key columns to identify the row", schema, table)
+	}
+
+	where, args := buildKeyCondition(key, nil)
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s", QualifiedName(schema, table), where)
+	return Statement{SQL: sql, Args: args}, nil
+}
+
+// buildKeyCondition appends key values to args and returns the WHERE condition
+func buildKeyCondition(key []KeyValue, args []interface{}) (string, []interface{}) {
+	conditions := make([]string, 0, len(key))
+	for _, kv := range key {
+		args = append(args, kv.Value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", QuoteIdent(kv.Column), len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// Preview renders the statement with its arguments inlined as literals.
+// It is meant for display only; execution always uses bind parameters.
+func (s Statement) Preview() string {
+	var b strings.Builder
+	sql := s.SQL
+	for i := 0; i < len(sql); i++ {
+		if sql[i] != '$' {
+			b.WriteByte(sql[i])
+			continue
+		}
+		// Parse the placeholder index following '$'
+		j := i + 1
+		for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			j++
+		}
+		var idx int
+		if j == i+1 {
+			b.WriteByte(sql[i])
+			continue
+		}
+		_, _ = fmt.Sscanf(sql[i+1:j], "%d", &idx)
+		if idx < 1 || idx > len(s.Args) {
+			b.WriteString(sql[i:j])
+		} else if s.Args[idx-1] == nil {
+			b.WriteString("NULL")
+		} else {
+			b.WriteString(QuoteLiteral(fmt.Sprintf("%v", s.Args[idx-1])))
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+// ApplyInTransaction executes statements in a single transaction.
+// Each statement must affect exactly one row; otherwise everything is rolled back.
+func ApplyInTransaction(ctx context.Context, pool *connection.Pool, statements []Statement) (int64, error) {
+	tx, err := pool.GetPool().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var total int64
+	for i, stmt := range statements {
+		tag, err := tx.Exec(ctx, stmt.SQL, stmt.Args...)
+		if err != nil {
+			return 0, fmt.Errorf("statement %d failed, all changes rolled back: %w", i+1, err)
+		}
+		if tag.RowsAffected() != 1 {
+			return 0, fmt.Errorf("statement %d affected %d rows (expected 1), all changes rolled back", i+1, tag.RowsAffected())
+		}
+		total += tag.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,154 @@
+package edit
+
+import (
+	"testing"
+)
+
+func TestBuildUpdate(t *testing.T) {
+	update := RowUpdate{
+		Key: []KeyValue{{Column: "id", Value: "42"}},
+		Changes: []ColumnChange{
+			{Column: "name", OldValue: "old", NewValue: "new"},
+			{Column: "deleted_at", OldValue: "2024-01-01", IsNull: true},
+		},
+	}
+
+	stmt, err := BuildUpdate("public", "users", update)
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+
+	expected := `UPDATE "public"."users" SET "name" = $1, "deleted_at" = NULL WHERE "id" = $2`
+	if stmt.SQL != expected {
+		t.Errorf("Expected SQL:\n%s\ngot:\n%s", expected, stmt.SQL)
+	}
+
+	if len(stmt.Args) != 2 || stmt.Args[0] != "new" || stmt.Args[1] != "42" {
+		t.Errorf("Unexpected args: %v", stmt.Args)
+	}
+}
+
+func TestBuildUpdateCompositeKey(t *testing.T) {
+	update := RowUpdate{
+		Key: []KeyValue{
+			{Column: "order_id", Value: "1"},
+			{Column: "line", Value: "3"},
+		},
+		Changes: []ColumnChange{{Column: "qty", NewValue: "5"}},
+	}
+
+	stmt, err := BuildUpdate("shop", "order_lines", update)
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+
+	expected := `UPDATE "shop"."order_lines" SET "qty" = $1 WHERE "order_id" = $2 AND "line" = $3`
+	if stmt.SQL != expected {
+		t.Errorf("Expected SQL:\n%s\ngot:\n%s", expected, stmt.SQL)
+	}
+}
+
+func TestBuildUpdateRequiresKey(t *testing.T) {
+	_, err := BuildUpdate("public", "logs", RowUpdate{
+		Changes: []ColumnChange{{Column: "msg", NewValue: "x"}},
+	})
+	if err == nil {
+		t.Error("Expected error for update without key columns")
+	}
+
+	_, err = BuildUpdate("public", "logs", RowUpdate{
+		Key: []KeyValue{{Column: "id", Value: "1"}},
+	})
+	if err == nil {
+		t.Error("Expected error for update without changes")
+	}
+}
+
+func TestBuildInsert(t *testing.T) {
+	stmt := BuildInsert("public", "users", []ColumnValue{
+		{Column: "name", Value: "alice"},
+		{Column: "note", IsNull: true},
+		{Column: "age", Value: "30"},
+	})
+
+	expected := `INSERT INTO "public"."users" ("name", "note", "age") VALUES ($1, NULL, $2)`
+	if stmt.SQL != expected {
+		t.Errorf("Expected SQL:\n%s\ngot:\n%s", expected, stmt.SQL)
+	}
+	if len(stmt.Args) != 2 || stmt.Args[0] != "alice" || stmt.Args[1] != "30" {
+		t.Errorf("Unexpected args: %v", stmt.Args)
+	}
+
+	stmt = BuildInsert("public", "users", nil)
+	if stmt.SQL != `INSERT INTO "public"."users" DEFAULT VALUES` {
+		t.Errorf("Unexpected SQL for default row: %s", stmt.SQL)
+	}
+}
+
+func TestBuildDelete(t *testing.T) {
+	stmt, err := BuildDelete("public", "users", []KeyValue{{Column: "id", Value: "7"}})
+	if err != nil {
+		t.Fatalf("BuildDelete failed: %v", err)
+	}
+
+	expected := `DELETE FROM "public"."users" WHERE "id" = $1`
+	if stmt.SQL != expected {
+		t.Errorf("Expected SQL:\n%s\ngot:\n%s", expected, stmt.SQL)
+	}
+
+	if _, err := BuildDelete("public", "users", nil); err == nil {
+		t.Error("Expected error for delete without key columns")
+	}
+}
+
+func TestLiteralDefault(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		ok       bool
+	}{
+		{"0", "0", true},
+		{"(-1)", "-1", true},
+		{"1.5", "1.5", true},
+		{"true", "true", true},
+		{"'active'::text", "active", true},
+		{"'it''s'::character varying", "it's", true},
+		{"'{}'::jsonb", "{}", true},
+		{"nextval('users_id_seq'::regclass)", "", false},
+		{"now()", "", false},
+		{"CURRENT_TIMESTAMP", "", false},
+		{"'a'::text || 'b'::text", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := LiteralDefault(tt.expr)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("LiteralDefault(%q) = (%q, %v), expected (%q, %v)", tt.expr, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestQuoteIdent(t *testing.T) {
+	tests := map[string]string{
+		"users":      `"users"`,
+		"Mixed Case": `"Mixed Case"`,
+		`we"ird`:     `"we""ird"`,
+	}
+	for input, expected := range tests {
+		if got := QuoteIdent(input); got != expected {
+			t.Errorf("QuoteIdent(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestStatementPreview(t *testing.T) {
+	stmt := Statement{
+		SQL:  `UPDATE "t" SET "a" = $1, "b" = $2 WHERE "id" = $10`,
+		Args: []interface{}{"it's", nil, "x", "x", "x", "x", "x", "x", "x", "7"},
+	}
+
+	expected := `UPDATE "t" SET "a" = 'it''s', "b" = NULL WHERE "id" = '7'`
+	if got := stmt.Preview(); got != expected {
+		t.Errorf("Expected preview:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rebelice/lazypg/internal/db/connection"
+)
+
+// Session is a client backend from pg_stat_activity
+type Session struct {
+	PID           int
+	User          string
+	Database      string
+	Application   string
+	Client        string
+	State         string // active, idle, idle in transaction, ...
+	WaitEventType string
+	WaitEvent     string
+	Query         string        // Running or last query
+	Duration      time.Duration // Time in the current state, i.e. the running time of an active query
+	XactDuration  time.Duration // Age of the open transaction, 0 when there is none
+	BlockedBy     []int         // PIDs holding locks this session waits for
+	WaitingFor    string        // Locks requested but not granted, e.g. "RowExclusiveLock on orders"
+	LocksHeld     int
+}
+
+// ListSessions returns the client sessions of the server other than our own,
+// with the sessions that block them
+func ListSessions(ctx context.Context, pool *connection.Pool) ([]Session, error) {
+	query := `
+		SELECT
+			a.pid,
+			coalesce(a.usename, '') AS usename,
+			coalesce(a.datname, '') AS datname,
+			coalesce(a.application_name, '') AS application_name,
+			coalesce(host(a.client_addr), CASE WHEN a.client_port = -1 THEN 'local' ELSE '' END) AS client,
+			coalesce(a.state, '') AS state,
+			coalesce(a.wait_event_type, '') AS wait_event_type,
+			coalesce(a.wait_event, '') AS wait_event,
+			coalesce(a.query, '') AS query,
+			coalesce(extract(epoch FROM now() - coalesce(a.state_change, a.backend_start)), 0)::float8 AS duration,
+			coalesce(extract(epoch FROM now() - a.xact_start), 0)::float8 AS xact_duration,
+			pg_catalog.pg_blocking_pids(a.pid) AS blocked_by,
+			coalesce((
+				SELECT string_agg(l.mode || ' on ' || coalesce(l.relation::regclass::text, l.locktype), ', ')
+				FROM pg_catalog.pg_locks l
+				WHERE l.pid = a.pid AND NOT l.granted
+			), '') AS waiting_for,
+			(
+				SELECT count(*)
+				FROM pg_catalog.pg_locks l
+				WHERE l.pid = a.pid AND l.granted
+			) AS locks_held
+		FROM pg_catalog.pg_stat_activity a
+		WHERE a.backend_type = 'client backend'
+			AND a.pid <> pg_catalog.pg_backend_pid()
+		ORDER BY a.pid;
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		session := Session{
+			PID:           int(toInt64(row["pid"])),
+			User:          toString(row["usename"]),
+			Database:      toString(row["datname"]),
+			Application:   toString(row["application_name"]),
+			Client:        toString(row["client"]),
+			State:         toString(row["state"]),
+			WaitEventType: toString(row["wait_event_type"]),
+			WaitEvent:     toString(row["wait_event"]),
+			Query:         toString(row["query"]),
+			Duration:      seconds(row["duration"]),
+			XactDuration:  seconds(row["xact_duration"]),
+			WaitingFor:    toString(row["waiting_for"]),
+			LocksHeld:     int(toInt64(row["locks_held"])),
+		}
+		if pids, ok := row["blocked_by"].([]interface{}); ok {
+			for _, pid := range pids {
+				session.BlockedBy = append(session.BlockedBy, int(toInt64(pid)))
+			}
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// SignalBackend cancels the running query of a backend, or terminates the
+// backend with terminate set. It fails when the server did not signal it,
+// e.g. because the session has ended.
+func SignalBackend(ctx context.Context, pool *connection.Pool, pid int, terminate bool) error {
+	function := "pg_cancel_backend"
+	if terminate {
+		function = "pg_terminate_backend"
+	}
+	row, err := pool.QueryRow(ctx, "SELECT pg_catalog."+function+"($1) AS signalled", pid)
+	if err != nil {
+		return err
+	}
+	if signalled, _ := row["signalled"].(bool); !signalled {
+		return fmt.Errorf("backend %d was not signalled; the session may have ended", pid)
+	}
+	return nil
+}
+
+// seconds converts a number of seconds from the database to a duration
+func seconds(v interface{}) time.Duration {
+	if f, ok := v.(float64); ok {
+		return time.Duration(f * float64(time.Second))
+	}
+	return 0
+}
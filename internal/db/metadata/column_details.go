@@ -32,7 +32,7 @@ func GetColumnDetails(ctx context.Context, pool *connection.Pool, schema, table
 			CASE
 				WHEN c.character_maximum_length IS NOT NULL
 				THEN c.data_type || '(' || c.character_maximum_length || ')'
-				WHEN c.numeric_precision IS NOT NULL
+				WHEN c.data_type = 'numeric' AND c.numeric_precision IS NOT NULL
 				THEN c.data_type || '(' || c.numeric_precision || ',' || c.numeric_scale || ')'
 				ELSE c.data_type
 			END AS formatted_type,
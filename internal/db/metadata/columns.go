@@ -15,6 +15,11 @@ func GetTableColumns(ctx context.Context, pool *connection.Pool, schema, table s
 			column_name,
 			data_type,
 			udt_name,
+			CASE
+				WHEN data_type = 'USER-DEFINED' THEN quote_ident(udt_schema) || '.' || quote_ident(udt_name)
+				WHEN data_type = 'ARRAY' THEN udt_name
+				ELSE data_type
+			END as type_name,
 			CASE WHEN data_type = 'ARRAY' THEN true ELSE false END as is_array
 		FROM information_schema.columns
 		WHERE table_schema = $1 AND table_name = $2
@@ -31,6 +36,7 @@ func GetTableColumns(ctx context.Context, pool *connection.Pool, schema, table s
 		var col models.ColumnInfo
 		col.Name = toString(row["column_name"])
 		col.DataType = toString(row["data_type"])
+		col.TypeName = toString(row["type_name"])
 		udtName := toString(row["udt_name"])
 
 		if isArray, ok := row["is_array"].(bool); ok {
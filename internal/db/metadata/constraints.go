@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rebelice/lazypg/internal/db/connection"
 	"github.com/rebelice/lazypg/internal/models"
 )
@@ -62,15 +61,9 @@ func GetConstraints(ctx context.Context, pool *connection.Pool, schema, table st
 			ForeignTable: toString(row["foreign_table"]),
 		}
 
-		// Parse columns array
-		if colsArray, ok := row["columns"].(pgtype.Array[string]); ok {
-			constraint.Columns = colsArray.Elements
-		}
-
-		// Parse foreign columns array
-		if fkArray, ok := row["foreign_columns"].(pgtype.Array[string]); ok {
-			constraint.ForeignCols = fkArray.Elements
-		}
+		// Parse column arrays (pgx decodes name[] as []interface{})
+		constraint.Columns = toStringSlice(row["columns"])
+		constraint.ForeignCols = toStringSlice(row["foreign_columns"])
 
 		constraints = append(constraints, constraint)
 	}
@@ -93,3 +86,63 @@ func FormatConstraintType(conType string) string {
 		return strings.ToUpper(conType)
 	}
 }
+
+// Reference is a foreign key of another table that references a table
+type Reference struct {
+	Name       string
+	Schema     string   // Schema of the referencing table
+	Table      string   // Referencing table
+	Columns    []string // Referencing columns
+	RefColumns []string // Referenced columns, in the same order
+}
+
+// GetReferences retrieves the foreign keys that reference a table,
+// including those of the table itself
+func GetReferences(ctx context.Context, pool *connection.Pool, schema, table string) ([]Reference, error) {
+	query := `
+		SELECT
+			con.conname AS constraint_name,
+			ns.nspname AS schema_name,
+			cl.relname AS table_name,
+			ARRAY(
+				SELECT att.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS u(attnum, attposition)
+				JOIN pg_catalog.pg_attribute att ON att.attrelid = con.conrelid
+					AND att.attnum = u.attnum
+				ORDER BY u.attposition
+			) AS columns,
+			ARRAY(
+				SELECT att.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS u(attnum, attposition)
+				JOIN pg_catalog.pg_attribute att ON att.attrelid = con.confrelid
+					AND att.attnum = u.attnum
+				ORDER BY u.attposition
+			) AS ref_columns
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class cl ON con.conrelid = cl.oid
+		JOIN pg_catalog.pg_namespace ns ON cl.relnamespace = ns.oid
+		JOIN pg_catalog.pg_class clf ON con.confrelid = clf.oid
+		JOIN pg_catalog.pg_namespace nf ON clf.relnamespace = nf.oid
+		WHERE con.contype = 'f' AND nf.nspname = $1 AND clf.relname = $2
+			AND con.conparentid = 0
+		ORDER BY ns.nspname, cl.relname, con.conname
+	`
+
+	rows, err := pool.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+
+	var references []Reference
+	for _, row := range rows {
+		references = append(references, Reference{
+			Name:       toString(row["constraint_name"]),
+			Schema:     toString(row["schema_name"]),
+			Table:      toString(row["table_name"]),
+			Columns:    toStringSlice(row["columns"]),
+			RefColumns: toStringSlice(row["ref_columns"]),
+		})
+	}
+
+	return references, nil
+}
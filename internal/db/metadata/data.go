@@ -2,17 +2,19 @@ package metadata
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/rebelice/lazypg/internal/db/connection"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/values"
 )
 
 // TableData represents paginated table data
 type TableData struct {
 	Columns   []string
-	Rows      [][]string
+	Rows      [][]string      // Display text of each cell
+	Cells     [][]models.Cell // Typed values behind Rows
 	TotalRows int64
 }
 
@@ -50,60 +52,121 @@ func QueryTableData(ctx context.Context, pool *connection.Pool, schema, table st
 
 	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 
-	result, err := pool.QueryWithColumns(ctx, query)
+	data, err := QueryRows(ctx, pool, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table data: %w", err)
 	}
+	data.TotalRows = totalRows
+	return data, nil
+}
 
-	if len(result.Rows) == 0 {
-		return &TableData{
-			Columns:   result.Columns,
-			Rows:      [][]string{},
-			TotalRows: totalRows,
-		}, nil
+// QueryFilteredTableData fetches the first rows of a table matching
+// whereClause, with the number of matching rows
+func QueryFilteredTableData(ctx context.Context, pool *connection.Pool, schema, table, whereClause string, args []interface{}, limit int) (*TableData, error) {
+	from := fmt.Sprintf(`"%s"."%s" %s`, schema, table, whereClause)
+
+	countRow, err := pool.QueryRow(ctx, "SELECT COUNT(*) as count FROM "+from, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	data, err := QueryRows(ctx, pool, fmt.Sprintf("SELECT * FROM %s LIMIT %d", from, limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table data: %w", err)
+	}
+	if count, ok := countRow["count"].(int64); ok {
+		data.TotalRows = count
 	}
+	return data, nil
+}
 
-	columns := result.Columns
+// CountRowsUpTo counts the rows of a table matching whereClause, stopping
+// at limit so that large tables are not scanned in full
+func CountRowsUpTo(ctx context.Context, pool *connection.Pool, schema, table, whereClause string, args []interface{}, limit int) (int64, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) as count FROM (SELECT 1 FROM "%s"."%s" %s LIMIT %d) AS matching`, schema, table, whereClause, limit)
+	row, err := pool.QueryRow(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(row["count"]), nil
+}
+
+// QueryRows runs a query and reads all of its rows as typed cells and their
+// display text. TotalRows is set to the number of rows read.
+func QueryRows(ctx context.Context, pool *connection.Pool, query string, args ...interface{}) (*TableData, error) {
+	rows, err := pool.GetPool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// Convert rows to string slices
-	data := make([][]string, len(result.Rows))
-	for i, row := range result.Rows {
-		rowData := make([]string, len(columns))
-		for j, col := range columns {
-			val := row[col]
-			if val == nil {
-				rowData[j] = "NULL"
-			} else {
-				rowData[j] = convertValueToString(val)
-			}
+	columns, oids := values.Columns(rows)
+	data := &TableData{
+		Columns: columns,
+		Rows:    [][]string{},
+	}
+	for rows.Next() {
+		cells, err := values.ReadRow(rows, oids)
+		if err != nil {
+			return nil, err
 		}
-		data[i] = rowData
+		data.Cells = append(data.Cells, cells)
+		data.Rows = append(data.Rows, values.FormatRow(cells))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
+	data.TotalRows = int64(len(data.Rows))
+	return data, nil
+}
 
-	return &TableData{
-		Columns:   columns,
-		Rows:      data,
-		TotalRows: totalRows,
-	}, nil
+// RowSink receives rows streamed from the database
+type RowSink interface {
+	WriteHeader(columns []string) error
+	WriteRow(cells []models.Cell) error
 }
 
-// convertValueToString converts a database value to string, handling JSONB properly
-func convertValueToString(val interface{}) string {
-	// Check if it's a map or slice (JSONB types)
-	switch v := val.(type) {
-	case map[string]interface{}, []interface{}:
-		// Convert to JSON string
-		jsonBytes, err := json.Marshal(v)
+// StreamTableData streams every row of a table matching whereClause to sink.
+// Rows are read with a single query and never held in memory as a whole.
+// Returns the number of rows written.
+func StreamTableData(ctx context.Context, pool *connection.Pool, schema, table, whereClause string, args []interface{}, sort *SortOptions, sink RowSink) (int64, error) {
+	query := fmt.Sprintf(`SELECT * FROM "%s"."%s"`, schema, table)
+	if whereClause != "" {
+		query += " " + whereClause
+	}
+
+	if sort != nil && sort.Column != "" {
+		nullsClause := "NULLS LAST"
+		if sort.NullsFirst {
+			nullsClause = "NULLS FIRST"
+		}
+		query += fmt.Sprintf(" ORDER BY \"%s\" %s %s", sort.Column, sort.Direction, nullsClause)
+	}
+
+	rows, err := pool.GetPool().Query(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query table data: %w", err)
+	}
+	defer rows.Close()
+
+	columns, oids := values.Columns(rows)
+	if err := sink.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for rows.Next() {
+		cells, err := values.ReadRow(rows, oids)
 		if err != nil {
-			return fmt.Sprintf("%v", val)
+			return count, fmt.Errorf("failed to read row: %w", err)
 		}
-		return string(jsonBytes)
-	case []byte:
-		// Might be raw JSON bytes
-		return string(v)
-	default:
-		return fmt.Sprintf("%v", val)
+		if err := sink.WriteRow(cells); err != nil {
+			return count, err
+		}
+		count++
 	}
+
+	return count, rows.Err()
 }
 
 // SearchTableData searches entire table using ILIKE on all columns
@@ -130,39 +193,9 @@ func SearchTableData(ctx context.Context, pool *connection.Pool, schema, table s
 	whereClause := strings.Join(conditions, " OR ")
 	query := fmt.Sprintf("SELECT * FROM %s.%s WHERE %s LIMIT %d", schema, table, whereClause, limit)
 
-	result, err := pool.QueryWithColumns(ctx, query)
+	data, err := QueryRows(ctx, pool, query)
 	if err != nil {
 		return nil, fmt.Errorf("search query failed: %w", err)
 	}
-
-	if len(result.Rows) == 0 {
-		return &TableData{
-			Columns:   result.Columns,
-			Rows:      [][]string{},
-			TotalRows: 0,
-		}, nil
-	}
-
-	cols := result.Columns
-
-	// Convert rows to string slices
-	data := make([][]string, len(result.Rows))
-	for i, row := range result.Rows {
-		rowData := make([]string, len(cols))
-		for j, col := range cols {
-			val := row[col]
-			if val == nil {
-				rowData[j] = "NULL"
-			} else {
-				rowData[j] = convertValueToString(val)
-			}
-		}
-		data[i] = rowData
-	}
-
-	return &TableData{
-		Columns:   cols,
-		Rows:      data,
-		TotalRows: int64(len(data)),
-	}, nil
+	return data, nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rebelice/lazypg/internal/db/connection"
 	"github.com/rebelice/lazypg/internal/models"
 )
@@ -59,10 +58,8 @@ func GetIndexes(ctx context.Context, pool *connection.Pool, schema, table string
 
 		index.IsPartial = index.Predicate != ""
 
-		// Parse columns array
-		if colsArray, ok := row["columns"].(pgtype.Array[string]); ok {
-			index.Columns = colsArray.Elements
-		}
+		// Parse columns array (pgx decodes name[] as []interface{})
+		index.Columns = toStringSlice(row["columns"])
 
 		indexes = append(indexes, index)
 	}
@@ -0,0 +1,281 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rebelice/lazypg/internal/db/connection"
+)
+
+// TableStats holds the activity, maintenance and size statistics of a table.
+// Counters are cumulative since StatsReset.
+type TableStats struct {
+	LiveTuples           int64
+	DeadTuples           int64
+	ModifiedSinceAnalyze int64
+	SeqScans             int64
+	SeqTuplesRead        int64
+	IndexScans           int64
+	IndexTuplesFetched   int64
+	Inserts              int64
+	Updates              int64
+	HotUpdates           int64
+	Deletes              int64
+
+	// Maintenance; zero times mean never
+	LastVacuum       time.Time
+	LastAutovacuum   time.Time
+	LastAnalyze      time.Time
+	LastAutoanalyze  time.Time
+	VacuumCount      int64
+	AutovacuumCount  int64
+	AnalyzeCount     int64
+	AutoanalyzeCount int64
+
+	// Buffer cache
+	HeapBlocksRead  int64
+	HeapBlocksHit   int64
+	IndexBlocksRead int64
+	IndexBlocksHit  int64
+	ToastBlocksRead int64
+	ToastBlocksHit  int64
+
+	// Sizes in bytes
+	TableSize   int64
+	ToastSize   int64
+	IndexesSize int64
+	TotalSize   int64
+
+	StatsReset time.Time // Zero when the statistics were never reset
+}
+
+// IndexStats holds the usage statistics of an index
+type IndexStats struct {
+	Name          string
+	Method        string
+	Definition    string
+	IsUnique      bool
+	IsPrimary     bool
+	Scans         int64
+	TuplesRead    int64
+	TuplesFetched int64
+	BlocksRead    int64
+	BlocksHit     int64
+	Size          int64
+
+	// Signature used to compare indexes
+	KeyParts    []string // "attnum/opclass/option" for each key, then attnum for each INCLUDE column
+	KeyCount    int      // Number of key columns in KeyParts
+	Expressions string
+	Predicate   string
+
+	// Flags set by FlagIndexes
+	DuplicateOf string // An identical index
+	CoveredBy   string // A btree index whose leading keys are this one's
+	Unused      bool   // Never scanned and not enforcing uniqueness
+}
+
+// GetTableStats returns the statistics of a table, or nil when the server
+// keeps none for it, e.g. for a view or a partitioned parent
+func GetTableStats(ctx context.Context, pool *connection.Pool, schema, table string) (*TableStats, error) {
+	query := `
+		SELECT
+			s.n_live_tup, s.n_dead_tup, s.n_mod_since_analyze,
+			coalesce(s.seq_scan, 0) AS seq_scan,
+			coalesce(s.seq_tup_read, 0) AS seq_tup_read,
+			coalesce(s.idx_scan, 0) AS idx_scan,
+			coalesce(s.idx_tup_fetch, 0) AS idx_tup_fetch,
+			s.n_tup_ins, s.n_tup_upd, s.n_tup_hot_upd, s.n_tup_del,
+			s.last_vacuum, s.last_autovacuum, s.last_analyze, s.last_autoanalyze,
+			s.vacuum_count, s.autovacuum_count, s.analyze_count, s.autoanalyze_count,
+			coalesce(io.heap_blks_read, 0) AS heap_blks_read,
+			coalesce(io.heap_blks_hit, 0) AS heap_blks_hit,
+			coalesce(io.idx_blks_read, 0) AS idx_blks_read,
+			coalesce(io.idx_blks_hit, 0) AS idx_blks_hit,
+			coalesce(io.toast_blks_read, 0) AS toast_blks_read,
+			coalesce(io.toast_blks_hit, 0) AS toast_blks_hit,
+			pg_relation_size(s.relid) AS table_size,
+			coalesce(pg_total_relation_size(c.reltoastrelid), 0) AS toast_size,
+			pg_indexes_size(s.relid) AS indexes_size,
+			pg_total_relation_size(s.relid) AS total_size,
+			(SELECT stats_reset FROM pg_catalog.pg_stat_database WHERE datname = current_database()) AS stats_reset
+		FROM pg_catalog.pg_stat_user_tables s
+		JOIN pg_catalog.pg_statio_user_tables io ON io.relid = s.relid
+		JOIN pg_catalog.pg_class c ON c.oid = s.relid
+		WHERE s.schemaname = $1 AND s.relname = $2
+	`
+
+	rows, err := pool.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table statistics: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	row := rows[0]
+	return &TableStats{
+		LiveTuples:           toInt64(row["n_live_tup"]),
+		DeadTuples:           toInt64(row["n_dead_tup"]),
+		ModifiedSinceAnalyze: toInt64(row["n_mod_since_analyze"]),
+		SeqScans:             toInt64(row["seq_scan"]),
+		SeqTuplesRead:        toInt64(row["seq_tup_read"]),
+		IndexScans:           toInt64(row["idx_scan"]),
+		IndexTuplesFetched:   toInt64(row["idx_tup_fetch"]),
+		Inserts:              toInt64(row["n_tup_ins"]),
+		Updates:              toInt64(row["n_tup_upd"]),
+		HotUpdates:           toInt64(row["n_tup_hot_upd"]),
+		Deletes:              toInt64(row["n_tup_del"]),
+		LastVacuum:           toTime(row["last_vacuum"]),
+		LastAutovacuum:       toTime(row["last_autovacuum"]),
+		LastAnalyze:          toTime(row["last_analyze"]),
+		LastAutoanalyze:      toTime(row["last_autoanalyze"]),
+		VacuumCount:          toInt64(row["vacuum_count"]),
+		AutovacuumCount:      toInt64(row["autovacuum_count"]),
+		AnalyzeCount:         toInt64(row["analyze_count"]),
+		AutoanalyzeCount:     toInt64(row["autoanalyze_count"]),
+		HeapBlocksRead:       toInt64(row["heap_blks_read"]),
+		HeapBlocksHit:        toInt64(row["heap_blks_hit"]),
+		IndexBlocksRead:      toInt64(row["idx_blks_read"]),
+		IndexBlocksHit:       toInt64(row["idx_blks_hit"]),
+		ToastBlocksRead:      toInt64(row["toast_blks_read"]),
+		ToastBlocksHit:       toInt64(row["toast_blks_hit"]),
+		TableSize:            toInt64(row["table_size"]),
+		ToastSize:            toInt64(row["toast_size"]),
+		IndexesSize:          toInt64(row["indexes_size"]),
+		TotalSize:            toInt64(row["total_size"]),
+		StatsReset:           toTime(row["stats_reset"]),
+	}, nil
+}
+
+// GetIndexStats returns the usage statistics of the indexes of a table,
+// flagged by FlagIndexes
+func GetIndexStats(ctx context.Context, pool *connection.Pool, schema, table string) ([]IndexStats, error) {
+	query := `
+		SELECT
+			s.indexrelname AS index_name,
+			am.amname AS method,
+			pg_get_indexdef(s.indexrelid) AS definition,
+			i.indisunique AS is_unique,
+			i.indisprimary AS is_primary,
+			s.idx_scan, s.idx_tup_read, s.idx_tup_fetch,
+			coalesce(io.idx_blks_read, 0) AS idx_blks_read,
+			coalesce(io.idx_blks_hit, 0) AS idx_blks_hit,
+			pg_relation_size(s.indexrelid) AS size,
+			ARRAY(
+				SELECT k.attnum::text || coalesce('/' || i.indclass[k.pos - 1]::text || '/' || i.indoption[k.pos - 1]::text, '')
+				FROM unnest(i.indkey) WITH ORDINALITY AS k(attnum, pos)
+				ORDER BY k.pos
+			) AS key_parts,
+			i.indnkeyatts AS key_count,
+			coalesce(pg_get_expr(i.indexprs, i.indrelid), '') AS expressions,
+			coalesce(pg_get_expr(i.indpred, i.indrelid), '') AS predicate
+		FROM pg_catalog.pg_stat_user_indexes s
+		JOIN pg_catalog.pg_statio_user_indexes io ON io.indexrelid = s.indexrelid
+		JOIN pg_catalog.pg_index i ON i.indexrelid = s.indexrelid
+		JOIN pg_catalog.pg_class ic ON ic.oid = s.indexrelid
+		JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+		WHERE s.schemaname = $1 AND s.relname = $2
+		ORDER BY i.indisprimary DESC, i.indisunique DESC, s.indexrelname
+	`
+
+	rows, err := pool.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index statistics: %w", err)
+	}
+
+	indexes := make([]IndexStats, 0, len(rows))
+	for _, row := range rows {
+		indexes = append(indexes, IndexStats{
+			Name:          toString(row["index_name"]),
+			Method:        toString(row["method"]),
+			Definition:    toString(row["definition"]),
+			IsUnique:      toBool(row["is_unique"]),
+			IsPrimary:     toBool(row["is_primary"]),
+			Scans:         toInt64(row["idx_scan"]),
+			TuplesRead:    toInt64(row["idx_tup_read"]),
+			TuplesFetched: toInt64(row["idx_tup_fetch"]),
+			BlocksRead:    toInt64(row["idx_blks_read"]),
+			BlocksHit:     toInt64(row["idx_blks_hit"]),
+			Size:          toInt64(row["size"]),
+			KeyParts:      toStringSlice(row["key_parts"]),
+			KeyCount:      int(toInt64(row["key_count"])),
+			Expressions:   toString(row["expressions"]),
+			Predicate:     toString(row["predicate"]),
+		})
+	}
+
+	FlagIndexes(indexes)
+	return indexes, nil
+}
+
+// FlagIndexes marks indexes that are never used or that duplicate another.
+// An index is a duplicate when another has the same method, columns,
+// operator classes, expressions and predicate; the earliest in the list
+// (primary and unique indexes first) is kept. A non-unique btree index is
+// covered when its keys lead the keys of another btree index.
+func FlagIndexes(indexes []IndexStats) {
+	for i := range indexes {
+		idx := &indexes[i]
+		idx.DuplicateOf, idx.CoveredBy = "", ""
+		idx.Unused = idx.Scans == 0 && !idx.IsUnique && !idx.IsPrimary
+
+		for j := range indexes {
+			other := &indexes[j]
+			if i == j || idx.Method != other.Method || idx.Predicate != other.Predicate || idx.Expressions != other.Expressions {
+				continue
+			}
+			if j < i && idx.KeyCount == other.KeyCount && slices.Equal(idx.KeyParts, other.KeyParts) {
+				idx.DuplicateOf = other.Name
+				break
+			}
+			if idx.CoveredBy == "" && idx.Method == "btree" && !idx.IsUnique && idx.Expressions == "" &&
+				len(keyParts(*idx)) < len(keyParts(*other)) && slices.Equal(keyParts(*idx), keyParts(*other)[:len(keyParts(*idx))]) {
+				idx.CoveredBy = other.Name
+			}
+		}
+		if idx.DuplicateOf != "" {
+			idx.CoveredBy = ""
+		}
+	}
+}
+
+// keyParts returns the key columns of an index, without INCLUDE columns
+func keyParts(idx IndexStats) []string {
+	return idx.KeyParts[:min(idx.KeyCount, len(idx.KeyParts))]
+}
+
+// HitRatio returns the share of block reads served from the buffer cache,
+// and false when no blocks were read at all
+func HitRatio(hit, read int64) (float64, bool) {
+	if hit+read == 0 {
+		return 0, false
+	}
+	return float64(hit) / float64(hit+read), true
+}
+
+// IndexFlags describes the flags FlagIndexes set on an index
+func IndexFlags(idx IndexStats) string {
+	var flags []string
+	if idx.Unused {
+		flags = append(flags, "unused")
+	}
+	if idx.DuplicateOf != "" {
+		flags = append(flags, "duplicate of "+idx.DuplicateOf)
+	}
+	if idx.CoveredBy != "" {
+		flags = append(flags, "covered by "+idx.CoveredBy)
+	}
+	return strings.Join(flags, ", ")
+}
+
+// toTime converts a timestamp from the database, or returns the zero time
+func toTime(v interface{}) time.Time {
+	if t, ok := v.(time.Time); ok {
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,44 @@
+package metadata
+
+import "testing"
+
+func TestFlagIndexes(t *testing.T) {
+	indexes := []IndexStats{
+		{Name: "orders_pkey", Method: "btree", IsPrimary: true, IsUnique: true, KeyParts: []string{"1/3124/0"}, KeyCount: 1},
+		{Name: "orders_customer_created", Method: "btree", Scans: 10, KeyParts: []string{"2/3124/0", "3/3124/0"}, KeyCount: 2},
+		{Name: "orders_customer", Method: "btree", Scans: 5, KeyParts: []string{"2/3124/0"}, KeyCount: 1},
+		{Name: "orders_id_copy", Method: "btree", Scans: 1, KeyParts: []string{"1/3124/0"}, KeyCount: 1},
+		{Name: "orders_customer_desc", Method: "btree", Scans: 1, KeyParts: []string{"2/3124/3"}, KeyCount: 1},
+		{Name: "orders_customer_incl", Method: "btree", Scans: 1, KeyParts: []string{"2/3124/0", "4"}, KeyCount: 1},
+		{Name: "orders_customer_hash", Method: "hash", KeyParts: []string{"2/1977/0"}, KeyCount: 1},
+		{Name: "orders_open", Method: "btree", Scans: 2, KeyParts: []string{"2/3124/0"}, KeyCount: 1, Predicate: "(status = 'open'::text)"},
+		{Name: "orders_unique_ref", Method: "btree", IsUnique: true, KeyParts: []string{"5/3124/0"}, KeyCount: 1},
+	}
+	FlagIndexes(indexes)
+
+	expected := map[string]string{
+		"orders_pkey":             "",
+		"orders_customer_created": "",
+		"orders_customer":         "covered by orders_customer_created",
+		"orders_id_copy":          "duplicate of orders_pkey",
+		"orders_customer_desc":    "",
+		"orders_customer_incl":    "covered by orders_customer_created",
+		"orders_customer_hash":    "unused",
+		"orders_open":             "",
+		"orders_unique_ref":       "",
+	}
+	for _, idx := range indexes {
+		if got := IndexFlags(idx); got != expected[idx.Name] {
+			t.Errorf("%s: expected %q, got %q", idx.Name, expected[idx.Name], got)
+		}
+	}
+}
+
+func TestHitRatio(t *testing.T) {
+	if _, ok := HitRatio(0, 0); ok {
+		t.Error("expected no ratio without reads")
+	}
+	if ratio, ok := HitRatio(99, 1); !ok || ratio != 0.99 {
+		t.Errorf("expected 0.99, got %v %v", ratio, ok)
+	}
+}
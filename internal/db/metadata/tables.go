@@ -67,6 +67,22 @@ func ListSchemas(ctx context.Context, pool *connection.Pool) ([]Schema, error) {
 	return schemas, nil
 }
 
+// ListSearchPath returns the schemas of the search path that exist, in
+// the order unqualified names are looked up
+func ListSearchPath(ctx context.Context, pool *connection.Pool) ([]string, error) {
+	rows, err := pool.Query(ctx, "SELECT unnest(current_schemas(false)) AS name")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]string, 0, len(rows))
+	for _, row := range rows {
+		schemas = append(schemas, toString(row["name"]))
+	}
+
+	return schemas, nil
+}
+
 // ListTables returns all tables in a schema
 func ListTables(ctx context.Context, pool *connection.Pool, schema string) ([]Table, error) {
 	query := `
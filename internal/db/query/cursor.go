@@ -0,0 +1,202 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// DefaultFetchSize is the number of rows fetched per page of a streamed result
+const DefaultFetchSize = 500
+
+// cursorName is the name of the server-side cursor; each cursor has its own
+// connection so the name never clashes
+const cursorName = "lazypg_results"
+
+// Cursor streams the rows of a query through a server-side cursor. It holds
+// a pooled connection and an open transaction until it is exhausted or closed.
+type Cursor struct {
+	mu     sync.Mutex
+	conn   *pgxpool.Conn
+	tx     pgx.Tx
+	done   bool
+	failed bool // The transaction is aborted and must be rolled back
+	closed bool
+}
+
+// Stream runs a query and returns its first page of rows. When more rows may
+// follow, the returned cursor fetches them. Statements that cannot run in a
+// cursor are executed in full and return a nil cursor.
+func Stream(ctx context.Context, pool *pgxpool.Pool, sql string, fetchSize int, args ...any) (models.QueryResult, *Cursor) {
+	if !Streamable(sql) {
+		return Execute(ctx, pool, sql, args...), nil
+	}
+
+	start := time.Now()
+	fail := func(err error) (models.QueryResult, *Cursor) {
+		return models.QueryResult{Error: err, Duration: time.Since(start)}, nil
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		conn.Release()
+		return fail(err)
+	}
+	c := &Cursor{conn: conn, tx: tx}
+	defer track(ctx, conn.Conn())()
+	abort := func(err error) (models.QueryResult, *Cursor) {
+		c.failed = true
+		_ = c.Close()
+		return fail(err)
+	}
+
+	// The timeout applies to the declaration and every fetch
+	if set := timeoutSQL(ctx, true); set != "" {
+		if _, err := tx.Exec(ctx, set); err != nil {
+			return abort(err)
+		}
+	}
+
+	statement := strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	if _, err := tx.Exec(ctx, "DECLARE "+cursorName+" NO SCROLL CURSOR FOR "+statement, args...); err != nil {
+		return abort(err)
+	}
+
+	columns, rows, cells, err := c.fetch(ctx, fetchSize)
+	if err != nil {
+		return abort(err)
+	}
+
+	result := models.QueryResult{
+		Columns:      columns,
+		Rows:         rows,
+		Cells:        cells,
+		RowsAffected: int64(len(rows)),
+		Duration:     time.Since(start),
+	}
+	if c.done {
+		_ = c.Close()
+		return result, nil
+	}
+	return result, c
+}
+
+// Fetch returns the display text and typed cells of the next n rows. After
+// an error or once the last row has been read the cursor is closed and Done
+// reports true.
+func (c *Cursor) Fetch(ctx context.Context, n int) ([][]string, [][]models.Cell, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done || c.closed {
+		return nil, nil, nil
+	}
+
+	_, rows, cells, err := c.fetch(ctx, n)
+	if err != nil {
+		// The transaction is aborted, so the cursor cannot continue
+		c.done = true
+		c.failed = true
+	}
+	if c.done {
+		_ = c.release()
+	}
+	return rows, cells, err
+}
+
+// fetch reads the next n rows from the cursor
+func (c *Cursor) fetch(ctx context.Context, n int) ([]string, [][]string, [][]models.Cell, error) {
+	rows, err := c.tx.Query(ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, cursorName))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, result, cells, err := readRows(rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(result) < n {
+		c.done = true
+	}
+	return columns, result, cells, nil
+}
+
+// Done reports whether all rows have been fetched or the cursor was closed
+func (c *Cursor) Done() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done || c.closed
+}
+
+// Close ends the transaction and returns the connection to the pool. It
+// waits for a running Fetch to finish.
+func (c *Cursor) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.release()
+}
+
+// release closes the cursor; callers must hold the lock
+func (c *Cursor) release() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// If the connection is broken the pool discards it on release
+	err := endCursorTx(ctx, c.tx, c.failed)
+	c.conn.Release()
+	return err
+}
+
+// endCursorTx ends the transaction of a cursor. It is committed unless it
+// failed: a query can have side effects, e.g. nextval() or a volatile
+// function that writes, and those must be kept as they are when the query
+// runs without a cursor.
+func endCursorTx(ctx context.Context, tx pgx.Tx, failed bool) error {
+	if failed {
+		return tx.Rollback(ctx)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return nil
+}
+
+// streamableKeywords start statements that can be declared as a cursor
+var streamableKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true,
+}
+
+// Streamable reports whether a statement can run through a cursor: a single
+// read-only query that does not create a table with SELECT INTO
+func Streamable(sql string) bool {
+	words := sqlWords(sql)
+	for len(words) > 0 && words[len(words)-1] == ";" {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 || !streamableKeywords[strings.ToUpper(words[0])] {
+		return false
+	}
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if word == ";" || upper == "INTO" || dataModifyingKeywords[upper] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeTx records how a transaction was ended
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = tx.commitErr == nil
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+func TestEndCursorTx(t *testing.T) {
+	ctx := context.Background()
+
+	// Side effects of a streamed query are kept
+	tx := &fakeTx{}
+	if err := endCursorTx(ctx, tx, false); err != nil || !tx.committed || tx.rolledBack {
+		t.Errorf("expected a commit, got %+v (%v)", tx, err)
+	}
+
+	tx = &fakeTx{}
+	if err := endCursorTx(ctx, tx, true); err != nil || tx.committed || !tx.rolledBack {
+		t.Errorf("expected a rollback after a failure, got %+v (%v)", tx, err)
+	}
+
+	tx = &fakeTx{commitErr: errors.New("connection lost")}
+	if err := endCursorTx(ctx, tx, false); err == nil || !tx.rolledBack {
+		t.Errorf("expected a failed commit to be reported and rolled back, got %+v (%v)", tx, err)
+	}
+}
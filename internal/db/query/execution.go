@@ -0,0 +1,122 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Execution is a statement or script run from the editor. It records the
+// connection that runs it, so that it can be cancelled on the server, and the
+// statement_timeout it runs with. It travels with the statement's context.
+type Execution struct {
+	Timeout time.Duration // statement_timeout; zero keeps the server's setting
+
+	started         time.Time
+	mu              sync.Mutex
+	conn            *pgconn.PgConn // Connection running a statement, nil in between
+	cancelRequested atomic.Bool
+}
+
+type executionKey struct{}
+
+// NewExecution starts tracking an execution
+func NewExecution(timeout time.Duration) *Execution {
+	return &Execution{Timeout: timeout, started: time.Now()}
+}
+
+// WithExecution returns a context that carries the execution
+func WithExecution(ctx context.Context, e *Execution) context.Context {
+	return context.WithValue(ctx, executionKey{}, e)
+}
+
+// executionFrom returns the execution carried by ctx, or nil
+func executionFrom(ctx context.Context) *Execution {
+	e, _ := ctx.Value(executionKey{}).(*Execution)
+	return e
+}
+
+// track records conn as running the execution carried by ctx, until the
+// returned function is called. The function waits for a cancel request being
+// sent, so the connection cannot be released and reused meanwhile.
+func track(ctx context.Context, conn *pgx.Conn) func() {
+	e := executionFrom(ctx)
+	if e == nil {
+		return func() {}
+	}
+	e.mu.Lock()
+	e.conn = conn.PgConn()
+	e.mu.Unlock()
+	return func() {
+		e.mu.Lock()
+		e.conn = nil
+		e.mu.Unlock()
+	}
+}
+
+// PID returns the process ID of the backend running the execution, or 0
+// when no statement is running on the server
+func (e *Execution) PID() uint32 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return 0
+	}
+	return e.conn.PID()
+}
+
+// Elapsed returns how long the execution has been running
+func (e *Execution) Elapsed() time.Duration {
+	return time.Since(e.started)
+}
+
+// CancelRequested reports whether Cancel was called
+func (e *Execution) CancelRequested() bool {
+	return e.cancelRequested.Load()
+}
+
+// Cancel sends a cancel request for the connection running the statement,
+// like Ctrl+C in psql. The request names the backend together with the
+// connection's secret key, so it cannot reach another session. It reports
+// whether the request was sent; false means no statement was running, e.g.
+// because it just finished.
+func (e *Execution) Cancel(ctx context.Context) (bool, error) {
+	e.cancelRequested.Store(true)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return false, nil
+	}
+	if err := e.conn.CancelRequest(ctx); err != nil {
+		return false, fmt.Errorf("failed to cancel backend %d: %w", e.conn.PID(), err)
+	}
+	return true, nil
+}
+
+// IsCanceled reports whether err is the server's error for a statement that
+// was cancelled, by request or by statement_timeout
+func IsCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
+
+// timeoutSQL returns the SET statement that applies the timeout of the
+// execution carried by ctx, or "" when there is none. local limits it to
+// the current transaction.
+func timeoutSQL(ctx context.Context, local bool) string {
+	e := executionFrom(ctx)
+	if e == nil || e.Timeout <= 0 {
+		return ""
+	}
+	scope := ""
+	if local {
+		scope = "LOCAL "
+	}
+	return fmt.Sprintf("SET %sstatement_timeout = %d", scope, e.Timeout.Milliseconds())
+}
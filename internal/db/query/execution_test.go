@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTimeoutSQL(t *testing.T) {
+	ctx := context.Background()
+	if got := timeoutSQL(ctx, false); got != "" {
+		t.Errorf("without an execution: %q", got)
+	}
+	if got := timeoutSQL(WithExecution(ctx, NewExecution(0)), false); got != "" {
+		t.Errorf("without a timeout: %q", got)
+	}
+
+	ctx = WithExecution(ctx, NewExecution(1500*time.Millisecond))
+	if got, want := timeoutSQL(ctx, false), "SET statement_timeout = 1500"; got != want {
+		t.Errorf("session: got %q, want %q", got, want)
+	}
+	if got, want := timeoutSQL(ctx, true), "SET LOCAL statement_timeout = 1500"; got != want {
+		t.Errorf("local: got %q, want %q", got, want)
+	}
+}
+
+func TestIsCanceled(t *testing.T) {
+	canceled := &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}
+	if !IsCanceled(fmt.Errorf("wrapped: %w", canceled)) {
+		t.Error("expected a wrapped 57014 error to be a cancellation")
+	}
+	if IsCanceled(&pgconn.PgError{Code: "42P01"}) || IsCanceled(errors.New("57014")) {
+		t.Error("unexpected cancellation")
+	}
+}
+
+func TestCancelWithoutStatement(t *testing.T) {
+	e := NewExecution(0)
+	sent, err := e.Cancel(context.Background())
+	if sent || err != nil {
+		t.Errorf("expected nothing to cancel, got %v (%v)", sent, err)
+	}
+	if !e.CancelRequested() || e.PID() != 0 {
+		t.Error("expected the cancel to be recorded without a backend")
+	}
+}
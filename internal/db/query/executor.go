@@ -2,87 +2,101 @@ package query
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/values"
 )
 
-// Execute executes a SQL query and returns the results
-func Execute(ctx context.Context, pool *pgxpool.Pool, sql string) models.QueryResult {
-	start := time.Now()
+// querier runs queries: a pool, or a connection pinned for a session
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
 
-	rows, err := pool.Query(ctx, sql)
+// Execute executes a SQL query with optional bind arguments and returns the
+// results. The statement_timeout of an Execution carried by ctx applies to
+// it alone.
+func Execute(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) models.QueryResult {
+	conn, err := pool.Acquire(ctx)
 	if err != nil {
-		return models.QueryResult{
-			Error:    err,
-			Duration: time.Since(start),
-		}
+		return models.QueryResult{Error: err}
 	}
-	defer rows.Close()
+	defer conn.Release()
 
-	// Get column names
-	fieldDescs := rows.FieldDescriptions()
-	columns := make([]string, len(fieldDescs))
-	for i, fd := range fieldDescs {
-		columns[i] = string(fd.Name)
+	if set := timeoutSQL(ctx, false); set != "" {
+		if _, err := conn.Exec(ctx, set); err != nil {
+			return models.QueryResult{Error: err}
+		}
+		defer func() {
+			resetCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, _ = conn.Exec(resetCtx, "RESET statement_timeout")
+		}()
 	}
 
-	// Get rows
-	var result [][]string
-	for rows.Next() {
-		values, err := rows.Values()
-		if err != nil {
-			return models.QueryResult{
-				Error:    err,
-				Duration: time.Since(start),
-			}
-		}
+	defer track(ctx, conn.Conn())()
+	return run(ctx, conn, sql, args...)
+}
+
+// run executes a statement on q and reads all of its rows
+func run(ctx context.Context, q querier, sql string, args ...any) models.QueryResult {
+	start := time.Now()
 
-		row := make([]string, len(values))
-		for i, v := range values {
-			if v == nil {
-				row[i] = "NULL"
-			} else {
-				row[i] = convertValueToString(v)
-			}
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return models.QueryResult{
+			Error:    err,
+			Duration: time.Since(start),
 		}
-		result = append(result, row)
 	}
+	defer rows.Close()
 
-	// Check for errors from iteration
-	if err := rows.Err(); err != nil {
+	columns, result, cells, err := readRows(rows)
+	if err != nil {
 		return models.QueryResult{
 			Error:    err,
 			Duration: time.Since(start),
 		}
 	}
 
+	// Rows returned, or rows changed by statements without a result
+	tag := rows.CommandTag()
+	affected := int64(len(result))
+	if len(columns) == 0 {
+		affected = tag.RowsAffected()
+	}
 	return models.QueryResult{
 		Columns:      columns,
 		Rows:         result,
-		RowsAffected: int64(len(result)),
+		Cells:        cells,
+		RowsAffected: affected,
+		Command:      tag.String(),
 		Duration:     time.Since(start),
 	}
 }
 
-// convertValueToString converts a database value to string, handling JSONB properly
-func convertValueToString(val interface{}) string {
-	// Check if it's a map or slice (JSONB types)
-	switch v := val.(type) {
-	case map[string]interface{}, []interface{}:
-		// Convert to JSON string
-		jsonBytes, err := json.Marshal(v)
+// readRows reads the column names and all rows of a result as typed cells
+// and their display text
+func readRows(rows pgx.Rows) ([]string, [][]string, [][]models.Cell, error) {
+	columns, oids := values.Columns(rows)
+
+	var result [][]string
+	var cells [][]models.Cell
+	for rows.Next() {
+		row, err := values.ReadRow(rows, oids)
 		if err != nil {
-			return fmt.Sprintf("%v", val)
+			return nil, nil, nil, err
 		}
-		return string(jsonBytes)
-	case []byte:
-		// Might be raw JSON bytes
-		return string(v)
-	default:
-		return fmt.Sprintf("%v", val)
+		cells = append(cells, row)
+		result = append(result, values.FormatRow(row))
+	}
+
+	// Check for errors from iteration
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
 	}
+
+	return columns, result, cells, nil
 }
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rebelice/lazypg/internal/explain"
+)
+
+// Explain runs EXPLAIN (FORMAT JSON) for a statement and parses the plan.
+// With analyze the statement is executed, always inside a transaction that
+// is rolled back so data-modifying statements leave no changes behind.
+// The Execution of ctx gives it a statement_timeout and a cancel request.
+func Explain(ctx context.Context, pool *pgxpool.Pool, sql string, analyze bool, args ...any) (*explain.Plan, error) {
+	sql = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if sql == "" {
+		return nil, fmt.Errorf("no statement to explain")
+	}
+
+	// VERBOSE makes the plan name the schema of every relation
+	options := "VERBOSE, FORMAT JSON"
+	if analyze {
+		options = "ANALYZE, VERBOSE, BUFFERS, FORMAT JSON"
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Never commit: ANALYZE really executes the statement
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if set := timeoutSQL(ctx, true); set != "" {
+		if _, err := tx.Exec(ctx, set); err != nil {
+			return nil, err
+		}
+	}
+
+	var output string
+	untrack := track(ctx, tx.Conn())
+	err = tx.QueryRow(ctx, fmt.Sprintf("EXPLAIN (%s) %s", options, sql), args...).Scan(&output)
+	untrack()
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := explain.Parse([]byte(output))
+	if err != nil {
+		return nil, err
+	}
+	plan.SQL = sql
+	plan.RolledBack = analyze && IsDataModifying(sql)
+
+	// Look up table sizes so sequential scans can be judged by table size
+	// rather than by the rows they return
+	if relations := plan.SeqScanRelations(); len(relations) > 0 {
+		sizes := make(map[string]float64, len(relations))
+		for _, relation := range relations {
+			var rows float64
+			err := tx.QueryRow(ctx, relationRowsSQL, relation.Schema, relation.Name).Scan(&rows)
+			if err == nil && rows >= 0 {
+				sizes[relation.Key()] = rows
+			}
+		}
+		plan.SetTableRows(sizes)
+	}
+
+	return plan, nil
+}
+
+// relationRowsSQL returns the estimated row count of a relation, or -1. The
+// name is schema qualified when the schema is known so the search_path does
+// not pick a table of the same name in another schema.
+const relationRowsSQL = `SELECT COALESCE((
+	SELECT reltuples::float8 FROM pg_catalog.pg_class
+	WHERE oid = to_regclass(CASE WHEN $1::text = '' THEN '' ELSE quote_ident($1::text) || '.' END || quote_ident($2::text))
+), -1)`
+
+// dataModifyingKeywords are statements that change data when executed
+var dataModifyingKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true,
+	"TRUNCATE": true, "CREATE": true, "DROP": true, "ALTER": true,
+	"REFRESH": true, "COPY": true,
+}
+
+// IsDataModifying reports whether a statement contains a data-modifying
+// keyword outside of string literals, quoted identifiers and comments
+func IsDataModifying(sql string) bool {
+	for _, word := range sqlWords(sql) {
+		if dataModifyingKeywords[strings.ToUpper(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlWords returns the bare words and semicolons of a statement, skipping
+// literals, quoted identifiers and comments
+func sqlWords(sql string) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			flush()
+			// Skip to the closing quote (doubled quotes are escapes)
+			for i++; i < len(sql); i++ {
+				if sql[i] == ch {
+					if i+1 < len(sql) && sql[i+1] == ch {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			flush()
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			flush()
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 3
+		case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9':
+			current.WriteByte(ch)
+		case ch == ';':
+			// Statement separators are kept as words of their own
+			flush()
+			words = append(words, ";")
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,63 @@
+package query
+
+import "testing"
+
+func TestIsDataModifying(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT * FROM users":                                false,
+		"select 'delete from x' as note":                     false,
+		`SELECT "update" FROM audit`:                         false,
+		"SELECT 1 -- drop table users\n":                     false,
+		"SELECT /* insert */ 1":                              false,
+		"UPDATE users SET name = 'x'":                        true,
+		"with moved as (delete from a returning *) select 1": true,
+		"INSERT INTO t SELECT * FROM s":                      true,
+		"SELECT updated_at, deleted FROM users":              false,
+		"CREATE TABLE copy AS SELECT * FROM users":           true,
+	}
+	for sql, expected := range tests {
+		if got := IsDataModifying(sql); got != expected {
+			t.Errorf("IsDataModifying(%q) = %v, expected %v", sql, got, expected)
+		}
+	}
+}
+
+func TestStreamable(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT * FROM events":                           true,
+		"  select 1;  ":                                  true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":           true,
+		"VALUES (1), (2)":                                true,
+		"(SELECT 1) UNION (SELECT 2)":                    true,
+		"SELECT ';' AS semi":                             true,
+		"SELECT 1; SELECT 2":                             false,
+		"SELECT * INTO backup FROM users":                false,
+		"WITH d AS (DELETE FROM t RETURNING *) SELECT 1": false,
+		"UPDATE t SET x = 1":                             false,
+		"SHOW search_path":                               false,
+		"-- comment\nSELECT 1":                           true,
+	}
+	for sql, expected := range tests {
+		if got := Streamable(sql); got != expected {
+			t.Errorf("Streamable(%q) = %v, expected %v", sql, got, expected)
+		}
+	}
+}
+
+func TestIsTransactionStart(t *testing.T) {
+	tests := map[string]bool{
+		"BEGIN":                              true,
+		"begin;":                             true,
+		"BEGIN ISOLATION LEVEL SERIALIZABLE": true,
+		"START TRANSACTION READ ONLY":        true,
+		"-- open\nbegin":                     true,
+		"START":                              false,
+		"SELECT 'BEGIN'":                     false,
+		"COMMIT":                             false,
+	}
+	for sql, expected := range tests {
+		if got := IsTransactionStart(sql); got != expected {
+			t.Errorf("IsTransactionStart(%q) = %v, expected %v", sql, got, expected)
+		}
+	}
+}
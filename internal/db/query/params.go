@@ -0,0 +1,167 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// placeholder is a bind variable found in a statement
+type placeholder struct {
+	text  string // As written, e.g. ":user_id" or "$1"
+	start int
+	end   int
+}
+
+// findPlaceholders returns the bind variables of sql outside string literals,
+// quoted identifiers, dollar-quoted bodies and comments. Named variables
+// start with a colon; casts (::), assignments (:=) and array slices are not
+// variables.
+func findPlaceholders(sql string) []placeholder {
+	var found []placeholder
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			i = skipBlockComment(sql, i)
+		case ch == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') &&
+				(i < 2 || !isIdentByte(sql[i-2]))
+			i = skipQuoted(sql, i, '\'', escapes)
+		case ch == '"':
+			i = skipQuoted(sql, i, '"', false)
+		case ch == '$':
+			if tag := dollarTag(sql, i); tag != "" {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					return found
+				}
+				i += len(tag) + end + len(tag) - 1
+				continue
+			}
+			if i > 0 && isIdentByte(sql[i-1]) {
+				continue
+			}
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				found = append(found, placeholder{text: sql[i:j], start: i, end: j})
+				i = j - 1
+			}
+		case ch == ':':
+			if i+1 < len(sql) && sql[i+1] == ':' {
+				i++
+				continue
+			}
+			if i > 0 && (isIdentByte(sql[i-1]) || sql[i-1] == '[' || sql[i-1] == ':') {
+				continue
+			}
+			j := i + 1
+			if j >= len(sql) || !(sql[j] == '_' || sql[j] >= 'a' && sql[j] <= 'z' || sql[j] >= 'A' && sql[j] <= 'Z' || sql[j] >= 0x80) {
+				continue
+			}
+			for j < len(sql) && isIdentByte(sql[j]) && sql[j] != '$' {
+				j++
+			}
+			found = append(found, placeholder{text: sql[i:j], start: i, end: j})
+			i = j - 1
+		}
+	}
+	return found
+}
+
+// Placeholders returns the distinct bind variables of sql in order of first
+// appearance, e.g. [":user_id" "$1"]
+func Placeholders(sql string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range findPlaceholders(sql) {
+		if !seen[p.text] {
+			seen[p.text] = true
+			names = append(names, p.text)
+		}
+	}
+	return names
+}
+
+// Bind rewrites the named bind variables of sql as positional parameters
+// numbered after any $n already used, and returns the arguments in parameter
+// order. Values are sent as text so the server infers their types.
+func Bind(sql string, values models.BindValues) (string, []any, error) {
+	found := findPlaceholders(sql)
+	if len(found) == 0 {
+		return sql, nil, nil
+	}
+
+	positional := 0
+	for _, p := range found {
+		if p.text[0] == '$' {
+			n, err := strconv.Atoi(p.text[1:])
+			if err != nil || n < 1 {
+				return "", nil, fmt.Errorf("invalid parameter %s", p.text)
+			}
+			positional = max(positional, n)
+		}
+	}
+
+	args := make([]any, positional)
+	numbers := make(map[string]int)
+	var b strings.Builder
+	last := 0
+	for _, p := range found {
+		value, ok := values[p.text]
+		if !ok {
+			return "", nil, fmt.Errorf("no value for %s", p.text)
+		}
+		if p.text[0] == '$' {
+			n, _ := strconv.Atoi(p.text[1:])
+			args[n-1] = bindArg(value)
+			continue
+		}
+		n, ok := numbers[p.text]
+		if !ok {
+			args = append(args, bindArg(value))
+			n = len(args)
+			numbers[p.text] = n
+		}
+		b.WriteString(sql[last:p.start])
+		b.WriteString("$" + strconv.Itoa(n))
+		last = p.end
+	}
+	b.WriteString(sql[last:])
+	return b.String(), args, nil
+}
+
+// bindArg returns the driver argument for a bind value
+func bindArg(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// DescribeBinds lists the values bound to the variables of sql, e.g.
+// ":user_id = '123', $2 = NULL", or "" when it has none
+func DescribeBinds(sql string, values models.BindValues) string {
+	var parts []string
+	for _, name := range Placeholders(sql) {
+		value, ok := values[name]
+		switch {
+		case !ok:
+			continue
+		case value == nil:
+			parts = append(parts, name+" = NULL")
+		default:
+			parts = append(parts, name+" = '"+strings.ReplaceAll(*value, "'", "''")+"'")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"SELECT * FROM users WHERE id = :user_id AND org = :org OR id = :user_id", []string{":user_id", ":org"}},
+		{"SELECT $2, $1, $2", []string{"$2", "$1"}},
+		{"SELECT x::int, a[1:2], a[:hi], ts::timestamptz FROM t", nil},
+		{"SELECT ':no', \":no\", $$ :no $1 $$, $f$ :no $f$ -- :no\n/* :no */ FROM t WHERE a=:yes", []string{":yes"}},
+		{"DO $$ BEGIN x := 1; END $$", nil},
+		{"SELECT a$1, E'\\' :no', :Mixed_1", []string{":Mixed_1"}},
+	}
+	for _, tt := range tests {
+		if got := Placeholders(tt.sql); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Placeholders(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	one, two := "1", "it's"
+	values := models.BindValues{":a": &one, "$2": &two, ":b": nil}
+
+	sql, args, err := Bind("SELECT :a, $2, :b, :a, ':a'", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT $3, $2, $4, $3, ':a'"; sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if want := []any{nil, "it's", "1", nil}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %#v, want %#v", args, want)
+	}
+
+	if _, _, err := Bind("SELECT :missing", values); err == nil {
+		t.Error("expected an error for a variable without a value")
+	}
+	if sql, args, _ := Bind("SELECT 1", nil); sql != "SELECT 1" || args != nil {
+		t.Errorf("Bind without variables = %q, %v", sql, args)
+	}
+
+	if got, want := DescribeBinds("SELECT :a, $2, :b", values), ":a = '1', $2 = 'it''s', :b = NULL"; got != want {
+		t.Errorf("DescribeBinds() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rebelice/lazypg/internal/db/connection"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// Statement is one statement of a script
+type Statement struct {
+	SQL   string // Text without surrounding whitespace and the semicolon
+	Start int    // Byte offset where the statement begins in the script
+	End   int    // Byte offset just past the statement and its semicolon
+}
+
+// SplitScript splits a script into statements at semicolons outside string
+// literals, quoted identifiers, dollar-quoted bodies and comments.
+// Statements that are empty or only hold comments are dropped.
+func SplitScript(script string) []Statement {
+	var statements []Statement
+	start := 0
+	hasCode := false // The statement has more than whitespace and comments
+
+	add := func(end, next int) {
+		if hasCode {
+			statements = append(statements, Statement{
+				SQL:   strings.TrimSpace(script[start:end]),
+				Start: start,
+				End:   next,
+			})
+		}
+		start = next
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(script) && script[i+1] == '*':
+			i = skipBlockComment(script, i)
+		case ch == '\'':
+			hasCode = true
+			// E'...' strings allow backslash escapes
+			escapes := i > 0 && (script[i-1] == 'E' || script[i-1] == 'e') &&
+				(i < 2 || !isIdentByte(script[i-2]))
+			i = skipQuoted(script, i, '\'', escapes)
+		case ch == '"':
+			hasCode = true
+			i = skipQuoted(script, i, '"', false)
+		case ch == '$':
+			hasCode = true
+			if tag := dollarTag(script, i); tag != "" {
+				end := strings.Index(script[i+len(tag):], tag)
+				if end < 0 {
+					i = len(script)
+				} else {
+					i += len(tag) + end + len(tag) - 1
+				}
+			}
+		case ch == ';':
+			add(i, i+1)
+		case ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' && ch != '\f' && ch != '\v':
+			hasCode = true
+		}
+	}
+	add(len(script), len(script))
+
+	return statements
+}
+
+// skipBlockComment returns the offset of the end of the (possibly nested)
+// block comment starting at i
+func skipBlockComment(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '/' && i+1 < len(s) && s[i+1] == '*':
+			depth++
+			i++
+		case s[i] == '*' && i+1 < len(s) && s[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// skipQuoted returns the offset of the quote closing the literal or quoted
+// identifier starting at i. A doubled quote is an escaped quote.
+func skipQuoted(s string, i int, quote byte, backslashEscapes bool) int {
+	for i++; i < len(s); i++ {
+		switch {
+		case backslashEscapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(s)
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string at i, such as
+// $$ or $body$, or "" when the $ is not one (e.g. a $1 parameter)
+func dollarTag(s string, i int) string {
+	if i > 0 && isIdentByte(s[i-1]) {
+		return ""
+	}
+	j := i + 1
+	if j < len(s) && (s[j] == '_' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= 0x80) {
+		for j < len(s) && s[j] != '$' && isIdentByte(s[j]) {
+			j++
+		}
+	}
+	if j < len(s) && s[j] == '$' {
+		return s[i : j+1]
+	}
+	return ""
+}
+
+// isIdentByte reports whether c can be part of an unquoted identifier
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' || c >= 0x80
+}
+
+// Session pins one pooled connection so that the statements of a script
+// share session state such as SET and temporary tables
+type Session struct {
+	conn         *pgxpool.Conn
+	txStarted    time.Time // When a transaction opened by the script began
+	txStatements int       // Statements run in that transaction
+	resetTimeout bool      // statement_timeout was set for the script
+}
+
+// NewSession takes a connection out of the pool for a script. The
+// statement_timeout of an Execution carried by ctx applies to each statement
+// unless the script sets its own.
+func NewSession(ctx context.Context, pool *connection.Pool) (*Session, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{conn: conn}
+	if set := timeoutSQL(ctx, false); set != "" {
+		if _, err := conn.Exec(ctx, set); err != nil {
+			conn.Release()
+			return nil, err
+		}
+		s.resetTimeout = true
+	}
+	return s, nil
+}
+
+// Execute runs a statement in the session
+func (s *Session) Execute(ctx context.Context, sql string, args ...any) models.QueryResult {
+	start := time.Now()
+	untrack := track(ctx, s.conn.Conn())
+	result := run(ctx, s.conn, sql, args...)
+	untrack()
+
+	if s.conn.Conn().IsClosed() || s.conn.Conn().PgConn().TxStatus() == 'I' {
+		s.txStarted = time.Time{}
+		s.txStatements = 0
+	} else if s.txStarted.IsZero() {
+		s.txStarted = start
+	} else {
+		s.txStatements++
+	}
+	return result
+}
+
+// Close ends the session. A transaction the script left open is handed over
+// as a Transaction on the same connection; otherwise the connection returns
+// to the pool and Close returns nil.
+func (s *Session) Close() *Transaction {
+	if !s.conn.Conn().IsClosed() {
+		if status := s.conn.Conn().PgConn().TxStatus(); status != 'I' {
+			tx := &Transaction{conn: s.conn, started: s.txStarted, resetTimeout: s.resetTimeout}
+			tx.statements.Store(int32(s.txStatements))
+			tx.failed.Store(status == 'E')
+			return tx
+		}
+		if s.resetTimeout {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, _ = s.conn.Exec(ctx, "RESET statement_timeout")
+			cancel()
+		}
+	}
+	s.conn.Release()
+	return nil
+}
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		script string
+		want   []string
+	}{
+		{"SELECT 1; SELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"SELECT 1;\n\n  ;  SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"SELECT 'a;b', \"c;d\"; SELECT 'it''s;'", []string{`SELECT 'a;b', "c;d"`, "SELECT 'it''s;'"}},
+		{`SELECT E'\';'; SELECT 2`, []string{`SELECT E'\';'`, "SELECT 2"}},
+		{"SELECT 1 -- ; not here\n; SELECT 2", []string{"SELECT 1 -- ; not here", "SELECT 2"}},
+		{"SELECT /* ; /* nested; */ ; */ 1; SELECT 2", []string{"SELECT /* ; /* nested; */ ; */ 1", "SELECT 2"}},
+		{
+			"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql; SELECT f()",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT f()"},
+		},
+		{"DO $body$ BEGIN PERFORM 1; END $body$; SELECT $1::int", []string{"DO $body$ BEGIN PERFORM 1; END $body$", "SELECT $1::int"}},
+		{"SELECT 1; -- trailing comment", []string{"SELECT 1"}},
+		{"-- title\nSELECT 1", []string{"-- title\nSELECT 1"}},
+		{"  \n ;; ", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, stmt := range SplitScript(tt.script) {
+			got = append(got, stmt.SQL)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitScript(%q) = %q, want %q", tt.script, got, tt.want)
+		}
+	}
+}
+
+func TestSplitScriptOffsets(t *testing.T) {
+	script := "SELECT 1;\nSELECT 2"
+	statements := SplitScript(script)
+	if len(statements) != 2 {
+		t.Fatalf("got %d statements, want 2", len(statements))
+	}
+	if statements[0].Start != 0 || statements[0].End != 9 {
+		t.Errorf("first statement spans %d-%d, want 0-9", statements[0].Start, statements[0].End)
+	}
+	if statements[1].Start != 9 || statements[1].End != len(script) {
+		t.Errorf("second statement spans %d-%d, want 9-%d", statements[1].Start, statements[1].End, len(script))
+	}
+}
@@ -0,0 +1,177 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rebelice/lazypg/internal/db/connection"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// Transaction is an explicit transaction opened from the SQL editor. It pins
+// one pooled connection so that every statement runs in the same session.
+type Transaction struct {
+	mu      sync.Mutex // Serializes use of conn
+	conn    *pgxpool.Conn
+	started time.Time
+
+	statements atomic.Int32
+	failed     atomic.Bool
+	closed     atomic.Bool
+	lost       atomic.Bool // The session ended, rolling the transaction back
+
+	resetTimeout bool // The session's statement_timeout was set by a script
+}
+
+// IsTransactionStart reports whether a statement opens a transaction
+func IsTransactionStart(sql string) bool {
+	words := sqlWords(sql)
+	if len(words) == 0 {
+		return false
+	}
+	switch strings.ToUpper(words[0]) {
+	case "BEGIN":
+		return true
+	case "START":
+		return len(words) > 1 && strings.EqualFold(words[1], "TRANSACTION")
+	}
+	return false
+}
+
+// Begin pins a connection and runs beginSQL on it, e.g. BEGIN or
+// START TRANSACTION ISOLATION LEVEL SERIALIZABLE. The statement_timeout of
+// an Execution carried by ctx applies until the transaction ends.
+func Begin(ctx context.Context, pool *connection.Pool, beginSQL string) (*Transaction, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, beginSQL); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if conn.Conn().PgConn().TxStatus() != 'T' {
+		// Not a statement that starts a transaction block
+		conn.Release()
+		return nil, fmt.Errorf("no transaction was started")
+	}
+	if set := timeoutSQL(ctx, true); set != "" {
+		if _, err := conn.Exec(ctx, set); err != nil {
+			_, _ = conn.Exec(ctx, "ROLLBACK")
+			conn.Release()
+			return nil, err
+		}
+	}
+	return &Transaction{conn: conn, started: time.Now()}, nil
+}
+
+// Execute runs a statement inside the transaction. A statement that ends
+// the transaction itself, such as COMMIT, closes it.
+func (t *Transaction) Execute(ctx context.Context, sql string, args ...any) models.QueryResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed.Load() {
+		return models.QueryResult{Error: fmt.Errorf("transaction is no longer open")}
+	}
+
+	untrack := track(ctx, t.conn.Conn())
+	result := run(ctx, t.conn, sql, args...)
+	untrack()
+	t.statements.Add(1)
+	t.syncState()
+	if result.Error != nil && t.lost.Load() {
+		result.Error = fmt.Errorf("%w (the transaction was rolled back)", result.Error)
+	}
+	return result
+}
+
+// syncState updates the state from the session; callers must hold mu
+func (t *Transaction) syncState() {
+	if t.conn.Conn().IsClosed() {
+		// The session is gone, and with it the transaction
+		t.lost.Store(true)
+		t.release()
+		return
+	}
+	switch t.conn.Conn().PgConn().TxStatus() {
+	case 'E':
+		t.failed.Store(true)
+	case 'I':
+		// Ended by COMMIT, ROLLBACK or similar from the editor
+		t.release()
+	}
+}
+
+// Commit commits the transaction. In a failed transaction PostgreSQL rolls
+// back instead, which is reported as an error.
+func (t *Transaction) Commit(ctx context.Context) error {
+	failed := t.failed.Load()
+	if err := t.finish(ctx, "COMMIT"); err != nil {
+		return err
+	}
+	if failed {
+		return fmt.Errorf("the transaction had failed and was rolled back")
+	}
+	return nil
+}
+
+// Rollback rolls the transaction back
+func (t *Transaction) Rollback(ctx context.Context) error {
+	return t.finish(ctx, "ROLLBACK")
+}
+
+// finish runs COMMIT or ROLLBACK and returns the connection to the pool
+func (t *Transaction) finish(ctx context.Context, sql string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed.Load() {
+		return nil
+	}
+	_, err := t.conn.Exec(ctx, sql)
+	t.release()
+	return err
+}
+
+// release returns the connection to the pool; callers must hold mu. The pool
+// discards connections that are still inside a transaction.
+func (t *Transaction) release() {
+	if t.closed.Swap(true) {
+		return
+	}
+	if t.conn != nil {
+		if t.resetTimeout && !t.conn.Conn().IsClosed() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, _ = t.conn.Exec(ctx, "RESET statement_timeout")
+			cancel()
+		}
+		t.conn.Release()
+	}
+}
+
+// Open reports whether the transaction is still in progress
+func (t *Transaction) Open() bool {
+	return !t.closed.Load()
+}
+
+// Failed reports whether a statement failed, so that PostgreSQL ignores
+// everything until the transaction is rolled back
+func (t *Transaction) Failed() bool {
+	return t.failed.Load()
+}
+
+// Statements returns the number of statements run in the transaction
+func (t *Transaction) Statements() int {
+	return int(t.statements.Load())
+}
+
+// Elapsed returns how long the transaction has been open
+func (t *Transaction) Elapsed() time.Duration {
+	return time.Since(t.started)
+}
@@ -0,0 +1,634 @@
+package schemadiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/db/edit"
+)
+
+// Status is how an object of the target differs from the source
+type Status int
+
+const (
+	Added   Status = iota // Only in the source; the migration creates it
+	Removed               // Only in the target; the migration drops it
+	Changed               // Defined differently; the migration alters it
+)
+
+// String returns the name of the status
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// Phases of a migration. Statements run phase by phase so that dependent
+// objects are dropped first and created last.
+const (
+	phaseDropViews = iota
+	phaseDropFunctions
+	phaseDropForeignKeys
+	phaseDropConstraints
+	phaseDropIndexes
+	phaseCreateTypes
+	phaseDropTables
+	phaseCreateTables
+	phaseColumns
+	phaseAddConstraints
+	phaseAddForeignKeys
+	phaseCreateIndexes
+	phaseCreateFunctions
+	phaseCreateViews
+	phaseDropTypes
+)
+
+// Statement is a statement of the migration of a difference
+type Statement struct {
+	Phase int
+	SQL   string
+}
+
+// Difference is an object defined differently in the two schemas
+type Difference struct {
+	Group      string // Object the difference belongs to, e.g. "table orders"
+	Kind       string // table, column, constraint, index, view, materialized view, function, procedure, sequence or enum
+	Name       string
+	Status     Status
+	Source     string // Definition in the source, empty when missing
+	Target     string // Definition in the target, empty when missing
+	Statements []Statement
+}
+
+// Comparison is the result of comparing a source schema with a target
+type Comparison struct {
+	Source      string // Name of the source schema
+	Target      string // Name of the target schema
+	Differences []Difference
+}
+
+// comparer collects the differences between two schemas
+type comparer struct {
+	target string
+	diffs  []Difference
+}
+
+// Compare returns the differences of the target schema from the source,
+// grouped by object: tables, views, functions, sequences, then enums
+func Compare(source, target *Schema) *Comparison {
+	c := &comparer{target: target.Name}
+	c.compareTables(source.Tables, target.Tables)
+	c.compareViews(source.Views, target.Views)
+	c.compareFunctions(source.Functions, target.Functions)
+	c.compareSequences(source.Sequences, target.Sequences)
+	c.compareEnums(source.Enums, target.Enums)
+	return &Comparison{Source: source.Name, Target: target.Name, Differences: c.diffs}
+}
+
+// add records a difference
+func (c *comparer) add(d Difference) {
+	c.diffs = append(c.diffs, d)
+}
+
+// name returns the quoted name of an object of the target schema
+func (c *comparer) name(name string) string {
+	return edit.QualifiedName(c.target, name)
+}
+
+// compareTables compares the tables of the schemas and their parts
+func (c *comparer) compareTables(source, target []Table) {
+	for _, st := range source {
+		group := "table " + st.Name
+		i := slices.IndexFunc(target, func(t Table) bool { return t.Name == st.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: group, Kind: "table", Name: st.Name, Status: Added,
+				Source:     describeTable(st),
+				Statements: c.createTable(st),
+			})
+			continue
+		}
+		tt := target[i]
+		if st.PartitionBy != tt.PartitionBy {
+			c.add(Difference{
+				Group: group, Kind: "table", Name: st.Name, Status: Changed,
+				Source: partitionText(st.PartitionBy),
+				Target: partitionText(tt.PartitionBy),
+				Statements: []Statement{{phaseCreateTables, fmt.Sprintf(
+					"-- The partitioning of %s differs; a table cannot be repartitioned in place.", c.name(st.Name))}},
+			})
+		}
+		c.compareColumns(group, st.Name, st.Columns, tt.Columns)
+		c.compareConstraints(group, st.Name, st.Constraints, tt.Constraints)
+		c.compareIndexes(group, st.Name, st.Indexes, tt.Indexes)
+	}
+	for _, tt := range target {
+		if !slices.ContainsFunc(source, func(t Table) bool { return t.Name == tt.Name }) {
+			c.add(Difference{
+				Group: "table " + tt.Name, Kind: "table", Name: tt.Name, Status: Removed,
+				Target:     describeTable(tt),
+				Statements: []Statement{{phaseDropTables, fmt.Sprintf("DROP TABLE %s;", c.name(tt.Name))}},
+			})
+		}
+	}
+}
+
+// createTable returns the statements creating a table with its columns and
+// constraints, then its foreign keys and indexes
+func (c *comparer) createTable(t Table) []Statement {
+	var lines []string
+	for _, col := range t.Columns {
+		lines = append(lines, "    "+edit.QuoteIdent(col.Name)+" "+describeColumn(col))
+	}
+	var after []Statement
+	for _, con := range t.Constraints {
+		if con.Type == "f" {
+			after = append(after, c.addConstraint(t.Name, con))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s %s", edit.QuoteIdent(con.Name), con.Definition))
+	}
+	for _, idx := range t.Indexes {
+		after = append(after, Statement{phaseCreateIndexes, c.createIndex(t.Name, idx)})
+	}
+
+	sql := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", c.name(t.Name), strings.Join(lines, ",\n"))
+	if t.PartitionBy != "" {
+		sql += " PARTITION BY " + t.PartitionBy
+	}
+	return append([]Statement{{phaseCreateTables, sql + ";"}}, after...)
+}
+
+// compareColumns compares the columns of a table
+func (c *comparer) compareColumns(group, table string, source, target []Column) {
+	alter := "ALTER TABLE " + c.name(table) + " "
+	for _, sc := range source {
+		i := slices.IndexFunc(target, func(col Column) bool { return col.Name == sc.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: group, Kind: "column", Name: sc.Name, Status: Added,
+				Source:     describeColumn(sc),
+				Statements: []Statement{{phaseColumns, alter + "ADD COLUMN " + edit.QuoteIdent(sc.Name) + " " + describeColumn(sc) + ";"}},
+			})
+			continue
+		}
+		tc := target[i]
+		if sc == tc {
+			continue
+		}
+		c.add(Difference{
+			Group: group, Kind: "column", Name: sc.Name, Status: Changed,
+			Source:     describeColumn(sc),
+			Target:     describeColumn(tc),
+			Statements: alterColumn(alter, sc, tc),
+		})
+	}
+	for _, tc := range target {
+		if !slices.ContainsFunc(source, func(col Column) bool { return col.Name == tc.Name }) {
+			c.add(Difference{
+				Group: group, Kind: "column", Name: tc.Name, Status: Removed,
+				Target:     describeColumn(tc),
+				Statements: []Statement{{phaseColumns, alter + "DROP COLUMN " + edit.QuoteIdent(tc.Name) + ";"}},
+			})
+		}
+	}
+}
+
+// alterColumn returns the statements changing a column of the target to
+// its definition in the source
+func alterColumn(alter string, source, target Column) []Statement {
+	name := edit.QuoteIdent(source.Name)
+	if source.Generated || target.Generated {
+		// Generation expressions cannot be changed in place
+		return []Statement{
+			{phaseColumns, alter + "DROP COLUMN " + name + ";"},
+			{phaseColumns, alter + "ADD COLUMN " + name + " " + describeColumn(source) + ";"},
+		}
+	}
+
+	column := alter + "ALTER COLUMN " + name + " "
+	var sqls []string
+	if source.Default != target.Default && target.Default != "" {
+		sqls = append(sqls, column+"DROP DEFAULT;")
+	}
+	if source.Type != target.Type {
+		sqls = append(sqls, fmt.Sprintf("%sTYPE %s USING %s::%s;", column, source.Type, name, source.Type))
+	}
+	if source.Default != target.Default && source.Default != "" {
+		sqls = append(sqls, column+"SET DEFAULT "+source.Default+";")
+	}
+	if source.NotNull && !target.NotNull {
+		sqls = append(sqls, column+"SET NOT NULL;")
+	}
+	switch {
+	case source.Identity == target.Identity:
+	case source.Identity == "":
+		sqls = append(sqls, column+"DROP IDENTITY;")
+	case target.Identity == "":
+		sqls = append(sqls, column+"ADD GENERATED "+source.Identity+" AS IDENTITY;")
+	default:
+		sqls = append(sqls, column+"SET GENERATED "+source.Identity+";")
+	}
+	if !source.NotNull && target.NotNull {
+		sqls = append(sqls, column+"DROP NOT NULL;")
+	}
+
+	statements := make([]Statement, len(sqls))
+	for i, sql := range sqls {
+		statements[i] = Statement{phaseColumns, sql}
+	}
+	return statements
+}
+
+// compareConstraints compares the constraints of a table
+func (c *comparer) compareConstraints(group, table string, source, target []Constraint) {
+	for _, sc := range source {
+		i := slices.IndexFunc(target, func(con Constraint) bool { return con.Name == sc.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: group, Kind: "constraint", Name: sc.Name, Status: Added,
+				Source:     sc.Definition,
+				Statements: []Statement{c.addConstraint(table, sc)},
+			})
+			continue
+		}
+		tc := target[i]
+		if sc == tc {
+			continue
+		}
+		c.add(Difference{
+			Group: group, Kind: "constraint", Name: sc.Name, Status: Changed,
+			Source:     sc.Definition,
+			Target:     tc.Definition,
+			Statements: []Statement{c.dropConstraint(table, tc), c.addConstraint(table, sc)},
+		})
+	}
+	for _, tc := range target {
+		if !slices.ContainsFunc(source, func(con Constraint) bool { return con.Name == tc.Name }) {
+			c.add(Difference{
+				Group: group, Kind: "constraint", Name: tc.Name, Status: Removed,
+				Target:     tc.Definition,
+				Statements: []Statement{c.dropConstraint(table, tc)},
+			})
+		}
+	}
+}
+
+// addConstraint returns the statement adding a constraint to a table
+func (c *comparer) addConstraint(table string, con Constraint) Statement {
+	phase := phaseAddConstraints
+	if con.Type == "f" {
+		phase = phaseAddForeignKeys
+	}
+	return Statement{phase, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", c.name(table), edit.QuoteIdent(con.Name), con.Definition)}
+}
+
+// dropConstraint returns the statement dropping a constraint of a table
+func (c *comparer) dropConstraint(table string, con Constraint) Statement {
+	phase := phaseDropConstraints
+	if con.Type == "f" {
+		phase = phaseDropForeignKeys
+	}
+	return Statement{phase, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", c.name(table), edit.QuoteIdent(con.Name))}
+}
+
+// compareIndexes compares the indexes of a table
+func (c *comparer) compareIndexes(group, table string, source, target []Index) {
+	for _, si := range source {
+		i := slices.IndexFunc(target, func(idx Index) bool { return idx.Name == si.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: group, Kind: "index", Name: si.Name, Status: Added,
+				Source:     describeIndex(si),
+				Statements: []Statement{{phaseCreateIndexes, c.createIndex(table, si)}},
+			})
+			continue
+		}
+		ti := target[i]
+		if si == ti {
+			continue
+		}
+		c.add(Difference{
+			Group: group, Kind: "index", Name: si.Name, Status: Changed,
+			Source: describeIndex(si),
+			Target: describeIndex(ti),
+			Statements: []Statement{
+				{phaseDropIndexes, fmt.Sprintf("DROP INDEX %s;", c.name(ti.Name))},
+				{phaseCreateIndexes, c.createIndex(table, si)},
+			},
+		})
+	}
+	for _, ti := range target {
+		if !slices.ContainsFunc(source, func(idx Index) bool { return idx.Name == ti.Name }) {
+			c.add(Difference{
+				Group: group, Kind: "index", Name: ti.Name, Status: Removed,
+				Target:     describeIndex(ti),
+				Statements: []Statement{{phaseDropIndexes, fmt.Sprintf("DROP INDEX %s;", c.name(ti.Name))}},
+			})
+		}
+	}
+}
+
+// createIndex returns the statement creating an index on a table
+func (c *comparer) createIndex(table string, idx Index) string {
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s USING %s;", unique, edit.QuoteIdent(idx.Name), c.name(table), idx.Using)
+}
+
+// compareViews compares the views and materialized views of the schemas
+func (c *comparer) compareViews(source, target []View) {
+	for _, sv := range source {
+		i := slices.IndexFunc(target, func(v View) bool { return v.Name == sv.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: viewKind(sv) + " " + sv.Name, Kind: viewKind(sv), Name: sv.Name, Status: Added,
+				Source:     sv.Definition,
+				Statements: []Statement{c.createView(sv)},
+			})
+			continue
+		}
+		tv := target[i]
+		if sv == tv {
+			continue
+		}
+		c.add(Difference{
+			Group: viewKind(sv) + " " + sv.Name, Kind: viewKind(sv), Name: sv.Name, Status: Changed,
+			Source:     sv.Definition,
+			Target:     tv.Definition,
+			Statements: []Statement{c.dropView(tv), c.createView(sv)},
+		})
+	}
+	for _, tv := range target {
+		if !slices.ContainsFunc(source, func(v View) bool { return v.Name == tv.Name }) {
+			c.add(Difference{
+				Group: viewKind(tv) + " " + tv.Name, Kind: viewKind(tv), Name: tv.Name, Status: Removed,
+				Target:     tv.Definition,
+				Statements: []Statement{c.dropView(tv)},
+			})
+		}
+	}
+}
+
+// viewKind returns "view" or "materialized view"
+func viewKind(v View) string {
+	if v.Materialized {
+		return "materialized view"
+	}
+	return "view"
+}
+
+// createView returns the statement creating a view
+func (c *comparer) createView(v View) Statement {
+	return Statement{phaseCreateViews, fmt.Sprintf("CREATE %s %s AS\n%s;",
+		strings.ToUpper(viewKind(v)), c.name(v.Name), strings.TrimSuffix(v.Definition, ";"))}
+}
+
+// dropView returns the statement dropping a view
+func (c *comparer) dropView(v View) Statement {
+	return Statement{phaseDropViews, fmt.Sprintf("DROP %s %s;", strings.ToUpper(viewKind(v)), c.name(v.Name))}
+}
+
+// compareFunctions compares the functions and procedures of the schemas,
+// identified by name and argument types
+func (c *comparer) compareFunctions(source, target []Function) {
+	same := func(a Function) func(Function) bool {
+		return func(b Function) bool { return a.Name == b.Name && a.Arguments == b.Arguments }
+	}
+	for _, sf := range source {
+		i := slices.IndexFunc(target, same(sf))
+		if i < 0 {
+			c.add(Difference{
+				Group: functionKind(sf) + " " + signature(sf), Kind: functionKind(sf), Name: signature(sf), Status: Added,
+				Source:     sf.Definition,
+				Statements: []Statement{{phaseCreateFunctions, sf.Definition + ";"}},
+			})
+			continue
+		}
+		tf := target[i]
+		if sf == tf {
+			continue
+		}
+		statements := []Statement{{phaseCreateFunctions, sf.Definition + ";"}}
+		if sf.Procedure != tf.Procedure {
+			statements = append([]Statement{c.dropFunction(tf)}, statements...)
+		}
+		c.add(Difference{
+			Group: functionKind(sf) + " " + signature(sf), Kind: functionKind(sf), Name: signature(sf), Status: Changed,
+			Source:     sf.Definition,
+			Target:     tf.Definition,
+			Statements: statements,
+		})
+	}
+	for _, tf := range target {
+		if !slices.ContainsFunc(source, same(tf)) {
+			c.add(Difference{
+				Group: functionKind(tf) + " " + signature(tf), Kind: functionKind(tf), Name: signature(tf), Status: Removed,
+				Target:     tf.Definition,
+				Statements: []Statement{c.dropFunction(tf)},
+			})
+		}
+	}
+}
+
+// functionKind returns "function" or "procedure"
+func functionKind(f Function) string {
+	if f.Procedure {
+		return "procedure"
+	}
+	return "function"
+}
+
+// signature returns the name and argument types of a function
+func signature(f Function) string {
+	return f.Name + "(" + f.Arguments + ")"
+}
+
+// dropFunction returns the statement dropping a function
+func (c *comparer) dropFunction(f Function) Statement {
+	return Statement{phaseDropFunctions, fmt.Sprintf("DROP %s %s(%s);", strings.ToUpper(functionKind(f)), c.name(f.Name), f.Arguments)}
+}
+
+// compareSequences compares the sequences of the schemas
+func (c *comparer) compareSequences(source, target []Sequence) {
+	for _, ss := range source {
+		i := slices.IndexFunc(target, func(s Sequence) bool { return s.Name == ss.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: "sequence " + ss.Name, Kind: "sequence", Name: ss.Name, Status: Added,
+				Source:     describeSequence(ss),
+				Statements: []Statement{{phaseCreateTypes, fmt.Sprintf("CREATE SEQUENCE %s %s;", c.name(ss.Name), sequenceOptions(ss))}},
+			})
+			continue
+		}
+		ts := target[i]
+		if ss == ts {
+			continue
+		}
+		c.add(Difference{
+			Group: "sequence " + ss.Name, Kind: "sequence", Name: ss.Name, Status: Changed,
+			Source:     describeSequence(ss),
+			Target:     describeSequence(ts),
+			Statements: []Statement{{phaseCreateTypes, fmt.Sprintf("ALTER SEQUENCE %s %s;", c.name(ss.Name), sequenceOptions(ss))}},
+		})
+	}
+	for _, ts := range target {
+		if !slices.ContainsFunc(source, func(s Sequence) bool { return s.Name == ts.Name }) {
+			c.add(Difference{
+				Group: "sequence " + ts.Name, Kind: "sequence", Name: ts.Name, Status: Removed,
+				Target:     describeSequence(ts),
+				Statements: []Statement{{phaseDropTypes, fmt.Sprintf("DROP SEQUENCE %s;", c.name(ts.Name))}},
+			})
+		}
+	}
+}
+
+// sequenceOptions returns the options defining a sequence
+func sequenceOptions(s Sequence) string {
+	cycle := "NO CYCLE"
+	if s.Cycle {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf("AS %s INCREMENT BY %d MINVALUE %d MAXVALUE %d START WITH %d CACHE %d %s",
+		s.DataType, s.Increment, s.Min, s.Max, s.Start, s.Cache, cycle)
+}
+
+// compareEnums compares the enum types of the schemas
+func (c *comparer) compareEnums(source, target []Enum) {
+	for _, se := range source {
+		i := slices.IndexFunc(target, func(e Enum) bool { return e.Name == se.Name })
+		if i < 0 {
+			c.add(Difference{
+				Group: "enum " + se.Name, Kind: "enum", Name: se.Name, Status: Added,
+				Source:     describeLabels(se.Labels),
+				Statements: []Statement{{phaseCreateTypes, fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", c.name(se.Name), describeLabels(se.Labels))}},
+			})
+			continue
+		}
+		te := target[i]
+		if slices.Equal(se.Labels, te.Labels) {
+			continue
+		}
+		c.add(Difference{
+			Group: "enum " + se.Name, Kind: "enum", Name: se.Name, Status: Changed,
+			Source:     describeLabels(se.Labels),
+			Target:     describeLabels(te.Labels),
+			Statements: c.alterEnum(se, te),
+		})
+	}
+	for _, te := range target {
+		if !slices.ContainsFunc(source, func(e Enum) bool { return e.Name == te.Name }) {
+			c.add(Difference{
+				Group: "enum " + te.Name, Kind: "enum", Name: te.Name, Status: Removed,
+				Target:     describeLabels(te.Labels),
+				Statements: []Statement{{phaseDropTypes, fmt.Sprintf("DROP TYPE %s;", c.name(te.Name))}},
+			})
+		}
+	}
+}
+
+// alterEnum returns the statements adding the labels of the source to an
+// enum of the target. Labels cannot be removed or reordered in place.
+func (c *comparer) alterEnum(source, target Enum) []Statement {
+	// The target's labels must appear in the source in the same order
+	next := 0
+	for _, label := range source.Labels {
+		if next < len(target.Labels) && label == target.Labels[next] {
+			next++
+		}
+	}
+	if next < len(target.Labels) {
+		return []Statement{{phaseCreateTypes, fmt.Sprintf(
+			"-- Labels of %s were removed or reordered; an enum cannot be changed this way in place.", c.name(source.Name))}}
+	}
+
+	var statements []Statement
+	for i, label := range source.Labels {
+		if slices.Contains(target.Labels, label) {
+			continue
+		}
+		position := ""
+		switch {
+		case i > 0:
+			position = " AFTER " + edit.QuoteLiteral(source.Labels[i-1])
+		case len(target.Labels) > 0:
+			position = " BEFORE " + edit.QuoteLiteral(target.Labels[0])
+		}
+		statements = append(statements, Statement{phaseCreateTypes, fmt.Sprintf(
+			"ALTER TYPE %s ADD VALUE %s%s;", c.name(source.Name), edit.QuoteLiteral(label), position)})
+	}
+	return statements
+}
+
+// describeTable returns the columns and constraints of a table, one per line
+func describeTable(t Table) string {
+	var lines []string
+	for _, col := range t.Columns {
+		lines = append(lines, col.Name+" "+describeColumn(col))
+	}
+	for _, con := range t.Constraints {
+		lines = append(lines, "CONSTRAINT "+con.Name+" "+con.Definition)
+	}
+	for _, idx := range t.Indexes {
+		lines = append(lines, "INDEX "+idx.Name+" "+describeIndex(idx))
+	}
+	if t.PartitionBy != "" {
+		lines = append(lines, partitionText(t.PartitionBy))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeColumn returns the definition of a column after its name
+func describeColumn(col Column) string {
+	def := col.Type
+	switch {
+	case col.Identity != "":
+		def += " GENERATED " + col.Identity + " AS IDENTITY"
+	case col.Generated:
+		def += " GENERATED ALWAYS AS (" + col.Default + ") STORED"
+	case col.Default != "":
+		def += " DEFAULT " + col.Default
+	}
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// describeIndex returns the definition of an index after its name
+func describeIndex(idx Index) string {
+	if idx.Unique {
+		return "UNIQUE USING " + idx.Using
+	}
+	return "USING " + idx.Using
+}
+
+// describeSequence returns the options of a sequence
+func describeSequence(s Sequence) string {
+	return sequenceOptions(s)
+}
+
+// describeLabels returns the labels of an enum as a list of literals
+func describeLabels(labels []string) string {
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = edit.QuoteLiteral(label)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// partitionText describes the partitioning of a table
+func partitionText(key string) string {
+	if key == "" {
+		return "not partitioned"
+	}
+	return "PARTITION BY " + key
+}
@@ -0,0 +1,241 @@
+package schemadiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sourceSchema returns the schema the tests migrate a target to
+func sourceSchema() *Schema {
+	return &Schema{
+		Name: "app",
+		Tables: []Table{
+			{
+				Name: "customers",
+				Columns: []Column{
+					{Name: "id", Type: "bigint", NotNull: true, Identity: "ALWAYS"},
+					{Name: "email", Type: "text", NotNull: true},
+					{Name: "tier", Type: "tier", Default: "'basic'::tier"},
+				},
+				Constraints: []Constraint{{Name: "customers_pkey", Type: "p", Definition: "PRIMARY KEY (id)"}},
+				Indexes:     []Index{{Name: "customers_email", Unique: true, Using: "btree (lower(email))"}},
+			},
+			{
+				Name: "orders",
+				Columns: []Column{
+					{Name: "id", Type: "bigint", NotNull: true},
+					{Name: "customer_id", Type: "bigint"},
+				},
+				Constraints: []Constraint{
+					{Name: "orders_pkey", Type: "p", Definition: "PRIMARY KEY (id)"},
+					{Name: "orders_customer_id_fkey", Type: "f", Definition: "FOREIGN KEY (customer_id) REFERENCES customers(id)"},
+				},
+			},
+		},
+		Views:     []View{{Name: "big_orders", Definition: " SELECT id\n   FROM orders;"}},
+		Sequences: []Sequence{{Name: "invoice_no", DataType: "bigint", Start: 1000, Min: 1, Max: 9999999, Increment: 1, Cache: 1}},
+		Enums:     []Enum{{Name: "tier", Labels: []string{"basic", "silver", "gold"}}},
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	c := Compare(sourceSchema(), sourceSchema())
+	if len(c.Differences) != 0 {
+		t.Errorf("expected no differences, got %+v", c.Differences)
+	}
+	if !strings.Contains(c.Script(), "nothing to migrate") {
+		t.Errorf("expected an empty migration, got %q", c.Script())
+	}
+}
+
+func TestCompareTables(t *testing.T) {
+	target := sourceSchema()
+	target.Name = "staging"
+	target.Tables = []Table{
+		{
+			Name: "customers",
+			Columns: []Column{
+				{Name: "id", Type: "integer", NotNull: true},
+				{Name: "email", Type: "text"},
+				{Name: "legacy", Type: "text"},
+			},
+			Constraints: []Constraint{{Name: "customers_pkey", Type: "p", Definition: "PRIMARY KEY (id)"}},
+			Indexes:     []Index{{Name: "customers_email", Using: "btree (email)"}},
+		},
+		{Name: "audit", Columns: []Column{{Name: "at", Type: "timestamp with time zone"}}},
+	}
+	c := Compare(sourceSchema(), target)
+
+	type summary struct {
+		group, kind, name string
+		status            Status
+	}
+	var got []summary
+	for _, d := range c.Differences {
+		if strings.HasPrefix(d.Group, "table ") {
+			got = append(got, summary{d.Group, d.Kind, d.Name, d.Status})
+		}
+	}
+	expected := []summary{
+		{"table customers", "column", "id", Changed},
+		{"table customers", "column", "email", Changed},
+		{"table customers", "column", "tier", Added},
+		{"table customers", "column", "legacy", Removed},
+		{"table customers", "index", "customers_email", Changed},
+		{"table orders", "table", "orders", Added},
+		{"table audit", "table", "audit", Removed},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	id := c.Differences[0]
+	if id.Source != "bigint GENERATED ALWAYS AS IDENTITY NOT NULL" || id.Target != "integer NOT NULL" {
+		t.Errorf("unexpected definitions %q and %q", id.Source, id.Target)
+	}
+	var sqls []string
+	for _, stmt := range id.Statements {
+		sqls = append(sqls, stmt.SQL)
+	}
+	expectedSQL := []string{
+		`ALTER TABLE "staging"."customers" ALTER COLUMN "id" TYPE bigint USING "id"::bigint;`,
+		`ALTER TABLE "staging"."customers" ALTER COLUMN "id" ADD GENERATED ALWAYS AS IDENTITY;`,
+	}
+	if !reflect.DeepEqual(sqls, expectedSQL) {
+		t.Errorf("expected %q, got %q", expectedSQL, sqls)
+	}
+}
+
+func TestAlterColumn(t *testing.T) {
+	alter := `ALTER TABLE "t" `
+	tests := []struct {
+		name           string
+		source, target Column
+		expected       []string
+	}{
+		{
+			name:   "default and null",
+			source: Column{Name: "n", Type: "integer", Default: "1", NotNull: true},
+			target: Column{Name: "n", Type: "integer", Default: "0"},
+			expected: []string{
+				`ALTER TABLE "t" ALTER COLUMN "n" DROP DEFAULT;`,
+				`ALTER TABLE "t" ALTER COLUMN "n" SET DEFAULT 1;`,
+				`ALTER TABLE "t" ALTER COLUMN "n" SET NOT NULL;`,
+			},
+		},
+		{
+			name:   "identity removed",
+			source: Column{Name: "n", Type: "integer"},
+			target: Column{Name: "n", Type: "integer", Identity: "BY DEFAULT", NotNull: true},
+			expected: []string{
+				`ALTER TABLE "t" ALTER COLUMN "n" DROP IDENTITY;`,
+				`ALTER TABLE "t" ALTER COLUMN "n" DROP NOT NULL;`,
+			},
+		},
+		{
+			name:   "identity changed",
+			source: Column{Name: "n", Type: "integer", Identity: "ALWAYS", NotNull: true},
+			target: Column{Name: "n", Type: "integer", Identity: "BY DEFAULT", NotNull: true},
+			expected: []string{
+				`ALTER TABLE "t" ALTER COLUMN "n" SET GENERATED ALWAYS;`,
+			},
+		},
+		{
+			name:   "generated",
+			source: Column{Name: "n", Type: "integer", Default: "(a * 2)", Generated: true},
+			target: Column{Name: "n", Type: "integer"},
+			expected: []string{
+				`ALTER TABLE "t" DROP COLUMN "n";`,
+				`ALTER TABLE "t" ADD COLUMN "n" integer GENERATED ALWAYS AS ((a * 2)) STORED;`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, stmt := range alterColumn(alter, tt.source, tt.target) {
+				got = append(got, stmt.SQL)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompareEnums(t *testing.T) {
+	source := sourceSchema()
+	target := sourceSchema()
+	target.Enums = []Enum{{Name: "tier", Labels: []string{"silver"}}}
+	c := Compare(source, target)
+	if len(c.Differences) != 1 {
+		t.Fatalf("expected one difference, got %+v", c.Differences)
+	}
+	var sqls []string
+	for _, stmt := range c.Differences[0].Statements {
+		sqls = append(sqls, stmt.SQL)
+	}
+	expected := []string{
+		`ALTER TYPE "app"."tier" ADD VALUE 'basic' BEFORE 'silver';`,
+		`ALTER TYPE "app"."tier" ADD VALUE 'gold' AFTER 'silver';`,
+	}
+	if !reflect.DeepEqual(sqls, expected) {
+		t.Errorf("expected %q, got %q", expected, sqls)
+	}
+
+	target.Enums = []Enum{{Name: "tier", Labels: []string{"gold", "basic"}}}
+	c = Compare(source, target)
+	if sql := c.Differences[0].Statements[0].SQL; !strings.HasPrefix(sql, "-- ") {
+		t.Errorf("expected a reordered enum to be left to the user, got %q", sql)
+	}
+}
+
+func TestCompareFunctions(t *testing.T) {
+	source := sourceSchema()
+	source.Functions = []Function{
+		{Name: "total", Arguments: "bigint", Definition: "CREATE OR REPLACE FUNCTION total(bigint) ... $$"},
+		{Name: "archive", Procedure: true, Definition: "CREATE OR REPLACE PROCEDURE archive() ... $$"},
+	}
+	target := sourceSchema()
+	target.Functions = []Function{
+		{Name: "total", Arguments: "bigint", Definition: "CREATE OR REPLACE FUNCTION total(bigint) ... old $$"},
+		{Name: "total", Arguments: "integer", Definition: "CREATE OR REPLACE FUNCTION total(integer) ... $$"},
+	}
+	c := Compare(source, target)
+
+	var got []string
+	for _, d := range c.Differences {
+		got = append(got, d.Status.String()+" "+d.Group)
+	}
+	expected := []string{"changed function total(bigint)", "added procedure archive()", "removed function total(integer)"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if sql := c.Differences[2].Statements[0].SQL; sql != `DROP FUNCTION "app"."total"(integer);` {
+		t.Errorf("unexpected drop %q", sql)
+	}
+}
+
+func TestUnqualifier(t *testing.T) {
+	unqualify := unqualifier("app")
+	tests := map[string]string{
+		"nextval('app.orders_id_seq'::regclass)":   "nextval('orders_id_seq'::regclass)",
+		`REFERENCES "app".customers(id)`:           "REFERENCES customers(id)",
+		"REFERENCES myapp.customers(id)":           "REFERENCES myapp.customers(id)",
+		"app.tier[]":                               "tier[]",
+		"SELECT o.app FROM orders o":               "SELECT o.app FROM orders o",
+		`REFERENCES "other".app.customers(id)`:     `REFERENCES "other".app.customers(id)`,
+		"CREATE FUNCTION app.total(app.tier) AS x": "CREATE FUNCTION total(tier) AS x",
+	}
+	for input, expected := range tests {
+		if got := unqualify(input); got != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, got)
+		}
+	}
+
+	quoted := unqualifier("My App")
+	if got := quoted(`"My App".orders`); got != "orders" {
+		t.Errorf("expected a quoted schema to be removed, got %q", got)
+	}
+}
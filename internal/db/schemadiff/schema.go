@@ -0,0 +1,437 @@
+package schemadiff
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/db/connection"
+)
+
+// Schema is the definition of the objects of a schema. Names in definitions
+// are relative to the schema, so that schemas with different names compare
+// equal.
+type Schema struct {
+	Name      string
+	Tables    []Table
+	Views     []View
+	Functions []Function
+	Sequences []Sequence
+	Enums     []Enum
+}
+
+// Table is a table with its columns, constraints and indexes
+type Table struct {
+	Name        string
+	PartitionBy string // Partition key of a partitioned table, e.g. "RANGE (created_at)"
+	Columns     []Column
+	Constraints []Constraint
+	Indexes     []Index // Indexes not backing a constraint
+}
+
+// Column is a column of a table
+type Column struct {
+	Name      string
+	Type      string
+	NotNull   bool
+	Default   string // Default expression, or the expression of a generated column
+	Identity  string // "ALWAYS", "BY DEFAULT" or empty
+	Generated bool   // Stored generated column computed from Default
+}
+
+// Constraint is a primary key, unique, foreign key, check or exclusion
+// constraint
+type Constraint struct {
+	Name       string
+	Type       string // 'p', 'u', 'f', 'c' or 'x'
+	Definition string
+}
+
+// Index is an index of a table
+type Index struct {
+	Name   string
+	Unique bool
+	Using  string // Method, keys and predicate, e.g. "btree (email) WHERE active"
+}
+
+// View is a view or materialized view
+type View struct {
+	Name         string
+	Materialized bool
+	Definition   string
+}
+
+// Function is a function or procedure
+type Function struct {
+	Name       string
+	Arguments  string // Identity arguments, e.g. "integer, text"
+	Procedure  bool
+	Definition string // Complete CREATE OR REPLACE statement
+}
+
+// Sequence is a sequence not backing an identity column
+type Sequence struct {
+	Name      string
+	DataType  string
+	Start     int64
+	Min       int64
+	Max       int64
+	Increment int64
+	Cache     int64
+	Cycle     bool
+}
+
+// Enum is an enum type
+type Enum struct {
+	Name   string
+	Labels []string
+}
+
+// notExtensionMember excludes objects created by extensions; %s is the
+// catalog and the object's oid
+const notExtensionMember = `NOT EXISTS (
+	SELECT 1 FROM pg_catalog.pg_depend dep
+	WHERE dep.classid = '%s'::regclass AND dep.objid = %s AND dep.deptype = 'e'
+)`
+
+// Load reads the definition of the objects of a schema
+func Load(ctx context.Context, pool *connection.Pool, schema string) (*Schema, error) {
+	exists, err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1) AS found", schema)
+	if err != nil {
+		return nil, err
+	}
+	if found, _ := exists["found"].(bool); !found {
+		return nil, fmt.Errorf("schema %q does not exist", schema)
+	}
+
+	s := &Schema{Name: schema}
+	unqualify := unqualifier(schema)
+	loaders := []func(context.Context, *connection.Pool, *Schema, func(string) string) error{
+		loadTables, loadColumns, loadConstraints, loadIndexes, loadViews, loadFunctions, loadSequences, loadEnums,
+	}
+	for _, load := range loaders {
+		if err := load(ctx, pool, s, unqualify); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// tableFilter selects the tables of the schema, partitions excepted
+var tableFilter = `n.nspname = $1 AND c.relkind IN ('r', 'p') AND NOT c.relispartition AND ` +
+	fmt.Sprintf(notExtensionMember, "pg_catalog.pg_class", "c.oid")
+
+// loadTables reads the tables of a schema
+func loadTables(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT c.relname AS name, coalesce(pg_catalog.pg_get_partkeydef(c.oid), '') AS partition_by
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE `+tableFilter+`
+		ORDER BY c.relname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load tables: %w", err)
+	}
+	for _, row := range rows {
+		s.Tables = append(s.Tables, Table{
+			Name:        toString(row["name"]),
+			PartitionBy: unqualify(toString(row["partition_by"])),
+		})
+	}
+	return nil
+}
+
+// loadColumns reads the columns of the tables of a schema
+func loadColumns(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	// attidentity and attgenerated are read through jsonb to work on servers without them
+	rows, err := pool.Query(ctx, `
+		SELECT
+			c.relname AS table_name,
+			a.attname AS name,
+			pg_catalog.format_type(a.atttypid, a.atttypmod) AS type,
+			a.attnotnull AS not_null,
+			coalesce(pg_catalog.pg_get_expr(d.adbin, d.adrelid), '') AS default_value,
+			coalesce(to_jsonb(a) ->> 'attidentity', '') AS identity,
+			coalesce(to_jsonb(a) ->> 'attgenerated', '') AS generated
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE `+tableFilter+` AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load columns: %w", err)
+	}
+	for _, row := range rows {
+		table := s.table(toString(row["table_name"]))
+		if table == nil {
+			continue
+		}
+		col := Column{
+			Name:      toString(row["name"]),
+			Type:      unqualify(toString(row["type"])),
+			NotNull:   toBool(row["not_null"]),
+			Default:   unqualify(toString(row["default_value"])),
+			Generated: toString(row["generated"]) == "s",
+		}
+		switch toString(row["identity"]) {
+		case "a":
+			col.Identity = "ALWAYS"
+		case "d":
+			col.Identity = "BY DEFAULT"
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	return nil
+}
+
+// loadConstraints reads the constraints of the tables of a schema
+func loadConstraints(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			c.relname AS table_name,
+			con.conname AS name,
+			con.contype::text AS type,
+			pg_catalog.pg_get_constraintdef(con.oid) AS definition
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE `+tableFilter+` AND con.contype IN ('p', 'u', 'f', 'c', 'x')
+		ORDER BY c.relname, con.conname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load constraints: %w", err)
+	}
+	for _, row := range rows {
+		if table := s.table(toString(row["table_name"])); table != nil {
+			table.Constraints = append(table.Constraints, Constraint{
+				Name:       toString(row["name"]),
+				Type:       toString(row["type"]),
+				Definition: unqualify(toString(row["definition"])),
+			})
+		}
+	}
+	return nil
+}
+
+// loadIndexes reads the indexes of the tables of a schema that do not back
+// a constraint
+func loadIndexes(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			c.relname AS table_name,
+			ic.relname AS name,
+			i.indisunique AS is_unique,
+			substring(pg_catalog.pg_get_indexdef(i.indexrelid) FROM ' USING (.*)$') AS using_clause
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_catalog.pg_class c ON c.oid = i.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE `+tableFilter+`
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_constraint con
+				WHERE con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x')
+			)
+		ORDER BY c.relname, ic.relname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load indexes: %w", err)
+	}
+	for _, row := range rows {
+		if table := s.table(toString(row["table_name"])); table != nil {
+			table.Indexes = append(table.Indexes, Index{
+				Name:   toString(row["name"]),
+				Unique: toBool(row["is_unique"]),
+				Using:  unqualify(toString(row["using_clause"])),
+			})
+		}
+	}
+	return nil
+}
+
+// loadViews reads the views and materialized views of a schema
+func loadViews(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT c.relname AS name, c.relkind = 'm' AS materialized, pg_catalog.pg_get_viewdef(c.oid) AS definition
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('v', 'm')
+			AND `+fmt.Sprintf(notExtensionMember, "pg_catalog.pg_class", "c.oid")+`
+		ORDER BY c.relname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load views: %w", err)
+	}
+	for _, row := range rows {
+		s.Views = append(s.Views, View{
+			Name:         toString(row["name"]),
+			Materialized: toBool(row["materialized"]),
+			Definition:   strings.TrimSpace(unqualify(toString(row["definition"]))),
+		})
+	}
+	return nil
+}
+
+// loadFunctions reads the functions and procedures of a schema, aggregates
+// and window functions excepted
+func loadFunctions(ctx context.Context, pool *connection.Pool, s *Schema, unqualify func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			p.proname AS name,
+			pg_catalog.pg_get_function_identity_arguments(p.oid) AS arguments,
+			p.prokind = 'p' AS is_procedure,
+			pg_catalog.pg_get_functiondef(p.oid) AS definition
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = $1 AND p.prokind IN ('f', 'p')
+			AND `+fmt.Sprintf(notExtensionMember, "pg_catalog.pg_proc", "p.oid")+`
+		ORDER BY p.proname, arguments
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load functions: %w", err)
+	}
+	for _, row := range rows {
+		s.Functions = append(s.Functions, Function{
+			Name:       toString(row["name"]),
+			Arguments:  unqualify(toString(row["arguments"])),
+			Procedure:  toBool(row["is_procedure"]),
+			Definition: strings.TrimSpace(unqualify(toString(row["definition"]))),
+		})
+	}
+	return nil
+}
+
+// loadSequences reads the sequences of a schema, except those of identity
+// columns
+func loadSequences(ctx context.Context, pool *connection.Pool, s *Schema, _ func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			c.relname AS name,
+			pg_catalog.format_type(seq.seqtypid, NULL) AS data_type,
+			seq.seqstart, seq.seqmin, seq.seqmax, seq.seqincrement, seq.seqcache, seq.seqcycle
+		FROM pg_catalog.pg_sequence seq
+		JOIN pg_catalog.pg_class c ON c.oid = seq.seqrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_depend dep
+				WHERE dep.classid = 'pg_catalog.pg_class'::regclass AND dep.objid = c.oid AND dep.deptype IN ('i', 'e')
+			)
+		ORDER BY c.relname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load sequences: %w", err)
+	}
+	for _, row := range rows {
+		s.Sequences = append(s.Sequences, Sequence{
+			Name:      toString(row["name"]),
+			DataType:  toString(row["data_type"]),
+			Start:     toInt64(row["seqstart"]),
+			Min:       toInt64(row["seqmin"]),
+			Max:       toInt64(row["seqmax"]),
+			Increment: toInt64(row["seqincrement"]),
+			Cache:     toInt64(row["seqcache"]),
+			Cycle:     toBool(row["seqcycle"]),
+		})
+	}
+	return nil
+}
+
+// loadEnums reads the enum types of a schema
+func loadEnums(ctx context.Context, pool *connection.Pool, s *Schema, _ func(string) string) error {
+	rows, err := pool.Query(ctx, `
+		SELECT t.typname AS name, array_agg(e.enumlabel ORDER BY e.enumsortorder) AS labels
+		FROM pg_catalog.pg_type t
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+		WHERE n.nspname = $1
+			AND `+fmt.Sprintf(notExtensionMember, "pg_catalog.pg_type", "t.oid")+`
+		GROUP BY t.typname
+		ORDER BY t.typname
+	`, s.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load enums: %w", err)
+	}
+	for _, row := range rows {
+		s.Enums = append(s.Enums, Enum{
+			Name:   toString(row["name"]),
+			Labels: toStringSlice(row["labels"]),
+		})
+	}
+	return nil
+}
+
+// table returns the table with a name, or nil
+func (s *Schema) table(name string) *Table {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// bareIdentifier matches names the server does not quote
+var bareIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// unqualifier returns a function removing a schema from the names it
+// qualifies in a definition. The server qualifies names of the schema when
+// it is not on the search path.
+func unqualifier(schema string) func(string) string {
+	pattern := regexp.QuoteMeta(`"` + strings.ReplaceAll(schema, `"`, `""`) + `".`)
+	if bareIdentifier.MatchString(schema) {
+		pattern = `(?:` + regexp.QuoteMeta(schema+".") + `|` + pattern + `)`
+	}
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9_$".])` + pattern)
+	return func(s string) string {
+		return re.ReplaceAllString(s, "$1")
+	}
+}
+
+// toString converts a value from the database to a string
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+// toBool converts a value from the database to a bool
+func toBool(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// toInt64 converts an integer from the database to an int64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// toStringSlice converts an array from the database to strings
+func toStringSlice(v interface{}) []string {
+	items, _ := v.([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, toString(item))
+	}
+	return out
+}
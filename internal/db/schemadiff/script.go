@@ -0,0 +1,60 @@
+package schemadiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/db/edit"
+)
+
+// Script returns a migration bringing the target schema in line with the
+// source. Statements are ordered so that objects are dropped before what
+// they depend on and created after it, and run in a single transaction.
+func (c *Comparison) Script() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Migrates schema %s to match schema %s\n", c.Target, c.Source)
+	b.WriteString("-- Review before running: dropped tables and columns lose their data.\n\n")
+
+	var statements []Statement
+	for _, d := range c.Differences {
+		statements = append(statements, d.Statements...)
+	}
+	if len(statements) == 0 {
+		b.WriteString("-- The schemas match; there is nothing to migrate.\n")
+		return b.String()
+	}
+	sort.SliceStable(statements, func(i, j int) bool {
+		return statements[i].Phase < statements[j].Phase
+	})
+
+	b.WriteString("BEGIN;\n\n")
+	// Definitions name objects of the schema without qualifying them
+	fmt.Fprintf(&b, "SET LOCAL search_path TO %s, public;\n", edit.QuoteIdent(c.Target))
+	phase := -1
+	for _, stmt := range statements {
+		if stmt.Phase != phase {
+			b.WriteString("\n")
+			phase = stmt.Phase
+		}
+		b.WriteString(stmt.SQL)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nCOMMIT;\n")
+	return b.String()
+}
+
+// Counts returns the number of added, removed and changed objects
+func (c *Comparison) Counts() (added, removed, changed int) {
+	for _, d := range c.Differences {
+		switch d.Status {
+		case Added:
+			added++
+		case Removed:
+			removed++
+		case Changed:
+			changed++
+		}
+	}
+	return added, removed, changed
+}
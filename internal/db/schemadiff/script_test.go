@@ -0,0 +1,72 @@
+package schemadiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	target := &Schema{
+		Name: "app",
+		Tables: []Table{
+			{Name: "customers", Columns: []Column{{Name: "id", Type: "bigint", NotNull: true}}},
+		},
+		Views: []View{{Name: "customer_ids", Definition: " SELECT id\n   FROM customers;"}},
+	}
+	source := &Schema{
+		Name: "app",
+		Tables: []Table{
+			{Name: "customers", Columns: []Column{{Name: "id", Type: "bigint", NotNull: true}, {Name: "tier", Type: "tier"}}},
+			{
+				Name:        "orders",
+				Columns:     []Column{{Name: "customer_id", Type: "bigint"}},
+				Constraints: []Constraint{{Name: "orders_customer_id_fkey", Type: "f", Definition: "FOREIGN KEY (customer_id) REFERENCES customers(id)"}},
+				Indexes:     []Index{{Name: "orders_customer", Using: "btree (customer_id)"}},
+			},
+		},
+		Views: []View{{Name: "customer_ids", Definition: " SELECT id, tier\n   FROM customers;"}},
+		Enums: []Enum{{Name: "tier", Labels: []string{"basic"}}},
+	}
+
+	expected := `-- Migrates schema app to match schema app
+-- Review before running: dropped tables and columns lose their data.
+
+BEGIN;
+
+SET LOCAL search_path TO "app", public;
+
+DROP VIEW "app"."customer_ids";
+
+CREATE TYPE "app"."tier" AS ENUM ('basic');
+
+CREATE TABLE "app"."orders" (
+    "customer_id" bigint
+);
+
+ALTER TABLE "app"."customers" ADD COLUMN "tier" tier;
+
+ALTER TABLE "app"."orders" ADD CONSTRAINT "orders_customer_id_fkey" FOREIGN KEY (customer_id) REFERENCES customers(id);
+
+CREATE INDEX "orders_customer" ON "app"."orders" USING btree (customer_id);
+
+CREATE VIEW "app"."customer_ids" AS
+ SELECT id, tier
+   FROM customers;
+
+COMMIT;
+`
+	got := Compare(source, target).Script()
+	if got != expected {
+		t.Errorf("unexpected script:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "COMMIT;\n") {
+		t.Error("expected the migration to commit")
+	}
+}
+
+func TestCounts(t *testing.T) {
+	c := &Comparison{Differences: []Difference{{Status: Added}, {Status: Changed}, {Status: Added}, {Status: Removed}}}
+	if added, removed, changed := c.Counts(); added != 2 || removed != 1 || changed != 1 {
+		t.Errorf("expected 2, 1, 1, got %d, %d, %d", added, removed, changed)
+	}
+}
@@ -0,0 +1,557 @@
+package erd
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Detail is how much of each table a diagram shows
+type Detail int
+
+const (
+	DetailNames Detail = iota // Table names only
+	DetailKeys                // Primary and foreign key columns
+	DetailAll                 // Every column
+)
+
+// Rect is the position of a table in a diagram
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Diagram is a graph laid out and drawn with box-drawing characters. Tables
+// are placed in columns, to the right of the tables they reference, and
+// foreign keys point at the referenced table.
+type Diagram struct {
+	Width  int
+	Height int
+	Boxes  map[string]Rect // Table ID → box
+	Order  []string        // Table IDs column by column, top to bottom
+
+	cells [][]rune  // Text, or 0 where there is none
+	lines [][]uint8 // Directions of the lines through each cell
+}
+
+// Directions of the lines through a cell
+const (
+	lineUp uint8 = 1 << iota
+	lineRight
+	lineDown
+	lineLeft
+)
+
+// wideTail marks the cell covered by the second half of a wide character
+const wideTail rune = -1
+
+// lineRunes maps the directions of the lines through a cell to its character
+var lineRunes = map[uint8]rune{
+	lineUp:                                   '│',
+	lineDown:                                 '│',
+	lineUp | lineDown:                        '│',
+	lineLeft:                                 '─',
+	lineRight:                                '─',
+	lineLeft | lineRight:                     '─',
+	lineRight | lineDown:                     '┌',
+	lineLeft | lineDown:                      '┐',
+	lineUp | lineRight:                       '└',
+	lineUp | lineLeft:                        '┘',
+	lineUp | lineDown | lineRight:            '├',
+	lineUp | lineDown | lineLeft:             '┤',
+	lineLeft | lineRight | lineDown:          '┬',
+	lineLeft | lineRight | lineUp:            '┴',
+	lineUp | lineDown | lineLeft | lineRight: '┼',
+}
+
+// node is a table, or a point carrying a foreign key through a column it
+// spans
+type node struct {
+	table *Table // nil for a point
+	layer int    // Column of the diagram
+	index int    // Position in the column
+	text  []string
+	rows  map[string]int // Column name → line within the box
+	rect  Rect
+}
+
+// edge is a foreign key between two different tables
+type edge struct {
+	child, parent *node
+	rel           Relation
+	first, last   *segment
+}
+
+// segment is the part of an edge between two adjacent columns
+type segment struct {
+	left, right   *node
+	edge          *edge
+	leftY, rightY int
+	track         int // X of the vertical line between the columns
+}
+
+// Render lays out a graph and draws it
+func Render(g *Graph, detail Detail) *Diagram {
+	nodes := make([]*node, len(g.Tables))
+	byID := make(map[string]*node, len(g.Tables))
+	for i := range g.Tables {
+		nodes[i] = &node{table: &g.Tables[i]}
+		byID[g.Tables[i].ID()] = nodes[i]
+	}
+
+	selfReferencing := make(map[*node]bool)
+	var edges []*edge
+	for _, rel := range g.Relations {
+		child, parent := byID[rel.From], byID[rel.To]
+		if child == nil || parent == nil {
+			continue
+		}
+		if child == parent {
+			selfReferencing[child] = true
+			continue
+		}
+		edges = append(edges, &edge{child: child, parent: parent, rel: rel})
+	}
+
+	assignLayers(nodes, edges)
+	columns, segments := buildColumns(nodes, edges)
+	orderColumns(columns, segments)
+	for _, n := range nodes {
+		n.text, n.rows = boxText(g, *n.table, detail, selfReferencing[n])
+		n.rect.Width = maxWidth(n.text) + 4
+		n.rect.Height = len(n.text) + 2
+		if len(n.text) > 1 {
+			n.rect.Height++ // Separator below the title
+		}
+	}
+
+	d := place(columns, segments)
+	for _, column := range columns {
+		for _, n := range column {
+			if n.table == nil {
+				d.hline(n.rect.Y, n.rect.X, n.rect.X+n.rect.Width-1)
+				continue
+			}
+			d.drawBox(n.rect, n.text)
+			d.Boxes[n.table.ID()] = n.rect
+			d.Order = append(d.Order, n.table.ID())
+		}
+	}
+	for _, s := range segments {
+		d.hline(s.leftY, s.left.rect.X+s.left.rect.Width-1, s.track)
+		d.vline(s.track, s.leftY, s.rightY)
+		d.hline(s.rightY, s.track, s.right.rect.X)
+	}
+	for _, e := range edges {
+		// Arrows point at the referenced table
+		if e.parent == e.first.left {
+			d.set(e.parent.rect.X+e.parent.rect.Width, e.first.leftY, '◀')
+		} else {
+			d.set(e.parent.rect.X-1, e.last.rightY, '▶')
+		}
+	}
+	return d
+}
+
+// assignLayers puts each table one column right of the rightmost table it
+// references. Foreign keys closing a cycle are ignored.
+func assignLayers(nodes []*node, edges []*edge) {
+	parents := make(map[*node][]*node)
+	for _, e := range edges {
+		parents[e.child] = append(parents[e.child], e.parent)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*node]int)
+	var visit func(n *node)
+	visit = func(n *node) {
+		state[n] = visiting
+		for _, p := range parents[n] {
+			if state[p] == visiting {
+				continue
+			}
+			if state[p] == unvisited {
+				visit(p)
+			}
+			n.layer = max(n.layer, p.layer+1)
+		}
+		state[n] = visited
+	}
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+}
+
+// buildColumns groups the nodes by column and splits the edges into
+// segments between adjacent columns, adding points to the columns an edge
+// spans
+func buildColumns(nodes []*node, edges []*edge) ([][]*node, []*segment) {
+	count := 0
+	for _, n := range nodes {
+		count = max(count, n.layer+1)
+	}
+	columns := make([][]*node, count)
+
+	// Tables without foreign keys go last in their column
+	linked := make(map[*node]bool)
+	for _, e := range edges {
+		linked[e.child], linked[e.parent] = true, true
+	}
+	for _, n := range nodes {
+		if linked[n] {
+			columns[n.layer] = append(columns[n.layer], n)
+		}
+	}
+	for _, n := range nodes {
+		if !linked[n] {
+			columns[n.layer] = append(columns[n.layer], n)
+		}
+	}
+
+	var segments []*segment
+	for _, e := range edges {
+		left, right := e.parent, e.child
+		if left.layer > right.layer {
+			left, right = right, left
+		}
+		prev := left
+		for layer := left.layer + 1; layer <= right.layer; layer++ {
+			next := right
+			if layer < right.layer {
+				next = &node{layer: layer}
+				columns[layer] = append(columns[layer], next)
+			}
+			s := &segment{left: prev, right: next, edge: e}
+			if e.first == nil {
+				e.first = s
+			}
+			e.last = s
+			segments = append(segments, s)
+			prev = next
+		}
+	}
+	return columns, segments
+}
+
+// orderColumns orders the nodes of each column by the average position of
+// their neighbours, sweeping back and forth, to reduce crossing lines
+func orderColumns(columns [][]*node, segments []*segment) {
+	for _, column := range columns {
+		for i, n := range column {
+			n.index = i
+		}
+	}
+	for sweep := 0; sweep < 4; sweep++ {
+		for i := 1; i < len(columns); i++ {
+			orderByNeighbours(columns[i], segments, true)
+		}
+		for i := len(columns) - 2; i >= 0; i-- {
+			orderByNeighbours(columns[i], segments, false)
+		}
+	}
+}
+
+// orderByNeighbours orders a column by the average position of the
+// neighbours in the column to its left, or to its right
+func orderByNeighbours(column []*node, segments []*segment, fromLeft bool) {
+	sum := make(map[*node]float64)
+	count := make(map[*node]int)
+	for _, s := range segments {
+		n, neighbour := s.right, s.left
+		if !fromLeft {
+			n, neighbour = s.left, s.right
+		}
+		sum[n] += float64(neighbour.index)
+		count[n]++
+	}
+
+	keys := make(map[*node]float64, len(column))
+	for _, n := range column {
+		keys[n] = float64(n.index)
+		if count[n] > 0 {
+			keys[n] = sum[n] / float64(count[n])
+		}
+	}
+	sort.SliceStable(column, func(i, j int) bool {
+		return keys[column[i]] < keys[column[j]]
+	})
+	for i, n := range column {
+		n.index = i
+	}
+}
+
+// boxText returns the lines of a table's box: its name, then the columns
+// shown at the detail level, with the line of each column
+func boxText(g *Graph, t Table, detail Detail, selfReferencing bool) ([]string, map[string]int) {
+	title := g.Label(t)
+	if selfReferencing {
+		title += " ↺"
+	}
+
+	var shown []Column
+	for _, col := range t.Columns {
+		if detail == DetailAll || detail == DetailKeys && (col.PrimaryKey || col.ForeignKey) {
+			shown = append(shown, col)
+		}
+	}
+	nameWidth := 0
+	for _, col := range shown {
+		nameWidth = max(nameWidth, runewidth.StringWidth(col.Name))
+	}
+
+	text := []string{title}
+	rows := make(map[string]int, len(shown))
+	for i, col := range shown {
+		marker := "  "
+		if col.PrimaryKey {
+			marker = "PK"
+		} else if col.ForeignKey {
+			marker = "FK"
+		}
+		line := marker + " " + runewidth.FillRight(col.Name, nameWidth) + "  " + col.Type
+		text = append(text, strings.TrimRight(line, " "))
+		rows[col.Name] = i + 3 // Border, title and separator come first
+	}
+	return text, rows
+}
+
+// maxWidth returns the display width of the widest line
+func maxWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		width = max(width, runewidth.StringWidth(line))
+	}
+	return width
+}
+
+// place positions the nodes, stacked in their columns, and routes each
+// segment through its own vertical track between the columns
+func place(columns [][]*node, segments []*segment) *Diagram {
+	x, height := 0, 0
+	gaps := make([][]*segment, len(columns))
+	for _, s := range segments {
+		gaps[s.left.layer] = append(gaps[s.left.layer], s)
+	}
+
+	trackX := make([]int, len(columns))
+	for i, column := range columns {
+		width := 1
+		for _, n := range column {
+			if n.table != nil {
+				width = max(width, n.rect.Width)
+			}
+		}
+		y := 0
+		for _, n := range column {
+			if n.table == nil {
+				n.rect = Rect{Width: width, Height: 1}
+			}
+			n.rect.X, n.rect.Y = x, y
+			y += n.rect.Height + 1
+		}
+		height = max(height, y-1)
+		x += width
+		if i < len(columns)-1 {
+			// Margin, tracks, then room for an arrow and a margin
+			trackX[i] = x + 2
+			x += len(gaps[i]) + 4
+		}
+	}
+
+	for _, s := range segments {
+		s.leftY = endY(s, s.left)
+		s.rightY = endY(s, s.right)
+	}
+	for i, gap := range gaps {
+		for j, s := range orderTracks(gap) {
+			s.track = trackX[i] + j
+		}
+	}
+
+	d := &Diagram{
+		Width:  x,
+		Height: height,
+		Boxes:  make(map[string]Rect),
+		cells:  make([][]rune, height),
+		lines:  make([][]uint8, height),
+	}
+	for y := range d.cells {
+		d.cells[y] = make([]rune, x)
+		d.lines[y] = make([]uint8, x)
+	}
+	return d
+}
+
+// orderTracks orders the segments of a gap from top to bottom, except that
+// a segment leaving a row turns before any segment entering the same row, so
+// that their horizontal lines do not overlap
+func orderTracks(gap []*segment) []*segment {
+	remaining := slices.Clone(gap)
+	sort.SliceStable(remaining, func(a, b int) bool {
+		if remaining[a].leftY != remaining[b].leftY {
+			return remaining[a].leftY < remaining[b].leftY
+		}
+		return remaining[a].rightY < remaining[b].rightY
+	})
+
+	ordered := make([]*segment, 0, len(gap))
+	for len(remaining) > 0 {
+		next := 0 // Rows swapping lines cannot both be satisfied
+		for i, s := range remaining {
+			ready := true
+			for _, other := range remaining {
+				if other != s && other.leftY == s.rightY {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		ordered = append(ordered, remaining[next])
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+	return ordered
+}
+
+// endY returns the line where a segment meets a node: the row of the
+// foreign key's first column when it is shown, otherwise the title
+func endY(s *segment, n *node) int {
+	if n.table == nil {
+		return n.rect.Y
+	}
+	columns := s.edge.rel.ToColumns
+	if n == s.edge.child {
+		columns = s.edge.rel.FromColumns
+	}
+	if len(columns) > 0 {
+		if row, ok := n.rows[columns[0]]; ok {
+			return n.rect.Y + row
+		}
+	}
+	return n.rect.Y + 1
+}
+
+// drawBox draws a table's box with its title above the columns
+func (d *Diagram) drawBox(r Rect, text []string) {
+	right, bottom := r.X+r.Width-1, r.Y+r.Height-1
+	d.hline(r.Y, r.X, right)
+	d.hline(bottom, r.X, right)
+	d.vline(r.X, r.Y, bottom)
+	d.vline(right, r.Y, bottom)
+	d.text(r.X+2, r.Y+1, text[0])
+	if len(text) > 1 {
+		d.hline(r.Y+2, r.X, right)
+		for i, line := range text[1:] {
+			d.text(r.X+2, r.Y+3+i, line)
+		}
+	}
+}
+
+// hline draws a horizontal line between two cells of a row
+func (d *Diagram) hline(y, x1, x2 int) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	for x := x1; x <= x2; x++ {
+		if x > x1 {
+			d.addLine(x, y, lineLeft)
+		}
+		if x < x2 {
+			d.addLine(x, y, lineRight)
+		}
+	}
+}
+
+// vline draws a vertical line between two cells of a column
+func (d *Diagram) vline(x, y1, y2 int) {
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	for y := y1; y <= y2; y++ {
+		if y > y1 {
+			d.addLine(x, y, lineUp)
+		}
+		if y < y2 {
+			d.addLine(x, y, lineDown)
+		}
+	}
+}
+
+// addLine adds a line direction to a cell
+func (d *Diagram) addLine(x, y int, dir uint8) {
+	if y >= 0 && y < d.Height && x >= 0 && x < d.Width {
+		d.lines[y][x] |= dir
+	}
+}
+
+// set puts a character in a cell, over any line
+func (d *Diagram) set(x, y int, r rune) {
+	if y >= 0 && y < d.Height && x >= 0 && x < d.Width {
+		d.cells[y][x] = r
+	}
+}
+
+// text writes a string from a cell, wide characters taking two cells
+func (d *Diagram) text(x, y int, s string) {
+	for _, r := range s {
+		width := runewidth.RuneWidth(r)
+		if width == 0 {
+			continue
+		}
+		d.set(x, y, r)
+		if width == 2 {
+			d.set(x+1, y, wideTail)
+		}
+		x += width
+	}
+}
+
+// Line returns width cells of a line of the diagram starting at x, padded
+// with spaces outside the diagram
+func (d *Diagram) Line(y, x, width int) string {
+	var b strings.Builder
+	for i := x; i < x+width; i++ {
+		if y < 0 || y >= d.Height || i < 0 || i >= d.Width {
+			b.WriteByte(' ')
+			continue
+		}
+		r := d.cells[y][i]
+		switch {
+		case r == wideTail:
+			if i == x {
+				b.WriteByte(' ') // First half is cut off
+			}
+		case r != 0:
+			if runewidth.RuneWidth(r) == 2 && i == x+width-1 {
+				b.WriteByte(' ') // Second half is cut off
+			} else {
+				b.WriteRune(r)
+			}
+		case d.lines[y][i] != 0:
+			b.WriteRune(lineRunes[d.lines[y][i]])
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// String returns the whole diagram, without trailing spaces
+func (d *Diagram) String() string {
+	lines := make([]string, d.Height)
+	for y := range lines {
+		lines[y] = strings.TrimRight(d.Line(y, 0, d.Width), " ")
+	}
+	return strings.Join(lines, "\n")
+}
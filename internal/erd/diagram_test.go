@@ -0,0 +1,99 @@
+package erd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	g := &Graph{
+		Schema: "public",
+		Tables: []Table{
+			{Schema: "public", Name: "users", Columns: []Column{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "email", Type: "text"},
+			}},
+			{Schema: "public", Name: "orders", Columns: []Column{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "user_id", Type: "integer", ForeignKey: true},
+				{Name: "parent_id", Type: "integer", ForeignKey: true},
+			}},
+		},
+		Relations: []Relation{
+			{Name: "orders_user_id_fkey", From: "public.orders", To: "public.users", FromColumns: []string{"user_id"}, ToColumns: []string{"id"}},
+			{Name: "orders_parent_id_fkey", From: "public.orders", To: "public.orders", FromColumns: []string{"parent_id"}, ToColumns: []string{"id"}},
+		},
+	}
+
+	want := strings.Join([]string{
+		"┌────────────────┐     ┌───────────────────────┐",
+		"│ users          │     │ orders ↺              │",
+		"├────────────────┤     ├───────────────────────┤",
+		"│ PK id  integer ├◀─┐  │ PK id         integer │",
+		"└────────────────┘  └──┤ FK user_id    integer │",
+		"                       │ FK parent_id  integer │",
+		"                       └───────────────────────┘",
+	}, "\n")
+	d := Render(g, DetailKeys)
+	if got := d.String(); got != want {
+		t.Errorf("Render(DetailKeys) =\n%s\nwant\n%s", got, want)
+	}
+	if got := d.Boxes["public.orders"]; got != (Rect{X: 23, Y: 0, Width: 25, Height: 7}) {
+		t.Errorf("orders box = %+v", got)
+	}
+	if !slices.Equal(d.Order, []string{"public.users", "public.orders"}) {
+		t.Errorf("order = %v", d.Order)
+	}
+
+	want = strings.Join([]string{
+		"┌───────┐     ┌──────────┐",
+		"│ users ├◀────┤ orders ↺ │",
+		"└───────┘     └──────────┘",
+	}, "\n")
+	if got := Render(g, DetailNames).String(); got != want {
+		t.Errorf("Render(DetailNames) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderCycle(t *testing.T) {
+	g := &Graph{
+		Schema: "public",
+		Tables: []Table{{Schema: "public", Name: "a"}, {Schema: "public", Name: "b"}},
+		Relations: []Relation{
+			{Name: "a_b", From: "public.a", To: "public.b"},
+			{Name: "b_a", From: "public.b", To: "public.a"},
+		},
+	}
+
+	d := Render(g, DetailNames)
+	if d.Boxes["public.a"].X == d.Boxes["public.b"].X {
+		t.Fatalf("tables of a cycle share a column:\n%s", d)
+	}
+	// One foreign key points left, the other right
+	out := d.String()
+	if !strings.Contains(out, "◀") || !strings.Contains(out, "▶") {
+		t.Errorf("want arrows in both directions:\n%s", out)
+	}
+}
+
+func TestDiagramLine(t *testing.T) {
+	g := &Graph{Schema: "public", Tables: []Table{{Schema: "public", Name: "顧客"}}}
+	d := Render(g, DetailNames)
+
+	tests := []struct {
+		x, width int
+		want     string
+	}{
+		{0, 8, "│ 顧客 │"},
+		{-2, 4, "  │ "},
+		{3, 3, " 客"},  // First half of 顧 is cut off
+		{0, 3, "│  "}, // Second half of 顧 is cut off
+		{6, 4, " │  "},
+	}
+	for _, tt := range tests {
+		if got := d.Line(1, tt.x, tt.width); got != tt.want {
+			t.Errorf("Line(1, %d, %d) = %q, want %q", tt.x, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package erd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// mermaidUnsafe matches characters Mermaid does not allow in entity,
+// attribute and type names
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_\-()\[\]]+`)
+
+// Mermaid returns the graph as a Mermaid entity-relationship diagram
+func Mermaid(g *Graph) string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+
+	for _, table := range g.Tables {
+		name := mermaidName(g.Label(table))
+		if len(table.Columns) == 0 {
+			fmt.Fprintf(&b, "    %s\n", name)
+			continue
+		}
+		fmt.Fprintf(&b, "    %s {\n", name)
+		for _, col := range table.Columns {
+			var keys []string
+			if col.PrimaryKey {
+				keys = append(keys, "PK")
+			}
+			if col.ForeignKey {
+				keys = append(keys, "FK")
+			}
+			line := mermaidName(col.Type) + " " + mermaidName(col.Name)
+			if len(keys) > 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			fmt.Fprintf(&b, "        %s\n", line)
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, rel := range g.Relations {
+		from, to := g.Table(rel.From), g.Table(rel.To)
+		if from == nil || to == nil {
+			continue
+		}
+		// The referenced row is optional when the foreign key can be null
+		parent := "||"
+		if g.Optional(rel) {
+			parent = "|o"
+		}
+		fmt.Fprintf(&b, "    %s %s--o{ %s : \"%s\"\n", mermaidName(g.Label(*to)), parent, mermaidName(g.Label(*from)),
+			strings.ReplaceAll(rel.Name, `"`, "'"))
+	}
+	return b.String()
+}
+
+// mermaidName replaces the characters Mermaid does not allow in a name
+func mermaidName(name string) string {
+	name = mermaidUnsafe.ReplaceAllString(name, "_")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// DOT returns the graph in the Graphviz DOT language, referenced tables on
+// the left as in the terminal diagram
+func DOT(g *Graph) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Schema))
+	b.WriteString("    rankdir=RL;\n")
+	b.WriteString("    node [shape=record, fontname=\"Helvetica\"];\n")
+	b.WriteString("    edge [arrowhead=normal];\n\n")
+
+	for _, table := range g.Tables {
+		label := dotEscape(g.Label(table))
+		if len(table.Columns) > 0 {
+			var cols strings.Builder
+			for _, col := range table.Columns {
+				marker := ""
+				if col.PrimaryKey {
+					marker = "PK "
+				} else if col.ForeignKey {
+					marker = "FK "
+				}
+				cols.WriteString(dotEscape(marker+col.Name+" : "+col.Type) + "\\l")
+			}
+			label = "{" + label + "|" + cols.String() + "}"
+		}
+		attrs := "label=\"" + label + "\""
+		if table.External {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "    %s [%s];\n", dotQuote(table.ID()), attrs)
+	}
+
+	if len(g.Relations) > 0 {
+		b.WriteString("\n")
+	}
+	for _, rel := range g.Relations {
+		label := fmt.Sprintf("%s\\n(%s) → (%s)", dotEscape(rel.Name),
+			dotEscape(strings.Join(rel.FromColumns, ", ")), dotEscape(strings.Join(rel.ToColumns, ", ")))
+		attrs := "label=\"" + label + "\""
+		if g.Optional(rel) {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "    %s -> %s [%s];\n", dotQuote(rel.From), dotQuote(rel.To), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote returns a DOT identifier as a quoted string
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + "\""
+}
+
+// dotEscape escapes text for a record label, where braces, bars and angle
+// brackets have a meaning
+func dotEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`,
+	).Replace(s)
+}
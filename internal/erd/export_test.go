@@ -0,0 +1,49 @@
+package erd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMermaid(t *testing.T) {
+	got := Mermaid(shopGraph())
+
+	for _, want := range []string{
+		"erDiagram\n",
+		"    users {\n        integer id PK\n        text email\n    }\n",
+		"        integer user_id FK\n",
+		"    audit_accounts\n",
+		`    users |o--o{ orders : "orders_user_id_fkey"`,
+		`    orders ||--o{ items : "items_order_id_fkey"`,
+		`    audit_accounts ||--o{ items : "items_created_by_fkey"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid output is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	got := DOT(shopGraph())
+
+	for _, want := range []string{
+		"digraph \"public\" {\n",
+		`"public.users" [label="{users|PK id : integer\lemail : text\l}"];`,
+		`"audit.accounts" [label="audit.accounts", style=dashed];`,
+		`"public.orders" -> "public.users" [label="orders_user_id_fkey\n(user_id) → (id)", style=dashed];`,
+		`"public.items" -> "public.orders" [label="items_order_id_fkey\n(order_id) → (id)"];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("DOT output is missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "}\n") {
+		t.Errorf("DOT output is not closed:\n%s", got)
+	}
+}
+
+func TestDotEscape(t *testing.T) {
+	if got := dotEscape(`a|b{c}<d>"e"`); got != `a\|b\{c\}\<d\>\"e\"` {
+		t.Errorf("dotEscape = %q", got)
+	}
+}
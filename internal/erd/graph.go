@@ -0,0 +1,172 @@
+package erd
+
+import (
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// Column is a column of a table in the diagram
+type Column struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+	ForeignKey bool
+	Nullable   bool
+}
+
+// Table is a table of the diagram
+type Table struct {
+	Schema   string
+	Name     string
+	Columns  []Column
+	External bool // Referenced from the schema but in another one; its columns are not loaded
+}
+
+// ID returns the schema-qualified name identifying the table
+func (t Table) ID() string {
+	return t.Schema + "." + t.Name
+}
+
+// Relation is a foreign key from a table to the table it references
+type Relation struct {
+	Name        string
+	From        string // ID of the referencing table
+	To          string // ID of the referenced table
+	FromColumns []string
+	ToColumns   []string
+}
+
+// Graph is the tables of a schema and the foreign keys between them
+type Graph struct {
+	Schema    string
+	Tables    []Table
+	Relations []Relation
+}
+
+// TableMetadata is the catalog information a table of the graph is built from
+type TableMetadata struct {
+	Name        string
+	Columns     []models.ColumnDetail
+	Constraints []models.Constraint
+}
+
+// NewGraph builds the graph of a schema from its tables. Tables of other
+// schemas referenced by foreign keys are added as external tables.
+func NewGraph(schema string, tables []TableMetadata) *Graph {
+	g := &Graph{Schema: schema}
+	for _, meta := range tables {
+		table := Table{Schema: schema, Name: meta.Name}
+		for _, col := range meta.Columns {
+			table.Columns = append(table.Columns, Column{
+				Name:       col.Name,
+				Type:       col.DataType,
+				PrimaryKey: col.IsPrimaryKey,
+				ForeignKey: col.IsForeignKey,
+				Nullable:   col.IsNullable,
+			})
+		}
+		g.Tables = append(g.Tables, table)
+
+		for _, con := range meta.Constraints {
+			if con.Type != "f" || con.ForeignTable == "" {
+				continue
+			}
+			g.Relations = append(g.Relations, Relation{
+				Name:        con.Name,
+				From:        table.ID(),
+				To:          con.ForeignTable,
+				FromColumns: con.Columns,
+				ToColumns:   con.ForeignCols,
+			})
+		}
+	}
+
+	for _, rel := range g.Relations {
+		if g.Table(rel.To) != nil {
+			continue
+		}
+		schema, name, _ := strings.Cut(rel.To, ".")
+		g.Tables = append(g.Tables, Table{Schema: schema, Name: name, External: true})
+	}
+	return g
+}
+
+// Table returns the table with an ID, or nil
+func (g *Graph) Table(id string) *Table {
+	for i := range g.Tables {
+		if g.Tables[i].ID() == id {
+			return &g.Tables[i]
+		}
+	}
+	return nil
+}
+
+// Label returns the name shown for a table, qualified when it is not in the
+// graph's schema
+func (g *Graph) Label(t Table) string {
+	if t.Schema == g.Schema {
+		return t.Name
+	}
+	return t.ID()
+}
+
+// Optional reports whether the referencing columns of a relation can be
+// null, i.e. whether a row may reference nothing
+func (g *Graph) Optional(rel Relation) bool {
+	table := g.Table(rel.From)
+	if table == nil {
+		return false
+	}
+	for _, col := range table.Columns {
+		for _, name := range rel.FromColumns {
+			if col.Name == name && col.Nullable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Neighbourhood returns the part of the graph within hops foreign keys of a
+// table, following them in either direction
+func (g *Graph) Neighbourhood(id string, hops int) *Graph {
+	distance := map[string]int{id: 0}
+	frontier := []string{id}
+	for hop := 1; hop <= hops && len(frontier) > 0; hop++ {
+		var next []string
+		for _, current := range frontier {
+			for _, rel := range g.Relations {
+				var other string
+				switch current {
+				case rel.From:
+					other = rel.To
+				case rel.To:
+					other = rel.From
+				default:
+					continue
+				}
+				if _, seen := distance[other]; !seen {
+					distance[other] = hop
+					next = append(next, other)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sub := &Graph{Schema: g.Schema}
+	for _, table := range g.Tables {
+		if _, ok := distance[table.ID()]; ok {
+			sub.Tables = append(sub.Tables, table)
+		}
+	}
+	for _, rel := range g.Relations {
+		_, from := distance[rel.From]
+		_, to := distance[rel.To]
+		if from && to {
+			sub.Relations = append(sub.Relations, rel)
+		}
+	}
+	return sub
+}
@@ -0,0 +1,107 @@
+package erd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// shopGraph is users ← orders ← items, with items referencing users too,
+// and a table without foreign keys
+func shopGraph() *Graph {
+	return NewGraph("public", []TableMetadata{
+		{
+			Name: "users",
+			Columns: []models.ColumnDetail{
+				{Name: "id", DataType: "integer", IsPrimaryKey: true},
+				{Name: "email", DataType: "text"},
+			},
+		},
+		{
+			Name: "orders",
+			Columns: []models.ColumnDetail{
+				{Name: "id", DataType: "integer", IsPrimaryKey: true},
+				{Name: "user_id", DataType: "integer", IsForeignKey: true, IsNullable: true},
+			},
+			Constraints: []models.Constraint{
+				{Name: "orders_pkey", Type: "p", Columns: []string{"id"}},
+				{Name: "orders_user_id_fkey", Type: "f", Columns: []string{"user_id"}, ForeignTable: "public.users", ForeignCols: []string{"id"}},
+			},
+		},
+		{
+			Name: "items",
+			Columns: []models.ColumnDetail{
+				{Name: "order_id", DataType: "integer", IsForeignKey: true},
+				{Name: "created_by", DataType: "integer", IsForeignKey: true},
+			},
+			Constraints: []models.Constraint{
+				{Name: "items_order_id_fkey", Type: "f", Columns: []string{"order_id"}, ForeignTable: "public.orders", ForeignCols: []string{"id"}},
+				{Name: "items_created_by_fkey", Type: "f", Columns: []string{"created_by"}, ForeignTable: "audit.accounts", ForeignCols: []string{"id"}},
+			},
+		},
+		{Name: "settings"},
+	})
+}
+
+func tableIDs(g *Graph) []string {
+	var ids []string
+	for _, table := range g.Tables {
+		ids = append(ids, table.ID())
+	}
+	return ids
+}
+
+func TestNewGraph(t *testing.T) {
+	g := shopGraph()
+
+	want := []string{"public.users", "public.orders", "public.items", "public.settings", "audit.accounts"}
+	if got := tableIDs(g); !slices.Equal(got, want) {
+		t.Errorf("tables = %v, want %v", got, want)
+	}
+	if external := g.Table("audit.accounts"); external == nil || !external.External {
+		t.Errorf("audit.accounts = %+v, want an external table", external)
+	}
+	if len(g.Relations) != 3 {
+		t.Fatalf("got %d relations, want 3", len(g.Relations))
+	}
+	rel := g.Relations[0]
+	if rel.From != "public.orders" || rel.To != "public.users" || !slices.Equal(rel.FromColumns, []string{"user_id"}) {
+		t.Errorf("relation = %+v", rel)
+	}
+	if !g.Optional(rel) {
+		t.Error("orders.user_id is nullable, want an optional relation")
+	}
+	if g.Optional(g.Relations[1]) {
+		t.Error("items.order_id is not nullable, want a required relation")
+	}
+	if got := g.Label(*g.Table("audit.accounts")); got != "audit.accounts" {
+		t.Errorf("label = %q, want the qualified name", got)
+	}
+}
+
+func TestNeighbourhood(t *testing.T) {
+	g := shopGraph()
+
+	tests := []struct {
+		table     string
+		hops      int
+		tables    []string
+		relations int
+	}{
+		{"public.users", 0, []string{"public.users"}, 0},
+		{"public.users", 1, []string{"public.users", "public.orders"}, 1},
+		{"public.users", 2, []string{"public.users", "public.orders", "public.items"}, 2},
+		{"public.items", 1, []string{"public.orders", "public.items", "audit.accounts"}, 2},
+		{"public.settings", 3, []string{"public.settings"}, 0},
+	}
+	for _, tt := range tests {
+		sub := g.Neighbourhood(tt.table, tt.hops)
+		if got := tableIDs(sub); !slices.Equal(got, tt.tables) {
+			t.Errorf("Neighbourhood(%s, %d) tables = %v, want %v", tt.table, tt.hops, got, tt.tables)
+		}
+		if len(sub.Relations) != tt.relations {
+			t.Errorf("Neighbourhood(%s, %d) has %d relations, want %d", tt.table, tt.hops, len(sub.Relations), tt.relations)
+		}
+	}
+}
@@ -0,0 +1,517 @@
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Thresholds used to flag plan nodes
+const (
+	// ExpensiveShare is the share of total time (or cost) above which a node is flagged
+	ExpensiveShare = 0.2
+	// EstimateMissFactor is the ratio between estimated and actual rows that is flagged
+	EstimateMissFactor = 10.0
+	// EstimateMissMinRows ignores misses where both estimate and actual rows are tiny
+	EstimateMissMinRows = 50.0
+	// LargeTableRows is the table size above which sequential scans are flagged
+	LargeTableRows = 10000.0
+	// HeavyReadBlocks is the number of blocks read from disk that is flagged
+	HeavyReadBlocks = 1000
+)
+
+// FindingKind categorizes a plan finding
+type FindingKind int
+
+const (
+	FindingExpensive FindingKind = iota
+	FindingEstimateMiss
+	FindingSeqScan
+	FindingBuffers
+)
+
+// Finding is a potential problem detected on a plan node
+type Finding struct {
+	Kind    FindingKind
+	Message string
+}
+
+// Node is a single node of an execution plan
+type Node struct {
+	Type     string                 // "Node Type", e.g. "Seq Scan"
+	Props    map[string]interface{} // All plan properties except child plans
+	Children []*Node
+	Parent   *Node
+	Level    int
+
+	StartupCost float64
+	TotalCost   float64
+	PlanRows    float64
+	PlanWidth   float64
+
+	HasActual         bool
+	ActualStartupTime float64 // Milliseconds, per loop
+	ActualTotalTime   float64 // Milliseconds, per loop
+	ActualRows        float64 // Per loop
+	Loops             float64
+
+	SelfCost  float64 // Cost excluding child nodes
+	SelfTime  float64 // Milliseconds over all loops, excluding child nodes
+	TableRows float64 // Estimated table size for scans, -1 if unknown
+
+	Findings []Finding
+}
+
+// Plan is a parsed EXPLAIN (FORMAT JSON) result
+type Plan struct {
+	Root          *Node
+	PlanningTime  float64 // Milliseconds, 0 if not reported
+	ExecutionTime float64 // Milliseconds, 0 if not analyzed
+	Analyzed      bool
+	Triggers      []string
+
+	// Set by the caller after running the statement
+	SQL        string
+	RolledBack bool
+}
+
+// Parse parses the output of EXPLAIN (FORMAT JSON)
+func Parse(data []byte) (*Plan, error) {
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid EXPLAIN output: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty EXPLAIN output")
+	}
+
+	top := raw[0]
+	rootProps, ok := top["Plan"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("EXPLAIN output has no plan")
+	}
+
+	plan := &Plan{
+		Root:          buildNode(rootProps, nil, 0),
+		PlanningTime:  number(top["Planning Time"]),
+		ExecutionTime: number(top["Execution Time"]),
+	}
+	plan.Analyzed = plan.Root.HasActual
+
+	if triggers, ok := top["Triggers"].([]interface{}); ok {
+		for _, t := range triggers {
+			if trigger, ok := t.(map[string]interface{}); ok {
+				plan.Triggers = append(plan.Triggers, fmt.Sprintf("%s: %.3f ms (%d calls)",
+					str(trigger["Trigger Name"]), number(trigger["Time"]), int64(number(trigger["Calls"]))))
+			}
+		}
+	}
+
+	plan.Annotate()
+	return plan, nil
+}
+
+// buildNode converts a JSON plan object into a node tree
+func buildNode(props map[string]interface{}, parent *Node, level int) *Node {
+	node := &Node{
+		Type:      str(props["Node Type"]),
+		Props:     make(map[string]interface{}, len(props)),
+		Parent:    parent,
+		Level:     level,
+		TableRows: -1,
+	}
+	for key, value := range props {
+		if key != "Plans" {
+			node.Props[key] = value
+		}
+	}
+
+	node.StartupCost = number(props["Startup Cost"])
+	node.TotalCost = number(props["Total Cost"])
+	node.PlanRows = number(props["Plan Rows"])
+	node.PlanWidth = number(props["Plan Width"])
+
+	if _, ok := props["Actual Total Time"]; ok {
+		node.HasActual = true
+		node.ActualStartupTime = number(props["Actual Startup Time"])
+		node.ActualTotalTime = number(props["Actual Total Time"])
+		node.ActualRows = number(props["Actual Rows"])
+		node.Loops = number(props["Actual Loops"])
+	} else if _, ok := props["Actual Loops"]; ok {
+		// ANALYZE with TIMING OFF, or a node that never executed
+		node.HasActual = true
+		node.ActualRows = number(props["Actual Rows"])
+		node.Loops = number(props["Actual Loops"])
+	}
+
+	if children, ok := props["Plans"].([]interface{}); ok {
+		for _, c := range children {
+			if childProps, ok := c.(map[string]interface{}); ok {
+				node.Children = append(node.Children, buildNode(childProps, node, level+1))
+			}
+		}
+	}
+	return node
+}
+
+// Nodes returns all nodes in depth-first order
+func (p *Plan) Nodes() []*Node {
+	var nodes []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		nodes = append(nodes, n)
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	if p.Root != nil {
+		walk(p.Root)
+	}
+	return nodes
+}
+
+// Relation is a table or view read by a plan node
+type Relation struct {
+	Schema string // Empty unless the plan was made with VERBOSE
+	Name   string
+}
+
+// Key returns the relation name, schema qualified when known, as used by
+// SetTableRows
+func (r Relation) Key() string {
+	if r.Schema != "" {
+		return r.Schema + "." + r.Name
+	}
+	return r.Name
+}
+
+// SeqScanRelations returns the relations read by sequential scans
+func (p *Plan) SeqScanRelations() []Relation {
+	seen := make(map[Relation]bool)
+	var relations []Relation
+	for _, n := range p.Nodes() {
+		if n.Type != "Seq Scan" {
+			continue
+		}
+		relation := Relation{Schema: str(n.Props["Schema"]), Name: str(n.Props["Relation Name"])}
+		if relation.Name != "" && !seen[relation] {
+			seen[relation] = true
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// SetTableRows records estimated table sizes (keyed by Relation.Key)
+// and recomputes the findings
+func (p *Plan) SetTableRows(sizes map[string]float64) {
+	for _, n := range p.Nodes() {
+		if size, ok := sizes[n.RelationName()]; ok {
+			n.TableRows = size
+		}
+	}
+	p.Annotate()
+}
+
+// Annotate computes exclusive cost and time for each node and detects findings
+func (p *Plan) Annotate() {
+	nodes := p.Nodes()
+	if len(nodes) == 0 {
+		return
+	}
+
+	root := p.Root
+	totalTime := p.ExecutionTime
+	if rootTime := root.ActualTotalTime * max(root.Loops, 1); rootTime > totalTime {
+		totalTime = rootTime
+	}
+
+	for _, n := range nodes {
+		n.Findings = nil
+
+		// Exclusive cost and time
+		n.SelfCost = n.TotalCost
+		n.SelfTime = n.ActualTotalTime * n.Loops
+		for _, c := range n.Children {
+			// InitPlans run separately from their parent and are not included in its cost
+			if c.ParentRelationship() == "InitPlan" {
+				continue
+			}
+			n.SelfCost -= c.TotalCost
+			n.SelfTime -= c.ActualTotalTime * c.Loops
+		}
+		n.SelfCost = max(n.SelfCost, 0)
+		n.SelfTime = max(n.SelfTime, 0)
+
+		// Expensive nodes
+		if p.Analyzed && totalTime > 0 {
+			if share := n.SelfTime / totalTime; share >= ExpensiveShare {
+				n.addFinding(FindingExpensive, fmt.Sprintf("%.0f%% of execution time", share*100))
+			}
+		} else if !p.Analyzed && root.TotalCost > 0 {
+			if share := n.SelfCost / root.TotalCost; share >= ExpensiveShare {
+				n.addFinding(FindingExpensive, fmt.Sprintf("%.0f%% of total cost", share*100))
+			}
+		}
+
+		// Row estimate misses
+		if n.HasActual && n.Loops > 0 {
+			estimated, actual := n.PlanRows, n.ActualRows
+			high, low := max(estimated, actual), max(min(estimated, actual), 1)
+			if high >= EstimateMissMinRows && high/low >= EstimateMissFactor {
+				direction := "under"
+				if estimated > actual {
+					direction = "over"
+				}
+				n.addFinding(FindingEstimateMiss, fmt.Sprintf("rows %sestimated %.0fx (estimated %s, actual %s)",
+					direction, high/low, formatCount(estimated), formatCount(actual)))
+			}
+		}
+
+		// Sequential scans on large tables
+		if n.Type == "Seq Scan" {
+			scanned := n.TableRows
+			if scanned < 0 {
+				scanned = n.PlanRows
+				if n.HasActual {
+					scanned = (n.ActualRows + number(n.Props["Rows Removed by Filter"])) * max(n.Loops, 1)
+				}
+			}
+			if scanned >= LargeTableRows {
+				n.addFinding(FindingSeqScan, fmt.Sprintf("sequential scan over ~%s rows", formatCount(scanned)))
+			}
+		}
+
+		// Buffer usage (reported cumulatively, so subtract children)
+		read := n.selfBlocks("Shared Read Blocks")
+		tempRead := n.selfBlocks("Temp Read Blocks")
+		tempWritten := n.selfBlocks("Temp Written Blocks")
+		if tempRead > 0 || tempWritten > 0 {
+			n.addFinding(FindingBuffers, fmt.Sprintf("spilled to disk (temp read=%d written=%d)", tempRead, tempWritten))
+		}
+		if read >= HeavyReadBlocks {
+			n.addFinding(FindingBuffers, fmt.Sprintf("read %d blocks (%s) from disk", read, formatBytes(read*8192)))
+		}
+	}
+}
+
+func (n *Node) addFinding(kind FindingKind, message string) {
+	n.Findings = append(n.Findings, Finding{Kind: kind, Message: message})
+}
+
+// HasFinding returns true if the node has a finding of the given kind
+func (n *Node) HasFinding(kind FindingKind) bool {
+	for _, f := range n.Findings {
+		if f.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// selfBlocks returns a buffer counter excluding child nodes
+func (n *Node) selfBlocks(key string) int64 {
+	blocks := int64(number(n.Props[key]))
+	for _, c := range n.Children {
+		blocks -= int64(number(c.Props[key]))
+	}
+	return max(blocks, 0)
+}
+
+// ParentRelationship returns how the node relates to its parent (Outer, Inner, InitPlan, SubPlan, ...)
+func (n *Node) ParentRelationship() string {
+	return str(n.Props["Parent Relationship"])
+}
+
+// RelationName returns the scanned relation, schema qualified when known
+func (n *Node) RelationName() string {
+	relation := str(n.Props["Relation Name"])
+	if relation == "" {
+		return ""
+	}
+	if schema := str(n.Props["Schema"]); schema != "" {
+		return schema + "." + relation
+	}
+	return relation
+}
+
+// Title returns a label like the one used by the text EXPLAIN format
+func (n *Node) Title() string {
+	title := n.Type
+
+	switch n.Type {
+	case "Aggregate":
+		switch str(n.Props["Strategy"]) {
+		case "Sorted":
+			title = "GroupAggregate"
+		case "Hashed":
+			title = "HashAggregate"
+		case "Mixed":
+			title = "MixedAggregate"
+		}
+	case "ModifyTable":
+		if op := str(n.Props["Operation"]); op != "" {
+			title = op
+		}
+	case "Nested Loop", "Hash Join", "Merge Join":
+		if joinType := str(n.Props["Join Type"]); joinType != "" && joinType != "Inner" {
+			if n.Type == "Nested Loop" {
+				title = fmt.Sprintf("Nested Loop %s Join", joinType)
+			} else {
+				title = strings.Replace(n.Type, " Join", " "+joinType+" Join", 1)
+			}
+		}
+	}
+
+	if b, ok := n.Props["Parallel Aware"].(bool); ok && b {
+		title = "Parallel " + title
+	}
+	if str(n.Props["Scan Direction"]) == "Backward" {
+		title += " Backward"
+	}
+	if index := str(n.Props["Index Name"]); index != "" {
+		title += " using " + index
+	}
+
+	target := n.RelationName()
+	if target == "" {
+		target = str(n.Props["CTE Name"])
+	}
+	if target == "" {
+		target = str(n.Props["Function Name"])
+	}
+	if target != "" {
+		title += " on " + target
+		if alias := str(n.Props["Alias"]); alias != "" && alias != str(n.Props["Relation Name"]) && alias != target {
+			title += " " + alias
+		}
+	}
+
+	if name := str(n.Props["Subplan Name"]); name != "" {
+		title = name + " → " + title
+	}
+	return title
+}
+
+// detailOrder lists properties shown first in node details
+var detailOrder = []string{
+	"Filter", "Index Cond", "Recheck Cond", "Hash Cond", "Merge Cond", "Join Filter",
+	"Rows Removed by Filter", "Rows Removed by Join Filter", "Rows Removed by Index Recheck",
+	"Sort Key", "Sort Method", "Sort Space Used", "Sort Space Type", "Group Key",
+	"Heap Fetches", "Workers Planned", "Workers Launched",
+	"Shared Hit Blocks", "Shared Read Blocks", "Shared Dirtied Blocks", "Shared Written Blocks",
+	"Temp Read Blocks", "Temp Written Blocks",
+}
+
+// summaryProps are shown in the node line and left out of the details
+var summaryProps = map[string]bool{
+	"Node Type": true, "Startup Cost": true, "Total Cost": true, "Plan Rows": true, "Plan Width": true,
+	"Actual Startup Time": true, "Actual Total Time": true, "Actual Rows": true, "Actual Loops": true,
+	"Relation Name": true, "Alias": true, "Index Name": true, "Parallel Aware": true, "Async Capable": true,
+	"Parent Relationship": true,
+}
+
+// Detail is a formatted plan property
+type Detail struct {
+	Key   string
+	Value string
+}
+
+// Details returns the node properties for display, most relevant first
+func (n *Node) Details() []Detail {
+	var details []Detail
+	used := make(map[string]bool)
+	add := func(key string) {
+		value, ok := n.Props[key]
+		if !ok || used[key] || summaryProps[key] {
+			return
+		}
+		used[key] = true
+		details = append(details, Detail{Key: key, Value: formatValue(value)})
+	}
+
+	for _, key := range detailOrder {
+		add(key)
+	}
+	rest := make([]string, 0, len(n.Props))
+	for key := range n.Props {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		add(key)
+	}
+	return details
+}
+
+// formatValue formats a JSON property value
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%.3f", v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatValue(item)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
+
+// formatCount formats a row count compactly (1234567 -> 1.2M)
+func formatCount(n float64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fG", n/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", n/1e6)
+	case n >= 1e4:
+		return fmt.Sprintf("%.1fk", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
+
+// formatBytes formats a size in bytes
+func formatBytes(b int64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1f kB", float64(b)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", b)
+	}
+}
+
+// number reads a JSON number, returning 0 for missing values
+func number(v interface{}) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	return 0
+}
+
+// str reads a JSON string, returning "" for missing values
+func str(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package explain
+
+import (
+	"strings"
+	"testing"
+)
+
+const analyzedPlan = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Parallel Aware": false,
+      "Join Type": "Left",
+      "Startup Cost": 10.0,
+      "Total Cost": 500.0,
+      "Plan Rows": 100,
+      "Plan Width": 16,
+      "Actual Startup Time": 0.5,
+      "Actual Total Time": 100.0,
+      "Actual Rows": 5000,
+      "Actual Loops": 1,
+      "Hash Cond": "(o.user_id = u.id)",
+      "Shared Hit Blocks": 10,
+      "Shared Read Blocks": 2500,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Outer",
+          "Parallel Aware": false,
+          "Relation Name": "orders",
+          "Alias": "o",
+          "Startup Cost": 0.0,
+          "Total Cost": 400.0,
+          "Plan Rows": 20000,
+          "Plan Width": 8,
+          "Actual Startup Time": 0.01,
+          "Actual Total Time": 80.0,
+          "Actual Rows": 20000,
+          "Actual Loops": 1,
+          "Shared Hit Blocks": 0,
+          "Shared Read Blocks": 2400
+        },
+        {
+          "Node Type": "Hash",
+          "Parent Relationship": "Inner",
+          "Startup Cost": 5.0,
+          "Total Cost": 5.0,
+          "Plan Rows": 10,
+          "Plan Width": 8,
+          "Actual Startup Time": 1.0,
+          "Actual Total Time": 1.0,
+          "Actual Rows": 10,
+          "Actual Loops": 1,
+          "Shared Hit Blocks": 10,
+          "Shared Read Blocks": 100,
+          "Plans": [
+            {
+              "Node Type": "Index Scan",
+              "Parent Relationship": "Outer",
+              "Scan Direction": "Forward",
+              "Index Name": "users_pkey",
+              "Relation Name": "users",
+              "Alias": "u",
+              "Startup Cost": 0.0,
+              "Total Cost": 5.0,
+              "Plan Rows": 10,
+              "Plan Width": 8,
+              "Actual Startup Time": 0.1,
+              "Actual Total Time": 0.9,
+              "Actual Rows": 10,
+              "Actual Loops": 1,
+              "Shared Hit Blocks": 10,
+              "Shared Read Blocks": 100
+            }
+          ]
+        }
+      ]
+    },
+    "Planning Time": 0.25,
+    "Triggers": [],
+    "Execution Time": 101.0
+  }
+]`
+
+func TestParseAnalyzed(t *testing.T) {
+	plan, err := Parse([]byte(analyzedPlan))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !plan.Analyzed || plan.ExecutionTime != 101.0 || plan.PlanningTime != 0.25 {
+		t.Errorf("unexpected plan summary: %+v", plan)
+	}
+
+	nodes := plan.Nodes()
+	if len(nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %d", len(nodes))
+	}
+	join, scan, index := nodes[0], nodes[1], nodes[3]
+
+	if join.Title() != "Hash Left Join" {
+		t.Errorf("unexpected join title: %s", join.Title())
+	}
+	if scan.Title() != "Seq Scan on orders o" {
+		t.Errorf("unexpected scan title: %s", scan.Title())
+	}
+	if index.Title() != "Index Scan using users_pkey on users u" {
+		t.Errorf("unexpected index scan title: %s", index.Title())
+	}
+	if index.Level != 2 || index.Parent != nodes[2] {
+		t.Errorf("unexpected tree structure for index scan")
+	}
+
+	// Join self time: 100 - 80 - 1 = 19ms of 101ms
+	if join.SelfTime < 18.9 || join.SelfTime > 19.1 {
+		t.Errorf("expected join self time 19ms, got %.3f", join.SelfTime)
+	}
+
+	if !scan.HasFinding(FindingExpensive) {
+		t.Error("expected seq scan to be flagged as expensive")
+	}
+	if !scan.HasFinding(FindingSeqScan) {
+		t.Error("expected seq scan over 20k rows to be flagged")
+	}
+	if !scan.HasFinding(FindingBuffers) {
+		t.Error("expected seq scan reading 2400 blocks to be flagged")
+	}
+	if !join.HasFinding(FindingEstimateMiss) {
+		t.Error("expected join estimate miss (100 vs 5000) to be flagged")
+	}
+	if join.HasFinding(FindingBuffers) {
+		t.Error("join reads no blocks itself and should not be flagged for buffers")
+	}
+	if len(index.Findings) != 0 {
+		t.Errorf("expected no findings for index scan, got %+v", index.Findings)
+	}
+}
+
+func TestParseEstimated(t *testing.T) {
+	input := `[{"Plan": {"Node Type": "Aggregate", "Strategy": "Hashed", "Startup Cost": 50, "Total Cost": 60, "Plan Rows": 5, "Plan Width": 4,
+		"Plans": [{"Node Type": "Seq Scan", "Relation Name": "events", "Alias": "events", "Startup Cost": 0, "Total Cost": 50, "Plan Rows": 100, "Plan Width": 4, "Filter": "(kind = 1)"}]}}]`
+	plan, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if plan.Analyzed {
+		t.Error("plan without actual times should not be analyzed")
+	}
+
+	root, scan := plan.Root, plan.Root.Children[0]
+	if root.Title() != "HashAggregate" {
+		t.Errorf("unexpected title: %s", root.Title())
+	}
+	if !scan.HasFinding(FindingExpensive) || root.HasFinding(FindingExpensive) {
+		t.Errorf("expected only the scan (50 of 60 cost) to be expensive")
+	}
+	if scan.HasFinding(FindingSeqScan) {
+		t.Error("small seq scan should not be flagged")
+	}
+
+	// The table is large even though the filter returns few rows
+	if relations := plan.SeqScanRelations(); len(relations) != 1 || relations[0].Key() != "events" {
+		t.Fatalf("unexpected seq scan relations: %v", relations)
+	}
+	plan.SetTableRows(map[string]float64{"events": 2e6})
+	if !scan.HasFinding(FindingSeqScan) {
+		t.Error("expected seq scan on 2M row table to be flagged")
+	}
+
+	details := scan.Details()
+	if len(details) == 0 || details[0].Key != "Filter" || details[0].Value != "(kind = 1)" {
+		t.Errorf("expected Filter first in details, got %+v", details)
+	}
+	for _, d := range details {
+		if d.Key == "Node Type" || d.Key == "Total Cost" {
+			t.Errorf("summary property %s should not be in details", d.Key)
+		}
+	}
+}
+
+func TestSeqScanRelationsSchema(t *testing.T) {
+	input := `[{"Plan": {"Node Type": "Append", "Total Cost": 20, "Plan Rows": 10,
+		"Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "sales", "Total Cost": 10, "Plan Rows": 5},
+			{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "public", "Total Cost": 10, "Plan Rows": 5}]}}]`
+	plan, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tables of the same name in two schemas stay apart
+	relations := plan.SeqScanRelations()
+	if len(relations) != 2 || relations[0] != (Relation{Schema: "sales", Name: "orders"}) || relations[1].Key() != "public.orders" {
+		t.Fatalf("unexpected seq scan relations: %v", relations)
+	}
+	plan.SetTableRows(map[string]float64{"sales.orders": 2e6})
+	if scans := plan.Root.Children; !scans[0].HasFinding(FindingSeqScan) || scans[1].HasFinding(FindingSeqScan) {
+		t.Error("expected only the scan of sales.orders to be flagged")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"", "[]", `[{"Foo": 1}]`, "not json"} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[float64]string{12: "12", 12345: "12.3k", 2500000: "2.5M"}
+	for n, expected := range tests {
+		if got := formatCount(n); got != expected {
+			t.Errorf("formatCount(%v) = %s, expected %s", n, got, expected)
+		}
+	}
+	if !strings.HasSuffix(formatBytes(2400*8192), "MB") {
+		t.Errorf("unexpected formatBytes: %s", formatBytes(2400*8192))
+	}
+}
@@ -0,0 +1,338 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/values"
+)
+
+// Format identifies an export file format for result rows
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatJSON     Format = "json"
+	FormatNDJSON   Format = "ndjson"
+	FormatMarkdown Format = "markdown"
+	FormatSQL      Format = "sql"
+)
+
+// Formats lists all supported row export formats
+var Formats = []Format{FormatCSV, FormatJSON, FormatNDJSON, FormatMarkdown, FormatSQL}
+
+// Extension returns the file extension for the format (without dot)
+func (f Format) Extension() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	default:
+		return string(f)
+	}
+}
+
+// Label returns a human readable name for the format
+func (f Format) Label() string {
+	switch f {
+	case FormatCSV:
+		return "CSV"
+	case FormatJSON:
+		return "JSON"
+	case FormatNDJSON:
+		return "NDJSON"
+	case FormatMarkdown:
+		return "Markdown table"
+	case FormatSQL:
+		return "SQL INSERT statements"
+	default:
+		return string(f)
+	}
+}
+
+// Options controls format specific output
+type Options struct {
+	Schema string // Target schema for SQL INSERT statements (optional)
+	Table  string // Target table for SQL INSERT statements
+}
+
+// RowWriter writes result rows in a specific format.
+// WriteHeader must be called once before any WriteRow.
+type RowWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(cells []models.Cell) error
+	Close() error
+}
+
+// NewRowWriter creates a RowWriter for the given format
+func NewRowWriter(w io.Writer, format Format, opts Options) (RowWriter, error) {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatCSV:
+		return &csvRowWriter{buf: bw, writer: csv.NewWriter(bw)}, nil
+	case FormatJSON:
+		return &jsonRowWriter{w: bw}, nil
+	case FormatNDJSON:
+		return &jsonRowWriter{w: bw, lines: true}, nil
+	case FormatMarkdown:
+		return &markdownRowWriter{w: bw}, nil
+	case FormatSQL:
+		table := opts.Table
+		if table == "" {
+			table = "query_result"
+		}
+		target := quoteIdent(table)
+		if opts.Schema != "" {
+			target = quoteIdent(opts.Schema) + "." + target
+		}
+		return &sqlRowWriter{w: bw, target: target}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportQueryResult writes a query result to a file and returns the number of rows written
+func ExportQueryResult(result models.QueryResult, format Format, path string, opts Options) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	writer, err := NewRowWriter(file, format, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writer.WriteHeader(result.Columns); err != nil {
+		return 0, err
+	}
+	typed := len(result.Cells) == len(result.Rows)
+	for i, row := range result.Rows {
+		cells := textCells(row)
+		if typed {
+			cells = result.Cells[i]
+		}
+		if err := writer.WriteRow(cells); err != nil {
+			return i, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return len(result.Rows), err
+	}
+	return len(result.Rows), file.Close()
+}
+
+// textCells wraps display text as untyped cells for results that were not
+// read with their types, treating the text NULL as SQL NULL
+func textCells(row []string) []models.Cell {
+	cells := make([]models.Cell, len(row))
+	for i, text := range row {
+		if text != values.NullText {
+			cells[i].Value = text
+		}
+	}
+	return cells
+}
+
+// csvRowWriter writes CSV with a header row. NULL is written as an empty field.
+type csvRowWriter struct {
+	buf    *bufio.Writer
+	writer *csv.Writer
+}
+
+func (c *csvRowWriter) WriteHeader(columns []string) error {
+	return c.writer.Write(columns)
+}
+
+func (c *csvRowWriter) WriteRow(cells []models.Cell) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		if !cell.IsNull() {
+			record[i] = values.Format(cell)
+		}
+	}
+	return c.writer.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return c.buf.Flush()
+}
+
+// jsonRowWriter writes a JSON array of objects, or one object per line for NDJSON.
+// Objects keep the column order of the result. Numbers, booleans, arrays and
+// json columns keep their JSON types.
+type jsonRowWriter struct {
+	w       *bufio.Writer
+	lines   bool
+	columns []string
+	count   int
+}
+
+func (j *jsonRowWriter) WriteHeader(columns []string) error {
+	j.columns = columns
+	if !j.lines {
+		_, err := j.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonRowWriter) WriteRow(cells []models.Cell) error {
+	var b strings.Builder
+	if !j.lines {
+		if j.count > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+	}
+
+	b.WriteString("{")
+	for i, col := range j.columns {
+		if i > 0 {
+			b.WriteString(",")
+			if !j.lines {
+				b.WriteString(" ")
+			}
+		}
+		key, _ := json.Marshal(col)
+		b.Write(key)
+		b.WriteString(":")
+		if !j.lines {
+			b.WriteString(" ")
+		}
+		if i >= len(cells) {
+			b.WriteString("null")
+		} else {
+			value, err := json.Marshal(values.JSONValue(cells[i]))
+			if err != nil {
+				value, _ = json.Marshal(values.Format(cells[i]))
+			}
+			b.Write(value)
+		}
+	}
+	b.WriteString("}")
+	if j.lines {
+		b.WriteString("\n")
+	}
+
+	j.count++
+	_, err := j.w.WriteString(b.String())
+	return err
+}
+
+func (j *jsonRowWriter) Close() error {
+	if !j.lines {
+		closing := "]\n"
+		if j.count > 0 {
+			closing = "\n]\n"
+		}
+		if _, err := j.w.WriteString(closing); err != nil {
+			return err
+		}
+	}
+	return j.w.Flush()
+}
+
+// markdownRowWriter writes a GitHub flavored Markdown table
+type markdownRowWriter struct {
+	w *bufio.Writer
+}
+
+func (m *markdownRowWriter) WriteHeader(columns []string) error {
+	if err := m.writeLine(columns); err != nil {
+		return err
+	}
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	_, err := m.w.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+	return err
+}
+
+func (m *markdownRowWriter) WriteRow(cells []models.Cell) error {
+	return m.writeLine(values.FormatRow(cells))
+}
+
+func (m *markdownRowWriter) writeLine(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		cell = strings.ReplaceAll(cell, "\r\n", "<br>")
+		cell = strings.ReplaceAll(cell, "\n", "<br>")
+		escaped[i] = cell
+	}
+	_, err := m.w.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	return err
+}
+
+func (m *markdownRowWriter) Close() error {
+	return m.w.Flush()
+}
+
+// sqlRowWriter writes one INSERT statement per row
+type sqlRowWriter struct {
+	w       *bufio.Writer
+	target  string
+	columns string
+}
+
+func (s *sqlRowWriter) WriteHeader(columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	s.columns = strings.Join(quoted, ", ")
+	return nil
+}
+
+func (s *sqlRowWriter) WriteRow(cells []models.Cell) error {
+	literals := make([]string, len(cells))
+	for i, cell := range cells {
+		literals[i] = sqlLiteral(cell)
+	}
+	_, err := fmt.Fprintf(s.w, "INSERT INTO %s (%s) VALUES (%s);\n", s.target, s.columns, strings.Join(literals, ", "))
+	return err
+}
+
+func (s *sqlRowWriter) Close() error {
+	return s.w.Flush()
+}
+
+// quoteIdent quotes a PostgreSQL identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlLiteral returns a cell as a SQL literal. Numbers and booleans are left
+// unquoted; other values are string literals the column type converts.
+func sqlLiteral(cell models.Cell) string {
+	if cell.IsNull() {
+		return "NULL"
+	}
+	text := values.Format(cell)
+	switch {
+	case cell.OID == pgtype.BoolOID:
+		return strings.ToUpper(text)
+	case values.IsNumeric(cell.OID) && json.Valid([]byte(text)):
+		// Excludes NaN, Infinity and formatted money amounts
+		return text
+	}
+	return quoteLiteral(text)
+}
+
+// quoteLiteral quotes a value as a SQL string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
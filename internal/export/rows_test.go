@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+var testColumns = []string{"id", "name", "note"}
+
+var testRows = [][]string{
+	{"1", "alice", "likes | pipes"},
+	{"2", "o'brien", "NULL"},
+}
+
+func writeRows(t *testing.T, format Format, opts Options) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, err := NewRowWriter(&buf, format, opts)
+	if err != nil {
+		t.Fatalf("NewRowWriter failed: %v", err)
+	}
+	if err := writer.WriteHeader(testColumns); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	for _, row := range testRows {
+		if err := writer.WriteRow(textCells(row)); err != nil {
+			t.Fatalf("WriteRow failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRowWriterCSV(t *testing.T) {
+	got := writeRows(t, FormatCSV, Options{})
+	expected := "id,name,note\n1,alice,likes | pipes\n2,o'brien,\n"
+	if got != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRowWriterJSON(t *testing.T) {
+	got := writeRows(t, FormatJSON, Options{})
+
+	var parsed []map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, got)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(parsed))
+	}
+	if parsed[1]["note"] != nil {
+		t.Errorf("Expected NULL to be exported as null, got %v", parsed[1]["note"])
+	}
+	if parsed[0]["name"] != "alice" {
+		t.Errorf("Expected name 'alice', got %v", parsed[0]["name"])
+	}
+}
+
+func TestRowWriterJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := NewRowWriter(&buf, FormatJSON, Options{})
+	_ = writer.WriteHeader(testColumns)
+	_ = writer.Close()
+
+	if buf.String() != "[]\n" {
+		t.Errorf("Expected empty array, got %q", buf.String())
+	}
+}
+
+func TestRowWriterNDJSON(t *testing.T) {
+	got := writeRows(t, FormatNDJSON, Options{})
+	expected := `{"id":"1","name":"alice","note":"likes | pipes"}` + "\n" +
+		`{"id":"2","name":"o'brien","note":null}` + "\n"
+	if got != expected {
+		t.Errorf("Expected NDJSON:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRowWriterMarkdown(t *testing.T) {
+	got := writeRows(t, FormatMarkdown, Options{})
+	expected := "| id | name | note |\n" +
+		"| --- | --- | --- |\n" +
+		"| 1 | alice | likes \\| pipes |\n" +
+		"| 2 | o'brien | NULL |\n"
+	if got != expected {
+		t.Errorf("Expected Markdown:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRowWriterSQL(t *testing.T) {
+	got := writeRows(t, FormatSQL, Options{Schema: "public", Table: "users"})
+	expected := `INSERT INTO "public"."users" ("id", "name", "note") VALUES ('1', 'alice', 'likes | pipes');` + "\n" +
+		`INSERT INTO "public"."users" ("id", "name", "note") VALUES ('2', 'o''brien', NULL);` + "\n"
+	if got != expected {
+		t.Errorf("Expected SQL:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestExportQueryResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.ndjson")
+	result := models.QueryResult{Columns: testColumns, Rows: testRows}
+
+	count, err := ExportQueryResult(result, FormatNDJSON, path, Options{})
+	if err != nil {
+		t.Fatalf("ExportQueryResult failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 rows written, got %d", count)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(`{"id":"1"`)) {
+		t.Errorf("Unexpected file content: %s", data)
+	}
+}
+
+func TestExportTypedCells(t *testing.T) {
+	result := models.QueryResult{
+		Columns: []string{"id", "active", "note"},
+		Rows:    [][]string{{"1", "true", "NULL"}},
+		Cells: [][]models.Cell{{
+			{OID: pgtype.Int4OID, Value: int32(1)},
+			{OID: pgtype.BoolOID, Value: true},
+			{OID: pgtype.TextOID, Value: "NULL"},
+		}},
+	}
+
+	path := filepath.Join(t.TempDir(), "result.ndjson")
+	if _, err := ExportQueryResult(result, FormatNDJSON, path, Options{}); err != nil {
+		t.Fatalf("ExportQueryResult failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if expected := `{"id":1,"active":true,"note":"NULL"}` + "\n"; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	path = filepath.Join(t.TempDir(), "result.sql")
+	if _, err := ExportQueryResult(result, FormatSQL, path, Options{Table: "t"}); err != nil {
+		t.Fatalf("ExportQueryResult failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if expected := `INSERT INTO "t" ("id", "active", "note") VALUES (1, TRUE, 'NULL');` + "\n"; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+}
@@ -230,6 +230,20 @@ func (m *Manager) RecordUsage(id string) error {
 	return fmt.Errorf("favorite with ID '%s' was not found", id)
 }
 
+// SetParameters remembers the values last bound to a favorite's variables
+func (m *Manager) SetParameters(id string, values models.BindValues) error {
+	for i, fav := range m.favorites {
+		if fav.ID == id {
+			m.favorites[i].Parameters = values
+			if err := m.Save(); err != nil {
+				return fmt.Errorf("failed to save favorite parameters: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("favorite with ID '%s' was not found", id)
+}
+
 // GetMostUsed returns the most frequently used favorites
 func (m *Manager) GetMostUsed(limit int) []models.Favorite {
 	sorted := make([]models.Favorite, len(m.favorites))
@@ -77,12 +77,44 @@ func (b *Builder) buildCondition(cond models.FilterCondition, paramIndex int) (s
 		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
 	case models.OpEqual, models.OpNotEqual, models.OpGreaterThan, models.OpGreaterOrEqual,
 		models.OpLessThan, models.OpLessOrEqual:
-		return fmt.Sprintf("%s %s $%d", column, cond.Operator, paramIndex), []interface{}{cond.Value}, nil
-	case models.OpLike, models.OpILike:
+		value, err := b.scalarValue(cond)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s $%d", column, cond.Operator, paramIndex), []interface{}{value}, nil
+	case models.OpLike, models.OpILike, models.OpNotLike, models.OpNotILike:
 		return fmt.Sprintf("%s %s $%d", column, cond.Operator, paramIndex), []interface{}{cond.Value}, nil
 	case models.OpIn, models.OpNotIn:
-		// IN/NOT IN requires array expansion - not yet implemented
-		return "", nil, fmt.Errorf("IN/NOT IN operators not yet implemented")
+		values, err := listValues(cond)
+		if err != nil {
+			return "", nil, err
+		}
+		param, array, err := arrayParam(cond.Type, values, paramIndex)
+		if err != nil {
+			return "", nil, err
+		}
+		// = ANY / <> ALL take a single array parameter however many values there are
+		if cond.Operator == models.OpIn {
+			return fmt.Sprintf("%s = ANY(%s)", column, param), []interface{}{array}, nil
+		}
+		return fmt.Sprintf("%s <> ALL(%s)", column, param), []interface{}{array}, nil
+	case models.OpBetween:
+		values, err := listValues(cond)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(values) != 2 {
+			return "", nil, fmt.Errorf("BETWEEN needs exactly two values, got %d", len(values))
+		}
+		low, err := castValue(cond.Type, values[0])
+		if err != nil {
+			return "", nil, err
+		}
+		high, err := castValue(cond.Type, values[1])
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s BETWEEN $%d AND $%d", column, paramIndex, paramIndex+1), []interface{}{low, high}, nil
 	case models.OpContains:
 		// JSONB @> operator
 		// If value is a string, treat it as JSON literal
@@ -100,6 +132,38 @@ func (b *Builder) buildCondition(cond models.FilterCondition, paramIndex int) (s
 	}
 }
 
+// scalarValue converts a comparison value to the column's type
+func (b *Builder) scalarValue(cond models.FilterCondition) (interface{}, error) {
+	value, ok := cond.Value.(string)
+	if !ok {
+		return cond.Value, nil
+	}
+	return castValue(cond.Type, value)
+}
+
+// listValues returns the values of a list condition, parsing them from a
+// string when needed
+func listValues(cond models.FilterCondition) ([]string, error) {
+	switch v := cond.Value.(type) {
+	case []string:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("%s needs at least one value", cond.Operator)
+		}
+		return v, nil
+	case string:
+		values, err := ParseList(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%s needs at least one value", cond.Operator)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s needs a list of values", cond.Operator)
+	}
+}
+
 // Validate checks if a filter is valid
 func (b *Builder) Validate(filter models.Filter) error {
 	if filter.TableName == "" {
@@ -138,6 +202,16 @@ func (b *Builder) validateCondition(cond models.FilterCondition) error {
 		return fmt.Errorf("value is required for operator %s", cond.Operator)
 	}
 
+	if cond.Operator.TakesList() {
+		values, err := listValues(cond)
+		if err != nil {
+			return err
+		}
+		if cond.Operator == models.OpBetween && len(values) != 2 {
+			return fmt.Errorf("BETWEEN needs exactly two values, got %d", len(values))
+		}
+	}
+
 	return nil
 }
 
@@ -153,12 +227,14 @@ func GetOperatorsForType(dataType string) []models.FilterOperator {
 			models.OpEqual, models.OpNotEqual,
 			models.OpGreaterThan, models.OpGreaterOrEqual,
 			models.OpLessThan, models.OpLessOrEqual,
+			models.OpIn, models.OpNotIn, models.OpBetween,
 			models.OpIsNull, models.OpIsNotNull,
 		}
 	case strings.Contains(dataType, "char") || strings.Contains(dataType, "text"):
 		return []models.FilterOperator{
 			models.OpEqual, models.OpNotEqual,
-			models.OpLike, models.OpILike,
+			models.OpLike, models.OpILike, models.OpNotLike, models.OpNotILike,
+			models.OpIn, models.OpNotIn,
 			models.OpIsNull, models.OpIsNotNull,
 		}
 	case strings.Contains(dataType, "jsonb"):
@@ -183,11 +259,13 @@ func GetOperatorsForType(dataType string) []models.FilterOperator {
 			models.OpEqual, models.OpNotEqual,
 			models.OpGreaterThan, models.OpGreaterOrEqual,
 			models.OpLessThan, models.OpLessOrEqual,
+			models.OpIn, models.OpNotIn, models.OpBetween,
 			models.OpIsNull, models.OpIsNotNull,
 		}
 	default:
 		return []models.FilterOperator{
 			models.OpEqual, models.OpNotEqual,
+			models.OpIn, models.OpNotIn,
 			models.OpIsNull, models.OpIsNotNull,
 		}
 	}
@@ -0,0 +1,139 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a, b,c", []string{"a", "b", "c"}},
+		{"1\n2\r\n3\n", []string{"1", "2", "3"}},
+		{`'a,b', "c d" , e`, []string{"a,b", "c d", "e"}},
+		{`'it''s', ''`, []string{"it's", ""}},
+		{" ' padded ' ", []string{" padded "}},
+		{"x,,y", []string{"x", "y"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseList(tt.input)
+		if err != nil {
+			t.Errorf("ParseList(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseList(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"'open", `"a"b`} {
+		if _, err := ParseList(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func build(t *testing.T, cond models.FilterCondition) (string, []interface{}) {
+	t.Helper()
+	where, args, err := NewBuilder().BuildWhere(models.Filter{
+		TableName: "t",
+		RootGroup: models.FilterGroup{Conditions: []models.FilterCondition{cond}},
+	})
+	if err != nil {
+		t.Fatalf("BuildWhere failed: %v", err)
+	}
+	return where, args
+}
+
+func TestBuildInOperators(t *testing.T) {
+	where, args := build(t, models.FilterCondition{Column: "id", Operator: models.OpIn, Value: []string{"1", "2"}, Type: "integer"})
+	if where != `WHERE "id" = ANY($1::integer[])` {
+		t.Errorf("unexpected clause: %s", where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{[]int64{1, 2}}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+
+	where, args = build(t, models.FilterCondition{Column: "name", Operator: models.OpNotIn, Value: "a, 'b,c'", Type: "character varying"})
+	if where != `WHERE "name" <> ALL($1::text[])` {
+		t.Errorf("unexpected clause: %s", where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{[]string{"a", "b,c"}}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+
+	where, _ = build(t, models.FilterCondition{Column: "mood", Operator: models.OpIn, Value: []string{"happy"}, Type: "public.mood"})
+	if where != `WHERE "mood" = ANY($1::text[]::public.mood[])` {
+		t.Errorf("unexpected enum clause: %s", where)
+	}
+
+	where, args = build(t, models.FilterCondition{Column: "active", Operator: models.OpIn, Value: []string{"t", "no"}, Type: "boolean"})
+	if where != `WHERE "active" = ANY($1::boolean[])` || !reflect.DeepEqual(args, []interface{}{[]bool{true, false}}) {
+		t.Errorf("unexpected boolean clause: %s %#v", where, args)
+	}
+}
+
+func TestBuildBetweenAndNotLike(t *testing.T) {
+	where, args := build(t, models.FilterCondition{Column: "price", Operator: models.OpBetween, Value: []string{"1.5", "10"}, Type: "double precision"})
+	if where != `WHERE "price" BETWEEN $1 AND $2` || !reflect.DeepEqual(args, []interface{}{1.5, 10.0}) {
+		t.Errorf("unexpected BETWEEN: %s %#v", where, args)
+	}
+
+	where, args = build(t, models.FilterCondition{Column: "email", Operator: models.OpNotILike, Value: "%@test.com", Type: "text"})
+	if where != `WHERE "email" NOT ILIKE $1` || args[0] != "%@test.com" {
+		t.Errorf("unexpected NOT ILIKE: %s %#v", where, args)
+	}
+
+	// Parameters keep counting after a two-value BETWEEN
+	where, args, err := NewBuilder().BuildWhere(models.Filter{RootGroup: models.FilterGroup{
+		Logic: "AND",
+		Conditions: []models.FilterCondition{
+			{Column: "created", Operator: models.OpBetween, Value: []string{"2024-01-01", "2024-02-01"}, Type: "date"},
+			{Column: "id", Operator: models.OpEqual, Value: "7", Type: "bigint"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("BuildWhere failed: %v", err)
+	}
+	if where != `WHERE "created" BETWEEN $1 AND $2 AND "id" = $3` {
+		t.Errorf("unexpected clause: %s", where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"2024-01-01", "2024-02-01", int64(7)}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildValueErrors(t *testing.T) {
+	conditions := []models.FilterCondition{
+		{Column: "id", Operator: models.OpEqual, Value: "abc", Type: "integer"},
+		{Column: "id", Operator: models.OpIn, Value: []string{"1", "x"}, Type: "integer"},
+		{Column: "id", Operator: models.OpIn, Value: []string{}, Type: "integer"},
+		{Column: "id", Operator: models.OpBetween, Value: []string{"1"}, Type: "integer"},
+		{Column: "ok", Operator: models.OpEqual, Value: "maybe", Type: "boolean"},
+	}
+	for _, cond := range conditions {
+		_, _, err := NewBuilder().BuildWhere(models.Filter{RootGroup: models.FilterGroup{Conditions: []models.FilterCondition{cond}}})
+		if err == nil {
+			t.Errorf("expected error for %+v", cond)
+		}
+	}
+}
+
+func TestCastType(t *testing.T) {
+	tests := map[string]string{
+		"character varying(255)": "character varying",
+		"serial":                 "integer",
+		`public."Mood"`:          `public."Mood"`,
+		"int; DROP TABLE x":      "",
+	}
+	for input, expected := range tests {
+		if got := castType(input); got != expected {
+			t.Errorf("castType(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
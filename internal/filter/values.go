@@ -0,0 +1,215 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseList splits multi-value input into values. Values are separated by
+// commas or newlines; single or double quotes keep separators and surrounding
+// spaces inside a value, and a doubled quote inside quotes is a literal quote.
+func ParseList(input string) ([]string, error) {
+	var values []string
+	var current strings.Builder
+	quoted := false  // Current value was quoted
+	var quote rune   // Open quote character, 0 when outside quotes
+	pending := false // Current value has content (possibly empty quotes)
+
+	flush := func() {
+		value := current.String()
+		if !quoted {
+			value = strings.TrimSpace(value)
+		}
+		if pending && (quoted || value != "") {
+			values = append(values, value)
+		}
+		current.Reset()
+		quoted = false
+		pending = false
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				if i+1 < len(runes) && runes[i+1] == quote {
+					current.WriteRune(r)
+					i++
+					continue
+				}
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == ',' || r == '\n' || r == '\r':
+			flush()
+		case (r == '\'' || r == '"') && strings.TrimSpace(current.String()) == "" && !quoted:
+			// Opening quote: drop leading whitespace before it
+			current.Reset()
+			quote = r
+			quoted = true
+			pending = true
+		case quoted:
+			// Only whitespace may follow a closing quote
+			if r != ' ' && r != '\t' {
+				return nil, fmt.Errorf("unexpected %q after quoted value", r)
+			}
+		default:
+			current.WriteRune(r)
+			pending = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quoted value")
+	}
+	flush()
+
+	return values, nil
+}
+
+// typeCategory is how filter values for a column type are converted
+type typeCategory int
+
+const (
+	categoryText typeCategory = iota
+	categoryInteger
+	categoryFloat
+	categoryBoolean
+	categoryOther // Passed as text and cast by the server
+)
+
+// categorize returns the conversion category of a PostgreSQL type name
+func categorize(dataType string) typeCategory {
+	switch baseType(dataType) {
+	case "smallint", "integer", "bigint", "int2", "int4", "int8", "int",
+		"smallserial", "serial", "bigserial":
+		return categoryInteger
+	case "real", "double precision", "float4", "float8":
+		return categoryFloat
+	case "boolean", "bool":
+		return categoryBoolean
+	case "text", "character varying", "varchar", "character", "char", "bpchar", "name", "":
+		return categoryText
+	default:
+		return categoryOther
+	}
+}
+
+// baseType lowercases a type name and strips modifiers such as (255)
+func baseType(dataType string) string {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	if idx := strings.Index(dataType, "("); idx >= 0 {
+		dataType = strings.TrimSpace(dataType[:idx])
+	}
+	return dataType
+}
+
+// serialTypes maps serial pseudo-types to their underlying types
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+}
+
+// castType returns the type to cast parameters to, or "" when the type name
+// is empty or not safe to embed in SQL
+func castType(dataType string) string {
+	dataType = strings.TrimSpace(dataType)
+	if idx := strings.Index(dataType, "("); idx >= 0 {
+		dataType = strings.TrimSpace(dataType[:idx])
+	}
+	if underlying, ok := serialTypes[strings.ToLower(dataType)]; ok {
+		return underlying
+	}
+	for _, r := range dataType {
+		if !(r == '_' || r == ' ' || r == '.' || r == '"' ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return dataType
+}
+
+// boolValues are the boolean literals PostgreSQL accepts
+var boolValues = map[string]bool{
+	"t": true, "true": true, "y": true, "yes": true, "on": true, "1": true,
+	"f": false, "false": false, "n": false, "no": false, "off": false, "0": false,
+}
+
+// castValue converts a filter value to a Go value matching the column type.
+// Types without a natural Go representation stay strings.
+func castValue(dataType, value string) (interface{}, error) {
+	switch categorize(dataType) {
+	case categoryInteger:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", value, baseType(dataType))
+		}
+		return n, nil
+	case categoryFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", value, baseType(dataType))
+		}
+		return f, nil
+	case categoryBoolean:
+		b, ok := boolValues[strings.ToLower(strings.TrimSpace(value))]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid boolean", value)
+		}
+		return b, nil
+	default:
+		return value, nil
+	}
+}
+
+// arrayParam converts values to a typed slice and returns the parameter
+// placeholder with the casts needed to compare it with the column
+func arrayParam(dataType string, values []string, paramIndex int) (string, interface{}, error) {
+	placeholder := fmt.Sprintf("$%d", paramIndex)
+	target := castType(dataType)
+
+	switch categorize(dataType) {
+	case categoryInteger:
+		ints := make([]int64, len(values))
+		for i, value := range values {
+			n, err := castValue(dataType, value)
+			if err != nil {
+				return "", nil, err
+			}
+			ints[i] = n.(int64)
+		}
+		return placeholder + "::" + target + "[]", ints, nil
+	case categoryFloat:
+		floats := make([]float64, len(values))
+		for i, value := range values {
+			f, err := castValue(dataType, value)
+			if err != nil {
+				return "", nil, err
+			}
+			floats[i] = f.(float64)
+		}
+		return placeholder + "::" + target + "[]", floats, nil
+	case categoryBoolean:
+		bools := make([]bool, len(values))
+		for i, value := range values {
+			b, err := castValue(dataType, value)
+			if err != nil {
+				return "", nil, err
+			}
+			bools[i] = b.(bool)
+		}
+		return placeholder + "::boolean[]", bools, nil
+	case categoryText:
+		return placeholder + "::text[]", values, nil
+	default:
+		// Send text and let the server convert to the column type
+		if target == "" {
+			return placeholder, values, nil
+		}
+		return placeholder + "::text[]::" + target + "[]", values, nil
+	}
+}
@@ -8,7 +8,8 @@ CREATE TABLE IF NOT EXISTS query_history (
     duration_ms INTEGER,
     rows_affected INTEGER,
     success BOOLEAN NOT NULL,
-    error_message TEXT
+    error_message TEXT,
+    parameters TEXT
 );
 
 CREATE INDEX IF NOT EXISTS idx_executed_at ON query_history(executed_at DESC);
@@ -3,6 +3,8 @@ package history
 import (
 	"database/sql"
 	_ "embed"
+	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -22,6 +24,7 @@ type HistoryEntry struct {
 	RowsAffected   int64
 	Success        bool
 	ErrorMessage   string
+	Parameters     string // Values bound to the query's variables, e.g. ":id = '1'"
 }
 
 // Store manages query history persistence
@@ -42,35 +45,146 @@ func NewStore(path string) (*Store, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	if err := migrate(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
 	return &Store{db: db}, nil
 }
 
+// migrate adds the columns that databases created by older versions lack
+func migrate(db *sql.DB) error {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('query_history') WHERE name = 'parameters'").Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = db.Exec("ALTER TABLE query_history ADD COLUMN parameters TEXT")
+	return err
+}
+
+// timeLayout is the format SQLite uses for CURRENT_TIMESTAMP (UTC)
+const timeLayout = "2006-01-02 15:04:05"
+
+// parseTime reads an executed_at value. go-sqlite3 returns TIMESTAMP
+// columns as RFC3339 text, while older rows may hold timeLayout text.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid executed_at %q: %w", value, err)
+	}
+	return t, nil
+}
+
+// entryColumns lists the columns read into a HistoryEntry
+const entryColumns = `id, connection_name, database_name, query, executed_at,
+		       duration_ms, rows_affected, success, error_message, parameters`
+
+// Filter narrows history queries. Zero values match everything.
+type Filter struct {
+	Text           string
+	ConnectionName string
+	DatabaseName   string
+	Success        *bool
+	Since          time.Time
+	Until          time.Time
+	MinDuration    time.Duration
+	MaxDuration    time.Duration
+}
+
+// where builds the WHERE clause and arguments for a filter
+func (f Filter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Text != "" {
+		conditions = append(conditions, "query LIKE ?")
+		args = append(args, "%"+f.Text+"%")
+	}
+	if f.ConnectionName != "" {
+		conditions = append(conditions, "connection_name = ?")
+		args = append(args, f.ConnectionName)
+	}
+	if f.DatabaseName != "" {
+		conditions = append(conditions, "database_name = ?")
+		args = append(args, f.DatabaseName)
+	}
+	if f.Success != nil {
+		conditions = append(conditions, "success = ?")
+		args = append(args, *f.Success)
+	}
+	if !f.Since.IsZero() {
+		conditions = append(conditions, "executed_at >= ?")
+		args = append(args, f.Since.UTC().Format(timeLayout))
+	}
+	if !f.Until.IsZero() {
+		conditions = append(conditions, "executed_at < ?")
+		args = append(args, f.Until.UTC().Format(timeLayout))
+	}
+	if f.MinDuration > 0 {
+		conditions = append(conditions, "duration_ms >= ?")
+		args = append(args, f.MinDuration.Milliseconds())
+	}
+	if f.MaxDuration > 0 {
+		conditions = append(conditions, "duration_ms <= ?")
+		args = append(args, f.MaxDuration.Milliseconds())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
 // Add adds a new query to history
 func (s *Store) Add(entry HistoryEntry) error {
+	executedAt := entry.ExecutedAt
+	if executedAt.IsZero() {
+		executedAt = time.Now()
+	}
+
 	_, err := s.db.Exec(`
 		INSERT INTO query_history
-		(connection_name, database_name, query, duration_ms, rows_affected, success, error_message)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		(connection_name, database_name, query, executed_at, duration_ms, rows_affected, success, error_message, parameters)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.ConnectionName,
 		entry.DatabaseName,
 		entry.Query,
+		executedAt.UTC().Format(timeLayout),
 		entry.Duration.Milliseconds(),
 		entry.RowsAffected,
 		entry.Success,
 		entry.ErrorMessage,
+		entry.Parameters,
 	)
 	return err
 }
 
 // GetRecent retrieves the most recent query history entries
 func (s *Store) GetRecent(limit int) ([]HistoryEntry, error) {
+	return s.Query(Filter{}, 0, limit)
+}
+
+// Search searches query history by query text
+func (s *Store) Search(query string, limit int) ([]HistoryEntry, error) {
+	return s.Query(Filter{Text: query}, 0, limit)
+}
+
+// Query retrieves a page of history entries matching the filter, newest first
+func (s *Store) Query(filter Filter, offset, limit int) ([]HistoryEntry, error) {
+	where, args := filter.where()
+	args = append(args, limit, offset)
+
 	rows, err := s.db.Query(`
-		SELECT id, connection_name, database_name, query, executed_at,
-		       duration_ms, rows_affected, success, error_message
+		SELECT `+entryColumns+`
 		FROM query_history
-		ORDER BY executed_at DESC
-		LIMIT ?`, limit)
+		`+where+`
+		ORDER BY executed_at DESC, id DESC
+		LIMIT ? OFFSET ?`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -81,73 +195,114 @@ func (s *Store) GetRecent(limit int) ([]HistoryEntry, error) {
 		var e HistoryEntry
 		var durationMs int64
 		var executedAt string
+		var connectionName, databaseName, errorMessage, parameters sql.NullString
+		var rowsAffected sql.NullInt64
 
 		err := rows.Scan(
 			&e.ID,
-			&e.ConnectionName,
-			&e.DatabaseName,
+			&connectionName,
+			&databaseName,
 			&e.Query,
 			&executedAt,
 			&durationMs,
-			&e.RowsAffected,
+			&rowsAffected,
 			&e.Success,
-			&e.ErrorMessage,
+			&errorMessage,
+			&parameters,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		e.ConnectionName = connectionName.String
+		e.DatabaseName = databaseName.String
+		e.ErrorMessage = errorMessage.String
+		e.Parameters = parameters.String
+		e.RowsAffected = rowsAffected.Int64
 		e.Duration = time.Duration(durationMs) * time.Millisecond
-		e.ExecutedAt, _ = time.Parse("2006-01-02 15:04:05", executedAt)
+		e.ExecutedAt, err = parseTime(executedAt)
+		if err != nil {
+			return nil, err
+		}
 
 		entries = append(entries, e)
 	}
 
-	return entries, nil
+	return entries, rows.Err()
 }
 
-// Search searches query history by query text
-func (s *Store) Search(query string, limit int) ([]HistoryEntry, error) {
-	rows, err := s.db.Query(`
-		SELECT id, connection_name, database_name, query, executed_at,
-		       duration_ms, rows_affected, success, error_message
-		FROM query_history
-		WHERE query LIKE ?
-		ORDER BY executed_at DESC
-		LIMIT ?`, "%"+query+"%", limit)
+// Count returns the number of history entries matching the filter
+func (s *Store) Count(filter Filter) (int, error) {
+	where, args := filter.where()
+
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM query_history "+where, args...).Scan(&count)
+	return count, err
+}
+
+// Connections returns the distinct connection names in history
+func (s *Store) Connections() ([]string, error) {
+	return s.distinct("connection_name")
+}
+
+// Databases returns the distinct database names in history
+func (s *Store) Databases() ([]string, error) {
+	return s.distinct("database_name")
+}
+
+func (s *Store) distinct(column string) ([]string, error) {
+	rows, err := s.db.Query(fmt.Sprintf(
+		"SELECT DISTINCT %[1]s FROM query_history WHERE %[1]s IS NOT NULL AND %[1]s <> '' ORDER BY %[1]s", column))
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var entries []HistoryEntry
+	var values []string
 	for rows.Next() {
-		var e HistoryEntry
-		var durationMs int64
-		var executedAt string
-
-		err := rows.Scan(
-			&e.ID,
-			&e.ConnectionName,
-			&e.DatabaseName,
-			&e.Query,
-			&executedAt,
-			&durationMs,
-			&e.RowsAffected,
-			&e.Success,
-			&e.ErrorMessage,
-		)
-		if err != nil {
+		var value string
+		if err := rows.Scan(&value); err != nil {
 			return nil, err
 		}
+		values = append(values, value)
+	}
 
-		e.Duration = time.Duration(durationMs) * time.Millisecond
-		e.ExecutedAt, _ = time.Parse("2006-01-02 15:04:05", executedAt)
+	return values, rows.Err()
+}
 
-		entries = append(entries, e)
+// Delete removes a single history entry
+func (s *Store) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM query_history WHERE id = ?", id)
+	return err
+}
+
+// PurgeOlderThan removes entries executed more than the given number of days ago
+func (s *Store) PurgeOlderThan(days int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result, err := s.db.Exec("DELETE FROM query_history WHERE executed_at < ?", cutoff.UTC().Format(timeLayout))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Trim keeps only the newest maxEntries entries. A non-positive limit keeps everything.
+func (s *Store) Trim(maxEntries int) (int64, error) {
+	if maxEntries <= 0 {
+		return 0, nil
 	}
 
-	return entries, nil
+	result, err := s.db.Exec(`
+		DELETE FROM query_history
+		WHERE id NOT IN (
+			SELECT id FROM query_history
+			ORDER BY executed_at DESC, id DESC
+			LIMIT ?
+		)`, maxEntries)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 // Close closes the database connection
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func seedStore(t *testing.T, store *Store) time.Time {
+	t.Helper()
+	now := time.Now().Truncate(time.Second)
+	entries := []HistoryEntry{
+		{ConnectionName: "local", DatabaseName: "shop", Query: "SELECT * FROM orders", ExecutedAt: now.Add(-40 * 24 * time.Hour), Duration: 5 * time.Millisecond, Success: true},
+		{ConnectionName: "local", DatabaseName: "shop", Query: "SELECT * FROM users", ExecutedAt: now.Add(-2 * time.Hour), Duration: 1500 * time.Millisecond, Success: true},
+		{ConnectionName: "prod", DatabaseName: "app", Query: "DELETE FROM users", ExecutedAt: now.Add(-1 * time.Hour), Duration: 20 * time.Millisecond, Success: false, ErrorMessage: "permission denied"},
+		{ConnectionName: "prod", DatabaseName: "app", Query: "SELECT 1", ExecutedAt: now, Duration: time.Millisecond, Success: true},
+	}
+	for _, e := range entries {
+		if err := store.Add(e); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	return now
+}
+
+func TestStoreQueryFilters(t *testing.T) {
+	store := newTestStore(t)
+	now := seedStore(t, store)
+	failed := false
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{"all newest first", Filter{}, []string{"SELECT 1", "DELETE FROM users", "SELECT * FROM users", "SELECT * FROM orders"}},
+		{"text", Filter{Text: "users"}, []string{"DELETE FROM users", "SELECT * FROM users"}},
+		{"connection", Filter{ConnectionName: "local"}, []string{"SELECT * FROM users", "SELECT * FROM orders"}},
+		{"database", Filter{DatabaseName: "app"}, []string{"SELECT 1", "DELETE FROM users"}},
+		{"failed", Filter{Success: &failed}, []string{"DELETE FROM users"}},
+		{"since", Filter{Since: now.Add(-24 * time.Hour)}, []string{"SELECT 1", "DELETE FROM users", "SELECT * FROM users"}},
+		{"until", Filter{Until: now.Add(-24 * time.Hour)}, []string{"SELECT * FROM orders"}},
+		{"min duration", Filter{MinDuration: time.Second}, []string{"SELECT * FROM users"}},
+		{"max duration", Filter{MaxDuration: 5 * time.Millisecond}, []string{"SELECT 1", "SELECT * FROM orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.Query(tt.filter, 0, 50)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			var queries []string
+			for _, e := range entries {
+				queries = append(queries, e.Query)
+			}
+			if len(queries) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, queries)
+			}
+			for i := range queries {
+				if queries[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, queries)
+				}
+			}
+
+			count, err := store.Count(tt.filter)
+			if err != nil || count != len(tt.expected) {
+				t.Errorf("Count = %d (%v), expected %d", count, err, len(tt.expected))
+			}
+		})
+	}
+}
+
+func TestStoreExecutedAtRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	now := seedStore(t, store)
+
+	entries, err := store.Query(Filter{}, 0, 50)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	expected := []time.Time{now, now.Add(-1 * time.Hour), now.Add(-2 * time.Hour), now.Add(-40 * 24 * time.Hour)}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, e := range entries {
+		if !e.ExecutedAt.Equal(expected[i]) {
+			t.Errorf("entry %d: ExecutedAt = %v, expected %v", i, e.ExecutedAt, expected[i])
+		}
+	}
+}
+
+func TestStorePagination(t *testing.T) {
+	store := newTestStore(t)
+	seedStore(t, store)
+
+	page, err := store.Query(Filter{}, 1, 2)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(page) != 2 || page[0].Query != "DELETE FROM users" || page[1].Query != "SELECT * FROM users" {
+		t.Errorf("unexpected second page: %+v", page)
+	}
+	if page[0].ErrorMessage != "permission denied" || page[0].Success {
+		t.Errorf("failed entry not read back correctly: %+v", page[0])
+	}
+	if page[1].Duration != 1500*time.Millisecond {
+		t.Errorf("expected duration 1.5s, got %v", page[1].Duration)
+	}
+}
+
+func TestStoreDistinctValues(t *testing.T) {
+	store := newTestStore(t)
+	seedStore(t, store)
+
+	connections, err := store.Connections()
+	if err != nil || len(connections) != 2 || connections[0] != "local" || connections[1] != "prod" {
+		t.Errorf("unexpected connections %v (%v)", connections, err)
+	}
+	databases, err := store.Databases()
+	if err != nil || len(databases) != 2 || databases[0] != "app" || databases[1] != "shop" {
+		t.Errorf("unexpected databases %v (%v)", databases, err)
+	}
+}
+
+func TestStoreDeletePurgeTrim(t *testing.T) {
+	store := newTestStore(t)
+	seedStore(t, store)
+
+	purged, err := store.PurgeOlderThan(30)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeOlderThan = %d (%v), expected 1", purged, err)
+	}
+
+	entries, _ := store.GetRecent(10)
+	if err := store.Delete(entries[0].ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if count, _ := store.Count(Filter{}); count != 2 {
+		t.Fatalf("expected 2 entries after delete, got %d", count)
+	}
+
+	trimmed, err := store.Trim(1)
+	if err != nil || trimmed != 1 {
+		t.Fatalf("Trim = %d (%v), expected 1", trimmed, err)
+	}
+	remaining, _ := store.GetRecent(10)
+	if len(remaining) != 1 || remaining[0].Query != "DELETE FROM users" {
+		t.Errorf("expected newest entry to remain, got %+v", remaining)
+	}
+
+	if trimmed, _ := store.Trim(0); trimmed != 0 {
+		t.Errorf("Trim(0) should keep everything, removed %d", trimmed)
+	}
+}
+
+func TestStoreMigratesParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`CREATE TABLE query_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, connection_name TEXT, database_name TEXT,
+		query TEXT NOT NULL, executed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		duration_ms INTEGER, rows_affected INTEGER, success BOOLEAN NOT NULL, error_message TEXT);
+		INSERT INTO query_history (query, duration_ms, success) VALUES ('SELECT 1', 1, 1)`)
+	_ = old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore failed on an old database: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.Add(HistoryEntry{Query: "SELECT :id", Success: true, Parameters: ":id = '7'"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	entries, err := store.GetRecent(10)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("GetRecent = %d entries (%v), expected 2", len(entries), err)
+	}
+	for _, e := range entries {
+		if want := map[string]string{"SELECT :id": ":id = '7'", "SELECT 1": ""}[e.Query]; e.Parameters != want {
+			t.Errorf("%s: Parameters = %q, want %q", e.Query, e.Parameters, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rebelice/lazypg/internal/db/connection"
+)
+
+// progressInterval is how many rows are sent between progress callbacks
+const progressInterval = 500
+
+// maxCopyRetries limits how many server-rejected rows Load skips before giving up
+const maxCopyRetries = 20
+
+// copyLinePattern extracts the line number from COPY error context
+var copyLinePattern = regexp.MustCompile(`COPY [^,]+, line (\d+)`)
+
+// Copy loads the given rows into schema.table with COPY FROM STDIN.
+// progress is called with the number of rows sent so far (may be nil).
+// COPY is atomic, so on error no rows are inserted; the returned RowError
+// points at the file row the server rejected when it can be determined.
+func Copy(ctx context.Context, pool *connection.Pool, schema, table string, data *Data, mappings []Mapping, rows []int, progress func(done int)) (int64, *RowError, error) {
+	if len(mappings) == 0 {
+		return 0, nil, fmt.Errorf("no columns mapped")
+	}
+
+	conn, err := pool.GetPool().Acquire(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(WriteCopyText(writer, data, mappings, rows, progress))
+	}()
+
+	tag, err := conn.Conn().PgConn().CopyFrom(ctx, reader, CopyStatement(schema, table, mappings))
+	// Unblock the writer if the server stopped reading early
+	_ = reader.Close()
+	if err != nil {
+		return 0, copyRowError(err, rows), fmt.Errorf("COPY failed: %w", err)
+	}
+	if progress != nil {
+		progress(len(rows))
+	}
+	return tag.RowsAffected(), nil, nil
+}
+
+// Load copies rows like Copy, but when the server rejects a row it records
+// the error, drops that row and retries, so one bad row does not block the
+// rest of the file. It returns the inserted count and the rejected rows.
+func Load(ctx context.Context, pool *connection.Pool, schema, table string, data *Data, mappings []Mapping, rows []int, progress func(done int)) (int64, []RowError, error) {
+	var rejected []RowError
+	remaining := rows
+	for attempt := 0; ; attempt++ {
+		if len(remaining) == 0 {
+			return 0, rejected, nil
+		}
+		inserted, rowErr, err := Copy(ctx, pool, schema, table, data, mappings, remaining, progress)
+		if err == nil {
+			return inserted, rejected, nil
+		}
+		if rowErr == nil || attempt >= maxCopyRetries || ctx.Err() != nil {
+			return 0, rejected, err
+		}
+		rejected = append(rejected, *rowErr)
+		remaining = withoutRow(remaining, rowErr.Row-1)
+	}
+}
+
+// withoutRow returns rows without the given row index
+func withoutRow(rows []int, index int) []int {
+	result := make([]int, 0, len(rows))
+	for _, r := range rows {
+		if r != index {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// CopyStatement builds the COPY FROM STDIN statement for the mapped columns
+func CopyStatement(schema, table string, mappings []Mapping) string {
+	columns := make([]string, len(mappings))
+	for i, m := range mappings {
+		columns[i] = quoteIdent(m.TableColumn)
+	}
+	return fmt.Sprintf("COPY %s.%s (%s) FROM STDIN", quoteIdent(schema), quoteIdent(table), strings.Join(columns, ", "))
+}
+
+// WriteCopyText writes rows in the COPY text format
+func WriteCopyText(w io.Writer, data *Data, mappings []Mapping, rows []int, progress func(done int)) error {
+	bw := bufio.NewWriter(w)
+	for n, rowIdx := range rows {
+		row := data.Rows[rowIdx]
+		for i, m := range mappings {
+			if i > 0 {
+				_ = bw.WriteByte('\t')
+			}
+			if m.FileColumn >= len(row) || row[m.FileColumn].Null {
+				_, _ = bw.WriteString(`\N`)
+			} else {
+				_, _ = bw.WriteString(escapeCopyText(row[m.FileColumn].Value))
+			}
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+		if progress != nil && (n+1)%progressInterval == 0 {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			progress(n + 1)
+		}
+	}
+	return bw.Flush()
+}
+
+// escapeCopyText escapes a value for the COPY text format
+func escapeCopyText(value string) string {
+	if !strings.ContainsAny(value, "\\\t\n\r") {
+		return value
+	}
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// copyRowError maps a COPY error back to the file row it refers to
+func copyRowError(err error, rows []int) *RowError {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	match := copyLinePattern.FindStringSubmatch(pgErr.Where)
+	if match == nil {
+		return nil
+	}
+	line, convErr := strconv.Atoi(match[1])
+	if convErr != nil || line < 1 || line > len(rows) {
+		return nil
+	}
+	return &RowError{Row: rows[line-1] + 1, Column: pgErr.ColumnName, Message: pgErr.Message}
+}
+
+// quoteIdent quotes a PostgreSQL identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,251 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies an import file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// Field is a single value read from an import file
+type Field struct {
+	Value string
+	Null  bool
+}
+
+// Data holds the parsed contents of an import file
+type Data struct {
+	Format  Format
+	Columns []string
+	Rows    [][]Field
+}
+
+// DetectFormat determines the file format from its extension
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported file type %q (expected .csv, .json, .ndjson or .jsonl)", filepath.Ext(path))
+	}
+}
+
+// ReadFile reads and parses an import file
+func ReadFile(path string) (*Data, error) {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var data *Data
+	switch format {
+	case FormatCSV:
+		data, err = ReadCSV(file)
+	case FormatJSON:
+		data, err = ReadJSON(file)
+	case FormatNDJSON:
+		data, err = ReadNDJSON(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Columns) == 0 {
+		return nil, fmt.Errorf("no columns found in %s", filepath.Base(path))
+	}
+	return data, nil
+}
+
+// ReadCSV parses CSV with a header row. Empty fields are read as NULL.
+func ReadCSV(r io.Reader) (*Data, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Report ragged rows during validation instead of failing
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &Data{Format: FormatCSV}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	// Strip a UTF-8 byte order mark from the first column name
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	data := &Data{Format: FormatCSV, Columns: header}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		row := make([]Field, len(header))
+		for i := range row {
+			if i < len(record) && record[i] != "" {
+				row[i] = Field{Value: record[i]}
+			} else {
+				row[i] = Field{Null: true}
+			}
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data, nil
+}
+
+// ReadJSON parses a JSON array of objects
+func ReadJSON(r io.Reader) (*Data, error) {
+	var objects []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of objects: %w", err)
+	}
+
+	builder := newObjectBuilder(FormatJSON)
+	for i, raw := range objects {
+		if err := builder.add(raw); err != nil {
+			return nil, fmt.Errorf("object %d: %w", i+1, err)
+		}
+	}
+	return builder.data(), nil
+}
+
+// ReadNDJSON parses one JSON object per line. Blank lines are ignored.
+func ReadNDJSON(r io.Reader) (*Data, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	builder := newObjectBuilder(FormatNDJSON)
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if err := builder.add(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return builder.data(), nil
+}
+
+// jsonObject is a decoded JSON object that remembers its key order
+type jsonObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// objectBuilder collects JSON objects into rows. Columns are the union of
+// all keys in first-seen order; missing keys are read as NULL.
+type objectBuilder struct {
+	format  Format
+	columns []string
+	index   map[string]int
+	objects []jsonObject
+}
+
+func newObjectBuilder(format Format) *objectBuilder {
+	return &objectBuilder{format: format, index: make(map[string]int)}
+}
+
+func (b *objectBuilder) add(raw []byte) error {
+	obj, err := decodeObject(raw)
+	if err != nil {
+		return err
+	}
+	for _, key := range obj.keys {
+		if _, ok := b.index[key]; !ok {
+			b.index[key] = len(b.columns)
+			b.columns = append(b.columns, key)
+		}
+	}
+	b.objects = append(b.objects, obj)
+	return nil
+}
+
+func (b *objectBuilder) data() *Data {
+	data := &Data{Format: b.format, Columns: b.columns}
+	for _, obj := range b.objects {
+		row := make([]Field, len(b.columns))
+		for i, col := range b.columns {
+			row[i] = jsonField(obj.values[col])
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+// decodeObject decodes a JSON object, keeping keys in document order
+func decodeObject(raw []byte) (jsonObject, error) {
+	obj := jsonObject{values: make(map[string]json.RawMessage)}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	token, err := decoder.Token()
+	if err != nil {
+		return obj, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return obj, fmt.Errorf("expected a JSON object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return obj, fmt.Errorf("invalid JSON: %w", err)
+		}
+		key := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return obj, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if _, exists := obj.values[key]; !exists {
+			obj.keys = append(obj.keys, key)
+		}
+		obj.values[key] = value
+	}
+	if _, err := decoder.Token(); err != nil {
+		return obj, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return obj, nil
+}
+
+// jsonField converts a raw JSON value to a field.
+// Strings are unquoted; objects and arrays are kept as JSON text.
+func jsonField(raw json.RawMessage) Field {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || string(trimmed) == "null" {
+		return Field{Null: true}
+	}
+	if trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err == nil {
+			return Field{Value: s}
+		}
+	}
+	return Field{Value: string(trimmed)}
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{
+		"users.csv":    FormatCSV,
+		"USERS.JSON":   FormatJSON,
+		"users.ndjson": FormatNDJSON,
+		"users.jsonl":  FormatNDJSON,
+	}
+	for path, expected := range tests {
+		got, err := DetectFormat(path)
+		if err != nil || got != expected {
+			t.Errorf("DetectFormat(%q) = %q, %v; expected %q", path, got, err, expected)
+		}
+	}
+	if _, err := DetectFormat("users.xlsx"); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "id,name,note\n1,alice,\"multi\nline\"\n2,,\n3,carol\n"
+	data, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadCSV failed: %v", err)
+	}
+	if len(data.Columns) != 3 || data.Columns[1] != "name" {
+		t.Fatalf("unexpected columns: %v", data.Columns)
+	}
+	if len(data.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(data.Rows))
+	}
+	if data.Rows[0][2].Value != "multi\nline" {
+		t.Errorf("expected quoted newline to be preserved, got %q", data.Rows[0][2].Value)
+	}
+	if !data.Rows[1][1].Null || !data.Rows[1][2].Null {
+		t.Errorf("expected empty fields to be NULL: %+v", data.Rows[1])
+	}
+	if !data.Rows[2][2].Null {
+		t.Errorf("expected missing trailing field to be NULL: %+v", data.Rows[2])
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `[{"name": "alice", "id": 1, "tags": ["a", "b"]}, {"id": 2, "name": null, "active": true}]`
+	data, err := ReadJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+
+	expected := []string{"name", "id", "tags", "active"}
+	if strings.Join(data.Columns, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected columns %v in document order, got %v", expected, data.Columns)
+	}
+	if data.Rows[0][1].Value != "1" || data.Rows[0][2].Value != `["a", "b"]` {
+		t.Errorf("unexpected first row: %+v", data.Rows[0])
+	}
+	if !data.Rows[1][0].Null || !data.Rows[0][3].Null {
+		t.Errorf("expected null and missing keys to be NULL")
+	}
+	if data.Rows[1][3].Value != "true" {
+		t.Errorf("expected boolean as text, got %q", data.Rows[1][3].Value)
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"id": 1}`)); err == nil {
+		t.Error("expected error for non-array JSON")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	input := "{\"id\": 1, \"name\": \"alice\"}\n\n{\"id\": 2, \"email\": \"b@example.com\"}\n"
+	data, err := ReadNDJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadNDJSON failed: %v", err)
+	}
+	if len(data.Rows) != 2 || len(data.Columns) != 3 {
+		t.Fatalf("expected 2 rows and 3 columns, got %d rows and %v", len(data.Rows), data.Columns)
+	}
+
+	if _, err := ReadNDJSON(strings.NewReader("{\"id\": 1}\n[1, 2]\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error mentioning line 2, got %v", err)
+	}
+}
+
+var testTableColumns = []models.ColumnDetail{
+	{Name: "id", DataType: "integer"},
+	{Name: "Name", DataType: "character varying(5)"},
+	{Name: "active", DataType: "boolean"},
+}
+
+func TestAutoMap(t *testing.T) {
+	got := AutoMap([]string{"NAME", "extra", "id", "Id"}, testTableColumns)
+	expected := []int{1, -1, 0, -1}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+
+	mappings := BuildMappings(got, testTableColumns)
+	if len(mappings) != 2 || mappings[0].TableColumn != "Name" || mappings[1].FileColumn != 2 {
+		t.Errorf("unexpected mappings: %+v", mappings)
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	valid := [][2]string{
+		{"integer", " 42 "},
+		{"smallint", "-32768"},
+		{"bigint", "9223372036854775807"},
+		{"numeric(10,2)", "3.14"},
+		{"double precision", "NaN"},
+		{"boolean", "yes"},
+		{"uuid", "A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11"},
+		{"jsonb", `{"a": [1, 2]}`},
+		{"character varying(3)", "héé"},
+		{"character(3)", "ab   "},
+		{"date", "not checked locally"},
+	}
+	for _, tc := range valid {
+		if err := CheckValue(tc[0], tc[1]); err != nil {
+			t.Errorf("CheckValue(%q, %q) returned error: %v", tc[0], tc[1], err)
+		}
+	}
+
+	invalid := [][2]string{
+		{"integer", "4.2"},
+		{"smallint", "40000"},
+		{"numeric", "abc"},
+		{"boolean", "maybe"},
+		{"uuid", "1234"},
+		{"json", "{broken"},
+		{"character varying(3)", "abcd"},
+	}
+	for _, tc := range invalid {
+		if err := CheckValue(tc[0], tc[1]); err == nil {
+			t.Errorf("CheckValue(%q, %q) expected error", tc[0], tc[1])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	data := &Data{
+		Columns: []string{"id", "name"},
+		Rows: [][]Field{
+			{{Value: "1"}, {Value: "alice"}},
+			{{Value: "x"}, {Value: "bob"}},
+			{{Null: true}, {Value: "toolong"}},
+			{{Value: "4"}, {Null: true}},
+		},
+	}
+	mappings := BuildMappings([]int{0, 1}, testTableColumns)
+
+	valid, errors := Validate(data, mappings)
+	if len(valid) != 2 || valid[0] != 0 || valid[1] != 3 {
+		t.Errorf("expected rows [0 3] to be valid, got %v", valid)
+	}
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 row errors, got %d", len(errors))
+	}
+	if errors[0].Row != 2 || errors[0].Column != "id" {
+		t.Errorf("unexpected first error: %+v", errors[0])
+	}
+	if !strings.HasPrefix(errors[1].String(), "row 3, Name: value too long") {
+		t.Errorf("unexpected error text: %s", errors[1].String())
+	}
+}
+
+func TestWriteCopyText(t *testing.T) {
+	data := &Data{
+		Columns: []string{"id", "note", "skipped"},
+		Rows: [][]Field{
+			{{Value: "1"}, {Value: "tab\there\nback\\slash"}, {Value: "x"}},
+			{{Value: "2"}, {Null: true}, {Value: "y"}},
+		},
+	}
+	mappings := []Mapping{{FileColumn: 0, TableColumn: "id"}, {FileColumn: 1, TableColumn: "note"}}
+
+	var buf bytes.Buffer
+	if err := WriteCopyText(&buf, data, mappings, []int{0, 1}, nil); err != nil {
+		t.Fatalf("WriteCopyText failed: %v", err)
+	}
+	expected := "1\ttab\\there\\nback\\\\slash\n2\t\\N\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	stmt := CopyStatement("public", `we"ird`, mappings)
+	if stmt != `COPY "public"."we""ird" ("id", "note") FROM STDIN` {
+		t.Errorf("unexpected COPY statement: %s", stmt)
+	}
+}
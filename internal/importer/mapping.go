@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// Mapping assigns a file column to a table column
+type Mapping struct {
+	FileColumn  int    // Index into Data.Columns
+	TableColumn string // Target column name
+	DataType    string // Formatted type from GetColumnDetails
+}
+
+// RowError describes why a file row could not be imported
+type RowError struct {
+	Row     int    // 1-based data row number in the file (header excluded)
+	Column  string // Table column, empty when the error is not column specific
+	Message string
+}
+
+// String formats the error for display
+func (e RowError) String() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, %s: %s", e.Row, e.Column, e.Message)
+}
+
+// AutoMap matches file columns to table columns by name (case-insensitive).
+// The result holds the table column index for each file column, or -1.
+func AutoMap(fileColumns []string, tableColumns []models.ColumnDetail) []int {
+	byName := make(map[string]int, len(tableColumns))
+	for i, col := range tableColumns {
+		byName[strings.ToLower(col.Name)] = i
+	}
+
+	used := make(map[int]bool)
+	result := make([]int, len(fileColumns))
+	for i, name := range fileColumns {
+		result[i] = -1
+		if idx, ok := byName[strings.ToLower(strings.TrimSpace(name))]; ok && !used[idx] {
+			result[i] = idx
+			used[idx] = true
+		}
+	}
+	return result
+}
+
+// BuildMappings converts a file-to-table index assignment into mappings,
+// skipping unmapped file columns
+func BuildMappings(assignment []int, tableColumns []models.ColumnDetail) []Mapping {
+	var mappings []Mapping
+	for fileCol, tableCol := range assignment {
+		if tableCol < 0 || tableCol >= len(tableColumns) {
+			continue
+		}
+		mappings = append(mappings, Mapping{
+			FileColumn:  fileCol,
+			TableColumn: tableColumns[tableCol].Name,
+			DataType:    tableColumns[tableCol].DataType,
+		})
+	}
+	return mappings
+}
+
+// Validate type-checks every row against the mapped columns. It returns the
+// indexes of rows that passed and an error entry for each row that did not.
+func Validate(data *Data, mappings []Mapping) ([]int, []RowError) {
+	var valid []int
+	var errors []RowError
+	for i, row := range data.Rows {
+		rowErr := validateRow(row, mappings)
+		if rowErr != nil {
+			rowErr.Row = i + 1
+			errors = append(errors, *rowErr)
+			continue
+		}
+		valid = append(valid, i)
+	}
+	return valid, errors
+}
+
+func validateRow(row []Field, mappings []Mapping) *RowError {
+	for _, m := range mappings {
+		if m.FileColumn >= len(row) || row[m.FileColumn].Null {
+			continue // NOT NULL violations are reported by the server
+		}
+		if err := CheckValue(m.DataType, row[m.FileColumn].Value); err != nil {
+			return &RowError{Column: m.TableColumn, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// CheckValue reports whether a value can be parsed as the given column type.
+// Types that are not checked locally (dates, enums, ...) are left to the server.
+func CheckValue(dataType, value string) error {
+	base, modifier := splitType(dataType)
+	trimmed := strings.TrimSpace(value)
+
+	switch base {
+	case "smallint":
+		return checkInt(trimmed, 16)
+	case "integer":
+		return checkInt(trimmed, 32)
+	case "bigint":
+		return checkInt(trimmed, 64)
+	case "numeric", "real", "double precision":
+		if strings.EqualFold(trimmed, "nan") || strings.EqualFold(trimmed, "infinity") || strings.EqualFold(trimmed, "-infinity") {
+			return nil
+		}
+		if _, err := strconv.ParseFloat(trimmed, 64); err != nil {
+			return fmt.Errorf("invalid %s %q", base, value)
+		}
+	case "boolean":
+		if !isBoolLiteral(trimmed) {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+	case "uuid":
+		if !isUUID(trimmed) {
+			return fmt.Errorf("invalid uuid %q", value)
+		}
+	case "json", "jsonb":
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("invalid %s", base)
+		}
+	case "character varying", "character":
+		if limit, err := strconv.Atoi(modifier); err == nil {
+			check := value
+			if base == "character" {
+				check = strings.TrimRight(value, " ")
+			}
+			if n := utf8.RuneCountInString(check); n > limit {
+				return fmt.Errorf("value too long for %s (%d > %d)", dataType, n, limit)
+			}
+		}
+	}
+	return nil
+}
+
+// splitType splits "character varying(20)" into its base name and modifier
+func splitType(dataType string) (string, string) {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	open := strings.Index(dataType, "(")
+	if open < 0 || !strings.HasSuffix(dataType, ")") {
+		return dataType, ""
+	}
+	return strings.TrimSpace(dataType[:open]), dataType[open+1 : len(dataType)-1]
+}
+
+func checkInt(value string, bits int) error {
+	if _, err := strconv.ParseInt(value, 10, bits); err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return fmt.Errorf("%q is out of range for a %d-bit integer", value, bits)
+		}
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	return nil
+}
+
+// isBoolLiteral accepts the boolean input forms PostgreSQL understands
+func isBoolLiteral(value string) bool {
+	switch strings.ToLower(value) {
+	case "t", "true", "y", "yes", "on", "1", "f", "false", "n", "no", "off", "0":
+		return true
+	}
+	return false
+}
+
+// isUUID accepts UUIDs with or without hyphens and braces
+func isUUID(value string) bool {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "{"), "}")
+	value = strings.ReplaceAll(value, "-", "")
+	if len(value) != 32 {
+		return false
+	}
+	for _, r := range value {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,24 +1,34 @@
 package models
 
 import (
+	"maps"
 	"time"
 )
 
 // ConnectionConfig represents a PostgreSQL connection configuration
 type ConnectionConfig struct {
-	Name     string `yaml:"name"`
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Database string `yaml:"database"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	SSLMode  string `yaml:"ssl_mode"`
+	Name          string            `yaml:"name"`
+	Host          string            `yaml:"host"` // One host or several separated by commas
+	Port          int               `yaml:"port"`
+	Database      string            `yaml:"database"`
+	User          string            `yaml:"user"`
+	Password      string            `yaml:"password"`
+	SSLMode       string            `yaml:"ssl_mode"`
+	Params        map[string]string `yaml:"params,omitempty"`         // Other libpq keywords, e.g. sslrootcert or application_name
+	ReadOnly      bool              `yaml:"read_only,omitempty"`      // Sessions default to read-only transactions
+	DefaultSchema string            `yaml:"default_schema,omitempty"` // Put first on the search_path
+	StartupSQL    string            `yaml:"startup_sql,omitempty"`    // Run on every new session
+	Color         string            `yaml:"color,omitempty"`          // Color of the connection in the UI
+
+	Profile        string `yaml:"-"` // Name of the profile it came from
+	NoSavePassword bool   `yaml:"-"` // Password must not be saved, e.g. one printed by a password command
 }
 
 // Connection represents an active database connection
 type Connection struct {
 	ID          string
 	Config      ConnectionConfig
+	Database    string // Database in use, Config.Database until another is chosen
 	Connected   bool
 	ConnectedAt time.Time
 	LastPing    time.Time
@@ -85,6 +95,8 @@ type ConnectionHistoryEntry struct {
 	User        string    `yaml:"user"`
 	// Note: Password is NOT stored for security reasons
 	SSLMode     string    `yaml:"ssl_mode"`
+	Params      map[string]string `yaml:"params,omitempty"` // Other libpq keywords, never the password
+	Profile     string    `yaml:"profile,omitempty"` // Profile it was connected through, reconnected through it
 	LastUsed    time.Time `yaml:"last_used"`
 	UsageCount  int       `yaml:"usage_count"`
 	CreatedAt   time.Time `yaml:"created_at"`
@@ -100,5 +112,7 @@ func (e *ConnectionHistoryEntry) ToConnectionConfig() ConnectionConfig {
 		User:     e.User,
 		Password: "", // Password not stored in history
 		SSLMode:  e.SSLMode,
+		Params:   maps.Clone(e.Params),
+		Profile:  e.Profile,
 	}
 }
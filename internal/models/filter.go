@@ -12,6 +12,9 @@ const (
 	OpLessOrEqual    FilterOperator = "<="
 	OpLike           FilterOperator = "LIKE"
 	OpILike          FilterOperator = "ILIKE"
+	OpNotLike        FilterOperator = "NOT LIKE"
+	OpNotILike       FilterOperator = "NOT ILIKE"
+	OpBetween        FilterOperator = "BETWEEN"
 	OpIn             FilterOperator = "IN"
 	OpNotIn          FilterOperator = "NOT IN"
 	OpIsNull         FilterOperator = "IS NULL"
@@ -22,6 +25,12 @@ const (
 	OpArrayOverlap   FilterOperator = "&&"  // Array overlap
 )
 
+// TakesList reports whether the operator compares against a list of values.
+// Conditions using these operators hold a []string Value.
+func (op FilterOperator) TakesList() bool {
+	return op == OpIn || op == OpNotIn || op == OpBetween
+}
+
 // FilterCondition represents a single filter condition
 type FilterCondition struct {
 	Column   string
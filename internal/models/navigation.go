@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxNavigationHistory is how many locations the navigation history keeps
+const maxNavigationHistory = 50
+
+// TableLocation is a table, optionally narrowed to some of its rows, as
+// shown in a table data tab
+type TableLocation struct {
+	Schema string
+	Table  string
+	Filter *Filter // nil shows every row
+	Row    int     // Selected row when the location was left
+	Col    int     // Selected column when the location was left
+}
+
+// ObjectID returns the identifier of the tab showing the location. A
+// location without a filter is the tab opened from the explorer.
+func (l TableLocation) ObjectID() string {
+	id := l.Schema + "." + l.Table
+	if l.Filter != nil {
+		id += "?" + DescribeFilter(*l.Filter)
+	}
+	return id
+}
+
+// Title returns the tab title for the location
+func (l TableLocation) Title() string {
+	if l.Filter == nil {
+		return l.Table
+	}
+	return fmt.Sprintf("%s (%s)", l.Table, DescribeFilter(*l.Filter))
+}
+
+// DescribeFilter returns the conditions of a filter as text, e.g.
+// "customer_id = 42 AND region IS NULL"
+func DescribeFilter(f Filter) string {
+	return describeGroup(f.RootGroup)
+}
+
+// describeGroup returns the conditions of a filter group as text
+func describeGroup(group FilterGroup) string {
+	logic := group.Logic
+	if logic == "" {
+		logic = "AND"
+	}
+	var parts []string
+	for _, cond := range group.Conditions {
+		switch {
+		case cond.Operator == OpIsNull || cond.Operator == OpIsNotNull:
+			parts = append(parts, fmt.Sprintf("%s %s", cond.Column, cond.Operator))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %s %v", cond.Column, cond.Operator, cond.Value))
+		}
+	}
+	for _, sub := range group.Groups {
+		parts = append(parts, "("+describeGroup(sub)+")")
+	}
+	return strings.Join(parts, " "+logic+" ")
+}
+
+// NavigationHistory is the back/forward history of table locations visited
+// by following foreign keys
+type NavigationHistory struct {
+	entries []TableLocation
+	current int // Index of the location shown, -1 when empty
+}
+
+// NewNavigationHistory creates an empty navigation history
+func NewNavigationHistory() *NavigationHistory {
+	return &NavigationHistory{current: -1}
+}
+
+// Visit records moving from one location to another. Locations ahead of the
+// current one are forgotten.
+func (h *NavigationHistory) Visit(from, to TableLocation) {
+	h.record(from)
+	h.entries = append(h.entries[:h.current+1], to)
+	h.current++
+	if len(h.entries) > maxNavigationHistory {
+		drop := len(h.entries) - maxNavigationHistory
+		h.entries = h.entries[drop:]
+		h.current -= drop
+	}
+}
+
+// Back returns the location before the one being left, if any
+func (h *NavigationHistory) Back(from TableLocation) (TableLocation, bool) {
+	if len(h.entries) == 0 {
+		return TableLocation{}, false
+	}
+	h.record(from)
+	if h.current == 0 {
+		return TableLocation{}, false
+	}
+	h.current--
+	return h.entries[h.current], true
+}
+
+// Forward returns the location after the one being left, if it was reached
+// by going back
+func (h *NavigationHistory) Forward(from TableLocation) (TableLocation, bool) {
+	if h.current < 0 || h.entries[h.current].ObjectID() != from.ObjectID() {
+		return TableLocation{}, false
+	}
+	h.entries[h.current] = from
+	if h.current == len(h.entries)-1 {
+		return TableLocation{}, false
+	}
+	h.current++
+	return h.entries[h.current], true
+}
+
+// record makes from the current location, keeping its selection. Leaving a
+// location that is not the current one, e.g. after switching tabs, forgets
+// the locations ahead.
+func (h *NavigationHistory) record(from TableLocation) {
+	if h.current >= 0 && h.entries[h.current].ObjectID() == from.ObjectID() {
+		h.entries[h.current] = from
+		return
+	}
+	h.entries = append(h.entries[:h.current+1], from)
+	h.current++
+}
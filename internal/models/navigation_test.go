@@ -0,0 +1,80 @@
+package models
+
+import "testing"
+
+func location(table string, row int, value string) TableLocation {
+	loc := TableLocation{Schema: "public", Table: table, Row: row}
+	if value != "" {
+		loc.Filter = &Filter{Schema: "public", TableName: table, RootGroup: FilterGroup{
+			Logic:      "AND",
+			Conditions: []FilterCondition{{Column: "id", Operator: OpEqual, Value: value}},
+		}}
+	}
+	return loc
+}
+
+func TestTableLocation(t *testing.T) {
+	loc := location("customers", 0, "42")
+	if got := loc.ObjectID(); got != "public.customers?id = 42" {
+		t.Errorf("unexpected object ID %q", got)
+	}
+	if got := loc.Title(); got != "customers (id = 42)" {
+		t.Errorf("unexpected title %q", got)
+	}
+	if got := location("customers", 0, "").ObjectID(); got != "public.customers" {
+		t.Errorf("expected the explorer tab's object ID, got %q", got)
+	}
+
+	loc.Filter.RootGroup.Conditions = append(loc.Filter.RootGroup.Conditions, FilterCondition{Column: "region", Operator: OpIsNull})
+	if got := DescribeFilter(*loc.Filter); got != "id = 42 AND region IS NULL" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestNavigationHistory(t *testing.T) {
+	h := NewNavigationHistory()
+	if _, ok := h.Back(location("orders", 0, "")); ok {
+		t.Fatal("expected no history")
+	}
+
+	orders, customer, country := location("orders", 0, ""), location("customers", 0, "7"), location("countries", 0, "3")
+	h.Visit(location("orders", 5, ""), customer)
+	h.Visit(location("customers", 0, "7"), country)
+
+	back, ok := h.Back(country)
+	if !ok || back.ObjectID() != customer.ObjectID() {
+		t.Fatalf("expected customer, got %+v", back)
+	}
+	back, ok = h.Back(customer)
+	if !ok || back.ObjectID() != orders.ObjectID() || back.Row != 5 {
+		t.Fatalf("expected orders at row 5, got %+v", back)
+	}
+	if _, ok := h.Back(location("orders", 6, "")); ok {
+		t.Fatal("expected the start of the history")
+	}
+
+	forward, ok := h.Forward(location("orders", 6, ""))
+	if !ok || forward.ObjectID() != customer.ObjectID() {
+		t.Fatalf("expected customer, got %+v", forward)
+	}
+	back, _ = h.Back(customer)
+	if back.Row != 6 {
+		t.Errorf("expected the selection kept when going forward, got row %d", back.Row)
+	}
+
+	// Following another key forgets the locations ahead
+	h.Visit(location("orders", 6, ""), location("customers", 0, "8"))
+	if _, ok := h.Forward(location("customers", 0, "8")); ok {
+		t.Error("expected no locations ahead")
+	}
+
+	// Switching tabs leaves the history; forward no longer applies
+	h.Back(location("customers", 0, "8"))
+	if _, ok := h.Forward(location("products", 0, "")); ok {
+		t.Error("expected no forward from another tab")
+	}
+	back, ok = h.Back(location("products", 0, ""))
+	if !ok || back.ObjectID() != orders.ObjectID() {
+		t.Errorf("expected orders before the tab switched to, got %+v", back)
+	}
+}
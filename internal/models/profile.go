@@ -0,0 +1,40 @@
+package models
+
+// ConnectionProfile is a named connection defined in config.yaml or connections.yaml
+type ConnectionProfile struct {
+	Name            string   `yaml:"name" mapstructure:"name"`
+	Host            string   `yaml:"host" mapstructure:"host"`
+	Port            int      `yaml:"port" mapstructure:"port"`
+	Database        string   `yaml:"database" mapstructure:"database"`
+	User            string   `yaml:"user" mapstructure:"user"`
+	SSLMode         string   `yaml:"sslmode,omitempty" mapstructure:"sslmode"`
+	PasswordCommand string   `yaml:"password_command,omitempty" mapstructure:"password_command"` // Shell command printing the password
+	Tags            []string `yaml:"tags,omitempty" mapstructure:"tags"`
+	Color           string   `yaml:"color,omitempty" mapstructure:"color"` // Color name or "#rrggbb"
+	ReadOnly        bool     `yaml:"read_only,omitempty" mapstructure:"read_only"`
+	DefaultSchema   string   `yaml:"default_schema,omitempty" mapstructure:"default_schema"`
+	StartupSQL      string   `yaml:"startup_sql,omitempty" mapstructure:"startup_sql"`
+
+	Source string `yaml:"-" mapstructure:"-"` // File the profile was read from
+}
+
+// ToConnectionConfig converts a profile to a ConnectionConfig (without password)
+func (p *ConnectionProfile) ToConnectionConfig() ConnectionConfig {
+	port := p.Port
+	if port == 0 {
+		port = 5432
+	}
+	return ConnectionConfig{
+		Name:          p.Name,
+		Host:          p.Host,
+		Port:          port,
+		Database:      p.Database,
+		User:          p.User,
+		SSLMode:       p.SSLMode,
+		ReadOnly:      p.ReadOnly,
+		DefaultSchema: p.DefaultSchema,
+		StartupSQL:    p.StartupSQL,
+		Color:         p.Color,
+		Profile:       p.Name,
+	}
+}
@@ -5,8 +5,25 @@ import "time"
 // QueryResult represents the result of a SQL query execution
 type QueryResult struct {
 	Columns      []string
-	Rows         [][]string
+	Rows         [][]string // Display text of each cell
+	Cells        [][]Cell   // Typed values behind Rows, nil when unknown
 	RowsAffected int64
+	Command      string // Command tag, e.g. "UPDATE 42"
 	Duration     time.Duration
 	Error        error
 }
+
+// Cell is a single value of a result row together with its PostgreSQL type
+type Cell struct {
+	OID   uint32      // Type OID of the column, 0 when unknown
+	Value interface{} // Value as decoded by the driver, nil for NULL
+}
+
+// IsNull reports whether the cell holds SQL NULL
+func (c Cell) IsNull() bool {
+	return c.Value == nil
+}
+
+// BindValues holds the values of a statement's bind variables, keyed by the
+// placeholder as written, e.g. ":user_id" or "$1". A nil value binds NULL.
+type BindValues map[string]*string
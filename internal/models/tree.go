@@ -10,6 +10,7 @@ type TreeNodeType string
 
 const (
 	TreeNodeTypeRoot       TreeNodeType = "root"
+	TreeNodeTypeServer     TreeNodeType = "server" // An open connection, with the databases of its server
 	TreeNodeTypeDatabase   TreeNodeType = "database"
 	TreeNodeTypeSchema     TreeNodeType = "schema"
 	TreeNodeTypeTableGroup TreeNodeType = "table_group" // "Tables" folder under schema
@@ -222,6 +223,17 @@ func BuildDatabaseTree(databases []string, activeDB string) *TreeNode {
 	return root
 }
 
+// BuildServerNode builds the node of an open connection, with the
+// databases of its server as children
+func BuildServerNode(connID string, databases []string, activeDB string) *TreeNode {
+	server := NewTreeNode(fmt.Sprintf("server:%s", connID), TreeNodeTypeServer, connID)
+	for _, dbNode := range BuildDatabaseTree(databases, activeDB).Children {
+		server.AddChild(dbNode)
+	}
+	server.Loaded = true
+	return server
+}
+
 // RefreshTreeChildren refreshes children of a specific node
 // This replaces the node's children with the provided list
 // Used for lazy loading when a node is expanded
@@ -278,6 +290,7 @@ func BuildTableNodes(dbName, schemaName string, tables []string) []*TreeNode {
 type ColumnInfo struct {
 	Name       string
 	DataType   string
+	TypeName   string // Castable type name, e.g. "integer", "_int4" or "public.mood"
 	Nullable   bool
 	PrimaryKey bool
 	Default    *string
@@ -285,6 +298,14 @@ type ColumnInfo struct {
 	IsJsonb    bool
 }
 
+// CastType returns the type name filter values should be cast to
+func (c ColumnInfo) CastType() string {
+	if c.TypeName != "" {
+		return c.TypeName
+	}
+	return c.DataType
+}
+
 // BuildColumnNodes creates column nodes for a table
 // This is a helper function for lazy loading columns when a table is expanded
 func BuildColumnNodes(dbName, schemaName, tableName string, columns []ColumnInfo) []*TreeNode {
@@ -338,6 +359,17 @@ func GetDatabaseFromNode(node *TreeNode) string {
 	return ""
 }
 
+// GetConnectionFromNode returns the ID of the connection of any node below
+// a server node
+func GetConnectionFromNode(node *TreeNode) string {
+	for current := node; current != nil; current = current.Parent {
+		if current.Type == TreeNodeTypeServer {
+			return strings.TrimPrefix(current.ID, "server:")
+		}
+	}
+	return ""
+}
+
 // GetSchemaFromNode returns the schema name for any node in a schema or below
 func GetSchemaFromNode(node *TreeNode) string {
 	if node == nil {
@@ -371,6 +371,31 @@ func TestGetDatabaseFromNode(t *testing.T) {
 	}
 }
 
+func TestBuildServerNode(t *testing.T) {
+	root := NewTreeNode("root", TreeNodeTypeRoot, "Databases")
+	server := BuildServerNode("dev@localhost:5432/app", []string{"app", "postgres"}, "app")
+	root.AddChild(server)
+
+	if server.Type != TreeNodeTypeServer || server.Label != "dev@localhost:5432/app" {
+		t.Errorf("unexpected server node %+v", server)
+	}
+	if len(server.Children) != 2 || server.Children[0].Parent != server {
+		t.Fatalf("expected the databases under the server node")
+	}
+	table := NewTreeNode("table:app.public.users", TreeNodeTypeTable, "users")
+	server.Children[0].AddChild(table)
+
+	if got := GetConnectionFromNode(table); got != "dev@localhost:5432/app" {
+		t.Errorf("expected the connection of a table node, got %q", got)
+	}
+	if got := GetDatabaseFromNode(table); got != "app" {
+		t.Errorf("expected the database of a table node, got %q", got)
+	}
+	if got := GetConnectionFromNode(root); got != "" {
+		t.Errorf("expected no connection for the root node, got %q", got)
+	}
+}
+
 func TestGetSchemaFromNode(t *testing.T) {
 	db := NewTreeNode("db:postgres", TreeNodeTypeDatabase, "postgres")
 	schema := NewTreeNode("schema:postgres.public", TreeNodeTypeSchema, "public")
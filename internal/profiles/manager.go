@@ -0,0 +1,202 @@
+package profiles
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/rebelice/lazypg/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources a profile can be read from
+const (
+	SourceConfig      = "config.yaml"
+	SourceConnections = "connections.yaml"
+)
+
+// Manager manages named connection profiles. Profiles come from the
+// connections section of config.yaml and from connections.yaml, which wins
+// when both define the same name. Only connections.yaml is written.
+type Manager struct {
+	path       string
+	configured []models.ConnectionProfile
+	saved      []models.ConnectionProfile
+}
+
+// NewManager creates a new profiles manager
+func NewManager(configDir string, configured []models.ConnectionProfile) (*Manager, error) {
+	m := &Manager{
+		path:  filepath.Join(configDir, SourceConnections),
+		saved: []models.ConnectionProfile{},
+	}
+	for _, p := range configured {
+		p.Source = SourceConfig
+		m.configured = append(m.configured, p)
+	}
+
+	// Load existing profiles if file exists
+	if _, err := os.Stat(m.path); err == nil {
+		if err := m.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load connection profiles: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// connectionsFile is the layout of connections.yaml
+type connectionsFile struct {
+	Connections []models.ConnectionProfile `yaml:"connections"`
+}
+
+// Load loads profiles from connections.yaml
+func (m *Manager) Load() error {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to read connections file: %w", err)
+	}
+
+	var file connectionsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse connections: %w", err)
+	}
+
+	m.saved = m.saved[:0]
+	for _, p := range file.Connections {
+		p.Source = SourceConnections
+		m.saved = append(m.saved, p)
+	}
+	return nil
+}
+
+// Save saves profiles to connections.yaml
+func (m *Manager) Save() error {
+	data, err := yaml.Marshal(connectionsFile{Connections: m.saved})
+	if err != nil {
+		return fmt.Errorf("failed to marshal connections: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(m.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write connections file: %w", err)
+	}
+	return nil
+}
+
+// GetAll returns every profile sorted by name
+func (m *Manager) GetAll() []models.ConnectionProfile {
+	all := make([]models.ConnectionProfile, 0, len(m.configured)+len(m.saved))
+	all = append(all, m.saved...)
+	for _, p := range m.configured {
+		if indexOf(m.saved, p.Name) < 0 {
+			all = append(all, p)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return strings.ToLower(all[i].Name) < strings.ToLower(all[j].Name)
+	})
+	return all
+}
+
+// Get returns a profile by name (case-insensitive)
+func (m *Manager) Get(name string) (*models.ConnectionProfile, error) {
+	if i := indexOf(m.saved, name); i >= 0 {
+		p := m.saved[i]
+		return &p, nil
+	}
+	if i := indexOf(m.configured, name); i >= 0 {
+		p := m.configured[i]
+		return &p, nil
+	}
+	return nil, fmt.Errorf("connection profile '%s' was not found", name)
+}
+
+// Put adds a profile to connections.yaml, replacing the one named oldName
+// when it is set. Editing a config.yaml profile saves an override.
+func (m *Manager) Put(profile models.ConnectionProfile, oldName string) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	profile.Host = strings.TrimSpace(profile.Host)
+	if profile.Name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if profile.Host == "" {
+		return fmt.Errorf("profile host cannot be empty")
+	}
+	if profile.Port < 0 || profile.Port > 65535 {
+		return fmt.Errorf("invalid port %d", profile.Port)
+	}
+
+	renamed := oldName != "" && !strings.EqualFold(oldName, profile.Name)
+	if oldName == "" || renamed {
+		if _, err := m.Get(profile.Name); err == nil {
+			return fmt.Errorf("a profile with the name '%s' already exists (names are case-insensitive)", profile.Name)
+		}
+	}
+	if renamed && indexOf(m.configured, oldName) >= 0 {
+		return fmt.Errorf("profile '%s' is defined in %s; rename it there", oldName, SourceConfig)
+	}
+
+	profile.Source = SourceConnections
+	if i := indexOf(m.saved, oldName); oldName != "" && i >= 0 {
+		m.saved[i] = profile
+	} else {
+		m.saved = append(m.saved, profile)
+	}
+	return m.Save()
+}
+
+// Delete removes a profile from connections.yaml
+func (m *Manager) Delete(name string) error {
+	i := indexOf(m.saved, name)
+	if i < 0 {
+		if indexOf(m.configured, name) >= 0 {
+			return fmt.Errorf("profile '%s' is defined in %s; remove it there", name, SourceConfig)
+		}
+		return fmt.Errorf("connection profile '%s' was not found", name)
+	}
+	m.saved = append(m.saved[:i], m.saved[i+1:]...)
+	return m.Save()
+}
+
+// indexOf returns the index of the profile with the given name, or -1
+func indexOf(profiles []models.ConnectionProfile, name string) int {
+	for i, p := range profiles {
+		if strings.EqualFold(p.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RunPasswordCommand runs a profile's password command through the shell
+// and returns what it prints, without the trailing newline
+func RunPasswordCommand(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("password command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("password command failed: %w", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
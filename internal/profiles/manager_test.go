@@ -0,0 +1,107 @@
+package profiles
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func TestGetAllMergesSources(t *testing.T) {
+	dir := t.TempDir()
+	configured := []models.ConnectionProfile{
+		{Name: "prod", Host: "db.example.com", Port: 5432},
+		{Name: "staging", Host: "staging.example.com"},
+	}
+	m, err := NewManager(dir, configured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Put(models.ConnectionProfile{Name: "Prod", Host: "replica.example.com", ReadOnly: true}, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Put(models.ConnectionProfile{Name: "local", Host: "localhost"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new manager reads connections.yaml back
+	m, err = NewManager(dir, configured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := m.GetAll()
+	if len(all) != 3 {
+		t.Fatalf("expected 3 profiles, got %d", len(all))
+	}
+	want := []string{"local", "Prod", "staging"}
+	for i, name := range want {
+		if all[i].Name != name {
+			t.Errorf("profile %d: expected %s, got %s", i, name, all[i].Name)
+		}
+	}
+	if all[1].Host != "replica.example.com" || !all[1].ReadOnly || all[1].Source != SourceConnections {
+		t.Errorf("expected connections.yaml to override config.yaml, got %+v", all[1])
+	}
+	if all[2].Source != SourceConfig {
+		t.Errorf("expected staging to come from config.yaml, got %s", all[2].Source)
+	}
+}
+
+func TestPutValidates(t *testing.T) {
+	m, err := NewManager(t.TempDir(), []models.ConnectionProfile{{Name: "prod", Host: "db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		profile models.ConnectionProfile
+		oldName string
+	}{
+		{"missing name", models.ConnectionProfile{Host: "db"}, ""},
+		{"missing host", models.ConnectionProfile{Name: "dev"}, ""},
+		{"bad port", models.ConnectionProfile{Name: "dev", Host: "db", Port: 70000}, ""},
+		{"duplicate name", models.ConnectionProfile{Name: "PROD", Host: "db"}, ""},
+		{"rename config profile", models.ConnectionProfile{Name: "live", Host: "db"}, "prod"},
+	}
+	for _, tt := range tests {
+		if err := m.Put(tt.profile, tt.oldName); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m, err := NewManager(t.TempDir(), []models.ConnectionProfile{{Name: "prod", Host: "db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete("prod"); err == nil {
+		t.Errorf("expected deleting a config.yaml profile to fail")
+	}
+	if err := m.Put(models.ConnectionProfile{Name: "dev", Host: "localhost"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete("DEV"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get("dev"); err == nil {
+		t.Errorf("expected dev to be deleted")
+	}
+}
+
+func TestRunPasswordCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	got, err := RunPasswordCommand(context.Background(), "printf 'pa ss\\n'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "pa ss" {
+		t.Errorf("expected %q, got %q", "pa ss", got)
+	}
+	if _, err := RunPasswordCommand(context.Background(), "echo nope >&2; exit 3"); err == nil {
+		t.Errorf("expected a failing command to return an error")
+	}
+}
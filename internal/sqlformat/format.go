@@ -0,0 +1,443 @@
+// Package sqlformat pretty-prints SQL: keywords in upper case, one clause
+// per line, SELECT lists, JOINs, CTEs, CASE expressions and sub-queries
+// indented.
+package sqlformat
+
+import "strings"
+
+// Options control the layout of formatted SQL
+type Options struct {
+	TabSize   int  // Spaces per indentation level
+	UseSpaces bool // Indent with spaces rather than tabs
+}
+
+// indent returns the text of one indentation level
+func (o Options) indent() string {
+	if !o.UseSpaces {
+		return "\t"
+	}
+	if o.TabSize < 1 {
+		return "  "
+	}
+	return strings.Repeat(" ", o.TabSize)
+}
+
+// frameKind is the kind of a nested construct
+type frameKind int
+
+const (
+	frameParen    frameKind = iota // Parenthesized expression or list, kept on one line
+	frameSubquery                  // Parenthesized query, laid out on its own lines
+	frameCase                      // CASE expression
+)
+
+// frame is an open parenthesis or CASE expression
+type frame struct {
+	kind   frameKind
+	line   int    // Indentation of the line it opened on
+	base   int    // Enclosing query's base indentation, for sub-queries
+	clause string // Enclosing query's clause, for sub-queries
+	inline bool   // CASE inside a parenthesized expression stays on one line
+}
+
+// formatter lays out the tokens of a SQL text
+type formatter struct {
+	tokens []token
+	i      int
+
+	// Output
+	out    strings.Builder
+	indent string
+	line   int  // Indentation of the line being written
+	level  int  // Indentation of the next line
+	breaks int  // Line breaks to write before the next token
+	glue   bool // The next token follows without a space
+	prev   *token
+
+	// Layout state of the current statement
+	stack       []frame
+	base        int    // Indentation of the current query's clauses
+	clause      string // Current clause, e.g. "select" or "where"
+	selectList  bool   // The SELECT list is about to start
+	selectDepth int
+	between     bool // The next AND belongs to BETWEEN
+}
+
+// Format returns sql pretty-printed. String literals, quoted identifiers,
+// dollar-quoted bodies and comments are kept verbatim.
+func Format(sql string, opts Options) string {
+	f := &formatter{tokens: tokenize(sql), indent: opts.indent()}
+	for f.i = 0; f.i < len(f.tokens); f.i++ {
+		f.token(f.tokens[f.i])
+	}
+	out := f.out.String()
+	if out != "" && strings.HasSuffix(sql, "\n") {
+		out += "\n"
+	}
+	return out
+}
+
+// token lays out a single token
+func (f *formatter) token(t token) {
+	if t.kind == tokLineComment || t.kind == tokBlockComment {
+		f.comment(t)
+		return
+	}
+	upper := ""
+	if t.kind == tokWord {
+		upper = strings.ToUpper(t.text)
+	}
+	text := t.text
+	if keywords[upper] && !f.prevIs(".") {
+		text = upper
+	}
+
+	// The SELECT list goes on its own lines unless it is a single item
+	if f.selectList && len(f.stack) == f.selectDepth {
+		switch {
+		case upper == "DISTINCT" || upper == "ALL":
+		case upper == "ON" && f.prevIs("DISTINCT"):
+		case t.text == "(" && f.prevIs("ON"):
+		default:
+			f.selectList = false
+			if f.listHasComma() {
+				f.newline(f.base + 1)
+			}
+		}
+	}
+
+	queryLevel := f.queryLevel()
+	switch {
+	case t.text == ";":
+		f.emit(t, text, false)
+		f.stack = nil
+		f.base, f.clause, f.selectList, f.between = 0, "", false, false
+		f.breaks, f.level = 2, 0
+		return
+
+	case t.text == "(":
+		if f.startsQuery(f.i + 1) {
+			f.emit(t, text, f.space(t))
+			f.stack = append(f.stack, frame{kind: frameSubquery, line: f.line, base: f.base, clause: f.clause})
+			f.base, f.clause = f.line+1, ""
+			f.newline(f.base)
+		} else {
+			f.emit(t, text, f.space(t))
+			f.stack = append(f.stack, frame{kind: frameParen, line: f.line})
+		}
+		f.glue = true
+		return
+
+	case t.text == ")":
+		for len(f.stack) > 0 && f.stack[len(f.stack)-1].kind == frameCase {
+			f.stack = f.stack[:len(f.stack)-1]
+		}
+		if len(f.stack) > 0 {
+			top := f.stack[len(f.stack)-1]
+			f.stack = f.stack[:len(f.stack)-1]
+			if top.kind == frameSubquery {
+				f.base, f.clause, f.selectList = top.base, top.clause, false
+				f.newline(top.line)
+			}
+		}
+		f.emit(t, text, false)
+		return
+
+	case t.text == ",":
+		f.emit(t, text, false)
+		if queryLevel {
+			switch f.clause {
+			case "select":
+				f.newline(f.base + 1)
+			case "with":
+				f.newline(f.base)
+			}
+		}
+		return
+	}
+
+	// The AND of BETWEEN x AND y is not a condition of its own
+	betweenAnd := upper == "AND" && f.between
+	switch upper {
+	case "BETWEEN":
+		f.between = true
+	case "AND":
+		f.between = false
+	}
+
+	if queryLevel && t.kind == tokWord && !betweenAnd {
+		if f.layoutKeyword(t, upper, text) {
+			return
+		}
+	}
+
+	switch upper {
+	case "CASE":
+		f.emit(t, text, f.space(t))
+		n := len(f.stack)
+		block := queryLevel || f.stack[n-1].kind == frameCase && !f.stack[n-1].inline
+		f.stack = append(f.stack, frame{kind: frameCase, line: f.line, inline: !block})
+		return
+	case "WHEN", "ELSE", "END":
+		if n := len(f.stack); n > 0 && f.stack[n-1].kind == frameCase {
+			top := f.stack[n-1]
+			if upper == "END" {
+				f.stack = f.stack[:n-1]
+				if !top.inline {
+					f.newline(top.line)
+				}
+			} else if !top.inline {
+				f.newline(top.line + 1)
+			}
+		}
+	}
+
+	f.emit(t, text, f.space(t))
+	switch {
+	case t.text == "." || t.text == "::" || t.text == ":" || t.text == "[":
+		f.glue = true
+	case t.kind == tokOperator && f.unary():
+		f.glue = true
+	}
+}
+
+// layoutKeyword starts a new line for clauses, JOINs and conditions, and
+// reports whether it wrote the token
+func (f *formatter) layoutKeyword(t token, upper, text string) bool {
+	first := f.prev == nil || f.prevIs("(") || f.prevIs(")") || f.prevIs(";")
+	clause := ""
+	switch upper {
+	case "SELECT", "WHERE", "HAVING", "LIMIT", "OFFSET", "FETCH", "RETURNING", "WINDOW",
+		"UNION", "EXCEPT", "INTERSECT":
+		clause = strings.ToLower(upper)
+	case "FROM":
+		if !f.prevIs("DELETE") && !f.prevIs("DISTINCT") {
+			clause = "from"
+		}
+	case "GROUP", "ORDER":
+		if f.nextIs("BY") {
+			clause = strings.ToLower(upper)
+		}
+	case "WITH", "INSERT", "UPDATE", "DELETE", "CREATE":
+		if first {
+			clause = strings.ToLower(upper)
+		}
+	case "SET":
+		if f.clause == "update" {
+			clause = "set"
+		}
+	case "VALUES":
+		if f.clause == "insert" || first {
+			clause = "values"
+		}
+	case "RETURNS", "LANGUAGE":
+		if f.clause == "create" {
+			clause = "create"
+		}
+	case "AS":
+		if f.clause == "create" && f.i+1 < len(f.tokens) && strings.HasPrefix(f.tokens[f.i+1].text, "$") {
+			clause = "create"
+		}
+	case "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL", "JOIN":
+		if f.startsJoin(f.i) && !f.prevIs("NATURAL") && !f.prevIs("LEFT") && !f.prevIs("RIGHT") &&
+			!f.prevIs("FULL") && !f.prevIs("INNER") && !f.prevIs("CROSS") && !f.prevIs("OUTER") {
+			clause = "join"
+		}
+	case "ON":
+		if f.nextIs("CONFLICT") {
+			clause = "conflict"
+			break
+		}
+		if f.clause == "join" {
+			f.clause = "on"
+		}
+		return false
+	case "AND", "OR":
+		if f.clause == "where" || f.clause == "having" || f.clause == "on" {
+			f.newline(f.base + 1)
+			f.emit(t, text, true)
+			return true
+		}
+		return false
+	default:
+		return false
+	}
+	if clause == "" {
+		return false
+	}
+
+	f.newline(f.base)
+	f.emit(t, text, true)
+	f.clause = clause
+	if clause == "union" || clause == "except" || clause == "intersect" {
+		f.clause = ""
+	}
+	if upper == "SELECT" {
+		f.selectList = true
+		f.selectDepth = len(f.stack)
+	}
+	return true
+}
+
+// comment writes a comment. One that followed code on the same line stays
+// there; a line comment always ends its line.
+func (f *formatter) comment(t token) {
+	if f.breaks > 0 && !t.lineBreak && f.out.Len() > 0 {
+		f.out.WriteString(" " + t.text)
+		return
+	}
+	f.emit(t, t.text, true)
+	if t.kind == tokLineComment {
+		f.newline(f.line)
+	}
+}
+
+// emit writes a token after the pending line breaks or a space
+func (f *formatter) emit(t token, text string, space bool) {
+	switch {
+	case f.out.Len() == 0:
+		f.line = f.level
+		f.out.WriteString(strings.Repeat(f.indent, f.level))
+	case f.breaks > 0:
+		f.out.WriteString(strings.Repeat("\n", f.breaks))
+		f.line = f.level
+		f.out.WriteString(strings.Repeat(f.indent, f.level))
+	case space && !f.glue:
+		f.out.WriteByte(' ')
+	}
+	f.out.WriteString(text)
+	f.breaks, f.glue = 0, false
+	if t.kind != tokLineComment && t.kind != tokBlockComment {
+		tok := t
+		f.prev = &tok
+	}
+}
+
+// newline ends the current line; the next token starts at level
+func (f *formatter) newline(level int) {
+	if f.out.Len() > 0 && f.breaks == 0 {
+		f.breaks = 1
+	}
+	f.level = level
+}
+
+// space reports whether a token is separated from the previous one
+func (f *formatter) space(t token) bool {
+	if f.prev == nil {
+		return false
+	}
+	switch t.text {
+	case "::":
+		// An operator followed by :: would lex as one longer operator
+		return f.prev.kind == tokOperator
+	case ",", ";", ")", "]", ".", ":":
+		return false
+	case "(", "[":
+		// Keep function calls and array subscripts attached
+		p := f.prev
+		if !t.space && (p.kind == tokWord || p.kind == tokQuoted || p.text == ")" || p.text == "]") {
+			return false
+		}
+	}
+	return true
+}
+
+// unary reports whether the operator just written has no left operand
+func (f *formatter) unary() bool {
+	if f.i == 0 {
+		return true
+	}
+	for j := f.i - 1; j >= 0; j-- {
+		p := f.tokens[j]
+		switch p.kind {
+		case tokLineComment, tokBlockComment:
+			continue
+		case tokOperator:
+			return true
+		case tokPunct:
+			return p.text != ")" && p.text != "]"
+		case tokWord:
+			return keywords[strings.ToUpper(p.text)] && !valueKeywords[strings.ToUpper(p.text)]
+		}
+		return false
+	}
+	return true
+}
+
+// queryLevel reports whether the current token belongs to a query rather
+// than a parenthesized or CASE expression, so clauses go on their own lines
+func (f *formatter) queryLevel() bool {
+	return len(f.stack) == 0 || f.stack[len(f.stack)-1].kind == frameSubquery
+}
+
+// prevIs reports whether the previous token is the given keyword or
+// punctuation
+func (f *formatter) prevIs(text string) bool {
+	return f.prev != nil && (f.prev.kind == tokWord && strings.EqualFold(f.prev.text, text) || f.prev.text == text)
+}
+
+// next returns the index of the next token that is not a comment from i
+func (f *formatter) next(i int) int {
+	for i < len(f.tokens) && (f.tokens[i].kind == tokLineComment || f.tokens[i].kind == tokBlockComment) {
+		i++
+	}
+	return i
+}
+
+// nextIs reports whether the token after the current one is the keyword
+func (f *formatter) nextIs(word string) bool {
+	j := f.next(f.i + 1)
+	return j < len(f.tokens) && f.tokens[j].kind == tokWord && strings.EqualFold(f.tokens[j].text, word)
+}
+
+// startsQuery reports whether a query starts at token i
+func (f *formatter) startsQuery(i int) bool {
+	j := f.next(i)
+	if j >= len(f.tokens) || f.tokens[j].kind != tokWord {
+		return false
+	}
+	switch strings.ToUpper(f.tokens[j].text) {
+	case "SELECT", "WITH", "VALUES", "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// startsJoin reports whether the words from token i form a JOIN
+func (f *formatter) startsJoin(i int) bool {
+	for j := f.next(i); j < len(f.tokens) && f.tokens[j].kind == tokWord; j = f.next(j + 1) {
+		switch strings.ToUpper(f.tokens[j].text) {
+		case "JOIN":
+			return true
+		case "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL", "OUTER":
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// listHasComma reports whether the SELECT list starting at the current
+// token has more than one item
+func (f *formatter) listHasComma() bool {
+	depth := 0
+	for j := f.i; j < len(f.tokens); j++ {
+		t := f.tokens[j]
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case t.text == ";":
+			return false
+		case depth == 0 && t.text == ",":
+			return true
+		case depth == 0 && t.kind == tokWord && listEnd[strings.ToUpper(t.text)]:
+			return false
+		}
+	}
+	return false
+}
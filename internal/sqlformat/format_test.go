@@ -0,0 +1,97 @@
+package sqlformat
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	opts := Options{TabSize: 2, UseSpaces: true}
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			"select list and joins",
+			"select u.id, count(*) as n from users u left join orders o on o.user_id = u.id and o.total > -5 where u.active or u.id between 1 and 10 group by u.id order by n desc limit 10",
+			"SELECT\n  u.id,\n  count(*) AS n\nFROM users u\nLEFT JOIN orders o ON o.user_id = u.id\n  AND o.total > -5\nWHERE u.active\n  OR u.id BETWEEN 1 AND 10\nGROUP BY u.id\nORDER BY n DESC\nLIMIT 10",
+		},
+		{
+			"single item",
+			"SELECT * FROM t WHERE a=1",
+			"SELECT *\nFROM t\nWHERE a = 1",
+		},
+		{
+			"cte and subquery",
+			"with a as (select id from t), b as (select 1) select * from a where id in (select id from b)",
+			"WITH a AS (\n  SELECT id\n  FROM t\n),\nb AS (\n  SELECT 1\n)\nSELECT *\nFROM a\nWHERE id IN (\n  SELECT id\n  FROM b\n)",
+		},
+		{
+			"case",
+			"select case when a then 1 else 2 end as x, coalesce(case when b then 1 end, 0) from t",
+			"SELECT\n  CASE\n    WHEN a THEN 1\n    ELSE 2\n  END AS x,\n  coalesce(CASE WHEN b THEN 1 END, 0)\nFROM t",
+		},
+		{
+			"statements and comments",
+			"select 1; -- one\n/* two */ update t set a=1 , b=x::int[] where id=$1 returning *;",
+			"SELECT 1; -- one\n\n/* two */\nUPDATE t\nSET a = 1, b = x::int[]\nWHERE id = $1\nRETURNING *;",
+		},
+		{
+			"literals kept",
+			"insert into t (a) values ('select  from', E'it\\'s', $$ x  y $$) on conflict (a) do nothing",
+			"INSERT INTO t (a)\nVALUES ('select  from', E'it\\'s', $$ x  y $$)\nON CONFLICT (a) DO NOTHING",
+		},
+		{
+			"prefixed literals kept",
+			"select U&'\\0041', u&\"d\\0061t\", B'0101', x'1F', N'abc' from t",
+			"SELECT\n  U&'\\0041',\n  u&\"d\\0061t\",\n  B'0101',\n  x'1F',\n  N'abc'\nFROM t",
+		},
+		{
+			"operator before cast",
+			"select * from t where x = ::int",
+			"SELECT *\nFROM t\nWHERE x = ::int",
+		},
+		{
+			"key and escape",
+			"create table t (id int primary key); select * from t where a like 'x!%' escape '!'",
+			"CREATE TABLE t (id int PRIMARY KEY);\n\nSELECT *\nFROM t\nWHERE a LIKE 'x!%' ESCAPE '!'",
+		},
+		{
+			"placeholders kept",
+			"select * from t where id = :user_id and kind = $1 and a[1:n] = :v::int",
+			"SELECT *\nFROM t\nWHERE id = :user_id\n  AND kind = $1\n  AND a[1:n] = :v::int",
+		},
+		{
+			"function body kept",
+			"CREATE FUNCTION f() RETURNS int LANGUAGE sql AS $f$\n  select   1\n$f$;\n",
+			"CREATE FUNCTION f()\nRETURNS int\nLANGUAGE sql\nAS $f$\n  select   1\n$f$;\n",
+		},
+	}
+	for _, tt := range tests {
+		got := Format(tt.sql, opts)
+		if got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+			continue
+		}
+		if again := Format(got, opts); again != got {
+			t.Errorf("%s: formatting again changed the result:\n%s", tt.name, again)
+		}
+	}
+}
+
+func TestFormatIndent(t *testing.T) {
+	sql := "select a, b from t"
+	if got, want := Format(sql, Options{UseSpaces: false}), "SELECT\n\ta,\n\tb\nFROM t"; got != want {
+		t.Errorf("tabs: got %q, want %q", got, want)
+	}
+	if got, want := Format(sql, Options{TabSize: 4, UseSpaces: true}), "SELECT\n    a,\n    b\nFROM t"; got != want {
+		t.Errorf("spaces: got %q, want %q", got, want)
+	}
+}
+
+func TestFormatKeepsPlaceholders(t *testing.T) {
+	opts := Options{TabSize: 2, UseSpaces: true}
+	for _, sql := range []string{"SELECT *\nFROM t\nWHERE id = :user_id", "SELECT *\nFROM t\nWHERE id = $1"} {
+		if got := Format(sql, opts); got != sql {
+			t.Errorf("expected %q unchanged, got %q", sql, got)
+		}
+	}
+}
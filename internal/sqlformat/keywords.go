@@ -0,0 +1,38 @@
+package sqlformat
+
+// keywords are written in upper case
+var keywords = map[string]bool{
+	"ALL": true, "ALTER": true, "ANALYZE": true, "AND": true, "ANY": true, "ARRAY": true,
+	"AS": true, "ASC": true, "BEGIN": true, "BETWEEN": true, "BY": true, "CASCADE": true,
+	"CASE": true, "CAST": true, "CHECK": true, "COLUMN": true, "COMMIT": true,
+	"CONFLICT": true, "CONSTRAINT": true, "CREATE": true, "CROSS": true, "DEFAULT": true,
+	"DELETE": true, "DESC": true, "DISTINCT": true, "DO": true, "DROP": true, "ELSE": true,
+	"END": true, "ESCAPE": true, "EXCEPT": true, "EXISTS": true, "EXPLAIN": true, "FALSE": true,
+	"FETCH": true, "FILTER": true, "FOR": true, "FOREIGN": true, "FROM": true, "FULL": true,
+	"FUNCTION": true, "GRANT": true, "GROUP": true, "HAVING": true, "IF": true,
+	"ILIKE": true, "IN": true, "INDEX": true, "INNER": true, "INSERT": true,
+	"INTERSECT": true, "INTO": true, "IS": true, "JOIN": true, "KEY": true, "LANGUAGE": true,
+	"LATERAL": true, "LEFT": true, "LIKE": true, "LIMIT": true, "MATERIALIZED": true,
+	"NATURAL": true, "NOT": true, "NOTHING": true, "NULL": true, "NULLS": true,
+	"OFFSET": true, "ON": true, "ONLY": true, "OR": true, "ORDER": true, "OUTER": true,
+	"OVER": true, "PARTITION": true, "PRIMARY": true, "PROCEDURE": true,
+	"RECURSIVE": true, "REFERENCES": true, "REPLACE": true, "RETURNING": true,
+	"RETURNS": true, "REVOKE": true, "RIGHT": true, "ROLLBACK": true, "SCHEMA": true,
+	"SELECT": true, "SEQUENCE": true, "SET": true, "SIMILAR": true, "SOME": true,
+	"TABLE": true, "THEN": true, "TO": true, "TRIGGER": true, "TRUE": true,
+	"TRUNCATE": true, "UNION": true, "UNIQUE": true, "UPDATE": true, "USING": true,
+	"VALUES": true, "VIEW": true, "WHEN": true, "WHERE": true, "WINDOW": true, "WITH": true,
+}
+
+// valueKeywords are keywords that are values, so an operator after them is
+// binary
+var valueKeywords = map[string]bool{
+	"NULL": true, "TRUE": true, "FALSE": true, "END": true,
+}
+
+// listEnd are the keywords that end a SELECT list
+var listEnd = map[string]bool{
+	"FROM": true, "INTO": true, "WHERE": true, "GROUP": true, "HAVING": true,
+	"ORDER": true, "LIMIT": true, "OFFSET": true, "UNION": true, "EXCEPT": true,
+	"INTERSECT": true, "WINDOW": true, "FETCH": true, "RETURNING": true, "FOR": true,
+}
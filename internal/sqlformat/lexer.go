@@ -0,0 +1,219 @@
+package sqlformat
+
+import "strings"
+
+// tokenKind is the lexical class of a token
+type tokenKind int
+
+const (
+	tokWord         tokenKind = iota // Unquoted identifier or keyword
+	tokQuoted                        // "Quoted identifier"
+	tokString                        // String literal or dollar-quoted body
+	tokNumber                        // Numeric literal
+	tokParam                         // $1 or :name placeholder
+	tokLineComment                   // -- comment
+	tokBlockComment                  // /* comment */
+	tokOperator                      // Operator such as = or ||
+	tokPunct                         // ( ) [ ] , ; . or ::
+)
+
+// token is a lexical token with its original text
+type token struct {
+	kind      tokenKind
+	text      string
+	space     bool // Preceded by whitespace in the input
+	lineBreak bool // Preceded by a line break in the input
+}
+
+// operatorChars are the characters PostgreSQL operators are made of
+const operatorChars = "+-*/<>=~!@#%^&|`?"
+
+// tokenize splits SQL into tokens, keeping literals and comments verbatim
+func tokenize(sql string) []token {
+	var tokens []token
+	space, lineBreak := false, false
+	i := 0
+	for i < len(sql) {
+		ch := sql[i]
+		start := i
+		kind := tokPunct
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v':
+			space = true
+			lineBreak = lineBreak || ch == '\n'
+			i++
+			continue
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			kind = tokLineComment
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			i = skipBlockComment(sql, i)
+			kind = tokBlockComment
+		case ch == '\'':
+			i = skipQuoted(sql, i, '\'')
+			kind = tokString
+		case (ch == 'E' || ch == 'e') && i+1 < len(sql) && sql[i+1] == '\'' && (i == 0 || !isWordByte(sql[i-1])):
+			i = skipEscapeString(sql, i+1)
+			kind = tokString
+		case strings.IndexByte("BbXxNn", ch) >= 0 && i+1 < len(sql) && sql[i+1] == '\'' && (i == 0 || !isWordByte(sql[i-1])):
+			// Bit string, hex string or national character literal
+			i = skipQuoted(sql, i+1, '\'')
+			kind = tokString
+		case (ch == 'U' || ch == 'u') && i+2 < len(sql) && sql[i+1] == '&' && (sql[i+2] == '\'' || sql[i+2] == '"') && (i == 0 || !isWordByte(sql[i-1])):
+			// Unicode escape string or identifier
+			i = skipQuoted(sql, i+2, sql[i+2])
+			kind = tokString
+			if sql[start+2] == '"' {
+				kind = tokQuoted
+			}
+		case ch == '"':
+			i = skipQuoted(sql, i, '"')
+			kind = tokQuoted
+		case ch == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			for i++; i < len(sql) && sql[i] >= '0' && sql[i] <= '9'; i++ {
+			}
+			kind = tokParam
+		case ch == '$' && dollarTag(sql, i) != "":
+			tag := dollarTag(sql, i)
+			if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(sql)
+			}
+			kind = tokString
+		case isWordStart(ch):
+			for i < len(sql) && isWordByte(sql[i]) {
+				i++
+			}
+			kind = tokWord
+		case ch >= '0' && ch <= '9' || ch == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			i = skipNumber(sql, i)
+			kind = tokNumber
+		case ch == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			i += 2
+		case ch == ':' && i+1 < len(sql) && isWordStart(sql[i+1]) && (i == 0 || !isWordByte(sql[i-1]) && sql[i-1] != '[' && sql[i-1] != ':'):
+			// Named bind variable, found like the query editor finds them
+			for i++; i < len(sql) && isWordByte(sql[i]) && sql[i] != '$'; i++ {
+			}
+			kind = tokParam
+		case strings.IndexByte(operatorChars, ch) >= 0:
+			for i < len(sql) && strings.IndexByte(operatorChars, sql[i]) >= 0 &&
+				!(sql[i] == '-' && i+1 < len(sql) && sql[i+1] == '-') &&
+				!(sql[i] == '/' && i+1 < len(sql) && sql[i+1] == '*') {
+				i++
+			}
+			// Like PostgreSQL, a trailing + or - is a separate operator
+			// unless the operator has one of the rarer characters
+			for i-start > 1 && (sql[i-1] == '+' || sql[i-1] == '-') &&
+				!strings.ContainsAny(sql[start:i], "~!@#%^&|`?") {
+				i--
+			}
+			kind = tokOperator
+		default:
+			i++
+		}
+		tokens = append(tokens, token{kind: kind, text: sql[start:i], space: space, lineBreak: lineBreak})
+		space, lineBreak = false, false
+	}
+	return tokens
+}
+
+// skipBlockComment returns the offset just past the possibly nested block
+// comment starting at i
+func skipBlockComment(s string, i int) int {
+	depth := 0
+	for i < len(s) {
+		switch {
+		case strings.HasPrefix(s[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(s[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(s)
+}
+
+// skipQuoted returns the offset just past the literal or quoted identifier
+// starting at i
+func skipQuoted(s string, i int, quote byte) int {
+	for i++; i < len(s); i++ {
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// skipEscapeString returns the offset just past the escape string whose quote
+// is at i
+func skipEscapeString(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == '\'':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// skipNumber returns the offset just past the numeric literal at i
+func skipNumber(s string, i int) int {
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == '_') {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			for i = j; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			}
+		}
+	}
+	return i
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string at i, or ""
+func dollarTag(s string, i int) string {
+	if i > 0 && isWordByte(s[i-1]) {
+		return ""
+	}
+	j := i + 1
+	if j < len(s) && isWordStart(s[j]) {
+		for j < len(s) && s[j] != '$' && isWordByte(s[j]) {
+			j++
+		}
+	}
+	if j < len(s) && s[j] == '$' {
+		return s[i : j+1]
+	}
+	return ""
+}
+
+// isWordStart reports whether c can start an unquoted identifier
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// isWordByte reports whether c can be part of an unquoted identifier
+func isWordByte(c byte) bool {
+	return isWordStart(c) || c >= '0' && c <= '9' || c == '$'
+}
@@ -0,0 +1,548 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/db/metadata"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// ZoneActivityItemPrefix is the zone ID prefix for sessions in the activity list
+const ZoneActivityItemPrefix = "activity-item-"
+
+// LoadActivityMsg requests the sessions of the server. Seq identifies the
+// refresh cycle that asked, so that stale timers can be ignored.
+type LoadActivityMsg struct {
+	Seq int
+}
+
+// SignalBackendRequestMsg asks to cancel the query of a session, or to
+// terminate it with Terminate set
+type SignalBackendRequestMsg struct {
+	Session   metadata.Session
+	Terminate bool
+}
+
+// OpenActivityQueryMsg is sent to open a session's query in the SQL editor
+type OpenActivityQueryMsg struct {
+	Query string
+}
+
+// CloseActivityDialogMsg is sent when the activity dialog is closed
+type CloseActivityDialogMsg struct{}
+
+// activityIntervals are the refresh intervals the dialog cycles through;
+// zero pauses refreshing
+var activityIntervals = []time.Duration{2 * time.Second, 5 * time.Second, 10 * time.Second, 0}
+
+// activityRow is a session in the blocking tree
+type activityRow struct {
+	session metadata.Session
+	depth   int
+	blocks  int // Sessions waiting for it, directly or indirectly
+}
+
+// ActivityDialog monitors the sessions of the server and the locks they
+// wait for, shown as a tree of who blocks whom
+type ActivityDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	sessions []metadata.Session
+	rows     []activityRow
+	loading  bool
+	loadedAt time.Time
+	err      error
+	notice   string
+
+	selected int
+	scroll   int
+	hideIdle bool
+	interval int // Index into activityIntervals
+	seq      int
+}
+
+// NewActivityDialog creates a new activity dialog
+func NewActivityDialog(th theme.Theme) *ActivityDialog {
+	return &ActivityDialog{
+		Width:  120,
+		Height: 30,
+		Theme:  th,
+	}
+}
+
+// Reset clears the dialog and requests the sessions
+func (d *ActivityDialog) Reset() tea.Cmd {
+	d.sessions = nil
+	d.rows = nil
+	d.err = nil
+	d.notice = ""
+	d.selected = 0
+	d.scroll = 0
+	return d.Refresh()
+}
+
+// Refresh requests the sessions now and restarts the refresh cycle
+func (d *ActivityDialog) Refresh() tea.Cmd {
+	d.seq++
+	d.loading = true
+	msg := LoadActivityMsg{Seq: d.seq}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// IsCurrent reports whether a load request belongs to the current refresh
+// cycle
+func (d *ActivityDialog) IsCurrent(msg LoadActivityMsg) bool {
+	return msg.Seq == d.seq
+}
+
+// SetSessions shows loaded sessions, keeping the selected one, and
+// schedules the next refresh
+func (d *ActivityDialog) SetSessions(sessions []metadata.Session, err error) tea.Cmd {
+	d.loading = false
+	d.err = err
+	if err == nil {
+		selected, hadSelection := d.current()
+		d.sessions = sessions
+		d.loadedAt = time.Now()
+		d.rebuild()
+		if hadSelection {
+			d.selectPID(selected.session.PID)
+		}
+	}
+
+	interval := activityIntervals[d.interval]
+	if interval == 0 {
+		return nil
+	}
+	seq := d.seq
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return LoadActivityMsg{Seq: seq}
+	})
+}
+
+// SetNotice shows the outcome of an action below the list
+func (d *ActivityDialog) SetNotice(notice string) {
+	d.notice = notice
+}
+
+// rebuild orders the sessions as the blocking tree
+func (d *ActivityDialog) rebuild() {
+	d.rows = blockingTree(d.sessions, d.hideIdle)
+	d.selected = max(0, min(d.selected, len(d.rows)-1))
+}
+
+// selectPID selects the row of a session, if it is still listed
+func (d *ActivityDialog) selectPID(pid int) {
+	for i, row := range d.rows {
+		if row.session.PID == pid {
+			d.selected = i
+			return
+		}
+	}
+}
+
+// current returns the selected row
+func (d *ActivityDialog) current() (activityRow, bool) {
+	if d.selected < 0 || d.selected >= len(d.rows) {
+		return activityRow{}, false
+	}
+	return d.rows[d.selected], true
+}
+
+// blockingTree orders sessions as a tree of who blocks whom. A blocked
+// session is listed under the first session blocking it, one level deeper.
+// Sessions that block others come first, then active ones, then the longest
+// running. Idle sessions are left out with hideIdle unless they block others.
+func blockingTree(sessions []metadata.Session, hideIdle bool) []activityRow {
+	byPID := make(map[int]metadata.Session, len(sessions))
+	for _, s := range sessions {
+		byPID[s.PID] = s
+	}
+
+	children := make(map[int][]int)
+	hasParent := make(map[int]bool)
+	for _, s := range sessions {
+		for _, blocker := range s.BlockedBy {
+			if _, ok := byPID[blocker]; ok && blocker != s.PID {
+				children[blocker] = append(children[blocker], s.PID)
+				hasParent[s.PID] = true
+				break
+			}
+		}
+	}
+
+	// Count the sessions waiting on each one
+	blocks := make(map[int]int)
+	var count func(pid int, seen map[int]bool) int
+	count = func(pid int, seen map[int]bool) int {
+		n := 0
+		for _, child := range children[pid] {
+			if !seen[child] {
+				seen[child] = true
+				n += 1 + count(child, seen)
+			}
+		}
+		return n
+	}
+	for _, s := range sessions {
+		blocks[s.PID] = count(s.PID, map[int]bool{s.PID: true})
+	}
+
+	order := func(pids []int) {
+		sort.SliceStable(pids, func(i, j int) bool {
+			a, b := byPID[pids[i]], byPID[pids[j]]
+			if blocks[a.PID] != blocks[b.PID] {
+				return blocks[a.PID] > blocks[b.PID]
+			}
+			if (a.State == "active") != (b.State == "active") {
+				return a.State == "active"
+			}
+			return a.Duration > b.Duration
+		})
+	}
+
+	var rows []activityRow
+	visited := make(map[int]bool)
+	var walk func(pid, depth int)
+	walk = func(pid, depth int) {
+		if visited[pid] {
+			return
+		}
+		visited[pid] = true
+		s := byPID[pid]
+		if hideIdle && s.State == "idle" && blocks[pid] == 0 {
+			return
+		}
+		rows = append(rows, activityRow{session: s, depth: depth, blocks: blocks[pid]})
+		kids := append([]int(nil), children[pid]...)
+		order(kids)
+		for _, child := range kids {
+			walk(child, depth+1)
+		}
+	}
+
+	var roots []int
+	for _, s := range sessions {
+		if !hasParent[s.PID] {
+			roots = append(roots, s.PID)
+		}
+	}
+	order(roots)
+	for _, pid := range roots {
+		walk(pid, 0)
+	}
+	// Sessions blocking each other in a cycle have no root
+	for _, s := range sessions {
+		walk(s.PID, 0)
+	}
+	return rows
+}
+
+// Update handles messages
+func (d *ActivityDialog) Update(msg tea.Msg) (*ActivityDialog, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		return d, func() tea.Msg {
+			return CloseActivityDialogMsg{}
+		}
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < len(d.rows)-1 {
+			d.selected++
+		}
+	case "g", "home":
+		d.selected = 0
+	case "G", "end":
+		d.selected = max(0, len(d.rows)-1)
+	case "r":
+		return d, d.Refresh()
+	case "a":
+		selected, ok := d.current()
+		d.hideIdle = !d.hideIdle
+		d.rebuild()
+		if ok {
+			d.selectPID(selected.session.PID)
+		}
+	case "i":
+		d.interval = (d.interval + 1) % len(activityIntervals)
+		return d, d.Refresh()
+	case "enter", "e":
+		if row, ok := d.current(); ok && strings.TrimSpace(row.session.Query) != "" {
+			query := row.session.Query
+			return d, func() tea.Msg {
+				return OpenActivityQueryMsg{Query: query}
+			}
+		}
+	case "c", "t":
+		if row, ok := d.current(); ok {
+			request := SignalBackendRequestMsg{Session: row.session, Terminate: keyMsg.String() == "t"}
+			return d, func() tea.Msg {
+				return request
+			}
+		}
+	}
+	return d, nil
+}
+
+// HandleMouseWheel scrolls the session list
+func (d *ActivityDialog) HandleMouseWheel(msg tea.MouseMsg) bool {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		d.selected = max(0, d.selected-3)
+		return true
+	case tea.MouseButtonWheelDown:
+		d.selected = max(0, min(len(d.rows)-1, d.selected+3))
+		return true
+	}
+	return false
+}
+
+// HandleMouseClick selects a session
+func (d *ActivityDialog) HandleMouseClick(msg tea.MouseMsg) (bool, tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+	for i := range d.rows {
+		if zone.Get(ZoneActivityItemPrefix + strconv.Itoa(i)).InBounds(msg) {
+			d.selected = i
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// View renders the dialog
+func (d *ActivityDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(d.Theme.Info).Padding(0, 1)
+	footerStyle := lipgloss.NewStyle().Faint(true).Foreground(d.Theme.Foreground).Padding(0, 1)
+	descStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground).Faint(true)
+	headerStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(d.Theme.Error)
+	noticeStyle := lipgloss.NewStyle().Foreground(d.Theme.Warning)
+
+	width := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Server Activity"))
+	content.WriteString("  ")
+	content.WriteString(descStyle.Render(d.summary()))
+	content.WriteString("\n\n")
+
+	// Session list
+	const detailLines = 8
+	visible := max(3, d.Height-detailLines-11)
+	if d.selected < d.scroll {
+		d.scroll = d.selected
+	}
+	if d.selected >= d.scroll+visible {
+		d.scroll = d.selected - visible + 1
+	}
+
+	switch {
+	case d.err != nil && len(d.rows) == 0:
+		content.WriteString(errorStyle.Render(d.err.Error()))
+		content.WriteString("\n")
+	case len(d.rows) == 0 && d.loading:
+		content.WriteString(descStyle.Render("Loading..."))
+		content.WriteString("\n")
+	case len(d.rows) == 0:
+		content.WriteString(descStyle.Render("No other sessions."))
+		content.WriteString("\n")
+	default:
+		content.WriteString(headerStyle.Render(d.formatRow("", "PID", "User", "Database", "State", "Wait", "Duration", "Query", width)))
+		content.WriteString("\n")
+		end := min(len(d.rows), d.scroll+visible)
+		for i := d.scroll; i < end; i++ {
+			line := d.renderRow(d.rows[i], i == d.selected, width)
+			content.WriteString(zone.Mark(ZoneActivityItemPrefix+strconv.Itoa(i), line))
+			content.WriteString("\n")
+		}
+	}
+	content.WriteString("\n")
+
+	if row, ok := d.current(); ok {
+		content.WriteString(d.renderDetails(row, width, detailLines))
+	}
+
+	if d.err != nil && len(d.rows) > 0 {
+		content.WriteString(errorStyle.Render(runewidth.Truncate(d.err.Error(), width, "…")))
+		content.WriteString("\n")
+	}
+	if d.notice != "" {
+		content.WriteString(noticeStyle.Render(runewidth.Truncate(d.notice, width, "…")))
+		content.WriteString("\n")
+	}
+
+	footer := "Enter: Open query in editor  │  c: Cancel query  │  t: Terminate  │  a: Hide idle  │  i: Interval  │  r: Refresh  │  Esc: Close"
+	content.WriteString(footerStyle.Render(runewidth.Truncate(footer, width, "…")))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
+
+// summary describes the sessions and the refresh state
+func (d *ActivityDialog) summary() string {
+	active, waiting := 0, 0
+	for _, s := range d.sessions {
+		if s.State == "active" {
+			active++
+		}
+		if len(s.BlockedBy) > 0 {
+			waiting++
+		}
+	}
+	parts := []string{
+		fmt.Sprintf("%d sessions", len(d.sessions)),
+		fmt.Sprintf("%d active", active),
+		fmt.Sprintf("%d waiting on locks", waiting),
+	}
+	if d.hideIdle {
+		parts = append(parts, "idle hidden")
+	}
+	if interval := activityIntervals[d.interval]; interval > 0 {
+		parts = append(parts, "refresh every "+interval.String())
+	} else if !d.loadedAt.IsZero() {
+		parts = append(parts, "paused at "+d.loadedAt.Format("15:04:05"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatRow lays out the columns of the session list
+func (d *ActivityDialog) formatRow(tree, pid, user, database, state, wait, duration, query string, width int) string {
+	cell := func(s string, w int) string {
+		return runewidth.FillRight(runewidth.Truncate(s, w, "…"), w)
+	}
+	prefix := cell(tree+pid, 12) + " " + cell(user, 12) + " " + cell(database, 12) + " " +
+		cell(state, 19) + " " + cell(wait, 18) + " " + runewidth.FillLeft(runewidth.Truncate(duration, 8, "…"), 8) + "  "
+	queryWidth := max(10, width-4-runewidth.StringWidth(prefix))
+	return prefix + runewidth.Truncate(query, queryWidth, "…")
+}
+
+// renderRow renders one session of the list
+func (d *ActivityDialog) renderRow(row activityRow, selected bool, width int) string {
+	s := row.session
+	tree := ""
+	if row.depth > 0 {
+		tree = strings.Repeat("  ", row.depth-1) + "└ "
+	}
+	wait := s.WaitEventType
+	if s.WaitEvent != "" {
+		wait += ":" + s.WaitEvent
+	}
+	query := strings.Join(strings.Fields(s.Query), " ")
+	line := d.formatRow(tree, strconv.Itoa(s.PID), s.User, s.Database, s.State, wait, formatHistoryDuration(s.Duration), query, width)
+
+	if selected {
+		return "▸ " + lipgloss.NewStyle().Foreground(d.Theme.BorderFocused).Bold(true).Render(line)
+	}
+	style := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	switch {
+	case len(s.BlockedBy) > 0:
+		style = style.Foreground(d.Theme.Error)
+	case row.blocks > 0 || strings.HasPrefix(s.State, "idle in transaction"):
+		style = style.Foreground(d.Theme.Warning)
+	case s.State == "active":
+		style = style.Foreground(d.Theme.Success)
+	case s.State == "idle":
+		style = style.Faint(true)
+	}
+	return "  " + style.Render(line)
+}
+
+// renderDetails renders the selected session's connection, locks and full
+// query
+func (d *ActivityDialog) renderDetails(row activityRow, width, lines int) string {
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Info)
+	metaStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	queryStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	lockStyle := lipgloss.NewStyle().Foreground(d.Theme.Error)
+
+	s := row.session
+	var b strings.Builder
+	used := 0
+	line := func(style lipgloss.Style, label, text string) {
+		b.WriteString(labelStyle.Render(label))
+		b.WriteString(style.Render(runewidth.Truncate(text, max(10, width-runewidth.StringWidth(label)), "…")))
+		b.WriteString("\n")
+		used++
+	}
+
+	connection := []string{fmt.Sprintf("pid %d", s.PID), valueOr(s.User, "-") + "@" + valueOr(s.Database, "-")}
+	if s.Application != "" {
+		connection = append(connection, s.Application)
+	}
+	if s.Client != "" {
+		connection = append(connection, s.Client)
+	}
+	line(metaStyle, "Session: ", strings.Join(connection, " · "))
+
+	state := fmt.Sprintf("%s for %s", valueOr(s.State, "unknown"), formatHistoryDuration(s.Duration))
+	if s.XactDuration > 0 {
+		state += fmt.Sprintf(" · transaction open %s", formatHistoryDuration(s.XactDuration))
+	}
+	if s.WaitEventType != "" {
+		state += fmt.Sprintf(" · waiting on %s:%s", s.WaitEventType, s.WaitEvent)
+	}
+	state += fmt.Sprintf(" · %d lock(s) held", s.LocksHeld)
+	line(metaStyle, "State: ", state)
+
+	if len(s.BlockedBy) > 0 {
+		pids := make([]string, len(s.BlockedBy))
+		for i, pid := range s.BlockedBy {
+			pids[i] = strconv.Itoa(pid)
+		}
+		text := "pid " + strings.Join(pids, ", ")
+		if s.WaitingFor != "" {
+			text += " · waiting for " + s.WaitingFor
+		}
+		line(lockStyle, "Blocked by: ", text)
+	}
+	if row.blocks > 0 {
+		line(lockStyle, "Blocking: ", fmt.Sprintf("%d session(s)", row.blocks))
+	}
+
+	queryLines := strings.Split(strings.TrimSpace(s.Query), "\n")
+	for i, text := range queryLines {
+		if used >= lines-1 {
+			break
+		}
+		if used == lines-2 && i < len(queryLines)-1 {
+			text = "…"
+		}
+		b.WriteString(queryStyle.Render("  " + runewidth.Truncate(strings.ReplaceAll(text, "\t", "  "), width-2, "…")))
+		b.WriteString("\n")
+		used++
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rebelice/lazypg/internal/db/metadata"
+)
+
+func TestBlockingTree(t *testing.T) {
+	sessions := []metadata.Session{
+		{PID: 1, State: "idle", Duration: time.Hour},
+		{PID: 2, State: "active", Duration: time.Second},
+		{PID: 3, State: "idle in transaction", Duration: time.Minute},
+		{PID: 4, State: "active", Duration: 5 * time.Second, BlockedBy: []int{3}},
+		{PID: 5, State: "active", Duration: 2 * time.Second, BlockedBy: []int{4, 3}},
+		{PID: 6, State: "active", Duration: time.Second, BlockedBy: []int{99}},
+		// A deadlock before the server resolves it
+		{PID: 7, State: "active", BlockedBy: []int{8}},
+		{PID: 8, State: "active", BlockedBy: []int{7}},
+	}
+
+	type want struct{ pid, depth, blocks int }
+	check := func(rows []activityRow, expected []want) {
+		t.Helper()
+		if len(rows) != len(expected) {
+			t.Fatalf("expected %d rows, got %d: %+v", len(expected), len(rows), rows)
+		}
+		for i, w := range expected {
+			got := want{rows[i].session.PID, rows[i].depth, rows[i].blocks}
+			if got != w {
+				t.Errorf("row %d: expected %+v, got %+v", i, w, got)
+			}
+		}
+	}
+
+	check(blockingTree(sessions, false), []want{
+		{3, 0, 2},
+		{4, 1, 1},
+		{5, 2, 0},
+		{2, 0, 0},
+		{6, 0, 0},
+		{1, 0, 0},
+		{7, 0, 1},
+		{8, 1, 1},
+	})
+
+	// Hiding idle sessions keeps the idle-in-transaction blocker
+	check(blockingTree(sessions, true), []want{
+		{3, 0, 2},
+		{4, 1, 1},
+		{5, 2, 0},
+		{2, 0, 0},
+		{6, 0, 0},
+		{7, 0, 1},
+		{8, 1, 1},
+	})
+}
@@ -0,0 +1,245 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// BindValuesMsg is sent when the values of a statement's bind variables
+// have been entered. Action is the execution to run with them.
+type BindValuesMsg struct {
+	Values models.BindValues
+	Action tea.Msg
+}
+
+// CloseBindDialogMsg is sent when the bind dialog is cancelled
+type CloseBindDialogMsg struct{}
+
+// BindDialog prompts for the values of a statement's bind variables
+type BindDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	sql    string
+	names  []string
+	inputs []textinput.Model
+	isNull []bool
+	focus  int
+	offset int // First visible field
+	action tea.Msg
+}
+
+// NewBindDialog creates a new bind dialog
+func NewBindDialog(th theme.Theme) *BindDialog {
+	return &BindDialog{
+		Theme:  th,
+		Width:  80,
+		Height: 24,
+	}
+}
+
+// SetStatement prepares the form for the variables of a statement,
+// pre-filled with earlier values, and the action to run once they are entered
+func (d *BindDialog) SetStatement(sql string, names []string, values models.BindValues, action tea.Msg) {
+	d.sql = sql
+	d.names = names
+	d.inputs = make([]textinput.Model, len(names))
+	d.isNull = make([]bool, len(names))
+	d.focus = 0
+	d.offset = 0
+	d.action = action
+
+	for i, name := range names {
+		input := textinput.New()
+		input.Prompt = ""
+		input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+		input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+		input.Placeholder = "empty string"
+		input.Width = 30
+
+		if value, ok := values[name]; ok {
+			if value == nil {
+				d.isNull[i] = true
+			} else {
+				input.SetValue(*value)
+			}
+		}
+		d.inputs[i] = input
+	}
+
+	if len(d.inputs) > 0 {
+		d.inputs[0].Focus()
+	}
+}
+
+// Init initializes the dialog
+func (d *BindDialog) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (d *BindDialog) Update(msg tea.Msg) (*BindDialog, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			return d, func() tea.Msg {
+				return CloseBindDialogMsg{}
+			}
+		case "enter", "ctrl+s":
+			result := BindValuesMsg{Values: d.Values(), Action: d.action}
+			return d, func() tea.Msg {
+				return result
+			}
+		case "tab", "down":
+			d.moveFocus(1)
+			return d, nil
+		case "shift+tab", "backtab", "up":
+			d.moveFocus(-1)
+			return d, nil
+		case "ctrl+n":
+			// Toggle NULL for the focused variable
+			if d.focus < len(d.isNull) {
+				d.isNull[d.focus] = !d.isNull[d.focus]
+			}
+			return d, nil
+		}
+	}
+
+	if d.focus >= len(d.inputs) {
+		return d, nil
+	}
+
+	var cmd tea.Cmd
+	d.inputs[d.focus], cmd = d.inputs[d.focus].Update(msg)
+	// Typing a value clears NULL
+	if _, ok := msg.(tea.KeyMsg); ok && d.inputs[d.focus].Value() != "" {
+		d.isNull[d.focus] = false
+	}
+	return d, cmd
+}
+
+// moveFocus moves the focus by delta fields, wrapping around
+func (d *BindDialog) moveFocus(delta int) {
+	if len(d.inputs) == 0 {
+		return
+	}
+	d.inputs[d.focus].Blur()
+	d.focus = (d.focus + delta + len(d.inputs)) % len(d.inputs)
+	d.inputs[d.focus].Focus()
+}
+
+// Values returns the entered values; an empty field is an empty string
+func (d *BindDialog) Values() models.BindValues {
+	values := make(models.BindValues, len(d.names))
+	for i, name := range d.names {
+		if d.isNull[i] {
+			values[name] = nil
+			continue
+		}
+		value := d.inputs[i].Value()
+		values[name] = &value
+	}
+	return values
+}
+
+// View renders the dialog
+func (d *BindDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground)
+
+	nullStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Comment).
+		Italic(true)
+
+	sqlStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground).
+		Faint(true)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	contentWidth := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	nameWidth := 4
+	for _, name := range d.names {
+		nameWidth = max(nameWidth, runewidth.StringWidth(name))
+	}
+	nameWidth = min(nameWidth, contentWidth/3)
+	inputWidth := max(10, contentWidth-nameWidth-6)
+
+	// The statement, shortened to a few lines
+	sqlLines := strings.Split(wrapText(d.sql, contentWidth), "\n")
+	maxSQLLines := max(1, min(6, d.Height-12-len(d.names)))
+	if len(sqlLines) > maxSQLLines {
+		sqlLines = append(sqlLines[:maxSQLLines-1], "…")
+	}
+
+	// Keep the focused field visible
+	visible := max(3, d.Height-10-len(sqlLines))
+	if d.focus < d.offset {
+		d.offset = d.focus
+	}
+	if d.focus >= d.offset+visible {
+		d.offset = d.focus - visible + 1
+	}
+	end := min(len(d.names), d.offset+visible)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Bind Variables"))
+	content.WriteString("\n\n")
+	content.WriteString(sqlStyle.Render(strings.Join(sqlLines, "\n")))
+	content.WriteString("\n\n")
+
+	for i := d.offset; i < end; i++ {
+		indicator := "  "
+		if i == d.focus {
+			indicator = "▸ "
+		}
+		name := runewidth.FillRight(runewidth.Truncate(d.names[i], nameWidth, "…"), nameWidth)
+
+		var value string
+		if d.isNull[i] {
+			value = nullStyle.Render("NULL")
+		} else {
+			d.inputs[i].Width = inputWidth
+			value = d.inputs[i].View()
+		}
+
+		content.WriteString(fmt.Sprintf("%s%s  %s\n", indicator, nameStyle.Render(name), value))
+	}
+	if len(d.names) > visible {
+		content.WriteString(footerStyle.Render(fmt.Sprintf("%d-%d of %d variables", d.offset+1, end, len(d.names))))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("Tab/↑↓: Field  │  Ctrl+N: NULL  │  Enter: Run  │  Esc: Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
@@ -13,6 +13,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/sqlformat"
 	"github.com/rebelice/lazypg/internal/ui/theme"
 )
 
@@ -63,6 +64,10 @@ type CodeEditor struct {
 	// Chroma formatter (cached for performance)
 	chromaStyle     *chroma.Style
 	chromaFormatter chroma.Formatter
+
+	// SQL formatting, and whether content is formatted before saving
+	formatOpts   sqlformat.Options
+	formatOnSave bool
 }
 
 // codeEditorStyles holds pre-computed styles
@@ -84,10 +89,11 @@ type codeEditorStyles struct {
 // NewCodeEditor creates a new code editor
 func NewCodeEditor(th theme.Theme) *CodeEditor {
 	ce := &CodeEditor{
-		lines:    []string{""},
-		ReadOnly: true,
-		Theme:    th,
-		Language: "sql",
+		lines:      []string{""},
+		ReadOnly:   true,
+		Theme:      th,
+		Language:   "sql",
+		formatOpts: sqlformat.Options{TabSize: 2, UseSpaces: true},
 	}
 	ce.initStyles()
 	ce.initChroma()
@@ -175,6 +181,27 @@ func (ce *CodeEditor) GetContent() string {
 	return strings.Join(ce.lines, "\n")
 }
 
+// SetFormatting sets how SQL is formatted, and whether it is formatted
+// automatically before saving
+func (ce *CodeEditor) SetFormatting(opts sqlformat.Options, onSave bool) {
+	ce.formatOpts = opts
+	ce.formatOnSave = onSave
+}
+
+// Format pretty-prints the content. Dollar-quoted function bodies are kept
+// as they are.
+func (ce *CodeEditor) Format() {
+	content := ce.GetContent()
+	formatted := sqlformat.Format(content, ce.formatOpts)
+	if formatted == content {
+		return
+	}
+	ce.lines = strings.Split(formatted, "\n")
+	ce.cursorRow = min(ce.cursorRow, len(ce.lines)-1)
+	ce.cursorCol = min(ce.cursorCol, len([]rune(ce.lines[ce.cursorRow])))
+	ce.Modified = formatted != ce.Original
+}
+
 // EnterEditMode switches to edit mode
 func (ce *CodeEditor) EnterEditMode() {
 	ce.ReadOnly = false
@@ -486,7 +513,7 @@ func (ce *CodeEditor) renderStatusBar(width int) string {
 			helpParts = append([]string{"j/k:scroll"}, helpParts...)
 		}
 	} else {
-		helpParts = []string{"Ctrl+S:save", "Esc:cancel"}
+		helpParts = []string{"Ctrl+S:save", "Alt+F:format", "Esc:cancel"}
 	}
 
 	helpText := strings.Join(helpParts, "  ")
@@ -626,8 +653,15 @@ func (ce *CodeEditor) handleEditKeys(msg tea.KeyMsg) (*CodeEditor, tea.Cmd) {
 			ce.insertChar(' ')
 		}
 
+	// Format
+	case "alt+f":
+		ce.Format()
+
 	// Save
 	case "ctrl+s":
+		if ce.formatOnSave {
+			ce.Format()
+		}
 		content := ce.GetContent()
 		return ce, func() tea.Msg {
 			return SaveObjectMsg{
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// Zone IDs for confirm dialog
+const (
+	ZoneConfirmYes = "confirm-yes"
+	ZoneConfirmNo  = "confirm-no"
+)
+
+// ConfirmDialogResultMsg is sent when the confirm dialog is answered
+// Action is the message to dispatch when Confirmed is true
+type ConfirmDialogResultMsg struct {
+	Confirmed bool
+	Action    tea.Msg
+}
+
+// ConfirmDialog asks the user to confirm a destructive operation
+type ConfirmDialog struct {
+	Title   string
+	Message string
+	Details string // Shown verbatim, e.g. the SQL that will run
+	Width   int
+	Height  int
+	Theme   theme.Theme
+
+	action tea.Msg
+}
+
+// NewConfirmDialog creates a new confirm dialog
+func NewConfirmDialog(th theme.Theme) *ConfirmDialog {
+	return &ConfirmDialog{
+		Theme:  th,
+		Width:  80,
+		Height: 20,
+	}
+}
+
+// SetConfirm sets the dialog content and the action to run on confirmation
+func (c *ConfirmDialog) SetConfirm(title, message, details string, action tea.Msg) {
+	c.Title = title
+	c.Message = message
+	c.Details = details
+	c.action = action
+}
+
+// Update handles keyboard input
+func (c *ConfirmDialog) Update(msg tea.KeyMsg) (*ConfirmDialog, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return c, c.result(true)
+	case "n", "N", "esc", "q":
+		return c, c.result(false)
+	}
+	return c, nil
+}
+
+func (c *ConfirmDialog) result(confirmed bool) tea.Cmd {
+	action := c.action
+	return func() tea.Msg {
+		return ConfirmDialogResultMsg{Confirmed: confirmed, Action: action}
+	}
+}
+
+// View renders the confirm dialog
+func (c *ConfirmDialog) View() string {
+	if c.Width <= 0 || c.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(c.Theme.Warning).
+		Padding(0, 1)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(c.Theme.Foreground).
+		Padding(0, 1)
+
+	detailsStyle := lipgloss.NewStyle().
+		Foreground(c.Theme.Foreground).
+		Faint(true).
+		Padding(0, 1)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(c.Theme.Foreground).
+		Padding(0, 1)
+
+	contentWidth := c.Width - 8 // border (2) + padding (4) + margin (2)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(c.Title))
+	content.WriteString("\n\n")
+	content.WriteString(messageStyle.Render(wrapText(c.Message, contentWidth-2)))
+	content.WriteString("\n")
+
+	if c.Details != "" {
+		lines := strings.Split(wrapText(c.Details, contentWidth-2), "\n")
+		maxLines := c.Height - 10
+		if maxLines < 3 {
+			maxLines = 3
+		}
+		if len(lines) > maxLines {
+			hidden := len(lines) - maxLines + 1
+			lines = append(lines[:maxLines-1], fmt.Sprintf("… %d more line(s)", hidden))
+		}
+		content.WriteString("\n")
+		content.WriteString(detailsStyle.Render(strings.Join(lines, "\n")))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	yesBtn := zone.Mark(ZoneConfirmYes, footerStyle.Render("[y/Enter] Confirm"))
+	noBtn := zone.Mark(ZoneConfirmNo, footerStyle.Render("[n/Esc] Cancel"))
+	content.WriteString(yesBtn)
+	content.WriteString("  ")
+	content.WriteString(noBtn)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(c.Theme.Warning).
+		Padding(1, 2).
+		MaxWidth(c.Width).
+		Background(c.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
+
+// HandleMouseClick handles mouse click events
+func (c *ConfirmDialog) HandleMouseClick(msg tea.MouseMsg) (handled bool, cmd tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+
+	if zone.Get(ZoneConfirmYes).InBounds(msg) {
+		return true, c.result(true)
+	}
+
+	if zone.Get(ZoneConfirmNo).InBounds(msg) {
+		return true, c.result(false)
+	}
+
+	return false, nil
+}
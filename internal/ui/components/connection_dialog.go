@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
+	"github.com/rebelice/lazypg/internal/db/connection"
 	"github.com/rebelice/lazypg/internal/models"
 	"github.com/rebelice/lazypg/internal/ui/theme"
 )
@@ -40,6 +41,7 @@ const (
 	databaseField
 	userField
 	passwordField
+	optionsField
 )
 
 // Zone IDs for mouse click handling
@@ -53,7 +55,7 @@ const (
 // NewConnectionDialog creates a new connection dialog
 func NewConnectionDialog(th theme.Theme) *ConnectionDialog {
 	// Create text inputs for each field
-	inputs := make([]textinput.Model, 5)
+	inputs := make([]textinput.Model, 6)
 
 	// Host input
 	inputs[hostField] = textinput.New()
@@ -62,7 +64,7 @@ func NewConnectionDialog(th theme.Theme) *ConnectionDialog {
 	inputs[hostField].PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
 	inputs[hostField].TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
 	inputs[hostField].Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
-	inputs[hostField].CharLimit = 100
+	inputs[hostField].CharLimit = 1000 // Also takes a whole URI or connection string
 	inputs[hostField].Width = 40
 
 	// Port input
@@ -104,6 +106,15 @@ func NewConnectionDialog(th theme.Theme) *ConnectionDialog {
 	inputs[passwordField].CharLimit = 100
 	inputs[passwordField].Width = 40
 
+	// Options input: other libpq keywords
+	inputs[optionsField] = textinput.New()
+	inputs[optionsField].Placeholder = "sslrootcert=ca.pem connect_timeout=5"
+	inputs[optionsField].PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+	inputs[optionsField].TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+	inputs[optionsField].Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+	inputs[optionsField].CharLimit = 1000
+	inputs[optionsField].Width = 40
+
 	// Create search input (width will be set dynamically in View)
 	searchInput := textinput.New()
 	searchInput.Placeholder = "Search for connection..."
@@ -328,7 +339,7 @@ func (c *ConnectionDialog) renderDiscoveryMode(contentWidth int) string {
 	if c.SearchMode {
 		sections = append(sections, helpStyle.Render("Type to search │ Enter: Apply │ Esc: Clear & Exit"))
 	} else {
-		sections = append(sections, helpStyle.Render("↑↓: Navigate │ /: Search │ m: Manual │ Enter: Connect"))
+		sections = append(sections, helpStyle.Render("↑↓: Navigate │ /: Search │ m: Manual │ p: Profiles │ Enter: Connect"))
 	}
 
 	return strings.Join(sections, "\n")
@@ -345,7 +356,7 @@ func (c *ConnectionDialog) renderManualMode(contentWidth int) string {
 	sections = append(sections, titleStyle.Render("🔧 Manual Connection"))
 
 	// Form fields
-	fieldLabels := []string{"Host:", "Port:", "Database:", "User:", "Password:"}
+	fieldLabels := []string{"Host:", "Port:", "Database:", "User:", "Password:", "Options:"}
 
 	for i, label := range fieldLabels {
 		labelStyle := lipgloss.NewStyle().
@@ -367,6 +378,10 @@ func (c *ConnectionDialog) renderManualMode(contentWidth int) string {
 		sections = append(sections, fieldLine)
 	}
 
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086"))
+	sections = append(sections, "")
+	sections = append(sections, hintStyle.Render("Host also takes a postgres:// URI or key=value string."))
 	sections = append(sections, "")
 
 	// Instructions - shorter to fit within MaxWidth
@@ -557,13 +572,39 @@ func (c *ConnectionDialog) GetSelectedHistory() *models.ConnectionHistoryEntry {
 	return &filtered[c.SelectedIndex]
 }
 
-// GetManualConfig returns the manual connection config if valid, or error
+// GetManualConfig returns the manual connection config if valid, or error.
+// A URI or connection string in the host field replaces the other fields
+// except the password and options.
 func (c *ConnectionDialog) GetManualConfig() (models.ConnectionConfig, error) {
 	host := strings.TrimSpace(c.inputs[hostField].Value())
 	port := strings.TrimSpace(c.inputs[portField].Value())
 	database := strings.TrimSpace(c.inputs[databaseField].Value())
 	user := strings.TrimSpace(c.inputs[userField].Value())
 	password := c.inputs[passwordField].Value()
+	options := c.inputs[optionsField].Value()
+
+	if connection.IsConnectionString(host) {
+		config, err := connection.ParseConnectionString(host)
+		if err != nil {
+			return models.ConnectionConfig{}, err
+		}
+		if password != "" {
+			config.Password = password
+		}
+		if err := connection.ApplyConnectionOptions(&config, options); err != nil {
+			return models.ConnectionConfig{}, fmt.Errorf("options: %w", err)
+		}
+		if config.Host == "" {
+			config.Host = c.inputs[hostField].Placeholder
+		}
+		if config.User == "" {
+			config.User = c.inputs[userField].Placeholder
+		}
+		if config.Database == "" {
+			config.Database = config.User
+		}
+		return config, nil
+	}
 
 	// Use placeholder values as defaults when fields are empty
 	if host == "" {
@@ -590,14 +631,18 @@ func (c *ConnectionDialog) GetManualConfig() (models.ConnectionConfig, error) {
 		return models.ConnectionConfig{}, fmt.Errorf("database is required")
 	}
 
-	return models.ConnectionConfig{
+	config := models.ConnectionConfig{
 		Host:     host,
 		Port:     mustParseInt(port, 5432),
 		Database: database,
 		User:     user,
 		Password: password,
 		SSLMode:  "prefer",
-	}, nil
+	}
+	if err := connection.ApplyConnectionOptions(&config, options); err != nil {
+		return models.ConnectionConfig{}, fmt.Errorf("options: %w", err)
+	}
+	return config, nil
 }
 
 // SetDiscoveredInstances updates the list of discovered instances
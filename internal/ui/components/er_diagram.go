@@ -0,0 +1,382 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rebelice/lazypg/internal/erd"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// maxERDiagramHops is the largest neighbourhood a focused diagram shows
+const maxERDiagramHops = 9
+
+// erDetailNames names the detail levels of the diagram
+var erDetailNames = map[erd.Detail]string{
+	erd.DetailNames: "names",
+	erd.DetailKeys:  "key columns",
+	erd.DetailAll:   "all columns",
+}
+
+// CloseERDiagramMsg is sent when the ER diagram should close
+type CloseERDiagramMsg struct{}
+
+// OpenERDiagramTableMsg is sent to open the data of a table of the diagram
+type OpenERDiagramTableMsg struct {
+	Schema string
+	Table  string
+}
+
+// ExportERDiagramMsg is sent to save the diagram as text
+type ExportERDiagramMsg struct {
+	Schema    string
+	Extension string // "mmd" or "dot"
+	Content   string
+}
+
+// ERDiagramView shows the tables of a schema and the foreign keys between
+// them as an entity-relationship diagram
+type ERDiagramView struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	schema  string
+	loading bool
+	err     error
+
+	graph    *erd.Graph // Whole schema
+	shown    *erd.Graph // graph, or the neighbourhood of the focused table
+	diagram  *erd.Diagram
+	detail   erd.Detail
+	focused  string // ID of the table the diagram is narrowed to, "" for none
+	hops     int
+	selected string // ID of the selected table
+
+	offsetX int
+	offsetY int
+	notice  string
+}
+
+// NewERDiagramView creates a new ER diagram view
+func NewERDiagramView(th theme.Theme) *ERDiagramView {
+	return &ERDiagramView{
+		Width:  100,
+		Height: 30,
+		Theme:  th,
+		detail: erd.DetailKeys,
+		hops:   1,
+	}
+}
+
+// SetLoading shows the diagram of a schema as loading
+func (v *ERDiagramView) SetLoading(schema string) {
+	v.schema = schema
+	v.loading = true
+	v.err = nil
+	v.graph, v.shown, v.diagram = nil, nil, nil
+	v.focused, v.selected = "", ""
+	v.offsetX, v.offsetY = 0, 0
+	v.notice = ""
+}
+
+// Schema returns the schema of the diagram
+func (v *ERDiagramView) Schema() string {
+	return v.schema
+}
+
+// SetGraph shows a schema's graph with a table selected, or the first one
+// when selected is empty
+func (v *ERDiagramView) SetGraph(g *erd.Graph, selected string, err error) {
+	v.loading = false
+	v.err = err
+	v.graph = g
+	v.selected = selected
+	v.rebuild()
+}
+
+// SetNotice shows a message below the diagram, e.g. where it was exported
+func (v *ERDiagramView) SetNotice(notice string) {
+	v.notice = notice
+}
+
+// rebuild lays out the shown graph again, keeping the selection in view
+func (v *ERDiagramView) rebuild() {
+	if v.graph == nil {
+		v.shown, v.diagram = nil, nil
+		return
+	}
+	v.shown = v.graph
+	if v.focused != "" {
+		v.shown = v.graph.Neighbourhood(v.focused, v.hops)
+	}
+	v.diagram = erd.Render(v.shown, v.detail)
+	if _, ok := v.diagram.Boxes[v.selected]; !ok {
+		v.selected = ""
+		if len(v.diagram.Order) > 0 {
+			v.selected = v.diagram.Order[0]
+		}
+	}
+	v.clampOffset()
+	v.scrollToSelected()
+}
+
+// canvasSize returns the width and height available to the diagram
+func (v *ERDiagramView) canvasSize() (int, int) {
+	return max(1, v.Width-4), max(1, v.Height-7)
+}
+
+// Update handles keyboard input
+func (v *ERDiagramView) Update(msg tea.KeyMsg) (*ERDiagramView, tea.Cmd) {
+	width, height := v.canvasSize()
+
+	switch msg.String() {
+	case "esc", "q":
+		return v, func() tea.Msg {
+			return CloseERDiagramMsg{}
+		}
+	}
+	if v.diagram == nil {
+		return v, nil
+	}
+
+	v.notice = ""
+	switch msg.String() {
+	case "up", "k":
+		v.scroll(0, -1)
+	case "down", "j":
+		v.scroll(0, 1)
+	case "left", "h":
+		v.scroll(-4, 0)
+	case "right", "l":
+		v.scroll(4, 0)
+	case "ctrl+u", "pgup":
+		v.scroll(0, -height/2)
+	case "ctrl+d", "pgdown":
+		v.scroll(0, height/2)
+	case "H":
+		v.scroll(-width/2, 0)
+	case "L":
+		v.scroll(width/2, 0)
+	case "g", "home":
+		v.offsetX, v.offsetY = 0, 0
+	case "G", "end":
+		v.offsetY = v.diagram.Height
+		v.clampOffset()
+
+	case "tab":
+		v.selectNext(1)
+	case "shift+tab", "backtab":
+		v.selectNext(-1)
+
+	case "+", "=":
+		if v.detail < erd.DetailAll {
+			v.detail++
+			v.rebuild()
+		}
+	case "-", "_":
+		if v.detail > erd.DetailNames {
+			v.detail--
+			v.rebuild()
+		}
+
+	case "f":
+		if v.focused != "" {
+			v.focused = ""
+		} else {
+			v.focused = v.selected
+		}
+		v.rebuild()
+	case "]":
+		if v.hops < maxERDiagramHops {
+			v.hops++
+			v.rebuild()
+		}
+	case "[":
+		if v.hops > 1 {
+			v.hops--
+			v.rebuild()
+		}
+
+	case "enter":
+		if table := v.shown.Table(v.selected); table != nil {
+			open := OpenERDiagramTableMsg{Schema: table.Schema, Table: table.Name}
+			return v, func() tea.Msg {
+				return open
+			}
+		}
+
+	case "m":
+		return v, v.export("mmd", erd.Mermaid(v.shown))
+	case "d":
+		return v, v.export("dot", erd.DOT(v.shown))
+	}
+	return v, nil
+}
+
+// export asks for the shown graph to be saved
+func (v *ERDiagramView) export(extension, content string) tea.Cmd {
+	msg := ExportERDiagramMsg{Schema: v.schema, Extension: extension, Content: content}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// scroll moves the view over the diagram
+func (v *ERDiagramView) scroll(dx, dy int) {
+	v.offsetX += dx
+	v.offsetY += dy
+	v.clampOffset()
+}
+
+// clampOffset keeps the view over the diagram
+func (v *ERDiagramView) clampOffset() {
+	if v.diagram == nil {
+		return
+	}
+	width, height := v.canvasSize()
+	v.offsetX = max(0, min(v.offsetX, v.diagram.Width-width))
+	v.offsetY = max(0, min(v.offsetY, v.diagram.Height-height))
+}
+
+// selectNext selects the next (or previous) table, column by column
+func (v *ERDiagramView) selectNext(direction int) {
+	order := v.diagram.Order
+	if len(order) == 0 {
+		return
+	}
+	i := slices.Index(order, v.selected)
+	i = (i + direction + len(order)) % len(order)
+	v.selected = order[i]
+	v.scrollToSelected()
+}
+
+// scrollToSelected scrolls as little as needed to show the selected table
+func (v *ERDiagramView) scrollToSelected() {
+	box, ok := v.diagram.Boxes[v.selected]
+	if !ok {
+		return
+	}
+	width, height := v.canvasSize()
+	if box.X+box.Width > v.offsetX+width {
+		v.offsetX = box.X + box.Width - width
+	}
+	if box.X < v.offsetX {
+		v.offsetX = box.X
+	}
+	if box.Y+box.Height > v.offsetY+height {
+		v.offsetY = box.Y + box.Height - height
+	}
+	if box.Y < v.offsetY {
+		v.offsetY = box.Y
+	}
+	v.clampOffset()
+}
+
+// HandleMouseWheel scrolls the diagram; with shift held it scrolls sideways
+func (v *ERDiagramView) HandleMouseWheel(msg tea.MouseMsg) bool {
+	if v.diagram == nil {
+		return false
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if msg.Shift {
+			v.scroll(-4, 0)
+		} else {
+			v.scroll(0, -3)
+		}
+		return true
+	case tea.MouseButtonWheelDown:
+		if msg.Shift {
+			v.scroll(4, 0)
+		} else {
+			v.scroll(0, 3)
+		}
+		return true
+	case tea.MouseButtonWheelLeft:
+		v.scroll(-4, 0)
+		return true
+	case tea.MouseButtonWheelRight:
+		v.scroll(4, 0)
+		return true
+	}
+	return false
+}
+
+// View renders the diagram
+func (v *ERDiagramView) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(v.Theme.Background).
+		Background(v.Theme.Info).
+		Padding(0, 1).
+		Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(v.Theme.Metadata).Padding(0, 1)
+	noticeStyle := lipgloss.NewStyle().Foreground(v.Theme.Warning).Padding(0, 1)
+
+	width, height := v.canvasSize()
+	title := "ER Diagram · " + v.schema
+	if v.focused != "" && v.shown != nil {
+		if table := v.graph.Table(v.focused); table != nil {
+			title += fmt.Sprintf(" · %s ±%d", v.graph.Label(*table), v.hops)
+		}
+	}
+	sections := []string{titleStyle.Render(title)}
+
+	var canvas []string
+	switch {
+	case v.loading:
+		sections = append(sections, metaStyle.Render("Loading tables and foreign keys…"))
+	case v.err != nil:
+		sections = append(sections, noticeStyle.Render(v.err.Error()))
+	case v.diagram == nil || len(v.diagram.Order) == 0:
+		sections = append(sections, metaStyle.Render("No tables in this schema"))
+	default:
+		sections = append(sections, metaStyle.Render(fmt.Sprintf("%d table(s) · %d foreign key(s) · showing %s",
+			len(v.shown.Tables), len(v.shown.Relations), erDetailNames[v.detail])))
+		canvas = v.renderCanvas(width, height)
+	}
+	for len(canvas) < height+1 {
+		canvas = append(canvas, "")
+	}
+	sections = append(sections, strings.Join(canvas, "\n"))
+
+	sections = append(sections, noticeStyle.Render(v.notice))
+	instr := "↑↓←→/hjkl: Scroll  Tab: Select  +/-: Detail  f: Focus  [/]: Hops  Enter: Open  m/d: Export Mermaid/DOT  Esc: Close"
+	sections = append(sections, metaStyle.Render(instr))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(v.Theme.Border).
+		Padding(0, 1).
+		Width(v.Width - 2).
+		Render(strings.Join(sections, "\n"))
+}
+
+// renderCanvas renders the visible part of the diagram, preceded by a blank
+// line, with the selected table highlighted
+func (v *ERDiagramView) renderCanvas(width, height int) []string {
+	normal := lipgloss.NewStyle().Foreground(v.Theme.Foreground)
+	selected := lipgloss.NewStyle().Foreground(v.Theme.BorderFocused).Bold(true)
+
+	box, hasBox := v.diagram.Boxes[v.selected]
+	lines := []string{""}
+	for y := v.offsetY; y < v.offsetY+height; y++ {
+		start, end := v.offsetX, v.offsetX
+		if hasBox && y >= box.Y && y < box.Y+box.Height {
+			start = max(v.offsetX, box.X)
+			end = min(v.offsetX+width, box.X+box.Width)
+		}
+		if start >= end {
+			lines = append(lines, normal.Render(v.diagram.Line(y, v.offsetX, width)))
+			continue
+		}
+		lines = append(lines,
+			normal.Render(v.diagram.Line(y, v.offsetX, start-v.offsetX))+
+				selected.Render(v.diagram.Line(y, start, end-start))+
+				normal.Render(v.diagram.Line(y, end, v.offsetX+width-end)))
+	}
+	return lines
+}
@@ -0,0 +1,537 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/explain"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// Zone ID prefixes for mouse click handling
+const (
+	ZoneExplainNodePrefix = "explain-node-"
+)
+
+// explainDetailHeight is the number of lines reserved for the node details
+const explainDetailHeight = 9
+
+// ExplainQueryMsg is sent when a statement should be explained
+type ExplainQueryMsg struct {
+	SQL     string
+	Analyze bool
+	Values  models.BindValues // Bind variable values; prompted for when nil
+}
+
+// CloseExplainViewerMsg is sent when the plan viewer should close
+type CloseExplainViewerMsg struct{}
+
+// ExplainViewer displays an execution plan as a collapsible tree
+type ExplainViewer struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	plan      *explain.Plan
+	collapsed map[*explain.Node]bool
+
+	// Flattened list of visible nodes (for rendering and navigation)
+	visibleNodes []*explain.Node
+
+	selectedIndex int
+	scrollOffset  int
+}
+
+// NewExplainViewer creates a new plan viewer
+func NewExplainViewer(th theme.Theme) *ExplainViewer {
+	return &ExplainViewer{
+		Width:     100,
+		Height:    30,
+		Theme:     th,
+		collapsed: make(map[*explain.Node]bool),
+	}
+}
+
+// SetPlan shows a plan with all nodes expanded and the most expensive node selected
+func (ev *ExplainViewer) SetPlan(plan *explain.Plan) {
+	ev.plan = plan
+	ev.collapsed = make(map[*explain.Node]bool)
+	ev.rebuildVisibleNodes()
+	ev.selectedIndex = 0
+	ev.scrollOffset = 0
+
+	// Start on the node that takes the largest share of the work
+	best := -1.0
+	for i, node := range ev.visibleNodes {
+		weight := node.SelfCost
+		if plan.Analyzed {
+			weight = node.SelfTime
+		}
+		if weight > best {
+			best = weight
+			ev.selectedIndex = i
+		}
+	}
+	ev.adjustScroll()
+}
+
+// rebuildVisibleNodes flattens the expanded part of the tree
+func (ev *ExplainViewer) rebuildVisibleNodes() {
+	ev.visibleNodes = nil
+	if ev.plan == nil || ev.plan.Root == nil {
+		return
+	}
+	var walk func(node *explain.Node)
+	walk = func(node *explain.Node) {
+		ev.visibleNodes = append(ev.visibleNodes, node)
+		if !ev.collapsed[node] {
+			for _, child := range node.Children {
+				walk(child)
+			}
+		}
+	}
+	walk(ev.plan.Root)
+}
+
+// selectedNode returns the node under the cursor
+func (ev *ExplainViewer) selectedNode() *explain.Node {
+	if ev.selectedIndex < 0 || ev.selectedIndex >= len(ev.visibleNodes) {
+		return nil
+	}
+	return ev.visibleNodes[ev.selectedIndex]
+}
+
+// treeHeight returns the number of lines available for the tree
+func (ev *ExplainViewer) treeHeight() int {
+	return max(1, ev.Height-explainDetailHeight-7)
+}
+
+// Update handles keyboard input
+func (ev *ExplainViewer) Update(msg tea.KeyMsg) (*ExplainViewer, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return ev, func() tea.Msg {
+			return CloseExplainViewerMsg{}
+		}
+
+	case "up", "k":
+		ev.moveSelection(-1)
+
+	case "down", "j":
+		ev.moveSelection(1)
+
+	case " ", "enter":
+		// Toggle expand/collapse
+		if node := ev.selectedNode(); node != nil && len(node.Children) > 0 {
+			ev.setCollapsed(node, !ev.collapsed[node])
+		}
+
+	case "right", "l":
+		if node := ev.selectedNode(); node != nil && len(node.Children) > 0 {
+			ev.setCollapsed(node, false)
+		}
+
+	case "left", "h":
+		// Collapse, or jump to parent when already collapsed
+		if node := ev.selectedNode(); node != nil {
+			if len(node.Children) > 0 && !ev.collapsed[node] {
+				ev.setCollapsed(node, true)
+			} else if node.Parent != nil {
+				ev.selectNode(node.Parent)
+			}
+		}
+
+	case "E":
+		// Expand all
+		ev.collapsed = make(map[*explain.Node]bool)
+		node := ev.selectedNode()
+		ev.rebuildVisibleNodes()
+		ev.selectNode(node)
+
+	case "C":
+		// Collapse all
+		if ev.plan != nil {
+			for _, node := range ev.plan.Nodes() {
+				if len(node.Children) > 0 && node != ev.plan.Root {
+					ev.collapsed[node] = true
+				}
+			}
+		}
+		ev.rebuildVisibleNodes()
+		ev.selectedIndex = min(ev.selectedIndex, len(ev.visibleNodes)-1)
+		ev.adjustScroll()
+
+	case "n":
+		ev.jumpToFinding(1)
+
+	case "N":
+		ev.jumpToFinding(-1)
+
+	case "ctrl+d":
+		ev.moveSelection(ev.treeHeight() / 2)
+
+	case "ctrl+u":
+		ev.moveSelection(-ev.treeHeight() / 2)
+
+	case "ctrl+f", "pgdown":
+		ev.moveSelection(ev.treeHeight())
+
+	case "ctrl+b", "pgup":
+		ev.moveSelection(-ev.treeHeight())
+
+	case "g", "home":
+		ev.selectedIndex = 0
+		ev.adjustScroll()
+
+	case "G", "end":
+		ev.selectedIndex = max(0, len(ev.visibleNodes)-1)
+		ev.adjustScroll()
+	}
+
+	return ev, nil
+}
+
+// moveSelection moves the cursor by delta visible nodes
+func (ev *ExplainViewer) moveSelection(delta int) {
+	if len(ev.visibleNodes) == 0 {
+		return
+	}
+	ev.selectedIndex = max(0, min(len(ev.visibleNodes)-1, ev.selectedIndex+delta))
+	ev.adjustScroll()
+}
+
+// setCollapsed collapses or expands a node, keeping it selected
+func (ev *ExplainViewer) setCollapsed(node *explain.Node, collapsed bool) {
+	ev.collapsed[node] = collapsed
+	ev.rebuildVisibleNodes()
+	ev.selectNode(node)
+}
+
+// selectNode moves the cursor to a node, expanding its ancestors if needed
+func (ev *ExplainViewer) selectNode(target *explain.Node) {
+	if target == nil {
+		return
+	}
+	expanded := false
+	for p := target.Parent; p != nil; p = p.Parent {
+		if ev.collapsed[p] {
+			ev.collapsed[p] = false
+			expanded = true
+		}
+	}
+	if expanded {
+		ev.rebuildVisibleNodes()
+	}
+	for i, node := range ev.visibleNodes {
+		if node == target {
+			ev.selectedIndex = i
+			break
+		}
+	}
+	ev.adjustScroll()
+}
+
+// jumpToFinding moves to the next (or previous) node with findings, in tree order
+func (ev *ExplainViewer) jumpToFinding(direction int) {
+	if ev.plan == nil {
+		return
+	}
+	nodes := ev.plan.Nodes()
+	current := 0
+	if selected := ev.selectedNode(); selected != nil {
+		for i, node := range nodes {
+			if node == selected {
+				current = i
+				break
+			}
+		}
+	}
+	for step := 1; step <= len(nodes); step++ {
+		i := (current + direction*step + len(nodes)*step) % len(nodes)
+		if len(nodes[i].Findings) > 0 {
+			ev.selectNode(nodes[i])
+			return
+		}
+	}
+}
+
+// adjustScroll keeps the selected node visible
+func (ev *ExplainViewer) adjustScroll() {
+	height := ev.treeHeight()
+	if ev.selectedIndex < ev.scrollOffset {
+		ev.scrollOffset = ev.selectedIndex
+	}
+	if ev.selectedIndex >= ev.scrollOffset+height {
+		ev.scrollOffset = ev.selectedIndex - height + 1
+	}
+	ev.scrollOffset = max(0, ev.scrollOffset)
+}
+
+// HandleMouseClick handles mouse click events
+// Returns true if click was handled, and a command if needed
+func (ev *ExplainViewer) HandleMouseClick(msg tea.MouseMsg) (handled bool, cmd tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+
+	end := min(len(ev.visibleNodes), ev.scrollOffset+ev.treeHeight())
+	for i := ev.scrollOffset; i < end; i++ {
+		if zone.Get(fmt.Sprintf("%s%d", ZoneExplainNodePrefix, i)).InBounds(msg) {
+			node := ev.visibleNodes[i]
+			if i == ev.selectedIndex && len(node.Children) > 0 {
+				// Click on already selected node toggles expand/collapse
+				ev.setCollapsed(node, !ev.collapsed[node])
+			} else {
+				ev.selectedIndex = i
+				ev.adjustScroll()
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HandleMouseWheel handles mouse wheel events for scrolling
+func (ev *ExplainViewer) HandleMouseWheel(msg tea.MouseMsg) bool {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		ev.moveSelection(-3)
+		return true
+	case tea.MouseButtonWheelDown:
+		ev.moveSelection(3)
+		return true
+	}
+	return false
+}
+
+// View renders the plan viewer
+func (ev *ExplainViewer) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ev.Theme.Background).
+		Background(ev.Theme.Info).
+		Padding(0, 1).
+		Bold(true)
+
+	metaStyle := lipgloss.NewStyle().
+		Foreground(ev.Theme.Metadata).
+		Padding(0, 1)
+
+	warnStyle := lipgloss.NewStyle().
+		Foreground(ev.Theme.Warning).
+		Padding(0, 1)
+
+	var sections []string
+	if ev.plan == nil {
+		sections = append(sections, titleStyle.Render("Query Plan"), metaStyle.Render("No plan"))
+		return ev.container().Render(strings.Join(sections, "\n"))
+	}
+
+	title := "EXPLAIN"
+	if ev.plan.Analyzed {
+		title = "EXPLAIN ANALYZE"
+	}
+	sections = append(sections, titleStyle.Render(title))
+
+	// Summary line
+	var summary []string
+	if ev.plan.PlanningTime > 0 {
+		summary = append(summary, fmt.Sprintf("Planning %.3f ms", ev.plan.PlanningTime))
+	}
+	if ev.plan.Analyzed {
+		summary = append(summary, fmt.Sprintf("Execution %.3f ms", ev.plan.ExecutionTime))
+	} else if ev.plan.Root != nil {
+		summary = append(summary, fmt.Sprintf("Total cost %.2f", ev.plan.Root.TotalCost))
+	}
+	flagged := 0
+	for _, node := range ev.plan.Nodes() {
+		if len(node.Findings) > 0 {
+			flagged++
+		}
+	}
+	summary = append(summary, fmt.Sprintf("%d node(s) flagged", flagged))
+	for _, trigger := range ev.plan.Triggers {
+		summary = append(summary, "Trigger "+trigger)
+	}
+	sections = append(sections, metaStyle.Render(strings.Join(summary, " · ")))
+	if ev.plan.RolledBack {
+		sections = append(sections, warnStyle.Render("Statement was executed in a transaction and rolled back"))
+	} else {
+		sections = append(sections, "")
+	}
+
+	// Tree
+	height := ev.treeHeight()
+	end := min(len(ev.visibleNodes), ev.scrollOffset+height)
+	lines := make([]string, 0, height)
+	for i := ev.scrollOffset; i < end; i++ {
+		line := ev.renderNode(ev.visibleNodes[i], i == ev.selectedIndex)
+		lines = append(lines, zone.Mark(fmt.Sprintf("%s%d", ZoneExplainNodePrefix, i), line))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	sections = append(sections, strings.Join(lines, "\n"))
+
+	// Details of the selected node
+	sections = append(sections, lipgloss.NewStyle().Foreground(ev.Theme.Border).Render(strings.Repeat("─", max(1, ev.Width-6))))
+	sections = append(sections, ev.renderDetails())
+
+	instr := "↑↓/jk: Move  ←→/hl: Collapse/Expand  Space: Toggle  E/C: Expand/Collapse all  n/N: Next/Prev flagged  Esc: Close"
+	sections = append(sections, metaStyle.Render(instr))
+
+	return ev.container().Render(strings.Join(sections, "\n"))
+}
+
+// container returns the outer box style
+func (ev *ExplainViewer) container() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ev.Theme.Border).
+		Padding(0, 1).
+		Width(ev.Width - 2)
+}
+
+// renderNode renders a single plan node line
+func (ev *ExplainViewer) renderNode(node *explain.Node, isSelected bool) string {
+	indent := strings.Repeat("  ", node.Level)
+	indicator := "  "
+	if len(node.Children) > 0 {
+		if ev.collapsed[node] {
+			indicator = "▶ "
+		} else {
+			indicator = "▼ "
+		}
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(ev.Theme.Foreground)
+	switch {
+	case node.HasFinding(explain.FindingExpensive):
+		titleStyle = titleStyle.Foreground(ev.Theme.Error).Bold(true)
+	case len(node.Findings) > 0:
+		titleStyle = titleStyle.Foreground(ev.Theme.Warning)
+	}
+	metricStyle := lipgloss.NewStyle().Foreground(ev.Theme.Metadata)
+
+	title := node.Title()
+	if rel := node.ParentRelationship(); rel == "InitPlan" || rel == "SubPlan" {
+		title = "[" + rel + "] " + title
+	}
+
+	line := indent + indicator + titleStyle.Render(title) + "  " + metricStyle.Render(nodeMetrics(node)) + ev.renderBadges(node)
+
+	if isSelected {
+		plain := indent + indicator + title + "  " + nodeMetrics(node) + badgeText(node)
+		return lipgloss.NewStyle().
+			Background(ev.Theme.BorderFocused).
+			Foreground(ev.Theme.Background).
+			Bold(true).
+			Width(ev.Width - 6).
+			Render(runewidth.Truncate(plain, ev.Width-6, "…"))
+	}
+	return lipgloss.NewStyle().MaxWidth(ev.Width - 6).Render(line)
+}
+
+// nodeMetrics formats cost, rows and timing of a node
+func nodeMetrics(node *explain.Node) string {
+	metrics := fmt.Sprintf("cost=%.2f..%.2f rows=%.0f", node.StartupCost, node.TotalCost, node.PlanRows)
+	if node.HasActual {
+		if node.Loops == 0 {
+			return metrics + " (never executed)"
+		}
+		metrics += fmt.Sprintf("  actual=%.3f..%.3f ms rows=%.0f loops=%.0f", node.ActualStartupTime, node.ActualTotalTime, node.ActualRows, node.Loops)
+	}
+	return metrics
+}
+
+// renderBadges renders short markers for the findings of a node
+func (ev *ExplainViewer) renderBadges(node *explain.Node) string {
+	var badges []string
+	for _, f := range node.Findings {
+		style := lipgloss.NewStyle().Foreground(ev.Theme.Warning)
+		if f.Kind == explain.FindingExpensive {
+			style = lipgloss.NewStyle().Foreground(ev.Theme.Error).Bold(true)
+		} else if f.Kind == explain.FindingBuffers {
+			style = lipgloss.NewStyle().Foreground(ev.Theme.Info)
+		}
+		badges = append(badges, style.Render(findingBadge(f.Kind)))
+	}
+	if len(badges) == 0 {
+		return ""
+	}
+	return "  " + strings.Join(badges, " ")
+}
+
+// badgeText returns the badges of a node without styling
+func badgeText(node *explain.Node) string {
+	if len(node.Findings) == 0 {
+		return ""
+	}
+	badges := make([]string, len(node.Findings))
+	for i, f := range node.Findings {
+		badges[i] = findingBadge(f.Kind)
+	}
+	return "  " + strings.Join(badges, " ")
+}
+
+// findingBadge returns the short marker for a finding kind
+func findingBadge(kind explain.FindingKind) string {
+	switch kind {
+	case explain.FindingExpensive:
+		return "● slow"
+	case explain.FindingEstimateMiss:
+		return "⚠ estimate"
+	case explain.FindingSeqScan:
+		return "⚠ seq scan"
+	case explain.FindingBuffers:
+		return "◆ buffers"
+	default:
+		return ""
+	}
+}
+
+// renderDetails renders findings and properties of the selected node
+func (ev *ExplainViewer) renderDetails() string {
+	node := ev.selectedNode()
+	if node == nil {
+		return strings.Repeat("\n", explainDetailHeight-1)
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(ev.Theme.Info)
+	valueStyle := lipgloss.NewStyle().Foreground(ev.Theme.Foreground)
+	width := ev.Width - 6
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(ev.Theme.Foreground).Render(node.Title()))
+	if node.HasActual && node.Loops > 0 {
+		lines = append(lines, keyStyle.Render("Self time: ")+valueStyle.Render(fmt.Sprintf("%.3f ms", node.SelfTime)))
+	} else {
+		lines = append(lines, keyStyle.Render("Self cost: ")+valueStyle.Render(fmt.Sprintf("%.2f", node.SelfCost)))
+	}
+	for _, f := range node.Findings {
+		color := ev.Theme.Warning
+		if f.Kind == explain.FindingExpensive {
+			color = ev.Theme.Error
+		}
+		lines = append(lines, lipgloss.NewStyle().Foreground(color).Render(findingBadge(f.Kind)+": "+f.Message))
+	}
+	for _, d := range node.Details() {
+		lines = append(lines, keyStyle.Render(d.Key+": ")+valueStyle.Render(d.Value))
+	}
+
+	if len(lines) > explainDetailHeight {
+		more := len(lines) - explainDetailHeight + 1
+		lines = append(lines[:explainDetailHeight-1], lipgloss.NewStyle().Foreground(ev.Theme.Metadata).Render(fmt.Sprintf("… %d more", more)))
+	}
+	for len(lines) < explainDetailHeight {
+		lines = append(lines, "")
+	}
+	for i, line := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
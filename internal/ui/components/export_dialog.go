@@ -0,0 +1,179 @@
+package components
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rebelice/lazypg/internal/export"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// ExportDataMsg is sent when the user confirms an export
+type ExportDataMsg struct {
+	Format export.Format
+	Path   string
+}
+
+// CloseExportDialogMsg is sent when the export dialog is cancelled
+type CloseExportDialogMsg struct{}
+
+// ExportDialog lets the user pick an export format and destination file
+type ExportDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	source   string // Description of what is exported
+	selected int
+	input    textinput.Model
+}
+
+// NewExportDialog creates a new export dialog
+func NewExportDialog(th theme.Theme) *ExportDialog {
+	input := textinput.New()
+	input.Prompt = ""
+	input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+	input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+	input.CharLimit = 512
+	input.Width = 50
+
+	return &ExportDialog{
+		Theme:  th,
+		Width:  70,
+		Height: 16,
+		input:  input,
+	}
+}
+
+// SetSource sets the description of the data and the default file name (without extension)
+func (d *ExportDialog) SetSource(source, baseName string) {
+	d.source = source
+	format := export.Formats[d.selected]
+	d.input.SetValue(baseName + "." + format.Extension())
+	d.input.CursorEnd()
+	d.input.Focus()
+}
+
+// Init initializes the dialog
+func (d *ExportDialog) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (d *ExportDialog) Update(msg tea.Msg) (*ExportDialog, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			return d, func() tea.Msg {
+				return CloseExportDialogMsg{}
+			}
+		case "enter":
+			path := strings.TrimSpace(d.input.Value())
+			if path == "" {
+				return d, nil
+			}
+			format := export.Formats[d.selected]
+			return d, func() tea.Msg {
+				return ExportDataMsg{Format: format, Path: path}
+			}
+		case "up", "shift+tab", "backtab":
+			d.selectFormat(d.selected - 1)
+			return d, nil
+		case "down", "tab":
+			d.selectFormat(d.selected + 1)
+			return d, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return d, cmd
+}
+
+// selectFormat changes the format and updates the file extension
+func (d *ExportDialog) selectFormat(index int) {
+	count := len(export.Formats)
+	index = (index + count) % count
+
+	oldExt := "." + export.Formats[d.selected].Extension()
+	newExt := "." + export.Formats[index].Extension()
+	d.selected = index
+
+	path := d.input.Value()
+	if filepath.Ext(path) == oldExt {
+		d.input.SetValue(strings.TrimSuffix(path, oldExt) + newExt)
+		d.input.CursorEnd()
+	}
+}
+
+// View renders the dialog
+func (d *ExportDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground).
+		Faint(true).
+		Padding(0, 1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.BorderFocused).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Export Data"))
+	content.WriteString("\n\n")
+	content.WriteString(descStyle.Render(d.source))
+	content.WriteString("\n\n")
+
+	content.WriteString(labelStyle.Render("Format:"))
+	content.WriteString("\n")
+	for i, format := range export.Formats {
+		if i == d.selected {
+			content.WriteString(selectedStyle.Render("  ▸ " + format.Label()))
+		} else {
+			content.WriteString(normalStyle.Render("    " + format.Label()))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	content.WriteString(labelStyle.Render("File:"))
+	content.WriteString("\n  ")
+	d.input.Width = max(20, d.Width-12)
+	content.WriteString(d.input.View())
+	content.WriteString("\n\n")
+
+	content.WriteString(footerStyle.Render("↑↓/Tab: Format  │  Enter: Export  │  Esc: Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
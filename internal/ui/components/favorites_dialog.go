@@ -29,6 +29,7 @@ const (
 // ExecuteFavoriteMsg is sent when a favorite should be executed
 type ExecuteFavoriteMsg struct {
 	Favorite models.Favorite
+	Values   models.BindValues // Bind variable values; prompted for when nil
 }
 
 // CloseFavoritesDialogMsg is sent when dialog should close
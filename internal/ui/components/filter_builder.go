@@ -3,6 +3,7 @@ package components
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -195,7 +196,7 @@ func (fb *FilterBuilder) handleOperatorMode(msg tea.KeyMsg) (*FilterBuilder, tea
 				Column:   fb.selectedColumn.Name,
 				Operator: selectedOp,
 				Value:    nil,
-				Type:     fb.selectedColumn.DataType,
+				Type:     fb.selectedColumn.CastType(),
 			})
 			fb.editMode = ""
 			fb.updatePreview()
@@ -214,6 +215,7 @@ func (fb *FilterBuilder) handleValueMode(msg tea.KeyMsg) (*FilterBuilder, tea.Cm
 		fb.editMode = "operator"
 		fb.valueInput = ""
 		fb.jsonbPath = ""
+		fb.validationError = ""
 	case "enter":
 		// Check if this is a JSONB operator and handle path extraction
 		selectedOp := fb.availableOps[fb.operatorIndex]
@@ -227,29 +229,78 @@ func (fb *FilterBuilder) handleValueMode(msg tea.KeyMsg) (*FilterBuilder, tea.Cm
 			}
 		}
 
-		// Add condition
-		fb.filter.RootGroup.Conditions = append(fb.filter.RootGroup.Conditions, models.FilterCondition{
+		cond := models.FilterCondition{
 			Column:   fb.selectedColumn.Name,
-			Operator: fb.availableOps[fb.operatorIndex],
+			Operator: selectedOp,
 			Value:    fb.valueInput,
-			Type:     fb.selectedColumn.DataType,
-		})
+			Type:     fb.selectedColumn.CastType(),
+		}
+		if selectedOp.TakesList() {
+			values, err := filter.ParseList(fb.valueInput)
+			if err != nil {
+				fb.validationError = err.Error()
+				return fb, nil
+			}
+			cond.Value = values
+		}
+
+		// Check the values convert to the column type before adding
+		if _, _, err := fb.builder.BuildWhere(models.Filter{
+			RootGroup: models.FilterGroup{Conditions: []models.FilterCondition{cond}},
+		}); err != nil {
+			fb.validationError = err.Error()
+			return fb, nil
+		}
+
+		// Add condition
+		fb.filter.RootGroup.Conditions = append(fb.filter.RootGroup.Conditions, cond)
 		fb.editMode = ""
 		fb.valueInput = ""
 		fb.jsonbPath = ""
+		fb.validationError = ""
 		fb.updatePreview()
+	case "ctrl+j", "alt+enter":
+		// One value per line for list operators
+		if fb.availableOps[fb.operatorIndex].TakesList() {
+			fb.valueInput += "\n"
+		}
 	case "backspace":
 		if len(fb.valueInput) > 0 {
-			fb.valueInput = fb.valueInput[:len(fb.valueInput)-1]
+			_, size := utf8.DecodeLastRuneInString(fb.valueInput)
+			fb.valueInput = fb.valueInput[:len(fb.valueInput)-size]
 		}
 	default:
-		if len(msg.String()) == 1 {
-			fb.valueInput += msg.String()
+		// Runes include pasted text, which may span several lines
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			text := string(msg.Runes)
+			if msg.Type == tea.KeySpace {
+				text = " "
+			}
+			if !fb.availableOps[fb.operatorIndex].TakesList() {
+				text = strings.ReplaceAll(text, "\n", " ")
+			}
+			fb.valueInput += text
 		}
 	}
 	return fb, nil
 }
 
+// formatConditionValue renders a condition's value for the conditions list
+func formatConditionValue(cond models.FilterCondition) string {
+	values, ok := cond.Value.([]string)
+	if !ok {
+		return fmt.Sprintf("%v", cond.Value)
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	if cond.Operator == models.OpBetween && len(quoted) == 2 {
+		return quoted[0] + " AND " + quoted[1]
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
 // updatePreview updates the SQL preview
 func (fb *FilterBuilder) updatePreview() {
 	whereClause, _, err := fb.builder.BuildWhere(fb.filter)
@@ -333,7 +384,7 @@ func (fb *FilterBuilder) HandleMouseClick(msg tea.MouseMsg) (handled bool, cmd t
 							Column:   fb.selectedColumn.Name,
 							Operator: selectedOp,
 							Value:    nil,
-							Type:     fb.selectedColumn.DataType,
+							Type:     fb.selectedColumn.CastType(),
 						})
 						fb.editMode = ""
 						fb.updatePreview()
@@ -376,7 +427,14 @@ func (fb *FilterBuilder) View() string {
 	case "operator":
 		instructions = "↑↓ Select operator, Enter to confirm, Esc to go back"
 	case "value":
-		instructions = "Type value, Enter to confirm, Esc to go back"
+		switch fb.availableOps[fb.operatorIndex] {
+		case models.OpIn, models.OpNotIn:
+			instructions = "Separate values with commas or Ctrl+J newlines, quote values containing commas, Enter to confirm"
+		case models.OpBetween:
+			instructions = "Type low, high (quote values containing commas), Enter to confirm, Esc to go back"
+		default:
+			instructions = "Type value, Enter to confirm, Esc to go back"
+		}
 	default:
 		instructions = "a=Add n=New d=Delete Enter=Apply Esc=Cancel"
 	}
@@ -395,7 +453,7 @@ func (fb *FilterBuilder) View() string {
 	if len(fb.filter.RootGroup.Conditions) > 0 {
 		sections = append(sections, "\nConditions:")
 		for i, cond := range fb.filter.RootGroup.Conditions {
-			condStr := fmt.Sprintf("%s %s %v", cond.Column, cond.Operator, cond.Value)
+			condStr := fmt.Sprintf("%s %s %s", cond.Column, cond.Operator, formatConditionValue(cond))
 			if cond.Operator == models.OpIsNull || cond.Operator == models.OpIsNotNull {
 				condStr = fmt.Sprintf("%s %s", cond.Column, cond.Operator)
 			}
@@ -432,7 +490,12 @@ func (fb *FilterBuilder) View() string {
 			}
 		case "value":
 			sections = append(sections, fmt.Sprintf("Column: %s %s", fb.selectedColumn.Name, fb.availableOps[fb.operatorIndex]))
-			sections = append(sections, fmt.Sprintf("Value: %s_", fb.valueInput))
+			label := "Value: "
+			if fb.availableOps[fb.operatorIndex].TakesList() {
+				label = "Values: "
+			}
+			lines := strings.Split(fb.valueInput+"_", "\n")
+			sections = append(sections, label+strings.Join(lines, "\n"+strings.Repeat(" ", len(label))))
 		}
 	}
 
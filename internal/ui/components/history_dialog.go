@@ -0,0 +1,670 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/history"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// HistoryPageSize is the number of entries loaded per page
+const HistoryPageSize = 100
+
+// ZoneHistoryItemPrefix is the zone ID prefix for history entries
+const ZoneHistoryItemPrefix = "history-item-"
+
+// LoadHistoryMsg requests a page of history entries
+type LoadHistoryMsg struct {
+	Filter history.Filter
+	Offset int
+	Limit  int
+}
+
+// OpenHistoryEntryMsg is sent to open a history entry in the SQL editor
+type OpenHistoryEntryMsg struct {
+	Entry history.HistoryEntry
+}
+
+// RunHistoryEntryMsg is sent to re-run a history entry
+type RunHistoryEntryMsg struct {
+	Entry history.HistoryEntry
+}
+
+// FavoriteHistoryEntryMsg is sent to save a history entry as a favorite
+type FavoriteHistoryEntryMsg struct {
+	Entry history.HistoryEntry
+}
+
+// DeleteHistoryEntryMsg is sent to delete a history entry
+type DeleteHistoryEntryMsg struct {
+	ID int
+}
+
+// PurgeHistoryMsg is sent to delete entries older than a number of days
+type PurgeHistoryMsg struct {
+	Days int
+}
+
+// CloseHistoryDialogMsg is sent when the history dialog is closed
+type CloseHistoryDialogMsg struct{}
+
+// historyStatus filters entries by outcome
+type historyStatus int
+
+const (
+	historyStatusAll historyStatus = iota
+	historyStatusSuccess
+	historyStatusFailed
+)
+
+var historyStatusLabels = []string{"any status", "succeeded", "failed"}
+
+// historyRange is a preset date range
+type historyRange struct {
+	Label string
+	Since time.Duration // Entries newer than now-Since
+	Until time.Duration // Entries older than now-Until
+}
+
+var historyRanges = []historyRange{
+	{Label: "any time"},
+	{Label: "last hour", Since: time.Hour},
+	{Label: "last 24 hours", Since: 24 * time.Hour},
+	{Label: "last 7 days", Since: 7 * 24 * time.Hour},
+	{Label: "last 30 days", Since: 30 * 24 * time.Hour},
+	{Label: "older than 30 days", Until: 30 * 24 * time.Hour},
+}
+
+// historyDuration is a preset duration range
+type historyDuration struct {
+	Label string
+	Min   time.Duration
+	Max   time.Duration
+}
+
+var historyDurations = []historyDuration{
+	{Label: "any duration"},
+	{Label: "≤ 100ms", Max: 100 * time.Millisecond},
+	{Label: "≥ 100ms", Min: 100 * time.Millisecond},
+	{Label: "≥ 1s", Min: time.Second},
+	{Label: "≥ 10s", Min: 10 * time.Second},
+}
+
+// HistoryDialog browses, searches and filters the query history
+type HistoryDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	entries []history.HistoryEntry
+	total   int
+	offset  int // Offset of the loaded page
+	loading bool
+	err     error
+
+	selected  int
+	scroll    int
+	confirmID int // Entry awaiting delete confirmation, 0 if none
+
+	search     textinput.Model
+	searching  bool
+	purge      textinput.Model
+	purging    bool
+	connection int // Index into connections, -1 for all
+	database   int // Index into databases, -1 for all
+	status     historyStatus
+	dateRange  int
+	duration   int
+
+	connections []string
+	databases   []string
+}
+
+// NewHistoryDialog creates a new history dialog
+func NewHistoryDialog(th theme.Theme) *HistoryDialog {
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "search query text"
+	search.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+	search.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+	search.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+	search.CharLimit = 256
+
+	purge := textinput.New()
+	purge.Prompt = "Delete entries older than (days): "
+	purge.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+	purge.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+	purge.CharLimit = 5
+	purge.Width = 8
+
+	return &HistoryDialog{
+		Width:      100,
+		Height:     30,
+		Theme:      th,
+		search:     search,
+		purge:      purge,
+		connection: -1,
+		database:   -1,
+	}
+}
+
+// Reset clears filters and sets the values the connection and database
+// filters cycle through, then requests the first page
+func (d *HistoryDialog) Reset(connections, databases []string) tea.Cmd {
+	d.connections = connections
+	d.databases = databases
+	d.connection = -1
+	d.database = -1
+	d.status = historyStatusAll
+	d.dateRange = 0
+	d.duration = 0
+	d.search.SetValue("")
+	d.search.Blur()
+	d.searching = false
+	d.purging = false
+	d.confirmID = 0
+	d.entries = nil
+	d.total = 0
+	d.err = nil
+	return d.load(0)
+}
+
+// SetPage shows a loaded page of entries
+func (d *HistoryDialog) SetPage(entries []history.HistoryEntry, total, offset int, err error) {
+	d.loading = false
+	d.err = err
+	if err != nil {
+		return
+	}
+	d.entries = entries
+	d.total = total
+	d.offset = offset
+	d.selected = max(0, min(d.selected, len(entries)-1))
+	d.scroll = min(d.scroll, d.selected)
+}
+
+// Reload requests the current page again, e.g. after a delete
+func (d *HistoryDialog) Reload() tea.Cmd {
+	offset := d.offset
+	if offset > 0 && len(d.entries) <= 1 {
+		// The only entry on this page is gone
+		offset = max(0, offset-HistoryPageSize)
+	}
+	return d.load(offset)
+}
+
+// IsInputActive reports whether the search or purge input has focus
+func (d *HistoryDialog) IsInputActive() bool {
+	return d.searching || d.purging
+}
+
+// Filter builds the store filter from the current filter settings
+func (d *HistoryDialog) Filter() history.Filter {
+	return d.filterAt(time.Now())
+}
+
+func (d *HistoryDialog) filterAt(now time.Time) history.Filter {
+	filter := history.Filter{Text: strings.TrimSpace(d.search.Value())}
+	if d.connection >= 0 && d.connection < len(d.connections) {
+		filter.ConnectionName = d.connections[d.connection]
+	}
+	if d.database >= 0 && d.database < len(d.databases) {
+		filter.DatabaseName = d.databases[d.database]
+	}
+	switch d.status {
+	case historyStatusSuccess:
+		success := true
+		filter.Success = &success
+	case historyStatusFailed:
+		success := false
+		filter.Success = &success
+	}
+	if r := historyRanges[d.dateRange]; r.Since > 0 {
+		filter.Since = now.Add(-r.Since)
+	} else if r.Until > 0 {
+		filter.Until = now.Add(-r.Until)
+	}
+	filter.MinDuration = historyDurations[d.duration].Min
+	filter.MaxDuration = historyDurations[d.duration].Max
+	return filter
+}
+
+// load requests the page starting at offset
+func (d *HistoryDialog) load(offset int) tea.Cmd {
+	d.loading = true
+	msg := LoadHistoryMsg{Filter: d.Filter(), Offset: offset, Limit: HistoryPageSize}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// refilter reloads from the first page after a filter change
+func (d *HistoryDialog) refilter() tea.Cmd {
+	d.selected = 0
+	d.scroll = 0
+	d.confirmID = 0
+	return d.load(0)
+}
+
+// current returns the selected entry
+func (d *HistoryDialog) current() (history.HistoryEntry, bool) {
+	if d.selected < 0 || d.selected >= len(d.entries) {
+		return history.HistoryEntry{}, false
+	}
+	return d.entries[d.selected], true
+}
+
+// Update handles messages
+func (d *HistoryDialog) Update(msg tea.Msg) (*HistoryDialog, tea.Cmd) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+
+	if d.purging {
+		if isKey {
+			switch keyMsg.String() {
+			case "esc":
+				d.purging = false
+				d.purge.Blur()
+				return d, nil
+			case "enter":
+				days, err := strconv.Atoi(strings.TrimSpace(d.purge.Value()))
+				if err != nil || days < 0 {
+					d.err = fmt.Errorf("enter a number of days")
+					return d, nil
+				}
+				d.purging = false
+				d.purge.Blur()
+				d.err = nil
+				return d, func() tea.Msg {
+					return PurgeHistoryMsg{Days: days}
+				}
+			}
+		}
+		var cmd tea.Cmd
+		d.purge, cmd = d.purge.Update(msg)
+		return d, cmd
+	}
+
+	if d.searching {
+		if isKey {
+			switch keyMsg.String() {
+			case "esc", "enter", "down", "up":
+				d.searching = false
+				d.search.Blur()
+				return d, nil
+			}
+		}
+		before := d.search.Value()
+		var cmd tea.Cmd
+		d.search, cmd = d.search.Update(msg)
+		if d.search.Value() != before {
+			return d, tea.Batch(cmd, d.refilter())
+		}
+		return d, cmd
+	}
+
+	if !isKey {
+		return d, nil
+	}
+
+	key := keyMsg.String()
+	if key != "d" && key != "delete" {
+		d.confirmID = 0
+	}
+
+	switch key {
+	case "esc", "q":
+		return d, func() tea.Msg {
+			return CloseHistoryDialogMsg{}
+		}
+	case "/":
+		d.searching = true
+		d.search.Focus()
+		d.search.CursorEnd()
+		return d, textinput.Blink
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		} else if d.offset > 0 {
+			d.selected = HistoryPageSize - 1
+			return d, d.load(max(0, d.offset-HistoryPageSize))
+		}
+	case "down", "j":
+		if d.selected < len(d.entries)-1 {
+			d.selected++
+		} else if d.offset+len(d.entries) < d.total {
+			d.selected = 0
+			d.scroll = 0
+			return d, d.load(d.offset + HistoryPageSize)
+		}
+	case "g", "home":
+		d.selected = 0
+	case "G", "end":
+		d.selected = max(0, len(d.entries)-1)
+	case "]", "pgdown":
+		if d.offset+len(d.entries) < d.total {
+			d.selected = 0
+			d.scroll = 0
+			return d, d.load(d.offset + HistoryPageSize)
+		}
+	case "[", "pgup":
+		if d.offset > 0 {
+			d.selected = 0
+			d.scroll = 0
+			return d, d.load(max(0, d.offset-HistoryPageSize))
+		}
+	case "c":
+		d.connection = cycleIndex(d.connection, len(d.connections))
+		return d, d.refilter()
+	case "b":
+		d.database = cycleIndex(d.database, len(d.databases))
+		return d, d.refilter()
+	case "s":
+		d.status = (d.status + 1) % historyStatus(len(historyStatusLabels))
+		return d, d.refilter()
+	case "t":
+		d.dateRange = (d.dateRange + 1) % len(historyRanges)
+		return d, d.refilter()
+	case "u":
+		d.duration = (d.duration + 1) % len(historyDurations)
+		return d, d.refilter()
+	case "F":
+		d.search.SetValue("")
+		d.connection, d.database = -1, -1
+		d.status, d.dateRange, d.duration = historyStatusAll, 0, 0
+		return d, d.refilter()
+	case "P":
+		d.purging = true
+		d.purge.SetValue("")
+		d.purge.Focus()
+		return d, textinput.Blink
+	case "enter", "e":
+		if entry, ok := d.current(); ok {
+			return d, func() tea.Msg {
+				return OpenHistoryEntryMsg{Entry: entry}
+			}
+		}
+	case "r":
+		if entry, ok := d.current(); ok {
+			return d, func() tea.Msg {
+				return RunHistoryEntryMsg{Entry: entry}
+			}
+		}
+	case "f":
+		if entry, ok := d.current(); ok {
+			return d, func() tea.Msg {
+				return FavoriteHistoryEntryMsg{Entry: entry}
+			}
+		}
+	case "d", "delete":
+		entry, ok := d.current()
+		if !ok {
+			return d, nil
+		}
+		if d.confirmID != entry.ID {
+			// First press asks for confirmation
+			d.confirmID = entry.ID
+			return d, nil
+		}
+		d.confirmID = 0
+		return d, func() tea.Msg {
+			return DeleteHistoryEntryMsg{ID: entry.ID}
+		}
+	}
+	return d, nil
+}
+
+// cycleIndex steps through -1 (all) and 0..n-1
+func cycleIndex(index, n int) int {
+	if index+1 >= n {
+		return -1
+	}
+	return index + 1
+}
+
+// HandleMouseWheel scrolls the entry list
+func (d *HistoryDialog) HandleMouseWheel(msg tea.MouseMsg) bool {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		d.selected = max(0, d.selected-3)
+		return true
+	case tea.MouseButtonWheelDown:
+		d.selected = max(0, min(len(d.entries)-1, d.selected+3))
+		return true
+	}
+	return false
+}
+
+// HandleMouseClick selects an entry; clicking the selected entry opens it
+func (d *HistoryDialog) HandleMouseClick(msg tea.MouseMsg) (bool, tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+	for i := range d.entries {
+		if !zone.Get(fmt.Sprintf("%s%d", ZoneHistoryItemPrefix, i)).InBounds(msg) {
+			continue
+		}
+		if i == d.selected {
+			entry := d.entries[i]
+			return true, func() tea.Msg {
+				return OpenHistoryEntryMsg{Entry: entry}
+			}
+		}
+		d.selected = i
+		d.confirmID = 0
+		return true, nil
+	}
+	return false, nil
+}
+
+// View renders the dialog
+func (d *HistoryDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(d.Theme.Info).Padding(0, 1)
+	footerStyle := lipgloss.NewStyle().Faint(true).Foreground(d.Theme.Foreground).Padding(0, 1)
+	descStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground).Faint(true)
+	filterStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	activeFilterStyle := lipgloss.NewStyle().Foreground(d.Theme.Warning).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(d.Theme.Error)
+
+	width := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Query History"))
+	content.WriteString("\n\n")
+
+	// Search and filters
+	d.search.Width = max(20, width-4)
+	content.WriteString(d.search.View())
+	content.WriteString("\n")
+
+	connection, database := "all connections", "all databases"
+	if d.connection >= 0 && d.connection < len(d.connections) {
+		connection = d.connections[d.connection]
+	}
+	if d.database >= 0 && d.database < len(d.databases) {
+		database = d.databases[d.database]
+	}
+	filters := []struct {
+		key, label string
+		active     bool
+	}{
+		{"c", connection, d.connection >= 0},
+		{"b", database, d.database >= 0},
+		{"s", historyStatusLabels[d.status], d.status != historyStatusAll},
+		{"t", historyRanges[d.dateRange].Label, d.dateRange != 0},
+		{"u", historyDurations[d.duration].Label, d.duration != 0},
+	}
+	var parts []string
+	for _, f := range filters {
+		style := filterStyle
+		if f.active {
+			style = activeFilterStyle
+		}
+		parts = append(parts, descStyle.Render(f.key+":")+style.Render(f.label))
+	}
+	content.WriteString(strings.Join(parts, "  "))
+	content.WriteString("\n\n")
+
+	// Entry list
+	const detailLines = 8
+	visible := max(3, d.Height-detailLines-12)
+	if d.selected < d.scroll {
+		d.scroll = d.selected
+	}
+	if d.selected >= d.scroll+visible {
+		d.scroll = d.selected - visible + 1
+	}
+
+	switch {
+	case d.err != nil && len(d.entries) == 0:
+		content.WriteString(errorStyle.Render(d.err.Error()))
+		content.WriteString("\n")
+	case len(d.entries) == 0 && d.loading:
+		content.WriteString(descStyle.Render("Loading..."))
+		content.WriteString("\n")
+	case len(d.entries) == 0:
+		content.WriteString(descStyle.Render("No queries match the current filters."))
+		content.WriteString("\n")
+	default:
+		end := min(len(d.entries), d.scroll+visible)
+		for i := d.scroll; i < end; i++ {
+			line := d.renderEntry(d.entries[i], i == d.selected, width)
+			content.WriteString(zone.Mark(fmt.Sprintf("%s%d", ZoneHistoryItemPrefix, i), line))
+			content.WriteString("\n")
+		}
+		content.WriteString(descStyle.Render(fmt.Sprintf("%d-%d of %d", d.offset+d.scroll+1, d.offset+end, d.total)))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	// Details of the selected entry
+	if entry, ok := d.current(); ok {
+		content.WriteString(d.renderDetails(entry, width, detailLines))
+	}
+
+	if d.purging {
+		content.WriteString(d.purge.View())
+		content.WriteString("\n")
+	} else if d.confirmID != 0 {
+		content.WriteString(errorStyle.Bold(true).Render("⚠ Press 'd' again to delete this entry"))
+		content.WriteString("\n")
+	}
+	if d.err != nil && len(d.entries) > 0 {
+		content.WriteString(errorStyle.Render(d.err.Error()))
+		content.WriteString("\n")
+	}
+
+	footer := "Enter: Open in editor  │  r: Run  │  f: Favorite  │  d: Delete  │  P: Purge  │  /: Search  │  F: Clear filters  │  Esc: Close"
+	if d.searching {
+		footer = "Type to search  │  Enter/Esc: Done"
+	}
+	content.WriteString(footerStyle.Render(runewidth.Truncate(footer, width, "…")))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
+
+// renderEntry renders one line of the entry list
+func (d *HistoryDialog) renderEntry(entry history.HistoryEntry, selected bool, width int) string {
+	status := lipgloss.NewStyle().Foreground(d.Theme.Success).Render("✓")
+	if !entry.Success {
+		status = lipgloss.NewStyle().Foreground(d.Theme.Error).Render("✗")
+	}
+
+	when := entry.ExecutedAt.Local().Format("01-02 15:04")
+	duration := formatHistoryDuration(entry.Duration)
+	prefix := fmt.Sprintf("%s  %7s  ", when, duration)
+
+	query := strings.Join(strings.Fields(entry.Query), " ")
+	query = runewidth.Truncate(query, max(10, width-runewidth.StringWidth(prefix)-4), "…")
+
+	if selected {
+		line := runewidth.FillRight(prefix+query, max(10, width-4))
+		return "▸ " + status + " " + lipgloss.NewStyle().Foreground(d.Theme.BorderFocused).Bold(true).Render(line)
+	}
+	return "  " + status + " " + lipgloss.NewStyle().Foreground(d.Theme.Metadata).Render(prefix) +
+		lipgloss.NewStyle().Foreground(d.Theme.Foreground).Render(query)
+}
+
+// renderDetails renders the selected entry's metadata, bound values and
+// full query
+func (d *HistoryDialog) renderDetails(entry history.HistoryEntry, width, lines int) string {
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Info)
+	metaStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	queryStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	errorStyle := lipgloss.NewStyle().Foreground(d.Theme.Error)
+
+	var b strings.Builder
+	meta := fmt.Sprintf("%s · %s/%s · %s · %d row(s)",
+		entry.ExecutedAt.Local().Format("2006-01-02 15:04:05"),
+		valueOr(entry.ConnectionName, "-"), valueOr(entry.DatabaseName, "-"),
+		formatHistoryDuration(entry.Duration), entry.RowsAffected)
+	b.WriteString(labelStyle.Render("Details: "))
+	b.WriteString(metaStyle.Render(runewidth.Truncate(meta, max(10, width-10), "…")))
+	b.WriteString("\n")
+
+	used := 0
+	if entry.ErrorMessage != "" {
+		b.WriteString(errorStyle.Render(runewidth.Truncate("Error: "+entry.ErrorMessage, width, "…")))
+		b.WriteString("\n")
+		used++
+	}
+	if entry.Parameters != "" {
+		b.WriteString(labelStyle.Render("Values: "))
+		b.WriteString(metaStyle.Render(runewidth.Truncate(entry.Parameters, max(10, width-8), "…")))
+		b.WriteString("\n")
+		used++
+	}
+
+	queryLines := strings.Split(strings.TrimSpace(entry.Query), "\n")
+	for i, line := range queryLines {
+		if used >= lines-1 {
+			break
+		}
+		if used == lines-2 && i < len(queryLines)-1 {
+			line = "…"
+		}
+		b.WriteString(queryStyle.Render("  " + runewidth.Truncate(strings.ReplaceAll(line, "\t", "  "), width-2, "…")))
+		b.WriteString("\n")
+		used++
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// formatHistoryDuration formats a duration compactly for the entry list
+func formatHistoryDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rebelice/lazypg/internal/history"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestHistoryDialogFilter(t *testing.T) {
+	d := NewHistoryDialog(theme.DefaultTheme())
+	d.Reset([]string{"local", "prod"}, []string{"app"})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if f := d.filterAt(now); f.ConnectionName != "" || f.Success != nil || !f.Since.IsZero() || f.MinDuration != 0 {
+		t.Fatalf("expected empty filter, got %+v", f)
+	}
+
+	for _, key := range []string{"c", "c", "b", "s", "s", "t", "u", "u"} {
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	f := d.filterAt(now)
+	if f.ConnectionName != "prod" || f.DatabaseName != "app" {
+		t.Errorf("unexpected connection/database filter: %+v", f)
+	}
+	if f.Success == nil || *f.Success {
+		t.Errorf("expected failed-only filter, got %v", f.Success)
+	}
+	if !f.Since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected last hour, got since %v", f.Since)
+	}
+	if f.MinDuration != 100*time.Millisecond || f.MaxDuration != 0 {
+		t.Errorf("unexpected duration filter: %v-%v", f.MinDuration, f.MaxDuration)
+	}
+
+	// Cycling past the last connection returns to all connections
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if f := d.filterAt(now); f.ConnectionName != "" {
+		t.Errorf("expected all connections, got %q", f.ConnectionName)
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	if f := d.filterAt(now); f.DatabaseName != "" || f.Success != nil || !f.Since.IsZero() || f.MinDuration != 0 {
+		t.Errorf("expected filters cleared, got %+v", f)
+	}
+}
+
+func TestHistoryDialogDeleteNeedsConfirmation(t *testing.T) {
+	d := NewHistoryDialog(theme.DefaultTheme())
+	d.SetPage([]history.HistoryEntry{{ID: 7, Query: "DELETE FROM users"}}, 1, 0, nil)
+
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}); cmd != nil {
+		t.Fatal("first delete press should only ask for confirmation")
+	}
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if cmd == nil {
+		t.Fatal("second delete press should delete")
+	}
+	if msg, ok := cmd().(DeleteHistoryEntryMsg); !ok || msg.ID != 7 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
@@ -0,0 +1,512 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/importer"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// importPreviewRows is the number of file rows shown in the preview
+const importPreviewRows = 5
+
+// LoadImportFileMsg is sent when the user picks a file to import
+type LoadImportFileMsg struct {
+	Path string
+}
+
+// StartImportMsg is sent when the user confirms the column mapping
+type StartImportMsg struct {
+	ObjectID string
+	Schema   string
+	Table    string
+	Data     *importer.Data
+	Mappings []importer.Mapping
+}
+
+// CancelImportMsg is sent when the user cancels a running import
+type CancelImportMsg struct{}
+
+// CloseImportDialogMsg is sent when the import dialog is closed
+type CloseImportDialogMsg struct {
+	ObjectID string
+	Imported bool // True if any rows were inserted
+}
+
+// importStage is the current step of the import dialog
+type importStage int
+
+const (
+	importStageFile importStage = iota
+	importStageMapping
+	importStageRunning
+	importStageResult
+)
+
+// ImportDialog walks through picking a file, mapping its columns to the
+// table columns, and reporting the result of the import
+type ImportDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	stage    importStage
+	objectID string
+	schema   string
+	table    string
+	columns  []models.ColumnDetail
+	input    textinput.Model
+	errorMsg string
+
+	// Mapping stage
+	path       string
+	data       *importer.Data
+	assignment []int // Table column index per file column, -1 to skip
+	selected   int
+	offset     int
+
+	// Progress and result
+	done      int
+	total     int
+	inserted  int64
+	rowErrors []importer.RowError
+	err       error
+	errOffset int
+}
+
+// NewImportDialog creates a new import dialog
+func NewImportDialog(th theme.Theme) *ImportDialog {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = "path to .csv, .json or .ndjson file"
+	input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+	input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+	input.CharLimit = 512
+	input.Width = 50
+
+	return &ImportDialog{
+		Theme:  th,
+		Width:  100,
+		Height: 30,
+		input:  input,
+	}
+}
+
+// SetTable resets the dialog for importing into a table
+func (d *ImportDialog) SetTable(objectID, schema, table string, columns []models.ColumnDetail) {
+	d.stage = importStageFile
+	d.objectID = objectID
+	d.schema = schema
+	d.table = table
+	d.columns = columns
+	d.errorMsg = ""
+	d.data = nil
+	d.assignment = nil
+	d.rowErrors = nil
+	d.err = nil
+	d.input.Focus()
+	d.input.CursorEnd()
+}
+
+// SetData shows the parsed file and auto-maps its columns by name
+func (d *ImportDialog) SetData(path string, data *importer.Data) {
+	d.path = path
+	d.data = data
+	d.assignment = importer.AutoMap(data.Columns, d.columns)
+	d.selected = 0
+	d.offset = 0
+	d.errorMsg = ""
+	d.stage = importStageMapping
+	d.input.Blur()
+}
+
+// SetFileError shows an error for the selected file
+func (d *ImportDialog) SetFileError(err error) {
+	d.errorMsg = err.Error()
+}
+
+// SetProgress updates the progress of a running import
+func (d *ImportDialog) SetProgress(done, total int) {
+	d.done = done
+	d.total = total
+}
+
+// SetResult shows the outcome of an import
+func (d *ImportDialog) SetResult(inserted int64, rowErrors []importer.RowError, err error) {
+	d.stage = importStageResult
+	d.inserted = inserted
+	d.rowErrors = rowErrors
+	d.err = err
+	d.errOffset = 0
+}
+
+// IsRunning returns true while rows are being copied
+func (d *ImportDialog) IsRunning() bool {
+	return d.stage == importStageRunning
+}
+
+// Init initializes the dialog
+func (d *ImportDialog) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (d *ImportDialog) Update(msg tea.Msg) (*ImportDialog, tea.Cmd) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+
+	switch d.stage {
+	case importStageFile:
+		if isKey {
+			switch keyMsg.String() {
+			case "esc":
+				return d, d.close()
+			case "enter":
+				path := strings.TrimSpace(d.input.Value())
+				if path == "" {
+					return d, nil
+				}
+				d.errorMsg = ""
+				return d, func() tea.Msg {
+					return LoadImportFileMsg{Path: path}
+				}
+			}
+		}
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		return d, cmd
+
+	case importStageMapping:
+		if !isKey {
+			return d, nil
+		}
+		switch keyMsg.String() {
+		case "esc":
+			// Back to file selection
+			d.stage = importStageFile
+			d.input.Focus()
+			return d, textinput.Blink
+		case "up", "k":
+			d.selected = max(0, d.selected-1)
+		case "down", "j":
+			d.selected = min(len(d.assignment)-1, d.selected+1)
+		case "left", "h":
+			d.cycleTarget(-1)
+		case "right", "l", " ":
+			d.cycleTarget(1)
+		case "x", "delete":
+			if d.selected < len(d.assignment) {
+				d.assignment[d.selected] = -1
+			}
+		case "enter":
+			return d, d.start()
+		}
+		return d, nil
+
+	case importStageRunning:
+		if isKey && (keyMsg.String() == "esc" || keyMsg.String() == "ctrl+c") {
+			return d, func() tea.Msg {
+				return CancelImportMsg{}
+			}
+		}
+		return d, nil
+
+	case importStageResult:
+		if !isKey {
+			return d, nil
+		}
+		switch keyMsg.String() {
+		case "esc", "enter", "q":
+			return d, d.close()
+		case "up", "k":
+			d.errOffset = max(0, d.errOffset-1)
+		case "down", "j":
+			d.errOffset = max(0, min(len(d.rowErrors)-1, d.errOffset+1))
+		}
+	}
+	return d, nil
+}
+
+// close emits a CloseImportDialogMsg
+func (d *ImportDialog) close() tea.Cmd {
+	msg := CloseImportDialogMsg{
+		ObjectID: d.objectID,
+		Imported: d.stage == importStageResult && d.inserted > 0,
+	}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// cycleTarget moves the selected file column to the next unused table column
+func (d *ImportDialog) cycleTarget(delta int) {
+	if d.selected >= len(d.assignment) {
+		return
+	}
+
+	used := make(map[int]bool)
+	for i, target := range d.assignment {
+		if i != d.selected && target >= 0 {
+			used[target] = true
+		}
+	}
+
+	// Positions run from -1 (skip) to len(columns)-1
+	count := len(d.columns) + 1
+	pos := d.assignment[d.selected]
+	for range count {
+		pos = (pos+1+delta+count)%count - 1
+		if pos < 0 || !used[pos] {
+			break
+		}
+	}
+	d.assignment[d.selected] = pos
+}
+
+// start validates the mapping and emits a StartImportMsg
+func (d *ImportDialog) start() tea.Cmd {
+	mappings := importer.BuildMappings(d.assignment, d.columns)
+	if len(mappings) == 0 {
+		d.errorMsg = "Map at least one column"
+		return nil
+	}
+	d.errorMsg = ""
+	d.stage = importStageRunning
+	d.done = 0
+	d.total = len(d.data.Rows)
+
+	msg := StartImportMsg{
+		ObjectID: d.objectID,
+		Schema:   d.schema,
+		Table:    d.table,
+		Data:     d.data,
+		Mappings: mappings,
+	}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// View renders the dialog
+func (d *ImportDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	contentWidth := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(fmt.Sprintf("Import into %s.%s", d.schema, d.table)))
+	content.WriteString("\n\n")
+
+	var footer string
+	switch d.stage {
+	case importStageFile:
+		d.renderFileStage(&content, contentWidth)
+		footer = "Enter: Load file  │  Esc: Cancel"
+	case importStageMapping:
+		d.renderMappingStage(&content, contentWidth)
+		footer = "↑↓: Column  │  ←→: Target  │  x: Skip  │  Enter: Import  │  Esc: Back"
+	case importStageRunning:
+		d.renderProgress(&content, contentWidth)
+		footer = "Esc: Cancel import"
+	case importStageResult:
+		d.renderResult(&content, contentWidth)
+		footer = "↑↓: Scroll errors  │  Enter/Esc: Close"
+	}
+
+	if d.errorMsg != "" {
+		content.WriteString(lipgloss.NewStyle().Foreground(d.Theme.Error).Render(d.errorMsg))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render(footer))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
+
+// renderFileStage renders the file path input
+func (d *ImportDialog) renderFileStage(content *strings.Builder, width int) {
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Info).Padding(0, 1)
+	descStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground).Faint(true).Padding(0, 1)
+
+	content.WriteString(descStyle.Render("CSV files need a header row. JSON files must contain an array of objects."))
+	content.WriteString("\n\n")
+	content.WriteString(labelStyle.Render("File:"))
+	content.WriteString("\n  ")
+	d.input.Width = max(20, width-4)
+	content.WriteString(d.input.View())
+	content.WriteString("\n\n")
+}
+
+// renderMappingStage renders the file preview and the column mapping
+func (d *ImportDialog) renderMappingStage(content *strings.Builder, width int) {
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Info)
+	descStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground).Faint(true)
+	nameStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	selectedStyle := lipgloss.NewStyle().Foreground(d.Theme.BorderFocused).Bold(true)
+	typeStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	skipStyle := lipgloss.NewStyle().Foreground(d.Theme.Comment).Italic(true)
+	warnStyle := lipgloss.NewStyle().Foreground(d.Theme.Warning)
+
+	previewCount := min(importPreviewRows, len(d.data.Rows))
+	content.WriteString(descStyle.Render(fmt.Sprintf("%s · %s · %d row(s)", d.path, strings.ToUpper(string(d.data.Format)), len(d.data.Rows))))
+	content.WriteString("\n\n")
+
+	// Preview of the first rows
+	content.WriteString(labelStyle.Render(fmt.Sprintf("Preview (first %d rows):", previewCount)))
+	content.WriteString("\n")
+	cellWidth := max(6, min(20, (width-2)/max(1, len(d.data.Columns))-3))
+	previewLine := func(cells []string, style lipgloss.Style) {
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			cell = strings.ReplaceAll(cell, "\n", "↵")
+			parts[i] = runewidth.FillRight(runewidth.Truncate(cell, cellWidth, "…"), cellWidth)
+		}
+		line := runewidth.Truncate(strings.Join(parts, " │ "), width-2, "…")
+		content.WriteString("  " + style.Render(line) + "\n")
+	}
+	previewLine(d.data.Columns, typeStyle)
+	for _, row := range d.data.Rows[:previewCount] {
+		cells := make([]string, len(row))
+		for i, field := range row {
+			if field.Null {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = field.Value
+			}
+		}
+		previewLine(cells, nameStyle)
+	}
+	content.WriteString("\n")
+
+	// Column mapping
+	content.WriteString(labelStyle.Render("Column mapping:"))
+	content.WriteString("\n")
+
+	fileWidth := 4
+	for _, col := range d.data.Columns {
+		fileWidth = max(fileWidth, runewidth.StringWidth(col))
+	}
+	fileWidth = min(fileWidth, width/3)
+
+	visible := max(3, d.Height-previewCount-20)
+	if d.selected < d.offset {
+		d.offset = d.selected
+	}
+	if d.selected >= d.offset+visible {
+		d.offset = d.selected - visible + 1
+	}
+	end := min(len(d.data.Columns), d.offset+visible)
+
+	for i := d.offset; i < end; i++ {
+		indicator := "  "
+		style := nameStyle
+		if i == d.selected {
+			indicator = "▸ "
+			style = selectedStyle
+		}
+		name := runewidth.FillRight(runewidth.Truncate(d.data.Columns[i], fileWidth, "…"), fileWidth)
+
+		var target string
+		if idx := d.assignment[i]; idx >= 0 {
+			col := d.columns[idx]
+			target = style.Render(col.Name) + " " + typeStyle.Render(col.DataType)
+			if bad := d.previewTypeErrors(i, col.DataType); bad > 0 {
+				target += " " + warnStyle.Render(fmt.Sprintf("⚠ %d of %d preview value(s) invalid", bad, previewCount))
+			}
+		} else {
+			target = skipStyle.Render("(skip)")
+		}
+		content.WriteString(fmt.Sprintf("%s%s  →  %s\n", indicator, style.Render(name), target))
+	}
+	if len(d.data.Columns) > visible {
+		content.WriteString(descStyle.Render(fmt.Sprintf("  %d-%d of %d columns", d.offset+1, end, len(d.data.Columns))))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+}
+
+// previewTypeErrors counts preview values of a file column that fail the type check
+func (d *ImportDialog) previewTypeErrors(fileCol int, dataType string) int {
+	count := 0
+	for _, row := range d.data.Rows[:min(importPreviewRows, len(d.data.Rows))] {
+		if fileCol < len(row) && !row[fileCol].Null && importer.CheckValue(dataType, row[fileCol].Value) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// renderProgress renders a progress bar for a running import
+func (d *ImportDialog) renderProgress(content *strings.Builder, width int) {
+	barWidth := max(10, min(60, width-20))
+	filled := 0
+	if d.total > 0 {
+		filled = min(barWidth, d.done*barWidth/d.total)
+	}
+	bar := lipgloss.NewStyle().Foreground(d.Theme.Info).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(d.Theme.Border).Render(strings.Repeat("░", barWidth-filled))
+
+	content.WriteString(fmt.Sprintf("Copying rows from %s\n\n", d.path))
+	content.WriteString(fmt.Sprintf("%s  %d / %d\n\n", bar, d.done, d.total))
+}
+
+// renderResult renders the import summary and the per-row error report
+func (d *ImportDialog) renderResult(content *strings.Builder, width int) {
+	successStyle := lipgloss.NewStyle().Foreground(d.Theme.Success).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(d.Theme.Error)
+	rowStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	descStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground).Faint(true)
+
+	if d.err != nil {
+		content.WriteString(errorStyle.Render(wrapText("Import failed: "+d.err.Error(), width)))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(successStyle.Render(fmt.Sprintf("Imported %d of %d row(s)", d.inserted, len(d.data.Rows))))
+		content.WriteString("\n")
+	}
+
+	if len(d.rowErrors) == 0 {
+		content.WriteString("\n")
+		return
+	}
+
+	content.WriteString("\n")
+	content.WriteString(errorStyle.Render(fmt.Sprintf("%d row(s) skipped:", len(d.rowErrors))))
+	content.WriteString("\n")
+
+	visible := max(3, d.Height-14)
+	end := min(len(d.rowErrors), d.errOffset+visible)
+	for _, rowErr := range d.rowErrors[d.errOffset:end] {
+		content.WriteString("  " + rowStyle.Render(runewidth.Truncate(rowErr.String(), width-2, "…")) + "\n")
+	}
+	if len(d.rowErrors) > visible {
+		content.WriteString(descStyle.Render(fmt.Sprintf("  %d-%d of %d errors", d.errOffset+1, end, len(d.rowErrors))))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+}
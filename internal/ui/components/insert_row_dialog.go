@@ -0,0 +1,301 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/db/edit"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// InsertRowMsg is sent when a new row should be inserted
+type InsertRowMsg struct {
+	ObjectID  string
+	Statement edit.Statement
+}
+
+// CloseInsertRowMsg is sent when the insert dialog is cancelled
+type CloseInsertRowMsg struct{}
+
+// InsertRowDialog is a form for entering the values of a new row
+type InsertRowDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	objectID string
+	schema   string
+	table    string
+	columns  []models.ColumnDetail
+	inputs   []textinput.Model
+	isNull   []bool
+	focus    int
+	offset   int // First visible field
+	errorMsg string
+}
+
+// NewInsertRowDialog creates a new insert row dialog
+func NewInsertRowDialog(th theme.Theme) *InsertRowDialog {
+	return &InsertRowDialog{
+		Theme:  th,
+		Width:  90,
+		Height: 30,
+	}
+}
+
+// SetTable prepares the form for a table, pre-filling literal column defaults
+func (d *InsertRowDialog) SetTable(objectID, schema, table string, columns []models.ColumnDetail) {
+	d.objectID = objectID
+	d.schema = schema
+	d.table = table
+	d.columns = columns
+	d.inputs = make([]textinput.Model, len(columns))
+	d.isNull = make([]bool, len(columns))
+	d.focus = 0
+	d.offset = 0
+	d.errorMsg = ""
+
+	for i, col := range columns {
+		input := textinput.New()
+		input.Prompt = ""
+		input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+		input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cdd6f4"))
+		input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#f38ba8"))
+		input.Width = 30
+
+		if hasColumnDefault(col) {
+			if value, ok := edit.LiteralDefault(col.DefaultValue); ok {
+				input.SetValue(value)
+			} else {
+				input.Placeholder = "DEFAULT " + col.DefaultValue
+			}
+		} else if col.IsNullable {
+			input.Placeholder = "NULL"
+		} else {
+			input.Placeholder = "required"
+		}
+
+		d.inputs[i] = input
+	}
+
+	if len(d.inputs) > 0 {
+		d.inputs[0].Focus()
+	}
+}
+
+// hasColumnDefault returns true if the column has a default expression
+func hasColumnDefault(col models.ColumnDetail) bool {
+	return col.DefaultValue != "" && col.DefaultValue != "-"
+}
+
+// Init initializes the dialog
+func (d *InsertRowDialog) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (d *InsertRowDialog) Update(msg tea.Msg) (*InsertRowDialog, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			return d, func() tea.Msg {
+				return CloseInsertRowMsg{}
+			}
+		case "enter", "ctrl+s":
+			return d, d.submit()
+		case "tab", "down":
+			d.moveFocus(1)
+			return d, nil
+		case "shift+tab", "backtab", "up":
+			d.moveFocus(-1)
+			return d, nil
+		case "ctrl+n":
+			// Toggle explicit NULL for the focused column
+			if d.focus < len(d.isNull) {
+				d.isNull[d.focus] = !d.isNull[d.focus]
+			}
+			return d, nil
+		}
+	}
+
+	if d.focus >= len(d.inputs) {
+		return d, nil
+	}
+
+	var cmd tea.Cmd
+	d.inputs[d.focus], cmd = d.inputs[d.focus].Update(msg)
+	// Typing a value clears an explicit NULL
+	if _, ok := msg.(tea.KeyMsg); ok && d.inputs[d.focus].Value() != "" {
+		d.isNull[d.focus] = false
+	}
+	return d, cmd
+}
+
+// moveFocus moves the focus by delta fields, wrapping around
+func (d *InsertRowDialog) moveFocus(delta int) {
+	if len(d.inputs) == 0 {
+		return
+	}
+	d.inputs[d.focus].Blur()
+	d.focus = (d.focus + delta + len(d.inputs)) % len(d.inputs)
+	d.inputs[d.focus].Focus()
+}
+
+// Values returns the column values to insert.
+// Empty fields are omitted so the column gets its default value.
+func (d *InsertRowDialog) Values() []edit.ColumnValue {
+	var values []edit.ColumnValue
+	for i, col := range d.columns {
+		if d.isNull[i] {
+			values = append(values, edit.ColumnValue{Column: col.Name, IsNull: true})
+			continue
+		}
+		value := d.inputs[i].Value()
+		if value == "" {
+			continue
+		}
+		values = append(values, edit.ColumnValue{Column: col.Name, Value: value})
+	}
+	return values
+}
+
+// submit validates the form and emits an InsertRowMsg
+func (d *InsertRowDialog) submit() tea.Cmd {
+	for i, col := range d.columns {
+		if d.isNull[i] && !col.IsNullable {
+			d.errorMsg = fmt.Sprintf("%s cannot be NULL", col.Name)
+			return nil
+		}
+		if !d.isNull[i] && d.inputs[i].Value() == "" && !col.IsNullable && !hasColumnDefault(col) {
+			d.errorMsg = fmt.Sprintf("%s is required", col.Name)
+			return nil
+		}
+	}
+	d.errorMsg = ""
+
+	msg := InsertRowMsg{
+		ObjectID:  d.objectID,
+		Statement: edit.BuildInsert(d.schema, d.table, d.Values()),
+	}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// View renders the dialog
+func (d *InsertRowDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground)
+
+	typeStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Metadata)
+
+	nullStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Comment).
+		Italic(true)
+
+	sqlStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground).
+		Faint(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Error)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	contentWidth := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	// Column widths for the name and type labels
+	nameWidth, typeWidth := 4, 4
+	for _, col := range d.columns {
+		nameWidth = max(nameWidth, runewidth.StringWidth(col.Name))
+		typeWidth = max(typeWidth, runewidth.StringWidth(col.DataType))
+	}
+	nameWidth = min(nameWidth, contentWidth/4)
+	typeWidth = min(typeWidth, contentWidth/4)
+	inputWidth := max(10, contentWidth-nameWidth-typeWidth-8)
+
+	// Keep the focused field visible
+	visible := max(3, d.Height-14)
+	if d.focus < d.offset {
+		d.offset = d.focus
+	}
+	if d.focus >= d.offset+visible {
+		d.offset = d.focus - visible + 1
+	}
+	end := min(len(d.columns), d.offset+visible)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(fmt.Sprintf("Insert Row · %s.%s", d.schema, d.table)))
+	content.WriteString("\n\n")
+
+	for i := d.offset; i < end; i++ {
+		col := d.columns[i]
+		indicator := "  "
+		if i == d.focus {
+			indicator = "▸ "
+		}
+
+		name := runewidth.FillRight(runewidth.Truncate(col.Name, nameWidth, "…"), nameWidth)
+		dataType := runewidth.FillRight(runewidth.Truncate(col.DataType, typeWidth, "…"), typeWidth)
+
+		var value string
+		if d.isNull[i] {
+			value = nullStyle.Render("NULL")
+		} else {
+			d.inputs[i].Width = inputWidth
+			value = d.inputs[i].View()
+		}
+
+		content.WriteString(fmt.Sprintf("%s%s  %s  %s\n",
+			indicator,
+			nameStyle.Render(name),
+			typeStyle.Render(dataType),
+			value,
+		))
+	}
+	if len(d.columns) > visible {
+		content.WriteString(footerStyle.Render(fmt.Sprintf("%d-%d of %d columns", d.offset+1, end, len(d.columns))))
+		content.WriteString("\n")
+	}
+
+	// Live SQL preview
+	content.WriteString("\n")
+	preview := edit.BuildInsert(d.schema, d.table, d.Values()).Preview()
+	content.WriteString(sqlStyle.Render(wrapText(preview, contentWidth)))
+	content.WriteString("\n")
+
+	if d.errorMsg != "" {
+		content.WriteString(errorStyle.Render(d.errorMsg))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("Tab/↑↓: Field  │  Ctrl+N: NULL  │  Empty: DEFAULT  │  Enter: Insert  │  Esc: Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
@@ -1530,5 +1530,5 @@ func (jv *JSONBViewer) updatePreviewPane() {
 		isTruncated = false
 	}
 
-	jv.previewPane.SetContent(content, path, isTruncated)
+	jv.previewPane.SetContent(content, path, isTruncated, false)
 }
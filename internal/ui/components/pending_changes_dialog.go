@@ -0,0 +1,246 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/db/edit"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// Zone IDs for pending changes dialog
+const (
+	ZonePendingCommit  = "pending-commit"
+	ZonePendingDiscard = "pending-discard"
+	ZonePendingCancel  = "pending-cancel"
+)
+
+// CommitChangesMsg is sent when staged changes should be written to the database
+type CommitChangesMsg struct {
+	ObjectID   string
+	Statements []edit.Statement
+}
+
+// DiscardChangesMsg is sent when staged changes should be thrown away
+type DiscardChangesMsg struct {
+	ObjectID string
+}
+
+// ClosePendingChangesMsg is sent when the dialog is closed without action
+type ClosePendingChangesMsg struct{}
+
+// PendingChangesDialog shows staged row edits and the SQL that will be executed
+type PendingChangesDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	objectID   string
+	schema     string
+	table      string
+	updates    []edit.RowUpdate
+	statements []edit.Statement
+	scroll     int
+}
+
+// NewPendingChangesDialog creates a new pending changes dialog
+func NewPendingChangesDialog(th theme.Theme) *PendingChangesDialog {
+	return &PendingChangesDialog{
+		Theme:  th,
+		Width:  90,
+		Height: 30,
+	}
+}
+
+// SetChanges sets the staged updates and builds the statements to execute
+func (d *PendingChangesDialog) SetChanges(objectID, schema, table string, updates []edit.RowUpdate) error {
+	statements := make([]edit.Statement, 0, len(updates))
+	for _, update := range updates {
+		stmt, err := edit.BuildUpdate(schema, table, update)
+		if err != nil {
+			return err
+		}
+		statements = append(statements, stmt)
+	}
+
+	d.objectID = objectID
+	d.schema = schema
+	d.table = table
+	d.updates = updates
+	d.statements = statements
+	d.scroll = 0
+	return nil
+}
+
+// Update handles keyboard input
+func (d *PendingChangesDialog) Update(msg tea.KeyMsg) (*PendingChangesDialog, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		return d, d.commitCmd()
+	case "d":
+		return d, d.discardCmd()
+	case "esc", "q":
+		return d, func() tea.Msg {
+			return ClosePendingChangesMsg{}
+		}
+	case "j", "down":
+		d.scroll++
+	case "k", "up":
+		if d.scroll > 0 {
+			d.scroll--
+		}
+	}
+	return d, nil
+}
+
+func (d *PendingChangesDialog) commitCmd() tea.Cmd {
+	objectID := d.objectID
+	statements := d.statements
+	return func() tea.Msg {
+		return CommitChangesMsg{ObjectID: objectID, Statements: statements}
+	}
+}
+
+func (d *PendingChangesDialog) discardCmd() tea.Cmd {
+	objectID := d.objectID
+	return func() tea.Msg {
+		return DiscardChangesMsg{ObjectID: objectID}
+	}
+}
+
+// View renders the dialog
+func (d *PendingChangesDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Metadata)
+
+	oldStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Error).
+		Strikethrough(true)
+
+	newStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Success)
+
+	sqlStyle := lipgloss.NewStyle().
+		Foreground(d.Theme.Foreground).
+		Faint(true)
+
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	contentWidth := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	// Build the diff body as plain lines so it can be scrolled
+	var lines []string
+	for i, update := range d.updates {
+		keyParts := make([]string, 0, len(update.Key))
+		for _, kv := range update.Key {
+			keyParts = append(keyParts, fmt.Sprintf("%s=%s", kv.Column, kv.Value))
+		}
+		lines = append(lines, keyStyle.Render("● "+strings.Join(keyParts, ", ")))
+
+		for _, change := range update.Changes {
+			newValue := change.NewValue
+			if change.IsNull {
+				newValue = "NULL"
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s → %s",
+				change.Column,
+				oldStyle.Render(runewidth.Truncate(change.OldValue, contentWidth/3, "…")),
+				newStyle.Render(runewidth.Truncate(newValue, contentWidth/3, "…")),
+			))
+		}
+
+		if i < len(d.statements) {
+			preview := wrapText(d.statements[i].Preview(), contentWidth-2)
+			for _, line := range strings.Split(preview, "\n") {
+				lines = append(lines, "  "+sqlStyle.Render(line))
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	// Apply scrolling
+	maxBody := d.Height - 8
+	if maxBody < 3 {
+		maxBody = 3
+	}
+	maxScroll := len(lines) - maxBody
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if d.scroll > maxScroll {
+		d.scroll = maxScroll
+	}
+	end := d.scroll + maxBody
+	if end > len(lines) {
+		end = len(lines)
+	}
+	body := lines[d.scroll:end]
+
+	var content strings.Builder
+	title := fmt.Sprintf("Pending Changes · %s.%s · %d row(s)", d.schema, d.table, len(d.updates))
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString(strings.Join(body, "\n"))
+	if maxScroll > 0 {
+		content.WriteString("\n")
+		content.WriteString(footerStyle.Render(fmt.Sprintf("j/k to scroll (%d/%d)", d.scroll+1, maxScroll+1)))
+	}
+	content.WriteString("\n")
+
+	commitBtn := zone.Mark(ZonePendingCommit, footerStyle.Render("[Enter] Commit"))
+	discardBtn := zone.Mark(ZonePendingDiscard, footerStyle.Render("[d] Discard all"))
+	cancelBtn := zone.Mark(ZonePendingCancel, footerStyle.Render("[Esc] Close"))
+	content.WriteString(commitBtn)
+	content.WriteString("  ")
+	content.WriteString(discardBtn)
+	content.WriteString("  ")
+	content.WriteString(cancelBtn)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
+
+// HandleMouseClick handles mouse click events
+func (d *PendingChangesDialog) HandleMouseClick(msg tea.MouseMsg) (handled bool, cmd tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+
+	if zone.Get(ZonePendingCommit).InBounds(msg) {
+		return true, d.commitCmd()
+	}
+
+	if zone.Get(ZonePendingDiscard).InBounds(msg) {
+		return true, d.discardCmd()
+	}
+
+	if zone.Get(ZonePendingCancel).InBounds(msg) {
+		return true, func() tea.Msg {
+			return ClosePendingChangesMsg{}
+		}
+	}
+
+	return false, nil
+}
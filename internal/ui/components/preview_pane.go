@@ -17,6 +17,7 @@ type PreviewPane struct {
 	MaxHeight int    // Maximum height (screen 1/3)
 	Content   string // Raw content to display
 	Title     string // Title (column name or JSON path)
+	IsNull    bool   // Content is SQL NULL rather than text
 
 	// Visibility state
 	Visible       bool // Whether pane should be shown
@@ -48,15 +49,17 @@ func NewPreviewPane(th theme.Theme) *PreviewPane {
 
 // SetContent sets the content to display
 // isTruncated indicates whether the content was truncated in the parent view
-func (p *PreviewPane) SetContent(content, title string, isTruncated bool) {
+// isNull indicates that the value is SQL NULL
+func (p *PreviewPane) SetContent(content, title string, isTruncated, isNull bool) {
 	// Skip if content hasn't changed (performance optimization)
-	if p.Content == content && p.Title == title {
+	if p.Content == content && p.Title == title && p.IsNull == isNull {
 		return
 	}
 
 	p.Content = content
 	p.Title = title
 	p.IsTruncated = isTruncated
+	p.IsNull = isNull
 	p.scrollY = 0
 	p.contentLines = nil // Clear cached lines, will be formatted on demand
 }
@@ -130,7 +133,7 @@ func (p *PreviewPane) Toggle() {
 		p.contentLines = nil // Clear formatted content for performance
 	} else {
 		// Show if we have content
-		if p.Content != "" && p.Content != "NULL" {
+		if p.Content != "" && !p.IsNull {
 			p.Visible = true
 			p.ForceHidden = false
 			p.formatContent()
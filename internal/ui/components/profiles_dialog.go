@@ -0,0 +1,516 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// Zone ID prefixes for mouse click handling
+const (
+	ZoneProfileItemPrefix  = "profile-item-"
+	ZoneProfileFieldPrefix = "profile-field-"
+)
+
+// Form fields of the profiles dialog, in display order
+const (
+	profileFieldName = iota
+	profileFieldHost
+	profileFieldPort
+	profileFieldDatabase
+	profileFieldUser
+	profileFieldSSLMode
+	profileFieldPasswordCommand
+	profileFieldTags
+	profileFieldColor
+	profileFieldReadOnly
+	profileFieldDefaultSchema
+	profileFieldStartupSQL
+	profileFieldCount
+)
+
+var profileFieldLabels = [profileFieldCount]string{
+	"Name: (required)",
+	"Host: (required)",
+	"Port:",
+	"Database:",
+	"User:",
+	"SSL mode:",
+	"Password command:",
+	"Tags: (comma separated)",
+	"Color: (name or #rrggbb)",
+	"Read-only: (space to toggle)",
+	"Default schema:",
+	"Startup SQL:",
+}
+
+// ConnectProfileMsg is sent when a profile should be connected to
+type ConnectProfileMsg struct {
+	Name string
+}
+
+// SaveProfileMsg is sent when a profile should be added or updated
+type SaveProfileMsg struct {
+	Profile models.ConnectionProfile
+	OldName string // Name of the edited profile, empty when adding
+}
+
+// DeleteProfileMsg is sent when a profile should be deleted
+type DeleteProfileMsg struct {
+	Name string
+}
+
+// CloseProfilesDialogMsg is sent when the dialog should close
+type CloseProfilesDialogMsg struct{}
+
+// ProfilesDialog lists and edits named connection profiles
+type ProfilesDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	// State
+	editing  bool
+	profiles []models.ConnectionProfile
+	selected int
+	offset   int
+
+	// Add/Edit state
+	oldName      string
+	inputs       [profileFieldCount]string
+	readOnly     bool
+	currentField int
+
+	validationError   string
+	deleteConfirmMode bool
+}
+
+// NewProfilesDialog creates a new profiles dialog
+func NewProfilesDialog(th theme.Theme) *ProfilesDialog {
+	return &ProfilesDialog{
+		Width:  80,
+		Height: 30,
+		Theme:  th,
+	}
+}
+
+// SetProfiles updates the profiles list, keeping the selection in range
+func (pd *ProfilesDialog) SetProfiles(profiles []models.ConnectionProfile) {
+	pd.profiles = profiles
+	pd.deleteConfirmMode = false
+	if pd.selected >= len(profiles) {
+		pd.selected = max(len(profiles)-1, 0)
+	}
+	if pd.offset > pd.selected {
+		pd.offset = pd.selected
+	}
+}
+
+// visibleHeight returns the number of profiles shown at once
+func (pd *ProfilesDialog) visibleHeight() int {
+	return max((pd.Height-10)/2, 1)
+}
+
+// Update handles keyboard input
+func (pd *ProfilesDialog) Update(msg tea.KeyMsg) (*ProfilesDialog, tea.Cmd) {
+	if pd.editing {
+		return pd.handleEditMode(msg)
+	}
+	return pd.handleListMode(msg)
+}
+
+func (pd *ProfilesDialog) handleListMode(msg tea.KeyMsg) (*ProfilesDialog, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		if pd.deleteConfirmMode {
+			pd.deleteConfirmMode = false
+			return pd, nil
+		}
+		return pd, func() tea.Msg {
+			return CloseProfilesDialogMsg{}
+		}
+	case "up", "k":
+		pd.deleteConfirmMode = false
+		if pd.selected > 0 {
+			pd.selected--
+			if pd.selected < pd.offset {
+				pd.offset = pd.selected
+			}
+		}
+	case "down", "j":
+		pd.deleteConfirmMode = false
+		if pd.selected < len(pd.profiles)-1 {
+			pd.selected++
+			if pd.selected >= pd.offset+pd.visibleHeight() {
+				pd.offset = pd.selected - pd.visibleHeight() + 1
+			}
+		}
+	case "enter":
+		if pd.selected < len(pd.profiles) {
+			name := pd.profiles[pd.selected].Name
+			return pd, func() tea.Msg {
+				return ConnectProfileMsg{Name: name}
+			}
+		}
+	case "a", "n":
+		pd.startEdit(models.ConnectionProfile{}, "")
+	case "e":
+		if pd.selected < len(pd.profiles) {
+			p := pd.profiles[pd.selected]
+			pd.startEdit(p, p.Name)
+		}
+	case "c":
+		// Copy the selected profile into a new one
+		if pd.selected < len(pd.profiles) {
+			p := pd.profiles[pd.selected]
+			p.Name += "-copy"
+			pd.startEdit(p, "")
+		}
+	case "d", "x":
+		if pd.selected < len(pd.profiles) {
+			if pd.deleteConfirmMode {
+				name := pd.profiles[pd.selected].Name
+				pd.deleteConfirmMode = false
+				return pd, func() tea.Msg {
+					return DeleteProfileMsg{Name: name}
+				}
+			}
+			pd.deleteConfirmMode = true
+		}
+	}
+	return pd, nil
+}
+
+// startEdit opens the form on a profile; oldName is empty for a new one
+func (pd *ProfilesDialog) startEdit(p models.ConnectionProfile, oldName string) {
+	pd.editing = true
+	pd.oldName = oldName
+	pd.inputs = [profileFieldCount]string{}
+	pd.inputs[profileFieldName] = p.Name
+	pd.inputs[profileFieldHost] = p.Host
+	if p.Port != 0 {
+		pd.inputs[profileFieldPort] = strconv.Itoa(p.Port)
+	}
+	pd.inputs[profileFieldDatabase] = p.Database
+	pd.inputs[profileFieldUser] = p.User
+	pd.inputs[profileFieldSSLMode] = p.SSLMode
+	pd.inputs[profileFieldPasswordCommand] = p.PasswordCommand
+	pd.inputs[profileFieldTags] = strings.Join(p.Tags, ", ")
+	pd.inputs[profileFieldColor] = p.Color
+	pd.inputs[profileFieldDefaultSchema] = p.DefaultSchema
+	pd.inputs[profileFieldStartupSQL] = p.StartupSQL
+	pd.readOnly = p.ReadOnly
+	pd.currentField = profileFieldName
+	pd.validationError = ""
+	pd.deleteConfirmMode = false
+}
+
+func (pd *ProfilesDialog) handleEditMode(msg tea.KeyMsg) (*ProfilesDialog, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		pd.editing = false
+		pd.validationError = ""
+	case "tab", "down":
+		pd.currentField = (pd.currentField + 1) % profileFieldCount
+		pd.validationError = ""
+	case "shift+tab", "up":
+		pd.currentField = (pd.currentField - 1 + profileFieldCount) % profileFieldCount
+		pd.validationError = ""
+	case "backspace":
+		if value := pd.inputs[pd.currentField]; value != "" {
+			runes := []rune(value)
+			pd.inputs[pd.currentField] = string(runes[:len(runes)-1])
+		}
+	case " ":
+		if pd.currentField == profileFieldReadOnly {
+			pd.readOnly = !pd.readOnly
+		} else {
+			pd.inputs[pd.currentField] += " "
+		}
+	case "ctrl+s":
+		return pd.save()
+	case "enter":
+		if pd.currentField == profileFieldCount-1 {
+			return pd.save()
+		}
+		pd.currentField++
+	default:
+		if msg.Type == tea.KeyRunes && pd.currentField != profileFieldReadOnly {
+			pd.inputs[pd.currentField] += string(msg.Runes)
+		}
+	}
+	return pd, nil
+}
+
+// save validates the form and sends it as a SaveProfileMsg
+func (pd *ProfilesDialog) save() (*ProfilesDialog, tea.Cmd) {
+	profile, err := pd.formProfile()
+	if err != nil {
+		pd.validationError = err.Error()
+		return pd, nil
+	}
+	oldName := pd.oldName
+	pd.editing = false
+	pd.validationError = ""
+	return pd, func() tea.Msg {
+		return SaveProfileMsg{Profile: profile, OldName: oldName}
+	}
+}
+
+// formProfile builds a profile from the form inputs
+func (pd *ProfilesDialog) formProfile() (models.ConnectionProfile, error) {
+	field := func(i int) string { return strings.TrimSpace(pd.inputs[i]) }
+
+	profile := models.ConnectionProfile{
+		Name:            field(profileFieldName),
+		Host:            field(profileFieldHost),
+		Database:        field(profileFieldDatabase),
+		User:            field(profileFieldUser),
+		SSLMode:         field(profileFieldSSLMode),
+		PasswordCommand: field(profileFieldPasswordCommand),
+		Color:           field(profileFieldColor),
+		ReadOnly:        pd.readOnly,
+		DefaultSchema:   field(profileFieldDefaultSchema),
+		StartupSQL:      field(profileFieldStartupSQL),
+	}
+	if profile.Name == "" {
+		return profile, fmt.Errorf("name is required")
+	}
+	if profile.Host == "" {
+		return profile, fmt.Errorf("host is required")
+	}
+	if port := field(profileFieldPort); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return profile, fmt.Errorf("port must be a number between 1 and 65535")
+		}
+		profile.Port = n
+	}
+	for _, tag := range strings.Split(pd.inputs[profileFieldTags], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			profile.Tags = append(profile.Tags, tag)
+		}
+	}
+	return profile, nil
+}
+
+// HandleMouseWheel scrolls the profiles list
+// Returns true if the event was handled
+func (pd *ProfilesDialog) HandleMouseWheel(msg tea.MouseMsg) bool {
+	if pd.editing {
+		return false
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		pd.selected = max(pd.selected-1, 0)
+	case tea.MouseButtonWheelDown:
+		pd.selected = max(min(pd.selected+1, len(pd.profiles)-1), 0)
+	default:
+		return false
+	}
+	pd.deleteConfirmMode = false
+	if pd.selected < pd.offset {
+		pd.offset = pd.selected
+	}
+	if pd.selected >= pd.offset+pd.visibleHeight() {
+		pd.offset = pd.selected - pd.visibleHeight() + 1
+	}
+	return true
+}
+
+// HandleMouseClick handles mouse click events
+// Returns true if click was handled, and a command if needed
+func (pd *ProfilesDialog) HandleMouseClick(msg tea.MouseMsg) (handled bool, cmd tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+
+	if pd.editing {
+		for i := 0; i < profileFieldCount; i++ {
+			if zone.Get(fmt.Sprintf("%s%d", ZoneProfileFieldPrefix, i)).InBounds(msg) {
+				if i == profileFieldReadOnly && pd.currentField == i {
+					pd.readOnly = !pd.readOnly
+				}
+				pd.currentField = i
+				pd.validationError = ""
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	end := min(pd.offset+pd.visibleHeight(), len(pd.profiles))
+	for i := pd.offset; i < end; i++ {
+		if zone.Get(fmt.Sprintf("%s%d", ZoneProfileItemPrefix, i)).InBounds(msg) {
+			// Click on already selected connects
+			if i == pd.selected {
+				name := pd.profiles[i].Name
+				return true, func() tea.Msg {
+					return ConnectProfileMsg{Name: name}
+				}
+			}
+			pd.selected = i
+			pd.deleteConfirmMode = false
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// View renders the dialog
+func (pd *ProfilesDialog) View() string {
+	if pd.editing {
+		return pd.renderEdit()
+	}
+	return pd.renderList()
+}
+
+func (pd *ProfilesDialog) renderList() string {
+	var sections []string
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(pd.Theme.Foreground).
+		Background(pd.Theme.Info).
+		Padding(0, 1).
+		Bold(true)
+	sections = append(sections, titleStyle.Render("Connections"))
+
+	instrStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#a6adc8")).
+		Padding(0, 1)
+	sections = append(sections, instrStyle.Render("↑↓: Navigate  Enter: Connect  a: Add  e: Edit  c: Copy  d: Delete  Esc: Close"))
+
+	if pd.deleteConfirmMode && len(pd.profiles) > 0 {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f38ba8")).
+			Background(lipgloss.Color("#45475a")).
+			Padding(0, 1).
+			Bold(true)
+		sections = append(sections, warningStyle.Render("⚠ Press 'd' again to confirm deletion, or Esc to cancel"))
+	}
+
+	if len(pd.profiles) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#a6adc8")).
+			Padding(1, 1)
+		emptyMsg := "No connection profiles yet.\n\nPress 'a' to add one, or define them in the connections\nsection of config.yaml or in connections.yaml."
+		sections = append(sections, emptyStyle.Render(emptyMsg))
+	} else {
+		sections = append(sections, "")
+		dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6c7086"))
+		end := min(pd.offset+pd.visibleHeight(), len(pd.profiles))
+		for i := pd.offset; i < end; i++ {
+			p := pd.profiles[i]
+
+			port := p.Port
+			if port == 0 {
+				port = 5432
+			}
+			target := fmt.Sprintf("%s@%s:%d/%s", p.User, p.Host, port, p.Database)
+			var flags []string
+			if p.ReadOnly {
+				flags = append(flags, "read-only")
+			}
+			if len(p.Tags) > 0 {
+				flags = append(flags, strings.Join(p.Tags, ", "))
+			}
+			details := target
+			if len(flags) > 0 {
+				details += " [" + strings.Join(flags, "; ") + "]"
+			}
+
+			style := lipgloss.NewStyle().Padding(0, 1).Width(pd.Width - 4)
+			if i == pd.selected {
+				if pd.deleteConfirmMode {
+					style = style.Background(lipgloss.Color("#f38ba8")).Foreground(lipgloss.Color("#1e1e2e"))
+				} else {
+					style = style.Background(pd.Theme.Selection).Foreground(pd.Theme.Foreground)
+				}
+			}
+			line := p.Name + "  " + dimStyle.Render("("+p.Source+")") + "\n  " + details
+			sections = append(sections, zone.Mark(fmt.Sprintf("%s%d", ZoneProfileItemPrefix, i), style.Render(line)))
+		}
+	}
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(pd.Theme.Border).
+		Width(pd.Width).
+		Height(pd.Height).
+		Padding(1)
+
+	return containerStyle.Render(strings.Join(sections, "\n"))
+}
+
+func (pd *ProfilesDialog) renderEdit() string {
+	var sections []string
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(pd.Theme.Foreground).
+		Background(pd.Theme.Info).
+		Padding(0, 1).
+		Bold(true)
+	title := "Add Connection"
+	if pd.oldName != "" {
+		title = "Edit Connection"
+	}
+	sections = append(sections, titleStyle.Render(title))
+
+	instrStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#a6adc8")).
+		Padding(0, 1)
+	sections = append(sections, instrStyle.Render("Tab/↑↓: Navigate fields  Ctrl+S: Save  Esc: Cancel"))
+
+	if pd.validationError != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#f38ba8")).
+			Background(lipgloss.Color("#45475a")).
+			Padding(0, 1).
+			Bold(true)
+		sections = append(sections, errorStyle.Render("⚠ "+pd.validationError))
+	}
+
+	sections = append(sections, "")
+	for i := 0; i < profileFieldCount; i++ {
+		value := pd.inputs[i]
+		if i == profileFieldReadOnly {
+			value = "no"
+			if pd.readOnly {
+				value = "yes"
+			}
+		}
+		zoneID := fmt.Sprintf("%s%d", ZoneProfileFieldPrefix, i)
+		sections = append(sections, zone.Mark(zoneID, pd.renderField(profileFieldLabels[i], value, pd.currentField == i, i != profileFieldReadOnly)))
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Padding(1, 1)
+	sections = append(sections, helpStyle.Render("Profiles are saved to connections.yaml.\nPress Enter on the last field or Ctrl+S to save."))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(pd.Theme.Border).
+		Width(pd.Width).
+		Height(pd.Height).
+		Padding(1)
+
+	return containerStyle.Render(strings.Join(sections, "\n"))
+}
+
+func (pd *ProfilesDialog) renderField(label, value string, active, cursor bool) string {
+	style := lipgloss.NewStyle().Padding(0, 1)
+	if active {
+		style = style.Background(pd.Theme.Selection).Foreground(pd.Theme.Foreground)
+		if cursor {
+			value += "_"
+		}
+	}
+	return style.Render(fmt.Sprintf("%-29s %s", label, value))
+}
@@ -0,0 +1,75 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestProfilesDialogEdit(t *testing.T) {
+	d := NewProfilesDialog(theme.DefaultTheme())
+	d.SetProfiles([]models.ConnectionProfile{
+		{Name: "prod", Host: "db.example.com", Port: 5433, User: "app", Tags: []string{"live"}},
+	})
+
+	typeText := func(s string) {
+		for _, r := range s {
+			d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	key := func(k tea.KeyType) tea.Cmd {
+		_, cmd := d.Update(tea.KeyMsg{Type: k})
+		return cmd
+	}
+
+	typeText("e")
+	if !d.editing || d.oldName != "prod" {
+		t.Fatalf("expected to edit prod, got editing=%v oldName=%q", d.editing, d.oldName)
+	}
+
+	// Rename, then toggle read-only and add a tag
+	typeText("2")
+	for d.currentField != profileFieldTags {
+		key(tea.KeyTab)
+	}
+	typeText(", eu")
+	key(tea.KeyTab)
+	key(tea.KeyTab)
+	key(tea.KeySpace)
+
+	msg, ok := key(tea.KeyCtrlS)().(SaveProfileMsg)
+	if !ok {
+		t.Fatalf("expected a SaveProfileMsg")
+	}
+	p := msg.Profile
+	if msg.OldName != "prod" || p.Name != "prod2" || p.Port != 5433 || !p.ReadOnly {
+		t.Errorf("unexpected profile: %+v (old name %q)", p, msg.OldName)
+	}
+	if len(p.Tags) != 2 || p.Tags[1] != "eu" {
+		t.Errorf("expected tags [live eu], got %v", p.Tags)
+	}
+	if d.editing {
+		t.Errorf("expected the form to close after saving")
+	}
+}
+
+func TestProfilesDialogValidation(t *testing.T) {
+	d := NewProfilesDialog(theme.DefaultTheme())
+	d.startEdit(models.ConnectionProfile{Name: "dev"}, "")
+	if _, cmd := d.save(); cmd != nil || d.validationError == "" {
+		t.Errorf("expected a missing host to be rejected")
+	}
+
+	d.inputs[profileFieldHost] = "localhost"
+	d.inputs[profileFieldPort] = "abc"
+	if _, cmd := d.save(); cmd != nil || !d.editing {
+		t.Errorf("expected an invalid port to be rejected")
+	}
+
+	d.inputs[profileFieldPort] = ""
+	if _, cmd := d.save(); cmd == nil {
+		t.Errorf("expected a valid profile to be saved, got %q", d.validationError)
+	}
+}
@@ -0,0 +1,169 @@
+package components
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// ZoneReferenceItemPrefix is the zone ID prefix for entries in the references list
+const ZoneReferenceItemPrefix = "reference-item-"
+
+// ReferenceEntry is a table whose rows reference the selected row
+type ReferenceEntry struct {
+	Label  string  // Referencing table and columns
+	Detail string  // Number of referencing rows
+	Action tea.Msg // Sent when the entry is opened
+}
+
+// CloseReferencesDialogMsg is sent when the references dialog is closed
+type CloseReferencesDialogMsg struct{}
+
+// ReferencesDialog lists the tables with rows referencing the selected row,
+// to open those rows
+type ReferencesDialog struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	title    string
+	entries  []ReferenceEntry
+	selected int
+	scroll   int
+}
+
+// NewReferencesDialog creates a new references dialog
+func NewReferencesDialog(th theme.Theme) *ReferencesDialog {
+	return &ReferencesDialog{
+		Width:  80,
+		Height: 20,
+		Theme:  th,
+	}
+}
+
+// SetEntries shows the referencing tables of a row
+func (d *ReferencesDialog) SetEntries(title string, entries []ReferenceEntry) {
+	d.title = title
+	d.entries = entries
+	d.selected = 0
+	d.scroll = 0
+}
+
+// Update handles messages
+func (d *ReferencesDialog) Update(msg tea.Msg) (*ReferencesDialog, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		return d, func() tea.Msg {
+			return CloseReferencesDialogMsg{}
+		}
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < len(d.entries)-1 {
+			d.selected++
+		}
+	case "enter":
+		return d, d.open()
+	}
+	return d, nil
+}
+
+// open sends the action of the selected entry
+func (d *ReferencesDialog) open() tea.Cmd {
+	if d.selected < 0 || d.selected >= len(d.entries) {
+		return nil
+	}
+	action := d.entries[d.selected].Action
+	return func() tea.Msg {
+		return action
+	}
+}
+
+// HandleMouseClick selects an entry; clicking the selected entry opens it
+func (d *ReferencesDialog) HandleMouseClick(msg tea.MouseMsg) (bool, tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return false, nil
+	}
+	for i := range d.entries {
+		if zone.Get(ZoneReferenceItemPrefix + strconv.Itoa(i)).InBounds(msg) {
+			if i == d.selected {
+				return true, d.open()
+			}
+			d.selected = i
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// View renders the dialog
+func (d *ReferencesDialog) View() string {
+	if d.Width <= 0 || d.Height <= 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(d.Theme.Info).Padding(0, 1)
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	detailStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	selectedStyle := lipgloss.NewStyle().Foreground(d.Theme.BorderFocused).Bold(true)
+	footerStyle := lipgloss.NewStyle().Faint(true).Foreground(d.Theme.Foreground).Padding(0, 1)
+
+	width := d.Width - 8 // border (2) + padding (4) + margin (2)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(runewidth.Truncate(d.title, width, "…")))
+	content.WriteString("\n\n")
+
+	visible := max(3, d.Height-8)
+	if d.selected < d.scroll {
+		d.scroll = d.selected
+	}
+	if d.selected >= d.scroll+visible {
+		d.scroll = d.selected - visible + 1
+	}
+
+	detailWidth := 0
+	for _, entry := range d.entries {
+		detailWidth = max(detailWidth, runewidth.StringWidth(entry.Detail))
+	}
+	labelWidth := max(10, width-detailWidth-4)
+
+	end := min(len(d.entries), d.scroll+visible)
+	for i := d.scroll; i < end; i++ {
+		entry := d.entries[i]
+		label := runewidth.FillRight(runewidth.Truncate(entry.Label, labelWidth, "…"), labelWidth)
+		detail := runewidth.FillLeft(entry.Detail, detailWidth)
+		var line string
+		if i == d.selected {
+			line = "▸ " + selectedStyle.Render(label) + "  " + selectedStyle.Render(detail)
+		} else {
+			line = "  " + labelStyle.Render(label) + "  " + detailStyle.Render(detail)
+		}
+		content.WriteString(zone.Mark(ZoneReferenceItemPrefix+strconv.Itoa(i), line))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("↑↓: Select  │  Enter: Open rows  │  Esc: Close"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		MaxWidth(d.Width).
+		Background(d.Theme.Background)
+
+	return boxStyle.Render(content.String())
+}
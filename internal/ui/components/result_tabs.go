@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -19,6 +20,20 @@ const (
 
 const MaxResultTabs = 10
 
+// MaxStreamingTabs is how many result tabs may keep an open row source. Each
+// source holds a pooled connection, so older tabs stop streaming.
+const MaxStreamingTabs = 2
+
+// RowSource supplies further rows of a query result that was only partly read
+type RowSource interface {
+	// Fetch returns the display text and typed values of up to n more rows
+	Fetch(ctx context.Context, n int) ([][]string, [][]models.Cell, error)
+	// Done reports whether no more rows can be fetched
+	Done() bool
+	// Close releases the source
+	Close() error
+}
+
 // Pre-compiled regex patterns for performance
 var (
 	dashCommentRe  = regexp.MustCompile(`^\s*--\s*(.+)$`)
@@ -55,15 +70,24 @@ type ResultTab struct {
 	Structure  *StructureView // For table data tabs
 
 	// Identifier for deduplication (e.g., "schema.table" or "schema.function")
-	ObjectID string
+	ObjectID   string
+	Connection string // ID of the connection the tab was opened in
+	Database   string // Database the tab was opened in
+
+	// Streaming state for query results read through a RowSource
+	Source   RowSource // nil once all rows are loaded or streaming stopped
+	MoreRows bool      // true while the end of the result is unknown
+	Fetching bool      // true while a page is being fetched
 }
 
 // ResultTabs manages multiple query result tabs
 type ResultTabs struct {
-	tabs      []*ResultTab
-	activeIdx int
-	nextID    int
-	Theme     theme.Theme
+	tabs       []*ResultTab
+	activeIdx  int
+	nextID     int
+	Theme      theme.Theme
+	Connection string // ID of the connection new tabs are opened in
+	Database   string // Database new tabs are opened in
 
 	// Pending execution state
 	pendingSQL       string
@@ -87,11 +111,13 @@ func (rt *ResultTabs) StartPendingQuery(sql string) {
 
 	// Create pending tab
 	tab := &ResultTab{
-		ID:        rt.nextID,
-		Title:     "Executing...",
-		SQL:       sql,
-		CreatedAt: time.Now(),
-		IsPending: true,
+		ID:         rt.nextID,
+		Connection: rt.Connection,
+		Database:   rt.Database,
+		Title:      "Executing...",
+		SQL:        sql,
+		CreatedAt:  time.Now(),
+		IsPending:  true,
 	}
 	rt.nextID++
 
@@ -99,38 +125,167 @@ func (rt *ResultTabs) StartPendingQuery(sql string) {
 	rt.tabs = append([]*ResultTab{tab}, rt.tabs...)
 
 	// Remove oldest (rightmost) if exceeding max
-	if len(rt.tabs) > MaxResultTabs {
-		rt.tabs = rt.tabs[:MaxResultTabs]
-	}
+	rt.trimTabs()
 
 	// Set pending tab as active
 	rt.activeIdx = 0
 }
 
-// CompletePendingQuery completes the pending query with results
-func (rt *ResultTabs) CompletePendingQuery(sql string, result models.QueryResult) {
+// CompletePendingQuery completes the pending query with results. A non-nil
+// source supplies the rest of a partly read result.
+func (rt *ResultTabs) CompletePendingQuery(sql string, result models.QueryResult, source RowSource) {
 	// Find and update the pending tab
+	found := false
 	for i, tab := range rt.tabs {
 		if tab.IsPending && tab.SQL == sql {
 			// Create TableView for results
 			tableView := NewTableView(rt.Theme)
 			tableView.SetData(result.Columns, result.Rows, len(result.Rows))
+			tableView.SetCells(result.Cells)
+			tableView.MoreRows = source != nil
 
 			tab.Title = rt.generateTitle(sql, result)
 			tab.Result = result
 			tab.TableView = tableView
 			tab.IsPending = false
+			tab.Source = source
+			tab.MoreRows = source != nil
 
 			// Make sure this tab is active
 			rt.activeIdx = i
+			found = true
 			break
 		}
 	}
+	if !found && source != nil {
+		// The pending tab was closed while the query ran
+		go source.Close()
+	}
+	rt.limitSources()
 
 	// Clear pending state
 	rt.pendingSQL = ""
 }
 
+// FinishScript shows the summary of a script in the script's pending (or
+// cancelled) tab, or in a new tab if that was closed. The summary becomes
+// the active tab when focus is set.
+func (rt *ResultTabs) FinishScript(sql, title string, summary models.QueryResult, focus bool) {
+	tableView := NewTableView(rt.Theme)
+	tableView.SetData(summary.Columns, summary.Rows, len(summary.Rows))
+
+	idx := -1
+	for i, tab := range rt.tabs {
+		if (tab.IsPending || tab.IsCancelled) && tab.SQL == sql {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		rt.tabs = append([]*ResultTab{{ID: rt.nextID, Connection: rt.Connection, Database: rt.Database, SQL: sql, CreatedAt: time.Now()}}, rt.tabs...)
+		rt.nextID++
+		idx = 0
+		if !focus {
+			rt.activeIdx++
+		}
+	}
+
+	tab := rt.tabs[idx]
+	tab.Title = title
+	tab.Result = summary
+	tab.TableView = tableView
+	tab.IsPending = false
+	tab.IsCancelled = false
+	tab.Type = TabTypeQueryResult
+	if focus {
+		rt.activeIdx = idx
+	}
+	rt.trimTabs()
+	if rt.activeIdx >= len(rt.tabs) {
+		rt.activeIdx = len(rt.tabs) - 1
+	}
+
+	if rt.pendingSQL == sql {
+		rt.pendingSQL = ""
+	}
+}
+
+// AppendRows adds rows fetched from a tab's row source. Once done, the
+// source is dropped and the row count becomes final.
+func (rt *ResultTabs) AppendRows(tabID int, rows [][]string, cells [][]models.Cell, done bool) {
+	tab := rt.GetTab(tabID)
+	if tab == nil {
+		return
+	}
+	tab.Fetching = false
+	tab.Result.Rows = append(tab.Result.Rows, rows...)
+	tab.Result.Cells = append(tab.Result.Cells, cells...)
+	tab.Result.RowsAffected = int64(len(tab.Result.Rows))
+	if done {
+		tab.Source = nil
+		tab.MoreRows = false
+	}
+	if tab.TableView != nil {
+		tab.TableView.AppendRows(rows, cells, tab.MoreRows)
+	}
+}
+
+// StopStreaming drops a tab's row source after a failed or cancelled fetch.
+// The rows loaded so far stay, still marked as incomplete.
+func (rt *ResultTabs) StopStreaming(tabID int) {
+	tab := rt.GetTab(tabID)
+	if tab == nil {
+		return
+	}
+	tab.Fetching = false
+	closeSource(tab)
+}
+
+// GetTab returns the tab with the given ID, or nil if it was closed
+func (rt *ResultTabs) GetTab(id int) *ResultTab {
+	for _, tab := range rt.tabs {
+		if tab.ID == id {
+			return tab
+		}
+	}
+	return nil
+}
+
+// trimTabs removes the oldest tabs beyond MaxResultTabs
+func (rt *ResultTabs) trimTabs() {
+	if len(rt.tabs) <= MaxResultTabs {
+		return
+	}
+	for _, tab := range rt.tabs[MaxResultTabs:] {
+		closeSource(tab)
+	}
+	rt.tabs = rt.tabs[:MaxResultTabs]
+}
+
+// limitSources closes the row sources of older tabs beyond MaxStreamingTabs
+func (rt *ResultTabs) limitSources() {
+	open := 0
+	for _, tab := range rt.tabs {
+		if tab.Source == nil {
+			continue
+		}
+		open++
+		if open > MaxStreamingTabs {
+			closeSource(tab)
+		}
+	}
+}
+
+// closeSource releases a tab's row source in the background, since closing
+// waits for a running fetch to finish
+func closeSource(tab *ResultTab) {
+	if tab.Source == nil {
+		return
+	}
+	go tab.Source.Close()
+	tab.Source = nil
+}
+
 // CancelPendingQuery marks the pending tab as cancelled
 func (rt *ResultTabs) CancelPendingQuery() {
 	// Find and mark the pending tab as cancelled
@@ -165,15 +320,18 @@ func (rt *ResultTabs) AddResult(sql string, result models.QueryResult) {
 	// Create TableView for this result
 	tableView := NewTableView(rt.Theme)
 	tableView.SetData(result.Columns, result.Rows, len(result.Rows))
+	tableView.SetCells(result.Cells)
 
 	tab := &ResultTab{
-		ID:        rt.nextID,
-		Title:     rt.generateTitle(sql, result),
-		SQL:       sql,
-		Result:    result,
-		CreatedAt: time.Now(),
-		TableView: tableView,
-		Type:      TabTypeQueryResult,
+		ID:         rt.nextID,
+		Connection: rt.Connection,
+		Database:   rt.Database,
+		Title:      rt.generateTitle(sql, result),
+		SQL:        sql,
+		Result:     result,
+		CreatedAt:  time.Now(),
+		TableView:  tableView,
+		Type:       TabTypeQueryResult,
 	}
 	rt.nextID++
 
@@ -181,20 +339,18 @@ func (rt *ResultTabs) AddResult(sql string, result models.QueryResult) {
 	rt.tabs = append([]*ResultTab{tab}, rt.tabs...)
 
 	// Remove oldest (rightmost) if exceeding max
-	if len(rt.tabs) > MaxResultTabs {
-		rt.tabs = rt.tabs[:MaxResultTabs]
-	}
+	rt.trimTabs()
 
 	// Set new tab as active (index 0 = leftmost)
 	rt.activeIdx = 0
 }
 
 // AddTableData adds a table/view data tab (from tree selection)
-// If a tab for the same objectID exists, it becomes active instead of creating a new tab
+// If a tab for the same objectID and target exists, it becomes active instead of creating a new tab
 func (rt *ResultTabs) AddTableData(objectID, title string, structure *StructureView) {
 	// Check if tab for this object already exists
 	for i, tab := range rt.tabs {
-		if tab.ObjectID == objectID && tab.Type == TabTypeTableData {
+		if tab.ObjectID == objectID && tab.Type == TabTypeTableData && rt.SameTarget(tab) {
 			// Tab exists, just activate it
 			rt.activeIdx = i
 			return
@@ -202,12 +358,14 @@ func (rt *ResultTabs) AddTableData(objectID, title string, structure *StructureV
 	}
 
 	tab := &ResultTab{
-		ID:        rt.nextID,
-		Title:     title,
-		CreatedAt: time.Now(),
-		Type:      TabTypeTableData,
-		Structure: structure,
-		ObjectID:  objectID,
+		ID:         rt.nextID,
+		Connection: rt.Connection,
+		Database:   rt.Database,
+		Title:      title,
+		CreatedAt:  time.Now(),
+		Type:       TabTypeTableData,
+		Structure:  structure,
+		ObjectID:   objectID,
 	}
 	rt.nextID++
 
@@ -215,20 +373,18 @@ func (rt *ResultTabs) AddTableData(objectID, title string, structure *StructureV
 	rt.tabs = append([]*ResultTab{tab}, rt.tabs...)
 
 	// Remove oldest (rightmost) if exceeding max
-	if len(rt.tabs) > MaxResultTabs {
-		rt.tabs = rt.tabs[:MaxResultTabs]
-	}
+	rt.trimTabs()
 
 	// Set new tab as active (index 0 = leftmost)
 	rt.activeIdx = 0
 }
 
 // AddCodeEditor adds a code/DDL display tab (for functions, sequences, etc.)
-// If a tab for the same objectID exists, it becomes active instead of creating a new tab
+// If a tab for the same objectID and target exists, it becomes active instead of creating a new tab
 func (rt *ResultTabs) AddCodeEditor(objectID, title string, codeEditor *CodeEditor) {
 	// Check if tab for this object already exists
 	for i, tab := range rt.tabs {
-		if tab.ObjectID == objectID && tab.Type == TabTypeCodeEditor {
+		if tab.ObjectID == objectID && tab.Type == TabTypeCodeEditor && rt.SameTarget(tab) {
 			// Tab exists, just activate it
 			rt.activeIdx = i
 			return
@@ -237,6 +393,8 @@ func (rt *ResultTabs) AddCodeEditor(objectID, title string, codeEditor *CodeEdit
 
 	tab := &ResultTab{
 		ID:         rt.nextID,
+		Connection: rt.Connection,
+		Database:   rt.Database,
 		Title:      title,
 		CreatedAt:  time.Now(),
 		Type:       TabTypeCodeEditor,
@@ -249,9 +407,7 @@ func (rt *ResultTabs) AddCodeEditor(objectID, title string, codeEditor *CodeEdit
 	rt.tabs = append([]*ResultTab{tab}, rt.tabs...)
 
 	// Remove oldest (rightmost) if exceeding max
-	if len(rt.tabs) > MaxResultTabs {
-		rt.tabs = rt.tabs[:MaxResultTabs]
-	}
+	rt.trimTabs()
 
 	// Set new tab as active (index 0 = leftmost)
 	rt.activeIdx = 0
@@ -264,6 +420,7 @@ func (rt *ResultTabs) CloseActiveTab() {
 	}
 
 	// Remove the active tab
+	closeSource(rt.tabs[rt.activeIdx])
 	rt.tabs = append(rt.tabs[:rt.activeIdx], rt.tabs[rt.activeIdx+1:]...)
 
 	// Adjust active index
@@ -272,6 +429,38 @@ func (rt *ResultTabs) CloseActiveTab() {
 	}
 }
 
+// CloseConnectionTabs closes the tabs opened in a connection, keeping the
+// active tab active when it stays open
+func (rt *ResultTabs) CloseConnectionTabs(connection string) {
+	var active *ResultTab
+	if rt.activeIdx < len(rt.tabs) {
+		active = rt.tabs[rt.activeIdx]
+	}
+
+	kept := rt.tabs[:0]
+	for _, tab := range rt.tabs {
+		if tab.Connection == connection {
+			closeSource(tab)
+			continue
+		}
+		kept = append(kept, tab)
+	}
+	rt.tabs = kept
+
+	rt.activeIdx = 0
+	for i, tab := range rt.tabs {
+		if tab == active {
+			rt.activeIdx = i
+		}
+	}
+}
+
+// SameTarget reports whether a tab was opened in the connection and
+// database new tabs are opened in
+func (rt *ResultTabs) SameTarget(tab *ResultTab) bool {
+	return tab.Connection == rt.Connection && tab.Database == rt.Database
+}
+
 // GetActiveStructureView returns the StructureView of the active tab (if it's a table data tab)
 func (rt *ResultTabs) GetActiveStructureView() *StructureView {
 	tab := rt.GetActiveTab()
@@ -444,7 +633,9 @@ func (rt *ResultTabs) RenderTabBar(width int) string {
 			// Format: [index] title (rows)
 			rowCount := len(tab.Result.Rows)
 			rowStr := fmt.Sprintf("%d rows", rowCount)
-			if rowCount == 1 {
+			if tab.MoreRows {
+				rowStr = fmt.Sprintf("%d+ rows", rowCount)
+			} else if rowCount == 1 {
 				rowStr = "1 row"
 			}
 			label = fmt.Sprintf("[%d] %s (%s)", i+1, tab.Title, rowStr)
@@ -0,0 +1,50 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestCloseConnectionTabs(t *testing.T) {
+	rt := NewResultTabs(theme.DefaultTheme())
+	for _, conn := range []string{"prod", "dev", "prod", "dev"} {
+		rt.Connection = conn
+		rt.AddResult("SELECT 1", models.QueryResult{Columns: []string{"n"}, Rows: [][]string{{"1"}}})
+	}
+	// New tabs open on the left: dev, prod, dev, prod. Activate the second dev.
+	rt.SetActiveTab(2)
+	active := rt.GetActiveTab()
+
+	rt.CloseConnectionTabs("prod")
+	if rt.TabCount() != 2 {
+		t.Fatalf("expected 2 tabs left, got %d", rt.TabCount())
+	}
+	for _, tab := range rt.GetAllTabs() {
+		if tab.Connection != "dev" {
+			t.Errorf("expected only dev tabs, got one of %s", tab.Connection)
+		}
+	}
+	if rt.GetActiveTab() != active {
+		t.Errorf("expected the active tab to stay active")
+	}
+
+	rt.CloseConnectionTabs("dev")
+	if rt.HasTabs() || rt.GetActiveTab() != nil {
+		t.Errorf("expected no tabs left")
+	}
+}
+
+func TestSameTarget(t *testing.T) {
+	rt := NewResultTabs(theme.DefaultTheme())
+	rt.Connection, rt.Database = "prod", "app"
+	tab := &ResultTab{Connection: "prod", Database: "app"}
+	if !rt.SameTarget(tab) {
+		t.Errorf("expected a tab of the same connection and database to match")
+	}
+	rt.Connection = "dev"
+	if rt.SameTarget(tab) {
+		t.Errorf("expected a tab of another connection not to match")
+	}
+}
@@ -0,0 +1,247 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// SchemaDiffEndpoint is a schema of a connection to compare
+type SchemaDiffEndpoint struct {
+	ConnectionID string
+	Schema       string
+}
+
+// Label names the schema, with its connection when the other side of the
+// comparison uses another one
+func (e SchemaDiffEndpoint) Label(other SchemaDiffEndpoint) string {
+	if e.ConnectionID == other.ConnectionID {
+		return e.Schema
+	}
+	return e.ConnectionID + " · " + e.Schema
+}
+
+// CompareSchemasMsg is sent to compare a source schema with a target
+type CompareSchemasMsg struct {
+	Source SchemaDiffEndpoint
+	Target SchemaDiffEndpoint
+}
+
+// CloseSchemaDiffDialogMsg is sent when the schema diff dialog is cancelled
+type CloseSchemaDiffDialogMsg struct{}
+
+// Fields of the schema diff dialog
+const (
+	diffFieldSourceConnection = iota
+	diffFieldSourceSchema
+	diffFieldTargetConnection
+	diffFieldTargetSchema
+	diffFieldCount
+)
+
+// SchemaDiffDialog picks the two schemas to compare, each from any open
+// connection
+type SchemaDiffDialog struct {
+	Width int
+	Theme theme.Theme
+
+	connections []string
+	source      int // Index into connections
+	target      int
+	schemas     [2]textinput.Model // Source and target
+	focus       int
+	err         string
+}
+
+// NewSchemaDiffDialog creates a new schema diff dialog
+func NewSchemaDiffDialog(th theme.Theme) *SchemaDiffDialog {
+	d := &SchemaDiffDialog{
+		Width: 76,
+		Theme: th,
+	}
+	for i := range d.schemas {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = "schema"
+		input.CharLimit = 63
+		input.TextStyle = lipgloss.NewStyle().Foreground(th.Foreground)
+		d.schemas[i] = input
+	}
+	return d
+}
+
+// Open prepares the dialog to compare a schema of the open connections with
+// itself, from the active connection
+func (d *SchemaDiffDialog) Open(connections []string, active, schema string) {
+	d.connections = connections
+	d.source = max(0, slices.Index(connections, active))
+	d.target = d.source
+	d.schemas[0].SetValue(schema)
+	d.schemas[1].SetValue(schema)
+	d.err = ""
+	d.setFocus(diffFieldTargetSchema)
+	if len(connections) > 1 {
+		// Comparing the same schema of another database is most likely
+		d.target = (d.source + 1) % len(connections)
+		d.setFocus(diffFieldTargetConnection)
+	}
+}
+
+// setFocus focuses a field
+func (d *SchemaDiffDialog) setFocus(field int) {
+	d.focus = field
+	for i := range d.schemas {
+		d.schemas[i].Blur()
+	}
+	switch field {
+	case diffFieldSourceSchema:
+		d.schemas[0].Focus()
+	case diffFieldTargetSchema:
+		d.schemas[1].Focus()
+	}
+}
+
+// Update handles messages
+func (d *SchemaDiffDialog) Update(msg tea.Msg) (*SchemaDiffDialog, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			return d, func() tea.Msg {
+				return CloseSchemaDiffDialogMsg{}
+			}
+		case "tab", "down":
+			d.setFocus((d.focus + 1) % diffFieldCount)
+			return d, nil
+		case "shift+tab", "backtab", "up":
+			d.setFocus((d.focus - 1 + diffFieldCount) % diffFieldCount)
+			return d, nil
+		case "enter":
+			return d, d.submit()
+		}
+
+		if d.focus == diffFieldSourceConnection || d.focus == diffFieldTargetConnection {
+			step := 0
+			switch keyMsg.String() {
+			case "left", "h":
+				step = -1
+			case "right", "l", " ":
+				step = 1
+			}
+			if step != 0 && len(d.connections) > 0 {
+				index := &d.source
+				if d.focus == diffFieldTargetConnection {
+					index = &d.target
+				}
+				*index = (*index + step + len(d.connections)) % len(d.connections)
+				d.err = ""
+			}
+			return d, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch d.focus {
+	case diffFieldSourceSchema:
+		d.schemas[0], cmd = d.schemas[0].Update(msg)
+	case diffFieldTargetSchema:
+		d.schemas[1], cmd = d.schemas[1].Update(msg)
+	}
+	d.err = ""
+	return d, cmd
+}
+
+// submit asks for the comparison, unless the schemas are missing or the same
+func (d *SchemaDiffDialog) submit() tea.Cmd {
+	if len(d.connections) == 0 {
+		d.err = "No open connections"
+		return nil
+	}
+	compare := CompareSchemasMsg{
+		Source: SchemaDiffEndpoint{ConnectionID: d.connections[d.source], Schema: strings.TrimSpace(d.schemas[0].Value())},
+		Target: SchemaDiffEndpoint{ConnectionID: d.connections[d.target], Schema: strings.TrimSpace(d.schemas[1].Value())},
+	}
+	if compare.Source.Schema == "" || compare.Target.Schema == "" {
+		d.err = "Enter the schemas to compare"
+		return nil
+	}
+	if compare.Source == compare.Target {
+		d.err = "Choose two different schemas or connections"
+		return nil
+	}
+	return func() tea.Msg {
+		return compare
+	}
+}
+
+// View renders the dialog
+func (d *SchemaDiffDialog) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(d.Theme.Info).
+		Padding(0, 1)
+	labelStyle := lipgloss.NewStyle().Foreground(d.Theme.Metadata)
+	valueStyle := lipgloss.NewStyle().Foreground(d.Theme.Foreground)
+	focusedStyle := lipgloss.NewStyle().Foreground(d.Theme.BorderFocused).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(d.Theme.Error).Padding(0, 1)
+	footerStyle := lipgloss.NewStyle().
+		Faint(true).
+		Foreground(d.Theme.Foreground).
+		Padding(0, 1)
+
+	inputWidth := max(10, d.Width-22)
+	field := func(index int, label, value string) string {
+		indicator := "  "
+		style := valueStyle
+		if d.focus == index {
+			indicator = "▸ "
+			style = focusedStyle
+		}
+		return fmt.Sprintf("%s%s %s\n", indicator, labelStyle.Render(fmt.Sprintf("%-11s", label)), style.Render(value))
+	}
+	connection := func(index int) string {
+		if len(d.connections) == 0 {
+			return "(none)"
+		}
+		name := d.connections[index]
+		if len(d.connections) > 1 {
+			name = "◀ " + name + " ▶"
+		}
+		return name
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Schema Diff"))
+	content.WriteString("\n\n")
+	content.WriteString(labelStyle.Render("Source — the schema to match"))
+	content.WriteString("\n")
+	content.WriteString(field(diffFieldSourceConnection, "Connection", connection(d.source)))
+	d.schemas[0].Width = inputWidth
+	content.WriteString(field(diffFieldSourceSchema, "Schema", d.schemas[0].View()))
+	content.WriteString("\n")
+	content.WriteString(labelStyle.Render("Target — the schema to migrate"))
+	content.WriteString("\n")
+	content.WriteString(field(diffFieldTargetConnection, "Connection", connection(d.target)))
+	d.schemas[1].Width = inputWidth
+	content.WriteString(field(diffFieldTargetSchema, "Schema", d.schemas[1].View()))
+
+	if d.err != "" {
+		content.WriteString("\n")
+		content.WriteString(errorStyle.Render(d.err))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("Tab/↑↓: Field  │  ←→: Connection  │  Enter: Compare  │  Esc: Cancel"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.Theme.Info).
+		Padding(1, 2).
+		Width(d.Width).
+		Background(d.Theme.Background).
+		Render(content.String())
+}
@@ -0,0 +1,42 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestSchemaDiffDialogSubmit(t *testing.T) {
+	d := NewSchemaDiffDialog(theme.DefaultTheme())
+
+	// One connection: the same schema cannot be compared with itself
+	d.Open([]string{"prod"}, "prod", "public")
+	if cmd := d.submit(); cmd != nil || d.err == "" {
+		t.Fatalf("expected comparing a schema with itself to be refused")
+	}
+	d.schemas[1].SetValue("staging")
+	cmd := d.submit()
+	if cmd == nil {
+		t.Fatalf("expected a comparison, got error %q", d.err)
+	}
+	expected := CompareSchemasMsg{
+		Source: SchemaDiffEndpoint{ConnectionID: "prod", Schema: "public"},
+		Target: SchemaDiffEndpoint{ConnectionID: "prod", Schema: "staging"},
+	}
+	if msg := cmd(); msg != expected {
+		t.Errorf("expected %+v, got %+v", expected, msg)
+	}
+
+	// Several connections: the target defaults to the next one
+	d.Open([]string{"dev", "prod", "staging"}, "prod", "public")
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRight})
+	msg := d.submit()()
+	expected = CompareSchemasMsg{
+		Source: SchemaDiffEndpoint{ConnectionID: "prod", Schema: "public"},
+		Target: SchemaDiffEndpoint{ConnectionID: "dev", Schema: "public"},
+	}
+	if msg != expected {
+		t.Errorf("expected %+v, got %+v", expected, msg)
+	}
+}
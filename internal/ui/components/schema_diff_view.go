@@ -0,0 +1,375 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/db/schemadiff"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+// CloseSchemaDiffMsg is sent when the schema diff should close
+type CloseSchemaDiffMsg struct{}
+
+// SaveMigrationMsg is sent to save the migration script to a file
+type SaveMigrationMsg struct {
+	Schema string // Target schema, to name the file
+	Script string
+}
+
+// OpenMigrationMsg is sent to open the migration script in the SQL editor
+type OpenMigrationMsg struct {
+	Script string
+}
+
+// schemaDiffSymbols mark the status of a difference in the list
+var schemaDiffSymbols = map[schemadiff.Status]string{
+	schemadiff.Added:   "+",
+	schemadiff.Removed: "-",
+	schemadiff.Changed: "~",
+}
+
+// schemaDiffRow is a line of the list: a group header, or a difference
+type schemaDiffRow struct {
+	group string
+	diff  int // Index into the differences, -1 for a header
+}
+
+// SchemaDiffView shows the differences of a target schema from a source,
+// grouped by object, with both definitions side by side and the migration
+// that brings the target in line
+type SchemaDiffView struct {
+	Width  int
+	Height int
+	Theme  theme.Theme
+
+	source     SchemaDiffEndpoint
+	target     SchemaDiffEndpoint
+	loading    bool
+	err        error
+	comparison *schemadiff.Comparison
+	rows       []schemaDiffRow
+
+	selected     int // Index into the differences
+	listOffset   int // First visible row
+	detailOffset int // First visible line of the details
+	notice       string
+}
+
+// NewSchemaDiffView creates a new schema diff view
+func NewSchemaDiffView(th theme.Theme) *SchemaDiffView {
+	return &SchemaDiffView{
+		Width:  120,
+		Height: 30,
+		Theme:  th,
+	}
+}
+
+// SetLoading shows a comparison as loading
+func (v *SchemaDiffView) SetLoading(source, target SchemaDiffEndpoint) {
+	v.source, v.target = source, target
+	v.loading = true
+	v.err = nil
+	v.comparison = nil
+	v.rows = nil
+	v.selected, v.listOffset, v.detailOffset = 0, 0, 0
+	v.notice = ""
+}
+
+// IsCurrent reports whether the view shows the comparison of two schemas
+func (v *SchemaDiffView) IsCurrent(source, target SchemaDiffEndpoint) bool {
+	return v.source == source && v.target == target
+}
+
+// SetComparison shows the result of a comparison
+func (v *SchemaDiffView) SetComparison(c *schemadiff.Comparison, err error) {
+	v.loading = false
+	v.err = err
+	v.comparison = c
+	v.rows = nil
+	if c == nil {
+		return
+	}
+	for i, d := range c.Differences {
+		if i == 0 || d.Group != c.Differences[i-1].Group {
+			v.rows = append(v.rows, schemaDiffRow{group: d.Group, diff: -1})
+		}
+		v.rows = append(v.rows, schemaDiffRow{group: d.Group, diff: i})
+	}
+}
+
+// SetNotice shows a message below the differences, e.g. where the script
+// was saved
+func (v *SchemaDiffView) SetNotice(notice string) {
+	v.notice = notice
+}
+
+// Script returns the migration script, headed by the schemas it compares
+func (v *SchemaDiffView) Script() string {
+	if v.comparison == nil {
+		return ""
+	}
+	return fmt.Sprintf("-- Source: %s, schema %s\n-- Target: %s, schema %s\n",
+		v.source.ConnectionID, v.source.Schema, v.target.ConnectionID, v.target.Schema) + v.comparison.Script()
+}
+
+// bodyHeight returns the number of lines of the list and details
+func (v *SchemaDiffView) bodyHeight() int {
+	return max(1, v.Height-6)
+}
+
+// Update handles keyboard input
+func (v *SchemaDiffView) Update(msg tea.KeyMsg) (*SchemaDiffView, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return v, func() tea.Msg {
+			return CloseSchemaDiffMsg{}
+		}
+	}
+	if v.comparison == nil {
+		return v, nil
+	}
+
+	v.notice = ""
+	count := len(v.comparison.Differences)
+	switch msg.String() {
+	case "up", "k":
+		v.selectDifference(v.selected - 1)
+	case "down", "j":
+		v.selectDifference(v.selected + 1)
+	case "g", "home":
+		v.selectDifference(0)
+	case "G", "end":
+		v.selectDifference(count - 1)
+	case "pgup":
+		v.selectDifference(v.selected - v.bodyHeight()/2)
+	case "pgdown":
+		v.selectDifference(v.selected + v.bodyHeight()/2)
+	case "ctrl+u":
+		v.detailOffset = max(0, v.detailOffset-v.bodyHeight()/2)
+	case "ctrl+d":
+		v.detailOffset += v.bodyHeight() / 2
+	case "s":
+		save := SaveMigrationMsg{Schema: v.target.Schema, Script: v.Script()}
+		return v, func() tea.Msg {
+			return save
+		}
+	case "e":
+		open := OpenMigrationMsg{Script: v.Script()}
+		return v, func() tea.Msg {
+			return open
+		}
+	}
+	return v, nil
+}
+
+// selectDifference selects a difference, keeping it and its group header
+// in view
+func (v *SchemaDiffView) selectDifference(i int) {
+	count := len(v.comparison.Differences)
+	if count == 0 {
+		return
+	}
+	v.selected = max(0, min(i, count-1))
+	v.detailOffset = 0
+
+	row := 0
+	for r, line := range v.rows {
+		if line.diff == v.selected {
+			row = r
+			break
+		}
+	}
+	height := v.bodyHeight()
+	if row-1 < v.listOffset {
+		v.listOffset = max(0, row-1)
+	}
+	if row >= v.listOffset+height {
+		v.listOffset = row - height + 1
+	}
+}
+
+// HandleMouseWheel moves the selection
+func (v *SchemaDiffView) HandleMouseWheel(msg tea.MouseMsg) bool {
+	if v.comparison == nil {
+		return false
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		v.selectDifference(v.selected - 1)
+		return true
+	case tea.MouseButtonWheelDown:
+		v.selectDifference(v.selected + 1)
+		return true
+	}
+	return false
+}
+
+// View renders the differences
+func (v *SchemaDiffView) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(v.Theme.Background).
+		Background(v.Theme.Info).
+		Padding(0, 1).
+		Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(v.Theme.Metadata).Padding(0, 1)
+	noticeStyle := lipgloss.NewStyle().Foreground(v.Theme.Warning).Padding(0, 1)
+
+	title := fmt.Sprintf("Schema Diff · %s → %s", v.source.Label(v.target), v.target.Label(v.source))
+	sections := []string{titleStyle.Render(title)}
+
+	height := v.bodyHeight()
+	var body []string
+	switch {
+	case v.loading:
+		sections = append(sections, metaStyle.Render("Loading both schemas…"))
+	case v.err != nil:
+		sections = append(sections, noticeStyle.Render(v.err.Error()))
+	case len(v.comparison.Differences) == 0:
+		sections = append(sections, metaStyle.Render("The schemas match"))
+	default:
+		added, removed, changed := v.comparison.Counts()
+		sections = append(sections, metaStyle.Render(fmt.Sprintf("%d added · %d removed · %d changed in the target",
+			added, removed, changed)))
+		body = v.renderBody(max(20, v.Width-6), height)
+	}
+	for len(body) < height {
+		body = append(body, "")
+	}
+	sections = append(sections, strings.Join(body, "\n"))
+
+	sections = append(sections, noticeStyle.Render(v.notice))
+	instr := "↑↓/jk: Select  Ctrl+U/D: Scroll details  s: Save script  e: Open in editor  Esc: Close"
+	sections = append(sections, metaStyle.Render(instr))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(v.Theme.Border).
+		Padding(0, 1).
+		Width(v.Width - 2).
+		Render(strings.Join(sections, "\n"))
+}
+
+// renderBody renders the list of differences next to the details of the
+// selected one
+func (v *SchemaDiffView) renderBody(width, height int) []string {
+	listWidth := min(40, width/3)
+	detailWidth := max(10, width-listWidth-3)
+	separator := lipgloss.NewStyle().Foreground(v.Theme.Border).Render(" │ ")
+
+	list := v.renderList(listWidth, height)
+	details := v.renderDetails(detailWidth)
+	v.detailOffset = max(0, min(v.detailOffset, len(details)-height))
+	details = details[min(v.detailOffset, len(details)):]
+
+	lines := make([]string, height)
+	for i := range lines {
+		detail := ""
+		if i < len(details) {
+			detail = details[i]
+		}
+		lines[i] = list[i] + separator + detail
+	}
+	return lines
+}
+
+// renderList renders the visible rows of the list, each listWidth wide
+func (v *SchemaDiffView) renderList(width, height int) []string {
+	groupStyle := lipgloss.NewStyle().Foreground(v.Theme.Info).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(v.Theme.BorderFocused).Bold(true)
+
+	lines := make([]string, height)
+	for i := range lines {
+		r := v.listOffset + i
+		if r >= len(v.rows) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+		row := v.rows[r]
+		if row.diff < 0 {
+			lines[i] = groupStyle.Render(fitWidth(row.group, width))
+			continue
+		}
+		d := v.comparison.Differences[row.diff]
+		indicator := "  "
+		if row.diff == v.selected {
+			indicator = "▸ "
+		}
+		symbol := v.statusStyle(d.Status).Render(schemaDiffSymbols[d.Status])
+		text := fitWidth(d.Kind+" "+d.Name, width-4)
+		if row.diff == v.selected {
+			text = selectedStyle.Render(text)
+		}
+		lines[i] = indicator + symbol + " " + text
+	}
+	return lines
+}
+
+// renderDetails renders the definitions of the selected difference side by
+// side, and the statements migrating it
+func (v *SchemaDiffView) renderDetails(width int) []string {
+	headerStyle := lipgloss.NewStyle().Foreground(v.Theme.Info).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(v.Theme.Foreground)
+	missingStyle := lipgloss.NewStyle().Foreground(v.Theme.Comment).Italic(true)
+	sqlStyle := lipgloss.NewStyle().Foreground(v.Theme.Keyword)
+
+	d := v.comparison.Differences[v.selected]
+	lines := []string{
+		v.statusStyle(d.Status).Render(fitWidth(fmt.Sprintf("%s %s %s · %s", schemaDiffSymbols[d.Status], d.Kind, d.Name, d.Status), width)),
+		"",
+	}
+
+	columnWidth := max(4, (width-3)/2)
+	source := strings.Split(wrapText(d.Source, columnWidth), "\n")
+	target := strings.Split(wrapText(d.Target, columnWidth), "\n")
+	lines = append(lines, headerStyle.Render(fitWidth("Source · "+v.source.Label(v.target), columnWidth))+
+		" │ "+headerStyle.Render(fitWidth("Target · "+v.target.Label(v.source), columnWidth)))
+	for i := 0; i < max(len(source), len(target)); i++ {
+		lines = append(lines, v.definitionLine(d.Source, source, i, columnWidth, textStyle, missingStyle)+
+			" │ "+v.definitionLine(d.Target, target, i, columnWidth, textStyle, missingStyle))
+	}
+
+	lines = append(lines, "", headerStyle.Render("Migration"))
+	for _, stmt := range d.Statements {
+		for _, line := range strings.Split(wrapText(stmt.SQL, width), "\n") {
+			lines = append(lines, sqlStyle.Render(fitWidth(line, width)))
+		}
+	}
+	return lines
+}
+
+// definitionLine renders a line of a definition, or a placeholder where the
+// object is missing
+func (v *SchemaDiffView) definitionLine(definition string, lines []string, i, width int, style, missing lipgloss.Style) string {
+	if definition == "" {
+		if i == 0 {
+			return missing.Render(fitWidth("(missing)", width))
+		}
+		return strings.Repeat(" ", width)
+	}
+	if i >= len(lines) {
+		return strings.Repeat(" ", width)
+	}
+	return style.Render(fitWidth(lines[i], width))
+}
+
+// statusStyle returns the style of a status
+func (v *SchemaDiffView) statusStyle(status schemadiff.Status) lipgloss.Style {
+	switch status {
+	case schemadiff.Added:
+		return lipgloss.NewStyle().Foreground(v.Theme.Success)
+	case schemadiff.Removed:
+		return lipgloss.NewStyle().Foreground(v.Theme.Error)
+	default:
+		return lipgloss.NewStyle().Foreground(v.Theme.Warning)
+	}
+}
+
+// fitWidth truncates or pads text to a width of cells
+func fitWidth(text string, width int) string {
+	text = strings.ReplaceAll(text, "\t", "    ")
+	return runewidth.FillRight(runewidth.Truncate(text, width, "…"), width)
+}
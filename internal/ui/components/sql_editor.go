@@ -7,7 +7,12 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	zone "github.com/lrstanley/bubblezone"
+	"github.com/rebelice/lazypg/internal/completion"
+	"github.com/rebelice/lazypg/internal/db/query"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/sqlformat"
 	"github.com/rebelice/lazypg/internal/ui/theme"
 )
 
@@ -25,7 +30,15 @@ const (
 
 // ExecuteQueryMsg is sent when a query should be executed
 type ExecuteQueryMsg struct {
-	SQL string
+	SQL    string
+	Values models.BindValues // Bind variable values; prompted for when nil
+}
+
+// ExecuteScriptMsg is sent when several statements should run one after
+// another
+type ExecuteScriptMsg struct {
+	SQL    string
+	Values models.BindValues // Bind variable values; prompted for when nil
 }
 
 // OpenExternalEditorMsg requests opening an external editor
@@ -39,6 +52,13 @@ type ExternalEditorResultMsg struct {
 	Error   error
 }
 
+// CompletionSource returns the completions for the cursor at byte offset in
+// sql, and a command loading metadata they still lack
+type CompletionSource func(sql string, offset int) (completion.Result, tea.Cmd)
+
+// completionRows is the number of suggestions the popup shows at once
+const completionRows = 8
+
 // SQLEditor is a multiline SQL editor component
 type SQLEditor struct {
 	// Content
@@ -46,6 +66,11 @@ type SQLEditor struct {
 	cursorRow int      // Current cursor row (0-indexed)
 	cursorCol int      // Current cursor column (0-indexed)
 
+	// Selection from the anchor to the cursor, made with Shift+arrows
+	selecting bool
+	anchorRow int
+	anchorCol int
+
 	// Dimensions
 	Width  int
 	Height int
@@ -61,6 +86,17 @@ type SQLEditor struct {
 	// History
 	history    []string
 	historyIdx int
+
+	// Completion popup. completion is nil when completing is not active;
+	// the popup shows while it has items.
+	completionSource CompletionSource
+	autoComplete     bool
+	completion       *completion.Result
+	completionIdx    int
+
+	// Formatting, and whether content is formatted before it runs
+	formatOpts   sqlformat.Options
+	formatOnSave bool
 }
 
 // NewSQLEditor creates a new SQL editor
@@ -74,7 +110,126 @@ func NewSQLEditor(th theme.Theme) *SQLEditor {
 		Theme:        th,
 		history:      []string{},
 		historyIdx:   -1,
+		formatOpts:   sqlformat.Options{TabSize: 2, UseSpaces: true},
+	}
+}
+
+// SetFormatting sets how SQL is formatted, and whether it is formatted
+// automatically before running
+func (e *SQLEditor) SetFormatting(opts sqlformat.Options, onSave bool) {
+	e.formatOpts = opts
+	e.formatOnSave = onSave
+}
+
+// Format pretty-prints the content, keeping the cursor in the statement it
+// was in
+func (e *SQLEditor) Format() {
+	content := e.GetContent()
+	formatted := sqlformat.Format(content, e.formatOpts)
+	if formatted == content {
+		return
+	}
+	index := statementAt(query.SplitScript(content), e.cursorOffset())
+	e.SetContent(formatted)
+	if statements := query.SplitScript(formatted); index >= 0 && index < len(statements) {
+		e.setCursorOffset(statements[index].End)
+	}
+}
+
+// FormatOnSave formats the content when format-on-save is enabled and
+// nothing is selected
+func (e *SQLEditor) FormatOnSave() {
+	if e.formatOnSave && !e.HasSelection() {
+		e.Format()
+	}
+}
+
+// SetCompletionSource sets where completions come from. With auto set the
+// popup opens while typing names, otherwise only on Ctrl+Space.
+func (e *SQLEditor) SetCompletionSource(source CompletionSource, auto bool) {
+	e.completionSource = source
+	e.autoComplete = auto
+	e.completion = nil
+}
+
+// CompletionVisible reports whether the completion popup is shown
+func (e *SQLEditor) CompletionVisible() bool {
+	return e.completion != nil && len(e.completion.Items) > 0
+}
+
+// CloseCompletion hides the completion popup
+func (e *SQLEditor) CloseCompletion() {
+	e.completion = nil
+}
+
+// RefreshCompletion completes again at the cursor, e.g. once metadata the
+// suggestions were missing has loaded
+func (e *SQLEditor) RefreshCompletion() tea.Cmd {
+	if e.completion == nil {
+		return nil
+	}
+	return e.complete()
+}
+
+// complete looks up the completions at the cursor and shows them
+func (e *SQLEditor) complete() tea.Cmd {
+	if e.completionSource == nil || !e.expanded {
+		e.completion = nil
+		return nil
+	}
+	res, cmd := e.completionSource(e.GetContent(), e.cursorOffset())
+	selected := ""
+	if e.CompletionVisible() && e.completionIdx < len(e.completion.Items) {
+		selected = e.completion.Items[e.completionIdx].Label
+	}
+	e.completion = &res
+	e.completionIdx = 0
+	for i, item := range res.Items {
+		if item.Label == selected {
+			e.completionIdx = i
+			break
+		}
+	}
+	return cmd
+}
+
+// acceptCompletion replaces the prefix before the cursor with the selected
+// suggestion
+func (e *SQLEditor) acceptCompletion() {
+	res := e.completion
+	e.completion = nil
+	if res == nil || e.completionIdx >= len(res.Items) {
+		return
+	}
+	item := res.Items[e.completionIdx]
+	line := e.lines[e.cursorRow]
+	start := e.cursorCol - (e.cursorOffset() - res.Start)
+	if start < 0 {
+		start = 0
 	}
+	e.lines[e.cursorRow] = line[:start] + item.Insert + line[e.cursorCol:]
+	e.cursorCol = start + len(item.Insert)
+}
+
+// setCursorOffset moves the cursor to a byte offset in the content
+func (e *SQLEditor) setCursorOffset(offset int) {
+	for row, line := range e.lines {
+		if offset <= len(line) || row == len(e.lines)-1 {
+			e.cursorRow = row
+			e.cursorCol = min(offset, len(line))
+			return
+		}
+		offset -= len(line) + 1
+	}
+}
+
+// cursorOffset returns the byte offset of the cursor in the content
+func (e *SQLEditor) cursorOffset() int {
+	offset := 0
+	for row := 0; row < e.cursorRow; row++ {
+		offset += len(e.lines[row]) + 1 // +1 for newline
+	}
+	return offset + e.cursorCol
 }
 
 // IsExpanded returns whether the editor is expanded
@@ -95,6 +250,7 @@ func (e *SQLEditor) Expand() {
 // Collapse collapses the editor
 func (e *SQLEditor) Collapse() {
 	e.expanded = false
+	e.completion = nil
 }
 
 // GetHeightPreset returns the current height preset
@@ -144,6 +300,8 @@ func (e *SQLEditor) SetContent(content string) {
 	}
 	e.cursorRow = len(e.lines) - 1
 	e.cursorCol = len(e.lines[e.cursorRow])
+	e.selecting = false
+	e.completion = nil
 }
 
 // Clear clears the editor content
@@ -151,6 +309,87 @@ func (e *SQLEditor) Clear() {
 	e.lines = []string{""}
 	e.cursorRow = 0
 	e.cursorCol = 0
+	e.selecting = false
+	e.completion = nil
+}
+
+// startSelection anchors a selection at the cursor unless one is active
+func (e *SQLEditor) startSelection() {
+	if !e.selecting {
+		e.selecting = true
+		e.anchorRow = e.cursorRow
+		e.anchorCol = e.cursorCol
+	}
+}
+
+// ClearSelection drops the selection
+func (e *SQLEditor) ClearSelection() {
+	e.selecting = false
+}
+
+// selectionBounds returns the start and end of the selection in document
+// order, and false when nothing is selected
+func (e *SQLEditor) selectionBounds() (startRow, startCol, endRow, endCol int, ok bool) {
+	if !e.selecting {
+		return 0, 0, 0, 0, false
+	}
+	startRow, startCol = e.anchorRow, e.anchorCol
+	endRow, endCol = e.cursorRow, e.cursorCol
+	if endRow < startRow || endRow == startRow && endCol < startCol {
+		startRow, startCol, endRow, endCol = endRow, endCol, startRow, startCol
+	}
+	if startRow == endRow && startCol == endCol {
+		return 0, 0, 0, 0, false
+	}
+	return startRow, startCol, endRow, endCol, true
+}
+
+// HasSelection reports whether text is selected
+func (e *SQLEditor) HasSelection() bool {
+	_, _, _, _, ok := e.selectionBounds()
+	return ok
+}
+
+// GetSelection returns the selected text
+func (e *SQLEditor) GetSelection() string {
+	startRow, startCol, endRow, endCol, ok := e.selectionBounds()
+	if !ok {
+		return ""
+	}
+	if startRow == endRow {
+		return e.lines[startRow][startCol:endCol]
+	}
+	parts := []string{e.lines[startRow][startCol:]}
+	parts = append(parts, e.lines[startRow+1:endRow]...)
+	parts = append(parts, e.lines[endRow][:endCol])
+	return strings.Join(parts, "\n")
+}
+
+// DeleteSelection removes the selected text and reports whether there was any
+func (e *SQLEditor) DeleteSelection() bool {
+	startRow, startCol, endRow, endCol, ok := e.selectionBounds()
+	e.selecting = false
+	if !ok {
+		return false
+	}
+	merged := e.lines[startRow][:startCol] + e.lines[endRow][endCol:]
+	e.lines = append(e.lines[:startRow+1], e.lines[endRow+1:]...)
+	e.lines[startRow] = merged
+	e.cursorRow = startRow
+	e.cursorCol = startCol
+	return true
+}
+
+// inSelection reports whether the character at row and col is selected
+func (e *SQLEditor) inSelection(row, col int) bool {
+	startRow, startCol, endRow, endCol, ok := e.selectionBounds()
+	if !ok || row < startRow || row > endRow {
+		return false
+	}
+	if row == startRow && col < startCol {
+		return false
+	}
+	return row != endRow || col < endCol
 }
 
 // GetCollapsedHeight returns the height when collapsed (2 lines + border)
@@ -466,6 +705,10 @@ func (e *SQLEditor) View() string {
 		for len(visibleLines) < contentHeight {
 			visibleLines = append(visibleLines, e.renderEmptyLine(startLine+len(visibleLines)))
 		}
+
+		if e.CompletionVisible() {
+			visibleLines = e.overlayCompletion(visibleLines, e.cursorRow-startLine)
+		}
 	} else {
 		// Collapsed: show first 2 lines
 		for i := 0; i < 2 && i < len(e.lines); i++ {
@@ -514,14 +757,85 @@ func (e *SQLEditor) renderLine(lineNum int, hasCursor bool) string {
 	tokens := e.tokenizeLine(line)
 	contentPart := e.renderTokens(tokens)
 
-	// Insert cursor if this line has it
-	if hasCursor && e.expanded {
-		contentPart = e.insertCursor(line, tokens)
+	// Insert cursor and selection highlight
+	if e.expanded && (hasCursor || e.lineSelected(lineNum)) {
+		contentPart = e.insertCursor(lineNum, line, tokens, hasCursor)
 	}
 
 	return lineNumPart + contentPart
 }
 
+// overlayCompletion draws the completion popup over the visible lines,
+// below the cursor line or above it when there is more room there
+func (e *SQLEditor) overlayCompletion(lines []string, cursorLine int) []string {
+	items := e.completion.Items
+	rows := min(completionRows, len(items))
+	first := 0
+	if e.completionIdx >= rows {
+		first = e.completionIdx - rows + 1
+	}
+
+	labelWidth, detailWidth := 0, 0
+	for _, item := range items[first : first+rows] {
+		labelWidth = max(labelWidth, ansi.StringWidth(item.Label))
+		detailWidth = max(detailWidth, ansi.StringWidth(item.Detail))
+	}
+	labelWidth = min(labelWidth, 32)
+	detailWidth = min(detailWidth, 32)
+
+	normal := lipgloss.NewStyle().Foreground(e.Theme.Foreground).Background(e.Theme.Selection)
+	selected := lipgloss.NewStyle().Foreground(e.Theme.Background).Background(e.Theme.Cursor)
+	dim := lipgloss.NewStyle().Foreground(e.Theme.Metadata).Background(e.Theme.Selection)
+
+	var popup []string
+	for i := first; i < first+rows; i++ {
+		item := items[i]
+		label := ansi.Truncate(item.Label, labelWidth, "…")
+		text := " " + label + strings.Repeat(" ", labelWidth-ansi.StringWidth(label))
+		if detailWidth > 0 {
+			detail := ansi.Truncate(item.Detail, detailWidth, "…")
+			text += "  " + detail + strings.Repeat(" ", detailWidth-ansi.StringWidth(detail))
+		}
+		kind := fmt.Sprintf(" %-8s", item.Kind)
+		if i == e.completionIdx {
+			popup = append(popup, selected.Render(text+kind))
+		} else {
+			popup = append(popup, normal.Render(text)+dim.Render(kind))
+		}
+	}
+
+	// Place the popup under the start of the prefix
+	x := e.getLineNumberWidth() + ansi.StringWidth(e.lines[e.cursorRow][:e.cursorCol]) - len(e.completion.Prefix)
+	if maxX := e.Width - 2 - ansi.StringWidth(popup[0]); x > maxX {
+		x = maxX
+	}
+	x = max(x, 0)
+
+	top := cursorLine + 1
+	if top+rows > len(lines) && cursorLine-rows >= 0 {
+		top = cursorLine - rows
+	}
+	for i, row := range popup {
+		if top+i >= 0 && top+i < len(lines) {
+			lines[top+i] = overlayAt(lines[top+i], row, x)
+		}
+	}
+	return lines
+}
+
+// overlayAt draws foreground over background starting at column x
+func overlayAt(background, foreground string, x int) string {
+	left := ansi.Truncate(background, x, "")
+	if width := ansi.StringWidth(left); width < x {
+		left += strings.Repeat(" ", x-width)
+	}
+	right := ""
+	if end := x + ansi.StringWidth(foreground); end < ansi.StringWidth(background) {
+		right = ansi.TruncateLeft(background, end, "")
+	}
+	return left + foreground + right
+}
+
 // renderEmptyLine renders an empty line placeholder
 func (e *SQLEditor) renderEmptyLine(lineNum int) string {
 	lineNumWidth := e.getLineNumberWidth()
@@ -546,8 +860,15 @@ func (e *SQLEditor) getLineNumberWidth() int {
 	return digits + 3 // digits + space + separator
 }
 
-// insertCursor inserts the cursor character into the rendered line
-func (e *SQLEditor) insertCursor(line string, tokens []Token) string {
+// lineSelected reports whether part of a line is selected
+func (e *SQLEditor) lineSelected(row int) bool {
+	startRow, _, endRow, _, ok := e.selectionBounds()
+	return ok && row >= startRow && row <= endRow
+}
+
+// insertCursor renders a line character by character to show the cursor
+// and the selected text
+func (e *SQLEditor) insertCursor(row int, line string, tokens []Token, hasCursor bool) string {
 	// Rebuild line with cursor
 	var result strings.Builder
 	charIdx := 0
@@ -574,9 +895,12 @@ func (e *SQLEditor) insertCursor(line string, tokens []Token) string {
 		}
 
 		for _, ch := range token.Value {
-			if charIdx == e.cursorCol {
+			switch {
+			case hasCursor && charIdx == e.cursorCol:
 				result.WriteString(cursorStyle.Render(string(ch)))
-			} else {
+			case e.inSelection(row, charIdx):
+				result.WriteString(style.Background(e.Theme.Selection).Render(string(ch)))
+			default:
 				result.WriteString(style.Render(string(ch)))
 			}
 			charIdx++
@@ -584,7 +908,7 @@ func (e *SQLEditor) insertCursor(line string, tokens []Token) string {
 	}
 
 	// Cursor at end of line
-	if e.cursorCol >= charIdx {
+	if hasCursor && e.cursorCol >= charIdx {
 		result.WriteString(cursorStyle.Render(" "))
 	}
 
@@ -593,7 +917,69 @@ func (e *SQLEditor) insertCursor(line string, tokens []Token) string {
 
 // Update handles keyboard input
 func (e *SQLEditor) Update(msg tea.KeyMsg) (*SQLEditor, tea.Cmd) {
-	switch msg.String() {
+	key := msg.String()
+
+	// The completion popup takes the keys that pick a suggestion
+	if e.CompletionVisible() {
+		switch key {
+		case "up", "ctrl+p":
+			if e.completionIdx > 0 {
+				e.completionIdx--
+			} else {
+				e.completionIdx = len(e.completion.Items) - 1
+			}
+			return e, nil
+		case "down", "ctrl+n":
+			e.completionIdx = (e.completionIdx + 1) % len(e.completion.Items)
+			return e, nil
+		case "tab", "enter":
+			e.acceptCompletion()
+			return e, nil
+		case "esc":
+			e.completion = nil
+			return e, nil
+		}
+	}
+
+	// Shift+movement extends the selection
+	switch key {
+	case "shift+left", "shift+right", "shift+up", "shift+down", "shift+home", "shift+end":
+		e.startSelection()
+		switch strings.TrimPrefix(key, "shift+") {
+		case "left":
+			e.MoveCursorLeft()
+		case "right":
+			e.MoveCursorRight()
+		case "up":
+			e.MoveCursorUp()
+		case "down":
+			e.MoveCursorDown()
+		case "home":
+			e.MoveCursorToLineStart()
+		case "end":
+			e.MoveCursorToLineEnd()
+		}
+		return e, nil
+	}
+
+	// Typing replaces the selection; keys that run or explain SQL keep it,
+	// any other key drops it
+	if e.HasSelection() {
+		switch {
+		case key == "backspace" || key == "delete":
+			e.DeleteSelection()
+			return e, nil
+		case key == "enter" || key == "tab" || len(key) == 1 || msg.Type == tea.KeyRunes:
+			e.DeleteSelection()
+		}
+	}
+	switch key {
+	case "ctrl+s", "f5", "ctrl+x", "alt+x":
+	default:
+		e.ClearSelection()
+	}
+
+	switch key {
 	// Cursor movement
 	case "left":
 		e.MoveCursorLeft()
@@ -627,14 +1013,29 @@ func (e *SQLEditor) Update(msg tea.KeyMsg) (*SQLEditor, tea.Cmd) {
 	case "ctrl+u":
 		e.Clear()
 
+	// Completion (Ctrl+Space)
+	case "ctrl+@":
+		return e, e.complete()
+
 	// History navigation
 	case "ctrl+up":
 		e.HistoryPrev()
 	case "ctrl+down":
 		e.HistoryNext()
 
+	// Format (Alt+F)
+	case "alt+f":
+		e.Format()
+
 	// Execute (Ctrl+S - note: ctrl+enter equals enter, alt+enter doesn't work on macOS)
 	case "ctrl+s":
+		e.FormatOnSave()
+		if selection := e.GetSelection(); strings.TrimSpace(selection) != "" {
+			e.AddToHistory(e.GetContent())
+			return e, func() tea.Msg {
+				return ExecuteScriptMsg{SQL: selection}
+			}
+		}
 		sql := e.GetCurrentStatement()
 		if sql != "" {
 			e.AddToHistory(e.GetContent())
@@ -643,6 +1044,30 @@ func (e *SQLEditor) Update(msg tea.KeyMsg) (*SQLEditor, tea.Cmd) {
 			}
 		}
 
+	// Run all statements, or those in the selection
+	case "f5":
+		e.FormatOnSave()
+		script := e.GetSelection()
+		if strings.TrimSpace(script) == "" {
+			script = e.GetContent()
+		}
+		if strings.TrimSpace(script) != "" {
+			e.AddToHistory(e.GetContent())
+			return e, func() tea.Msg {
+				return ExecuteScriptMsg{SQL: script}
+			}
+		}
+
+	// Explain (Ctrl+X) / Explain Analyze (Alt+X) the current statement
+	case "ctrl+x", "alt+x":
+		sql := e.GetCurrentStatement()
+		if sql != "" {
+			analyze := msg.String() == "alt+x"
+			return e, func() tea.Msg {
+				return ExplainQueryMsg{SQL: sql, Analyze: analyze}
+			}
+		}
+
 	// External editor
 	case "ctrl+o":
 		return e, func() tea.Msg {
@@ -651,21 +1076,48 @@ func (e *SQLEditor) Update(msg tea.KeyMsg) (*SQLEditor, tea.Cmd) {
 
 	default:
 		// Handle printable characters
+		var typed []rune
 		if len(msg.String()) == 1 {
 			ch := rune(msg.String()[0])
 			if ch >= 32 && ch <= 126 {
-				e.InsertChar(ch)
+				typed = []rune{ch}
 			}
 		} else if msg.Type == tea.KeyRunes {
-			for _, r := range msg.Runes {
-				e.InsertChar(r)
-			}
+			typed = msg.Runes
+		}
+		for _, r := range typed {
+			e.InsertChar(r)
+		}
+		if len(typed) > 0 {
+			return e, e.completeAfterTyping(typed[len(typed)-1])
 		}
 	}
 
+	// Deleting narrows the prefix again; anything else ends completing
+	if e.completion != nil {
+		if key == "backspace" || key == "delete" {
+			return e, e.complete()
+		}
+		e.completion = nil
+	}
+
 	return e, nil
 }
 
+// completeAfterTyping updates the completion popup after a character was
+// typed: names keep or start completing, anything else ends it
+func (e *SQLEditor) completeAfterTyping(ch rune) tea.Cmd {
+	name := ch == '_' || ch == '$' || ch == '"' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+	switch {
+	case name && (e.completion != nil || e.autoComplete && !unicode.IsDigit(ch)):
+		return e.complete()
+	case ch == '.' && (e.completion != nil || e.autoComplete):
+		return e.complete()
+	}
+	e.completion = nil
+	return nil
+}
+
 // AddToHistory adds content to history
 func (e *SQLEditor) AddToHistory(content string) {
 	if content == "" {
@@ -706,76 +1158,21 @@ func (e *SQLEditor) HistoryNext() {
 
 // GetCurrentStatement returns the SQL statement at cursor position
 func (e *SQLEditor) GetCurrentStatement() string {
-	content := e.GetContent()
-	if content == "" {
-		return ""
-	}
-
-	// Find statement boundaries using semicolons
-	statements := splitStatements(content)
+	statements := query.SplitScript(e.GetContent())
 	if len(statements) == 0 {
-		return strings.TrimSpace(content)
-	}
-
-	// Find which statement the cursor is in
-	charPos := 0
-	for row := 0; row < e.cursorRow; row++ {
-		charPos += len(e.lines[row]) + 1 // +1 for newline
-	}
-	charPos += e.cursorCol
-
-	// Find the statement containing this position
-	currentPos := 0
-	for _, stmt := range statements {
-		stmtLen := len(stmt)
-		if charPos >= currentPos && charPos <= currentPos+stmtLen {
-			return strings.TrimSpace(stmt)
-		}
-		currentPos += stmtLen + 1 // +1 for semicolon
+		return ""
 	}
-
-	// Return last statement if cursor is at end
-	return strings.TrimSpace(statements[len(statements)-1])
+	return statements[statementAt(statements, e.cursorOffset())].SQL
 }
 
-// splitStatements splits SQL content into individual statements
-func splitStatements(content string) []string {
-	var statements []string
-	var current strings.Builder
-	inString := false
-
-	for i := 0; i < len(content); i++ {
-		ch := content[i]
-
-		if ch == '\'' && !inString {
-			inString = true
-			current.WriteByte(ch)
-		} else if ch == '\'' && inString {
-			// Check for escaped quote
-			if i+1 < len(content) && content[i+1] == '\'' {
-				current.WriteByte(ch)
-				current.WriteByte(content[i+1])
-				i++
-			} else {
-				inString = false
-				current.WriteByte(ch)
-			}
-		} else if ch == ';' && !inString {
-			stmt := current.String()
-			if strings.TrimSpace(stmt) != "" {
-				statements = append(statements, stmt)
-			}
-			current.Reset()
-		} else {
-			current.WriteByte(ch)
+// statementAt returns the index of the statement a byte offset is in, or
+// -1 when there are none. A cursor right after a semicolon still belongs to
+// that statement; one past the last statement belongs to it.
+func statementAt(statements []query.Statement, offset int) int {
+	for i, stmt := range statements {
+		if offset <= stmt.End {
+			return i
 		}
 	}
-
-	// Add remaining content
-	stmt := current.String()
-	if strings.TrimSpace(stmt) != "" {
-		statements = append(statements, stmt)
-	}
-
-	return statements
+	return len(statements) - 1
 }
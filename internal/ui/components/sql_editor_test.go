@@ -0,0 +1,111 @@
+// internal/ui/components/sql_editor_test.go
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rebelice/lazypg/internal/completion"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func TestGetCurrentStatement(t *testing.T) {
+	e := NewSQLEditor(theme.DefaultTheme())
+	e.SetContent("SELECT ';';\nSELECT 2;\n\nSELECT 3")
+
+	tests := []struct {
+		row, col int
+		want     string
+	}{
+		{0, 0, "SELECT ';'"},
+		{0, 11, "SELECT ';'"}, // Right after the semicolon
+		{1, 3, "SELECT 2"},
+		{2, 0, "SELECT 3"},
+		{3, 8, "SELECT 3"},
+	}
+	for _, tt := range tests {
+		e.cursorRow, e.cursorCol = tt.row, tt.col
+		if got := e.GetCurrentStatement(); got != tt.want {
+			t.Errorf("cursor at %d:%d: GetCurrentStatement() = %q, want %q", tt.row, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	e := NewSQLEditor(theme.DefaultTheme())
+	e.SetContent("select 1; select a, b from t")
+	e.cursorRow, e.cursorCol = 0, 12
+
+	e.Format()
+	if got, want := e.GetContent(), "SELECT 1;\n\nSELECT\n  a,\n  b\nFROM t"; got != want {
+		t.Fatalf("GetContent() = %q, want %q", got, want)
+	}
+	if got := e.GetCurrentStatement(); got != "SELECT\n  a,\n  b\nFROM t" {
+		t.Errorf("cursor left the second statement, GetCurrentStatement() = %q", got)
+	}
+}
+
+func TestSelection(t *testing.T) {
+	e := NewSQLEditor(theme.DefaultTheme())
+	e.SetContent("SELECT 1;\nSELECT 2;")
+	e.cursorRow, e.cursorCol = 0, 7
+
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+	if got, want := e.GetSelection(), "1;\nSELECT 2"; got != want {
+		t.Fatalf("GetSelection() = %q, want %q", got, want)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got, want := e.GetContent(), "SELECT ;"; got != want {
+		t.Errorf("content after deleting selection = %q, want %q", got, want)
+	}
+	if e.HasSelection() {
+		t.Error("selection should be cleared after deleting it")
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	cat := &completion.Catalog{
+		Schemas:   []string{"public"},
+		Relations: []completion.Relation{{Schema: "public", Name: "users"}, {Schema: "public", Name: "user_stats"}},
+	}
+	e := NewSQLEditor(theme.DefaultTheme())
+	e.SetCompletionSource(func(sql string, offset int) (completion.Result, tea.Cmd) {
+		return completion.Complete(cat, sql, offset), nil
+	}, true)
+	e.Width, e.Height = 60, 10
+	e.Expand()
+	e.SetContent("SELECT * FROM ")
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("us")})
+	if !e.CompletionVisible() {
+		t.Fatal("completion popup should open while typing a name")
+	}
+	if view := e.View(); !strings.Contains(view, "user_stats") {
+		t.Errorf("view does not show the suggestions:\n%s", view)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got, want := e.GetContent(), "SELECT * FROM users"; got != want {
+		t.Errorf("content after accepting = %q, want %q", got, want)
+	}
+	if e.CompletionVisible() {
+		t.Error("completion popup should close after accepting")
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")})
+	if e.CompletionVisible() {
+		t.Error("completion popup should not open after a space")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	if !e.CompletionVisible() {
+		t.Error("Ctrl+Space should open the completion popup")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.CompletionVisible() {
+		t.Error("Esc should close the completion popup")
+	}
+}
@@ -0,0 +1,136 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rebelice/lazypg/internal/db/metadata"
+)
+
+// deadTupleWarning is the share of dead tuples above which a table is
+// flagged as needing a vacuum
+const deadTupleWarning = 0.2
+
+// statsLine is a labelled line of the table statistics summary
+type statsLine struct {
+	label string
+	text  string
+	warn  bool
+}
+
+// statsSummary describes the health of a table as of now
+func statsSummary(stats *metadata.TableStats, now time.Time) []statsLine {
+	dead := fmt.Sprintf("%s live · %s dead", formatNumber(stats.LiveTuples), formatNumber(stats.DeadTuples))
+	deadRatio := 0.0
+	if total := stats.LiveTuples + stats.DeadTuples; total > 0 {
+		deadRatio = float64(stats.DeadTuples) / float64(total)
+		dead += fmt.Sprintf(" (%.1f%%)", deadRatio*100)
+	}
+	dead += fmt.Sprintf(" · %s modified since analyze", formatNumber(stats.ModifiedSinceAnalyze))
+
+	scans := fmt.Sprintf("%s sequential (%s rows read) · %s index (%s rows fetched)",
+		formatNumber(stats.SeqScans), formatNumber(stats.SeqTuplesRead),
+		formatNumber(stats.IndexScans), formatNumber(stats.IndexTuplesFetched))
+	if total := stats.SeqScans + stats.IndexScans; total > 0 {
+		scans += fmt.Sprintf(" · %.1f%% by index", float64(stats.IndexScans)/float64(total)*100)
+	}
+
+	writes := fmt.Sprintf("%s inserted · %s updated (%s HOT) · %s deleted",
+		formatNumber(stats.Inserts), formatNumber(stats.Updates), formatNumber(stats.HotUpdates), formatNumber(stats.Deletes))
+
+	vacuum := fmt.Sprintf("%s · auto %s · %d manual, %d auto",
+		formatStatsTime(stats.LastVacuum, now), formatStatsTime(stats.LastAutovacuum, now),
+		stats.VacuumCount, stats.AutovacuumCount)
+	analyze := fmt.Sprintf("%s · auto %s · %d manual, %d auto",
+		formatStatsTime(stats.LastAnalyze, now), formatStatsTime(stats.LastAutoanalyze, now),
+		stats.AnalyzeCount, stats.AutoanalyzeCount)
+
+	cache := fmt.Sprintf("table %s · indexes %s · toast %s",
+		formatHitRatio(stats.HeapBlocksHit, stats.HeapBlocksRead),
+		formatHitRatio(stats.IndexBlocksHit, stats.IndexBlocksRead),
+		formatHitRatio(stats.ToastBlocksHit, stats.ToastBlocksRead))
+
+	size := fmt.Sprintf("%s total · table %s · toast %s · indexes %s",
+		metadata.FormatSize(stats.TotalSize), metadata.FormatSize(stats.TableSize),
+		metadata.FormatSize(stats.ToastSize), metadata.FormatSize(stats.IndexesSize))
+
+	lines := []statsLine{
+		{label: "Tuples", text: dead, warn: deadRatio > deadTupleWarning},
+		{label: "Scans", text: scans},
+		{label: "Writes", text: writes},
+		{label: "Vacuum", text: vacuum},
+		{label: "Analyze", text: analyze},
+		{label: "Cache hit", text: cache},
+		{label: "Size", text: size},
+	}
+	if !stats.StatsReset.IsZero() {
+		lines = append(lines, statsLine{label: "Since", text: "statistics reset " + formatStatsTime(stats.StatsReset, now)})
+	}
+	return lines
+}
+
+// renderStats renders the Stats tab: the table summary above the usage of
+// its indexes
+func (sv *StructureView) renderStats(height int) string {
+	if sv.statsError != "" {
+		return lipgloss.NewStyle().Foreground(sv.Theme.Error).Render(sv.statsError)
+	}
+	if sv.tableStats == nil {
+		return lipgloss.NewStyle().Foreground(sv.Theme.Metadata).Render("No statistics are collected for this relation.")
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(sv.Theme.Info)
+	textStyle := lipgloss.NewStyle().Foreground(sv.Theme.Foreground)
+	warnStyle := lipgloss.NewStyle().Foreground(sv.Theme.Warning)
+
+	var b strings.Builder
+	lines := statsSummary(sv.tableStats, time.Now())
+	for _, line := range lines {
+		style := textStyle
+		if line.warn {
+			style = warnStyle
+		}
+		label := runewidth.FillRight(line.label, 11)
+		b.WriteString(" " + labelStyle.Render(label))
+		b.WriteString(style.Render(runewidth.Truncate(line.text, max(10, sv.Width-13), "…")))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	sv.statsTable.Width = sv.Width
+	sv.statsTable.Height = max(3, height-len(lines)-1)
+	b.WriteString(sv.statsTable.View())
+	return b.String()
+}
+
+// formatHitRatio formats a buffer cache hit ratio, or "-" without reads
+func formatHitRatio(hit, read int64) string {
+	ratio, ok := metadata.HitRatio(hit, read)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
+
+// formatStatsTime formats a statistics timestamp with its age
+func formatStatsTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	age := now.Sub(t)
+	var ago string
+	switch {
+	case age < time.Minute:
+		ago = "just now"
+	case age < time.Hour:
+		ago = fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		ago = fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		ago = fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+	return fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04"), ago)
+}
@@ -0,0 +1,49 @@
+package components
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rebelice/lazypg/internal/db/metadata"
+)
+
+func TestStatsSummary(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stats := &metadata.TableStats{
+		LiveTuples:     700,
+		DeadTuples:     300,
+		SeqScans:       1,
+		IndexScans:     3,
+		LastAutovacuum: now.Add(-3 * time.Hour),
+		HeapBlocksHit:  99,
+		HeapBlocksRead: 1,
+	}
+
+	lines := statsSummary(stats, now)
+	byLabel := make(map[string]statsLine)
+	for _, line := range lines {
+		byLabel[line.label] = line
+	}
+
+	if line := byLabel["Tuples"]; !line.warn || !strings.Contains(line.text, "300 dead (30.0%)") {
+		t.Errorf("expected dead tuple warning, got %+v", line)
+	}
+	if line := byLabel["Scans"]; !strings.Contains(line.text, "75.0% by index") {
+		t.Errorf("unexpected scans line: %q", line.text)
+	}
+	if line := byLabel["Vacuum"]; !strings.HasPrefix(line.text, "never · auto ") || !strings.Contains(line.text, "(3h ago)") {
+		t.Errorf("unexpected vacuum line: %q", line.text)
+	}
+	if line := byLabel["Cache hit"]; line.text != "table 99.0% · indexes - · toast -" {
+		t.Errorf("unexpected cache line: %q", line.text)
+	}
+	if _, ok := byLabel["Since"]; ok {
+		t.Error("expected no reset line for statistics never reset")
+	}
+
+	stats.DeadTuples = 10
+	if line := statsSummary(stats, now)[0]; line.warn {
+		t.Errorf("expected no warning at 1.4%% dead tuples, got %+v", line)
+	}
+}
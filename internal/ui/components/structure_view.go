@@ -3,6 +3,7 @@ package components
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/atotto/clipboard"
@@ -26,7 +27,7 @@ type StructureView struct {
 	Height int
 	Theme  theme.Theme
 
-	// Current active tab (0=Data, 1=Columns, 2=Constraints, 3=Indexes)
+	// Current active tab (0=Data, 1=Columns, 2=Constraints, 3=Indexes, 4=Stats)
 	activeTab int
 
 	// Tab views - all using TableView for consistent UI
@@ -34,16 +35,21 @@ type StructureView struct {
 	columnsTable    *TableView // For Columns tab
 	constraintsTable *TableView // For Constraints tab
 	indexesTable    *TableView // For Indexes tab
+	statsTable      *TableView // For index usage in Stats tab
 
 	// Raw data for copy operations
 	columnsData     []models.ColumnDetail
 	constraintsData []models.Constraint
 	indexesData     []models.IndexInfo
+	tableStats      *metadata.TableStats
+	indexStats      []metadata.IndexStats
+	statsError      string
 
 	// Table info
 	schema string
 	table  string
 	pool   *connection.Pool
+	filter *models.Filter // Rows shown in the Data tab; nil for all rows
 
 	// Status
 	loading      bool
@@ -59,6 +65,7 @@ func NewStructureView(th theme.Theme, tableView *TableView) *StructureView {
 		columnsTable:     NewTableView(th),
 		constraintsTable: NewTableView(th),
 		indexesTable:     NewTableView(th),
+		statsTable:       NewTableView(th),
 	}
 }
 
@@ -67,6 +74,69 @@ func (sv *StructureView) HasTableLoaded(schema, table string) bool {
 	return sv.schema == schema && sv.table == table
 }
 
+// GetSchema returns the schema of the loaded table
+func (sv *StructureView) GetSchema() string {
+	return sv.schema
+}
+
+// GetTable returns the name of the loaded table
+func (sv *StructureView) GetTable() string {
+	return sv.table
+}
+
+// Filter returns the filter on the rows of the Data tab, or nil
+func (sv *StructureView) Filter() *models.Filter {
+	return sv.filter
+}
+
+// SetFilter limits the Data tab to the rows matching filter
+func (sv *StructureView) SetFilter(filter *models.Filter) {
+	sv.filter = filter
+}
+
+// Location returns the table, filter and selected cell of the Data tab
+func (sv *StructureView) Location() models.TableLocation {
+	row, col := sv.tableView.GetSelectedCell()
+	return models.TableLocation{Schema: sv.schema, Table: sv.table, Filter: sv.filter, Row: row, Col: col}
+}
+
+// ForeignKeyFor returns the foreign key that includes a column, or nil
+func (sv *StructureView) ForeignKeyFor(column string) *models.Constraint {
+	for i, con := range sv.constraintsData {
+		if con.Type == "f" && slices.Contains(con.Columns, column) {
+			return &sv.constraintsData[i]
+		}
+	}
+	return nil
+}
+
+// GetActiveTab returns the index of the active tab (0=Data)
+func (sv *StructureView) GetActiveTab() int {
+	return sv.activeTab
+}
+
+// GetColumns returns the column details of the loaded table
+func (sv *StructureView) GetColumns() []models.ColumnDetail {
+	return sv.columnsData
+}
+
+// RowKeyColumns returns the columns that uniquely identify a row:
+// the primary key, or the first unique constraint if there is none.
+// Returns nil if the table has neither.
+func (sv *StructureView) RowKeyColumns() []string {
+	for _, con := range sv.constraintsData {
+		if con.Type == "p" && len(con.Columns) > 0 {
+			return con.Columns
+		}
+	}
+	for _, con := range sv.constraintsData {
+		if con.Type == "u" && len(con.Columns) > 0 {
+			return con.Columns
+		}
+	}
+	return nil
+}
+
 // SetTable sets the current table and loads structure data
 func (sv *StructureView) SetTable(ctx context.Context, pool *connection.Pool, schema, table string) error {
 	sv.schema = schema
@@ -105,10 +175,33 @@ func (sv *StructureView) SetTable(ctx context.Context, pool *connection.Pool, sc
 	sv.indexesData = indexes
 	sv.setIndexesTableData(indexes)
 
+	// Load statistics; the structure is still useful without them
+	sv.loadStats(ctx, pool, schema, table)
+
 	sv.loading = false
 	return nil
 }
 
+// loadStats loads the statistics of the table and its indexes
+func (sv *StructureView) loadStats(ctx context.Context, pool *connection.Pool, schema, table string) {
+	sv.tableStats, sv.indexStats, sv.statsError = nil, nil, ""
+
+	stats, err := metadata.GetTableStats(ctx, pool, schema, table)
+	if err != nil {
+		sv.statsError = fmt.Sprintf("Failed to load statistics: %v", err)
+		return
+	}
+	sv.tableStats = stats
+
+	indexStats, err := metadata.GetIndexStats(ctx, pool, schema, table)
+	if err != nil {
+		sv.statsError = fmt.Sprintf("Failed to load index statistics: %v", err)
+		return
+	}
+	sv.indexStats = indexStats
+	sv.setStatsTableData(indexStats)
+}
+
 // setColumnsTableData converts column details to TableView format
 func (sv *StructureView) setColumnsTableData(columns []models.ColumnDetail) {
 	headers := []string{"Name", "Type", "Nullable", "Default", "Constraints", "Comment"}
@@ -241,9 +334,34 @@ func (sv *StructureView) formatIndexProperties(idx models.IndexInfo) string {
 	return strings.Join(props, ", ")
 }
 
+// setStatsTableData converts index statistics to TableView format
+func (sv *StructureView) setStatsTableData(indexes []metadata.IndexStats) {
+	headers := []string{"Index", "Scans", "Tuples Read", "Tuples Fetched", "Cache Hit", "Size", "Flags"}
+	rows := make([][]string, len(indexes))
+
+	for i, idx := range indexes {
+		flags := metadata.IndexFlags(idx)
+		if flags == "" {
+			flags = "-"
+		}
+
+		rows[i] = []string{
+			idx.Name,
+			formatNumber(idx.Scans),
+			formatNumber(idx.TuplesRead),
+			formatNumber(idx.TuplesFetched),
+			formatHitRatio(idx.BlocksHit, idx.BlocksRead),
+			metadata.FormatSize(idx.Size),
+			flags,
+		}
+	}
+
+	sv.statsTable.SetData(headers, rows, len(rows))
+}
+
 // SwitchTab switches to a specific tab
 func (sv *StructureView) SwitchTab(tabIndex int) {
-	if tabIndex >= 0 && tabIndex <= 3 {
+	if tabIndex >= 0 && tabIndex <= 4 {
 		sv.activeTab = tabIndex
 	}
 }
@@ -257,7 +375,7 @@ func (sv *StructureView) HandleMouseClick(msg tea.MouseMsg) (bool, int) {
 	}
 
 	// Check each tab zone
-	for i := 0; i <= 3; i++ {
+	for i := 0; i <= 4; i++ {
 		zoneID := fmt.Sprintf("%s%d", ZoneStructureTabPrefix, i)
 		if zone.Get(zoneID).InBounds(msg) {
 			sv.SwitchTab(i)
@@ -311,6 +429,8 @@ func (sv *StructureView) getCurrentTableView() *TableView {
 		return sv.constraintsTable
 	case 3:
 		return sv.indexesTable
+	case 4:
+		return sv.statsTable
 	default:
 		return nil
 	}
@@ -328,6 +448,8 @@ func (sv *StructureView) GetActiveTableView() *TableView {
 		return sv.constraintsTable
 	case 3:
 		return sv.indexesTable
+	case 4:
+		return sv.statsTable
 	default:
 		return sv.tableView
 	}
@@ -381,6 +503,8 @@ func (sv *StructureView) View() string {
 		b.WriteString(sv.constraintsTable.View())
 	case 3:
 		b.WriteString(sv.indexesTable.View())
+	case 4:
+		b.WriteString(sv.renderStats(contentHeight))
 	default:
 		b.WriteString("Unknown tab")
 	}
@@ -397,6 +521,7 @@ func (sv *StructureView) renderTabBar() string {
 		{1, "Columns"},
 		{2, "Constraints"},
 		{3, "Indexes"},
+		{4, "Stats"},
 	}
 
 	var parts []string
@@ -457,6 +582,10 @@ func (sv *StructureView) CopyCurrentName() string {
 		if idx := sv.getSelectedIndex(); idx != nil {
 			name = idx.Name
 		}
+	case 4:
+		if idx := sv.getSelectedIndexStats(); idx != nil {
+			name = idx.Name
+		}
 	}
 
 	if name != "" {
@@ -485,6 +614,10 @@ func (sv *StructureView) CopyCurrentDefinition() string {
 		if idx := sv.getSelectedIndex(); idx != nil {
 			definition = idx.Definition
 		}
+	case 4:
+		if idx := sv.getSelectedIndexStats(); idx != nil {
+			definition = idx.Definition
+		}
 	}
 
 	if definition != "" {
@@ -524,3 +657,12 @@ func (sv *StructureView) getSelectedIndex() *models.IndexInfo {
 	}
 	return &sv.indexesData[idx]
 }
+
+// getSelectedIndexStats returns the currently selected index of the Stats tab
+func (sv *StructureView) getSelectedIndexStats() *metadata.IndexStats {
+	idx := sv.statsTable.SelectedRow
+	if idx < 0 || idx >= len(sv.indexStats) {
+		return nil
+	}
+	return &sv.indexStats[idx]
+}
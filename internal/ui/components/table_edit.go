@@ -0,0 +1,337 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rebelice/lazypg/internal/db/edit"
+)
+
+// CellEdit is a staged, uncommitted change to a single cell
+type CellEdit struct {
+	Original string // Value currently stored in the database
+	Value    string // New value (ignored when IsNull is true)
+	IsNull   bool   // Set the cell to NULL
+}
+
+// DisplayValue returns the staged value as it should be rendered
+func (e CellEdit) DisplayValue() string {
+	if e.IsNull {
+		return "NULL"
+	}
+	return e.Value
+}
+
+// RowEdits groups the staged edits of a single row
+type RowEdits struct {
+	Row   int
+	Cells map[int]CellEdit // Keyed by column index
+}
+
+// BeginCellEdit starts editing the selected cell
+// Returns false if there is no cell to edit
+func (tv *TableView) BeginCellEdit() bool {
+	if tv.SelectedRow < 0 || tv.SelectedRow >= len(tv.Rows) {
+		return false
+	}
+	if tv.SelectedCol < 0 || tv.SelectedCol >= len(tv.Columns) {
+		return false
+	}
+
+	// Start from the staged value if the cell was already edited
+	if edit, ok := tv.getPendingEdit(tv.SelectedRow, tv.SelectedCol); ok {
+		if edit.IsNull {
+			tv.EditBuffer = ""
+		} else {
+			tv.EditBuffer = edit.Value
+		}
+	} else {
+		tv.EditBuffer = tv.GetSelectedCellContent()
+		if tv.IsNullCell(tv.SelectedRow, tv.SelectedCol) {
+			tv.EditBuffer = ""
+		}
+	}
+
+	tv.EditingCell = true
+	return true
+}
+
+// CancelCellEdit leaves edit mode without staging anything
+func (tv *TableView) CancelCellEdit() {
+	tv.EditingCell = false
+	tv.EditBuffer = ""
+}
+
+// IsEditingCell returns true while a cell is being edited
+func (tv *TableView) IsEditingCell() bool {
+	return tv.EditingCell
+}
+
+// HandleCellEditKey handles keyboard input while a cell is being edited
+// Enter stages the value, Ctrl+N stages NULL, Esc cancels
+func (tv *TableView) HandleCellEditKey(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc":
+		tv.CancelCellEdit()
+	case "enter":
+		tv.StageCellEdit(tv.SelectedRow, tv.SelectedCol, tv.EditBuffer, false)
+		tv.CancelCellEdit()
+	case "ctrl+n":
+		tv.StageCellEdit(tv.SelectedRow, tv.SelectedCol, "", true)
+		tv.CancelCellEdit()
+	case "backspace":
+		if len(tv.EditBuffer) > 0 {
+			runes := []rune(tv.EditBuffer)
+			tv.EditBuffer = string(runes[:len(runes)-1])
+		}
+	case "ctrl+u":
+		tv.EditBuffer = ""
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			tv.EditBuffer += string(msg.Runes)
+		}
+	}
+}
+
+// StageCellEdit records a change for a cell without touching the loaded rows
+// Staging the original value again removes the pending change
+func (tv *TableView) StageCellEdit(row, col int, value string, isNull bool) {
+	if row < 0 || row >= len(tv.Rows) || col < 0 || col >= len(tv.Rows[row]) {
+		return
+	}
+
+	original := tv.Rows[row][col]
+	originalNull := tv.IsNullCell(row, col)
+	unchanged := (isNull && originalNull) || (!isNull && !originalNull && value == original)
+
+	if unchanged {
+		if cells, ok := tv.PendingEdits[row]; ok {
+			delete(cells, col)
+			if len(cells) == 0 {
+				delete(tv.PendingEdits, row)
+			}
+		}
+		return
+	}
+
+	if tv.PendingEdits == nil {
+		tv.PendingEdits = make(map[int]map[int]CellEdit)
+	}
+	if tv.PendingEdits[row] == nil {
+		tv.PendingEdits[row] = make(map[int]CellEdit)
+	}
+	tv.PendingEdits[row][col] = CellEdit{
+		Original: original,
+		Value:    value,
+		IsNull:   isNull,
+	}
+}
+
+// getPendingEdit returns the staged edit for a cell, if any
+func (tv *TableView) getPendingEdit(row, col int) (CellEdit, bool) {
+	cells, ok := tv.PendingEdits[row]
+	if !ok {
+		return CellEdit{}, false
+	}
+	edit, ok := cells[col]
+	return edit, ok
+}
+
+// RevertRowEdits discards staged changes for a single row
+func (tv *TableView) RevertRowEdits(row int) {
+	delete(tv.PendingEdits, row)
+}
+
+// DiscardPendingEdits discards all staged changes
+func (tv *TableView) DiscardPendingEdits() {
+	tv.PendingEdits = nil
+	tv.CancelCellEdit()
+}
+
+// HasPendingEdits returns true if there are staged changes
+func (tv *TableView) HasPendingEdits() bool {
+	return len(tv.PendingEdits) > 0
+}
+
+// PendingEditCount returns the number of changed cells
+func (tv *TableView) PendingEditCount() int {
+	count := 0
+	for _, cells := range tv.PendingEdits {
+		count += len(cells)
+	}
+	return count
+}
+
+// GetPendingEdits returns staged changes ordered by row
+func (tv *TableView) GetPendingEdits() []RowEdits {
+	rows := make([]int, 0, len(tv.PendingEdits))
+	for row := range tv.PendingEdits {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+
+	result := make([]RowEdits, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, RowEdits{Row: row, Cells: tv.PendingEdits[row]})
+	}
+	return result
+}
+
+// ApplyPendingEdits writes staged values into the loaded rows and clears them
+// Call this after the changes were committed to the database
+func (tv *TableView) ApplyPendingEdits() {
+	for row, cells := range tv.PendingEdits {
+		if row >= len(tv.Rows) {
+			continue
+		}
+		for col, edit := range cells {
+			if col < len(tv.Rows[row]) {
+				tv.Rows[row][col] = edit.DisplayValue()
+			}
+			// Typed cells decide what is NULL, so they must change too
+			if cell, ok := tv.cellAt(row, col); ok {
+				cell.Value = nil
+				if !edit.IsNull {
+					cell.Value = edit.Value
+				}
+				tv.Cells[row][col] = cell
+			}
+		}
+	}
+	tv.PendingEdits = nil
+	tv.calculateColumnWidths()
+}
+
+// BuildRowUpdates converts staged edits into key-scoped row updates
+// keyColumns must uniquely identify a row (primary key or unique constraint)
+func (tv *TableView) BuildRowUpdates(keyColumns []string) ([]edit.RowUpdate, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table has no primary key or unique constraint")
+	}
+
+	keyIndexes, err := tv.resolveColumns(keyColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []edit.RowUpdate
+	for _, rowEdits := range tv.GetPendingEdits() {
+		if rowEdits.Row >= len(tv.Rows) {
+			continue
+		}
+		key, err := tv.rowKey(rowEdits.Row, keyColumns, keyIndexes)
+		if err != nil {
+			return nil, err
+		}
+		update := edit.RowUpdate{Key: key}
+
+		cols := make([]int, 0, len(rowEdits.Cells))
+		for col := range rowEdits.Cells {
+			cols = append(cols, col)
+		}
+		sort.Ints(cols)
+		for _, col := range cols {
+			cell := rowEdits.Cells[col]
+			update.Changes = append(update.Changes, edit.ColumnChange{
+				Column:   tv.Columns[col],
+				OldValue: cell.Original,
+				NewValue: cell.Value,
+				IsNull:   cell.IsNull,
+			})
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// ToggleRowMark marks or unmarks a row for bulk actions
+func (tv *TableView) ToggleRowMark(row int) {
+	if row < 0 || row >= len(tv.Rows) {
+		return
+	}
+	if tv.MarkedRows[row] {
+		delete(tv.MarkedRows, row)
+		return
+	}
+	if tv.MarkedRows == nil {
+		tv.MarkedRows = make(map[int]bool)
+	}
+	tv.MarkedRows[row] = true
+}
+
+// IsRowMarked returns true if the row is marked
+func (tv *TableView) IsRowMarked(row int) bool {
+	return tv.MarkedRows[row]
+}
+
+// ClearMarks unmarks all rows
+func (tv *TableView) ClearMarks() {
+	tv.MarkedRows = nil
+}
+
+// GetMarkedRows returns marked row indexes in ascending order
+func (tv *TableView) GetMarkedRows() []int {
+	rows := make([]int, 0, len(tv.MarkedRows))
+	for row := range tv.MarkedRows {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// BuildRowKeys returns the key values identifying each of the given rows
+func (tv *TableView) BuildRowKeys(keyColumns []string, rows []int) ([][]edit.KeyValue, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table has no primary key or unique constraint")
+	}
+
+	keyIndexes, err := tv.resolveColumns(keyColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([][]edit.KeyValue, 0, len(rows))
+	for _, rowIdx := range rows {
+		if rowIdx < 0 || rowIdx >= len(tv.Rows) {
+			continue
+		}
+		key, err := tv.rowKey(rowIdx, keyColumns, keyIndexes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// resolveColumns returns the positions of the named columns in the loaded data
+func (tv *TableView) resolveColumns(names []string) ([]int, error) {
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		indexes[i] = -1
+		for j, col := range tv.Columns {
+			if col == name {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("key column %q is not part of the loaded data", name)
+		}
+	}
+	return indexes, nil
+}
+
+// rowKey extracts the key values of a row
+func (tv *TableView) rowKey(rowIdx int, keyColumns []string, keyIndexes []int) ([]edit.KeyValue, error) {
+	row := tv.Rows[rowIdx]
+	key := make([]edit.KeyValue, 0, len(keyIndexes))
+	for i, idx := range keyIndexes {
+		if tv.IsNullCell(rowIdx, idx) {
+			return nil, fmt.Errorf("row %d has NULL in key column %q", rowIdx+1, keyColumns[i])
+		}
+		key = append(key, edit.KeyValue{Column: keyColumns[i], Value: row[idx]})
+	}
+	return key, nil
+}
@@ -0,0 +1,175 @@
+// internal/ui/components/table_edit_test.go
+package components
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+	"github.com/rebelice/lazypg/internal/ui/theme"
+)
+
+func newEditTestTable() *TableView {
+	tv := NewTableView(theme.DefaultTheme())
+	tv.SetData(
+		[]string{"id", "name", "email"},
+		[][]string{
+			{"1", "alice", "a@example.com"},
+			{"2", "bob", "NULL"},
+		},
+		2,
+	)
+	return tv
+}
+
+func TestStageCellEdit(t *testing.T) {
+	tv := newEditTestTable()
+
+	tv.StageCellEdit(0, 1, "alicia", false)
+	if tv.PendingEditCount() != 1 {
+		t.Fatalf("expected 1 pending edit, got %d", tv.PendingEditCount())
+	}
+	if tv.Rows[0][1] != "alice" {
+		t.Errorf("staging must not modify loaded rows, got '%s'", tv.Rows[0][1])
+	}
+
+	// Staging the original value again removes the edit
+	tv.StageCellEdit(0, 1, "alice", false)
+	if tv.HasPendingEdits() {
+		t.Error("expected no pending edits after restoring original value")
+	}
+
+	// NULL on a NULL cell is not a change
+	tv.StageCellEdit(1, 2, "", true)
+	if tv.HasPendingEdits() {
+		t.Error("expected no pending edit when setting NULL cell to NULL")
+	}
+}
+
+func TestBuildRowUpdates(t *testing.T) {
+	tv := newEditTestTable()
+	tv.StageCellEdit(1, 2, "bob@example.com", false)
+	tv.StageCellEdit(0, 2, "", true)
+	tv.StageCellEdit(0, 1, "alicia", false)
+
+	updates, err := tv.BuildRowUpdates([]string{"id"})
+	if err != nil {
+		t.Fatalf("BuildRowUpdates failed: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 row updates, got %d", len(updates))
+	}
+
+	first := updates[0]
+	if first.Key[0].Column != "id" || first.Key[0].Value != "1" {
+		t.Errorf("unexpected key for first update: %+v", first.Key)
+	}
+	if len(first.Changes) != 2 || first.Changes[0].Column != "name" || !first.Changes[1].IsNull {
+		t.Errorf("unexpected changes for first update: %+v", first.Changes)
+	}
+
+	if _, err := tv.BuildRowUpdates(nil); err == nil {
+		t.Error("expected error without key columns")
+	}
+	if _, err := tv.BuildRowUpdates([]string{"missing"}); err == nil {
+		t.Error("expected error for key column not in result")
+	}
+}
+
+func TestApplyPendingEdits(t *testing.T) {
+	tv := newEditTestTable()
+	tv.StageCellEdit(0, 1, "alicia", false)
+	tv.StageCellEdit(1, 1, "", true)
+	tv.ApplyPendingEdits()
+
+	if tv.HasPendingEdits() {
+		t.Error("expected pending edits to be cleared")
+	}
+	if tv.Rows[0][1] != "alicia" || tv.Rows[1][1] != "NULL" {
+		t.Errorf("unexpected rows after apply: %v", tv.Rows)
+	}
+}
+
+func TestRowMarks(t *testing.T) {
+	tv := newEditTestTable()
+	tv.ToggleRowMark(1)
+	tv.ToggleRowMark(0)
+	tv.ToggleRowMark(5) // Out of range is ignored
+
+	marked := tv.GetMarkedRows()
+	if len(marked) != 2 || marked[0] != 0 || marked[1] != 1 {
+		t.Fatalf("expected marked rows [0 1], got %v", marked)
+	}
+
+	keys, err := tv.BuildRowKeys([]string{"id"}, marked)
+	if err != nil {
+		t.Fatalf("BuildRowKeys failed: %v", err)
+	}
+	if len(keys) != 2 || keys[1][0].Value != "2" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	tv.ToggleRowMark(0)
+	if tv.IsRowMarked(0) {
+		t.Error("expected row 0 to be unmarked")
+	}
+
+	if _, err := tv.BuildRowKeys([]string{"email"}, []int{1}); err == nil {
+		t.Error("expected error for NULL key value")
+	}
+}
+
+func TestApplyPendingEditsTypedCells(t *testing.T) {
+	tv := NewTableView(theme.DefaultTheme())
+	tv.SetData([]string{"id", "note"}, [][]string{{"1", "hello"}, {"2", "NULL"}}, 2)
+	tv.SetCells([][]models.Cell{
+		{{OID: pgtype.Int4OID, Value: int32(1)}, {OID: pgtype.TextOID, Value: "hello"}},
+		{{OID: pgtype.Int4OID, Value: int32(2)}, {OID: pgtype.TextOID}},
+	})
+
+	tv.StageCellEdit(0, 1, "", true)
+	tv.StageCellEdit(1, 1, "world", false)
+	tv.ApplyPendingEdits()
+
+	if !tv.IsNullCell(0, 1) {
+		t.Error("expected the committed NULL to be SQL NULL")
+	}
+	if tv.IsNullCell(1, 1) || tv.Cells[1][1].Value != "world" {
+		t.Errorf("expected the committed value, got %+v", tv.Cells[1][1])
+	}
+
+	// Editing again starts from the committed values
+	tv.StageCellEdit(0, 1, "", true)
+	tv.StageCellEdit(1, 1, "world", false)
+	if tv.HasPendingEdits() {
+		t.Errorf("expected no change against the committed values, got %v", tv.PendingEdits)
+	}
+}
+
+func TestTypedNullCells(t *testing.T) {
+	tv := NewTableView(theme.DefaultTheme())
+	tv.SetData([]string{"id", "note"}, [][]string{{"1", "NULL"}, {"2", "NULL"}}, 2)
+	tv.SetCells([][]models.Cell{
+		{{OID: pgtype.Int4OID, Value: int32(1)}, {OID: pgtype.TextOID, Value: "NULL"}},
+		{{OID: pgtype.Int4OID, Value: int32(2)}, {OID: pgtype.TextOID}},
+	})
+
+	if tv.IsNullCell(0, 1) {
+		t.Error("the text NULL must not be treated as SQL NULL")
+	}
+	if !tv.IsNullCell(1, 1) {
+		t.Error("expected SQL NULL")
+	}
+
+	// Setting the text cell to NULL is a change, setting the NULL cell is not
+	tv.StageCellEdit(0, 1, "", true)
+	tv.StageCellEdit(1, 1, "", true)
+	if tv.PendingEditCount() != 1 {
+		t.Errorf("expected 1 pending edit, got %d", tv.PendingEditCount())
+	}
+
+	tv.AppendRows([][]string{{"3", "x"}}, [][]models.Cell{{{OID: pgtype.Int4OID, Value: int32(3)}, {OID: pgtype.TextOID, Value: "x"}}}, false)
+	if tv.IsNullCell(2, 1) || len(tv.Cells) != 3 {
+		t.Error("appended rows must keep their typed values")
+	}
+}
@@ -10,7 +10,9 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 	"github.com/mattn/go-runewidth"
 	"github.com/rebelice/lazypg/internal/jsonb"
+	"github.com/rebelice/lazypg/internal/models"
 	"github.com/rebelice/lazypg/internal/ui/theme"
+	"github.com/rebelice/lazypg/internal/values"
 )
 
 // Zone ID prefixes for mouse click handling
@@ -23,6 +25,7 @@ const (
 type TableView struct {
 	Columns      []string
 	Rows         [][]string
+	Cells        [][]models.Cell // Typed values behind Rows, nil when unknown
 	Width        int
 	Height       int
 	Style        lipgloss.Style
@@ -35,6 +38,7 @@ type TableView struct {
 	SelectedRow  int
 	SelectedCol  int // Currently selected column
 	TotalRows    int
+	MoreRows     bool // More rows may follow TotalRows (streamed results)
 
 	// Column widths (calculated)
 	ColumnWidths []int
@@ -67,8 +71,17 @@ type TableView struct {
 	PendingCountTime time.Time // Last input time for timeout
 	PendingG         bool      // Waiting for second 'g' in 'gg'
 
+	// Inline editing state
+	EditingCell  bool                     // Whether the selected cell is being edited
+	EditBuffer   string                   // Input for the cell being edited
+	PendingEdits map[int]map[int]CellEdit // Staged changes keyed by row, then column
+	MarkedRows   map[int]bool             // Rows selected for bulk actions (e.g. delete)
+
 	// Cached styles for performance (avoid recreating on every render)
 	cachedStyles *tableViewStyles
+
+	// Columns holding numbers, which are aligned to the right
+	numericCols []bool
 }
 
 // tableViewStyles holds pre-computed styles for TableView rendering
@@ -82,11 +95,16 @@ type tableViewStyles struct {
 	selectedCell     lipgloss.Style
 	currentMatch     lipgloss.Style
 	otherMatch       lipgloss.Style
+	modifiedCell     lipgloss.Style
+	editingCell      lipgloss.Style
+	markedRow        lipgloss.Style
 	selectedRow      lipgloss.Style
 	normal           lipgloss.Style
+	nullCell         lipgloss.Style
 	lineNumNormal    lipgloss.Style
 	lineNumSelected  lipgloss.Style
 	lineNumRelative  lipgloss.Style
+	lineNumMarked    lipgloss.Style
 	status           lipgloss.Style
 	containerNormal  lipgloss.Style // Container border style when not focused
 	containerFocused lipgloss.Style // Container border style when focused
@@ -146,7 +164,19 @@ func (tv *TableView) initStyles() {
 		selectedRow: lipgloss.NewStyle().
 			Background(tv.Theme.Selection).
 			Foreground(tv.Theme.Foreground),
+		modifiedCell: lipgloss.NewStyle().
+			Foreground(tv.Theme.Warning).
+			Italic(true),
+		editingCell: lipgloss.NewStyle().
+			Background(tv.Theme.Selection).
+			Foreground(tv.Theme.Foreground).
+			Underline(true),
+		markedRow: lipgloss.NewStyle().
+			Foreground(tv.Theme.Info),
 		normal: lipgloss.NewStyle(),
+		nullCell: lipgloss.NewStyle().
+			Foreground(tv.Theme.Comment).
+			Italic(true),
 		lineNumNormal: lipgloss.NewStyle().
 			Foreground(tv.Theme.Metadata),
 		lineNumSelected: lipgloss.NewStyle().
@@ -154,6 +184,10 @@ func (tv *TableView) initStyles() {
 			Bold(true),
 		lineNumRelative: lipgloss.NewStyle().
 			Foreground(tv.Theme.Comment),
+		lineNumMarked: lipgloss.NewStyle().
+			Background(tv.Theme.Info).
+			Foreground(tv.Theme.Background).
+			Bold(true),
 		status: lipgloss.NewStyle().
 			Foreground(tv.Theme.Metadata).
 			Italic(true),
@@ -170,10 +204,59 @@ func (tv *TableView) initStyles() {
 func (tv *TableView) SetData(columns []string, rows [][]string, totalRows int) {
 	tv.Columns = columns
 	tv.Rows = rows
+	tv.Cells = nil
+	tv.numericCols = nil
 	tv.TotalRows = totalRows
+	// Staged edits are keyed by row index and become invalid on reload
+	tv.DiscardPendingEdits()
+	tv.ClearMarks()
 	tv.calculateColumnWidths()
 }
 
+// SetCells attaches the typed values behind the rows set with SetData
+func (tv *TableView) SetCells(cells [][]models.Cell) {
+	tv.Cells = cells
+	tv.numericCols = make([]bool, len(tv.Columns))
+	if len(cells) > 0 {
+		for i, cell := range cells[0] {
+			if i < len(tv.numericCols) {
+				tv.numericCols[i] = values.IsNumeric(cell.OID)
+			}
+		}
+	}
+}
+
+// AppendRows adds rows loaded after the initial data, with their typed
+// values when known. more reports whether further rows may still follow.
+func (tv *TableView) AppendRows(rows [][]string, cells [][]models.Cell, more bool) {
+	typed := tv.Cells != nil && len(tv.Cells) == len(tv.Rows) && len(cells) == len(rows)
+	tv.Rows = append(tv.Rows, rows...)
+	if typed {
+		tv.Cells = append(tv.Cells, cells...)
+	} else {
+		tv.Cells = nil
+	}
+	tv.TotalRows = len(tv.Rows)
+	tv.MoreRows = more
+}
+
+// cellAt returns the typed value of a cell, if the rows were loaded with types
+func (tv *TableView) cellAt(row, col int) (models.Cell, bool) {
+	if len(tv.Cells) != len(tv.Rows) || row < 0 || row >= len(tv.Cells) || col < 0 || col >= len(tv.Cells[row]) {
+		return models.Cell{}, false
+	}
+	return tv.Cells[row][col], true
+}
+
+// IsNullCell reports whether a cell holds SQL NULL. Without type
+// information the text NULL is taken as NULL.
+func (tv *TableView) IsNullCell(row, col int) bool {
+	if cell, ok := tv.cellAt(row, col); ok {
+		return cell.IsNull()
+	}
+	return row >= 0 && row < len(tv.Rows) && col >= 0 && col < len(tv.Rows[row]) && tv.Rows[row][col] == values.NullText
+}
+
 // getLineNumberWidth returns the width needed for line number column
 func (tv *TableView) getLineNumberWidth() int {
 	if !tv.ShowLineNumbers {
@@ -232,7 +315,10 @@ func (tv *TableView) renderLineNumber(rowIndex int, isSelected bool) string {
 
 	// Use cached styles based on selection
 	var style lipgloss.Style
-	if isSelected {
+	if tv.IsRowMarked(rowIndex) {
+		// Marked for a bulk action
+		style = tv.cachedStyles.lineNumMarked
+	} else if isSelected {
 		// Current line: highlighted
 		style = tv.cachedStyles.lineNumSelected
 	} else if tv.RelativeNumbers {
@@ -537,6 +623,10 @@ func (tv *TableView) renderRow(row []string, selected bool, rowIndex int, visibl
 		}
 
 		value := row[i]
+		edit, isModified := tv.getPendingEdit(rowIndex, i)
+		if isModified {
+			value = edit.DisplayValue()
+		}
 
 		// Check if this looks like JSONB and format for display
 		cellValue := value
@@ -547,10 +637,21 @@ func (tv *TableView) renderRow(row []string, selected bool, rowIndex int, visibl
 		// Use runewidth.Truncate for proper truncation (handles multibyte chars)
 		truncated := runewidth.Truncate(cellValue, width, "…")
 
+		isEditing := tv.EditingCell && selected && i == tv.SelectedCol
+		if isEditing {
+			// Show the tail of the input so the cursor stays visible
+			truncated = tv.EditBuffer + "▏"
+			if overflow := runewidth.StringWidth(truncated) - width; overflow > 0 {
+				truncated = runewidth.TruncateLeft(truncated, overflow+1, "…")
+			}
+		}
+
 		// Determine cell style based on selection and search
-		// Priority: selected cell > current match > other matches > selected row > normal
+		// Priority: editing cell > selected cell > current match > other matches > modified > selected row > marked row > normal
 		var cellStyle lipgloss.Style
-		if selected && i == tv.SelectedCol {
+		if isEditing {
+			cellStyle = tv.cachedStyles.editingCell
+		} else if selected && i == tv.SelectedCol {
 			// Selected cell - highest priority, bright highlight
 			cellStyle = tv.cachedStyles.selectedCell
 		} else if tv.IsCurrentMatch(rowIndex, i) {
@@ -559,14 +660,28 @@ func (tv *TableView) renderRow(row []string, selected bool, rowIndex int, visibl
 		} else if tv.IsMatch(rowIndex, i) {
 			// Other search match - subtle highlight
 			cellStyle = tv.cachedStyles.otherMatch
+		} else if isModified {
+			// Cell with a staged, uncommitted change
+			cellStyle = tv.cachedStyles.modifiedCell
 		} else if selected {
 			// Selected row but not selected column - dim highlight
 			cellStyle = tv.cachedStyles.selectedRow
+		} else if tv.IsRowMarked(rowIndex) {
+			// Row marked for a bulk action
+			cellStyle = tv.cachedStyles.markedRow
+		} else if tv.IsNullCell(rowIndex, i) {
+			// SQL NULL, as opposed to the text "NULL"
+			cellStyle = tv.cachedStyles.nullCell
 		} else {
 			// Normal cell
 			cellStyle = tv.cachedStyles.normal
 		}
 
+		// Numbers line up on the right, like in psql
+		if !isEditing && !isModified && i < len(tv.numericCols) && tv.numericCols[i] {
+			cellStyle = cellStyle.Align(lipgloss.Right)
+		}
+
 		// Apply width constraint to the cell style and render
 		renderedCell := cellStyle.Width(width).MaxWidth(width).Inline(true).Render(truncated)
 
@@ -613,7 +728,20 @@ func (tv *TableView) renderStatus() string {
 		colInfo = fmt.Sprintf("Cols %d-%d of %d │ ", tv.LeftColOffset+1, endCol, len(tv.Columns))
 	}
 
-	showing := fmt.Sprintf(" 󰈙 %s%s%d-%d of %d rows", matchInfo, colInfo, tv.TopRow+1, endRow, tv.TotalRows)
+	// Staged edit info
+	editInfo := ""
+	if count := tv.PendingEditCount(); count > 0 {
+		editInfo = fmt.Sprintf(" │ %d pending change(s), w to review", count)
+	}
+	if count := len(tv.MarkedRows); count > 0 {
+		editInfo += fmt.Sprintf(" │ %d marked", count)
+	}
+
+	total := fmt.Sprintf("%d", tv.TotalRows)
+	if tv.MoreRows {
+		total += "+"
+	}
+	showing := fmt.Sprintf(" 󰈙 %s%s%d-%d of %s rows%s", matchInfo, colInfo, tv.TopRow+1, endRow, total, editInfo)
 	return tv.cachedStyles.status.Render(showing)
 }
 
@@ -1046,8 +1174,11 @@ func (tv *TableView) UpdatePreviewPane() {
 	content := tv.GetSelectedCellContent()
 	title := tv.GetSelectedColumnName()
 	isTruncated := tv.IsCellTruncated()
+	if cell, ok := tv.cellAt(tv.SelectedRow, tv.SelectedCol); ok && cell.OID != 0 {
+		title += " · " + values.TypeName(cell.OID)
+	}
 
-	tv.PreviewPane.SetContent(content, title, isTruncated)
+	tv.PreviewPane.SetContent(content, title, isTruncated, tv.IsNullCell(tv.SelectedRow, tv.SelectedCol))
 }
 
 // SetPreviewPaneDimensions sets the dimensions for the preview pane
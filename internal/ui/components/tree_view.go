@@ -453,6 +453,19 @@ func (tv *TreeView) getNodeIcon(node *models.TreeNode) string {
 	var iconColor lipgloss.Color
 
 	switch node.Type {
+	case models.TreeNodeTypeServer:
+		// Filled for the active connection, in the connection's color
+		icon = "◇"
+		iconColor = tv.Theme.DatabaseInactive
+		if meta, ok := node.Metadata.(map[string]interface{}); ok {
+			if active, ok := meta["active"].(bool); ok && active {
+				icon = "◆"
+			}
+			if color, ok := meta["color"].(string); ok {
+				iconColor = lipgloss.Color(color)
+			}
+		}
+
 	case models.TreeNodeTypeDatabase:
 		// Check if database is active
 		isActive := false
@@ -837,6 +850,16 @@ func (tv *TreeView) ExpandAndNavigateToNode(nodeID string) bool {
 	if targetNode == nil {
 		return false
 	}
+	return tv.NavigateToNode(targetNode)
+}
+
+// NavigateToNode expands all ancestors of a node of the tree and moves the
+// cursor to it. Unlike IDs, which repeat across connections, the node is
+// unambiguous.
+func (tv *TreeView) NavigateToNode(targetNode *models.TreeNode) bool {
+	if tv.Root == nil {
+		return false
+	}
 
 	// Expand all ancestors from root to parent
 	current := targetNode.Parent
@@ -848,7 +871,7 @@ func (tv *TreeView) ExpandAndNavigateToNode(nodeID string) bool {
 	// Now the node should be visible, set cursor to it
 	visibleNodes := tv.Root.Flatten()
 	for i, node := range visibleNodes {
-		if node.ID == nodeID {
+		if node == targetNode {
 			tv.CursorIndex = i
 			// Adjust scroll offset to make the node visible
 			tv.adjustScrollOffset(len(visibleNodes), tv.Height)
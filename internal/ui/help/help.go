@@ -20,6 +20,9 @@ func GetGlobalKeys() []KeyBinding {
 		{"Esc/Enter", "Dismiss error"},
 		{"Ctrl+K", "Open command palette"},
 		{"Ctrl+P", "Quick query"},
+		{"Ctrl+Y", "Browse query history"},
+		{"Alt+C", "Commit open transaction"},
+		{"Alt+R", "Roll back open transaction"},
 		{"Tab", "Switch panel focus"},
 		{"c", "Open connection dialog"},
 		{"r, F5", "Refresh current view"},
@@ -44,6 +47,8 @@ func GetNavigationKeys() []KeyBinding {
 		{"→/l", "Expand or move right"},
 		{"Enter", "Select item"},
 		{"Backspace", "Go to parent"},
+		{"I", "Import file into selected table"},
+		{"D", "ER diagram of selected schema"},
 	}
 }
 
@@ -54,6 +59,9 @@ func GetDataViewKeys() []KeyBinding {
 		{"Ctrl+F", "Quick filter from cell"},
 		{"Ctrl+R", "Clear filter"},
 		{"J", "Open JSONB viewer (on JSONB cell)"},
+		{"Enter", "Follow foreign key to referenced row"},
+		{"R", "List rows referencing current row"},
+		{"Alt+←/Alt+→", "Back/forward through followed keys"},
 		{"s", "Toggle sort on column (ASC/DESC)"},
 		{"S", "Toggle NULLS FIRST/LAST"},
 		{"h/l", "Move column left/right"},
@@ -62,13 +70,36 @@ func GetDataViewKeys() []KeyBinding {
 		{"$", "Jump to last column"},
 		{"/", "Open search (Tab to toggle mode)"},
 		{"n/N", "Next/Previous search match"},
+		{"e", "Edit cell (Enter stage, Ctrl+N NULL)"},
+		{"u", "Revert staged edits in row"},
+		{"w", "Review and commit staged edits"},
+		{"a", "Add a row"},
+		{"Space", "Mark/unmark row"},
+		{"D", "Delete marked rows (or current row)"},
+		{"E", "Export results to file"},
+		{"I", "Import file into table"},
+	}
+}
+
+// GetSQLEditorKeys returns SQL editor key bindings
+func GetSQLEditorKeys() []KeyBinding {
+	return []KeyBinding{
+		{"Ctrl+S", "Run statement at cursor (or selection)"},
+		{"F5", "Run all statements (or selection)"},
+		{"Shift+Arrows", "Select text"},
+		{"Ctrl+Space", "Show completions (Tab/Enter accepts)"},
+		{"Alt+F", "Format SQL"},
+		{"Ctrl+X / Alt+X", "Explain / Explain Analyze"},
+		{"Ctrl+↑/↓", "Previous/next query"},
+		{"Ctrl+O", "Open in external editor"},
+		{"Ctrl+U", "Clear editor"},
 	}
 }
 
 // GetStructureViewKeys returns structure view key bindings
 func GetStructureViewKeys() []KeyBinding {
 	return []KeyBinding{
-		{"1/2/3/4", "Switch tabs (Data/Columns/Constraints/Indexes)"},
+		{"1-5", "Switch tabs (Data/Columns/Constraints/Indexes/Stats)"},
 		{"↑↓ or j/k", "Navigate rows"},
 		{"y", "Copy name"},
 		{"Y", "Copy definition"},
@@ -144,6 +175,17 @@ func Render(width, height int, theme lipgloss.Style) string {
 	}
 	b.WriteString("\n")
 
+	// SQL editor keys
+	b.WriteString(sectionStyle.Render("SQL Editor"))
+	b.WriteString("\n")
+	for _, kb := range GetSQLEditorKeys() {
+		b.WriteString("  ")
+		b.WriteString(keyStyle.Render(kb.Key))
+		b.WriteString(descStyle.Render(kb.Description))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
 	// Structure view keys
 	b.WriteString(sectionStyle.Render("Structure View"))
 	b.WriteString("\n")
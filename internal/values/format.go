@@ -0,0 +1,300 @@
+package values
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// NullText is the display text of SQL NULL
+const NullText = "NULL"
+
+// Bytea output formats
+const (
+	ByteaHex    = "hex"
+	ByteaEscape = "escape"
+)
+
+// Options control how cell values are rendered as text
+type Options struct {
+	TimeZone    *time.Location // Zone timestamptz values are shown in
+	ByteaFormat string         // ByteaHex or ByteaEscape
+}
+
+// DefaultOptions returns the options used until SetOptions is called
+func DefaultOptions() Options {
+	return Options{TimeZone: time.Local, ByteaFormat: ByteaHex}
+}
+
+var current = DefaultOptions()
+
+// SetOptions changes the options used by Format. It is meant to be called
+// once at startup, before any rows are read.
+func SetOptions(opts Options) {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.Local
+	}
+	if opts.ByteaFormat != ByteaEscape {
+		opts.ByteaFormat = ByteaHex
+	}
+	current = opts
+}
+
+// ParseTimeZone resolves a configured zone name. An empty name or "local"
+// is the zone of the machine.
+func ParseTimeZone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	if strings.EqualFold(name, "utc") {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// Format renders a cell as text with the current options
+func Format(cell models.Cell) string {
+	return FormatWith(cell, current)
+}
+
+// FormatRow renders a row of cells as text
+func FormatRow(cells []models.Cell) []string {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = Format(cell)
+	}
+	return row
+}
+
+// FormatWith renders a cell as text, close to how PostgreSQL prints it
+func FormatWith(cell models.Cell, opts Options) string {
+	if cell.IsNull() {
+		return NullText
+	}
+	return formatValue(cell.OID, cell.Value, opts)
+}
+
+func formatValue(oid uint32, value interface{}, opts Options) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case float32:
+		return formatFloat(float64(v), 32)
+	case float64:
+		return formatFloat(v, 64)
+	case []byte:
+		if oid == pgtype.ByteaOID {
+			return formatBytea(v, opts.ByteaFormat)
+		}
+		return string(v)
+	case time.Time:
+		return formatTime(oid, v, opts.TimeZone)
+	case pgtype.InfinityModifier:
+		return v.String()
+	case pgtype.Interval:
+		return formatInterval(v)
+	case [16]byte:
+		return formatUUID(v)
+	case netip.Prefix:
+		// Host addresses print without their full-length mask, like inet
+		if oid != pgtype.CIDROID && v.Bits() == v.Addr().BitLen() {
+			return v.Addr().String()
+		}
+		return v.String()
+	case map[string]interface{}:
+		return formatJSON(v)
+	case []interface{}:
+		if isJSON(oid) || oid == 0 {
+			return formatJSON(v)
+		}
+		return formatArray(elementOID(oid), v, opts)
+	case pgtype.Range[interface{}]:
+		return formatRange(elementOID(oid), v, opts)
+	case pgtype.Multirange[pgtype.Range[interface{}]]:
+		rangeOID := elementOID(oid)
+		parts := make([]string, len(v))
+		for i, r := range v {
+			parts[i] = formatRange(elementOID(rangeOID), r, opts)
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case driver.Valuer:
+		// Numeric, time of day, geometric and bit string types render
+		// their PostgreSQL text form
+		if text, err := v.Value(); err == nil {
+			if s, ok := text.(string); ok {
+				return s
+			}
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// formatFloat prints a float the way PostgreSQL does: the shortest exact
+// form, switching to exponent notation for very large or small values
+func formatFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-4 || abs >= 1e15) {
+		return strconv.FormatFloat(f, 'e', -1, bits)
+	}
+	return strconv.FormatFloat(f, 'f', -1, bits)
+}
+
+// formatBytea prints binary data in PostgreSQL's hex or escape format
+func formatBytea(data []byte, format string) string {
+	if format != ByteaEscape {
+		return `\x` + hex.EncodeToString(data)
+	}
+	var b strings.Builder
+	for _, c := range data {
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// formatTime prints dates and timestamps in ISO 8601 form. Values with a
+// time zone are converted to zone.
+func formatTime(oid uint32, t time.Time, zone *time.Location) string {
+	switch oid {
+	case pgtype.DateOID:
+		return t.Format("2006-01-02")
+	case pgtype.TimestampOID:
+		return t.Format("2006-01-02 15:04:05.999999")
+	default:
+		if zone != nil {
+			t = t.In(zone)
+		}
+		return t.Format("2006-01-02 15:04:05.999999-07:00")
+	}
+}
+
+// formatInterval prints an interval like PostgreSQL's default style,
+// e.g. "1 year 2 mons 3 days 04:05:06"
+func formatInterval(iv pgtype.Interval) string {
+	var parts []string
+	unit := func(n int64, name string) {
+		if n == 0 {
+			return
+		}
+		if n != 1 {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+	}
+	unit(int64(iv.Months/12), "year")
+	unit(int64(iv.Months%12), "mon")
+	unit(int64(iv.Days), "day")
+
+	if iv.Microseconds != 0 || len(parts) == 0 {
+		us := iv.Microseconds
+		sign := ""
+		if us < 0 {
+			sign = "-"
+			us = -us
+		}
+		clock := fmt.Sprintf("%s%02d:%02d:%02d", sign, us/3600e6, us/60e6%60, us/1e6%60)
+		if frac := us % 1e6; frac != 0 {
+			clock += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+		}
+		parts = append(parts, clock)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatUUID prints a UUID in its canonical hyphenated form
+func formatUUID(u [16]byte) string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// formatJSON prints a decoded json or jsonb value
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// formatArray prints an array literal such as {1,2,NULL} or {"a b",c}
+func formatArray(elemOID uint32, elements []interface{}, opts Options) string {
+	parts := make([]string, len(elements))
+	for i, element := range elements {
+		if element == nil {
+			parts[i] = NullText
+			continue
+		}
+		parts[i] = quoteElement(formatValue(elemOID, element, opts), `{},"\`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatRange prints a range literal such as [1,10) or empty
+func formatRange(elemOID uint32, r pgtype.Range[interface{}], opts Options) string {
+	if r.LowerType == pgtype.Empty {
+		return "empty"
+	}
+
+	bound := func(value interface{}, boundType pgtype.BoundType) string {
+		if boundType == pgtype.Unbounded || value == nil {
+			return ""
+		}
+		return quoteElement(formatValue(elemOID, value, opts), `()[],"\`)
+	}
+
+	lower, upper := "(", ")"
+	if r.LowerType == pgtype.Inclusive {
+		lower = "["
+	}
+	if r.UpperType == pgtype.Inclusive {
+		upper = "]"
+	}
+	return lower + bound(r.Lower, r.LowerType) + "," + bound(r.Upper, r.UpperType) + upper
+}
+
+// quoteElement double-quotes an array element or range bound when it is
+// empty, reads as NULL, or contains whitespace or one of special
+func quoteElement(s, special string) string {
+	needsQuotes := s == "" || strings.EqualFold(s, NullText) ||
+		strings.ContainsAny(s, special+" \t\n\r\v\f")
+	if !needsQuotes {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
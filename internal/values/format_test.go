@@ -0,0 +1,84 @@
+package values
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+func TestFormatWith(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	opts := Options{TimeZone: berlin, ByteaFormat: ByteaHex}
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 500000000, time.UTC)
+
+	tests := []struct {
+		name string
+		cell models.Cell
+		want string
+	}{
+		{"null", models.Cell{OID: pgtype.TextOID}, "NULL"},
+		{"text NULL", models.Cell{OID: pgtype.TextOID, Value: "NULL"}, "NULL"},
+		{"int", models.Cell{OID: pgtype.Int8OID, Value: int64(-42)}, "-42"},
+		{"float", models.Cell{OID: pgtype.Float8OID, Value: 1234567.5}, "1234567.5"},
+		{"float exponent", models.Cell{OID: pgtype.Float8OID, Value: 1e20}, "1e+20"},
+		{"float infinity", models.Cell{OID: pgtype.Float8OID, Value: math.Inf(-1)}, "-Infinity"},
+		{"numeric", models.Cell{OID: pgtype.NumericOID, Value: pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}}, "123.45"},
+		{"bytea", models.Cell{OID: pgtype.ByteaOID, Value: []byte{0xde, 0xad}}, `\xdead`},
+		{"date", models.Cell{OID: pgtype.DateOID, Value: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, "2024-03-01"},
+		{"timestamp", models.Cell{OID: pgtype.TimestampOID, Value: ts}, "2024-03-01 12:30:00.5"},
+		{"timestamptz", models.Cell{OID: pgtype.TimestamptzOID, Value: ts}, "2024-03-01 13:30:00.5+01:00"},
+		{"infinity", models.Cell{OID: pgtype.TimestamptzOID, Value: pgtype.Infinity}, "infinity"},
+		{"interval", models.Cell{OID: pgtype.IntervalOID, Value: pgtype.Interval{Months: 14, Days: 3, Microseconds: 4*3600e6 + 5*60e6 + 6e6, Valid: true}}, "1 year 2 mons 3 days 04:05:06"},
+		{"negative interval", models.Cell{OID: pgtype.IntervalOID, Value: pgtype.Interval{Days: -1, Microseconds: -1500000, Valid: true}}, "-1 days -00:00:01.5"},
+		{"zero interval", models.Cell{OID: pgtype.IntervalOID, Value: pgtype.Interval{Valid: true}}, "00:00:00"},
+		{"uuid", models.Cell{OID: pgtype.UUIDOID, Value: [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8}}, "12345678-9abc-def0-0102-030405060708"},
+		{"inet host", models.Cell{OID: pgtype.InetOID, Value: netip.MustParsePrefix("10.0.0.1/32")}, "10.0.0.1"},
+		{"cidr", models.Cell{OID: pgtype.CIDROID, Value: netip.MustParsePrefix("10.0.0.0/8")}, "10.0.0.0/8"},
+		{"jsonb", models.Cell{OID: pgtype.JSONBOID, Value: map[string]interface{}{"a": []interface{}{1.0, "x"}}}, `{"a":[1,"x"]}`},
+		{"int array", models.Cell{OID: pgtype.Int4ArrayOID, Value: []interface{}{int32(1), nil, int32(3)}}, "{1,NULL,3}"},
+		{"text array", models.Cell{OID: pgtype.TextArrayOID, Value: []interface{}{"a b", "null", `q"`, "", "c"}}, `{"a b","null","q\"","",c}`},
+		{"int range", models.Cell{OID: pgtype.Int4rangeOID, Value: pgtype.Range[interface{}]{Lower: int32(1), Upper: int32(10), LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}}, "[1,10)"},
+		{"unbounded range", models.Cell{OID: pgtype.TstzrangeOID, Value: pgtype.Range[interface{}]{Lower: ts, LowerType: pgtype.Inclusive, UpperType: pgtype.Unbounded, Valid: true}}, `["2024-03-01 13:30:00.5+01:00",)`},
+		{"empty range", models.Cell{OID: pgtype.Int4rangeOID, Value: pgtype.Range[interface{}]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}}, "empty"},
+	}
+	for _, tt := range tests {
+		if got := FormatWith(tt.cell, opts); got != tt.want {
+			t.Errorf("%s: FormatWith() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatByteaEscape(t *testing.T) {
+	got := formatBytea([]byte("a\\b\x00\xff"), ByteaEscape)
+	if want := `a\\b\000\377`; got != want {
+		t.Errorf("formatBytea() = %q, want %q", got, want)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	row := map[string]interface{}{
+		"null":    JSONValue(models.Cell{OID: pgtype.Int4OID}),
+		"int":     JSONValue(models.Cell{OID: pgtype.Int4OID, Value: int32(7)}),
+		"numeric": JSONValue(models.Cell{OID: pgtype.NumericOID, Value: pgtype.Numeric{Int: big.NewInt(15), Exp: -1, Valid: true}}),
+		"nan":     JSONValue(models.Cell{OID: pgtype.NumericOID, Value: pgtype.Numeric{NaN: true, Valid: true}}),
+		"array":   JSONValue(models.Cell{OID: pgtype.Int4ArrayOID, Value: []interface{}{int32(1), nil}}),
+		"jsonb":   JSONValue(models.Cell{OID: pgtype.JSONBOID, Value: map[string]interface{}{"k": true}}),
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"array":[1,null],"int":7,"jsonb":{"k":true},"nan":"NaN","null":null,"numeric":1.5}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,135 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rebelice/lazypg/internal/models"
+)
+
+// moneyOID is the type OID of money, which pgtype has no constant for
+const moneyOID = 790
+
+// typeMap resolves the built-in PostgreSQL types by OID
+var typeMap = pgtype.NewMap()
+
+// TypeName returns the PostgreSQL name of a type, e.g. "int4" or "_text"
+// for text[]. Unknown types are shown by OID.
+func TypeName(oid uint32) string {
+	if oid == moneyOID {
+		return "money"
+	}
+	if t, ok := typeMap.TypeForOID(oid); ok {
+		return t.Name
+	}
+	return fmt.Sprintf("oid %d", oid)
+}
+
+// IsNumeric reports whether values of a type are numbers, which the grid
+// aligns to the right
+func IsNumeric(oid uint32) bool {
+	switch oid {
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.OIDOID,
+		pgtype.Float4OID, pgtype.Float8OID, pgtype.NumericOID, moneyOID:
+		return true
+	}
+	return false
+}
+
+// isJSON reports whether a type is json or jsonb
+func isJSON(oid uint32) bool {
+	return oid == pgtype.JSONOID || oid == pgtype.JSONBOID
+}
+
+// elementOID returns the element type of an array, range or multirange
+// type, or 0 when unknown
+func elementOID(oid uint32) uint32 {
+	t, ok := typeMap.TypeForOID(oid)
+	if !ok {
+		return 0
+	}
+	switch codec := t.Codec.(type) {
+	case *pgtype.ArrayCodec:
+		return codec.ElementType.OID
+	case *pgtype.RangeCodec:
+		return codec.ElementType.OID
+	case *pgtype.MultirangeCodec:
+		return codec.ElementType.OID
+	}
+	return 0
+}
+
+// Columns returns the names and type OIDs of the columns of a result
+func Columns(rows pgx.Rows) ([]string, []uint32) {
+	fields := rows.FieldDescriptions()
+	names := make([]string, len(fields))
+	oids := make([]uint32, len(fields))
+	for i, fd := range fields {
+		names[i] = fd.Name
+		oids[i] = fd.DataTypeOID
+	}
+	return names, oids
+}
+
+// ReadRow decodes the current row of rows into cells
+func ReadRow(rows pgx.Rows, oids []uint32) ([]models.Cell, error) {
+	raw, err := rows.Values()
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]models.Cell, len(raw))
+	for i, value := range raw {
+		cells[i].Value = value
+		if i < len(oids) {
+			cells[i].OID = oids[i]
+		}
+	}
+	return cells, nil
+}
+
+// JSONValue returns the value of a cell for JSON output: numbers and
+// booleans stay unquoted, json columns are embedded and arrays become JSON
+// arrays. Everything else is its display text.
+func JSONValue(cell models.Cell) interface{} {
+	if cell.IsNull() {
+		return nil
+	}
+	return jsonValue(cell.OID, cell.Value)
+}
+
+func jsonValue(oid uint32, value interface{}) interface{} {
+	if isJSON(oid) {
+		return value
+	}
+	switch v := value.(type) {
+	case bool, int16, int32, int64, uint32:
+		return v
+	case float32, float64:
+		return numberOrText(formatValue(oid, v, current))
+	case []interface{}:
+		elemOID := elementOID(oid)
+		elements := make([]interface{}, len(v))
+		for i, element := range v {
+			if element != nil {
+				elements[i] = jsonValue(elemOID, element)
+			}
+		}
+		return elements
+	}
+	text := formatValue(oid, value, current)
+	if oid == pgtype.NumericOID {
+		return numberOrText(text)
+	}
+	return text
+}
+
+// numberOrText returns text as a JSON number, or as a string for values such
+// as NaN and Infinity that JSON cannot represent
+func numberOrText(text string) interface{} {
+	if text != "" && (text[0] == '-' || text[0] >= '0' && text[0] <= '9') && json.Valid([]byte(text)) {
+		return json.Number(text)
+	}
+	return text
+}